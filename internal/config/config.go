@@ -22,11 +22,255 @@ const (
 	ControlPatchFile = "control-patch-file"
 	FilterPatchFile  = "filter-patch-file"
 
+	// Revision is the control plane revision used by the install and
+	// the namespace labeling operations
+	Revision = "revision"
+
+	// Profile is the IstioOperator profile used by the install operation
+	Profile = "profile"
+
+	// The requests and limits of the sidecar proxies set by the install
+	// operation, the defaults of the profile are used when empty
+	ProxyCPURequest    = "proxyResources.requests.cpu"
+	ProxyMemoryRequest = "proxyResources.requests.memory"
+	ProxyCPULimit      = "proxyResources.limits.cpu"
+	ProxyMemoryLimit   = "proxyResources.limits.memory"
+
+	// TracingSampling is the percentage of the requests the proxies trace,
+	// between 0 and 100, the default of the profile is used when empty
+	TracingSampling = "tracingSampling"
+
+	// The scaling of istiod set by the install operation, either a fixed
+	// replica count or the bounds of its HorizontalPodAutoscaler, the
+	// scaling of the profile is used when empty
+	PilotReplicaCount = "pilotReplicaCount"
+	PilotMinReplicas  = "pilotMinReplicas"
+	PilotMaxReplicas  = "pilotMaxReplicas"
+	PilotTargetCPU    = "pilotTargetCPU"
+
+	// The CA istiod issues the workload certificates with, set by the
+	// install operation in place of its self-signed root: the name of a
+	// Kubernetes signer such as clusterissuers.cert-manager.io/istio-ca, the
+	// host:port address of an external CA such as istio-csr, or the secret
+	// of a plugged-in CA, copied to cacerts. Only one of them can be set.
+	CASigner      = "caSigner"
+	CAAddress     = "caAddress"
+	CACertsSecret = "caCertsSecret"
+
+	// The SPIFFE trust domain of the workload identities set by the install
+	// operation, and the comma separated trust domains the mesh accepts the
+	// identities of as well, the trust domain of the profile is used when
+	// empty
+	TrustDomain        = "trustDomain"
+	TrustDomainAliases = "trustDomainAliases"
+
+	// The Istio CNI plugin installed by the install operation in place of
+	// the istio-init containers, either chained to the CNI config of the
+	// cluster or standalone, and the host directories of the CNI binaries
+	// and configs, the plugin of the profile is used when empty
+	CNIEnabled = "cni.enabled"
+	CNIChained = "cni.chained"
+	CNIBinDir  = "cni.cniBinDir"
+	CNIConfDir = "cni.cniConfDir"
+
+	// Purge makes the uninstall of Istio remove the Istio CRDs, the webhook
+	// configurations and the empty istio-system namespace as well
+	Purge = "purge"
+
+	// The HTTP(S) proxy the install and the release listing operations
+	// download through, overriding the HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+	// environment variables. The proxy applies to the whole adapter once
+	// an operation sets it.
+	HTTPProxy  = "httpProxy"
+	HTTPSProxy = "httpsProxy"
+	NoProxy    = "noProxy"
+
+	// DryRun makes the install operation render the manifest of the control
+	// plane in the event details instead of applying it
+	DryRun = "dryRun"
+
+	// AddonVersion pins the version of the addon manifests installed by the
+	// addon operations, the manifests bundled with the adapter are used when empty
+	AddonVersion = "addonVersion"
+
+	// ReadinessTimeout is how long the sample app operations wait for the
+	// deployments of the app to be available, no wait is done when empty
+	ReadinessTimeout = "readiness-timeout"
+
+	// OperationTimeout bounds the install, addon, sample app and SMI
+	// conformance operations, a Go duration such as 10m. Their readiness
+	// waits use it in place of their default timeouts, which are used when
+	// empty.
+	OperationTimeout = "operationTimeout"
+
+	// Wait makes the install and addon operations wait for their deployments
+	// to roll out, the default. Set to false, the operations return once
+	// applied with a submitted event, leaving the rollout to be polled.
+	Wait = "wait"
+
+	// ExtraLabels and ExtraAnnotations are merged onto the resources the
+	// install, addon and policy operations create, comma separated key=value
+	// lists such as team=payments,cost-center=42
+	ExtraLabels      = "extraLabels"
+	ExtraAnnotations = "extraAnnotations"
+
+	// ImageHub is the registry the Istio and addon images are pulled from in
+	// place of docker.io and gcr.io, a host[:port]/path such as
+	// registry.internal:5000/istio, for the clusters which can't reach them
+	ImageHub = "imageHub"
+
+	// MeshID, ClusterName and Network place the control plane installed by
+	// the install operation in a multi-cluster mesh. The mesh ID and the
+	// cluster name are set together, the network only when the clusters are
+	// on different networks.
+	MeshID      = "meshID"
+	ClusterName = "clusterName"
+	Network     = "network"
+
+	// RoutingTemplate is the template of the routing resources of the
+	// Gateway API sample app, applied separately from its workloads
+	RoutingTemplate = "routing-template"
+
+	// GatewayAPIBookInfoOperation deploys BookInfo routed through the
+	// Kubernetes Gateway API
+	GatewayAPIBookInfoOperation = "gateway-api-bookinfo"
+
 	// Istio vet operation
 	IstioVetOperation = "istio-vet"
 
-	// Configure Envoy filter operation
+	// ResultFormat selects the format of the results of the pass/fail
+	// operations, set to "junit" to stream the results as JUnit XML or, for
+	// istio-vet, to "json" to stream the findings as JSON
+	ResultFormat = "result-format"
+
+	// SMITestVersion pins the SMI conformance tests to a tag or a branch of
+	// their repository
+	SMITestVersion = "smi-test-version"
+
+	// SMIResultsDir is the directory the SMI conformance operation writes
+	// its report to as JSON, in a file named after the Istio and
+	// Kubernetes versions, no file is written when empty
+	SMIResultsDir = "smi-results-dir"
+
+	// FailOnWarning makes the warnings of istioctl analyze count as failures
+	// in its results
+	FailOnWarning = "failOnWarning"
+
+	// Legacy authentication policy migration operation
+	MigrateAuthPolicyOperation = "migrate-auth-policy-operation"
+	RemoveLegacyPolicies       = "remove-legacy-policies"
+
+	// Readiness gated traffic shifting operation
+	ReadinessGatedRolloutOperation = "readiness-gated-rollout-operation"
+	StableVersion                  = "stable-version"
+	CanaryVersion                  = "canary-version"
+	RolloutWindow                  = "rollout-window"
+	RolloutSteps                   = "rollout-steps"
+
+	// Configure Envoy filter operation, with merge the config patches are
+	// merged into the existing EnvoyFilters instead of replacing them
 	EnvoyFilterOperation = "envoy-filter-operation"
+	FilterMerge          = "merge"
+
+	// Gateway operations
+	IngressGatewayOperation = "ingress-gateway-operation"
+	EgressGatewayOperation  = "egress-gateway-operation"
+	GatewayReplicas         = "replicas"
+	GatewayServiceType      = "service-type"
+
+	// East-west gateway operation, the gateway is the one of the network
+	// property, the network of the multi-cluster install
+	EastWestGatewayOperation = "east-west-gateway-operation"
+
+	// Remote secret operation, the kubeconfig of the remote cluster is given
+	// inline or as a file:// or http(s):// location. The remote cluster is
+	// named after its current context unless the cluster name is set.
+	CreateRemoteSecretOperation = "create-remote-secret-operation"
+	RemoteKubeconfig            = "remoteKubeconfig"
+
+	// Proxy log level operation, the level is set on the proxies of the
+	// namespace, or on the proxy of the pod only if the pod is set
+	SetProxyLogLevelOperation = "set-proxy-log-level-operation"
+	ProxyLogLevel             = "logLevel"
+	ProxyPod                  = "pod"
+
+	// Telemetry API operation
+	TelemetryOperation = "telemetry-operation"
+	ProviderName       = "providerName"
+
+	// istioctl analyze operation
+	IstioAnalyzeOperation = "istio-analyze-operation"
+
+	// Sync status of the xDS configuration of every proxy, the proxies not
+	// fully synced being reported as warnings
+	ProxyStatusOperation = "proxy-status-operation"
+
+	// Envoy configuration dump of the sidecar of the pod of the namespace,
+	// the config type being clusters, listeners, routes or endpoints
+	ProxyConfigOperation = "proxy-config-operation"
+	ProxyConfigPod       = "pod"
+	ProxyConfigType      = "configType"
+
+	// Bug report operation, the diagnostics of the control plane and of the
+	// workloads of the namespace are archived on every cluster
+	IstioBugReportOperation = "istio-bug-report-operation"
+
+	// Mesh health check operation
+	IstioHealthCheckOperation = "istio-health-check-operation"
+
+	// Logs operation, fetching the last lines logged by the pods of the
+	// component, istiod or a gateway, in the namespace, istio-system when
+	// empty
+	IstioLogsOperation = "istio-logs-operation"
+	LogsComponent      = "component"
+	LogsTailLines      = "tailLines"
+
+	// Ambient waypoint proxy operation, the waypoint is the one of the
+	// namespace unless the service account is set
+	WaypointOperation      = "waypoint-operation"
+	WaypointServiceAccount = "service-account"
+
+	// Namespace injection status operation
+	InjectionStatusOperation = "injection-status-operation"
+
+	// Install of the Istio base alone, the CRDs and the cluster roles, for
+	// istiod to be installed separately
+	IstioBaseOperation = "istio-base-operation"
+
+	// Rollback of the control plane of the default revision to the
+	// requested version, older than the running one
+	IstioRollbackOperation = "istio-rollback-operation"
+
+	// Dump of the effective mesh configuration of every cluster
+	MeshConfigDumpOperation = "mesh-config-dump-operation"
+
+	// Inventory of the IstioOperator components, the control plane revisions
+	// and the addons installed on every cluster
+	ListInstalledComponentsOperation = "list-installed-components-operation"
+
+	// Export of the installed Istio resources of the namespace, of all the
+	// namespaces when empty, as a manifest bundle
+	ExportManifestsOperation = "export-manifests-operation"
+
+	// Restart of the workloads of a namespace for them to pick up their
+	// sidecars
+	RestartWorkloadsOperation = "restart-workloads-operation"
+
+	// Cancellation of a running operation by its operation ID
+	CancelOperation   = "cancel-operation"
+	CancelOperationID = "operation-id"
+
+	// Status of an operation by its operation ID, for the clients whose
+	// event stream dropped to catch up on its progress
+	OperationStatusOperation = "operation-status-operation"
+
+	// Istio releases listing operation
+	IstioListVersionsOperation = "istio-list-versions-operation"
+
+	// Service metrics summary operation
+	MetricsSummaryOperation = "metrics-summary-operation"
+	WorkloadName            = "workload-name"
+	PrometheusURL           = "prometheus-url"
 
 	// Addons that the adapter supports
 	PrometheusAddon = "prometheus-addon"
@@ -34,6 +278,8 @@ const (
 	KialiAddon      = "kiali-addon"
 	JaegerAddon     = "jaeger-addon"
 	ZipkinAddon     = "zipkin-addon"
+	LokiAddon       = "loki-addon"
+	TempoAddon      = "tempo-addon"
 
 	// Policies
 	DenyAllPolicyOperation     = "deny-all-policy-operation"
@@ -41,6 +287,123 @@ const (
 	MutualMTLSPolicyOperation  = "mutual-mtls-policy-operation"
 	DisableMTLSPolicyOperation = "disable-mtls-policy-operation"
 
+	// AllowFrom are the sources the deny-all policy makes exceptions for, a
+	// comma separated list of namespaces, namespace/service-account pairs
+	// and principals, such as frontend,payments/checkout
+	AllowFrom = "allowFrom"
+
+	// Namespace scoped mTLS policy operation
+	NamespaceMTLSPolicyOperation = "namespace-mtls-policy-operation"
+	MTLSMode                     = "mtlsMode"
+	PortLevelMTLS                = "portLevelMtls"
+	WorkloadSelector             = "workload-selector"
+
+	// AuthorizationPolicy operation
+	AuthorizationPolicyOperation = "authorization-policy-operation"
+	PolicyName                   = "policy-name"
+	PolicyAction                 = "action"
+	PolicyRules                  = "rules"
+
+	// RequestAuthentication operation, the audiences are a comma separated
+	// list
+	RequestAuthenticationOperation = "request-authentication-operation"
+	JWTIssuer                      = "issuer"
+	JWKSURI                        = "jwksUri"
+	JWTAudiences                   = "audiences"
+
+	// Expose service operation, routing the ingress gateway requests for
+	// the host to the service. The port is the one of the ingress gateway
+	// and the service port defaults to the only port of the service.
+	ExposeServiceOperation = "expose-service-operation"
+	ExposeHost             = "host"
+	ExposePort             = "port"
+	ExposeService          = "service"
+	ExposeServicePort      = "service-port"
+
+	// Traffic mirror operation, a copy of the percentage of the requests to
+	// the service is sent to the target service
+	TrafficMirrorOperation = "traffic-mirror-operation"
+	MirrorService          = "mirror-service"
+	MirrorTarget           = "mirror-target"
+	MirrorPercentage       = "mirror-percentage"
+
+	// Fault injection operation, the requests to the service are delayed by
+	// fixedDelay and aborted with httpStatus in the given percentages
+	FaultInjectionOperation = "fault-injection-operation"
+	FaultService            = "fault-service"
+	FaultDelayPercent       = "delayPercent"
+	FaultFixedDelay         = "fixedDelay"
+	FaultAbortPercent       = "abortPercent"
+	FaultHTTPStatus         = "httpStatus"
+
+	// ServiceEntry operation, the hosts and the ports, such as 443/TLS, are
+	// comma separated lists. The ServiceEntry is named after its first host
+	// unless its name is set.
+	ServiceEntryOperation  = "service-entry-operation"
+	ServiceEntryName       = "service-entry-name"
+	ServiceEntryHosts      = "hosts"
+	ServiceEntryPorts      = "ports"
+	ServiceEntryResolution = "resolution"
+	ServiceEntryLocation   = "location"
+	ServiceEntryEndpoints  = "endpoints"
+
+	// DestinationRule operation tuning the traffic policy of a host, the
+	// DestinationRule is named after the host unless its name is set. The
+	// zero limits and durations are left out, the outlier detection is only
+	// enabled by consecutive5xxErrors.
+	DestinationRuleOperation = "destination-rule-operation"
+	DestinationRuleName      = "destination-rule-name"
+	DestinationRuleHost      = "host"
+	LoadBalancer             = "loadBalancer"
+	MaxConnections           = "maxConnections"
+	MaxPendingRequests       = "http1MaxPendingRequests"
+	MaxRequestsPerConnection = "maxRequestsPerConnection"
+	Consecutive5xxErrors     = "consecutive5xxErrors"
+	OutlierInterval          = "interval"
+	BaseEjectionTime         = "baseEjectionTime"
+	MaxEjectionPercent       = "maxEjectionPercent"
+
+	// WorkloadEntry and WorkloadGroup operations registering the VMs in the
+	// mesh, named after the workload-name property. The labels are a comma
+	// separated key=value list and the network is the one of the network
+	// property.
+	WorkloadEntryOperation = "workload-entry-operation"
+	WorkloadGroupOperation = "workload-group-operation"
+	WorkloadAddress        = "address"
+	WorkloadLabels         = "workloadLabels"
+	WorkloadServiceAccount = "serviceAccount"
+
+	// Connectivity test operation, the request is sent from the source pod
+	// of the namespace to the target service, such as httpbin:8000/headers
+	ConnectivityTestOperation = "connectivity-test-operation"
+	SourcePod                 = "sourcePod"
+	TargetService             = "targetService"
+
+	// Namespace cleanup operation, removing the Istio resources of the
+	// namespace the custom operations and the policies created
+	CleanupNamespaceOperation = "cleanup-namespace-operation"
+
+	// mTLS verification operation, checking that the services of the
+	// namespace refuse plaintext. The requests are sent from the sourcePod
+	// property, the first pod of the namespace with a sidecar when empty.
+	VerifyMTLSOperation = "verify-mtls-operation"
+
+	// Root CA rotation operation
+	IstioRotateCAOperation = "istio-rotate-ca-operation"
+
+	// Canary upgrade operation, the namespaces are a comma separated list
+	// and default to the namespace of the request
+	IstioCanaryUpgradeOperation = "istio-canary-upgrade-operation"
+	FromRevision                = "from-revision"
+	ToRevision                  = "to-revision"
+	UpgradeNamespaces           = "namespaces"
+
+	// Switch of the namespace of the request to the control plane of the
+	// to-revision revision, the default one when empty, restarting its
+	// workloads unless restartWorkloads is false
+	SwitchNamespaceRevisionOperation = "switch-namespace-revision-operation"
+	RestartWorkloads                 = "restartWorkloads"
+
 	// OAM Metadata constants
 	OAMAdapterNameMetadataKey       = "adapter.meshery.io/name"
 	OAMComponentCategoryMetadataKey = "ui.meshery.io/category"
@@ -80,6 +443,26 @@ var (
 		configprovider.FileName: "istio",
 	}
 
+	// ClusterConcurrency is the number of clusters an operation works on at a
+	// time, it can be overridden with the CLUSTER_CONCURRENCY env variable
+	ClusterConcurrency = 4
+
+	// ApplyMaxAttempts is the number of times a manifest is applied to a
+	// cluster before its transient failures are reported, it can be
+	// overridden with the APPLY_MAX_ATTEMPTS env variable
+	ApplyMaxAttempts = 5
+
+	// MetricsAddress is the address the Prometheus metrics of the adapter
+	// are served on at /metrics, it can be overridden with the
+	// METRICS_ADDRESS env variable, an empty address disabling them
+	MetricsAddress = ":10010"
+
+	// TemplatesOverrideDir is the directory of the manifests overriding the
+	// templates of the operations, such as a mounted ConfigMap, it can be
+	// set with the TEMPLATES_OVERRIDE_DIR env variable, the bundled
+	// templates being used when empty
+	TemplatesOverrideDir = ""
+
 	// KubeConfig - Controlling the kubeconfig lifecycle with viper
 	KubeConfig = map[string]string{
 		configprovider.FilePath: configRootPath,
@@ -117,7 +500,11 @@ func New(provider string) (h config.Handler, err error) {
 	}
 
 	// Setup Operations Config
-	if err := h.SetObject(adapter.OperationsKey, GetOperations(common.Operations, "master")); err != nil {
+	operations := GetOperations(common.Operations, "master")
+	if err := overrideTemplates(operations, TemplatesOverrideDir); err != nil {
+		return nil, err
+	}
+	if err := h.SetObject(adapter.OperationsKey, operations); err != nil {
 		return nil, err
 	}
 
@@ -19,9 +19,10 @@ import (
 )
 
 const (
-	ErrEmptyConfigCode           = "1000"
-	ErrGetLatestReleasesCode     = "1001"
-	ErrGetLatestReleaseNamesCode = "1002"
+	ErrEmptyConfigCode             = "1000"
+	ErrGetLatestReleasesCode       = "1001"
+	ErrGetLatestReleaseNamesCode   = "1002"
+	ErrInvalidTemplateOverrideCode = "1117"
 )
 
 var (
@@ -37,3 +38,8 @@ func ErrGetLatestReleases(err error) error {
 func ErrGetLatestReleaseNames(err error) error {
 	return errors.New(ErrGetLatestReleaseNamesCode, errors.Alert, []string{"failed to extract release names"}, []string{err.Error()}, []string{"Invalid release format"}, []string{})
 }
+
+// ErrInvalidTemplateOverride is the error when a manifest of the templates override directory can't be read or isn't a valid template of its operation
+func ErrInvalidTemplateOverride(file string, err error) error {
+	return errors.New(ErrInvalidTemplateOverrideCode, errors.Alert, []string{"Invalid template override " + file}, []string{err.Error()}, []string{"The file is not named after an operation of the adapter", "The file is not valid YAML or a document has no apiVersion or kind", "The file has resources of other kinds than the bundled templates of the operation"}, []string{"Name the override <operation>.yaml and keep the kinds of the bundled templates, or remove it from TEMPLATES_OVERRIDE_DIR"})
+}
@@ -25,93 +25,417 @@ func GetOperations(dev adapter.Operations, version string) adapter.Operations {
 	dev[common.ImageHubOperation].Templates = append(dev[common.ImageHubOperation].Templates, "file://templates/imagehub/gateway.yaml")
 	dev[common.EmojiVotoOperation].Templates = append(dev[common.EmojiVotoOperation].Templates, "file://templates/emojivoto/gateway.yaml")
 
+	for _, op := range []string{common.BookInfoOperation, common.HTTPBinOperation, common.ImageHubOperation, common.EmojiVotoOperation} {
+		dev[op].AdditionalProperties[ReadinessTimeout] = "5m"
+	}
+
+	dev[GatewayAPIBookInfoOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_SAMPLE_APPLICATION),
+		Description: "Istio Book Info Application with Gateway API",
+		Versions:    adapter.NoneVersion,
+		Templates: []adapter.Template{
+			"https://raw.githubusercontent.com/istio/istio/master/samples/bookinfo/platform/kube/bookinfo.yaml",
+		},
+		AdditionalProperties: map[string]string{
+			common.ServiceName: common.BookInfoOperation,
+			RoutingTemplate:    "file://templates/bookinfo/gateway-api.yaml",
+			ReadinessTimeout:   "5m",
+		},
+	}
+
+	dev[common.SmiConformanceOperation].AdditionalProperties = map[string]string{
+		ResultFormat:     "",
+		SMITestVersion:   "master",
+		SMIResultsDir:    "",
+		OperationTimeout: "",
+	}
+
 	dev[IstioOperation] = &adapter.Operation{
-		Type:                 int32(meshes.OpCategory_INSTALL),
-		Description:          "Istio Service Mesh",
-		Versions:             adapterVersions,
-		AdditionalProperties: map[string]string{},
+		Type:        int32(meshes.OpCategory_INSTALL),
+		Description: "Istio Service Mesh",
+		Versions:    adapterVersions,
+		AdditionalProperties: map[string]string{
+			Revision:           "",
+			Profile:            "default",
+			Purge:              "false",
+			DryRun:             "false",
+			HTTPProxy:          "",
+			HTTPSProxy:         "",
+			NoProxy:            "",
+			ProxyCPURequest:    "",
+			ProxyMemoryRequest: "",
+			ProxyCPULimit:      "",
+			ProxyMemoryLimit:   "",
+			TracingSampling:    "",
+			PilotReplicaCount:  "",
+			PilotMinReplicas:   "",
+			PilotMaxReplicas:   "",
+			PilotTargetCPU:     "",
+			CASigner:           "",
+			CAAddress:          "",
+			CACertsSecret:      "",
+			TrustDomain:        "",
+			TrustDomainAliases: "",
+			CNIEnabled:         "",
+			CNIChained:         "",
+			CNIBinDir:          "",
+			CNIConfDir:         "",
+			MeshID:             "",
+			ClusterName:        "",
+			Network:            "",
+		},
+	}
+
+	dev[IstioBaseOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_INSTALL),
+		Description: "Istio Base (CRDs only)",
+		Versions:    adapterVersions,
+	}
+
+	dev[IstioRollbackOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_INSTALL),
+		Description: "Istio Rollback",
+		Versions:    adapterVersions,
+		AdditionalProperties: map[string]string{
+			Profile:          "default",
+			OperationTimeout: "",
+		},
 	}
 
 	dev[LabelNamespace] = &adapter.Operation{
 		Type:        int32(meshes.OpCategory_CONFIGURE),
 		Description: "Automatic Sidecar Injection",
+		AdditionalProperties: map[string]string{
+			Revision: "",
+		},
 	}
 
 	dev[PrometheusAddon] = &adapter.Operation{
 		Type:        int32(meshes.OpCategory_CONFIGURE),
 		Description: "Add-on: Prometheus",
+		Versions:    adapterVersions,
 		Templates: []adapter.Template{
 			adapter.Template(fmt.Sprintf("https://raw.githubusercontent.com/istio/istio/%s/samples/addons/prometheus.yaml", version)),
 		},
 		AdditionalProperties: map[string]string{
 			ServiceName:      "prometheus",
 			ServicePatchFile: "file://templates/patches/service-loadbalancer.json",
+			AddonVersion:     "",
 		},
 	}
 
 	dev[GrafanaAddon] = &adapter.Operation{
 		Type:        int32(meshes.OpCategory_CONFIGURE),
 		Description: "Add-on: Grafana",
+		Versions:    adapterVersions,
 		Templates: []adapter.Template{
 			adapter.Template(fmt.Sprintf("https://raw.githubusercontent.com/istio/istio/%s/samples/addons/grafana.yaml", version)),
 		},
 		AdditionalProperties: map[string]string{
 			ServiceName:      "grafana",
 			ServicePatchFile: "file://templates/patches/service-loadbalancer.json",
+			AddonVersion:     "",
 		},
 	}
 
 	dev[KialiAddon] = &adapter.Operation{
 		Type:        int32(meshes.OpCategory_CONFIGURE),
 		Description: "Add-on: Kiali",
+		Versions:    adapterVersions,
 		Templates: []adapter.Template{
 			adapter.Template(fmt.Sprintf("https://raw.githubusercontent.com/istio/istio/%s/samples/addons/kiali.yaml", version)),
 		},
 		AdditionalProperties: map[string]string{
 			ServiceName:      "kiali",
 			ServicePatchFile: "file://templates/patches/service-loadbalancer.json",
+			AddonVersion:     "",
 		},
 	}
 
 	dev[JaegerAddon] = &adapter.Operation{
 		Type:        int32(meshes.OpCategory_CONFIGURE),
 		Description: "Add-on: Jaeger",
+		Versions:    adapterVersions,
 		Templates: []adapter.Template{
 			adapter.Template(fmt.Sprintf("https://raw.githubusercontent.com/istio/istio/%s/samples/addons/jaeger.yaml", version)),
 		},
 		AdditionalProperties: map[string]string{
 			ServiceName:      "jaeger-collector",
 			ServicePatchFile: "file://templates/patches/service-loadbalancer.json",
+			AddonVersion:     "",
 		},
 	}
 
 	dev[ZipkinAddon] = &adapter.Operation{
 		Type:        int32(meshes.OpCategory_CONFIGURE),
 		Description: "Add-on: Zipkin",
+		Versions:    adapterVersions,
 		Templates: []adapter.Template{
 			adapter.Template(fmt.Sprintf("https://raw.githubusercontent.com/istio/istio/%s/samples/addons/extras/zipkin.yaml", version)),
 		},
 		AdditionalProperties: map[string]string{
 			ServiceName:      "zipkin",
 			ServicePatchFile: "file://templates/patches/service-loadbalancer.json",
+			AddonVersion:     "",
+		},
+	}
+
+	dev[LokiAddon] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_CONFIGURE),
+		Description: "Add-on: Loki",
+		Versions:    adapterVersions,
+		Templates: []adapter.Template{
+			adapter.Template(fmt.Sprintf("https://raw.githubusercontent.com/istio/istio/%s/samples/addons/loki.yaml", version)),
+		},
+		AdditionalProperties: map[string]string{
+			ServiceName:      "loki",
+			ServicePatchFile: "file://templates/patches/service-loadbalancer.json",
+			AddonVersion:     "",
+		},
+	}
+
+	dev[TempoAddon] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_CONFIGURE),
+		Description: "Add-on: Tempo",
+		Templates: []adapter.Template{
+			"file://templates/addons/tempo.yaml",
+		},
+		AdditionalProperties: map[string]string{
+			ServiceName:      "tempo",
+			ServicePatchFile: "file://templates/patches/service-loadbalancer.json",
 		},
 	}
 
 	dev[IstioVetOperation] = &adapter.Operation{
 		Type:        int32(meshes.OpCategory_VALIDATE),
 		Description: "Analyze Running Configuration",
+		AdditionalProperties: map[string]string{
+			ResultFormat: "",
+		},
+	}
+
+	dev[IstioAnalyzeOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_VALIDATE),
+		Description: "Analyze Istio Configuration",
+		Versions:    adapterVersions,
+		AdditionalProperties: map[string]string{
+			ResultFormat:  "",
+			FailOnWarning: "false",
+		},
+	}
+
+	dev[IngressGatewayOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_INSTALL),
+		Description: "Istio Ingress Gateway",
+		Versions:    adapterVersions,
+		AdditionalProperties: map[string]string{
+			GatewayReplicas:    "1",
+			GatewayServiceType: "LoadBalancer",
+		},
+	}
+
+	dev[EgressGatewayOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_INSTALL),
+		Description: "Istio Egress Gateway",
+		Versions:    adapterVersions,
+		AdditionalProperties: map[string]string{
+			GatewayReplicas:    "1",
+			GatewayServiceType: "ClusterIP",
+		},
+	}
+
+	dev[EastWestGatewayOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_INSTALL),
+		Description: "Istio East-West Gateway",
+		Versions:    adapterVersions,
+		AdditionalProperties: map[string]string{
+			Network: "network1",
+		},
+	}
+
+	dev[CreateRemoteSecretOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_CONFIGURE),
+		Description: "Multi-Cluster Remote Secret",
+		Versions:    adapterVersions,
+		AdditionalProperties: map[string]string{
+			RemoteKubeconfig: "",
+			ClusterName:      "",
+		},
+	}
+
+	dev[SetProxyLogLevelOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_CONFIGURE),
+		Description: "Proxy Log Level",
+		Versions:    adapterVersions,
+		AdditionalProperties: map[string]string{
+			ProxyLogLevel: "warning",
+			ProxyPod:      "",
+		},
+	}
+
+	dev[TelemetryOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_CONFIGURE),
+		Description: "Telemetry: Metrics, Access Logging and Tracing",
+		Templates: []adapter.Template{
+			"file://templates/telemetry/telemetry.yaml",
+		},
+		AdditionalProperties: map[string]string{
+			ProviderName: "",
+		},
+	}
+
+	dev[IstioHealthCheckOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_VALIDATE),
+		Description: "Mesh Health Check",
+		Versions:    adapter.NoneVersion,
+	}
+
+	dev[IstioLogsOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_VALIDATE),
+		Description: "Component Logs",
+		Versions:    adapter.NoneVersion,
+		AdditionalProperties: map[string]string{
+			LogsComponent: "istiod",
+			LogsTailLines: "100",
+		},
+	}
+
+	dev[WaypointOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_CONFIGURE),
+		Description: "Ambient Waypoint Proxy",
+		Versions:    adapter.NoneVersion,
+		Templates: []adapter.Template{
+			"file://templates/ambient/waypoint.yaml",
+		},
+		AdditionalProperties: map[string]string{
+			WaypointServiceAccount: "",
+		},
+	}
+
+	dev[InjectionStatusOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_VALIDATE),
+		Description: "Sidecar Injection Status",
+		Versions:    adapter.NoneVersion,
+	}
+
+	dev[ProxyStatusOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_VALIDATE),
+		Description: "Proxy Sync Status",
+		Versions:    adapterVersions,
+	}
+
+	dev[ProxyConfigOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_VALIDATE),
+		Description: "Proxy Config",
+		Versions:    adapterVersions,
+		AdditionalProperties: map[string]string{
+			ProxyConfigPod:  "",
+			ProxyConfigType: "clusters",
+		},
+	}
+
+	dev[IstioBugReportOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_VALIDATE),
+		Description: "Bug Report",
+		Versions:    adapterVersions,
+	}
+
+	dev[MeshConfigDumpOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_VALIDATE),
+		Description: "Mesh Configuration Dump",
+		Versions:    adapter.NoneVersion,
+	}
+
+	dev[ListInstalledComponentsOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_VALIDATE),
+		Description: "Installed Components Inventory",
+		Versions:    adapter.NoneVersion,
+	}
+
+	dev[ExportManifestsOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_CUSTOM),
+		Description: "Export Installed Manifests",
+		Versions:    adapter.NoneVersion,
+	}
+
+	dev[RestartWorkloadsOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_CONFIGURE),
+		Description: "Restart Workloads",
+		Versions:    adapter.NoneVersion,
+	}
+
+	dev[CancelOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_CONFIGURE),
+		Description: "Cancel Operation",
+		Versions:    adapter.NoneVersion,
+		AdditionalProperties: map[string]string{
+			CancelOperationID: "",
+		},
+	}
+
+	dev[OperationStatusOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_VALIDATE),
+		Description: "Operation Status",
+		Versions:    adapter.NoneVersion,
+		AdditionalProperties: map[string]string{
+			CancelOperationID: "",
+		},
+	}
+
+	dev[IstioListVersionsOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_VALIDATE),
+		Description: "List Available Istio Versions",
+		Versions:    adapter.NoneVersion,
+		AdditionalProperties: map[string]string{
+			HTTPProxy:  "",
+			HTTPSProxy: "",
+			NoProxy:    "",
+		},
+	}
+
+	dev[MetricsSummaryOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_VALIDATE),
+		Description: "Service Metrics Summary",
+		Versions:    adapter.NoneVersion,
+		AdditionalProperties: map[string]string{
+			WorkloadName:  "",
+			PrometheusURL: "",
+		},
+	}
+
+	dev[MigrateAuthPolicyOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_CONFIGURE),
+		Description: "Migrate Legacy Authentication Policies",
+		Versions:    adapter.NoneVersion,
+		AdditionalProperties: map[string]string{
+			RemoveLegacyPolicies: "false",
+		},
+	}
+
+	dev[ReadinessGatedRolloutOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_CONFIGURE),
+		Description: "Readiness Gated Traffic Shift",
+		Versions:    adapter.NoneVersion,
+		AdditionalProperties: map[string]string{
+			ServiceName:   "reviews",
+			StableVersion: "v1",
+			CanaryVersion: "v2",
+			RolloutWindow: "5m",
+			RolloutSteps:  "5",
+		},
 	}
 
 	dev[EnvoyFilterOperation] = &adapter.Operation{
 		Type:        int32(meshes.OpCategory_CONFIGURE),
 		Description: "Envoy Filter for Image Hub",
-		Versions:    adapter.NoneVersion,
+		Versions:    adapterVersions,
 		Templates: []adapter.Template{
 			"file://templates/imagehub/rate_limit_filter.yaml",
 		},
 		AdditionalProperties: map[string]string{
 			ServiceName:     "api-v1",
 			FilterPatchFile: "file://templates/imagehub/filter_patch.json",
+			FilterMerge:     "false",
 		},
 	}
 
@@ -121,6 +445,9 @@ func GetOperations(dev adapter.Operations, version string) adapter.Operations {
 		Templates: []adapter.Template{
 			"file://templates/policies/denyall.yaml",
 		},
+		AdditionalProperties: map[string]string{
+			AllowFrom: "",
+		},
 	}
 
 	dev[StrictMTLSPolicyOperation] = &adapter.Operation{
@@ -147,5 +474,224 @@ func GetOperations(dev adapter.Operations, version string) adapter.Operations {
 		},
 	}
 
+	dev[NamespaceMTLSPolicyOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_CONFIGURE),
+		Description: "Policy: Namespace MTLS",
+		Templates: []adapter.Template{
+			"file://templates/policies/namespace_mtls.yaml",
+		},
+		AdditionalProperties: map[string]string{
+			MTLSMode:         "STRICT",
+			PortLevelMTLS:    "",
+			WorkloadSelector: "",
+		},
+	}
+
+	dev[AuthorizationPolicyOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_CONFIGURE),
+		Description: "Policy: Authorization",
+		Templates: []adapter.Template{
+			"file://templates/policies/authorization.yaml",
+		},
+		AdditionalProperties: map[string]string{
+			PolicyName:       "",
+			PolicyAction:     "ALLOW",
+			PolicyRules:      "",
+			WorkloadSelector: "",
+		},
+	}
+
+	dev[RequestAuthenticationOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_CONFIGURE),
+		Description: "Policy: JWT Request Authentication",
+		Templates: []adapter.Template{
+			"file://templates/policies/request_authentication.yaml",
+		},
+		AdditionalProperties: map[string]string{
+			PolicyName:       "",
+			JWTIssuer:        "",
+			JWKSURI:          "",
+			JWTAudiences:     "",
+			WorkloadSelector: "",
+		},
+	}
+
+	dev[ExposeServiceOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_CONFIGURE),
+		Description: "Expose Service Through The Ingress Gateway",
+		Templates: []adapter.Template{
+			"file://templates/routing/expose.yaml",
+		},
+		AdditionalProperties: map[string]string{
+			ExposeHost:        "",
+			ExposePort:        "80",
+			ExposeService:     "",
+			ExposeServicePort: "",
+		},
+	}
+
+	dev[TrafficMirrorOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_CONFIGURE),
+		Description: "BookInfo Traffic Mirroring",
+		Templates: []adapter.Template{
+			"file://templates/routing/mirror.yaml",
+		},
+		AdditionalProperties: map[string]string{
+			MirrorService:    "reviews",
+			MirrorTarget:     "reviews-v2",
+			MirrorPercentage: "100",
+		},
+	}
+
+	dev[FaultInjectionOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_CONFIGURE),
+		Description: "BookInfo Fault Injection",
+		Templates: []adapter.Template{
+			"file://templates/routing/fault.yaml",
+		},
+		AdditionalProperties: map[string]string{
+			FaultService:      "ratings",
+			FaultDelayPercent: "100",
+			FaultFixedDelay:   "7s",
+			FaultAbortPercent: "0",
+			FaultHTTPStatus:   "500",
+		},
+	}
+
+	dev[DestinationRuleOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_CONFIGURE),
+		Description: "Destination Rule",
+		Templates: []adapter.Template{
+			"file://templates/routing/destination-rule.yaml",
+		},
+		AdditionalProperties: map[string]string{
+			DestinationRuleName:      "",
+			DestinationRuleHost:      "reviews",
+			LoadBalancer:             "ROUND_ROBIN",
+			MaxConnections:           "",
+			MaxPendingRequests:       "",
+			MaxRequestsPerConnection: "",
+			Consecutive5xxErrors:     "",
+			OutlierInterval:          "10s",
+			BaseEjectionTime:         "30s",
+			MaxEjectionPercent:       "",
+		},
+	}
+
+	dev[ServiceEntryOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_CONFIGURE),
+		Description: "Service Entry",
+		Templates: []adapter.Template{
+			"file://templates/routing/service-entry.yaml",
+		},
+		AdditionalProperties: map[string]string{
+			ServiceEntryName:       "",
+			ServiceEntryHosts:      "httpbin.org",
+			ServiceEntryPorts:      "443/TLS",
+			ServiceEntryResolution: "DNS",
+			ServiceEntryLocation:   "MESH_EXTERNAL",
+			ServiceEntryEndpoints:  "",
+		},
+	}
+
+	dev[WorkloadEntryOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_CONFIGURE),
+		Description: "VM Workload Entry",
+		Templates: []adapter.Template{
+			"file://templates/vm/workload-entry.yaml",
+		},
+		AdditionalProperties: map[string]string{
+			WorkloadName:           "",
+			WorkloadAddress:        "",
+			WorkloadLabels:         "app=vm",
+			WorkloadServiceAccount: "",
+			Network:                "",
+		},
+	}
+
+	dev[WorkloadGroupOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_CONFIGURE),
+		Description: "VM Workload Group",
+		Templates: []adapter.Template{
+			"file://templates/vm/workload-group.yaml",
+		},
+		AdditionalProperties: map[string]string{
+			WorkloadName:           "",
+			WorkloadLabels:         "app=vm",
+			WorkloadServiceAccount: "",
+			Network:                "",
+		},
+	}
+
+	dev[ConnectivityTestOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_VALIDATE),
+		Description: "Service Connectivity Test",
+		Versions:    adapter.NoneVersion,
+		AdditionalProperties: map[string]string{
+			SourcePod:     "",
+			TargetService: "httpbin:8000/headers",
+		},
+	}
+
+	dev[VerifyMTLSOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_VALIDATE),
+		Description: "Verify mTLS Enforcement",
+		Versions:    adapter.NoneVersion,
+		AdditionalProperties: map[string]string{
+			SourcePod: "",
+		},
+	}
+
+	dev[CleanupNamespaceOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_CONFIGURE),
+		Description: "Clean Up Istio Resources of Namespace",
+		Versions:    adapter.NoneVersion,
+	}
+
+	dev[IstioRotateCAOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_CONFIGURE),
+		Description: "Rotate Root CA",
+		Versions:    adapter.NoneVersion,
+	}
+
+	dev[IstioCanaryUpgradeOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_INSTALL),
+		Description: "Istio Canary Upgrade",
+		Versions:    adapterVersions,
+		AdditionalProperties: map[string]string{
+			FromRevision:      "",
+			ToRevision:        "",
+			Profile:           "default",
+			UpgradeNamespaces: "",
+		},
+	}
+
+	dev[SwitchNamespaceRevisionOperation] = &adapter.Operation{
+		Type:        int32(meshes.OpCategory_CONFIGURE),
+		Description: "Switch Namespace to Revision",
+		Versions:    adapter.NoneVersion,
+		AdditionalProperties: map[string]string{
+			ToRevision:       "",
+			RestartWorkloads: "true",
+		},
+	}
+
+	for _, op := range []string{IstioOperation, PrometheusAddon, GrafanaAddon, KialiAddon, JaegerAddon, ZipkinAddon, LokiAddon, TempoAddon, common.BookInfoOperation, common.HTTPBinOperation, common.ImageHubOperation, common.EmojiVotoOperation, GatewayAPIBookInfoOperation} {
+		dev[op].AdditionalProperties[OperationTimeout] = ""
+	}
+
+	for _, op := range []string{IstioOperation, PrometheusAddon, GrafanaAddon, KialiAddon, JaegerAddon, ZipkinAddon, LokiAddon, TempoAddon, DenyAllPolicyOperation, StrictMTLSPolicyOperation, MutualMTLSPolicyOperation, DisableMTLSPolicyOperation, NamespaceMTLSPolicyOperation, AuthorizationPolicyOperation, RequestAuthenticationOperation, FaultInjectionOperation, DestinationRuleOperation, ServiceEntryOperation, WorkloadEntryOperation, WorkloadGroupOperation} {
+		if dev[op].AdditionalProperties == nil {
+			dev[op].AdditionalProperties = map[string]string{}
+		}
+		dev[op].AdditionalProperties[ExtraLabels] = ""
+		dev[op].AdditionalProperties[ExtraAnnotations] = ""
+	}
+
+	for _, op := range []string{IstioOperation, PrometheusAddon, GrafanaAddon, KialiAddon, JaegerAddon, ZipkinAddon, LokiAddon, TempoAddon} {
+		dev[op].AdditionalProperties[ImageHub] = ""
+		dev[op].AdditionalProperties[Wait] = "true"
+	}
+
 	return dev
 }
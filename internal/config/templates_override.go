@@ -0,0 +1,128 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"gopkg.in/yaml.v2"
+)
+
+// overrideTemplates replaces the templates of the operations with the
+// manifests of the directory, <operation>.yaml replacing all the templates of
+// the operation. The directory is typically a ConfigMap mounted as a volume,
+// whose hidden entries are skipped. Every document of a manifest must have an
+// apiVersion and a kind, among the kinds of the bundled templates when they
+// are all bundled with the adapter. Nothing is overridden when dir is empty.
+func overrideTemplates(operations adapter.Operations, dir string) error {
+	if dir == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ErrInvalidTemplateOverride(dir, err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		ext := filepath.Ext(name)
+		if strings.HasPrefix(name, ".") || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		file, err := filepath.Abs(filepath.Join(dir, name))
+		if err != nil {
+			return ErrInvalidTemplateOverride(name, err)
+		}
+		operation, ok := operations[strings.TrimSuffix(name, ext)]
+		if !ok {
+			return ErrInvalidTemplateOverride(file, fmt.Errorf("no operation is named %s", strings.TrimSuffix(name, ext)))
+		}
+		contents, err := os.ReadFile(file)
+		if err != nil {
+			return ErrInvalidTemplateOverride(file, err)
+		}
+		kinds, err := manifestKinds(contents)
+		if err != nil {
+			return ErrInvalidTemplateOverride(file, err)
+		}
+		if expected := bundledKinds(operation.Templates); expected != nil {
+			for _, kind := range kinds {
+				if !expected[kind] {
+					return ErrInvalidTemplateOverride(file, fmt.Errorf("kind %s is not one of the kinds of the bundled templates: %s", kind, strings.Join(sortedKinds(expected), ", ")))
+				}
+			}
+		}
+		operation.Templates = []adapter.Template{adapter.Template("file://" + file)}
+	}
+	return nil
+}
+
+// manifestKinds returns the kinds of the documents of the manifest, every
+// document but the empty ones having an apiVersion and a kind
+func manifestKinds(manifest []byte) ([]string, error) {
+	var kinds []string
+	dec := yaml.NewDecoder(bytes.NewReader(manifest))
+	for i := 1; ; i++ {
+		var doc map[string]interface{}
+		err := dec.Decode(&doc)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("document %d: %w", i, err)
+		}
+		if len(doc) == 0 {
+			continue
+		}
+		apiVersion, _ := doc["apiVersion"].(string)
+		kind, _ := doc["kind"].(string)
+		if apiVersion == "" || kind == "" {
+			return nil, fmt.Errorf("document %d has no apiVersion or kind", i)
+		}
+		kinds = append(kinds, kind)
+	}
+	if len(kinds) == 0 {
+		return nil, fmt.Errorf("the manifest has no resources")
+	}
+	return kinds, nil
+}
+
+// bundledKinds returns the kinds of the templates when they are all bundled
+// with the adapter, nil when any of them is fetched or can't be read
+func bundledKinds(templates []adapter.Template) map[string]bool {
+	if len(templates) == 0 {
+		return nil
+	}
+	expected := map[string]bool{}
+	for _, template := range templates {
+		file, ok := strings.CutPrefix(string(template), "file://")
+		if !ok {
+			return nil
+		}
+		contents, err := os.ReadFile(file)
+		if err != nil {
+			return nil
+		}
+		kinds, err := manifestKinds(contents)
+		if err != nil {
+			return nil
+		}
+		for _, kind := range kinds {
+			expected[kind] = true
+		}
+	}
+	return expected
+}
+
+func sortedKinds(kinds map[string]bool) []string {
+	sorted := make([]string, 0, len(kinds))
+	for kind := range kinds {
+		sorted = append(sorted, kind)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
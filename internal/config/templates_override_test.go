@@ -0,0 +1,193 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/layer5io/meshkit/errors"
+)
+
+const (
+	deploymentManifest = "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: productpage\n"
+	serviceManifest    = "apiVersion: v1\nkind: Service\nmetadata:\n  name: productpage\n"
+)
+
+func writeManifests(t *testing.T, dir string, manifests map[string]string) {
+	t.Helper()
+	for name, manifest := range manifests {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(manifest), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestOverrideTemplates(t *testing.T) {
+	bundled := t.TempDir()
+	writeManifests(t, bundled, map[string]string{"bookinfo.yaml": deploymentManifest + "---\n" + serviceManifest})
+
+	tests := []struct {
+		name      string
+		manifests map[string]string
+		noDir     bool
+		fetched   bool
+		// overridden is the operation whose templates are replaced by
+		// the manifest of the same name, none if empty
+		overridden string
+		wantErr    string
+	}{
+		{
+			name:      "no override dir",
+			manifests: map[string]string{"bookinfo.yaml": serviceManifest},
+			noDir:     true,
+		},
+		{
+			name:       "override with the bundled kinds",
+			manifests:  map[string]string{"bookinfo.yaml": serviceManifest},
+			overridden: "bookinfo",
+		},
+		{
+			name:       "yml extension",
+			manifests:  map[string]string{"bookinfo.yml": "---\n" + deploymentManifest},
+			overridden: "bookinfo",
+		},
+		{
+			name: "hidden and non-YAML files skipped",
+			manifests: map[string]string{
+				".bookinfo.yaml": "not: [valid",
+				"bookinfo.json":  "{}",
+				"README.md":      "# overrides",
+			},
+		},
+		{
+			name:      "unknown operation",
+			manifests: map[string]string{"istio.yaml": serviceManifest},
+			wantErr:   "no operation is named istio",
+		},
+		{
+			name:      "kind mismatch with the bundled templates",
+			manifests: map[string]string{"bookinfo.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: productpage\n"},
+			wantErr:   "kind ConfigMap is not one of the kinds of the bundled templates: Deployment, Service",
+		},
+		{
+			name:       "any kind of a fetched template",
+			manifests:  map[string]string{"bookinfo.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: productpage\n"},
+			fetched:    true,
+			overridden: "bookinfo",
+		},
+		{
+			name:      "unparsable manifest",
+			manifests: map[string]string{"bookinfo.yaml": serviceManifest + "---\nnot: [valid\n"},
+			wantErr:   "document 2",
+		},
+		{
+			name:      "document with no kind",
+			manifests: map[string]string{"bookinfo.yaml": "apiVersion: v1\nmetadata:\n  name: productpage\n"},
+			wantErr:   "document 1 has no apiVersion or kind",
+		},
+		{
+			name:      "empty manifest",
+			manifests: map[string]string{"bookinfo.yaml": "---\n"},
+			wantErr:   "the manifest has no resources",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template := adapter.Template("file://" + filepath.Join(bundled, "bookinfo.yaml"))
+			if tt.fetched {
+				template = "https://raw.githubusercontent.com/istio/istio/master/samples/bookinfo/platform/kube/bookinfo.yaml"
+			}
+			operations := adapter.Operations{
+				"bookinfo": &adapter.Operation{Templates: []adapter.Template{template}},
+			}
+			dir := t.TempDir()
+			writeManifests(t, dir, tt.manifests)
+			if tt.noDir {
+				dir = ""
+			}
+
+			err := overrideTemplates(operations, dir)
+			if tt.wantErr != "" {
+				if err == nil || errors.GetCode(err) != ErrInvalidTemplateOverrideCode || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("overrideTemplates() error = %v, want %s containing %q", err, ErrInvalidTemplateOverrideCode, tt.wantErr)
+				}
+				if !reflect.DeepEqual(operations["bookinfo"].Templates, []adapter.Template{template}) {
+					t.Errorf("overrideTemplates() failed but replaced the templates with %v", operations["bookinfo"].Templates)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("overrideTemplates() error = %v", err)
+			}
+			want := []adapter.Template{template}
+			if tt.overridden != "" {
+				for name := range tt.manifests {
+					want = []adapter.Template{adapter.Template("file://" + filepath.Join(dir, name))}
+				}
+			}
+			if got := operations["bookinfo"].Templates; !reflect.DeepEqual(got, want) {
+				t.Errorf("overrideTemplates() templates = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestManifestKinds(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		want     []string
+		wantErr  bool
+	}{
+		{name: "documents", manifest: deploymentManifest + "---\n" + serviceManifest, want: []string{"Deployment", "Service"}},
+		{name: "empty documents skipped", manifest: "---\n" + serviceManifest + "---\n", want: []string{"Service"}},
+		{name: "empty manifest", manifest: "", wantErr: true},
+		{name: "no apiVersion", manifest: "kind: Service\n", wantErr: true},
+		{name: "unparsable", manifest: "kind: [Service\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := manifestKinds([]byte(tt.manifest))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("manifestKinds() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("manifestKinds() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBundledKinds(t *testing.T) {
+	dir := t.TempDir()
+	writeManifests(t, dir, map[string]string{
+		"deployment.yaml": deploymentManifest,
+		"service.yaml":    serviceManifest + "---\n" + deploymentManifest,
+		"invalid.yaml":    "not: [valid",
+	})
+	bundled := func(name string) adapter.Template {
+		return adapter.Template("file://" + filepath.Join(dir, name))
+	}
+
+	tests := []struct {
+		name      string
+		templates []adapter.Template
+		want      map[string]bool
+	}{
+		{name: "bundled templates", templates: []adapter.Template{bundled("deployment.yaml"), bundled("service.yaml")}, want: map[string]bool{"Deployment": true, "Service": true}},
+		{name: "no templates"},
+		{name: "fetched template", templates: []adapter.Template{bundled("deployment.yaml"), "https://example.com/bookinfo.yaml"}},
+		{name: "missing template", templates: []adapter.Template{bundled("missing.yaml")}},
+		{name: "invalid template", templates: []adapter.Template{bundled("invalid.yaml")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bundledKinds(tt.templates); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("bundledKinds() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
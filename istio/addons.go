@@ -3,93 +3,421 @@ package istio
 import (
 	"context"
 	"fmt"
+	"net"
+	"net/http"
 	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/layer5io/meshery-adapter-library/adapter"
 	"github.com/layer5io/meshery-adapter-library/status"
 	"github.com/layer5io/meshkit/utils"
 	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
 )
 
+// addonManifestURL matches the addon manifests published in the istio
+// repository, the second group being the release or branch they are taken from
+var addonManifestURL = regexp.MustCompile(`^(https://raw\.githubusercontent\.com/istio/istio/)([^/]+)(/samples/addons/.+)$`)
+
+// releaseVersionRegex matches the major, minor and patch of a release
+var releaseVersionRegex = regexp.MustCompile(`^v?(\d+)\.(\d+)\.(\d+)$`)
+
+// pinAddonVersion returns the templates of the addon pointing to the manifests
+// of the given addon version, along with the resolved version.
+//
+// The addon manifests are released with istio, hence the version must be one
+// of the versions of the addon operation. The templates are returned as is,
+// with the version they are bundled with, if addonVersion is empty.
+func pinAddonVersion(templates []adapter.Template, versions []adapter.Version, addonVersion string) ([]adapter.Template, string, error) {
+	if addonVersion == "" {
+		for _, template := range templates {
+			if m := addonManifestURL.FindStringSubmatch(string(template)); m != nil {
+				return templates, m[2], nil
+			}
+		}
+		return templates, "", nil
+	}
+	if !utils.Contains[[]adapter.Version, adapter.Version](versions, adapter.Version(addonVersion)) {
+		return nil, "", ErrAddonVersion(addonVersion)
+	}
+
+	pinned := make([]adapter.Template, 0, len(templates))
+	for _, template := range templates {
+		pinned = append(pinned, adapter.Template(addonManifestURL.ReplaceAllString(string(template), "${1}"+addonVersion+"${3}")))
+	}
+	return pinned, addonVersion, nil
+}
+
+// addonManifestExists reports whether the addon manifest can be fetched, the
+// error being the one of the request when the repository can't be reached
+var addonManifestExists = func(manifest string) (bool, error) {
+	client := http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Head(manifest)
+	if err != nil {
+		return false, err
+	}
+	_ = resp.Body.Close()
+	return resp.StatusCode == http.StatusOK, nil
+}
+
+// locateAddonManifests returns the templates of the addon pointing to
+// manifests which exist, along with their version. The addon manifests of
+// some releases are missing, hence the ones of the nearest release of the
+// same major version are substituted then, the nearest minor and the latest
+// patch first, the older minor first among the equally near ones.
+// ErrAddonManifestNotFound is returned if no release has them.
+//
+// The templates are returned as is if the manifests can't be checked, such
+// as when the repository can't be reached, for the install to report why.
+func locateAddonManifests(templates []adapter.Template, versions []adapter.Version, version string) ([]adapter.Template, string, error) {
+	pinned := func(v string) []adapter.Template {
+		pinned := make([]adapter.Template, 0, len(templates))
+		for _, template := range templates {
+			pinned = append(pinned, adapter.Template(addonManifestURL.ReplaceAllString(string(template), "${1}"+v+"${3}")))
+		}
+		return pinned
+	}
+	exist := func(templates []adapter.Template) (bool, error) {
+		for _, template := range templates {
+			if !addonManifestURL.MatchString(string(template)) {
+				continue
+			}
+			ok, err := addonManifestExists(string(template))
+			if err != nil || !ok {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+
+	if version == "" {
+		return templates, version, nil
+	}
+	ok, err := exist(templates)
+	if err != nil || ok {
+		return templates, version, nil
+	}
+	for _, candidate := range nearestReleases(version, versions) {
+		if ok, err := exist(pinned(candidate)); err == nil && ok {
+			return pinned(candidate), candidate, nil
+		}
+	}
+	return nil, version, ErrAddonManifestNotFound(version)
+}
+
+// nearestReleases returns the latest patch of every other minor release of
+// the same major version, the nearest minor first and the older one first
+// among the equally near ones
+func nearestReleases(version string, versions []adapter.Version) []string {
+	parse := func(v string) (major, minor, patch int, ok bool) {
+		m := releaseVersionRegex.FindStringSubmatch(v)
+		if m == nil {
+			return 0, 0, 0, false
+		}
+		major, _ = strconv.Atoi(m[1])
+		minor, _ = strconv.Atoi(m[2])
+		patch, _ = strconv.Atoi(m[3])
+		return major, minor, patch, true
+	}
+	major, minor, _, ok := parse(version)
+	if !ok {
+		return nil
+	}
+	latest := map[int]int{}
+	names := map[int]string{}
+	for _, v := range versions {
+		vMajor, vMinor, vPatch, ok := parse(string(v))
+		if !ok || vMajor != major || string(v) == version {
+			continue
+		}
+		if _, seen := names[vMinor]; !seen || vPatch > latest[vMinor] {
+			latest[vMinor] = vPatch
+			names[vMinor] = string(v)
+		}
+	}
+	minors := make([]int, 0, len(names))
+	for m := range names {
+		minors = append(minors, m)
+	}
+	distance := func(m int) int {
+		if m < minor {
+			return minor - m
+		}
+		return m - minor
+	}
+	sort.Slice(minors, func(i, j int) bool {
+		if distance(minors[i]) != distance(minors[j]) {
+			return distance(minors[i]) < distance(minors[j])
+		}
+		return minors[i] < minors[j]
+	})
+	releases := make([]string, 0, len(minors))
+	for _, m := range minors {
+		releases = append(releases, names[m])
+	}
+	return releases
+}
+
+// addonWorkloads are the kinds of the core workload of an addon, the addon is
+// not installed when one of them fails to apply
+var addonWorkloads = map[string]bool{
+	"Deployment":  true,
+	"StatefulSet": true,
+	"DaemonSet":   true,
+}
+
+// addonResource is a resource of the manifest of an addon, the resources are
+// applied independently of each other
+type addonResource struct {
+	// Name is the kind and name of the resource, such as Deployment/grafana
+	Name string
+
+	// Core reports whether the resource is the workload of the addon
+	Core bool
+
+	Manifest string
+}
+
+// splitAddonManifest splits the multi-document manifest of an addon into its
+// resources. The documents which can't be read are returned as well, named
+// after their position, for their apply to report why.
+func splitAddonManifest(manifest string) []addonResource {
+	var resources []addonResource
+	for i, doc := range strings.Split(manifest, "\n---\n") {
+		if strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(doc), "---")) == "" {
+			continue
+		}
+		var object struct {
+			Kind     string `yaml:"kind"`
+			Metadata struct {
+				Name string `yaml:"name"`
+			} `yaml:"metadata"`
+		}
+		resource := addonResource{Name: fmt.Sprintf("document %d", i+1), Manifest: doc}
+		if err := yaml.Unmarshal([]byte(doc), &object); err == nil && object.Kind != "" {
+			resource.Name = fmt.Sprintf("%s/%s", object.Kind, object.Metadata.Name)
+			resource.Core = addonWorkloads[object.Kind]
+		}
+		resources = append(resources, resource)
+	}
+	return resources
+}
+
+// ignoredAddonError reports whether the failure to apply a resource of an
+// addon is expected and ignored altogether
+func ignoredAddonError(err error) bool {
+	// Specifically choosing to ignore kiali dashboard's error.
+	// Referring to: https://github.com/kiali/kiali/issues/3112
+	return strings.Contains(err.Error(), "no matches for kind \"MonitoringDashboard\" in version \"monitoring.kiali.io/v1alpha1\"") ||
+		strings.Contains(err.Error(), "clusterIP")
+}
+
 // installAddon installs/uninstalls an addon in the given namespace
 //
 // the template defines the manifest's link/location which needs to be used to
-// install the addon
-func (istio *Istio) installAddon(namespace string, del bool, service string, patches []string, templates []adapter.Template, kubeconfigs []string) (string, error) {
+// install the addon, progress is called once the addon is done on a cluster
+// if it isn't nil. Once installed, the endpoints the addon service is
+// accessible at are returned, one for each cluster. The manifests are read
+// for the extra metadata to be merged onto their resources and for their
+// images to be pulled from the hub, when set. Unless wait is set, the addon
+// is returned with statusSubmitted once applied, neither its rollout nor its
+// endpoints being waited for.
+//
+// Every resource of the manifests is applied on its own. Only the failure of
+// the workload of the addon fails the install, the failures of the other
+// resources and of the service patches are returned as warnings, one for
+// each resource and cluster, the addon being installed regardless.
+func (istio *Istio) installAddon(ctx context.Context, namespace string, del bool, service string, patches []string, templates []adapter.Template, metadata extraMetadata, hub string, wait bool, progress clusterProgress, kubeconfigs []string) (string, []string, []string, error) {
 	st := status.Installing
 
 	if del {
 		st = status.Removing
 	}
 
-	istio.Log.Debug(fmt.Sprintf("Overidden namespace: %s", namespace))
+	istio.log(ctx).Debug(fmt.Sprintf("Overidden namespace: %s", namespace))
 	namespace = "istio-system"
-	var wg sync.WaitGroup
-	var errMx sync.Mutex
-	var errs []error
-	for _, k8sconfig := range kubeconfigs {
-		wg.Add(1)
-		go func(k8sconfig string) {
-			defer wg.Done()
-			mclient, err := mesherykube.New([]byte(k8sconfig))
-			if err != nil {
-				errMx.Lock()
-				errs = append(errs, err)
-				errMx.Unlock()
-				return
+	var mx sync.Mutex
+	var endpoints, warnings []string
+	err := forEachCluster(kubeconfigs, func(k8sconfig string) error {
+		mclient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return err
+		}
+		cluster := clusterName(k8sconfig)
+		warn := func(name string, err error) {
+			istio.log(ctx).Warn(fmt.Errorf("%s of the addon on %s failed: %w", name, cluster, err))
+			mx.Lock()
+			warnings = append(warnings, fmt.Sprintf("%s: %s: %s", cluster, name, err))
+			mx.Unlock()
+		}
+		var errs []error
+		for _, template := range templates {
+			if err := ctx.Err(); err != nil {
+				return interrupted(ctx)
+			}
+			manifest := []byte(template.String())
+			if !del && (!metadata.empty() || hub != "") {
+				contents, err := utils.ReadFileSource(template.String())
+				if err != nil {
+					return mergeErrors(append(errs, err))
+				}
+				if manifest, err = mirrorImages([]byte(contents), hub); err != nil {
+					return mergeErrors(append(errs, err))
+				}
+				if manifest, err = metadata.manifest(manifest); err != nil {
+					return mergeErrors(append(errs, err))
+				}
 			}
-			for _, template := range templates {
-				err := istio.applyManifestOnSingleCluster([]byte(template.String()), del, namespace, mclient)
-				// Specifically choosing to ignore kiali dashboard's error.
-				// Referring to: https://github.com/kiali/kiali/issues/3112
-				if err != nil && !strings.Contains(err.Error(), "no matches for kind \"MonitoringDashboard\" in version \"monitoring.kiali.io/v1alpha1\"") {
-					if !strings.Contains(err.Error(), "clusterIP") {
-						errMx.Lock()
-						errs = append(errs, err)
-						errMx.Unlock()
-					}
+			for _, resource := range splitAddonManifest(string(manifest)) {
+				if err := ctx.Err(); err != nil {
+					return interrupted(ctx)
+				}
+				err := istio.applyManifestOnSingleCluster([]byte(resource.Manifest), del, namespace, mclient)
+				if err == nil || ignoredAddonError(err) {
+					continue
+				}
+				if !resource.Core {
+					warn(resource.Name, err)
+					continue
+				}
+				errs = append(errs, fmt.Errorf("%s: %w", resource.Name, err))
+			}
+		}
+
+		for _, patch := range patches {
+			if patch == "" {
+				continue //avoid throwing error when a given patch key didn't exist for a specific addon type in operations
+			}
+			if !del {
+				name := fmt.Sprintf("patch of Service/%s", service)
+				_, err := url.ParseRequestURI(patch)
+				if err != nil {
+					warn(name, err)
+					continue
+				}
+
+				content, err := utils.ReadFileSource(patch)
+				if err != nil {
+					warn(name, err)
+					continue
+				}
+
+				_, err = mclient.KubeClient.CoreV1().Services(namespace).Patch(ctx, service, types.MergePatchType, []byte(content), metav1.PatchOptions{})
+				if err != nil {
+					warn(name, err)
 				}
 			}
+		}
+		if len(errs) != 0 || del || service == "" || !wait {
+			return mergeErrors(errs)
+		}
+
+		endpoint, err := resolveAddonEndpoint(mclient.KubeClient, namespace, service)
+		if err != nil {
+			// The addon is installed regardless, hence the port-forward
+			// hint is reported instead
+			istio.log(ctx).Warn(err)
+		}
+		mx.Lock()
+		endpoints = append(endpoints, fmt.Sprintf("%s: %s", cluster, endpoint))
+		mx.Unlock()
+		return nil
+	}, progress)
+	sort.Strings(warnings)
+	if err != nil {
+		return st, nil, warnings, ErrAddonFromTemplate(err)
+	}
+	if !del && !wait {
+		return statusSubmitted, endpoints, warnings, nil
+	}
+	if !del {
+		var deployments []string
+		for _, template := range templates {
+			deployments = append(deployments, manifestDeployments(template.String())...)
+		}
+		if err := istio.waitForRollout(ctx, namespace, deployments, rolloutTimeout(ctx, addonRolloutTimeout), kubeconfigs); err != nil {
+			return st, endpoints, warnings, err
+		}
+	}
+	return status.Installed, endpoints, warnings, nil
+}
+
+// addonEndpointTimeout is how long the LoadBalancer addon services are waited
+// for to get an external IP or hostname
+var addonEndpointTimeout = 30 * time.Second
 
-			for _, patch := range patches {
-				if patch == "" {
-					continue //avoid throwing error when a given patch key didn't exist for a specific addon type in operations
+// resolveAddonEndpoint returns the endpoint the addon service is accessible
+// at. A port-forward hint is returned along with ErrAddonEndpointUnresolved if
+// the LoadBalancer service doesn't get an external address in time.
+func resolveAddonEndpoint(client kubernetes.Interface, namespace, service string) (string, error) {
+	deadline := time.Now().Add(addonEndpointTimeout)
+	for {
+		svc, err := client.CoreV1().Services(namespace).Get(context.TODO(), service, metav1.GetOptions{})
+		if err != nil {
+			return portForwardHint(namespace, service, 0), ErrAddonEndpointUnresolved(service, err)
+		}
+		if len(svc.Spec.Ports) == 0 {
+			return portForwardHint(namespace, service, 0), ErrAddonEndpointUnresolved(service, fmt.Errorf("service %s exposes no ports", service))
+		}
+		port := svc.Spec.Ports[0]
+
+		switch svc.Spec.Type {
+		case corev1.ServiceTypeLoadBalancer:
+			for _, ingress := range svc.Status.LoadBalancer.Ingress {
+				host := ingress.IP
+				if host == "" {
+					host = ingress.Hostname
 				}
-				if !del {
-					_, err := url.ParseRequestURI(patch)
-					if err != nil {
-						errMx.Lock()
-						errs = append(errs, err)
-						errMx.Unlock()
-						return
-					}
-
-					content, err := utils.ReadFileSource(patch)
-					if err != nil {
-						errMx.Lock()
-						errs = append(errs, err)
-						errMx.Unlock()
-						return
-					}
-
-					_, err = mclient.KubeClient.CoreV1().Services(namespace).Patch(context.TODO(), service, types.MergePatchType, []byte(content), metav1.PatchOptions{})
-					if err != nil {
-						errMx.Lock()
-						errs = append(errs, err)
-						errMx.Unlock()
-						return
-					}
+				if host != "" {
+					return fmt.Sprintf("http://%s", net.JoinHostPort(host, fmt.Sprint(port.Port))), nil
 				}
 			}
-		}(k8sconfig)
+			if time.Now().After(deadline) {
+				return portForwardHint(namespace, service, port.Port), ErrAddonEndpointUnresolved(service, fmt.Errorf("no external address assigned within %s", addonEndpointTimeout))
+			}
+			time.Sleep(2 * time.Second)
+			continue
+		case corev1.ServiceTypeNodePort:
+			if address := nodeAddress(client); address != "" {
+				return fmt.Sprintf("http://%s", net.JoinHostPort(address, fmt.Sprint(port.NodePort))), nil
+			}
+			return fmt.Sprintf("node port %d of any node", port.NodePort), nil
+		}
+		return fmt.Sprintf("http://%s.%s.svc.cluster.local:%d in cluster, or %s", service, namespace, port.Port, portForwardHint(namespace, service, port.Port)), nil
 	}
-	wg.Wait()
-	if len(errs) == 0 {
-		return status.Installed, nil
+}
+
+// nodeAddress returns the external, or else the internal, address of a node
+// of the cluster
+func nodeAddress(client kubernetes.Interface) string {
+	nodes, err := client.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{Limit: 1})
+	if err != nil || len(nodes.Items) == 0 {
+		return ""
+	}
+	var internal string
+	for _, address := range nodes.Items[0].Status.Addresses {
+		switch address.Type {
+		case corev1.NodeExternalIP:
+			return address.Address
+		case corev1.NodeInternalIP:
+			internal = address.Address
+		}
+	}
+	return internal
+}
+
+func portForwardHint(namespace, service string, port int32) string {
+	if port == 0 {
+		return fmt.Sprintf("kubectl port-forward -n %s svc/%s <local-port>:<service-port>", namespace, service)
 	}
-	return st, ErrAddonFromTemplate(mergeErrors(errs))
+	return fmt.Sprintf("kubectl port-forward -n %s svc/%s %d:%d", namespace, service, port, port)
 }
@@ -1,10 +1,19 @@
 package istio
 
 import (
+	"context"
+	"fmt"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/layer5io/meshery-adapter-library/adapter"
 	"github.com/layer5io/meshery-adapter-library/status"
+	"github.com/layer5io/meshkit/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
 )
 
 func TestIstio_installAddon(t *testing.T) {
@@ -71,7 +80,7 @@ func TestIstio_installAddon(t *testing.T) {
 					Log:    getLoggerHandler(t),
 				},
 			}
-			got, err := istio.installAddon(tt.args.namespace, tt.args.del, tt.args.service, tt.args.patches, tt.args.templates, tt.kubeconfigs)
+			got, _, _, err := istio.installAddon(context.Background(), tt.args.namespace, tt.args.del, tt.args.service, tt.args.patches, tt.args.templates, extraMetadata{}, "", true, nil, tt.kubeconfigs)
 			if (err != nil) == tt.wantErr {
 				t.Errorf("Istio.installAddon() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -82,3 +91,233 @@ func TestIstio_installAddon(t *testing.T) {
 		})
 	}
 }
+
+func TestPinAddonVersion(t *testing.T) {
+	templates := []adapter.Template{
+		"https://raw.githubusercontent.com/istio/istio/master/samples/addons/kiali.yaml",
+		"file://templates/patches/service-loadbalancer.json",
+	}
+	versions := []adapter.Version{"1.19.3", "1.20.0"}
+
+	tests := []struct {
+		name          string
+		addonVersion  string
+		wantTemplates []adapter.Template
+		wantVersion   string
+		wantErr       bool
+	}{
+		{
+			name:          "bundled version",
+			wantTemplates: templates,
+			wantVersion:   "master",
+		},
+		{
+			name:         "pinned version",
+			addonVersion: "1.19.3",
+			wantTemplates: []adapter.Template{
+				"https://raw.githubusercontent.com/istio/istio/1.19.3/samples/addons/kiali.yaml",
+				"file://templates/patches/service-loadbalancer.json",
+			},
+			wantVersion: "1.19.3",
+		},
+		{
+			name:         "unavailable version",
+			addonVersion: "0.1.0",
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, version, err := pinAddonVersion(templates, versions, tt.addonVersion)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("pinAddonVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.wantTemplates) {
+				t.Errorf("pinAddonVersion() templates = %v, want %v", got, tt.wantTemplates)
+			}
+			if version != tt.wantVersion {
+				t.Errorf("pinAddonVersion() version = %s, want %s", version, tt.wantVersion)
+			}
+		})
+	}
+}
+
+func TestLocateAddonManifests(t *testing.T) {
+	templates := func(version string) []adapter.Template {
+		return []adapter.Template{
+			adapter.Template("https://raw.githubusercontent.com/istio/istio/" + version + "/samples/addons/kiali.yaml"),
+			"file://templates/patches/service-loadbalancer.json",
+		}
+	}
+	versions := []adapter.Version{"1.18.7", "1.19.0", "1.19.3", "1.20.0", "1.21.1", "1.22.0", "2.0.0"}
+
+	tests := []struct {
+		name        string
+		version     string
+		published   map[string]bool
+		unreachable bool
+		wantVersion string
+		wantErr     bool
+	}{
+		{
+			name:        "published",
+			version:     "1.20.0",
+			published:   map[string]bool{"1.20.0": true},
+			wantVersion: "1.20.0",
+		},
+		{
+			name:        "nearest older minor",
+			version:     "1.20.0",
+			published:   map[string]bool{"1.19.3": true, "1.21.1": true, "1.18.7": true},
+			wantVersion: "1.19.3",
+		},
+		{
+			name:        "nearest newer minor",
+			version:     "1.20.0",
+			published:   map[string]bool{"1.21.1": true, "1.18.7": true, "2.0.0": true},
+			wantVersion: "1.21.1",
+		},
+		{
+			name:        "unreachable",
+			version:     "1.20.0",
+			unreachable: true,
+			wantVersion: "1.20.0",
+		},
+		{
+			name:      "none",
+			version:   "1.20.0",
+			published: map[string]bool{"2.0.0": true},
+			wantErr:   true,
+		},
+	}
+	defer func(exists func(string) (bool, error)) { addonManifestExists = exists }(addonManifestExists)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addonManifestExists = func(manifest string) (bool, error) {
+				if tt.unreachable {
+					return false, fmt.Errorf("dial tcp: lookup raw.githubusercontent.com: no such host")
+				}
+				m := addonManifestURL.FindStringSubmatch(manifest)
+				return m != nil && tt.published[m[2]], nil
+			}
+			got, version, err := locateAddonManifests(templates(tt.version), versions, tt.version)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("locateAddonManifests() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if errors.GetCode(err) != ErrAddonManifestNotFoundCode {
+					t.Errorf("locateAddonManifests() error code = %s, want %s", errors.GetCode(err), ErrAddonManifestNotFoundCode)
+				}
+				return
+			}
+			if version != tt.wantVersion || !reflect.DeepEqual(got, templates(tt.wantVersion)) {
+				t.Errorf("locateAddonManifests() = %v, %s, want the manifests of %s", got, version, tt.wantVersion)
+			}
+		})
+	}
+}
+
+func TestSplitAddonManifest(t *testing.T) {
+	manifest := `---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: grafana
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: grafana
+---
+apiVersion: monitoring.kiali.io/v1alpha1
+kind: MonitoringDashboard
+metadata:
+  name: envoy
+---
+: not yaml
+---
+`
+	got := splitAddonManifest(manifest)
+	var names []string
+	var core []string
+	for _, r := range got {
+		names = append(names, r.Name)
+		if r.Core {
+			core = append(core, r.Name)
+		}
+	}
+	if want := []string{"ServiceAccount/grafana", "Deployment/grafana", "MonitoringDashboard/envoy", "document 4"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("splitAddonManifest() = %v, want %v", names, want)
+	}
+	if want := []string{"Deployment/grafana"}; !reflect.DeepEqual(core, want) {
+		t.Errorf("splitAddonManifest() core = %v, want %v", core, want)
+	}
+}
+
+func TestResolveAddonEndpoint(t *testing.T) {
+	defer func(d time.Duration) { addonEndpointTimeout = d }(addonEndpointTimeout)
+	addonEndpointTimeout = 0
+
+	service := func(svcType corev1.ServiceType, ingress ...corev1.LoadBalancerIngress) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "kiali", Namespace: "istio-system"},
+			Spec: corev1.ServiceSpec{
+				Type:  svcType,
+				Ports: []corev1.ServicePort{{Port: 20001, NodePort: 31000}},
+			},
+			Status: corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{Ingress: ingress}},
+		}
+	}
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node"},
+		Status: corev1.NodeStatus{Addresses: []corev1.NodeAddress{
+			{Type: corev1.NodeInternalIP, Address: "10.0.0.1"},
+			{Type: corev1.NodeExternalIP, Address: "203.0.113.1"},
+		}},
+	}
+
+	tests := []struct {
+		name    string
+		objects []runtime.Object
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "load balancer",
+			objects: []runtime.Object{service(corev1.ServiceTypeLoadBalancer, corev1.LoadBalancerIngress{Hostname: "kiali.example.com"})},
+			want:    "http://kiali.example.com:20001",
+		},
+		{
+			name:    "pending load balancer",
+			objects: []runtime.Object{service(corev1.ServiceTypeLoadBalancer)},
+			want:    "kubectl port-forward -n istio-system svc/kiali 20001:20001",
+			wantErr: true,
+		},
+		{
+			name:    "node port",
+			objects: []runtime.Object{service(corev1.ServiceTypeNodePort), node},
+			want:    "http://203.0.113.1:31000",
+		},
+		{
+			name:    "cluster ip",
+			objects: []runtime.Object{service(corev1.ServiceTypeClusterIP)},
+			want:    "http://kiali.istio-system.svc.cluster.local:20001 in cluster, or kubectl port-forward -n istio-system svc/kiali 20001:20001",
+		},
+		{
+			name:    "missing service",
+			want:    "kubectl port-forward -n istio-system svc/kiali <local-port>:<service-port>",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveAddonEndpoint(fake.NewSimpleClientset(tt.objects...), "istio-system", "kiali")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveAddonEndpoint() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolveAddonEndpoint() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
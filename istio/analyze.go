@@ -0,0 +1,103 @@
+package istio
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+)
+
+// Levels of the istioctl analyze messages
+const (
+	AnalyzerLevelError   = "Error"
+	AnalyzerLevelWarning = "Warning"
+	AnalyzerLevelInfo    = "Info"
+)
+
+// AnalyzerMessage is a diagnostic reported by istioctl analyze
+type AnalyzerMessage struct {
+	Cluster          string `json:"cluster"`
+	Code             string `json:"code"`
+	Level            string `json:"level"`
+	Origin           string `json:"origin,omitempty"`
+	Reference        string `json:"reference,omitempty"`
+	Message          string `json:"message"`
+	DocumentationURL string `json:"documentationUrl,omitempty"`
+}
+
+func (m AnalyzerMessage) String() string {
+	if m.Origin == "" {
+		return fmt.Sprintf("%s [%s] %s", m.Level, m.Code, m.Message)
+	}
+	return fmt.Sprintf("%s [%s] (%s) %s", m.Level, m.Code, m.Origin, m.Message)
+}
+
+// runAnalyze runs istioctl analyze of the given version on the namespace of
+// every cluster, or on all the namespaces if namespace is empty, and returns
+// the diagnostics of all the clusters.
+//
+// The diagnostics of the clusters which could be analyzed are returned along
+// with ErrIstioAnalyzeFailed if istioctl failed to run on any cluster.
+func (istio *Istio) runAnalyze(version, namespace string, kubeConfigs []string) ([]AnalyzerMessage, error) {
+	executable, err := istio.getExecutable(version)
+	if err != nil {
+		return nil, ErrIstioAnalyzeFailed(err)
+	}
+
+	var mx sync.Mutex
+	var messages []AnalyzerMessage
+	err = forEachCluster(kubeConfigs, func(k8sconfig string) error {
+		kClient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return err
+		}
+		kContext, err := kClient.GetCurrentContext()
+		if err != nil {
+			return err
+		}
+
+		args := []string{"analyze", "-o", "json", "--context", kContext}
+		if namespace == "" {
+			args = append(args, "--all-namespaces")
+		} else {
+			args = append(args, "--namespace", namespace)
+		}
+		// istioctl exits with an error when it reports error level
+		// diagnostics, hence the output is parsed regardless of the error
+		out, runErr := runIstioctlOnCluster(executable, k8sconfig, args...)
+		msgs, err := parseAnalyzerMessages(out)
+		if err != nil {
+			if runErr != nil {
+				return runErr
+			}
+			return err
+		}
+		cluster := clusterName(k8sconfig)
+		for i := range msgs {
+			msgs[i].Cluster = cluster
+		}
+		mx.Lock()
+		messages = append(messages, msgs...)
+		mx.Unlock()
+		return nil
+	}, nil)
+	if err != nil {
+		return messages, ErrIstioAnalyzeFailed(err)
+	}
+	return messages, nil
+}
+
+// parseAnalyzerMessages parses the JSON output of istioctl analyze
+func parseAnalyzerMessages(out string) ([]AnalyzerMessage, error) {
+	out = strings.TrimSpace(out)
+	if out == "" {
+		return nil, fmt.Errorf("istioctl analyze generated no output")
+	}
+	var messages []AnalyzerMessage
+	if err := json.Unmarshal([]byte(out), &messages); err != nil {
+		return nil, fmt.Errorf("invalid istioctl analyze output: %w", err)
+	}
+	return messages, nil
+}
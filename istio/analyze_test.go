@@ -0,0 +1,55 @@
+package istio
+
+import "testing"
+
+func TestParseAnalyzerMessages(t *testing.T) {
+	tests := []struct {
+		name    string
+		out     string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "no diagnostics",
+			out:  "[]\n",
+			want: nil,
+		},
+		{
+			name: "mixed levels",
+			out: `[
+  {"code": "IST0101", "level": "Error", "origin": "VirtualService bookinfo/reviews", "message": "Referenced host not found: \"reviews.bookinfo\""},
+  {"code": "IST0102", "level": "Info", "origin": "Namespace default", "message": "The namespace is not enabled for Istio injection."}
+]`,
+			want: []string{
+				`Error [IST0101] (VirtualService bookinfo/reviews) Referenced host not found: "reviews.bookinfo"`,
+				"Info [IST0102] (Namespace default) The namespace is not enabled for Istio injection.",
+			},
+		},
+		{
+			name:    "empty output",
+			out:     "",
+			wantErr: true,
+		},
+		{
+			name:    "istioctl failure output",
+			out:     "Error: context \"kind\" does not exist",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseAnalyzerMessages(tt.out)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseAnalyzerMessages() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseAnalyzerMessages() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i].String() != tt.want[i] {
+					t.Errorf("parseAnalyzerMessages()[%d] = %s, want %s", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
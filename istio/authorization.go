@@ -0,0 +1,169 @@
+package istio
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/layer5io/meshery-istio/internal/config"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+var (
+	validAuthorizationActions = map[string]bool{
+		"ALLOW": true,
+		"DENY":  true,
+	}
+
+	// The fields of the sources and operations of the AuthorizationPolicy
+	// rules, all of them are lists of strings
+	authorizationSourceFields = map[string]bool{
+		"principals": true, "notPrincipals": true,
+		"requestPrincipals": true, "notRequestPrincipals": true,
+		"namespaces": true, "notNamespaces": true,
+		"ipBlocks": true, "notIpBlocks": true,
+		"remoteIpBlocks": true, "notRemoteIpBlocks": true,
+	}
+	authorizationOperationFields = map[string]bool{
+		"hosts": true, "notHosts": true,
+		"ports": true, "notPorts": true,
+		"methods": true, "notMethods": true,
+		"paths": true, "notPaths": true,
+	}
+)
+
+// authorizationRule is a rule of an AuthorizationPolicy, a request matches
+// the rule if it matches any of the sources, any of the operations and all
+// the conditions
+type authorizationRule struct {
+	From []authorizationSource `json:"from,omitempty"`
+	To   []struct {
+		Operation map[string][]string `json:"operation"`
+	} `json:"to,omitempty"`
+	When []struct {
+		Key       string   `json:"key"`
+		Values    []string `json:"values,omitempty"`
+		NotValues []string `json:"notValues,omitempty"`
+	} `json:"when,omitempty"`
+}
+
+// authorizationSource is a source of the requests of a rule, matching the
+// requests which match all of its fields
+type authorizationSource struct {
+	Source map[string][]string `json:"source"`
+}
+
+// authorizationPolicyValues are the values of the AuthorizationPolicy template
+type authorizationPolicyValues struct {
+	Name      string
+	Namespace string
+	Action    string
+	Selector  map[string]string
+	// Rules are the rules marshaled to JSON, which is valid YAML
+	Rules string
+}
+
+// newAuthorizationPolicyValues validates the AuthorizationPolicy properties of
+// the operation.
+//
+// The rules are passed as a JSON list of rules with the from, to and when
+// fields of the AuthorizationPolicy API. The policy is named after the
+// policy-name property, or else after the workloads of its selector. Deleting
+// a policy only needs its name, so the action and rules are not validated then.
+func newAuthorizationPolicyValues(namespace string, props map[string]string, del bool) (*authorizationPolicyValues, error) {
+	values := &authorizationPolicyValues{
+		Name:      strings.TrimSpace(props[config.PolicyName]),
+		Namespace: namespace,
+		Action:    strings.ToUpper(strings.TrimSpace(props[config.PolicyAction])),
+	}
+
+	selector, err := parseWorkloadSelector(props[config.WorkloadSelector])
+	if err != nil {
+		return nil, ErrInvalidAuthorizationPolicy(err)
+	}
+	values.Selector = selector
+	if values.Name == "" {
+		values.Name = "namespace-authz"
+		if len(selector) > 0 {
+			values.Name = workloadName(selector) + "-authz"
+		}
+	}
+	if errs := validation.IsDNS1123Subdomain(values.Name); len(errs) > 0 {
+		return nil, ErrInvalidAuthorizationPolicy(fmt.Errorf("invalid policy name %q: %s", values.Name, strings.Join(errs, ", ")))
+	}
+	if del {
+		return values, nil
+	}
+
+	if !validAuthorizationActions[values.Action] {
+		return nil, ErrInvalidAuthorizationPolicy(fmt.Errorf("action %q is not one of ALLOW or DENY", values.Action))
+	}
+	rules, err := parseAuthorizationRules(props[config.PolicyRules])
+	if err != nil {
+		return nil, ErrInvalidAuthorizationPolicy(err)
+	}
+	// An ALLOW policy without rules denies every request, while a DENY
+	// policy without rules denies none of them
+	if len(rules) == 0 && values.Action == "DENY" {
+		return nil, ErrInvalidAuthorizationPolicy(fmt.Errorf("a DENY policy needs at least one rule"))
+	}
+	if len(rules) > 0 {
+		contents, err := json.Marshal(rules)
+		if err != nil {
+			return nil, ErrInvalidAuthorizationPolicy(err)
+		}
+		values.Rules = string(contents)
+	}
+	return values, nil
+}
+
+// parseAuthorizationRules parses and validates the JSON list of rules, the
+// unknown fields are rejected so that a typo doesn't widen the policy
+func parseAuthorizationRules(rules string) ([]authorizationRule, error) {
+	if strings.TrimSpace(rules) == "" {
+		return nil, nil
+	}
+	var parsed []authorizationRule
+	decoder := json.NewDecoder(bytes.NewBufferString(rules))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("invalid rules %q: %w", rules, err)
+	}
+	for i, rule := range parsed {
+		for _, from := range rule.From {
+			if err := checkRuleFields(from.Source, authorizationSourceFields); err != nil {
+				return nil, fmt.Errorf("rule %d source: %w", i, err)
+			}
+		}
+		for _, to := range rule.To {
+			if err := checkRuleFields(to.Operation, authorizationOperationFields); err != nil {
+				return nil, fmt.Errorf("rule %d operation: %w", i, err)
+			}
+		}
+		for _, when := range rule.When {
+			if when.Key == "" {
+				return nil, fmt.Errorf("rule %d condition: key is required", i)
+			}
+			if len(when.Values) == 0 && len(when.NotValues) == 0 {
+				return nil, fmt.Errorf("rule %d condition %s: values or notValues are required", i, when.Key)
+			}
+		}
+	}
+	return parsed, nil
+}
+
+func checkRuleFields(fields map[string][]string, known map[string]bool) error {
+	if len(fields) == 0 {
+		return fmt.Errorf("no field is set")
+	}
+	for field, values := range fields {
+		if !known[field] {
+			return fmt.Errorf("unknown field %q", field)
+		}
+		if len(values) == 0 {
+			return fmt.Errorf("%s is empty", field)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package istio
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/layer5io/meshery-istio/internal/config"
+	"gopkg.in/yaml.v2"
+)
+
+func TestAuthorizationPolicy(t *testing.T) {
+	tmpl, err := os.ReadFile("../templates/policies/authorization.yaml")
+	if err != nil {
+		t.Fatalf("unable to read the policy template: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		props        map[string]string
+		del          bool
+		wantName     string
+		wantAction   string
+		wantSelector map[string]string
+		wantRules    int
+		wantErr      bool
+	}{
+		{
+			name:       "allow without rules denies everything",
+			props:      map[string]string{config.PolicyAction: "allow"},
+			wantName:   "namespace-authz",
+			wantAction: "ALLOW",
+		},
+		{
+			name: "deny rules for a workload",
+			props: map[string]string{
+				config.PolicyAction:     "DENY",
+				config.WorkloadSelector: "app=ratings",
+				config.PolicyRules:      `[{"from": [{"source": {"notNamespaces": ["bookinfo"]}}], "to": [{"operation": {"methods": ["POST"]}}], "when": [{"key": "request.headers[x-user]", "values": ["guest"]}]}]`,
+			},
+			wantName:     "ratings-authz",
+			wantAction:   "DENY",
+			wantSelector: map[string]string{"app": "ratings"},
+			wantRules:    1,
+		},
+		{
+			name: "named policy",
+			props: map[string]string{
+				config.PolicyName:   "allow-frontend",
+				config.PolicyAction: "ALLOW",
+				config.PolicyRules:  `[{"from": [{"source": {"namespaces": ["frontend"]}}]}, {"to": [{"operation": {"paths": ["/healthz"]}}]}]`,
+			},
+			wantName:   "allow-frontend",
+			wantAction: "ALLOW",
+			wantRules:  2,
+		},
+		{
+			name:     "delete does not need an action",
+			props:    map[string]string{config.PolicyName: "allow-frontend"},
+			del:      true,
+			wantName: "allow-frontend",
+		},
+		{
+			name:    "invalid action",
+			props:   map[string]string{config.PolicyAction: "AUDIT"},
+			wantErr: true,
+		},
+		{
+			name:    "deny without rules",
+			props:   map[string]string{config.PolicyAction: "DENY"},
+			wantErr: true,
+		},
+		{
+			name: "unknown source field",
+			props: map[string]string{
+				config.PolicyAction: "ALLOW",
+				config.PolicyRules:  `[{"from": [{"source": {"namespace": ["frontend"]}}]}]`,
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown rule field",
+			props: map[string]string{
+				config.PolicyAction: "ALLOW",
+				config.PolicyRules:  `[{"form": [{"source": {"namespaces": ["frontend"]}}]}]`,
+			},
+			wantErr: true,
+		},
+		{
+			name: "condition without values",
+			props: map[string]string{
+				config.PolicyAction: "ALLOW",
+				config.PolicyRules:  `[{"when": [{"key": "source.ip"}]}]`,
+			},
+			wantErr: true,
+		},
+		{
+			name:    "invalid name",
+			props:   map[string]string{config.PolicyName: "Allow_All", config.PolicyAction: "ALLOW"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := newAuthorizationPolicyValues("bookinfo", tt.props, tt.del)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newAuthorizationPolicyValues() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			rendered, err := renderTemplate(string(tmpl), values)
+			if err != nil {
+				t.Fatalf("renderTemplate() error = %v", err)
+			}
+			var policy struct {
+				Metadata struct {
+					Name      string `yaml:"name"`
+					Namespace string `yaml:"namespace"`
+				} `yaml:"metadata"`
+				Spec struct {
+					Selector struct {
+						MatchLabels map[string]string `yaml:"matchLabels"`
+					} `yaml:"selector"`
+					Action string        `yaml:"action"`
+					Rules  []interface{} `yaml:"rules"`
+				} `yaml:"spec"`
+			}
+			if err := yaml.Unmarshal([]byte(rendered), &policy); err != nil {
+				t.Fatalf("renderTemplate() generated invalid YAML: %v\n%s", err, rendered)
+			}
+			if policy.Metadata.Name != tt.wantName || policy.Metadata.Namespace != "bookinfo" {
+				t.Errorf("renderTemplate() name/namespace = %s/%s, want %s/bookinfo", policy.Metadata.Name, policy.Metadata.Namespace, tt.wantName)
+			}
+			if policy.Spec.Action != tt.wantAction {
+				t.Errorf("renderTemplate() action = %s, want %s", policy.Spec.Action, tt.wantAction)
+			}
+			if !reflect.DeepEqual(policy.Spec.Selector.MatchLabels, tt.wantSelector) {
+				t.Errorf("renderTemplate() selector = %v, want %v", policy.Spec.Selector.MatchLabels, tt.wantSelector)
+			}
+			if len(policy.Spec.Rules) != tt.wantRules {
+				t.Errorf("renderTemplate() rules = %v, want %d rules", policy.Spec.Rules, tt.wantRules)
+			}
+		})
+	}
+}
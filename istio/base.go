@@ -0,0 +1,154 @@
+package istio
+
+import (
+	"context"
+	"path"
+	"strconv"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/layer5io/meshery-adapter-library/status"
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	kubeerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// baseCRDs are the CRDs of the base chart istiod can't run without
+var baseCRDs = []string{
+	"virtualservices.networking.istio.io",
+	"destinationrules.networking.istio.io",
+	"gateways.networking.istio.io",
+	"serviceentries.networking.istio.io",
+	"sidecars.networking.istio.io",
+	"peerauthentications.security.istio.io",
+	"authorizationpolicies.security.istio.io",
+	"requestauthentications.security.istio.io",
+	"telemetries.telemetry.istio.io",
+}
+
+// crdVersionLabels are the labels the base chart and istioctl record the
+// Istio version of the CRDs in
+var crdVersionLabels = []string{"app.kubernetes.io/version", "operator.istio.io/version"}
+
+// installBase installs or removes the Istio base chart alone, the CRDs and
+// the cluster roles, for the control plane to be installed later on. The
+// removal is refused with ErrBaseStillInUse on the clusters istiod still
+// runs on, the CRDs being removed along with all the resources of their
+// kinds.
+func (istio *Istio) installBase(ctx context.Context, del bool, version string, kubeconfigs []string) (string, error) {
+	st := status.Installing
+	if del {
+		st = status.Removing
+	}
+
+	var spec adapter.Spec
+	err := istio.Config.GetObject(adapter.MeshSpecKey, &spec)
+	if err != nil {
+		return st, ErrMeshConfig(err)
+	}
+	if del {
+		err := forEachCluster(kubeconfigs, func(k8sconfig string) error {
+			mclient, err := mesherykube.New([]byte(k8sconfig))
+			if err != nil {
+				return err
+			}
+			running, err := istiodDeployments(ctx, mclient.KubeClient)
+			if err != nil {
+				return err
+			}
+			if len(running) != 0 {
+				return ErrBaseStillInUse(clusterName(k8sconfig), running)
+			}
+			return nil
+		}, nil)
+		if err != nil {
+			return st, err
+		}
+	}
+
+	dirName, err := istio.getIstioRelease(version)
+	if err != nil {
+		return st, ErrGettingIstioRelease(err)
+	}
+	act := mesherykube.INSTALL
+	if del {
+		act = mesherykube.UNINSTALL
+	}
+	err = forEachCluster(kubeconfigs, func(k8sconfig string) error {
+		mclient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return err
+		}
+		return mclient.ApplyHelmChart(mesherykube.ApplyHelmChartConfig{
+			LocalPath:       path.Join(downloadLocation, dirName, "manifests/charts/base"),
+			Namespace:       istioRootNamespace,
+			Action:          act,
+			CreateNamespace: true,
+		})
+	}, nil)
+	if err != nil {
+		return st, ErrApplyHelmChart(err)
+	}
+
+	if del {
+		return status.Removed, nil
+	}
+	return status.Installed, nil
+}
+
+// istiodDeployments returns the istiod deployments of istio-system, of all
+// the revisions
+func istiodDeployments(ctx context.Context, client kubernetes.Interface) ([]string, error) {
+	deployments, err := client.AppsV1().Deployments(istioRootNamespace).List(ctx, metav1.ListOptions{LabelSelector: "app=istiod"})
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, deployment := range deployments.Items {
+		names = append(names, deployment.Name)
+	}
+	return names, nil
+}
+
+// baseInstalled reports whether all the base CRDs are installed at a version
+// compatible with the requested one, the CRDs of an unknown version being
+// reinstalled
+func baseInstalled(ctx context.Context, dyn dynamic.Interface, version string) (bool, error) {
+	for _, name := range baseCRDs {
+		crd, err := dyn.Resource(crdResource).Get(ctx, name, metav1.GetOptions{})
+		if kubeerror.IsNotFound(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		installed := ""
+		for _, label := range crdVersionLabels {
+			if v := crd.GetLabels()[label]; v != "" {
+				installed = v
+				break
+			}
+		}
+		if !crdsCompatible(installed, version) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// crdsCompatible reports whether the CRDs of the installed version serve the
+// requested one. The CRDs only ever add fields and versions, hence the CRDs
+// of the same or a later minor of the same major are compatible.
+func crdsCompatible(installed, requested string) bool {
+	i := minorVersionRegex.FindStringSubmatch(installed)
+	r := minorVersionRegex.FindStringSubmatch(requested)
+	if i == nil || r == nil {
+		return false
+	}
+	iMajor, _ := strconv.Atoi(i[1])
+	iMinor, _ := strconv.Atoi(i[2])
+	rMajor, _ := strconv.Atoi(r[1])
+	rMinor, _ := strconv.Atoi(r[2])
+	return iMajor == rMajor && iMinor >= rMinor
+}
@@ -0,0 +1,107 @@
+package istio
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCRDsCompatible(t *testing.T) {
+	tests := []struct {
+		installed, requested string
+		want                 bool
+	}{
+		{installed: "1.24.0", requested: "1.24.3", want: true},
+		{installed: "1.25.1", requested: "v1.24.0", want: true},
+		{installed: "1.23.2", requested: "1.24.0", want: false},
+		{installed: "2.0.0", requested: "1.24.0", want: false},
+		{installed: "", requested: "1.24.0", want: false},
+	}
+	for _, tt := range tests {
+		if got := crdsCompatible(tt.installed, tt.requested); got != tt.want {
+			t.Errorf("crdsCompatible(%q, %q) = %v, want %v", tt.installed, tt.requested, got, tt.want)
+		}
+	}
+}
+
+func TestBaseInstalled(t *testing.T) {
+	crds := func(labels map[string]interface{}, names ...string) []runtime.Object {
+		var objects []runtime.Object
+		for _, name := range names {
+			objects = append(objects, &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "apiextensions.k8s.io/v1",
+				"kind":       "CustomResourceDefinition",
+				"metadata":   map[string]interface{}{"name": name, "labels": labels},
+			}})
+		}
+		return objects
+	}
+	tests := []struct {
+		name    string
+		objects []runtime.Object
+		want    bool
+	}{
+		{
+			name:    "compatible",
+			objects: crds(map[string]interface{}{"app.kubernetes.io/version": "1.24.1"}, baseCRDs...),
+			want:    true,
+		},
+		{
+			name:    "installed by istioctl",
+			objects: crds(map[string]interface{}{"operator.istio.io/version": "1.25.0"}, baseCRDs...),
+			want:    true,
+		},
+		{
+			name:    "older",
+			objects: crds(map[string]interface{}{"app.kubernetes.io/version": "1.23.0"}, baseCRDs...),
+		},
+		{
+			name:    "unknown version",
+			objects: crds(map[string]interface{}{}, baseCRDs...),
+		},
+		{
+			name:    "missing",
+			objects: crds(map[string]interface{}{"app.kubernetes.io/version": "1.24.1"}, baseCRDs[1:]...),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+				crdResource: "CustomResourceDefinitionList",
+			}, tt.objects...)
+			got, err := baseInstalled(context.Background(), dyn, "1.24.0")
+			if err != nil {
+				t.Fatalf("baseInstalled() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("baseInstalled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIstiodDeployments(t *testing.T) {
+	deployment := func(name string, labels map[string]string) *appsv1.Deployment {
+		return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: istioRootNamespace, Labels: labels}}
+	}
+	client := fake.NewSimpleClientset(
+		deployment("istiod", map[string]string{"app": "istiod"}),
+		deployment("istiod-canary", map[string]string{"app": "istiod", "istio.io/rev": "canary"}),
+		deployment("istio-ingressgateway", map[string]string{"app": "istio-ingressgateway"}),
+	)
+	got, err := istiodDeployments(context.Background(), client)
+	if err != nil {
+		t.Fatalf("istiodDeployments() error = %v", err)
+	}
+	if want := []string{"istiod", "istiod-canary"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("istiodDeployments() = %q, want %q", got, want)
+	}
+}
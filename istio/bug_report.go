@@ -0,0 +1,116 @@
+package istio
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+)
+
+// bugReportArchive is the name of the archive istioctl bug-report creates in
+// its working directory
+const bugReportArchive = "bug-report.tar.gz"
+
+// bugReportArchiveRegex matches the line istioctl bug-report prints the path
+// of the archive it created on
+var bugReportArchiveRegex = regexp.MustCompile(`(?m)Creating an archive at (\S+)`)
+
+// BugReport is the archive of the diagnostics of a single cluster, the logs
+// of the control plane and the proxies, their configuration and the state of
+// the cluster
+type BugReport struct {
+	Cluster string
+	Archive string
+}
+
+// collectBugReports runs istioctl bug-report of the given version on every
+// cluster, the report being scoped to the namespace unless it is empty. Every
+// report is run in its own directory of the download location, where its
+// archive is kept. ErrBugReportFailed is returned along with the archives of
+// the clusters the report succeeded on if it failed on any cluster.
+func (istio *Istio) collectBugReports(version, namespace string, kubeConfigs []string) ([]BugReport, error) {
+	executable, err := istio.getExecutable(version)
+	if err != nil {
+		return nil, ErrBugReportFailed(err)
+	}
+
+	var mx sync.Mutex
+	var reports []BugReport
+	err = forEachCluster(kubeConfigs, func(k8sconfig string) error {
+		kClient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return err
+		}
+		kContext, err := kClient.GetCurrentContext()
+		if err != nil {
+			return err
+		}
+		kubeconfig, err := writeKubeconfigFile(k8sconfig)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(kubeconfig)
+		dir, err := os.MkdirTemp(downloadLocation, "istio-bug-report-*")
+		if err != nil {
+			return err
+		}
+		// We need a variable executable here hence using nosec
+		// #nosec
+		command := exec.Command(executable, append(bugReportArgs(kContext, namespace), "--kubeconfig", kubeconfig)...)
+		command.Dir = dir
+		var out, er bytes.Buffer
+		command.Stdout = &out
+		command.Stderr = &er
+		if err := command.Run(); err != nil {
+			_ = os.RemoveAll(dir)
+			return fmt.Errorf("%w: %s", err, strings.TrimSpace(er.String()))
+		}
+		mx.Lock()
+		reports = append(reports, BugReport{
+			Cluster: clusterName(k8sconfig),
+			Archive: bugReportArchivePath(out.String()+er.String(), dir),
+		})
+		mx.Unlock()
+		return nil
+	}, nil)
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].Cluster < reports[j].Cluster
+	})
+	if err != nil {
+		return reports, ErrBugReportFailed(err)
+	}
+	return reports, nil
+}
+
+// bugReportArgs are the istioctl arguments of the report of the cluster. The
+// control plane namespace is always part of the report, the namespace only
+// scopes the workloads whose logs and proxy configuration are collected.
+func bugReportArgs(kContext, namespace string) []string {
+	args := []string{"bug-report", "--context", kContext}
+	if namespace != "" {
+		args = append(args, "--include", namespace)
+	}
+	return args
+}
+
+// bugReportArchivePath returns the path of the archive istioctl printed, the
+// archive of the working directory of the report if it printed none. The
+// path is printed at the end of a sentence, hence its trailing dot is left
+// out.
+func bugReportArchivePath(out, dir string) string {
+	if m := bugReportArchiveRegex.FindStringSubmatch(out); m != nil {
+		archive := strings.TrimSuffix(m[1], ".")
+		if filepath.IsAbs(archive) {
+			return archive
+		}
+		return filepath.Join(dir, archive)
+	}
+	return filepath.Join(dir, bugReportArchive)
+}
@@ -0,0 +1,46 @@
+package istio
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBugReportArgs(t *testing.T) {
+	if got, want := bugReportArgs("kind-east", ""), []string{"bug-report", "--context", "kind-east"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("bugReportArgs() = %v, want %v", got, want)
+	}
+	if got, want := bugReportArgs("kind-east", "bookinfo"), []string{"bug-report", "--context", "kind-east", "--include", "bookinfo"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("bugReportArgs() = %v, want %v", got, want)
+	}
+}
+
+func TestBugReportArchivePath(t *testing.T) {
+	tests := []struct {
+		name string
+		out  string
+		want string
+	}{
+		{
+			name: "absolute path",
+			out:  "Fetching logs for istio-system/istiod-6cf8d4f9cb-wm7x6\nCreating an archive at /tmp/istio-bug-report-1/bug-report.tar.gz.\n",
+			want: "/tmp/istio-bug-report-1/bug-report.tar.gz",
+		},
+		{
+			name: "relative path",
+			out:  "Creating an archive at bug-report.tar.gz.\nCleaning up temporary files in /tmp/bug-report.\n",
+			want: "/tmp/istio-bug-report-1/bug-report.tar.gz",
+		},
+		{
+			name: "no path",
+			out:  "Done.\n",
+			want: "/tmp/istio-bug-report-1/bug-report.tar.gz",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bugReportArchivePath(tt.out, "/tmp/istio-bug-report-1"); got != tt.want {
+				t.Errorf("bugReportArchivePath() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
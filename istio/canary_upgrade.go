@@ -0,0 +1,158 @@
+package istio
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// canaryUpgradeOptions describes the revision installed by a canary upgrade
+type canaryUpgradeOptions struct {
+	// Version and Profile are the version and profile of the new revision
+	Version string
+	Profile string
+
+	// OnStep, if set, is called once a step of the upgrade is done
+	OnStep func(step string)
+}
+
+// upgradeCanary moves the workloads of the namespaces from the fromRev control
+// plane, the default one being "", to the toRev one:
+//  1. the toRev revision is installed next to fromRev
+//  2. the namespaces are labeled with istio.io/rev=toRev
+//  3. the deployments of the namespaces are restarted to be injected again
+//  4. the proxies of the namespaces are checked to be of toRev and ready,
+//     hence connected to the new istiod
+//
+// If any step after the install fails, the namespaces are labeled for fromRev
+// again and their deployments restarted, the toRev revision being left
+// installed for the failure to be looked into.
+func (istio *Istio) upgradeCanary(ctx context.Context, fromRev, toRev string, namespaces []string, opts canaryUpgradeOptions, kubeConfigs []string) error {
+	if toRev == "" || toRev == fromRev {
+		return ErrCanaryUpgradeFailed(fmt.Errorf("the revision to upgrade to must be set and differ from %q", fromRev))
+	}
+	if len(namespaces) == 0 {
+		return ErrCanaryUpgradeFailed(fmt.Errorf("no namespace to upgrade"))
+	}
+	step := func(format string, a ...interface{}) {
+		if opts.OnStep != nil {
+			opts.OnStep(fmt.Sprintf(format, a...))
+		}
+	}
+
+	if _, err := istio.installIstio(ctx, false, false, opts.Version, "", installOptions{Profile: opts.Profile, Revision: toRev}, kubeConfigs); err != nil {
+		return ErrCanaryUpgradeFailed(fmt.Errorf("unable to install the %s revision: %w", toRev, err))
+	}
+	step("Revision %s of Istio %s installed", toRev, opts.Version)
+
+	err := istio.moveNamespaces(ctx, namespaces, toRev, kubeConfigs)
+	if err == nil {
+		step("Namespaces %s moved to revision %s", strings.Join(namespaces, ", "), toRev)
+		err = istio.verifyProxyRevision(ctx, namespaces, toRev, kubeConfigs)
+	}
+	if err == nil {
+		step("Proxies of the namespaces %s connected to revision %s", strings.Join(namespaces, ", "), toRev)
+		return nil
+	}
+
+	istio.log(ctx).Error(err)
+	// The rollback runs even if the upgrade is cancelled, for the namespaces
+	// not to be left between two revisions
+	if rerr := istio.moveNamespaces(context.WithoutCancel(ctx), namespaces, fromRev, kubeConfigs); rerr != nil {
+		return ErrCanaryUpgradeFailed(fmt.Errorf("%w, the rollback to %s failed as well: %v", err, revisionName(fromRev), rerr))
+	}
+	step("Namespaces %s rolled back to revision %s", strings.Join(namespaces, ", "), revisionName(fromRev))
+	return ErrCanaryUpgradeFailed(fmt.Errorf("%w, the namespaces are rolled back to %s", err, revisionName(fromRev)))
+}
+
+// moveNamespaces labels the namespaces for the revision and restarts their
+// deployments so that they are injected with its proxies
+func (istio *Istio) moveNamespaces(ctx context.Context, namespaces []string, revision string, kubeConfigs []string) error {
+	for _, namespace := range namespaces {
+		if err := istio.LoadNamespaceToMesh(namespace, false, revision, kubeConfigs); err != nil {
+			return err
+		}
+	}
+	return forEachCluster(kubeConfigs, func(k8sconfig string) error {
+		mclient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return err
+		}
+		for _, namespace := range namespaces {
+			if _, err := restartDeployments(ctx, mclient, namespace, "", workloadRolloutTimeout); err != nil {
+				return fmt.Errorf("unable to restart the deployments of %s: %w", namespace, err)
+			}
+		}
+		return nil
+	}, nil)
+}
+
+// verifyProxyRevision checks that the injected pods of the namespaces run
+// proxies of the revision which are ready, a ready proxy having received its
+// configuration from istiod
+func (istio *Istio) verifyProxyRevision(ctx context.Context, namespaces []string, revision string, kubeConfigs []string) error {
+	return forEachCluster(kubeConfigs, func(k8sconfig string) error {
+		mclient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return err
+		}
+		var problems []string
+		for _, namespace := range namespaces {
+			pods, err := mclient.KubeClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return err
+			}
+			problems = append(problems, proxyRevisionProblems(pods.Items, revision)...)
+		}
+		if len(problems) > 0 {
+			return fmt.Errorf("%s", strings.Join(problems, ", "))
+		}
+		return nil
+	}, nil)
+}
+
+// proxyRevisionProblems returns the injected pods whose proxy is not of the
+// revision or not ready, the terminating pods are skipped
+func proxyRevisionProblems(pods []corev1.Pod, revision string) []string {
+	var problems []string
+	for _, pod := range pods {
+		annotation, ok := pod.Annotations["sidecar.istio.io/status"]
+		if !ok || pod.DeletionTimestamp != nil {
+			continue
+		}
+		var status struct {
+			Revision string `json:"revision"`
+		}
+		if err := json.Unmarshal([]byte(annotation), &status); err != nil {
+			problems = append(problems, fmt.Sprintf("%s/%s has an invalid sidecar status: %v", pod.Namespace, pod.Name, err))
+			continue
+		}
+		if revisionName(status.Revision) != revisionName(revision) {
+			problems = append(problems, fmt.Sprintf("%s/%s runs a proxy of revision %s", pod.Namespace, pod.Name, revisionName(status.Revision)))
+			continue
+		}
+		// Sidecars run either as regular containers or as native sidecars
+		var statuses []corev1.ContainerStatus
+		statuses = append(statuses, pod.Status.ContainerStatuses...)
+		statuses = append(statuses, pod.Status.InitContainerStatuses...)
+		for _, cs := range statuses {
+			if cs.Name == "istio-proxy" && !cs.Ready {
+				problems = append(problems, fmt.Sprintf("%s/%s proxy is not ready", pod.Namespace, pod.Name))
+			}
+		}
+	}
+	return problems
+}
+
+// revisionName returns the name of the revision, "default" for the default one
+func revisionName(revision string) string {
+	if revision == "" {
+		return "default"
+	}
+	return revision
+}
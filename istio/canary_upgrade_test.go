@@ -0,0 +1,68 @@
+package istio
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestProxyRevisionProblems(t *testing.T) {
+	pod := func(name, status string, ready bool) corev1.Pod {
+		p := corev1.Pod{}
+		p.Name = name
+		p.Namespace = "bookinfo"
+		if status != "" {
+			p.Annotations = map[string]string{"sidecar.istio.io/status": status}
+		}
+		p.Status.ContainerStatuses = []corev1.ContainerStatus{
+			{Name: "app", Ready: true},
+			{Name: "istio-proxy", Ready: ready},
+		}
+		return p
+	}
+	terminating := pod("reviews-old", `{"revision":"default"}`, true)
+	terminating.DeletionTimestamp = &metav1.Time{}
+
+	tests := []struct {
+		name     string
+		pods     []corev1.Pod
+		revision string
+		want     []string
+	}{
+		{
+			name: "upgraded",
+			pods: []corev1.Pod{
+				pod("reviews", `{"initContainers":["istio-init"],"containers":["istio-proxy"],"revision":"1-20"}`, true),
+				pod("not-injected", "", false),
+				terminating,
+			},
+			revision: "1-20",
+		},
+		{
+			name:     "old revision",
+			pods:     []corev1.Pod{pod("reviews", `{"revision":"default"}`, true)},
+			revision: "1-20",
+			want:     []string{"bookinfo/reviews runs a proxy of revision default"},
+		},
+		{
+			name:     "proxy not ready",
+			pods:     []corev1.Pod{pod("reviews", `{"revision":"1-20"}`, false)},
+			revision: "1-20",
+			want:     []string{"bookinfo/reviews proxy is not ready"},
+		},
+		{
+			name:     "rolled back to the default revision",
+			pods:     []corev1.Pod{pod("reviews", `{"revision":"default"}`, true)},
+			revision: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := proxyRevisionProblems(tt.pods, tt.revision); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("proxyRevisionProblems() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package istio
+
+import (
+	"context"
+	"sync"
+)
+
+// runningOperations are the cancel functions of the running operations by
+// their operation ID
+type runningOperations struct {
+	mx      sync.Mutex
+	cancels map[string]*context.CancelFunc
+}
+
+// start returns the context of the operation along with the function to call
+// once it is done.
+//
+// The context of the gRPC request is cancelled as soon as ApplyOperation
+// returns, which is before the operation goroutines are done, hence only its
+// values are kept and the operation is cancelled by the cancel operation.
+func (r *runningOperations) start(ctx context.Context, operationID string) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	if r.cancels == nil {
+		r.cancels = map[string]*context.CancelFunc{}
+	}
+	r.cancels[operationID] = &cancel
+	return ctx, func() {
+		cancel()
+		r.mx.Lock()
+		defer r.mx.Unlock()
+		// The operation ID may have been reused by a later operation
+		if r.cancels[operationID] == &cancel {
+			delete(r.cancels, operationID)
+		}
+	}
+}
+
+// cancel cancels the running operation and reports whether it was running
+func (r *runningOperations) cancel(operationID string) bool {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	cancel, ok := r.cancels[operationID]
+	if ok {
+		(*cancel)()
+		delete(r.cancels, operationID)
+	}
+	return ok
+}
@@ -0,0 +1,34 @@
+package istio
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRunningOperations(t *testing.T) {
+	var running runningOperations
+	type key struct{}
+	parent, cancelParent := context.WithCancel(context.WithValue(context.Background(), key{}, "request"))
+	ctx, done := running.start(parent, "op-1")
+
+	// The operation outlives the request
+	cancelParent()
+	if ctx.Err() != nil || ctx.Value(key{}) != "request" {
+		t.Fatalf("start() context = %v, %v, want the values of the request without its cancellation", ctx.Err(), ctx.Value(key{}))
+	}
+
+	if running.cancel("op-2") {
+		t.Errorf("cancel() cancelled an operation which isn't running")
+	}
+	if !running.cancel("op-1") || ctx.Err() != context.Canceled {
+		t.Errorf("cancel() didn't cancel the running operation, context error = %v", ctx.Err())
+	}
+	done()
+
+	// An operation is no longer cancellable once done
+	_, done = running.start(context.Background(), "op-3")
+	done()
+	if running.cancel("op-3") {
+		t.Errorf("cancel() cancelled an operation which is done")
+	}
+}
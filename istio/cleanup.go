@@ -0,0 +1,125 @@
+package istio
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// The management label the adapter sets on the Istio resources of the custom
+// operations and of the policies, the cleanup only removes the resources
+// carrying it
+const (
+	managedByLabel = "app.kubernetes.io/managed-by"
+	managedByValue = "meshery-istio"
+)
+
+// cleanupResources are the kinds of the Istio resources the cleanup removes,
+// along with the kind they are reported as
+var cleanupResources = []struct {
+	Kind string
+	GVR  schema.GroupVersionResource
+}{
+	{"VirtualService", schema.GroupVersionResource{Group: "networking.istio.io", Version: "v1beta1", Resource: "virtualservices"}},
+	{"DestinationRule", schema.GroupVersionResource{Group: "networking.istio.io", Version: "v1beta1", Resource: "destinationrules"}},
+	{"Gateway", schema.GroupVersionResource{Group: "networking.istio.io", Version: "v1beta1", Resource: "gateways"}},
+	{"ServiceEntry", schema.GroupVersionResource{Group: "networking.istio.io", Version: "v1beta1", Resource: "serviceentries"}},
+	{"Sidecar", schema.GroupVersionResource{Group: "networking.istio.io", Version: "v1beta1", Resource: "sidecars"}},
+	{"WorkloadEntry", schema.GroupVersionResource{Group: "networking.istio.io", Version: "v1beta1", Resource: "workloadentries"}},
+	{"WorkloadGroup", schema.GroupVersionResource{Group: "networking.istio.io", Version: "v1beta1", Resource: "workloadgroups"}},
+	{"EnvoyFilter", schema.GroupVersionResource{Group: "networking.istio.io", Version: "v1alpha3", Resource: "envoyfilters"}},
+	{"AuthorizationPolicy", schema.GroupVersionResource{Group: "security.istio.io", Version: "v1beta1", Resource: "authorizationpolicies"}},
+	{"PeerAuthentication", schema.GroupVersionResource{Group: "security.istio.io", Version: "v1beta1", Resource: "peerauthentications"}},
+	{"RequestAuthentication", schema.GroupVersionResource{Group: "security.istio.io", Version: "v1beta1", Resource: "requestauthentications"}},
+	{"Telemetry", schema.GroupVersionResource{Group: "telemetry.istio.io", Version: "v1alpha1", Resource: "telemetries"}},
+}
+
+// managedManifest sets the management label on the Istio resources of the
+// multi-document manifest, the resources of the other API groups and the
+// ones already labeled by another manager being left untouched
+func managedManifest(contents []byte) ([]byte, error) {
+	managed := extraMetadata{Labels: map[string]string{managedByLabel: managedByValue}}
+	return mapManifest(contents, func(object map[string]interface{}) {
+		obj := &unstructured.Unstructured{Object: object}
+		if isIstioGroup("." + obj.GroupVersionKind().Group) {
+			managed.merge(obj)
+		}
+	})
+}
+
+// cleanupIstioResources removes the Istio resources of the namespace the
+// adapter created, the ones carrying its management label, on every cluster.
+// The number of resources removed of every kind is returned prefixed with the
+// cluster.
+func (istio *Istio) cleanupIstioResources(namespace string, kubeConfigs []string) ([]string, error) {
+	if namespace == "" {
+		return nil, ErrCleanupNamespace(fmt.Errorf("no namespace to clean up"))
+	}
+	var mx sync.Mutex
+	var removed []string
+	err := forEachCluster(kubeConfigs, func(k8sconfig string) error {
+		mclient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return err
+		}
+		counts, err := cleanupNamespace(context.TODO(), mclient.DynamicKubeClient, namespace)
+		cluster := clusterName(k8sconfig)
+		mx.Lock()
+		for _, kind := range cleanupResources {
+			if counts[kind.Kind] > 0 {
+				removed = append(removed, fmt.Sprintf("%s: %d %s", cluster, counts[kind.Kind], kind.Kind))
+			}
+		}
+		mx.Unlock()
+		return err
+	}, nil)
+	sort.Strings(removed)
+	if err != nil {
+		return removed, ErrCleanupNamespace(err)
+	}
+	return removed, nil
+}
+
+// cleanupNamespace removes the Istio resources of the namespace carrying the
+// management label and returns the number removed of every kind. The kinds
+// whose CRD is not installed are skipped.
+func cleanupNamespace(ctx context.Context, dyn dynamic.Interface, namespace string) (map[string]int, error) {
+	counts := map[string]int{}
+	var errs []error
+	selector := managedByLabel + "=" + managedByValue
+	for _, kind := range cleanupResources {
+		list, err := dyn.Resource(kind.GVR).Namespace(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+		if kerrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to list the %s of %s: %w", kind.GVR.Resource, namespace, err))
+			continue
+		}
+		for _, obj := range list.Items {
+			// Never trust the selector alone to guard the resources of
+			// the other managers
+			if obj.GetLabels()[managedByLabel] != managedByValue {
+				continue
+			}
+			err := dyn.Resource(kind.GVR).Namespace(namespace).Delete(ctx, obj.GetName(), metav1.DeleteOptions{})
+			if kerrors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("unable to delete %s %s: %w", kind.Kind, obj.GetName(), err))
+				continue
+			}
+			counts[kind.Kind]++
+		}
+	}
+	return counts, mergeErrors(errs)
+}
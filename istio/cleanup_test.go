@@ -0,0 +1,101 @@
+package istio
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestManagedManifest(t *testing.T) {
+	manifest := `apiVersion: networking.istio.io/v1beta1
+kind: VirtualService
+metadata:
+  name: reviews
+---
+apiVersion: networking.istio.io/v1beta1
+kind: DestinationRule
+metadata:
+  name: reviews
+  labels:
+    app.kubernetes.io/managed-by: argocd
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: reviews
+`
+	got, err := managedManifest([]byte(manifest))
+	if err != nil {
+		t.Fatalf("managedManifest() error = %v", err)
+	}
+	want := map[string]string{"VirtualService": managedByValue, "DestinationRule": "argocd", "ConfigMap": ""}
+	for _, doc := range strings.Split(string(got), "---\n") {
+		var obj interface{}
+		if err := yaml.Unmarshal([]byte(doc), &obj); err != nil {
+			t.Fatalf("managedManifest() generated invalid YAML: %v\n%s", err, doc)
+		}
+		u := unstructured.Unstructured{Object: jsonValue(obj).(map[string]interface{})}
+		if got := u.GetLabels()[managedByLabel]; got != want[u.GetKind()] {
+			t.Errorf("managedManifest() %s %s = %q, want %q", u.GetKind(), managedByLabel, got, want[u.GetKind()])
+		}
+	}
+}
+
+func TestCleanupNamespace(t *testing.T) {
+	managed := map[string]interface{}{managedByLabel: managedByValue}
+	listKinds := map[schema.GroupVersionResource]string{}
+	for _, kind := range cleanupResources {
+		listKinds[kind.GVR] = kind.Kind + "List"
+	}
+	virtualServices, destinationRules, gateways := cleanupResources[0].GVR, cleanupResources[1].GVR, cleanupResources[2].GVR
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds)
+	for _, r := range []struct {
+		gvr             schema.GroupVersionResource
+		namespace, name string
+		labels          map[string]interface{}
+	}{
+		{virtualServices, "default", "reviews", managed},
+		{virtualServices, "default", "ratings", managed},
+		{virtualServices, "default", "details", nil},
+		{destinationRules, "default", "reviews", managed},
+		{gateways, "default", "bookinfo", map[string]interface{}{managedByLabel: "argocd"}},
+		{gateways, "bookinfo", "bookinfo", managed},
+	} {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": r.gvr.GroupVersion().String(),
+			"metadata":   map[string]interface{}{"name": r.name, "namespace": r.namespace, "labels": r.labels},
+		}}
+		if _, err := dyn.Resource(r.gvr).Namespace(r.namespace).Create(context.Background(), obj, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("unable to create %s %s/%s: %v", r.gvr.Resource, r.namespace, r.name, err)
+		}
+	}
+
+	counts, err := cleanupNamespace(context.Background(), dyn, "default")
+	if err != nil {
+		t.Fatalf("cleanupNamespace() error = %v", err)
+	}
+	if want := map[string]int{"VirtualService": 2, "DestinationRule": 1}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("cleanupNamespace() = %v, want %v", counts, want)
+	}
+
+	for _, left := range []struct {
+		gvr             schema.GroupVersionResource
+		namespace, name string
+	}{
+		{virtualServices, "default", "details"},
+		{gateways, "default", "bookinfo"},
+		{gateways, "bookinfo", "bookinfo"},
+	} {
+		if _, err := dyn.Resource(left.gvr).Namespace(left.namespace).Get(context.Background(), left.name, metav1.GetOptions{}); err != nil {
+			t.Errorf("cleanupNamespace() removed %s %s/%s: %v", left.gvr.Resource, left.namespace, left.name, err)
+		}
+	}
+}
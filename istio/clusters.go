@@ -0,0 +1,188 @@
+package istio
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/layer5io/meshery-adapter-library/meshes"
+	"github.com/layer5io/meshery-istio/internal/config"
+	"github.com/layer5io/meshkit/errors"
+)
+
+// clusterProgress is called once the work on a cluster is done, err is nil
+// if the work succeeded
+type clusterProgress func(cluster string, err error)
+
+// forEachCluster runs fn for every kubeconfig, with at most
+// config.ClusterConcurrency clusters at a time. A failing cluster doesn't stop
+// the others, the errors of all the clusters are merged and prefixed with the
+// name of their cluster. progress may be nil.
+func forEachCluster(kubeconfigs []string, fn func(k8sconfig string) error, progress clusterProgress) error {
+	limit := config.ClusterConcurrency
+	if limit <= 0 {
+		limit = 1
+	}
+	sem := make(chan struct{}, limit)
+
+	var wg sync.WaitGroup
+	var errMx sync.Mutex
+	var errs []error
+	for _, k8sconfig := range kubeconfigs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(k8sconfig string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			cluster := clusterName(k8sconfig)
+			err := fn(k8sconfig)
+			if progress != nil {
+				progress(cluster, err)
+			}
+			if err != nil {
+				errMx.Lock()
+				errs = append(errs, fmt.Errorf("%s: %w", cluster, err))
+				errMx.Unlock()
+			}
+		}(k8sconfig)
+	}
+	wg.Wait()
+	return mergeErrors(errs)
+}
+
+// ClusterResult is the outcome of an operation on a single cluster
+type ClusterResult struct {
+	Cluster string `json:"cluster"`
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+}
+
+// The statuses of the ClusterResults
+const (
+	clusterSucceeded = "succeeded"
+	clusterFailed    = "failed"
+)
+
+// clusterResults collects the outcome of an operation on every cluster from
+// its clusterProgress. A cluster which failed a step stays failed even if a
+// later step succeeds on it.
+type clusterResults struct {
+	mx      sync.Mutex
+	results map[string]ClusterResult
+}
+
+func newClusterResults() *clusterResults {
+	return &clusterResults{results: map[string]ClusterResult{}}
+}
+
+// track returns a clusterProgress recording the outcome of the clusters
+// before calling next, if not nil
+func (r *clusterResults) track(next clusterProgress) clusterProgress {
+	return func(cluster string, err error) {
+		r.mx.Lock()
+		if previous, ok := r.results[cluster]; !ok || previous.Status != clusterFailed {
+			result := ClusterResult{Cluster: cluster, Status: clusterSucceeded}
+			if err != nil {
+				result.Status = clusterFailed
+				result.Error = err.Error()
+			}
+			r.results[cluster] = result
+		}
+		r.mx.Unlock()
+		if next != nil {
+			next(cluster, err)
+		}
+	}
+}
+
+// List returns the results sorted by cluster
+func (r *clusterResults) List() []ClusterResult {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	list := make([]ClusterResult, 0, len(r.results))
+	for _, result := range r.results {
+		list = append(list, result)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Cluster < list[j].Cluster })
+	return list
+}
+
+// details appends the results to the details of an event as a JSON list on
+// a line starting with "Clusters: ", for the clients to render a per cluster
+// status
+func (r *clusterResults) details(details string) string {
+	list := r.List()
+	if len(list) == 0 {
+		return details
+	}
+	encoded, _ := json.Marshal(list)
+	if details == "" {
+		return fmt.Sprintf("Clusters: %s", encoded)
+	}
+	return fmt.Sprintf("%s\nClusters: %s", details, encoded)
+}
+
+// streamClusterProgress returns a clusterProgress which streams an event for
+// every cluster the operation is done with, so that the progress of the
+// operations spanning several clusters is visible
+func (istio *Istio) streamClusterProgress(ee *meshes.EventsResponse, action string) clusterProgress {
+	return func(cluster string, err error) {
+		e := &meshes.EventsResponse{
+			OperationId:   ee.OperationId,
+			Component:     ee.Component,
+			ComponentName: ee.ComponentName,
+		}
+		if err != nil {
+			e.Summary = fmt.Sprintf("Error while %s on cluster %s", action, cluster)
+			e.Details = err.Error()
+			// The errors of the clusters are not necessarily meshkit errors
+			if _, ok := err.(*errors.Error); ok {
+				e.ErrorCode = errors.GetCode(err)
+				e.ProbableCause = errors.GetCause(err)
+				e.SuggestedRemediation = errors.GetRemedy(err)
+			}
+			e.EventType = meshes.EventType_ERROR
+			istio.statuses.record(cluster, OperationFailed, e)
+			istio.StreamErr(e, err)
+			return
+		}
+		e.Summary = fmt.Sprintf("Finished %s on cluster %s", action, cluster)
+		istio.statuses.record(cluster, OperationSucceeded, e)
+		istio.StreamInfo(e)
+	}
+}
+
+// streamRetryProgress returns a retryProgress streaming an info event for
+// every retry, so that the operation doesn't look stuck while it backs off
+func (istio *Istio) streamRetryProgress(ee *meshes.EventsResponse, action string) retryProgress {
+	return func(cluster string, attempt int, err error) {
+		e := &meshes.EventsResponse{
+			OperationId:   ee.OperationId,
+			Component:     ee.Component,
+			ComponentName: ee.ComponentName,
+			Summary:       fmt.Sprintf("Retrying %s on cluster %s, attempt %d failed", action, cluster, attempt),
+			Details:       err.Error(),
+		}
+		istio.statuses.record(cluster, OperationInProgress, e)
+		istio.StreamInfo(e)
+	}
+}
+
+// streamPhaseProgress returns an installPhaseProgress streaming an info event
+// for every phase of the install done on all the clusters, with the rough
+// share of the install done by then
+func (istio *Istio) streamPhaseProgress(ee *meshes.EventsResponse, action string) installPhaseProgress {
+	return func(phase installPhase, percent int) {
+		istio.StreamInfo(&meshes.EventsResponse{
+			OperationId:   ee.OperationId,
+			Component:     ee.Component,
+			ComponentName: ee.ComponentName,
+			Summary:       fmt.Sprintf("%s %d%% done: %s", strings.ToUpper(action[:1])+action[1:], percent, phase),
+			Details:       fmt.Sprintf("The %s phase is done on all the clusters.", phase),
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package istio
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/layer5io/meshery-istio/internal/config"
+)
+
+func TestForEachCluster(t *testing.T) {
+	defer func(n int) { config.ClusterConcurrency = n }(config.ClusterConcurrency)
+	config.ClusterConcurrency = 2
+
+	var kubeconfigs []string
+	for i := 0; i < 5; i++ {
+		kubeconfigs = append(kubeconfigs, fmt.Sprintf("current-context: cluster-%d\n", i))
+	}
+
+	var mx sync.Mutex
+	var running, maxRunning int
+	done := map[string]error{}
+	err := forEachCluster(kubeconfigs, func(k8sconfig string) error {
+		mx.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mx.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mx.Lock()
+		running--
+		mx.Unlock()
+		if strings.Contains(k8sconfig, "cluster-1") {
+			return fmt.Errorf("unreachable")
+		}
+		return nil
+	}, func(cluster string, err error) {
+		mx.Lock()
+		done[cluster] = err
+		mx.Unlock()
+	})
+
+	if maxRunning > config.ClusterConcurrency {
+		t.Errorf("forEachCluster() ran %d clusters at a time, want at most %d", maxRunning, config.ClusterConcurrency)
+	}
+	if len(done) != len(kubeconfigs) {
+		t.Errorf("forEachCluster() reported progress of %d clusters, want %d", len(done), len(kubeconfigs))
+	}
+	for cluster, err := range done {
+		if (err != nil) != (cluster == "cluster-1") {
+			t.Errorf("forEachCluster() reported %v for %s", err, cluster)
+		}
+	}
+	if err == nil || err.Error() != "cluster-1: unreachable" {
+		t.Errorf("forEachCluster() error = %v, want cluster-1: unreachable", err)
+	}
+}
+
+func TestClusterResults(t *testing.T) {
+	results := newClusterResults()
+	var called int
+	progress := results.track(func(cluster string, err error) { called++ })
+	progress("kind-west", nil)
+	progress("kind-east", fmt.Errorf("unreachable"))
+	progress("kind-east", nil)
+
+	if called != 3 {
+		t.Errorf("track() called the next progress %d times, want 3", called)
+	}
+	want := `Installed
+Clusters: [{"cluster":"kind-east","status":"failed","error":"unreachable"},{"cluster":"kind-west","status":"succeeded"}]`
+	if got := results.details("Installed"); got != want {
+		t.Errorf("details() = %q, want %q", got, want)
+	}
+	if got := newClusterResults().details("Installed"); got != "Installed" {
+		t.Errorf("details() without results = %q, want Installed", got)
+	}
+}
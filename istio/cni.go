@@ -0,0 +1,179 @@
+package istio
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/layer5io/meshery-istio/internal/config"
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	kubeerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// cniDaemonSet is the DaemonSet of the Istio CNI node agent in istio-system
+const cniDaemonSet = "istio-cni-node"
+
+// cniConfig is how the Istio CNI plugin is installed in place of the
+// istio-init containers. The zero value leaves the CNI plugin to the profile.
+type cniConfig struct {
+	Enabled bool
+
+	// Chained, if set, appends the plugin to the CNI config of the cluster,
+	// the plugin having its own CNI config in standalone mode otherwise
+	Chained bool
+
+	// BinDir and ConfDir are the host directories of the CNI binaries and
+	// configs, the defaults of the chart are used when empty. GKE keeps the
+	// binaries in /home/kubernetes/bin and OpenShift uses Multus.
+	BinDir  string
+	ConfDir string
+}
+
+// newCNIConfig reads the CNI plugin options of the install operation. The
+// cni.chained, cni.cniBinDir and cni.cniConfDir properties require
+// cni.enabled, chained being the default, and the ambient profile can't
+// disable the plugin it redirects the traffic with.
+func newCNIConfig(props map[string]string) (cniConfig, error) {
+	var cni cniConfig
+	enabled, err := parseCNIBool(props, config.CNIEnabled)
+	if err != nil {
+		return cni, err
+	}
+	chained, err := parseCNIBool(props, config.CNIChained)
+	if err != nil {
+		return cni, err
+	}
+	binDir := strings.TrimSpace(props[config.CNIBinDir])
+	confDir := strings.TrimSpace(props[config.CNIConfDir])
+	for key, dir := range map[string]string{config.CNIBinDir: binDir, config.CNIConfDir: confDir} {
+		if dir != "" && !path.IsAbs(dir) {
+			return cni, ErrInvalidCNIConfig(fmt.Errorf("%s %q is not an absolute path", key, dir))
+		}
+	}
+	if enabled == nil || !*enabled {
+		if chained != nil || binDir != "" || confDir != "" {
+			return cni, ErrInvalidCNIConfig(fmt.Errorf("%s, %s and %s require %s to be true", config.CNIChained, config.CNIBinDir, config.CNIConfDir, config.CNIEnabled))
+		}
+		if enabled != nil && props[config.Profile] == "ambient" {
+			return cni, ErrInvalidCNIConfig(fmt.Errorf("the ambient profile requires the CNI plugin, %s can't be false", config.CNIEnabled))
+		}
+		return cni, nil
+	}
+	cni = cniConfig{Enabled: true, Chained: true, BinDir: binDir, ConfDir: confDir}
+	if chained != nil {
+		cni.Chained = *chained
+	}
+	return cni, nil
+}
+
+// parseCNIBool returns the boolean of the property, nil when it's empty
+func parseCNIBool(props map[string]string, key string) (*bool, error) {
+	value := strings.TrimSpace(props[key])
+	if value == "" {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, ErrInvalidCNIConfig(fmt.Errorf("%s %q is not a boolean", key, value))
+	}
+	return &b, nil
+}
+
+// mode is chained or standalone
+func (c cniConfig) mode() string {
+	if c.Chained {
+		return "chained"
+	}
+	return "standalone"
+}
+
+// String describes the CNI plugin for the event details
+func (c cniConfig) String() string {
+	s := fmt.Sprintf("the Istio CNI plugin in %s mode", c.mode())
+	var dirs []string
+	if c.BinDir != "" {
+		dirs = append(dirs, "binaries in "+c.BinDir)
+	}
+	if c.ConfDir != "" {
+		dirs = append(dirs, "configs in "+c.ConfDir)
+	}
+	if len(dirs) > 0 {
+		s = fmt.Sprintf("%s, with its %s", s, strings.Join(dirs, " and "))
+	}
+	return s
+}
+
+// values returns the cni values of the IstioOperator, nil if the plugin isn't
+// enabled. The standalone plugin writes its own config file.
+func (c cniConfig) values() map[string]interface{} {
+	if !c.Enabled {
+		return nil
+	}
+	values := map[string]interface{}{
+		"chained": c.Chained,
+	}
+	if !c.Chained {
+		values["cniConfFileName"] = "istio-cni.conf"
+	}
+	if c.BinDir != "" {
+		values["cniBinDir"] = c.BinDir
+	}
+	if c.ConfDir != "" {
+		values["cniConfDir"] = c.ConfDir
+	}
+	return values
+}
+
+// component returns the cni component of the IstioOperator, nil if the
+// plugin isn't enabled. The node agent runs in istio-system for its status
+// to be read along with the control plane.
+func (c cniConfig) component() map[string]interface{} {
+	if !c.Enabled {
+		return nil
+	}
+	return map[string]interface{}{
+		"enabled":   true,
+		"namespace": istioRootNamespace,
+	}
+}
+
+// cniStatus returns the readiness of the CNI node agent on every cluster, as
+// "ready of desired nodes on cluster", sorted by cluster
+func cniStatus(ctx context.Context, kubeconfigs []string) ([]string, error) {
+	var mx sync.Mutex
+	var statuses []string
+	err := forEachCluster(kubeconfigs, func(k8sconfig string) error {
+		mclient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return err
+		}
+		status, err := clusterCNIStatus(ctx, mclient.KubeClient)
+		if err != nil {
+			return err
+		}
+		mx.Lock()
+		statuses = append(statuses, fmt.Sprintf("%s on %s", status, clusterName(k8sconfig)))
+		mx.Unlock()
+		return nil
+	}, nil)
+	sort.Strings(statuses)
+	return statuses, err
+}
+
+// clusterCNIStatus describes the readiness of the CNI node agent of a cluster
+func clusterCNIStatus(ctx context.Context, client kubernetes.Interface) (string, error) {
+	ds, err := client.AppsV1().DaemonSets(istioRootNamespace).Get(ctx, cniDaemonSet, metav1.GetOptions{})
+	if kubeerror.IsNotFound(err) {
+		return "not deployed", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("ready on %d of %d nodes", ds.Status.NumberReady, ds.Status.DesiredNumberScheduled), nil
+}
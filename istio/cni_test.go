@@ -0,0 +1,107 @@
+package istio
+
+import (
+	"context"
+	"testing"
+
+	"github.com/layer5io/meshery-istio/internal/config"
+	"github.com/layer5io/meshkit/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestNewCNIConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		props   map[string]string
+		want    cniConfig
+		wantErr bool
+	}{
+		{
+			name:  "plugin of the profile",
+			props: map[string]string{config.CNIEnabled: "", config.CNIChained: "", config.CNIBinDir: "", config.CNIConfDir: ""},
+		},
+		{
+			name:  "disabled",
+			props: map[string]string{config.CNIEnabled: "false"},
+		},
+		{
+			name:  "chained by default",
+			props: map[string]string{config.CNIEnabled: "true"},
+			want:  cniConfig{Enabled: true, Chained: true},
+		},
+		{
+			name:  "standalone with the directories of OpenShift",
+			props: map[string]string{config.CNIEnabled: "true", config.CNIChained: "false", config.CNIBinDir: "/var/lib/cni/bin", config.CNIConfDir: " /etc/cni/multus/net.d "},
+			want:  cniConfig{Enabled: true, BinDir: "/var/lib/cni/bin", ConfDir: "/etc/cni/multus/net.d"},
+		},
+		{
+			name:  "ambient profile",
+			props: map[string]string{config.Profile: "ambient", config.CNIEnabled: "true"},
+			want:  cniConfig{Enabled: true, Chained: true},
+		},
+		{
+			name:    "ambient profile without the plugin",
+			props:   map[string]string{config.Profile: "ambient", config.CNIEnabled: "false"},
+			wantErr: true,
+		},
+		{
+			name:    "chained without the plugin",
+			props:   map[string]string{config.CNIChained: "true"},
+			wantErr: true,
+		},
+		{
+			name:    "directory of the disabled plugin",
+			props:   map[string]string{config.CNIEnabled: "false", config.CNIBinDir: "/home/kubernetes/bin"},
+			wantErr: true,
+		},
+		{
+			name:    "relative directory",
+			props:   map[string]string{config.CNIEnabled: "true", config.CNIConfDir: "etc/cni/net.d"},
+			wantErr: true,
+		},
+		{
+			name:    "not a boolean",
+			props:   map[string]string{config.CNIEnabled: "yes"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newCNIConfig(tt.props)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newCNIConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && errors.GetCode(err) != ErrInvalidCNIConfigCode {
+				t.Errorf("newCNIConfig() error code = %s, want %s", errors.GetCode(err), ErrInvalidCNIConfigCode)
+			}
+			if got != tt.want {
+				t.Errorf("newCNIConfig() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClusterCNIStatus(t *testing.T) {
+	ds := &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: cniDaemonSet, Namespace: istioRootNamespace}}
+	ds.Status.DesiredNumberScheduled = 3
+	ds.Status.NumberReady = 2
+	tests := []struct {
+		name   string
+		client *fake.Clientset
+		want   string
+	}{
+		{name: "deployed", client: fake.NewSimpleClientset(ds), want: "ready on 2 of 3 nodes"},
+		{name: "not deployed", client: fake.NewSimpleClientset(), want: "not deployed"},
+	}
+	for _, tt := range tests {
+		got, err := clusterCNIStatus(context.Background(), tt.client)
+		if err != nil {
+			t.Fatalf("clusterCNIStatus() %s error = %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("clusterCNIStatus() %s = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package istio
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// defaultLogTailLines is the number of lines fetched from every pod
+	// when the operation doesn't set it
+	defaultLogTailLines = 100
+
+	// maxLogTailLines bounds the lines fetched from every pod, the logs
+	// being streamed in the event details
+	maxLogTailLines = 5000
+)
+
+// logComponent is an Istio component the logs can be fetched from
+type logComponent struct {
+	// Selector selects the pods of the component
+	Selector string
+
+	// Container is the container of the pods the logs are fetched from
+	Container string
+}
+
+// logComponents are the components the logs can be fetched from, keyed by
+// their name without the istio- prefix
+var logComponents = map[string]logComponent{
+	"istiod":          {Selector: "app=istiod", Container: "discovery"},
+	"ingressgateway":  {Selector: "istio=ingressgateway", Container: "istio-proxy"},
+	"egressgateway":   {Selector: "istio=egressgateway", Container: "istio-proxy"},
+	"eastwestgateway": {Selector: "istio=eastwestgateway", Container: "istio-proxy"},
+	"ztunnel":         {Selector: "app=ztunnel", Container: "istio-proxy"},
+	"cni":             {Selector: "k8s-app=istio-cni-node", Container: "install-cni"},
+}
+
+// ComponentLogs are the last lines logged by a pod of a component on a
+// cluster
+type ComponentLogs struct {
+	Cluster string
+	Pod     string
+	Logs    string
+
+	// Previous are the last lines logged by the previous container of the
+	// pod, if it restarted
+	Previous string
+}
+
+func (l ComponentLogs) String() string {
+	if l.Previous == "" {
+		return fmt.Sprintf("==> %s: %s <==\n%s", l.Cluster, l.Pod, strings.TrimRight(l.Logs, "\n"))
+	}
+	return fmt.Sprintf("==> %s: %s (previous container) <==\n%s\n==> %s: %s <==\n%s", l.Cluster, l.Pod, strings.TrimRight(l.Previous, "\n"), l.Cluster, l.Pod, strings.TrimRight(l.Logs, "\n"))
+}
+
+// fetchComponentLogs fetches the last lines logged by the pods of the
+// component, such as istiod or ingressgateway, in the namespace on every
+// cluster, sorted by cluster and pod. ErrFetchLogsFailed is returned along
+// with the logs fetched if the component is unknown, if it has no pod on a
+// cluster or if the logs of a pod can't be fetched.
+func (istio *Istio) fetchComponentLogs(ctx context.Context, component, namespace string, tailLines int, kubeConfigs []string) ([]ComponentLogs, error) {
+	c, err := parseLogComponent(component)
+	if err != nil {
+		return nil, ErrFetchLogsFailed(component, err)
+	}
+	if namespace == "" {
+		namespace = istioRootNamespace
+	}
+
+	var mx sync.Mutex
+	var logs []ComponentLogs
+	err = forEachCluster(kubeConfigs, func(k8sconfig string) error {
+		mclient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return err
+		}
+		podLogs, err := componentPodLogs(ctx, mclient.KubeClient, c, namespace, tailLines)
+		cluster := clusterName(k8sconfig)
+		for i := range podLogs {
+			podLogs[i].Cluster = cluster
+		}
+		mx.Lock()
+		logs = append(logs, podLogs...)
+		mx.Unlock()
+		return err
+	}, nil)
+	sort.Slice(logs, func(i, j int) bool {
+		if logs[i].Cluster != logs[j].Cluster {
+			return logs[i].Cluster < logs[j].Cluster
+		}
+		return logs[i].Pod < logs[j].Pod
+	})
+	if err != nil {
+		return logs, ErrFetchLogsFailed(component, err)
+	}
+	return logs, nil
+}
+
+// componentPodLogs fetches the last lines logged by the container of the
+// component in every pod of the component, the previous container being
+// read as well when it restarted
+func componentPodLogs(ctx context.Context, client kubernetes.Interface, c logComponent, namespace string, tailLines int) ([]ComponentLogs, error) {
+	pods, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: c.Selector})
+	if err != nil {
+		return nil, err
+	}
+	if len(pods.Items) == 0 {
+		return nil, fmt.Errorf("no pod matching %s in %s", c.Selector, namespace)
+	}
+	tail := int64(tailLines)
+	fetch := func(pod string, previous bool) (string, error) {
+		out, err := client.CoreV1().Pods(namespace).GetLogs(pod, &corev1.PodLogOptions{
+			Container: c.Container,
+			TailLines: &tail,
+			Previous:  previous,
+		}).DoRaw(ctx)
+		if err != nil {
+			return "", fmt.Errorf("unable to fetch the logs of %s: %w", pod, err)
+		}
+		return string(out), nil
+	}
+	var logs []ComponentLogs
+	for _, pod := range pods.Items {
+		l := ComponentLogs{Pod: pod.Name}
+		if l.Logs, err = fetch(pod.Name, false); err != nil {
+			return logs, err
+		}
+		for _, cs := range pod.Status.ContainerStatuses {
+			if cs.Name == c.Container && cs.RestartCount > 0 {
+				// The previous logs may be gone along with the node
+				// the container ran on
+				l.Previous, _ = fetch(pod.Name, true)
+			}
+		}
+		logs = append(logs, l)
+	}
+	return logs, nil
+}
+
+// parseLogComponent returns the component of the name, with or without its
+// istio- prefix
+func parseLogComponent(name string) (logComponent, error) {
+	name = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(name)), "istio-")
+	if name == "" {
+		name = "istiod"
+	}
+	c, ok := logComponents[name]
+	if !ok {
+		names := make([]string, 0, len(logComponents))
+		for n := range logComponents {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return c, fmt.Errorf("unknown component %q, want one of %s", name, strings.Join(names, ", "))
+	}
+	return c, nil
+}
+
+// parseTailLines parses the number of lines fetched from every pod, between
+// 1 and maxLogTailLines, defaultLogTailLines being used when empty
+func parseTailLines(value string) (int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return defaultLogTailLines, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("tail lines %q is not an integer", value)
+	}
+	if n < 1 || n > maxLogTailLines {
+		return 0, fmt.Errorf("tail lines %d is not between 1 and %d", n, maxLogTailLines)
+	}
+	return n, nil
+}
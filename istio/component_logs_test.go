@@ -0,0 +1,76 @@
+package istio
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestComponentPodLogs(t *testing.T) {
+	pod := func(name string, labels map[string]string, restarts int32) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: istioRootNamespace, Labels: labels},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "discovery", RestartCount: restarts},
+			}},
+		}
+	}
+	client := fake.NewSimpleClientset(
+		pod("istiod-1", map[string]string{"app": "istiod"}, 0),
+		pod("istiod-2", map[string]string{"app": "istiod"}, 2),
+		pod("istio-ingressgateway-1", map[string]string{"istio": "ingressgateway"}, 0),
+	)
+	istiod, err := parseLogComponent("istiod")
+	if err != nil {
+		t.Fatalf("parseLogComponent() error = %v", err)
+	}
+	logs, err := componentPodLogs(context.Background(), client, istiod, istioRootNamespace, 10)
+	if err != nil {
+		t.Fatalf("componentPodLogs() error = %v", err)
+	}
+	if len(logs) != 2 || logs[0].Pod != "istiod-1" || logs[1].Pod != "istiod-2" {
+		t.Fatalf("componentPodLogs() = %+v, want the logs of the istiod pods", logs)
+	}
+	if logs[0].Logs == "" || logs[0].Previous != "" || logs[1].Previous == "" {
+		t.Errorf("componentPodLogs() = %+v, want the previous logs of the restarted pod only", logs)
+	}
+
+	egress, _ := parseLogComponent("istio-egressgateway")
+	if _, err := componentPodLogs(context.Background(), client, egress, istioRootNamespace, 10); err == nil {
+		t.Errorf("componentPodLogs() error = nil, want an error without pods")
+	}
+}
+
+func TestParseLogsRequest(t *testing.T) {
+	for _, name := range []string{"", "istiod", "Istio-IngressGateway", "eastwestgateway"} {
+		if _, err := parseLogComponent(name); err != nil {
+			t.Errorf("parseLogComponent(%q) error = %v", name, err)
+		}
+	}
+	if _, err := parseLogComponent("kiali"); err == nil {
+		t.Errorf("parseLogComponent(kiali) error = nil, want an unknown component")
+	}
+	tests := []struct {
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{value: "", want: defaultLogTailLines},
+		{value: " 250 ", want: 250},
+		{value: "0", wantErr: true},
+		{value: "10000", wantErr: true},
+		{value: "all", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseTailLines(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseTailLines(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("parseTailLines(%q) = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+}
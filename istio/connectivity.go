@@ -0,0 +1,178 @@
+package istio
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// connectivityTimeout bounds the request sent from the source pod
+const connectivityTimeout = 10 * time.Second
+
+// ConnectivityResult is the outcome of the request sent from the source pod
+// to the target service on a cluster
+type ConnectivityResult struct {
+	Cluster    string
+	Source     string
+	Target     string
+	StatusCode int
+
+	// MTLS reports whether the request reached the target over mutual TLS,
+	// see parseConnectivityResponse
+	MTLS bool
+}
+
+func (r ConnectivityResult) String() string {
+	tls := "without mTLS"
+	if r.MTLS {
+		tls = "over mTLS"
+	}
+	return fmt.Sprintf("%s: %s -> %s: HTTP %d %s", r.Cluster, r.Source, r.Target, r.StatusCode, tls)
+}
+
+// testConnectivity sends an HTTP request from the application container of
+// the source pod of the namespace to the target service, such as
+// httpbin:8000/headers, with curl on every cluster. Any HTTP status is a
+// result, the policies denying the request with a 403 included, while
+// ErrConnectivityTestFailed is returned with the error curl observed when no
+// response is received, such as when the connection is reset.
+func (istio *Istio) testConnectivity(ctx context.Context, sourcePod, targetService, namespace string, kubeConfigs []string) ([]ConnectivityResult, error) {
+	target, err := connectivityURL(targetService)
+	if err != nil {
+		return nil, ErrConnectivityTestFailed(err)
+	}
+
+	var mx sync.Mutex
+	var results []ConnectivityResult
+	err = forEachCluster(kubeConfigs, func(k8sconfig string) error {
+		mclient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return err
+		}
+		pod, err := mclient.KubeClient.CoreV1().Pods(namespace).Get(ctx, sourcePod, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		container, err := sourceContainer(pod)
+		if err != nil {
+			return err
+		}
+		command := []string{"curl", "-sS", "-i", "-m", strconv.Itoa(int(connectivityTimeout.Seconds())), target}
+		stdout, stderr, err := execInPod(ctx, mclient, namespace, sourcePod, container, command)
+		if err != nil {
+			if stderr = strings.TrimSpace(stderr); stderr != "" {
+				return fmt.Errorf("%s -> %s: %s", sourcePod, target, stderr)
+			}
+			return fmt.Errorf("%s -> %s: %w", sourcePod, target, err)
+		}
+		status, mtls, err := parseConnectivityResponse(stdout)
+		if err != nil {
+			return fmt.Errorf("%s -> %s: %w", sourcePod, target, err)
+		}
+		mx.Lock()
+		results = append(results, ConnectivityResult{
+			Cluster:    clusterName(k8sconfig),
+			Source:     fmt.Sprintf("%s/%s", namespace, sourcePod),
+			Target:     target,
+			StatusCode: status,
+			MTLS:       mtls,
+		})
+		mx.Unlock()
+		return nil
+	}, nil)
+	sort.Slice(results, func(i, j int) bool { return results[i].Cluster < results[j].Cluster })
+	if err != nil {
+		return results, ErrConnectivityTestFailed(err)
+	}
+	return results, nil
+}
+
+// connectivityURL returns the URL of the target service, http being used
+// unless the target has a scheme
+func connectivityURL(target string) (string, error) {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return "", fmt.Errorf("the target service is required")
+	}
+	if !strings.Contains(target, "://") {
+		target = "http://" + target
+	}
+	return target, nil
+}
+
+// sourceContainer returns the container of the pod the request is sent
+// from, its first application container, the proxy container being left out
+// as its requests bypass the sidecar
+func sourceContainer(pod *corev1.Pod) (string, error) {
+	for _, container := range pod.Spec.Containers {
+		if container.Name != "istio-proxy" {
+			return container.Name, nil
+		}
+	}
+	return "", fmt.Errorf("pod %s has no application container", pod.Name)
+}
+
+// parseConnectivityResponse parses the response printed by curl -i, the last
+// one when curl printed interim responses, and reports its status along with
+// whether the request reached the target over mTLS. The server sidecar
+// passes the identity of the client to the application in the
+// X-Forwarded-Client-Cert header only when mTLS is used, hence the targets
+// echoing the request headers, such as httpbin, tell whether mTLS was used.
+func parseConnectivityResponse(out string) (int, bool, error) {
+	reader := bufio.NewReader(strings.NewReader(out))
+	for {
+		resp, err := http.ReadResponse(reader, nil)
+		if err != nil {
+			return 0, false, fmt.Errorf("invalid response: %w", err)
+		}
+		if resp.StatusCode >= 100 && resp.StatusCode < 200 {
+			continue
+		}
+		var body bytes.Buffer
+		_, _ = body.ReadFrom(resp.Body)
+		_ = resp.Body.Close()
+		header := textproto.CanonicalMIMEHeaderKey("X-Forwarded-Client-Cert")
+		mtls := resp.Header.Get(header) != "" || strings.Contains(strings.ToLower(body.String()), strings.ToLower(header))
+		return resp.StatusCode, mtls, nil
+	}
+}
+
+// execInPod runs the command in the container of the pod and returns its
+// standard output and error
+func execInPod(ctx context.Context, mclient *mesherykube.Client, namespace, pod, container string, command []string) (string, string, error) {
+	req := mclient.KubeClient.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(pod).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+	executor, err := remotecommand.NewSPDYExecutor(&mclient.RestConfig, http.MethodPost, req.URL())
+	if err != nil {
+		return "", "", err
+	}
+	var stdout, stderr bytes.Buffer
+	err = executor.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdout: &stdout,
+		Stderr: &stderr,
+	})
+	return stdout.String(), stderr.String(), err
+}
@@ -0,0 +1,96 @@
+package istio
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseConnectivityResponse(t *testing.T) {
+	crlf := func(s string) string { return strings.ReplaceAll(s, "\n", "\r\n") }
+	tests := []struct {
+		name       string
+		out        string
+		wantStatus int
+		wantMTLS   bool
+		wantErr    bool
+	}{
+		{
+			name: "mtls",
+			out: crlf(`HTTP/1.1 200 OK
+server: envoy
+content-type: application/json
+content-length: 120
+
+`) + `{"headers": {"Host": "httpbin:8000", "X-Forwarded-Client-Cert": "By=spiffe://cluster.local/ns/default/sa/httpbin;URI=spiffe://cluster.local/ns/default/sa/sleep"}}`,
+			wantStatus: 200,
+			wantMTLS:   true,
+		},
+		{
+			name: "plain text after continue",
+			out: crlf(`HTTP/1.1 100 Continue
+
+HTTP/1.1 200 OK
+content-length: 2
+
+`) + "{}",
+			wantStatus: 200,
+		},
+		{
+			name: "denied",
+			out: crlf(`HTTP/1.1 403 Forbidden
+content-length: 19
+content-type: text/plain
+
+`) + "RBAC: access denied",
+			wantStatus: 403,
+		},
+		{
+			name:    "no response",
+			out:     "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, mtls, err := parseConnectivityResponse(tt.out)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseConnectivityResponse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if status != tt.wantStatus || mtls != tt.wantMTLS {
+				t.Errorf("parseConnectivityResponse() = %d, %v, want %d, %v", status, mtls, tt.wantStatus, tt.wantMTLS)
+			}
+		})
+	}
+}
+
+func TestConnectivityURL(t *testing.T) {
+	for target, want := range map[string]string{
+		"httpbin:8000/headers":             "http://httpbin:8000/headers",
+		" https://httpbin.org/headers ":    "https://httpbin.org/headers",
+		"reviews.bookinfo.svc:9080/health": "http://reviews.bookinfo.svc:9080/health",
+	} {
+		if got, err := connectivityURL(target); err != nil || got != want {
+			t.Errorf("connectivityURL(%q) = %q, %v, want %q", target, got, err, want)
+		}
+	}
+	if _, err := connectivityURL(" "); err == nil {
+		t.Error("connectivityURL() of no target error = nil, want an error")
+	}
+}
+
+func TestSourceContainer(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "sleep"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "istio-proxy"}, {Name: "sleep"}}},
+	}
+	if got, err := sourceContainer(pod); err != nil || got != "sleep" {
+		t.Errorf("sourceContainer() = %q, %v, want sleep", got, err)
+	}
+	pod.Spec.Containers = pod.Spec.Containers[:1]
+	if _, err := sourceContainer(pod); err == nil {
+		t.Error("sourceContainer() of a pod with the proxy only error = nil, want an error")
+	}
+}
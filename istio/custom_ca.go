@@ -0,0 +1,194 @@
+package istio
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/layer5io/meshery-istio/internal/config"
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	kubeerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+)
+
+// caSecretKeys are the sets of keys istiod loads a plugged-in CA from, the
+// cacerts keys of Istio or the kubernetes.io/tls keys cert-manager writes
+var caSecretKeys = [][]string{
+	{"ca-cert.pem", "ca-key.pem", "cert-chain.pem", "root-cert.pem"},
+	{"tls.crt", "tls.key", "ca.crt"},
+}
+
+// customCA is the CA istiod issues the workload certificates with in place
+// of its self-signed root. At most one of its fields is set, the zero value
+// keeping the self-signed root.
+type customCA struct {
+	// Signer is the Kubernetes signer istiod requests the workload
+	// certificates from through the CertificateSigningRequest API, such as
+	// clusterissuers.cert-manager.io/istio-ca
+	Signer string
+
+	// Address is the address of the external CA the proxies request their
+	// certificates from, such as the istio-csr agent of cert-manager, istiod
+	// not running its own CA
+	Address string
+
+	// SecretNamespace and SecretName are the secret holding the plugged-in
+	// CA, copied to the cacerts secret istiod loads it from
+	SecretNamespace string
+	SecretName      string
+}
+
+// newCustomCA reads the CA of the install operation from its caSigner,
+// caAddress and caCertsSecret properties, which are mutually exclusive. The
+// secret is a name in istio-system or a namespace/name pair.
+func newCustomCA(props map[string]string) (customCA, error) {
+	var ca customCA
+	var set []string
+	if signer := strings.TrimSpace(props[config.CASigner]); signer != "" {
+		set = append(set, config.CASigner)
+		domain, path, found := strings.Cut(signer, "/")
+		if !found || path == "" || len(validation.IsDNS1123Subdomain(domain)) > 0 {
+			return ca, ErrInvalidCAConfig(fmt.Errorf("%s %q is not a <domain>/<name> signer name", config.CASigner, signer))
+		}
+		ca.Signer = signer
+	}
+	if address := strings.TrimSpace(props[config.CAAddress]); address != "" {
+		set = append(set, config.CAAddress)
+		host, port, err := net.SplitHostPort(address)
+		if err == nil && host == "" {
+			err = fmt.Errorf("missing host")
+		}
+		if err == nil {
+			if n, perr := strconv.Atoi(port); perr != nil || n < 1 || n > 65535 {
+				err = fmt.Errorf("invalid port %q", port)
+			}
+		}
+		if err != nil {
+			return ca, ErrInvalidCAConfig(fmt.Errorf("%s %q is not a host:port address: %w", config.CAAddress, address, err))
+		}
+		ca.Address = address
+	}
+	if secret := strings.TrimSpace(props[config.CACertsSecret]); secret != "" {
+		set = append(set, config.CACertsSecret)
+		namespace, name, found := strings.Cut(secret, "/")
+		if !found {
+			namespace, name = istioRootNamespace, secret
+		}
+		if len(validation.IsDNS1123Label(namespace)) > 0 || len(validation.IsDNS1123Subdomain(name)) > 0 {
+			return ca, ErrInvalidCAConfig(fmt.Errorf("%s %q is not a secret name or a namespace/name pair", config.CACertsSecret, secret))
+		}
+		ca.SecretNamespace, ca.SecretName = namespace, name
+	}
+	if len(set) > 1 {
+		return customCA{}, ErrInvalidCAConfig(fmt.Errorf("%s are mutually exclusive, set only one of them", strings.Join(set, " and ")))
+	}
+	return ca, nil
+}
+
+func (ca customCA) empty() bool {
+	return ca == customCA{}
+}
+
+// String describes the CA mode for the event details
+func (ca customCA) String() string {
+	switch {
+	case ca.Signer != "":
+		return fmt.Sprintf("the Kubernetes signer %s", ca.Signer)
+	case ca.Address != "":
+		return fmt.Sprintf("the external CA at %s", ca.Address)
+	case ca.SecretName != "":
+		return fmt.Sprintf("the CA plugged in from the secret %s/%s", ca.SecretNamespace, ca.SecretName)
+	}
+	return "the self-signed root of istiod"
+}
+
+// env returns the environment of istiod selecting the CA, nil if istiod runs
+// its own CA, be it self-signed or plugged in
+func (ca customCA) env() map[string]interface{} {
+	switch {
+	case ca.Signer != "":
+		return map[string]interface{}{
+			"EXTERNAL_CA": "ISTIOD_RA_KUBERNETES_API",
+			"K8S_SIGNER":  ca.Signer,
+		}
+	case ca.Address != "":
+		return map[string]interface{}{
+			"ENABLE_CA_SERVER": "false",
+		}
+	}
+	return nil
+}
+
+// plugCACerts copies the secret of the plugged-in CA to the cacerts secret
+// of istio-system on every cluster, before istiod is installed and loads it
+func plugCACerts(ctx context.Context, ca customCA, kubeconfigs []string) error {
+	if ca.SecretName == "" {
+		return nil
+	}
+	return forEachCluster(kubeconfigs, func(k8sconfig string) error {
+		mclient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return err
+		}
+		return plugCACertsOnCluster(ctx, mclient.KubeClient, ca)
+	}, nil)
+}
+
+func plugCACertsOnCluster(ctx context.Context, client kubernetes.Interface, ca customCA) error {
+	source, err := client.CoreV1().Secrets(ca.SecretNamespace).Get(ctx, ca.SecretName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to get the secret %s/%s: %w", ca.SecretNamespace, ca.SecretName, err)
+	}
+	if !hasCASecretKeys(source.Data) {
+		return fmt.Errorf("the secret %s/%s has neither the %s nor the %s keys", ca.SecretNamespace, ca.SecretName, strings.Join(caSecretKeys[0], ", "), strings.Join(caSecretKeys[1], ", "))
+	}
+	if ca.SecretNamespace == istioRootNamespace && ca.SecretName == caCertsSecret {
+		return nil
+	}
+
+	if _, err := client.CoreV1().Namespaces().Get(ctx, istioRootNamespace, metav1.GetOptions{}); kubeerror.IsNotFound(err) {
+		_, err = client.CoreV1().Namespaces().Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: istioRootNamespace}}, metav1.CreateOptions{})
+		if err != nil && !kubeerror.IsAlreadyExists(err) {
+			return err
+		}
+	}
+	secrets := client.CoreV1().Secrets(istioRootNamespace)
+	secret, err := secrets.Get(ctx, caCertsSecret, metav1.GetOptions{})
+	if kubeerror.IsNotFound(err) {
+		_, err = secrets.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: caCertsSecret, Namespace: istioRootNamespace},
+			Type:       source.Type,
+			Data:       source.Data,
+		}, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	secret.Data = source.Data
+	_, err = secrets.Update(ctx, secret, metav1.UpdateOptions{})
+	return err
+}
+
+// hasCASecretKeys reports whether the data holds either set of the keys of
+// a plugged-in CA
+func hasCASecretKeys(data map[string][]byte) bool {
+	for _, keys := range caSecretKeys {
+		found := true
+		for _, key := range keys {
+			if len(data[key]) == 0 {
+				found = false
+				break
+			}
+		}
+		if found {
+			return true
+		}
+	}
+	return false
+}
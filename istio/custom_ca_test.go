@@ -0,0 +1,100 @@
+package istio
+
+import (
+	"context"
+	"testing"
+
+	"github.com/layer5io/meshery-istio/internal/config"
+	"github.com/layer5io/meshkit/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestNewCustomCA(t *testing.T) {
+	tests := []struct {
+		name    string
+		props   map[string]string
+		want    customCA
+		wantErr bool
+	}{
+		{
+			name:  "self-signed root",
+			props: map[string]string{config.CASigner: "", config.CAAddress: "", config.CACertsSecret: ""},
+		},
+		{
+			name:  "kubernetes signer",
+			props: map[string]string{config.CASigner: "clusterissuers.cert-manager.io/istio-ca"},
+			want:  customCA{Signer: "clusterissuers.cert-manager.io/istio-ca"},
+		},
+		{
+			name:  "external CA",
+			props: map[string]string{config.CAAddress: "cert-manager-istio-csr.cert-manager.svc:443"},
+			want:  customCA{Address: "cert-manager-istio-csr.cert-manager.svc:443"},
+		},
+		{
+			name:  "secret of istio-system",
+			props: map[string]string{config.CACertsSecret: "intermediate-ca"},
+			want:  customCA{SecretNamespace: istioRootNamespace, SecretName: "intermediate-ca"},
+		},
+		{
+			name:  "secret of another namespace",
+			props: map[string]string{config.CACertsSecret: "cert-manager/istio-ca"},
+			want:  customCA{SecretNamespace: "cert-manager", SecretName: "istio-ca"},
+		},
+		{
+			name:    "signer without a name",
+			props:   map[string]string{config.CASigner: "istio-ca"},
+			wantErr: true,
+		},
+		{
+			name:    "address without a port",
+			props:   map[string]string{config.CAAddress: "istio-csr.cert-manager.svc"},
+			wantErr: true,
+		},
+		{
+			name:    "signer and secret",
+			props:   map[string]string{config.CASigner: "clusterissuers.cert-manager.io/istio-ca", config.CACertsSecret: "cacerts"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newCustomCA(tt.props)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newCustomCA() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if code := errors.GetCode(err); code != ErrInvalidCAConfigCode {
+					t.Errorf("newCustomCA() error code = %s, want %s", code, ErrInvalidCAConfigCode)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf("newCustomCA() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlugCACertsOnCluster(t *testing.T) {
+	data := map[string][]byte{"tls.crt": []byte("cert"), "tls.key": []byte("key"), "ca.crt": []byte("root")}
+	client := fake.NewSimpleClientset(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "istio-ca", Namespace: "cert-manager"}, Type: corev1.SecretTypeTLS, Data: data},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "partial", Namespace: "cert-manager"}, Data: map[string][]byte{"tls.crt": []byte("cert")}},
+	)
+	if err := plugCACertsOnCluster(context.Background(), client, customCA{SecretNamespace: "cert-manager", SecretName: "istio-ca"}); err != nil {
+		t.Fatalf("plugCACertsOnCluster() error = %v", err)
+	}
+	secret, err := client.CoreV1().Secrets(istioRootNamespace).Get(context.Background(), caCertsSecret, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("plugCACertsOnCluster() didn't create the %s secret: %v", caCertsSecret, err)
+	}
+	if string(secret.Data["tls.key"]) != "key" || secret.Type != corev1.SecretTypeTLS {
+		t.Errorf("plugCACertsOnCluster() %s secret = %v, want a copy of cert-manager/istio-ca", caCertsSecret, secret.Data)
+	}
+
+	if err := plugCACertsOnCluster(context.Background(), client, customCA{SecretNamespace: "cert-manager", SecretName: "partial"}); err == nil {
+		t.Errorf("plugCACertsOnCluster() of a secret without the CA keys succeeded, want error")
+	}
+}
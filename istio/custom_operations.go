@@ -2,16 +2,142 @@
 package istio
 
 import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
 	"github.com/layer5io/meshery-adapter-library/status"
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
 )
 
-func (istio *Istio) applyCustomOperation(namespace string, manifest string, isDel bool, kubeconfigs []string) (string, error) {
+// dryRunClients returns the clients the dry run of a cluster is done with
+var dryRunClients = func(k8sconfig string) (discovery.DiscoveryInterface, dynamic.Interface, error) {
+	mclient, err := mesherykube.New([]byte(k8sconfig))
+	if err != nil {
+		return nil, nil, err
+	}
+	return mclient.KubeClient.Discovery(), mclient.DynamicKubeClient, nil
+}
+
+func (istio *Istio) applyCustomOperation(ctx context.Context, namespace string, manifest string, isDel, dryRun bool, kubeconfigs []string) (string, error) {
 	st := status.Starting
 
-	err := istio.applyManifest([]byte(manifest), isDel, namespace, kubeconfigs)
+	if dryRun {
+		diagnostics, err := dryRunManifest([]byte(manifest), isDel, namespace, kubeconfigs)
+		if err != nil {
+			return st, ErrCustomOperation(err)
+		}
+		if len(diagnostics) > 0 {
+			return st, ErrCustomOperationInvalid(diagnostics)
+		}
+		return status.Completed, nil
+	}
+
+	contents := []byte(manifest)
+	if !isDel {
+		var err error
+		if contents, err = managedManifest(contents); err != nil {
+			return st, ErrCustomOperation(err)
+		}
+	}
+	err := istio.applyManifest(ctx, contents, isDel, namespace, kubeconfigs)
 	if err != nil {
 		return st, ErrCustomOperation(err)
 	}
 
 	return status.Completed, nil
 }
+
+// dryRunManifest submits every resource of the manifest to the API server of
+// every cluster with a server side dry run, so that the resources are admitted
+// and validated, including by the istio validating webhook, without being
+// persisted. The validation failures are returned as diagnostics while the
+// error is set only if the clusters can't be reached.
+func dryRunManifest(contents []byte, isDel bool, namespace string, kubeconfigs []string) ([]string, error) {
+	var mx sync.Mutex
+	var diagnostics []string
+	err := forEachCluster(kubeconfigs, func(k8sconfig string) error {
+		disc, dyn, err := dryRunClients(k8sconfig)
+		if err != nil {
+			return err
+		}
+		d, err := dryRunOnSingleCluster(disc, dyn, contents, isDel, namespace)
+		if err != nil {
+			return err
+		}
+		cluster := clusterName(k8sconfig)
+		mx.Lock()
+		for _, msg := range d {
+			diagnostics = append(diagnostics, fmt.Sprintf("%s: %s", cluster, msg))
+		}
+		mx.Unlock()
+		return nil
+	}, nil)
+	return diagnostics, err
+}
+
+func dryRunOnSingleCluster(disc discovery.DiscoveryInterface, dyn dynamic.Interface, contents []byte, isDel bool, namespace string) ([]string, error) {
+	groupResources, err := restmapper.GetAPIGroupResources(disc)
+	if err != nil {
+		return nil, err
+	}
+	mapper := restmapper.NewDiscoveryRESTMapper(groupResources)
+
+	var diagnostics []string
+	for _, manifest := range strings.Split(string(contents), "\n---\n") {
+		if strings.TrimSpace(manifest) == "" {
+			continue
+		}
+		_, obj, err := mesherykube.GetObjectFromManifest(manifest)
+		if err != nil {
+			diagnostics = append(diagnostics, err.Error())
+			continue
+		}
+		name := fmt.Sprintf("%s/%s", obj.GetKind(), obj.GetName())
+		gvk := obj.GroupVersionKind()
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			diagnostics = append(diagnostics, fmt.Sprintf("%s: %s", name, err))
+			continue
+		}
+
+		var resource dynamic.ResourceInterface = dyn.Resource(mapping.Resource)
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			ns := obj.GetNamespace()
+			if namespace != "" {
+				ns = namespace
+			}
+			obj.SetNamespace(ns)
+			resource = dyn.Resource(mapping.Resource).Namespace(ns)
+		}
+
+		dryRun := []string{metav1.DryRunAll}
+		if isDel {
+			err = resource.Delete(context.TODO(), obj.GetName(), metav1.DeleteOptions{DryRun: dryRun})
+			if kerrors.IsNotFound(err) {
+				err = nil
+			}
+		} else {
+			_, err = resource.Create(context.TODO(), obj, metav1.CreateOptions{DryRun: dryRun})
+			if kerrors.IsAlreadyExists(err) {
+				// Custom resources can't be updated unconditionally
+				existing, getErr := resource.Get(context.TODO(), obj.GetName(), metav1.GetOptions{})
+				if getErr == nil {
+					obj.SetResourceVersion(existing.GetResourceVersion())
+				}
+				_, err = resource.Update(context.TODO(), obj, metav1.UpdateOptions{DryRun: dryRun})
+			}
+		}
+		if err != nil {
+			diagnostics = append(diagnostics, fmt.Sprintf("%s: %s", name, err))
+		}
+	}
+	return diagnostics, nil
+}
@@ -2,10 +2,23 @@
 package istio
 
 import (
+	"context"
+	"strings"
 	"testing"
 
 	"github.com/layer5io/meshery-adapter-library/adapter"
 	"github.com/layer5io/meshery-adapter-library/status"
+	"github.com/layer5io/meshkit/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/discovery"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 func TestIstio_applyCustomOperation(t *testing.T) {
@@ -13,6 +26,7 @@ func TestIstio_applyCustomOperation(t *testing.T) {
 		namespace string
 		manifest  string
 		isDel     bool
+		dryRun    bool
 	}
 
 	tests := []struct {
@@ -33,6 +47,16 @@ func TestIstio_applyCustomOperation(t *testing.T) {
 			want:    status.Completed,
 			wantErr: true,
 		},
+		{
+			name: "dry run without clusters",
+			args: args{
+				namespace: "default",
+				manifest:  "",
+				dryRun:    true,
+			},
+			want:    status.Completed,
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -42,7 +66,7 @@ func TestIstio_applyCustomOperation(t *testing.T) {
 					Log:    getLoggerHandler(t),
 				},
 			}
-			got, err := istio.applyCustomOperation(tt.args.namespace, tt.args.manifest, tt.args.isDel, tt.kubeconfigs)
+			got, err := istio.applyCustomOperation(context.Background(), tt.args.namespace, tt.args.manifest, tt.args.isDel, tt.args.dryRun, tt.kubeconfigs)
 			if (err != nil) == tt.wantErr {
 				t.Errorf("Istio.applyCustomOperation() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -53,3 +77,74 @@ func TestIstio_applyCustomOperation(t *testing.T) {
 		})
 	}
 }
+
+func TestIstio_applyCustomOperationDryRun(t *testing.T) {
+	disc := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{}}
+	disc.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{{Name: "configmaps", Kind: "ConfigMap", Namespaced: true}},
+		},
+		{
+			GroupVersion: "security.istio.io/v1beta1",
+			APIResources: []metav1.APIResource{{Name: "peerauthentications", Kind: "PeerAuthentication", Namespaced: true}},
+		},
+	}
+	dyn := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+	var created []string
+	dyn.PrependReactor("create", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		obj := action.(k8stesting.CreateAction).GetObject()
+		created = append(created, action.GetResource().Resource)
+		return true, obj, nil
+	})
+	dyn.PrependReactor("create", "peerauthentications", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, kerrors.NewInvalid(
+			schema.GroupKind{Group: "security.istio.io", Kind: "PeerAuthentication"}, "default",
+			field.ErrorList{field.Invalid(field.NewPath("spec", "mtls", "mode"), "OPTIONAL", "unsupported mTLS mode")},
+		)
+	})
+
+	dryRunClientsBackup := dryRunClients
+	defer func() { dryRunClients = dryRunClientsBackup }()
+	dryRunClients = func(string) (discovery.DiscoveryInterface, dynamic.Interface, error) {
+		return disc, dyn, nil
+	}
+
+	manifest := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+---
+apiVersion: security.istio.io/v1beta1
+kind: PeerAuthentication
+metadata:
+  name: default
+spec:
+  mtls:
+    mode: OPTIONAL
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: unknown
+`
+	istio := &Istio{}
+	got, err := istio.applyCustomOperation(context.Background(), "default", manifest, false, true, []string{"kubeconfig"})
+	if err == nil || errors.GetCode(err) != ErrCustomOperationInvalidCode {
+		t.Fatalf("Istio.applyCustomOperation() error = %v, want code %s", err, ErrCustomOperationInvalidCode)
+	}
+	if got != status.Starting {
+		t.Errorf("Istio.applyCustomOperation() = %v, want %v", got, status.Starting)
+	}
+	for _, want := range []string{"PeerAuthentication/default", "Widget/unknown"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Istio.applyCustomOperation() diagnostics = %q, want %s rejected", err.Error(), want)
+		}
+	}
+	if strings.Contains(err.Error(), "ConfigMap/settings") {
+		t.Errorf("Istio.applyCustomOperation() diagnostics = %q, want ConfigMap/settings accepted", err.Error())
+	}
+	if len(created) != 1 || created[0] != "configmaps" {
+		t.Errorf("Istio.applyCustomOperation() created %v, want [configmaps] dry run", created)
+	}
+}
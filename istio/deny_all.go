@@ -0,0 +1,125 @@
+package istio
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/layer5io/meshery-istio/internal/config"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// denyAllExceptionsPolicy is the name of the ALLOW AuthorizationPolicy
+// accompanying the deny-all policy, the requests of its sources being
+// allowed despite the deny-all
+const denyAllExceptionsPolicy = "deny-all-exceptions"
+
+// newDenyAllExceptions reads the allowFrom property of the deny-all
+// operation, a comma separated list of the namespaces, such as frontend, and
+// the service accounts, such as frontend/web for the web service account of
+// the frontend namespace or a full principal such as
+// cluster.local/ns/frontend/sa/web, whose requests are allowed. The service
+// accounts are matched in any trust domain unless they are full principals.
+//
+// The exceptions are the values of the AuthorizationPolicy template, nil if
+// no source is allowed. Deleting the deny-all always removes the exceptions,
+// hence their values are returned whatever the property then.
+func newDenyAllExceptions(namespace string, props map[string]string, del bool) (*authorizationPolicyValues, error) {
+	values := &authorizationPolicyValues{
+		Name:      denyAllExceptionsPolicy,
+		Namespace: namespace,
+		Action:    "ALLOW",
+	}
+	if del {
+		return values, nil
+	}
+	namespaces, principals, err := parseAllowFrom(props[config.AllowFrom])
+	if err != nil {
+		return nil, ErrInvalidAllowList(err)
+	}
+	if len(namespaces) == 0 && len(principals) == 0 {
+		return nil, nil
+	}
+	var rule authorizationRule
+	if len(namespaces) > 0 {
+		rule.From = append(rule.From, authorizationSource{Source: map[string][]string{"namespaces": namespaces}})
+	}
+	if len(principals) > 0 {
+		rule.From = append(rule.From, authorizationSource{Source: map[string][]string{"principals": principals}})
+	}
+	contents, err := json.Marshal([]authorizationRule{rule})
+	if err != nil {
+		return nil, ErrInvalidAllowList(err)
+	}
+	values.Rules = string(contents)
+	return values, nil
+}
+
+// parseAllowFrom splits the allowed sources into the namespaces and the
+// principals of the service accounts, both sorted and deduplicated
+func parseAllowFrom(value string) ([]string, []string, error) {
+	namespaces, principals := map[string]bool{}, map[string]bool{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.Split(entry, "/")
+		switch len(parts) {
+		case 1:
+			if err := checkAllowName("namespace", parts[0], entry); err != nil {
+				return nil, nil, err
+			}
+			namespaces[entry] = true
+		case 2:
+			if err := checkAllowName("namespace", parts[0], entry); err != nil {
+				return nil, nil, err
+			}
+			if err := checkAllowName("service account", parts[1], entry); err != nil {
+				return nil, nil, err
+			}
+			principals[fmt.Sprintf("*/ns/%s/sa/%s", parts[0], parts[1])] = true
+		case 5:
+			if parts[1] != "ns" || parts[3] != "sa" {
+				return nil, nil, fmt.Errorf("%q is not a <trust-domain>/ns/<namespace>/sa/<service-account> principal", entry)
+			}
+			if errs := validation.IsDNS1123Subdomain(parts[0]); len(errs) > 0 {
+				return nil, nil, fmt.Errorf("invalid trust domain %q in %q: %s", parts[0], entry, strings.Join(errs, ", "))
+			}
+			if err := checkAllowName("namespace", parts[2], entry); err != nil {
+				return nil, nil, err
+			}
+			if err := checkAllowName("service account", parts[4], entry); err != nil {
+				return nil, nil, err
+			}
+			principals[entry] = true
+		default:
+			return nil, nil, fmt.Errorf("%q is neither a namespace, a namespace/service-account pair nor a principal", entry)
+		}
+	}
+	return sortedKeys(namespaces), sortedKeys(principals), nil
+}
+
+func checkAllowName(kind, name, entry string) error {
+	check := validation.IsDNS1123Label
+	if kind == "service account" {
+		check = validation.IsDNS1123Subdomain
+	}
+	if errs := check(name); len(errs) > 0 {
+		return fmt.Errorf("invalid %s %q in %q: %s", kind, name, entry, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+func sortedKeys(set map[string]bool) []string {
+	if len(set) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
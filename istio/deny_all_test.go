@@ -0,0 +1,76 @@
+package istio
+
+import (
+	"testing"
+
+	"github.com/layer5io/meshery-istio/internal/config"
+	"github.com/layer5io/meshkit/errors"
+)
+
+func TestNewDenyAllExceptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		allowFrom string
+		del       bool
+		wantRules string
+		wantNil   bool
+		wantErr   bool
+	}{
+		{
+			name:    "no exception",
+			wantNil: true,
+		},
+		{
+			name:      "namespaces and service accounts",
+			allowFrom: "payments/checkout, frontend,cluster.local/ns/billing/sa/api,frontend",
+			wantRules: `[{"from":[{"source":{"namespaces":["frontend"]}},{"source":{"principals":["*/ns/payments/sa/checkout","cluster.local/ns/billing/sa/api"]}}]}]`,
+		},
+		{
+			name:      "service account only",
+			allowFrom: "payments/checkout",
+			wantRules: `[{"from":[{"source":{"principals":["*/ns/payments/sa/checkout"]}}]}]`,
+		},
+		{
+			name: "delete without exception",
+			del:  true,
+		},
+		{
+			name:      "invalid namespace",
+			allowFrom: "Payments",
+			wantErr:   true,
+		},
+		{
+			name:      "invalid principal",
+			allowFrom: "cluster.local/namespace/billing/sa/api",
+			wantErr:   true,
+		},
+		{
+			name:      "too many segments",
+			allowFrom: "billing/api/v1",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newDenyAllExceptions("default", map[string]string{config.AllowFrom: tt.allowFrom}, tt.del)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newDenyAllExceptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if errors.GetCode(err) != ErrInvalidAllowListCode {
+					t.Errorf("newDenyAllExceptions() error code = %s, want %s", errors.GetCode(err), ErrInvalidAllowListCode)
+				}
+				return
+			}
+			if (got == nil) != tt.wantNil {
+				t.Fatalf("newDenyAllExceptions() = %+v, want nil %v", got, tt.wantNil)
+			}
+			if got == nil {
+				return
+			}
+			if got.Name != denyAllExceptionsPolicy || got.Action != "ALLOW" || got.Rules != tt.wantRules {
+				t.Errorf("newDenyAllExceptions() = %+v, want the ALLOW %s policy with rules %s", got, denyAllExceptionsPolicy, tt.wantRules)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package istio
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/layer5io/meshery-istio/internal/config"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// destinationRuleLoadBalancers are the simple load balancing policies of a
+// DestinationRule
+var destinationRuleLoadBalancers = map[string]bool{"ROUND_ROBIN": true, "LEAST_REQUEST": true, "RANDOM": true, "PASSTHROUGH": true}
+
+// destinationRuleValues are the values of the DestinationRule template, the
+// zero limits and an empty outlier detection are left out
+type destinationRuleValues struct {
+	Name      string
+	Namespace string
+	Host      string
+
+	LoadBalancer string
+
+	MaxConnections           int
+	MaxPendingRequests       int
+	MaxRequestsPerConnection int
+
+	// Consecutive5xxErrors enables the outlier detection, the interval and
+	// the base ejection time are in seconds, the format of the durations of
+	// the Istio APIs
+	Consecutive5xxErrors int
+	Interval             string
+	BaseEjectionTime     string
+	MaxEjectionPercent   int
+}
+
+// newDestinationRuleValues validates the DestinationRule properties of the
+// operation. The DestinationRule is named after its host unless its name is
+// set, deleting it only needs the name or the host. At least one of the load
+// balancer, the connection pool limits and the outlier detection is required.
+func newDestinationRuleValues(namespace string, props map[string]string, del bool) (*destinationRuleValues, error) {
+	values := &destinationRuleValues{
+		Namespace:    namespace,
+		Name:         strings.TrimSpace(props[config.DestinationRuleName]),
+		Host:         strings.TrimSpace(props[config.DestinationRuleHost]),
+		LoadBalancer: strings.ToUpper(strings.TrimSpace(props[config.LoadBalancer])),
+	}
+	if values.Host == "" && !(del && values.Name != "") {
+		return nil, ErrInvalidDestinationRule(fmt.Errorf("the host is required"))
+	}
+	if values.Host != "" {
+		if errs := validation.IsDNS1123Subdomain(strings.TrimPrefix(values.Host, "*.")); len(errs) > 0 {
+			return nil, ErrInvalidDestinationRule(fmt.Errorf("invalid host %q: %s", values.Host, strings.Join(errs, ", ")))
+		}
+	}
+	if values.Name == "" {
+		values.Name = strings.ReplaceAll(strings.TrimPrefix(values.Host, "*."), ".", "-")
+		if len(values.Name) > validation.DNS1123LabelMaxLength {
+			values.Name = strings.Trim(values.Name[:validation.DNS1123LabelMaxLength], "-")
+		}
+	}
+	if errs := validation.IsDNS1123Subdomain(values.Name); len(errs) > 0 {
+		return nil, ErrInvalidDestinationRule(fmt.Errorf("invalid name %q: %s", values.Name, strings.Join(errs, ", ")))
+	}
+	if del {
+		return values, nil
+	}
+
+	if values.LoadBalancer != "" && !destinationRuleLoadBalancers[values.LoadBalancer] {
+		return nil, ErrInvalidDestinationRule(fmt.Errorf("loadBalancer %q is not one of ROUND_ROBIN, LEAST_REQUEST, RANDOM and PASSTHROUGH", values.LoadBalancer))
+	}
+
+	var err error
+	for _, limit := range []struct {
+		key   string
+		value *int
+	}{
+		{config.MaxConnections, &values.MaxConnections},
+		{config.MaxPendingRequests, &values.MaxPendingRequests},
+		{config.MaxRequestsPerConnection, &values.MaxRequestsPerConnection},
+		{config.Consecutive5xxErrors, &values.Consecutive5xxErrors},
+	} {
+		if *limit.value, err = parseLimit(props[limit.key]); err != nil {
+			return nil, ErrInvalidDestinationRule(fmt.Errorf("invalid %s: %w", limit.key, err))
+		}
+	}
+
+	if values.Consecutive5xxErrors > 0 {
+		for _, duration := range []struct {
+			key   string
+			value *string
+		}{
+			{config.OutlierInterval, &values.Interval},
+			{config.BaseEjectionTime, &values.BaseEjectionTime},
+		} {
+			value := strings.TrimSpace(props[duration.key])
+			if value == "" {
+				continue
+			}
+			d, err := time.ParseDuration(value)
+			if err != nil || d < time.Millisecond {
+				return nil, ErrInvalidDestinationRule(fmt.Errorf("%s %q is not a duration of at least 1ms", duration.key, value))
+			}
+			*duration.value = strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+		}
+		percent, err := parsePercentage(props[config.MaxEjectionPercent])
+		if err != nil || percent != float64(int(percent)) {
+			return nil, ErrInvalidDestinationRule(fmt.Errorf("maxEjectionPercent %q is not a whole number between 0 and 100", props[config.MaxEjectionPercent]))
+		}
+		values.MaxEjectionPercent = int(percent)
+	}
+
+	if values.LoadBalancer == "" && !values.connectionPool() && values.Consecutive5xxErrors == 0 {
+		return nil, ErrInvalidDestinationRule(fmt.Errorf("either loadBalancer, a connection pool limit or consecutive5xxErrors must be set"))
+	}
+	return values, nil
+}
+
+// connectionPool reports whether a limit of the connection pool is set
+func (v *destinationRuleValues) connectionPool() bool {
+	return v.MaxConnections > 0 || v.MaxPendingRequests > 0 || v.MaxRequestsPerConnection > 0
+}
+
+// String describes the traffic policy for the event details
+func (v *destinationRuleValues) String() string {
+	var parts []string
+	if v.LoadBalancer != "" {
+		parts = append(parts, fmt.Sprintf("%s load balancing", v.LoadBalancer))
+	}
+	if v.MaxConnections > 0 {
+		parts = append(parts, fmt.Sprintf("at most %d connections", v.MaxConnections))
+	}
+	if v.MaxPendingRequests > 0 {
+		parts = append(parts, fmt.Sprintf("at most %d pending requests", v.MaxPendingRequests))
+	}
+	if v.MaxRequestsPerConnection > 0 {
+		parts = append(parts, fmt.Sprintf("at most %d requests per connection", v.MaxRequestsPerConnection))
+	}
+	if v.Consecutive5xxErrors > 0 {
+		parts = append(parts, fmt.Sprintf("hosts ejected after %d consecutive 5xx errors", v.Consecutive5xxErrors))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// parseLimit parses a limit of the traffic policy, an empty limit being 0,
+// which leaves it out
+func parseLimit(value string) (int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%q is not a positive number", value)
+	}
+	return n, nil
+}
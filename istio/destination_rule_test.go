@@ -0,0 +1,151 @@
+package istio
+
+import (
+	"os"
+	"testing"
+
+	"github.com/layer5io/meshery-istio/internal/config"
+	"github.com/layer5io/meshkit/errors"
+	"gopkg.in/yaml.v2"
+)
+
+func TestDestinationRule(t *testing.T) {
+	tmpl, err := os.ReadFile("../templates/routing/destination-rule.yaml")
+	if err != nil {
+		t.Fatalf("unable to read the DestinationRule template: %v", err)
+	}
+
+	type trafficPolicy struct {
+		LoadBalancer *struct {
+			Simple string `yaml:"simple"`
+		} `yaml:"loadBalancer"`
+		ConnectionPool *struct {
+			TCP *struct {
+				MaxConnections int `yaml:"maxConnections"`
+			} `yaml:"tcp"`
+			HTTP *struct {
+				HTTP1MaxPendingRequests  int `yaml:"http1MaxPendingRequests"`
+				MaxRequestsPerConnection int `yaml:"maxRequestsPerConnection"`
+			} `yaml:"http"`
+		} `yaml:"connectionPool"`
+		OutlierDetection *struct {
+			Consecutive5xxErrors int    `yaml:"consecutive5xxErrors"`
+			Interval             string `yaml:"interval"`
+			BaseEjectionTime     string `yaml:"baseEjectionTime"`
+			MaxEjectionPercent   int    `yaml:"maxEjectionPercent"`
+		} `yaml:"outlierDetection"`
+	}
+
+	tests := []struct {
+		name     string
+		props    map[string]string
+		del      bool
+		wantName string
+		check    func(t *testing.T, policy trafficPolicy)
+		wantErr  bool
+	}{
+		{
+			name:     "load balancer",
+			props:    map[string]string{config.DestinationRuleHost: "reviews", config.LoadBalancer: "least_request", config.OutlierInterval: "10s"},
+			wantName: "reviews",
+			check: func(t *testing.T, policy trafficPolicy) {
+				if policy.LoadBalancer == nil || policy.LoadBalancer.Simple != "LEAST_REQUEST" {
+					t.Errorf("loadBalancer = %+v, want LEAST_REQUEST", policy.LoadBalancer)
+				}
+				if policy.ConnectionPool != nil || policy.OutlierDetection != nil {
+					t.Errorf("trafficPolicy = %+v, want only the load balancer", policy)
+				}
+			},
+		},
+		{
+			name: "connection pool and outlier detection",
+			props: map[string]string{
+				config.DestinationRuleName: "reviews-breaker", config.DestinationRuleHost: "reviews.bookinfo.svc.cluster.local",
+				config.MaxConnections: "100", config.MaxPendingRequests: "10",
+				config.Consecutive5xxErrors: "5", config.OutlierInterval: "500ms", config.BaseEjectionTime: "1m", config.MaxEjectionPercent: "50",
+			},
+			wantName: "reviews-breaker",
+			check: func(t *testing.T, policy trafficPolicy) {
+				if policy.LoadBalancer != nil {
+					t.Errorf("loadBalancer = %+v, want none", policy.LoadBalancer)
+				}
+				pool := policy.ConnectionPool
+				if pool == nil || pool.TCP == nil || pool.TCP.MaxConnections != 100 || pool.HTTP == nil || pool.HTTP.HTTP1MaxPendingRequests != 10 || pool.HTTP.MaxRequestsPerConnection != 0 {
+					t.Errorf("connectionPool = %+v, want 100 connections and 10 pending requests", pool)
+				}
+				outlier := policy.OutlierDetection
+				if outlier == nil || outlier.Consecutive5xxErrors != 5 || outlier.Interval != "0.5s" || outlier.BaseEjectionTime != "60s" || outlier.MaxEjectionPercent != 50 {
+					t.Errorf("outlierDetection = %+v, want 5 errors every 0.5s ejected for 60s up to 50%%", outlier)
+				}
+			},
+		},
+		{
+			name:     "delete by name",
+			props:    map[string]string{config.DestinationRuleName: "reviews-breaker"},
+			del:      true,
+			wantName: "reviews-breaker",
+		},
+		{
+			name:    "no host",
+			props:   map[string]string{config.LoadBalancer: "RANDOM"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown load balancer",
+			props:   map[string]string{config.DestinationRuleHost: "reviews", config.LoadBalancer: "LEAST_CONN"},
+			wantErr: true,
+		},
+		{
+			name:    "negative limit",
+			props:   map[string]string{config.DestinationRuleHost: "reviews", config.MaxConnections: "-1"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid interval",
+			props:   map[string]string{config.DestinationRuleHost: "reviews", config.Consecutive5xxErrors: "5", config.OutlierInterval: "10"},
+			wantErr: true,
+		},
+		{
+			name:    "no traffic policy",
+			props:   map[string]string{config.DestinationRuleHost: "reviews", config.OutlierInterval: "10s"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := newDestinationRuleValues("bookinfo", tt.props, tt.del)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newDestinationRuleValues() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if errors.GetCode(err) != ErrInvalidDestinationRuleCode {
+					t.Errorf("newDestinationRuleValues() error code = %s, want %s", errors.GetCode(err), ErrInvalidDestinationRuleCode)
+				}
+				return
+			}
+			rendered, err := renderTemplate(string(tmpl), values)
+			if err != nil {
+				t.Fatalf("renderTemplate() error = %v", err)
+			}
+			var destinationRule struct {
+				Metadata struct {
+					Name      string `yaml:"name"`
+					Namespace string `yaml:"namespace"`
+				} `yaml:"metadata"`
+				Spec struct {
+					Host          string        `yaml:"host"`
+					TrafficPolicy trafficPolicy `yaml:"trafficPolicy"`
+				} `yaml:"spec"`
+			}
+			if err := yaml.Unmarshal([]byte(rendered), &destinationRule); err != nil {
+				t.Fatalf("renderTemplate() generated invalid YAML: %v\n%s", err, rendered)
+			}
+			if destinationRule.Metadata.Name != tt.wantName || destinationRule.Metadata.Namespace != "bookinfo" || destinationRule.Spec.Host != tt.props[config.DestinationRuleHost] {
+				t.Errorf("renderTemplate() = %s, want %s for %q in bookinfo", rendered, tt.wantName, tt.props[config.DestinationRuleHost])
+			}
+			if tt.check != nil {
+				tt.check(t, destinationRule.Spec.TrafficPolicy)
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package istio
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/layer5io/meshery-adapter-library/status"
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// eastWestGatewayName is the name of the gateway deployment and service
+	eastWestGatewayName = "istio-eastwestgateway"
+
+	// networkLabel is the label of the network of the control plane
+	// namespace and of the east-west gateway
+	networkLabel = "topology.istio.io/network"
+)
+
+// eastWestAddressTimeout is how long the external address of the east-west
+// gateway is waited for once it is installed
+var eastWestAddressTimeout = 5 * time.Minute
+
+// installEastWestGateway installs the east-west gateway of the network in the
+// namespace, the gateway the clusters of the other networks reach the
+// services and the control plane of the cluster through, along with the
+// Gateway exposing the services of the cluster on its TLS port. The control
+// plane namespace is labeled with the network. Once the gateway is installed
+// on a cluster, onAddress, which may be nil, is called with the external
+// address allocated to it, one cluster at a time. Its deletion leaves the network label in place,
+// the workloads of the cluster still being on the network.
+func (istio *Istio) installEastWestGateway(ctx context.Context, network, namespace string, del bool, version string, onAddress func(cluster, address string), kubeConfigs []string) (string, error) {
+	st := status.Installing
+	if del {
+		st = status.Removing
+	}
+
+	operator, err := eastWestGatewayOperator(network, namespace)
+	if err != nil {
+		return st, err
+	}
+	gateway, err := crossNetworkGateway(namespace)
+	if err != nil {
+		return st, ErrEastWestGatewayFailed(err)
+	}
+
+	executable, err := istio.getExecutable(version)
+	if err != nil {
+		return st, ErrEastWestGatewayFailed(err)
+	}
+	operatorFile, err := os.CreateTemp("", "istio-eastwestgateway-*.yaml")
+	if err != nil {
+		return st, ErrEastWestGatewayFailed(err)
+	}
+	defer os.Remove(operatorFile.Name())
+	if _, err := operatorFile.Write(operator); err != nil {
+		_ = operatorFile.Close()
+		return st, ErrEastWestGatewayFailed(err)
+	}
+	if err := operatorFile.Close(); err != nil {
+		return st, ErrEastWestGatewayFailed(err)
+	}
+	manifest, err := runIstioctl(executable, "manifest", "generate", "-f", operatorFile.Name())
+	if err != nil {
+		return st, ErrEastWestGatewayFailed(err)
+	}
+
+	if del {
+		if err := istio.applyManifest(ctx, gateway, true, namespace, kubeConfigs); err != nil {
+			return st, ErrEastWestGatewayFailed(err)
+		}
+		if err := scaleDownGateway(eastWestGatewayName, namespace, kubeConfigs); err != nil {
+			return st, ErrEastWestGatewayFailed(err)
+		}
+		if err := istio.applyManifest(ctx, []byte(manifest), true, namespace, kubeConfigs); err != nil {
+			return st, ErrEastWestGatewayFailed(err)
+		}
+		return status.Removed, nil
+	}
+
+	var mx sync.Mutex
+	err = forEachCluster(kubeConfigs, func(k8sconfig string) error {
+		mclient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return err
+		}
+		if err := labelNetwork(ctx, mclient.KubeClient, istioRootNamespace, network); err != nil {
+			return err
+		}
+		for _, contents := range [][]byte{[]byte(manifest), gateway} {
+			if err := istio.applyManifestOnCluster(ctx, contents, false, namespace, k8sconfig); err != nil {
+				return err
+			}
+		}
+		address, err := waitForExternalAddress(ctx, mclient.KubeClient, namespace, eastWestGatewayName, eastWestAddressTimeout)
+		if err != nil {
+			return err
+		}
+		if onAddress != nil {
+			mx.Lock()
+			onAddress(clusterName(k8sconfig), address)
+			mx.Unlock()
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		return st, ErrEastWestGatewayFailed(err)
+	}
+	return status.Installed, nil
+}
+
+// eastWestGatewayOperator renders the IstioOperator of the east-west gateway
+// of the network, the gateway of the Istio multicluster samples: a gateway
+// of the network requesting the view of its own network, exposing the
+// status, the cross-network TLS, the istiod and the webhook ports
+func eastWestGatewayOperator(network, namespace string) ([]byte, error) {
+	if network == "" {
+		return nil, ErrEastWestGatewayFailed(fmt.Errorf("the network of the gateway is required"))
+	}
+	if errs := validation.IsValidLabelValue(network); len(errs) > 0 {
+		return nil, ErrEastWestGatewayFailed(fmt.Errorf("invalid network %q: %s", network, strings.Join(errs, ", ")))
+	}
+	ports := []interface{}{}
+	for _, port := range []struct {
+		name   string
+		number int
+	}{{"status-port", 15021}, {"tls", 15443}, {"tls-istiod", 15012}, {"tls-webhook", 15017}} {
+		ports = append(ports, map[string]interface{}{"name": port.name, "port": port.number, "targetPort": port.number})
+	}
+	gateway := map[string]interface{}{
+		"name":      eastWestGatewayName,
+		"namespace": namespace,
+		"enabled":   true,
+		"label": map[string]string{
+			"istio":      "eastwestgateway",
+			"app":        eastWestGatewayName,
+			networkLabel: network,
+		},
+		"k8s": map[string]interface{}{
+			"env": []interface{}{
+				map[string]interface{}{"name": "ISTIO_META_REQUESTED_NETWORK_VIEW", "value": network},
+			},
+			"service": map[string]interface{}{
+				"type":  "LoadBalancer",
+				"ports": ports,
+			},
+		},
+	}
+	return yaml.Marshal(map[string]interface{}{
+		"apiVersion": "install.istio.io/v1alpha1",
+		"kind":       "IstioOperator",
+		"metadata": map[string]interface{}{
+			"name":      eastWestGatewayName,
+			"namespace": namespace,
+		},
+		"spec": map[string]interface{}{
+			// The empty profile leaves out the control plane
+			"profile": "empty",
+			"components": map[string]interface{}{
+				"ingressGateways": []interface{}{gateway},
+			},
+			"values": map[string]interface{}{
+				"gateways": map[string]interface{}{
+					"istio-ingressgateway": map[string]interface{}{
+						"injectionTemplate": "gateway",
+					},
+				},
+				"global": map[string]interface{}{
+					"network": network,
+				},
+			},
+		},
+	})
+}
+
+// crossNetworkGateway renders the Gateway exposing the services of the
+// cluster to the other networks, the mTLS traffic of the proxies being
+// passed through the east-west gateway as is
+func crossNetworkGateway(namespace string) ([]byte, error) {
+	return yaml.Marshal(map[string]interface{}{
+		"apiVersion": "networking.istio.io/v1beta1",
+		"kind":       "Gateway",
+		"metadata": map[string]interface{}{
+			"name":      "cross-network-gateway",
+			"namespace": namespace,
+		},
+		"spec": map[string]interface{}{
+			"selector": map[string]string{"istio": "eastwestgateway"},
+			"servers": []interface{}{
+				map[string]interface{}{
+					"port":  map[string]interface{}{"number": 15443, "name": "tls", "protocol": "TLS"},
+					"tls":   map[string]interface{}{"mode": "AUTO_PASSTHROUGH"},
+					"hosts": []string{"*.local"},
+				},
+			},
+		},
+	})
+}
+
+// labelNetwork labels the namespace with the network
+func labelNetwork(ctx context.Context, client kubernetes.Interface, namespace, network string) error {
+	ns, err := client.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if ns.Labels[networkLabel] == network {
+		return nil
+	}
+	if ns.Labels == nil {
+		ns.Labels = map[string]string{}
+	}
+	ns.Labels[networkLabel] = network
+	_, err = client.CoreV1().Namespaces().Update(ctx, ns, metav1.UpdateOptions{})
+	return err
+}
+
+// waitForExternalAddress waits for the load balancer of the service to be
+// allocated an external address, an IP or a hostname, and returns it
+func waitForExternalAddress(ctx context.Context, client kubernetes.Interface, namespace, name string, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for {
+		service, err := client.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err == nil {
+			if address := externalAddress(service); address != "" {
+				return address, nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			if err != nil {
+				return "", fmt.Errorf("no external address allocated to %s within %s: %w", name, timeout, err)
+			}
+			return "", fmt.Errorf("no external address allocated to %s within %s", name, timeout)
+		case <-time.After(rolloutPollInterval):
+		}
+	}
+}
+
+func externalAddress(service *corev1.Service) string {
+	for _, ingress := range service.Status.LoadBalancer.Ingress {
+		if ingress.IP != "" {
+			return ingress.IP
+		}
+		if ingress.Hostname != "" {
+			return ingress.Hostname
+		}
+	}
+	return ""
+}
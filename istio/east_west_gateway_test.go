@@ -0,0 +1,110 @@
+package istio
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/layer5io/meshkit/errors"
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestEastWestGatewayOperator(t *testing.T) {
+	for _, network := range []string{"", "network 1"} {
+		_, err := eastWestGatewayOperator(network, istioRootNamespace)
+		if err == nil || errors.GetCode(err) != ErrEastWestGatewayFailedCode {
+			t.Errorf("eastWestGatewayOperator(%q) error = %v, want %s", network, err, ErrEastWestGatewayFailedCode)
+		}
+	}
+
+	got, err := eastWestGatewayOperator("network1", istioRootNamespace)
+	if err != nil {
+		t.Fatalf("eastWestGatewayOperator() error = %v", err)
+	}
+	var operator struct {
+		Spec struct {
+			Profile    string `yaml:"profile"`
+			Components struct {
+				IngressGateways []struct {
+					Name  string            `yaml:"name"`
+					Label map[string]string `yaml:"label"`
+					K8s   struct {
+						Env []struct {
+							Name  string `yaml:"name"`
+							Value string `yaml:"value"`
+						} `yaml:"env"`
+						Service struct {
+							Ports []struct {
+								Port int `yaml:"port"`
+							} `yaml:"ports"`
+						} `yaml:"service"`
+					} `yaml:"k8s"`
+				} `yaml:"ingressGateways"`
+			} `yaml:"components"`
+			Values struct {
+				Global struct {
+					Network string `yaml:"network"`
+				} `yaml:"global"`
+			} `yaml:"values"`
+		} `yaml:"spec"`
+	}
+	if err := yaml.Unmarshal(got, &operator); err != nil {
+		t.Fatalf("eastWestGatewayOperator() generated invalid YAML: %v", err)
+	}
+	if operator.Spec.Profile != "empty" || len(operator.Spec.Components.IngressGateways) != 1 {
+		t.Fatalf("eastWestGatewayOperator() = %s, want the east-west gateway alone", got)
+	}
+	gateway := operator.Spec.Components.IngressGateways[0]
+	if gateway.Name != eastWestGatewayName || gateway.Label[networkLabel] != "network1" || operator.Spec.Values.Global.Network != "network1" {
+		t.Errorf("eastWestGatewayOperator() = %s, want %s on network1", got, eastWestGatewayName)
+	}
+	if len(gateway.K8s.Env) != 1 || gateway.K8s.Env[0].Value != "network1" {
+		t.Errorf("eastWestGatewayOperator() env = %v, want the network1 view", gateway.K8s.Env)
+	}
+	ports := map[int]bool{}
+	for _, port := range gateway.K8s.Service.Ports {
+		ports[port.Port] = true
+	}
+	for _, port := range []int{15021, 15443, 15012, 15017} {
+		if !ports[port] {
+			t.Errorf("eastWestGatewayOperator() ports = %v, want %d exposed", ports, port)
+		}
+	}
+}
+
+func TestWaitForExternalAddress(t *testing.T) {
+	defer func(interval time.Duration) { rolloutPollInterval = interval }(rolloutPollInterval)
+	rolloutPollInterval = time.Millisecond
+
+	service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: eastWestGatewayName, Namespace: istioRootNamespace}}
+	client := fake.NewSimpleClientset(service)
+	if _, err := waitForExternalAddress(context.Background(), client, istioRootNamespace, eastWestGatewayName, 20*time.Millisecond); err == nil {
+		t.Errorf("waitForExternalAddress() error = nil, want a timeout without address")
+	}
+
+	service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{Hostname: "a1b2.elb.amazonaws.com"}}
+	if _, err := client.CoreV1().Services(istioRootNamespace).UpdateStatus(context.Background(), service, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("unable to update the service: %v", err)
+	}
+	got, err := waitForExternalAddress(context.Background(), client, istioRootNamespace, eastWestGatewayName, time.Second)
+	if err != nil || got != "a1b2.elb.amazonaws.com" {
+		t.Errorf("waitForExternalAddress() = %q, %v, want a1b2.elb.amazonaws.com", got, err)
+	}
+}
+
+func TestLabelNetwork(t *testing.T) {
+	client := fake.NewSimpleClientset(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: istioRootNamespace}})
+	if err := labelNetwork(context.Background(), client, istioRootNamespace, "network1"); err != nil {
+		t.Fatalf("labelNetwork() error = %v", err)
+	}
+	ns, err := client.CoreV1().Namespaces().Get(context.Background(), istioRootNamespace, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unable to get the namespace: %v", err)
+	}
+	if ns.Labels[networkLabel] != "network1" {
+		t.Errorf("labelNetwork() labels = %v, want %s=network1", ns.Labels, networkLabel)
+	}
+}
@@ -0,0 +1,314 @@
+package istio
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	kubeerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// envoyFilterResource is the resource of the EnvoyFilters
+var envoyFilterResource = schema.GroupVersionResource{Group: "networking.istio.io", Version: "v1alpha3", Resource: "envoyfilters"}
+
+// envoyFilterPatchesAnnotation holds the hashes of the config patches the
+// merge mode of the EnvoyFilter operation added to an EnvoyFilter, the ones
+// its removal takes out
+const envoyFilterPatchesAnnotation = "meshery.io/envoy-filter-patches"
+
+var (
+	// The values of the applyTo, patch.operation and match.context fields
+	// of the EnvoyFilter config patches
+	envoyFilterApplyTo = map[string]bool{
+		"LISTENER": true, "FILTER_CHAIN": true, "NETWORK_FILTER": true,
+		"HTTP_FILTER": true, "ROUTE_CONFIGURATION": true, "VIRTUAL_HOST": true,
+		"HTTP_ROUTE": true, "CLUSTER": true, "EXTENSION_CONFIG": true,
+		"BOOTSTRAP": true, "LISTENER_FILTER": true,
+	}
+	envoyFilterOperations = map[string]bool{
+		"MERGE": true, "ADD": true, "REMOVE": true, "INSERT_BEFORE": true,
+		"INSERT_AFTER": true, "INSERT_FIRST": true, "REPLACE": true,
+	}
+	envoyFilterContexts = map[string]bool{
+		"ANY": true, "SIDECAR_INBOUND": true, "SIDECAR_OUTBOUND": true, "GATEWAY": true,
+	}
+)
+
+// envoyFilter holds the fields of an EnvoyFilter which are validated
+type envoyFilter struct {
+	Kind     string `yaml:"kind"`
+	Metadata struct {
+		Name string `yaml:"name"`
+	} `yaml:"metadata"`
+	Spec struct {
+		ConfigPatches []struct {
+			ApplyTo string `yaml:"applyTo"`
+			Match   struct {
+				Context string `yaml:"context"`
+			} `yaml:"match"`
+			Patch struct {
+				Operation string      `yaml:"operation"`
+				Value     interface{} `yaml:"value"`
+			} `yaml:"patch"`
+		} `yaml:"configPatches"`
+	} `yaml:"spec"`
+}
+
+// checkEnvoyFilters checks the config patches of the EnvoyFilters of the
+// manifest, the other resources being left to istioctl validate. The error
+// names the EnvoyFilter, the patch and the field at fault.
+func checkEnvoyFilters(manifest []byte) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(manifest))
+	for {
+		var filter envoyFilter
+		err := decoder.Decode(&filter)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if filter.Kind != "EnvoyFilter" {
+			continue
+		}
+		if len(filter.Spec.ConfigPatches) == 0 {
+			return fmt.Errorf("EnvoyFilter %s: configPatches is empty", filter.Metadata.Name)
+		}
+		for i, patch := range filter.Spec.ConfigPatches {
+			prefix := fmt.Sprintf("EnvoyFilter %s: configPatches[%d]", filter.Metadata.Name, i)
+			if !envoyFilterApplyTo[patch.ApplyTo] {
+				return fmt.Errorf("%s: applyTo %q is not a valid value", prefix, patch.ApplyTo)
+			}
+			if !envoyFilterOperations[patch.Patch.Operation] {
+				return fmt.Errorf("%s: patch.operation %q is not a valid value", prefix, patch.Patch.Operation)
+			}
+			if patch.Match.Context != "" && !envoyFilterContexts[patch.Match.Context] {
+				return fmt.Errorf("%s: match.context %q is not a valid value", prefix, patch.Match.Context)
+			}
+			if patch.Patch.Operation != "REMOVE" && patch.Patch.Value == nil {
+				return fmt.Errorf("%s: patch.value is required by the %s operation", prefix, patch.Patch.Operation)
+			}
+		}
+	}
+}
+
+// validateEnvoyFilter validates the deployment patch and the manifests of the
+// EnvoyFilter operation before anything is applied: the patch must be a JSON
+// object and the manifests are checked with checkEnvoyFilters, then with
+// istioctl validate of the given version.
+func (istio *Istio) validateEnvoyFilter(version, deploymentPatch string, manifests []string) error {
+	var patch map[string]interface{}
+	if err := json.Unmarshal([]byte(deploymentPatch), &patch); err != nil {
+		return ErrInvalidEnvoyFilter(fmt.Errorf("the deployment patch is not a JSON object: %w", err))
+	}
+	for _, manifest := range manifests {
+		if err := checkEnvoyFilters([]byte(manifest)); err != nil {
+			return ErrInvalidEnvoyFilter(err)
+		}
+	}
+
+	executable, err := istio.getExecutable(version)
+	if err != nil {
+		return ErrInvalidEnvoyFilter(fmt.Errorf("unable to validate the EnvoyFilter with istioctl %s: %w", version, err))
+	}
+	for _, manifest := range manifests {
+		if err := validateManifest(executable, []byte(manifest)); err != nil {
+			return ErrInvalidEnvoyFilter(err)
+		}
+	}
+	return nil
+}
+
+// splitEnvoyFilters splits the multi-document manifest into its EnvoyFilters
+// and the manifest of the other resources
+func splitEnvoyFilters(manifest []byte) ([]*unstructured.Unstructured, []byte, error) {
+	var filters []*unstructured.Unstructured
+	var rest []string
+	decoder := yaml.NewDecoder(bytes.NewReader(manifest))
+	for {
+		var doc interface{}
+		err := decoder.Decode(&doc)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		object, ok := jsonValue(doc).(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if object["kind"] == "EnvoyFilter" {
+			// The JSON decoding of unstructured turns the YAML integers
+			// into the int64 values it deep copies
+			data, err := json.Marshal(object)
+			if err != nil {
+				return nil, nil, err
+			}
+			filter := &unstructured.Unstructured{}
+			if err := filter.UnmarshalJSON(data); err != nil {
+				return nil, nil, err
+			}
+			filters = append(filters, filter)
+			continue
+		}
+		out, err := yaml.Marshal(object)
+		if err != nil {
+			return nil, nil, err
+		}
+		rest = append(rest, string(out))
+	}
+	return filters, []byte(strings.Join(rest, "---\n")), nil
+}
+
+// mergeEnvoyFilter merges the config patches of the EnvoyFilter into the
+// EnvoyFilter of the same name of the cluster instead of replacing it, the
+// EnvoyFilter being created if there is none. Its removal only takes out the
+// patches the merge added, the EnvoyFilter being deleted once it is left
+// without any.
+func mergeEnvoyFilter(ctx context.Context, dyn dynamic.Interface, filter *unstructured.Unstructured, del bool, namespace string) error {
+	if filter.GetNamespace() != "" {
+		namespace = filter.GetNamespace()
+	}
+	client := dyn.Resource(envoyFilterResource).Namespace(namespace)
+	patches, _, _ := unstructured.NestedSlice(filter.Object, "spec", "configPatches")
+	existing, err := client.Get(ctx, filter.GetName(), metav1.GetOptions{})
+	if kubeerror.IsNotFound(err) {
+		if del {
+			return nil
+		}
+		created := filter.DeepCopy()
+		created.SetNamespace(namespace)
+		added := make([]string, 0, len(patches))
+		for _, patch := range patches {
+			added = append(added, envoyFilterPatchHash(patch))
+		}
+		setAddedPatches(created, added)
+		_, err = client.Create(ctx, created, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	var changed bool
+	if del {
+		changed = removeEnvoyFilterPatches(existing, patches)
+		current, _, _ := unstructured.NestedSlice(existing.Object, "spec", "configPatches")
+		if len(current) == 0 {
+			return client.Delete(ctx, existing.GetName(), metav1.DeleteOptions{})
+		}
+	} else {
+		changed = mergeEnvoyFilterPatches(existing, patches)
+	}
+	if !changed {
+		return nil
+	}
+	_, err = client.Update(ctx, existing, metav1.UpdateOptions{})
+	return err
+}
+
+// mergeEnvoyFilterPatches appends the patches the EnvoyFilter doesn't have
+// yet to its config patches, recording them as added by the merge, and
+// reports whether the EnvoyFilter changed
+func mergeEnvoyFilterPatches(filter *unstructured.Unstructured, patches []interface{}) bool {
+	current, _, _ := unstructured.NestedSlice(filter.Object, "spec", "configPatches")
+	present := map[string]bool{}
+	for _, patch := range current {
+		present[envoyFilterPatchHash(patch)] = true
+	}
+	added := addedPatches(filter)
+	changed := false
+	for _, patch := range patches {
+		hash := envoyFilterPatchHash(patch)
+		if present[hash] {
+			continue
+		}
+		present[hash] = true
+		current = append(current, patch)
+		added = append(added, hash)
+		changed = true
+	}
+	if changed {
+		_ = unstructured.SetNestedSlice(filter.Object, current, "spec", "configPatches")
+		setAddedPatches(filter, added)
+	}
+	return changed
+}
+
+// removeEnvoyFilterPatches removes the patches the merge added from the
+// config patches of the EnvoyFilter, the identical ones authored by the user
+// being left in place, and reports whether the EnvoyFilter changed
+func removeEnvoyFilterPatches(filter *unstructured.Unstructured, patches []interface{}) bool {
+	remove := map[string]bool{}
+	for _, patch := range patches {
+		remove[envoyFilterPatchHash(patch)] = true
+	}
+	added := map[string]bool{}
+	for _, hash := range addedPatches(filter) {
+		added[hash] = true
+	}
+	current, _, _ := unstructured.NestedSlice(filter.Object, "spec", "configPatches")
+	kept := make([]interface{}, 0, len(current))
+	for _, patch := range current {
+		hash := envoyFilterPatchHash(patch)
+		if remove[hash] && added[hash] {
+			delete(added, hash)
+			continue
+		}
+		kept = append(kept, patch)
+	}
+	if len(kept) == len(current) {
+		return false
+	}
+	_ = unstructured.SetNestedSlice(filter.Object, kept, "spec", "configPatches")
+	remaining := make([]string, 0, len(added))
+	for hash := range added {
+		remaining = append(remaining, hash)
+	}
+	sort.Strings(remaining)
+	setAddedPatches(filter, remaining)
+	return true
+}
+
+// envoyFilterPatchHash identifies a config patch by the hash of its JSON
+// encoding, whose keys are sorted
+func envoyFilterPatchHash(patch interface{}) string {
+	out, _ := json.Marshal(patch)
+	sum := sha256.Sum256(out)
+	return hex.EncodeToString(sum[:8])
+}
+
+func addedPatches(filter *unstructured.Unstructured) []string {
+	var added []string
+	for _, hash := range strings.Split(filter.GetAnnotations()[envoyFilterPatchesAnnotation], ",") {
+		if hash != "" {
+			added = append(added, hash)
+		}
+	}
+	return added
+}
+
+func setAddedPatches(filter *unstructured.Unstructured, added []string) {
+	annotations := filter.GetAnnotations()
+	if len(added) == 0 {
+		delete(annotations, envoyFilterPatchesAnnotation)
+	} else {
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[envoyFilterPatchesAnnotation] = strings.Join(added, ",")
+	}
+	filter.SetAnnotations(annotations)
+}
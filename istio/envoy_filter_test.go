@@ -0,0 +1,192 @@
+package istio
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestCheckEnvoyFilters(t *testing.T) {
+	filter, err := os.ReadFile("../templates/imagehub/rate_limit_filter.yaml")
+	if err != nil {
+		t.Fatalf("unable to read the EnvoyFilter template: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		manifest string
+		wantErr  string
+	}{
+		{
+			name:     "imagehub filter",
+			manifest: string(filter),
+		},
+		{
+			name:     "other resources are skipped",
+			manifest: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: filters\n---\n" + string(filter),
+		},
+		{
+			name:     "invalid applyTo",
+			manifest: strings.Replace(string(filter), "applyTo: HTTP_FILTER", "applyTo: HTTP_FILTERS", 1),
+			wantErr:  `configPatches[0]: applyTo "HTTP_FILTERS"`,
+		},
+		{
+			name:     "invalid operation",
+			manifest: strings.Replace(string(filter), "operation: INSERT_BEFORE", "operation: PREPEND", 1),
+			wantErr:  `configPatches[0]: patch.operation "PREPEND"`,
+		},
+		{
+			name:     "invalid context",
+			manifest: strings.Replace(string(filter), "context: SIDECAR_INBOUND", "context: INBOUND", 1),
+			wantErr:  `configPatches[0]: match.context "INBOUND"`,
+		},
+		{
+			name: "missing value",
+			manifest: `apiVersion: networking.istio.io/v1alpha3
+kind: EnvoyFilter
+metadata:
+  name: lua
+spec:
+  configPatches:
+  - applyTo: HTTP_FILTER
+    patch:
+      operation: INSERT_FIRST
+`,
+			wantErr: "patch.value is required",
+		},
+		{
+			name: "remove without value",
+			manifest: `apiVersion: networking.istio.io/v1alpha3
+kind: EnvoyFilter
+metadata:
+  name: no-fault
+spec:
+  configPatches:
+  - applyTo: HTTP_FILTER
+    match:
+      context: SIDECAR_OUTBOUND
+    patch:
+      operation: REMOVE
+`,
+		},
+		{
+			name:     "no patches",
+			manifest: "apiVersion: networking.istio.io/v1alpha3\nkind: EnvoyFilter\nmetadata:\n  name: empty\nspec: {}\n",
+			wantErr:  "configPatches is empty",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkEnvoyFilters([]byte(tt.manifest))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkEnvoyFilters() error = %v, want none", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkEnvoyFilters() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMergeEnvoyFilter(t *testing.T) {
+	patch := func(port int64) interface{} {
+		return map[string]interface{}{
+			"applyTo": "HTTP_FILTER",
+			"match":   map[string]interface{}{"listener": map[string]interface{}{"portNumber": port}},
+			"patch":   map[string]interface{}{"operation": "INSERT_BEFORE", "value": map[string]interface{}{"name": "envoy.filter.http.wasm"}},
+		}
+	}
+	filter := func(patches ...interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "networking.istio.io/v1alpha3",
+			"kind":       "EnvoyFilter",
+			"metadata":   map[string]interface{}{"name": "imagehub-filter", "namespace": "default"},
+			"spec":       map[string]interface{}{"configPatches": patches},
+		}}
+	}
+	ctx := context.Background()
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{envoyFilterResource: "EnvoyFilterList"},
+		filter(patch(8080), patch(9091)))
+	patches := func() []interface{} {
+		got, err := dyn.Resource(envoyFilterResource).Namespace("default").Get(ctx, "imagehub-filter", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("unable to get the EnvoyFilter: %v", err)
+		}
+		patches, _, _ := unstructured.NestedSlice(got.Object, "spec", "configPatches")
+		return patches
+	}
+
+	// 9091 is authored by the user as well, hence it is neither added nor
+	// removed by the merge
+	added := filter(patch(9091), patch(9092))
+	for i := 0; i < 2; i++ {
+		if err := mergeEnvoyFilter(ctx, dyn, added, false, "default"); err != nil {
+			t.Fatalf("mergeEnvoyFilter() error = %v", err)
+		}
+	}
+	if got, want := patches(), []interface{}{patch(8080), patch(9091), patch(9092)}; !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeEnvoyFilter() patches = %v, want %v", got, want)
+	}
+
+	if err := mergeEnvoyFilter(ctx, dyn, added, true, "default"); err != nil {
+		t.Fatalf("mergeEnvoyFilter() delete error = %v", err)
+	}
+	if got, want := patches(), []interface{}{patch(8080), patch(9091)}; !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeEnvoyFilter() delete patches = %v, want %v", got, want)
+	}
+
+	// The EnvoyFilter the merge created is deleted along with its patches
+	created := filter(patch(7070))
+	created.SetName("created-filter")
+	if err := mergeEnvoyFilter(ctx, dyn, created, false, "default"); err != nil {
+		t.Fatalf("mergeEnvoyFilter() create error = %v", err)
+	}
+	if err := mergeEnvoyFilter(ctx, dyn, created, true, "default"); err != nil {
+		t.Fatalf("mergeEnvoyFilter() delete error = %v", err)
+	}
+	if _, err := dyn.Resource(envoyFilterResource).Namespace("default").Get(ctx, "created-filter", metav1.GetOptions{}); err == nil {
+		t.Errorf("mergeEnvoyFilter() left the EnvoyFilter it created without patches")
+	}
+}
+
+func TestSplitEnvoyFilters(t *testing.T) {
+	manifest := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: filters
+---
+apiVersion: networking.istio.io/v1alpha3
+kind: EnvoyFilter
+metadata:
+  name: imagehub-filter
+spec:
+  configPatches:
+  - applyTo: HTTP_FILTER
+    match:
+      listener:
+        portNumber: 9091
+`
+	filters, rest, err := splitEnvoyFilters([]byte(manifest))
+	if err != nil {
+		t.Fatalf("splitEnvoyFilters() error = %v", err)
+	}
+	if len(filters) != 1 || filters[0].GetName() != "imagehub-filter" {
+		t.Fatalf("splitEnvoyFilters() filters = %v, want imagehub-filter", filters)
+	}
+	// The EnvoyFilters are deep copied when the merge creates them
+	_ = filters[0].DeepCopy()
+	if !strings.Contains(string(rest), "kind: ConfigMap") || strings.Contains(string(rest), "EnvoyFilter") {
+		t.Errorf("splitEnvoyFilters() rest = %s, want the ConfigMap alone", rest)
+	}
+}
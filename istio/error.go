@@ -2,6 +2,9 @@
 package istio
 
 import (
+	"strconv"
+	"strings"
+
 	"github.com/layer5io/meshkit/errors"
 )
 
@@ -48,8 +51,8 @@ var (
 	// during addon deployment process
 	ErrAddonFromTemplateCode = "1012"
 
-	//ErrInvalidInstallationProfileCode implies error while invalid profile option is passed in pattern file
-	ErrInvalidInstallationProfileCode = "1013"
+	// ErrInvalidProfileCode implies error while invalid installation profile is requested
+	ErrInvalidProfileCode = "1013"
 
 	// ErrCreatingIstioClientCode represents the errors which are generated
 	// during creating istio client process
@@ -126,15 +129,267 @@ var (
 	// ErrLoadNamespaceCode implies error while finding namespace
 	ErrFetchIstioVersionsCode = "1033"
 
+	// ErrPrometheusNotFoundCode implies that no Prometheus instance could be
+	// found to query the mesh metrics from
+	ErrPrometheusNotFoundCode = "1034"
+
+	// ErrQueryPrometheusCode implies failure while querying Prometheus
+	ErrQueryPrometheusCode = "1035"
+
+	// ErrMigrateAuthPolicyCode implies failure while migrating the legacy
+	// authentication policies
+	ErrMigrateAuthPolicyCode = "1036"
+
+	// ErrInvalidPolicyConversionCode implies that a legacy authentication
+	// policy could not be converted to a valid PeerAuthentication
+	ErrInvalidPolicyConversionCode = "1037"
+
+	// ErrTrafficGatingCode implies failure while shifting traffic based on
+	// the readiness of the canary version
+	ErrTrafficGatingCode = "1038"
+
+	// ErrJUnitReportCode implies failure while rendering the JUnit report
+	ErrJUnitReportCode = "1039"
+
+	// ErrMeshUnhealthyCode implies that a component of the mesh is not healthy
+	ErrMeshUnhealthyCode = "1040"
+
+	// ErrIstioctlVersionMismatchCode implies that the istioctl client doesn't match the requested version
+	ErrIstioctlVersionMismatchCode = "1041"
+
+	// ErrInvalidMTLSModeCode implies that an unknown mTLS mode is requested
+	ErrInvalidMTLSModeCode = "1042"
+
+	// ErrApplyTelemetryCode implies failure while applying the Telemetry resources
+	ErrApplyTelemetryCode = "1043"
+
+	// ErrInstallGatewayCode implies failure while installing or removing a gateway
+	ErrInstallGatewayCode = "1044"
+
+	// ErrCustomOperationInvalidCode implies that the dry run of a custom operation found invalid resources
+	ErrCustomOperationInvalidCode = "1045"
+
+	// ErrIstioAnalyzeFailedCode implies that istioctl analyze could not be run
+	ErrIstioAnalyzeFailedCode = "1046"
+
+	// ErrIstioctlCacheCode implies failure while downloading or verifying a cached istioctl
+	ErrIstioctlCacheCode = "1047"
+
+	// ErrAddonVersionCode implies that the requested addon version is not available
+	ErrAddonVersionCode = "1048"
+
+	// ErrPatchTracingProviderCode implies failure while patching the tracing provider of the mesh config
+	ErrPatchTracingProviderCode = "1049"
+
+	// ErrAddonEndpointUnresolvedCode implies that the endpoint of an addon service couldn't be resolved
+	ErrAddonEndpointUnresolvedCode = "1050"
+
+	// ErrSampleAppNotReadyCode implies that the deployments of a sample app didn't become available
+	ErrSampleAppNotReadyCode = "1051"
+
+	// ErrGatewayAPINotInstalledCode implies that the Gateway API CRDs are not installed
+	ErrGatewayAPINotInstalledCode = "1052"
+
+	// ErrPurgeIstioCode implies failure while removing the leftovers of Istio after the uninstall
+	ErrPurgeIstioCode = "1053"
+
+	// ErrInvalidKubeContextCode implies that the requested context is not defined in the kubeconfig
+	ErrInvalidKubeContextCode = "1054"
+
+	// ErrInvalidResourceQuantityCode implies that a proxy resource is not a valid Kubernetes quantity
+	ErrInvalidResourceQuantityCode = "1055"
+
+	// ErrRolloutNotReadyCode implies that some deployments didn't become available after an install
+	ErrRolloutNotReadyCode = "1056"
+
+	// ErrInvalidAuthorizationPolicyCode implies that the properties of an AuthorizationPolicy are invalid
+	ErrInvalidAuthorizationPolicyCode = "1057"
+
+	// ErrCARotationFailedCode implies that the root CA of the mesh couldn't be rotated
+	ErrCARotationFailedCode = "1058"
+
+	// ErrInvalidEnvoyFilterCode implies that the EnvoyFilter of the operation is invalid
+	ErrInvalidEnvoyFilterCode = "1059"
+
+	// ErrCanaryUpgradeFailedCode implies that the canary upgrade to a new revision failed
+	ErrCanaryUpgradeFailedCode = "1060"
+
+	// ErrInjectionStatusCode implies that the injection of the namespaces couldn't be listed
+	ErrInjectionStatusCode = "1061"
+
+	// ErrAmbientUnsupportedVersionCode implies that ambient mode is requested for a version predating its GA
+	ErrAmbientUnsupportedVersionCode = "1062"
+
+	// ErrDeployWaypointCode implies that the waypoint proxy couldn't be deployed or removed
+	ErrDeployWaypointCode = "1063"
+
+	// ErrWorkloadRestartFailedCode implies that a workload couldn't be restarted
+	ErrWorkloadRestartFailedCode = "1064"
+
+	// ErrInvalidRequestAuthenticationCode implies that the properties of a RequestAuthentication are invalid
+	ErrInvalidRequestAuthenticationCode = "1065"
+
+	// ErrInvalidSMITestVersionCode implies that the SMI conformance tests can't be pinned to the requested version
+	ErrInvalidSMITestVersionCode = "1066"
+
+	// ErrOperationCancelledCode implies that the operation was cancelled before it completed
+	ErrOperationCancelledCode = "1067"
+
+	// ErrPreflightFailedCode implies that a cluster doesn't meet the requirements of the install
+	ErrPreflightFailedCode = "1068"
+
+	// ErrExposeServiceInvalidCode implies that the properties of the expose service operation are invalid
+	ErrExposeServiceInvalidCode = "1069"
+
+	// ErrExposeServiceCode implies that the Gateway and VirtualService of an exposed service couldn't be applied
+	ErrExposeServiceCode = "1070"
+
+	// ErrInvalidTimeoutCode implies that the operationTimeout property is not a valid duration
+	ErrInvalidTimeoutCode = "1071"
+
+	// ErrOperationTimedOutCode implies that the operation didn't complete within its operationTimeout
+	ErrOperationTimedOutCode = "1072"
+
+	// ErrInvalidKubeconfigCode implies that a kubeconfig of the request can't be parsed
+	ErrInvalidKubeconfigCode = "1073"
+
+	// ErrWriteKubeconfigCode implies that the merged kubeconfig couldn't be written to the kubeconfig handler
+	ErrWriteKubeconfigCode = "1074"
+
+	// ErrKubeconfigsCode implies that several kubeconfigs of the request are invalid
+	ErrKubeconfigsCode = "1075"
+
+	// ErrDumpMeshConfigCode implies that the mesh configuration of a cluster couldn't be collected
+	ErrDumpMeshConfigCode = "1076"
+
+	// ErrInvalidOperatorManifestCode implies that the operatorManifest request option is not a single IstioOperator
+	ErrInvalidOperatorManifestCode = "1077"
+
+	// ErrTrafficMirrorInvalidCode implies that the properties of the traffic mirror operation are invalid
+	ErrTrafficMirrorInvalidCode = "1078"
+
+	// ErrTrafficMirrorCode implies that the mirroring VirtualService couldn't be applied
+	ErrTrafficMirrorCode = "1079"
+
+	// ErrInvalidFaultInjectionCode implies that the properties of the fault injection operation are invalid
+	ErrInvalidFaultInjectionCode = "1080"
+
+	// ErrExportManifestsCode implies that the installed Istio resources of a cluster couldn't be exported
+	ErrExportManifestsCode = "1081"
+
+	// ErrBaseStillInUseCode implies that the Istio base can't be removed as istiod still runs
+	ErrBaseStillInUseCode = "1082"
+
+	// ErrInvalidExtraMetadataCode implies that the extra labels or annotations of the operation are invalid
+	ErrInvalidExtraMetadataCode = "1083"
+
+	// ErrProxyStatusFailedCode implies that the sync status of the proxies couldn't be read from istiod
+	ErrProxyStatusFailedCode = "1084"
+
+	// ErrInvalidImageHubCode implies that the image hub of the operation is not a valid registry
+	ErrInvalidImageHubCode = "1085"
+
+	// ErrAddonManifestNotFoundCode implies that the addon manifests of the requested version and of the releases near it are missing
+	ErrAddonManifestNotFoundCode = "1086"
+
+	// ErrInvalidServiceEntryCode implies that the properties of the ServiceEntry operation are invalid
+	ErrInvalidServiceEntryCode = "1087"
+
+	// ErrBugReportFailedCode implies that istioctl bug-report failed to archive the diagnostics of a cluster
+	ErrBugReportFailedCode = "1088"
+
+	// ErrIncompleteMultiClusterConfigCode implies that the multi-cluster properties of the install operation are incomplete or invalid
+	ErrIncompleteMultiClusterConfigCode = "1089"
+
+	// ErrEastWestGatewayFailedCode implies that the east-west gateway couldn't be installed or removed
+	ErrEastWestGatewayFailedCode = "1090"
+
+	// ErrRemoteSecretFailedCode implies that the secret of the remote cluster couldn't be created or removed
+	ErrRemoteSecretFailedCode = "1091"
+
+	// ErrInvalidLogLevelCode implies that the log level of the proxies is not one of the Envoy log levels
+	ErrInvalidLogLevelCode = "1092"
+
+	// ErrProxyLogLevelFailedCode implies that istioctl couldn't set the log level of some of the proxies
+	ErrProxyLogLevelFailedCode = "1093"
+
+	// ErrRenderManifestFailedCode implies that the manifest of the dry run of the install couldn't be generated
+	ErrRenderManifestFailedCode = "1094"
+
+	// ErrAddonPartiallyAppliedCode implies that some of the resources of the addon other than its workload failed to apply
+	ErrAddonPartiallyAppliedCode = "1095"
+
+	// ErrInvalidWorkloadCode implies that the WorkloadEntry or WorkloadGroup properties of the operation are invalid
+	ErrInvalidWorkloadCode = "1096"
+
+	// ErrConnectivityTestFailedCode implies that the request of the connectivity test received no response
+	ErrConnectivityTestFailedCode = "1097"
+
+	// ErrProxyConfigInvalidCode implies that the HTTP(S) proxy of the adapter is not a valid URL
+	ErrProxyConfigInvalidCode = "1098"
+
+	// ErrCleanupNamespaceCode implies failure while removing the Istio resources the adapter created in a namespace
+	ErrCleanupNamespaceCode = "1099"
+
+	// ErrInvalidTracingSamplingCode implies that the tracing sampling of the install is not a percentage
+	ErrInvalidTracingSamplingCode = "1100"
+
+	// ErrInvalidDestinationRuleCode implies that the properties of the DestinationRule operation are invalid
+	ErrInvalidDestinationRuleCode = "1101"
+
+	// ErrRevisionNotInstalledCode implies that istiod of the revision a namespace is switched to doesn't run
+	ErrRevisionNotInstalledCode = "1102"
+
+	// ErrMTLSNotEnforcedCode implies that a service of the namespace accepted a plaintext request
+	ErrMTLSNotEnforcedCode = "1103"
+
+	// ErrInvalidPilotScalingCode implies that the istiod scaling of the install is invalid
+	ErrInvalidPilotScalingCode = "1104"
+
+	// ErrProxyConfigFailedCode implies that the Envoy configuration of a sidecar couldn't be dumped
+	ErrProxyConfigFailedCode = "1105"
+
+	// ErrRollbackFailedCode implies that the control plane couldn't be rolled back to the requested version
+	ErrRollbackFailedCode = "1106"
+
+	// ErrInvalidOperationRequestCode implies that a field of the operation request is missing or invalid
+	ErrInvalidOperationRequestCode = "1107"
+
+	// ErrInvalidAllowListCode implies that a source the deny-all policy makes an exception for is invalid
+	ErrInvalidAllowListCode = "1108"
+
+	// ErrFetchLogsFailedCode implies that the logs of an Istio component couldn't be fetched
+	ErrFetchLogsFailedCode = "1109"
+
+	// ErrUnsupportedUpgradePathCode implies that the requested version skips minor versions of the running one
+	ErrUnsupportedUpgradePathCode = "1110"
+
+	// ErrInvalidCAConfigCode implies that the custom CA of the install is invalid
+	ErrInvalidCAConfigCode = "1111"
+
+	// ErrFieldConflictCode implies that the fields of a resource applied with server-side apply are owned by another field manager
+	ErrFieldConflictCode = "1112"
+
+	// ErrListInstalledComponentsCode implies that the installed components of a cluster couldn't be listed
+	ErrListInstalledComponentsCode = "1113"
+
+	// ErrInvalidTrustDomainCode implies that the trust domain of the install is invalid
+	ErrInvalidTrustDomainCode = "1114"
+
+	// ErrSMITestTimeoutCode implies that the SMI conformance tests didn't complete in time
+	ErrSMITestTimeoutCode = "1115"
+
+	// ErrWriteSMIResultsCode implies that the report of the SMI conformance tests couldn't be written to its file
+	ErrWriteSMIResultsCode = "1116"
+
+	// ErrInvalidCNIConfigCode implies that the CNI plugin options of the install are invalid or conflicting
+	ErrInvalidCNIConfigCode = "1118"
+
 	ErrFetchIstioVersions = errors.New(ErrFetchIstioVersionsCode, errors.Alert, []string{"could not get any istio versions"}, []string{"versions for istio could not be fetched"}, []string{"could not reach github.com/istio/istio/releases", "no versions could be fetched from istio release page"}, []string{"make sure adapter is reachable to github"})
 	// ErrOpInvalid represents the errors which are generated
 	// when an invalid operation is requested
 	ErrOpInvalid = errors.New(ErrOpInvalidCode, errors.Alert, []string{"Invalid operation"}, []string{"Istio adapter received an invalid operation from the meshey server"}, []string{"The operation is not supported by the adapter", "Invalid operation name"}, []string{"Check if the operation name is valid and supported by the adapter"})
 
-	// ErrParseOAMComponent represents the error which is
-	// generated during the OAM component parsing
-	ErrParseOAMComponent = errors.New(ErrParseOAMComponentCode, errors.Alert, []string{"error parsing the component"}, []string{"Error occurred while parsing application component in the OAM request made by Meshery server"}, []string{"Could not unmarshall configuration component received via ProcessOAM gRPC call into a valid Component struct"}, []string{"Check if Meshery Server is creating valid component for ProcessOAM gRPC call. This error should never happen and can be reported as a bug in Meshery Server. Also check if Meshery Server and adapters are referring to same component struct provided in MeshKit."})
-
 	// ErrParseOAMConfig represents the error which is
 	// generated during the OAM configuration parsing
 	ErrParseOAMConfig = errors.New(ErrParseOAMConfigCode, errors.Alert, []string{"error parsing the configuration"}, []string{"Error occurred while parsing configuration in the request made by Meshery Server"}, []string{"Could not unmarshall OAM config received via ProcessOAM gRPC call into a valid Config struct"}, []string{"Check if Meshery Server is creating valid config for ProcessOAM gRPC call. This error should never happen and can be reported as a bug in Meshery Server. Also, confirm that Meshery Server and Adapters are referring to same config struct provided in MeshKit"})
@@ -147,6 +402,10 @@ var (
 	// unsupported
 	ErrUnsupportedPlatform = errors.New(ErrUnsupportedPlatformCode, errors.Alert, []string{"requested platform is not supported by Istio"}, []string{"Istio only supports Windows, Linux and Darwin"}, []string{}, []string{""})
 
+	// ErrPrometheusNotFound implies that neither the Prometheus addon nor an
+	// external Prometheus is configured
+	ErrPrometheusNotFound = errors.New(ErrPrometheusNotFoundCode, errors.Alert, []string{"Unable to find Prometheus"}, []string{"No Prometheus instance is available to fetch the mesh metrics from"}, []string{"Prometheus addon is not installed in the istio-system namespace", "External Prometheus URL is not configured"}, []string{"Install the Prometheus addon or set the \"prometheus-url\" property of the operation"})
+
 	// ErrIstioctlNotFound implies istioctl was not found locally
 	ErrIstioctlNotFound = errors.New(ErrIstioctlNotFoundCode, errors.Alert, []string{"Unable to find Istioctl"}, []string{}, []string{}, []string{})
 )
@@ -267,7 +526,436 @@ func ErrLoadNamespace(err error, str string) error {
 	return errors.New(ErrLoadNamespaceCode, errors.Alert, []string{"Error while labeling namespace:", str}, []string{err.Error()}, []string{}, []string{})
 }
 
-// ErrInvalidInstallationProfile implies error while invalid profile option is passed in pattern file
-func ErrInvalidInstallationProfile(str string) error {
-	return errors.New(ErrInvalidInstallationProfileCode, errors.Alert, []string{"Error while installing istio due to wrong profile"}, []string{"Gotten profile " + str}, []string{"Invalid profile passed"}, []string{"Provide one of the profiles: \"demo\",\"minimal\",\"default\" profiles"})
+// ErrInvalidProfile implies error while invalid installation profile is requested
+func ErrInvalidProfile(profile string) error {
+	return errors.New(ErrInvalidProfileCode, errors.Alert, []string{"Error while installing istio due to invalid profile"}, []string{"Profile " + profile + " is not supported"}, []string{"Invalid profile passed in the operation or in the pattern file"}, []string{"Provide one of the profiles: \"default\", \"demo\", \"minimal\", \"ambient\""})
+}
+
+// ErrQueryPrometheus is the error when a Prometheus query fails
+func ErrQueryPrometheus(err error) error {
+	return errors.New(ErrQueryPrometheusCode, errors.Alert, []string{"Error occurred while querying Prometheus"}, []string{err.Error()}, []string{"Prometheus is not reachable", "Invalid PromQL query"}, []string{"Make sure that Prometheus is running and reachable from the adapter"})
+}
+
+// ErrMigrateAuthPolicy is the error when the legacy authentication policies migration fails
+func ErrMigrateAuthPolicy(err error) error {
+	return errors.New(ErrMigrateAuthPolicyCode, errors.Alert, []string{"Error occurred while migrating legacy authentication policies"}, []string{err.Error()}, []string{"Invalid kubeclient config", "Legacy policy could not be converted"}, []string{"Check the migration report in the event details and migrate the remaining policies manually"})
+}
+
+// ErrInvalidPolicyConversion is the error when a legacy authentication policy converts to an invalid PeerAuthentication
+func ErrInvalidPolicyConversion(source string, err error) error {
+	return errors.New(ErrInvalidPolicyConversionCode, errors.Alert, []string{"Unable to convert " + source + " to PeerAuthentication"}, []string{err.Error()}, []string{"The legacy policy uses settings which have no PeerAuthentication equivalent", "The policy target service does not exist"}, []string{"Migrate the policy manually to a PeerAuthentication"})
+}
+
+// ErrTrafficGating is the error when the readiness gated traffic shift fails
+func ErrTrafficGating(err error) error {
+	return errors.New(ErrTrafficGatingCode, errors.Alert, []string{"Error occurred while shifting traffic to the canary version"}, []string{err.Error()}, []string{"Invalid rollout properties", "Invalid kubeclient config"}, []string{"Check the service name, versions, rollout window and steps of the operation"})
+}
+
+// ErrJUnitReport is the error when the JUnit report of an operation can't be generated
+func ErrJUnitReport(err error) error {
+	return errors.New(ErrJUnitReportCode, errors.Alert, []string{"Error occurred while generating JUnit report"}, []string{err.Error()}, []string{}, []string{})
+}
+
+// ErrMeshUnhealthy is the error when a control plane component of the mesh has no ready replicas
+func ErrMeshUnhealthy(err error) error {
+	return errors.New(ErrMeshUnhealthyCode, errors.Alert, []string{"Istio service mesh is not healthy"}, []string{err.Error()}, []string{"istiod or the ingress gateway has no ready replicas", "The istio validating webhook service has no ready endpoints"}, []string{"Check the status of the pods in the istio-system namespace", "Reinstall the Istio service mesh"})
+}
+
+// ErrIstioctlVersionMismatch is the error when the istioctl client can't install the requested version
+func ErrIstioctlVersionMismatch(clientVersion, requestedVersion string) error {
+	return errors.New(ErrIstioctlVersionMismatchCode, errors.Alert, []string{"istioctl version doesn't match the requested Istio version"}, []string{"istioctl client version " + clientVersion + " can't install Istio " + requestedVersion}, []string{"An istioctl binary of another release was found in the PATH or in the meshery bin directory"}, []string{"Use an istioctl " + requestedVersion + " client instead of " + clientVersion + ", or pick the adapter release matching Istio " + clientVersion})
+}
+
+// ErrInvalidMTLSMode is the error when an unknown mTLS mode is requested
+func ErrInvalidMTLSMode(mode string) error {
+	return errors.New(ErrInvalidMTLSModeCode, errors.Alert, []string{"Invalid mTLS mode"}, []string{"mTLS mode \"" + mode + "\" is not supported"}, []string{"The mTLS mode passed in the operation is not one of STRICT, PERMISSIVE or DISABLE"}, []string{"Set the mtlsMode property to STRICT, PERMISSIVE or DISABLE"})
+}
+
+// ErrParseOAMComponent represents the error which is
+// generated during the OAM component parsing
+func ErrParseOAMComponent(component string, err error) error {
+	return errors.New(ErrParseOAMComponentCode, errors.Alert, []string{"error parsing the component " + component}, []string{"Error occurred while parsing application component " + component + " in the OAM request made by Meshery server", err.Error()}, []string{"Could not unmarshall configuration component received via ProcessOAM gRPC call into a valid Component struct", "Required settings of the component are missing"}, []string{"Check if Meshery Server is creating valid component for ProcessOAM gRPC call. This error should never happen and can be reported as a bug in Meshery Server. Also check if Meshery Server and adapters are referring to same component struct provided in MeshKit.", "Provide all the required settings of the component"})
+}
+
+// ErrApplyTelemetry is the error when the Telemetry resources can't be applied
+func ErrApplyTelemetry(err error) error {
+	return errors.New(ErrApplyTelemetryCode, errors.Alert, []string{"Error with telemetry operation"}, []string{err.Error()}, []string{"Invalid kubeclient config", "Telemetry API is not available in the installed Istio version", "Invalid tracing provider"}, []string{"Make sure that the tracing provider is defined in the extensionProviders of the mesh config"})
+}
+
+// ErrInstallGateway is the error when an ingress or egress gateway can't be installed or removed
+func ErrInstallGateway(gatewayType string, err error) error {
+	return errors.New(ErrInstallGatewayCode, errors.Alert, []string{"Error with " + gatewayType + " gateway operation"}, []string{err.Error()}, []string{"Invalid gateway replicas or service type", "istioctl is not available", "Invalid kubeclient config"}, []string{"Check the replicas and service-type properties of the operation", "Make sure that the Istio control plane is installed"})
+}
+
+// ErrCustomOperationInvalid is the error when the server side dry run of a custom operation rejects some resources
+func ErrCustomOperationInvalid(diagnostics []string) error {
+	return errors.New(ErrCustomOperationInvalidCode, errors.Alert, []string{"Custom operation manifest is invalid"}, []string{strings.Join(diagnostics, "\n")}, []string{"The manifest has resources which are rejected by the API server or the Istio validating webhook"}, diagnostics)
+}
+
+// ErrIstioAnalyzeFailed is the error when istioctl analyze fails to run, as opposed to reporting diagnostics
+func ErrIstioAnalyzeFailed(err error) error {
+	return errors.New(ErrIstioAnalyzeFailedCode, errors.Alert, []string{"Error while running istioctl analyze"}, []string{err.Error()}, []string{"istioctl is not available", "Invalid kubeclient config", "The cluster is not reachable"}, []string{"Make sure that istioctl of the requested version is available and the cluster is reachable"})
+}
+
+// ErrIstioctlCache is the error when istioctl can't be downloaded to the cache or fails the checksum verification
+func ErrIstioctlCache(err error) error {
+	return errors.New(ErrIstioctlCacheCode, errors.Alert, []string{"Error while caching istioctl"}, []string{err.Error()}, []string{"github.com is not reachable", "The downloaded istioctl archive doesn't match its published checksum", "The cache directory is not writable"}, []string{"Make sure that the adapter can reach github.com and retry, corrupted downloads are discarded", "Place the istioctl binary of the requested version in the PATH or in the meshery bin directory"})
+}
+
+// ErrAddonVersion is the error when the requested addon version is not available
+func ErrAddonVersion(version string) error {
+	return errors.New(ErrAddonVersionCode, errors.Alert, []string{"Invalid addon version"}, []string{"Addon version " + version + " is not available"}, []string{"The addon manifests are not published for the requested version"}, []string{"Use one of the versions of the addon operation, or leave the addon version empty to use the default one"})
+}
+
+// ErrPatchTracingProvider is the error when the tracing provider can't be set in the mesh config
+func ErrPatchTracingProvider(err error) error {
+	return errors.New(ErrPatchTracingProviderCode, errors.Alert, []string{"Error while patching the tracing provider"}, []string{err.Error()}, []string{"Istio is not installed with the default revision", "The mesh config in the istio ConfigMap is invalid"}, []string{"Install Istio before the tracing addon and make sure that the istio ConfigMap exists in the istio-system namespace"})
+}
+
+// ErrAddonEndpointUnresolved is the error when the endpoint the addon service is accessible at can't be resolved
+func ErrAddonEndpointUnresolved(service string, err error) error {
+	return errors.New(ErrAddonEndpointUnresolvedCode, errors.Alert, []string{"Unable to resolve the endpoint of the " + service + " addon"}, []string{err.Error()}, []string{"The cluster doesn't provision external addresses for the LoadBalancer services", "The addon service was not created"}, []string{"Use kubectl port-forward to access the addon, or install a load balancer implementation in the cluster"})
+}
+
+// ErrSampleAppNotReady is the error when the deployments of a sample app don't become available in time
+func ErrSampleAppNotReady(deployments []string, err error) error {
+	return errors.New(ErrSampleAppNotReadyCode, errors.Alert, []string{"Sample application is not ready"}, []string{"Deployments not available: " + strings.Join(deployments, ", "), err.Error()}, []string{"The images of the sample application are still being pulled", "The pods of the sample application can't be scheduled or are crashing"}, []string{"Check the events and the pods of the deployments in the namespace", "Increase the readiness timeout of the operation"})
+}
+
+// ErrGatewayAPINotInstalled is the error when the Gateway API CRDs are not installed in the clusters
+func ErrGatewayAPINotInstalled(clusters []string) error {
+	return errors.New(ErrGatewayAPINotInstalledCode, errors.Alert, []string{"Gateway API is not installed"}, []string{"The Gateway and HTTPRoute CRDs of " + gatewayAPIGroupVersion + " are not installed in the clusters: " + strings.Join(clusters, ", ")}, []string{"The Kubernetes Gateway API CRDs are not bundled with Kubernetes"}, []string{"Install the Gateway API CRDs with kubectl apply -k \"github.com/kubernetes-sigs/gateway-api/config/crd?ref=v1.0.0\""})
+}
+
+// ErrPurgeIstio is the error when the CRDs, webhook configurations or namespace of Istio can't be removed
+func ErrPurgeIstio(err error) error {
+	return errors.New(ErrPurgeIstioCode, errors.Alert, []string{"Error while purging Istio"}, []string{err.Error()}, []string{"The kubeclient is not allowed to delete the cluster scoped resources"}, []string{"Remove the remaining Istio CRDs and webhook configurations with kubectl"})
+}
+
+// ErrInvalidKubeContext is the error when the context requested for a kubeconfig is not one of its contexts
+func ErrInvalidKubeContext(context string, available []string) error {
+	return errors.New(ErrInvalidKubeContextCode, errors.Alert, []string{"Invalid kubeconfig context"}, []string{"context \"" + context + "\" is not defined in the kubeconfig, available contexts: " + strings.Join(available, ", ")}, []string{"The context passed in the kubeContexts request option is not in the contexts of the kubeconfig", "The contexts are not listed in the same order as the kubeconfigs"}, []string{"Set the kubeContexts request option to contexts defined in the kubeconfigs, in the order of the kubeconfigs"})
+}
+
+// ErrInvalidResourceQuantity is the error when a proxy resource request or limit is not a valid quantity
+func ErrInvalidResourceQuantity(key, quantity string, err error) error {
+	return errors.New(ErrInvalidResourceQuantityCode, errors.Alert, []string{"Invalid proxy resource quantity"}, []string{"\"" + quantity + "\" set for " + key + " is not a valid quantity", err.Error()}, []string{"The proxyResources property of the install operation is not a Kubernetes resource quantity"}, []string{"Use quantities such as 100m or 0.5 for cpu and 128Mi or 1Gi for memory"})
+}
+
+// ErrRolloutNotReady is the error when the deployments of an install don't become available in time
+func ErrRolloutNotReady(deployments []string, err error) error {
+	return errors.New(ErrRolloutNotReadyCode, errors.Alert, []string{"Deployments are not ready"}, []string{"Deployments not available: " + strings.Join(deployments, ", "), err.Error()}, []string{"The images of the deployments are still being pulled", "The pods of the deployments can't be scheduled or are crashing"}, []string{"Check the events and the pods of the deployments in the namespace"})
+}
+
+// ErrInvalidAuthorizationPolicy is the error when the AuthorizationPolicy properties of the operation are invalid
+func ErrInvalidAuthorizationPolicy(err error) error {
+	return errors.New(ErrInvalidAuthorizationPolicyCode, errors.Alert, []string{"Invalid authorization policy"}, []string{err.Error()}, []string{"The action is not one of ALLOW or DENY", "The rules are not a JSON list of rules with from, to and when fields", "The policy name is not a valid resource name"}, []string{"Set the action property to ALLOW or DENY and the rules property to a JSON list such as [{\"from\": [{\"source\": {\"namespaces\": [\"frontend\"]}}]}]"})
+}
+
+// ErrCARotationFailed is the error when the cacerts secret can't be replaced or istiod can't be restarted
+func ErrCARotationFailed(err error) error {
+	return errors.New(ErrCARotationFailedCode, errors.Alert, []string{"Error while rotating the root CA"}, []string{err.Error()}, []string{"The supplied CA is not a PEM encoded CA certificate, with its chain, and its private key", "The kubeclient is not allowed to update the secrets or the deployments of istio-system", "istiod didn't roll out with the new CA"}, []string{"Supply the signing certificate first, followed by its chain up to the root and its private key", "Check the logs of istiod for the errors loading the cacerts secret"})
+}
+
+// ErrInvalidEnvoyFilter is the error when the EnvoyFilter or the deployment patch of the operation are invalid
+func ErrInvalidEnvoyFilter(err error) error {
+	return errors.New(ErrInvalidEnvoyFilterCode, errors.Alert, []string{"Invalid EnvoyFilter"}, []string{err.Error()}, []string{"The applyTo, patch.operation or match.context of a config patch is not a valid value", "A config patch has no value to add, merge or insert", "istioctl validate rejects the EnvoyFilter"}, []string{"Fix the field reported in the error, see https://istio.io/latest/docs/reference/config/networking/envoy-filter/ for its valid values"})
+}
+
+// ErrCanaryUpgradeFailed is the error when a step of the canary upgrade fails
+func ErrCanaryUpgradeFailed(err error) error {
+	return errors.New(ErrCanaryUpgradeFailedCode, errors.Alert, []string{"Canary upgrade failed"}, []string{err.Error()}, []string{"The new revision couldn't be installed", "The workloads of the namespaces didn't roll out with the proxies of the new revision", "The proxies of the new revision are not ready"}, []string{"Check the logs of the istiod of the new revision and the events of the pods of the namespaces", "Remove the new revision with the install operation once the failure is understood"})
+}
+
+// ErrInjectionStatus is the error when the namespaces or their pods can't be listed
+func ErrInjectionStatus(err error) error {
+	return errors.New(ErrInjectionStatusCode, errors.Alert, []string{"Unable to get the sidecar injection status"}, []string{err.Error()}, []string{"The kubeclient is not allowed to list the namespaces or the pods"}, []string{"Allow the kubeclient to list the namespaces and the pods of the cluster"})
+}
+
+// ErrAmbientUnsupportedVersion is the error when the ambient profile is requested for a version without ambient GA
+func ErrAmbientUnsupportedVersion(version string) error {
+	return errors.New(ErrAmbientUnsupportedVersionCode, errors.Alert, []string{"Ambient mode is not supported by Istio " + version}, []string{"Ambient mode is generally available since Istio " + ambientMinVersion + ", Istio " + version + " predates it"}, []string{"The ambient profile is requested for an older Istio version"}, []string{"Install Istio " + ambientMinVersion + " or later with the ambient profile, or use a sidecar profile for " + version})
+}
+
+// ErrDeployWaypoint is the error when the waypoint proxy can't be deployed or removed
+func ErrDeployWaypoint(err error) error {
+	return errors.New(ErrDeployWaypointCode, errors.Alert, []string{"Error while deploying the waypoint proxy"}, []string{err.Error()}, []string{"The service account is not a valid name", "The namespace doesn't exist", "Istio is not installed with the ambient profile"}, []string{"Install Istio with the ambient profile and deploy the waypoint in an existing namespace"})
+}
+
+// ErrWorkloadRestartFailed is the error when the workloads of a namespace can't be listed or patched for a restart
+func ErrWorkloadRestartFailed(err error) error {
+	return errors.New(ErrWorkloadRestartFailedCode, errors.Alert, []string{"Error while restarting the workloads"}, []string{err.Error()}, []string{"The kubeclient is not allowed to list or patch the deployments, statefulsets or daemonsets of the namespace"}, []string{"Allow the kubeclient to patch the workloads of the namespace, or restart them with kubectl rollout restart"})
+}
+
+// ErrInvalidRequestAuthentication is the error when the RequestAuthentication properties of the operation are invalid
+func ErrInvalidRequestAuthentication(err error) error {
+	return errors.New(ErrInvalidRequestAuthenticationCode, errors.Alert, []string{"Invalid request authentication policy"}, []string{err.Error()}, []string{"The issuer is empty", "The jwksUri is not an http or https URL", "The policy name is not a valid resource name"}, []string{"Set the issuer property to the iss claim of the tokens and the jwksUri property to the URL of the public keys of the issuer, such as https://example.com/.well-known/jwks.json"})
+}
+
+// ErrInvalidSMITestVersion is the error when the SMI conformance tests can't be pinned to the requested version
+func ErrInvalidSMITestVersion(err error) error {
+	return errors.New(ErrInvalidSMITestVersionCode, errors.Alert, []string{"Invalid SMI conformance test version"}, []string{err.Error()}, []string{"The version is not a tag or a branch name", "The manifest of the operation is not fetched from a git ref"}, []string{"Set the smi-test-version property to a tag or a branch of the SMI conformance tests, such as master"})
+}
+
+// ErrOperationCancelled is the error when the operation is cancelled before it completed
+func ErrOperationCancelled(err error) error {
+	return errors.New(ErrOperationCancelledCode, errors.Alert, []string{"Operation cancelled"}, []string{err.Error()}, []string{"The operation was cancelled by the cancel operation before it completed"}, []string{"Run the operation again to complete it, or its delete operation to remove what it already applied"})
+}
+
+// ErrPreflightFailed is the error when the clusters don't meet the requirements of the install
+func ErrPreflightFailed(problems []string) error {
+	return errors.New(ErrPreflightFailedCode, errors.Alert, []string{"The clusters don't meet the requirements of the install"}, problems, []string{"The Kubernetes version is not supported by the Istio version", "Another Istio control plane or the Istio operator is installed", "The kubeclient is not allowed to create the cluster scoped resources of Istio"}, []string{"Install an Istio version supporting the Kubernetes version of the cluster", "Remove the other Istio installation first", "Grant the kubeclient cluster-admin for the install"})
+}
+
+// ErrExposeServiceInvalid is the error when the service can't be exposed with the properties of the operation
+func ErrExposeServiceInvalid(err error) error {
+	return errors.New(ErrExposeServiceInvalidCode, errors.Alert, []string{"Invalid service to expose"}, []string{err.Error()}, []string{"The host is not a valid DNS name", "The port or the service port is not a valid port number", "The service doesn't exist in the namespace of the operation"}, []string{"Set the host property to a DNS name such as app.example.com and the service property to a service of the namespace"})
+}
+
+// ErrExposeService is the error when the Gateway and VirtualService of the exposed service can't be applied or removed
+func ErrExposeService(err error) error {
+	return errors.New(ErrExposeServiceCode, errors.Alert, []string{"Error while exposing the service"}, []string{err.Error()}, []string{"The Istio CRDs are not installed", "The kubeclient is not allowed to create Gateways and VirtualServices in the namespace"}, []string{"Install Istio before exposing a service"})
+}
+
+// ErrInvalidTimeout is the error when the operationTimeout property can't be parsed as a positive duration
+func ErrInvalidTimeout(err error) error {
+	return errors.New(ErrInvalidTimeoutCode, errors.Alert, []string{"Invalid operation timeout"}, []string{err.Error()}, []string{"The operationTimeout property is not a Go duration"}, []string{"Set operationTimeout to a positive duration such as 90s or 10m, or leave it empty for the default timeouts"})
+}
+
+// ErrOperationTimedOut is the error when the operation is stopped once its operationTimeout is over
+func ErrOperationTimedOut(err error) error {
+	return errors.New(ErrOperationTimedOutCode, errors.Alert, []string{"Operation timed out"}, []string{err.Error()}, []string{"The operation didn't complete within its operationTimeout", "The images of the workloads are slow to pull on the cluster"}, []string{"Run the operation again with a longer operationTimeout"})
+}
+
+// ErrInvalidKubeconfig is the error when the kubeconfig at the index of the request can't be parsed, context being the context requested for it if any
+func ErrInvalidKubeconfig(index int, context string, err error) error {
+	kubeconfig := "kubeconfig at index " + strconv.Itoa(index)
+	if context != "" {
+		kubeconfig += " (context " + strconv.Quote(context) + ")"
+	}
+	return errors.New(ErrInvalidKubeconfigCode, errors.Alert, []string{"Invalid kubeconfig"}, []string{kubeconfig + " can't be parsed: " + err.Error()}, []string{"The kubeconfig is not valid YAML", "The kubeconfig was truncated or its base64 encoding is corrupted"}, []string{"Check the kubeconfig at the index with kubectl config view --kubeconfig, and upload it again"})
+}
+
+// ErrWriteKubeconfig is the error when the key of the merged kubeconfig can't be written to the kubeconfig handler
+func ErrWriteKubeconfig(key string, err error) error {
+	return errors.New(ErrWriteKubeconfigCode, errors.Alert, []string{"Error while writing the merged kubeconfig"}, []string{"unable to write the " + key + " of the merged kubeconfig: " + err.Error()}, []string{"The kubeconfig handler of the adapter can't serialize the entries of the kubeconfigs", "The kubeconfig file of the adapter is not writable"}, []string{"Make sure the adapter can write to its config directory, and check the entries of the kubeconfigs"})
+}
+
+// ErrKubeconfigs is the error listing the failures of several kubeconfigs of the request
+func ErrKubeconfigs(errs []error) error {
+	var details []string
+	for _, err := range errs {
+		details = append(details, err.Error())
+	}
+	return errors.New(ErrKubeconfigsCode, errors.Alert, []string{"Invalid kubeconfigs"}, []string{strings.Join(details, "\n")}, []string{"Several kubeconfigs of the request are malformed or select contexts they don't define"}, []string{"Fix each of the kubeconfigs listed in the description, they are identified by their index in the request"})
+}
+
+// ErrDumpMeshConfig is the error when the mesh configuration of a cluster can't be collected
+func ErrDumpMeshConfig(err error) error {
+	return errors.New(ErrDumpMeshConfigCode, errors.Alert, []string{"Error while dumping the mesh configuration"}, []string{err.Error()}, []string{"The kubeclient is not allowed to read the ConfigMaps of istio-system or the CRDs", "The mesh config of the istio ConfigMap is not valid YAML"}, []string{"Grant the kubeclient read access to istio-system and the CustomResourceDefinitions"})
+}
+
+// ErrInvalidOperatorManifest is the error when the operatorManifest request option is not a well-formed IstioOperator
+func ErrInvalidOperatorManifest(err error) error {
+	return errors.New(ErrInvalidOperatorManifestCode, errors.Alert, []string{"Invalid IstioOperator manifest"}, []string{err.Error()}, []string{"The operatorManifest request option is not valid YAML", "The manifest is not a single install.istio.io IstioOperator", "The profile of the IstioOperator is not supported by the adapter"}, []string{"Pass a single IstioOperator with one of the default, demo, minimal or ambient profiles, istioctl manifest generate -f can check it beforehand"})
+}
+
+// ErrTrafficMirrorInvalid is the error when the traffic can't be mirrored with the properties of the operation
+func ErrTrafficMirrorInvalid(err error) error {
+	return errors.New(ErrTrafficMirrorInvalidCode, errors.Alert, []string{"Invalid traffic mirroring"}, []string{err.Error()}, []string{"The mirror percentage is not between 0 and 100", "The mirrored service or the mirror target doesn't exist in the namespace of the operation"}, []string{"Deploy BookInfo along with its version services, from samples/bookinfo/platform/kube/bookinfo-versions.yaml, before mirroring its traffic"})
+}
+
+// ErrTrafficMirror is the error when the mirroring VirtualService can't be applied or removed
+func ErrTrafficMirror(err error) error {
+	return errors.New(ErrTrafficMirrorCode, errors.Alert, []string{"Error while mirroring the traffic"}, []string{err.Error()}, []string{"The Istio CRDs are not installed", "The kubeclient is not allowed to create VirtualServices in the namespace"}, []string{"Install Istio before mirroring the traffic of a service"})
+}
+
+// ErrInvalidFaultInjection is the error when the faults of the fault injection operation are invalid
+func ErrInvalidFaultInjection(err error) error {
+	return errors.New(ErrInvalidFaultInjectionCode, errors.Alert, []string{"Invalid fault injection"}, []string{err.Error()}, []string{"The delayPercent or abortPercent is not between 0 and 100", "The fixedDelay is not a duration such as 7s", "The httpStatus is not between 200 and 599"}, []string{"Set delayPercent with fixedDelay, or abortPercent with httpStatus, or both"})
+}
+
+// ErrExportManifests is the error when the installed Istio resources of a cluster can't be exported
+func ErrExportManifests(err error) error {
+	return errors.New(ErrExportManifestsCode, errors.Alert, []string{"Error while exporting the installed manifests"}, []string{err.Error()}, []string{"The kubeclient is not allowed to list the Istio resources or the addon resources of the namespace", "The API discovery of the cluster failed"}, []string{"Grant the kubeclient list access to the istio.io API groups and to the addon resources"})
+}
+
+// ErrBaseStillInUse is the error when the Istio base is removed from a cluster istiod still runs on
+func ErrBaseStillInUse(cluster string, deployments []string) error {
+	return errors.New(ErrBaseStillInUseCode, errors.Alert, []string{"Istio base still in use"}, []string{"istiod still runs on " + cluster + ": " + strings.Join(deployments, ", ")}, []string{"The control plane of a revision is still installed, removing the CRDs would remove all the Istio resources it serves"}, []string{"Uninstall every revision of Istio before removing the Istio base"})
+}
+
+// ErrInvalidExtraMetadata is the error when the extraLabels or extraAnnotations property of the operation is invalid
+func ErrInvalidExtraMetadata(property string, err error) error {
+	return errors.New(ErrInvalidExtraMetadataCode, errors.Alert, []string{"Invalid " + property}, []string{err.Error()}, []string{"The property is not a comma separated list of key=value pairs", "A key is not a valid Kubernetes label or annotation key, or a label value is not a valid label value", "A key is in the istio.io domains or is one of the app, version, istio and release labels Istio selects resources with"}, []string{"Set the property to a list such as team=payments,cost-center=42 with keys outside of the istio.io domains"})
+}
+
+// ErrProxyStatusFailed is the error when istioctl proxy-status fails on a cluster
+func ErrProxyStatusFailed(err error) error {
+	return errors.New(ErrProxyStatusFailedCode, errors.Alert, []string{"Error while reading the proxy sync status"}, []string{err.Error()}, []string{"istiod is not running or can't be reached by istioctl", "istioctl of the requested version couldn't be found or downloaded"}, []string{"Make sure istiod is running in istio-system and that the kubeclient is allowed to port-forward to it"})
+}
+
+// ErrInvalidImageHub is the error when the imageHub property of the operation is not a host[:port]/path
+func ErrInvalidImageHub(hub string, err error) error {
+	return errors.New(ErrInvalidImageHubCode, errors.Alert, []string{"Invalid image hub"}, []string{"Invalid image hub " + hub + ": " + err.Error()}, []string{"The imageHub property is not a registry host, with an optional port, followed by an optional repository path"}, []string{"Set imageHub to the registry mirroring the Istio images, such as registry.internal:5000/istio"})
+}
+
+// ErrAddonManifestNotFound is the error when the addon manifests are missing for the requested version and no other release has them
+func ErrAddonManifestNotFound(version string) error {
+	return errors.New(ErrAddonManifestNotFoundCode, errors.Alert, []string{"Addon manifest not found"}, []string{"The addon manifests of Istio " + version + " are missing, and no other release of the same major version has them"}, []string{"The addon is not published with the requested release of Istio"}, []string{"Set the addonVersion of the operation to a release publishing the addon manifests"})
+}
+
+// ErrInvalidServiceEntry is the error when the ServiceEntry can't be rendered from the properties of the operation
+func ErrInvalidServiceEntry(err error) error {
+	return errors.New(ErrInvalidServiceEntryCode, errors.Alert, []string{"Invalid ServiceEntry"}, []string{err.Error()}, []string{"No host is set or a host is not a DNS name", "The resolution is not one of NONE, STATIC and DNS", "A port is not a number followed by one of the Istio protocols, such as 443/TLS", "The STATIC resolution is used without endpoint addresses"}, []string{"Set the hosts and ports of the external service, the DNS resolution suits most of the external APIs"})
+}
+
+// ErrBugReportFailed is the error when istioctl bug-report fails on a cluster
+func ErrBugReportFailed(err error) error {
+	return errors.New(ErrBugReportFailedCode, errors.Alert, []string{"Error while collecting the bug report"}, []string{err.Error()}, []string{"The cluster can't be reached or the kubeclient is not allowed to read the logs and the configuration of the pods", "istioctl of the requested version couldn't be found or downloaded", "The download location of the adapter is not writable"}, []string{"Make sure the cluster is reachable and that the adapter can write to its temporary directory, then run the report again scoped to a single namespace"})
+}
+
+// ErrIncompleteMultiClusterConfig is the error when the meshID, clusterName and network properties of the install operation don't make a valid multi-cluster topology
+func ErrIncompleteMultiClusterConfig(err error) error {
+	return errors.New(ErrIncompleteMultiClusterConfigCode, errors.Alert, []string{"Incomplete multi-cluster configuration"}, []string{err.Error()}, []string{"Only some of the meshID and clusterName properties are set", "A property is not a valid label value"}, []string{"Set both the meshID and the clusterName, along with the network when the clusters are on different networks, or leave all of them empty"})
+}
+
+// ErrEastWestGatewayFailed is the error when the east-west gateway of the network can't be installed or removed, or isn't allocated an external address
+func ErrEastWestGatewayFailed(err error) error {
+	return errors.New(ErrEastWestGatewayFailedCode, errors.Alert, []string{"Error while installing the east-west gateway"}, []string{err.Error()}, []string{"The network of the gateway is not set or not a valid label value", "The cluster doesn't allocate external addresses to the LoadBalancer services", "istioctl of the requested version couldn't be found or downloaded"}, []string{"Set the network the control plane was installed with and make sure the cluster provisions load balancers, such as with MetalLB on bare metal clusters"})
+}
+
+// ErrRemoteSecretFailed is the error when the secret granting istiod access to the remote cluster can't be created or removed
+func ErrRemoteSecretFailed(err error) error {
+	return errors.New(ErrRemoteSecretFailedCode, errors.Alert, []string{"Error while creating the remote secret"}, []string{err.Error()}, []string{"The kubeconfig of the remote cluster is missing or invalid", "The API server of the remote cluster can't be reached from the adapter", "The remote cluster name is not a valid label value", "istioctl of the requested version couldn't be found or downloaded"}, []string{"Provide a kubeconfig of the remote cluster whose API server is reachable from the clusters, and set the cluster name the remote control plane was installed with"})
+}
+
+// ErrInvalidLogLevel is the error when the log level of the proxies is not one of the Envoy log levels
+func ErrInvalidLogLevel(level string) error {
+	return errors.New(ErrInvalidLogLevelCode, errors.Alert, []string{"Invalid proxy log level"}, []string{"\"" + level + "\" is not an Envoy log level"}, []string{"The log level is not one of trace, debug, info, warning, error, critical and off"}, []string{"Set the logLevel of the operation to one of trace, debug, info, warning, error, critical and off"})
+}
+
+// ErrProxyLogLevelFailed is the error when istioctl proxy-config log fails on some of the proxies
+func ErrProxyLogLevelFailed(err error) error {
+	return errors.New(ErrProxyLogLevelFailedCode, errors.Alert, []string{"Error while setting the log level of the proxies"}, []string{err.Error()}, []string{"The pod doesn't exist or has no sidecar", "The admin port of the proxy can't be reached through the API server", "istioctl of the requested version couldn't be found or downloaded"}, []string{"Make sure the pods of the namespace are running with an injected sidecar, then set the log level again"})
+}
+
+// ErrRenderManifestFailed is the error when istioctl can't generate the manifest of the dry run of the install
+func ErrRenderManifestFailed(err error) error {
+	return errors.New(ErrRenderManifestFailedCode, errors.Alert, []string{"Error while rendering the Istio manifest"}, []string{err.Error()}, []string{"istioctl of the requested version couldn't be found or downloaded", "The IstioOperator of the operation is not valid for the requested version"}, []string{"Check the profile, revision and IstioOperator of the operation against the requested version of Istio"})
+}
+
+// ErrAddonPartiallyApplied is the warning when some of the resources of the addon other than its workload, or its service patches, failed to apply while the addon is installed
+func ErrAddonPartiallyApplied(addon string, failures []string) error {
+	return errors.New(ErrAddonPartiallyAppliedCode, errors.Alert, []string{addon + " partially applied"}, failures, []string{"A CRD the resource depends on is not installed on the cluster", "The kubeclient is not allowed to create the resource", "The service patch of the addon can't be read or applied"}, []string{"The addon is running, fix the failing resources and install the addon again to apply them"})
+}
+
+// ErrInvalidWorkload is the error when the WorkloadEntry or WorkloadGroup can't be rendered from the properties of the operation
+func ErrInvalidWorkload(err error) error {
+	return errors.New(ErrInvalidWorkloadCode, errors.Alert, []string{"Invalid VM workload"}, []string{err.Error()}, []string{"The name is not set or not a DNS name", "The address of the WorkloadEntry is neither an IP address nor a DNS name", "The WorkloadGroup has no labels", "A label is not a key=value pair of a valid label"}, []string{"Set the name and the labels of the VM workload, along with the address of the VM for a WorkloadEntry"})
+}
+
+// ErrConnectivityTestFailed is the error when the request sent from the source pod to the target service of the connectivity test receives no response
+func ErrConnectivityTestFailed(err error) error {
+	return errors.New(ErrConnectivityTestFailedCode, errors.Alert, []string{"Connectivity test failed"}, []string{err.Error()}, []string{"The source pod doesn't exist in the namespace or its application container has no curl", "The connection was reset, such as by a STRICT PeerAuthentication when the source pod has no sidecar", "The target service doesn't exist or doesn't listen on the port"}, []string{"Check the PeerAuthentication and AuthorizationPolicy of the namespace of the target, and that the source pod is running with a sidecar"})
+}
+
+// ErrProxyConfigInvalid is the error when the HTTP(S) proxy of the environment or of the operation is not a valid URL
+func ErrProxyConfigInvalid(err error) error {
+	return errors.New(ErrProxyConfigInvalidCode, errors.Alert, []string{"Invalid HTTP proxy configuration"}, []string{err.Error()}, []string{"The HTTP_PROXY or HTTPS_PROXY environment variable, or the httpProxy or httpsProxy property, is not a URL", "The scheme of the proxy is not one of http, https and socks5"}, []string{"Set the proxy as a URL such as http://proxy.corp.example.com:3128, along with the credentials in its user info if the proxy requires them"})
+}
+
+// ErrCleanupNamespace is the error when the Istio resources the adapter created in the namespace can't be listed or removed
+func ErrCleanupNamespace(err error) error {
+	return errors.New(ErrCleanupNamespaceCode, errors.Alert, []string{"Error while cleaning up the Istio resources of the namespace"}, []string{err.Error()}, []string{"The request has no namespace", "The kubeclient is not allowed to list or delete the Istio resources of the namespace"}, []string{"Set the namespace of the request", "Grant the adapter the permission to delete the Istio resources of the namespace"})
+}
+
+// ErrInvalidTracingSampling is the error when the tracing sampling of the install is not a percentage between 0 and 100
+func ErrInvalidTracingSampling(value string, err error) error {
+	return errors.New(ErrInvalidTracingSamplingCode, errors.Alert, []string{"Invalid tracing sampling"}, []string{"tracing sampling \"" + value + "\": " + err.Error()}, []string{"The tracingSampling property is not a number", "The tracingSampling property is not between 0 and 100"}, []string{"Set tracingSampling to the percentage of the requests to trace, such as 10 or 0.5"})
+}
+
+// ErrInvalidDestinationRule is the error when the DestinationRule can't be rendered from the properties of the operation
+func ErrInvalidDestinationRule(err error) error {
+	return errors.New(ErrInvalidDestinationRuleCode, errors.Alert, []string{"Invalid DestinationRule"}, []string{err.Error()}, []string{"No host is set or the host is not a DNS name", "The loadBalancer is not one of ROUND_ROBIN, LEAST_REQUEST, RANDOM and PASSTHROUGH", "A connection pool limit or consecutive5xxErrors is not a positive number", "The interval or baseEjectionTime is not a duration such as 10s", "No traffic policy is set"}, []string{"Set the host along with the loadBalancer, the connection pool limits or consecutive5xxErrors for the outlier detection"})
+}
+
+// ErrRevisionNotInstalled is the error when istiod of the revision the namespace is switched to doesn't run on some clusters
+func ErrRevisionNotInstalled(revision string, clusters []string) error {
+	return errors.New(ErrRevisionNotInstalledCode, errors.Alert, []string{"Revision " + revision + " is not installed"}, []string{"istiod of revision " + revision + " doesn't run on " + strings.Join(clusters, ", ") + ", the namespace was left untouched"}, []string{"The revision was not installed or was uninstalled", "The revision is misspelled"}, []string{"Install the revision with the Istio operation first, or switch to one of the revisions running in istio-system"})
+}
+
+// ErrMTLSNotEnforced is the error when services of the namespace accepted plaintext requests
+func ErrMTLSNotEnforced(namespace string, services []string) error {
+	return errors.New(ErrMTLSNotEnforcedCode, errors.Alert, []string{"mTLS is not enforced in " + namespace}, []string{"Plaintext accepted by " + strings.Join(services, ", ")}, []string{"No STRICT PeerAuthentication applies to the namespace or to the workloads of the services", "A port level mTLS setting of a PeerAuthentication is PERMISSIVE or DISABLE", "The pods of the services run without a sidecar"}, []string{"Apply the strict mTLS policy to the mesh or the namespace, and check that the pods of the services are injected"})
+}
+
+// ErrInvalidPilotScaling is the error when the istiod replica count or the bounds of its HPA are invalid
+func ErrInvalidPilotScaling(err error) error {
+	return errors.New(ErrInvalidPilotScalingCode, errors.Alert, []string{"Invalid istiod scaling"}, []string{err.Error()}, []string{"A replica count or the target CPU utilization is not a positive integer", "pilotMinReplicas is greater than pilotMaxReplicas", "pilotReplicaCount is set along with the HPA bounds"}, []string{"Set either pilotReplicaCount or the HPA bounds to positive integers, pilotMinReplicas being at most pilotMaxReplicas"})
+}
+
+// ErrProxyConfigFailed is the error when istioctl proxy-config fails to dump the configuration of the sidecar of a pod
+func ErrProxyConfigFailed(err error) error {
+	return errors.New(ErrProxyConfigFailedCode, errors.Alert, []string{"Error while dumping the proxy config"}, []string{err.Error()}, []string{"The config type is not clusters, listeners, routes or endpoints", "The pod doesn't exist in the namespace or runs without a sidecar", "istioctl of the requested version couldn't be found or downloaded"}, []string{"Set the pod property to a pod of the namespace with a sidecar and the configType property to clusters, listeners, routes or endpoints"})
+}
+
+// ErrRollbackFailed is the error when the control plane can't be rolled back to the version
+func ErrRollbackFailed(version string, err error) error {
+	return errors.New(ErrRollbackFailedCode, errors.Alert, []string{"Error while rolling back Istio to " + version}, []string{err.Error()}, []string{"The requested version is not older than the running one", "The control plane of the default revision isn't running on every cluster", "The release of the requested version couldn't be downloaded", "The charts of the requested version couldn't be applied"}, []string{"Request a version older than the running one and make sure its release can be downloaded, the proxies are left running their version until their workloads are restarted"})
+}
+
+// ErrInvalidOperationRequest is the error when the fields of the operation request the operation relies on are missing or invalid
+func ErrInvalidOperationRequest(operation string, problems []string) error {
+	return errors.New(ErrInvalidOperationRequestCode, errors.Alert, []string{"Invalid request of the operation " + operation}, []string{strings.Join(problems, ", ")}, []string{"The namespace of an operation applying to a namespace is empty", "The namespace is not a valid namespace name", "The custom operation has no manifest"}, []string{"Set the namespace of the request, and the manifest for a custom operation"})
+}
+
+// ErrInvalidAllowList is the error when an entry of the allowFrom property of the deny-all policy is not a namespace, a namespace/service-account pair or a principal
+func ErrInvalidAllowList(err error) error {
+	return errors.New(ErrInvalidAllowListCode, errors.Alert, []string{"Invalid deny-all exceptions"}, []string{err.Error()}, []string{"An entry of allowFrom is not a namespace, a namespace/service-account pair or a <trust-domain>/ns/<namespace>/sa/<service-account> principal", "A namespace or service account is not a valid name"}, []string{"Set allowFrom to a comma separated list such as frontend,payments/checkout,cluster.local/ns/billing/sa/api"})
+}
+
+// ErrFetchLogsFailed is the error when the logs of the pods of an Istio component can't be fetched
+func ErrFetchLogsFailed(component string, err error) error {
+	return errors.New(ErrFetchLogsFailedCode, errors.Alert, []string{"Error while fetching the logs of " + component}, []string{err.Error()}, []string{"The component is not istiod, a gateway, ztunnel or cni", "The tail lines are not a number between 1 and 5000", "The component has no pod in the namespace on a cluster"}, []string{"Set the component property to istiod or a gateway such as ingressgateway, and the namespace to the one the component runs in"})
+}
+
+// ErrUnsupportedUpgradePath is the error when the control plane would be upgraded by more than one minor version on some clusters
+func ErrUnsupportedUpgradePath(version string, problems []string) error {
+	return errors.New(ErrUnsupportedUpgradePathCode, errors.Alert, []string{"Unsupported upgrade of Istio to " + version}, []string{"Istio supports upgrading one minor version at a time: " + strings.Join(problems, "; ")}, []string{"The requested version is more than one minor version later than the running control plane", "The running control plane is of another major version"}, []string{"Upgrade through every intermediate minor version in turn, or uninstall the control plane before installing Istio " + version})
+}
+
+// ErrInvalidCAConfig is the error when the custom CA options of the install are invalid, set together, or the secret of the plugged-in CA can't be copied
+func ErrInvalidCAConfig(err error) error {
+	return errors.New(ErrInvalidCAConfigCode, errors.Alert, []string{"Invalid custom CA configuration"}, []string{err.Error()}, []string{"More than one of caSigner, caAddress and caCertsSecret is set", "The signer is not a <domain>/<name> signer name or the address not a host:port address", "The secret of the plugged-in CA doesn't exist or lacks the ca-cert.pem, ca-key.pem, cert-chain.pem and root-cert.pem keys"}, []string{"Set only one of caSigner, caAddress and caCertsSecret", "Create the secret of the plugged-in CA with the cacerts keys, or the tls.crt, tls.key and ca.crt keys cert-manager writes"})
+}
+
+// ErrFieldConflict is the error when server-side apply can't set fields of a resource another field manager owns
+func ErrFieldConflict(resource string, err error) error {
+	return errors.New(ErrFieldConflictCode, errors.Alert, []string{"Conflicting field ownership of " + resource}, []string{err.Error()}, []string{"Fields of the resource are owned by another field manager, such as kubectl, an operator or a client-side apply", "The resource was changed by hand after the adapter applied it"}, []string{"Remove the conflicting fields from the resource, or delete the resource and apply it again through the adapter", "Stop the controller managing the resource before applying it with the adapter"})
+}
+
+// ErrListInstalledComponents is the error when the IstioOperators, the control planes or the addons of a cluster can't be listed
+func ErrListInstalledComponents(err error) error {
+	return errors.New(ErrListInstalledComponentsCode, errors.Alert, []string{"Error while listing the installed Istio components"}, []string{err.Error()}, []string{"The kubeclient is not allowed to list the IstioOperators of istio-system or the deployments of the cluster", "The cluster is not reachable"}, []string{"Grant the kubeclient read access to the deployments of every namespace and the IstioOperators of istio-system"})
+}
+
+// ErrInvalidTrustDomain is the error when the trust domain or one of the trust domain aliases of the install is not a DNS subdomain
+func ErrInvalidTrustDomain(err error) error {
+	return errors.New(ErrInvalidTrustDomainCode, errors.Alert, []string{"Invalid trust domain"}, []string{err.Error()}, []string{"The trust domain or a trust domain alias is not a lowercase DNS subdomain such as cluster.local or example.com", "The trust domain aliases are not separated by commas"}, []string{"Set trustDomain to a DNS subdomain such as example.com, and trustDomainAliases to a comma separated list of DNS subdomains"})
+}
+
+// ErrSMITestTimeout is the error when the SMI conformance tests are stopped once their timeout is over
+func ErrSMITestTimeout(err error) error {
+	return errors.New(ErrSMITestTimeoutCode, errors.Alert, []string{"SMI conformance tests timed out"}, []string{err.Error()}, []string{"The conformance tool didn't become reachable or its runner stalled", "The images of the conformance tool are slow to pull on the cluster", "The tests take longer than the operationTimeout of the operation"}, []string{"Check the pods of the conformance tool in the meshery namespace, then run the tests again", "Run the tests again with a longer operationTimeout"})
+}
+
+// ErrWriteSMIResults is the error when the report of the SMI conformance tests can't be written to the results directory
+func ErrWriteSMIResults(err error) error {
+	return errors.New(ErrWriteSMIResultsCode, errors.Alert, []string{"Error while writing the SMI conformance report"}, []string{err.Error()}, []string{"The results directory can't be created or written to by the adapter", "The Kubernetes version of a cluster can't be read"}, []string{"Set smi-results-dir to a directory the adapter can write to, such as a volume mounted in its container"})
+}
+
+// ErrInvalidCNIConfig is the error when the CNI plugin options of the install are invalid or conflict with each other or with the profile
+func ErrInvalidCNIConfig(err error) error {
+	return errors.New(ErrInvalidCNIConfigCode, errors.Alert, []string{"Invalid Istio CNI configuration"}, []string{err.Error()}, []string{"cni.chained, cni.cniBinDir or cni.cniConfDir is set without cni.enabled being true", "cni.enabled or cni.chained is not a boolean", "cni.cniBinDir or cni.cniConfDir is not an absolute path", "cni.enabled is false with the ambient profile"}, []string{"Set cni.enabled to true along with the other CNI options, or leave them all empty", "Use the host directories of the CNI plugins of the cluster, such as /home/kubernetes/bin on GKE"})
 }
@@ -0,0 +1,191 @@
+package istio
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
+)
+
+// addonApps are the app labels of the addon workloads, the addon manifests
+// label their resources with either app or app.kubernetes.io/name
+var addonApps = []string{"prometheus", "grafana", "kiali", "jaeger", "zipkin", "loki", "tempo"}
+
+// addonResources are the kinds of resources the addon manifests are made of
+var addonResources = []schema.GroupVersionResource{
+	{Group: "apps", Version: "v1", Resource: "deployments"},
+	{Version: "v1", Resource: "services"},
+	{Version: "v1", Resource: "configmaps"},
+	{Version: "v1", Resource: "serviceaccounts"},
+}
+
+// ManifestBundle is the manifest of the Istio resources installed on a
+// single cluster
+type ManifestBundle struct {
+	Cluster   string
+	Resources int
+
+	// Manifest is a multi-document YAML of the resources, stripped of their
+	// cluster specific fields
+	Manifest string
+}
+
+// exportInstalledManifests exports the Istio resources of the namespace, of
+// all the namespaces if it is empty, as a manifest bundle for every cluster.
+// The bundles hold the resources of the istio.io API groups, among which the
+// IstioOperators, the Gateways and the policies, and the resources of the
+// addons, sorted by kind, namespace and name.
+func (istio *Istio) exportInstalledManifests(namespace string, kubeConfigs []string) ([]ManifestBundle, error) {
+	var mx sync.Mutex
+	var bundles []ManifestBundle
+	err := forEachCluster(kubeConfigs, func(k8sconfig string) error {
+		disc, dyn, err := dryRunClients(k8sconfig)
+		if err != nil {
+			return err
+		}
+		resources, err := exportCluster(context.TODO(), disc, dyn, namespace)
+		if err != nil {
+			return err
+		}
+		manifest, err := bundleManifest(resources)
+		if err != nil {
+			return err
+		}
+		mx.Lock()
+		bundles = append(bundles, ManifestBundle{Cluster: clusterName(k8sconfig), Resources: len(resources), Manifest: manifest})
+		mx.Unlock()
+		return nil
+	}, nil)
+	sort.Slice(bundles, func(i, j int) bool { return bundles[i].Cluster < bundles[j].Cluster })
+	if err != nil {
+		return bundles, ErrExportManifests(err)
+	}
+	return bundles, nil
+}
+
+// exportCluster lists the Istio and addon resources of the namespace
+func exportCluster(ctx context.Context, disc discovery.DiscoveryInterface, dyn dynamic.Interface, namespace string) ([]unstructured.Unstructured, error) {
+	groupResources, err := restmapper.GetAPIGroupResources(disc)
+	if err != nil {
+		return nil, err
+	}
+	var istioResources []schema.GroupVersionResource
+	for _, group := range groupResources {
+		if group.Group.Name != "istio.io" && !strings.HasSuffix(group.Group.Name, ".istio.io") {
+			continue
+		}
+		version := group.Group.PreferredVersion.Version
+		for _, resource := range group.VersionedResources[version] {
+			if strings.Contains(resource.Name, "/") || !resource.Namespaced || !canList(resource) {
+				continue
+			}
+			istioResources = append(istioResources, schema.GroupVersionResource{Group: group.Group.Name, Version: version, Resource: resource.Name})
+		}
+	}
+
+	var resources []unstructured.Unstructured
+	for _, gvr := range istioResources {
+		list, err := dyn.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("unable to list %s: %w", gvr.GroupResource(), err)
+		}
+		resources = append(resources, list.Items...)
+	}
+
+	exported := map[string]bool{}
+	for _, gvr := range addonResources {
+		for _, label := range []string{"app", "app.kubernetes.io/name"} {
+			selector := fmt.Sprintf("%s in (%s)", label, strings.Join(addonApps, ","))
+			list, err := dyn.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+			if err != nil {
+				return nil, fmt.Errorf("unable to list the addon %s: %w", gvr.Resource, err)
+			}
+			for _, item := range list.Items {
+				key := fmt.Sprintf("%s/%s/%s", gvr.Resource, item.GetNamespace(), item.GetName())
+				if !exported[key] {
+					exported[key] = true
+					resources = append(resources, item)
+				}
+			}
+		}
+	}
+
+	for i := range resources {
+		stripClusterFields(&resources[i])
+	}
+	sort.SliceStable(resources, func(i, j int) bool {
+		a, b := resources[i], resources[j]
+		if a.GetAPIVersion() != b.GetAPIVersion() {
+			return a.GetAPIVersion() < b.GetAPIVersion()
+		}
+		if a.GetKind() != b.GetKind() {
+			return a.GetKind() < b.GetKind()
+		}
+		if a.GetNamespace() != b.GetNamespace() {
+			return a.GetNamespace() < b.GetNamespace()
+		}
+		return a.GetName() < b.GetName()
+	})
+	return resources, nil
+}
+
+func canList(resource metav1.APIResource) bool {
+	for _, verb := range resource.Verbs {
+		if verb == "list" {
+			return true
+		}
+	}
+	return false
+}
+
+// stripClusterFields removes the fields set by the cluster the resource is
+// read from, so that the resource can be applied to another cluster
+func stripClusterFields(obj *unstructured.Unstructured) {
+	unstructured.RemoveNestedField(obj.Object, "status")
+	for _, field := range []string{"uid", "resourceVersion", "creationTimestamp", "generation", "managedFields", "selfLink", "ownerReferences"} {
+		unstructured.RemoveNestedField(obj.Object, "metadata", field)
+	}
+	annotations := obj.GetAnnotations()
+	delete(annotations, "kubectl.kubernetes.io/last-applied-configuration")
+	delete(annotations, "deployment.kubernetes.io/revision")
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	obj.SetAnnotations(annotations)
+	if obj.GetKind() == "Service" {
+		for _, field := range []string{"clusterIP", "clusterIPs"} {
+			unstructured.RemoveNestedField(obj.Object, "spec", field)
+		}
+		ports, _, _ := unstructured.NestedSlice(obj.Object, "spec", "ports")
+		for _, port := range ports {
+			if p, ok := port.(map[string]interface{}); ok {
+				delete(p, "nodePort")
+			}
+		}
+		if ports != nil {
+			_ = unstructured.SetNestedSlice(obj.Object, ports, "spec", "ports")
+		}
+	}
+}
+
+// bundleManifest marshals the resources to a multi-document YAML
+func bundleManifest(resources []unstructured.Unstructured) (string, error) {
+	docs := make([]string, 0, len(resources))
+	for _, resource := range resources {
+		doc, err := yaml.Marshal(resource.Object)
+		if err != nil {
+			return "", err
+		}
+		docs = append(docs, string(doc))
+	}
+	return strings.Join(docs, "---\n"), nil
+}
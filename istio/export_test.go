@@ -0,0 +1,137 @@
+package istio
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestExportCluster(t *testing.T) {
+	disc := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{}}
+	list := []string{"get", "list"}
+	disc.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "services", Kind: "Service", Namespaced: true, Verbs: list},
+				{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: list},
+				{Name: "serviceaccounts", Kind: "ServiceAccount", Namespaced: true, Verbs: list},
+			},
+		},
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{{Name: "deployments", Kind: "Deployment", Namespaced: true, Verbs: list}},
+		},
+		{
+			GroupVersion: "networking.istio.io/v1beta1",
+			APIResources: []metav1.APIResource{
+				{Name: "gateways", Kind: "Gateway", Namespaced: true, Verbs: list},
+				{Name: "gateways/status", Kind: "Gateway", Namespaced: true, Verbs: []string{"get"}},
+			},
+		},
+		{
+			GroupVersion: "security.istio.io/v1beta1",
+			APIResources: []metav1.APIResource{{Name: "peerauthentications", Kind: "PeerAuthentication", Namespaced: true, Verbs: list}},
+		},
+	}
+
+	object := func(apiVersion, kind, name string, labels map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": apiVersion,
+			"kind":       kind,
+			"metadata": map[string]interface{}{
+				"name":              name,
+				"namespace":         "istio-system",
+				"uid":               "6c1f2b1e",
+				"resourceVersion":   "42",
+				"creationTimestamp": "2024-01-01T00:00:00Z",
+				"labels":            labels,
+				"annotations":       map[string]interface{}{"kubectl.kubernetes.io/last-applied-configuration": "{}"},
+			},
+			"status": map[string]interface{}{"observedGeneration": int64(1)},
+		}}
+	}
+	kiali := object("v1", "Service", "kiali", map[string]interface{}{"app": "kiali"})
+	kiali.Object["spec"] = map[string]interface{}{
+		"clusterIP": "10.0.0.12",
+		"type":      "LoadBalancer",
+		"ports":     []interface{}{map[string]interface{}{"port": int64(20001), "nodePort": int64(31234)}},
+	}
+	gateways := schema.GroupVersionResource{Group: "networking.istio.io", Version: "v1beta1", Resource: "gateways"}
+	peerAuthentications := schema.GroupVersionResource{Group: "security.istio.io", Version: "v1beta1", Resource: "peerauthentications"}
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		gateways:            "GatewayList",
+		peerAuthentications: "PeerAuthenticationList",
+		addonResources[0]:   "DeploymentList",
+		addonResources[1]:   "ServiceList",
+		addonResources[2]:   "ConfigMapList",
+		addonResources[3]:   "ServiceAccountList",
+	})
+	// The objects are created by resource, the fake client guessing the
+	// resource of Gateway wrong
+	for gvr, obj := range map[schema.GroupVersionResource][]*unstructured.Unstructured{
+		gateways:            {object("networking.istio.io/v1beta1", "Gateway", "bookinfo-gateway", nil)},
+		peerAuthentications: {object("security.istio.io/v1beta1", "PeerAuthentication", "default", nil)},
+		addonResources[0]: {
+			object("apps/v1", "Deployment", "grafana", map[string]interface{}{"app.kubernetes.io/name": "grafana"}),
+			object("apps/v1", "Deployment", "istiod", map[string]interface{}{"app": "istiod"}),
+		},
+		addonResources[1]: {kiali},
+	} {
+		for _, o := range obj {
+			if _, err := dyn.Resource(gvr).Namespace("istio-system").Create(context.Background(), o, metav1.CreateOptions{}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	resources, err := exportCluster(context.Background(), disc, dyn, "istio-system")
+	if err != nil {
+		t.Fatalf("exportCluster() error = %v", err)
+	}
+	var got []string
+	for _, r := range resources {
+		got = append(got, r.GetKind()+"/"+r.GetName())
+	}
+	want := "Deployment/grafana Gateway/bookinfo-gateway PeerAuthentication/default Service/kiali"
+	if strings.Join(got, " ") != want {
+		t.Errorf("exportCluster() = %v, want %s", got, want)
+	}
+
+	manifest, err := bundleManifest(resources)
+	if err != nil {
+		t.Fatalf("bundleManifest() error = %v", err)
+	}
+	for _, field := range []string{"uid", "resourceVersion", "creationTimestamp", "status", "last-applied-configuration", "clusterIP", "nodePort"} {
+		if strings.Contains(manifest, field) {
+			t.Errorf("bundleManifest() = %s, want no %s", manifest, field)
+		}
+	}
+	docs := strings.Split(manifest, "---\n")
+	if len(docs) != len(resources) {
+		t.Fatalf("bundleManifest() has %d documents, want %d", len(docs), len(resources))
+	}
+	var service struct {
+		Spec struct {
+			Type  string `yaml:"type"`
+			Ports []struct {
+				Port int `yaml:"port"`
+			} `yaml:"ports"`
+		} `yaml:"spec"`
+	}
+	if err := yaml.Unmarshal([]byte(docs[3]), &service); err != nil {
+		t.Fatalf("bundleManifest() generated invalid YAML: %v", err)
+	}
+	if service.Spec.Type != "LoadBalancer" || len(service.Spec.Ports) != 1 || service.Spec.Ports[0].Port != 20001 {
+		t.Errorf("bundleManifest() kiali service = %s, want its type and ports kept", docs[3])
+	}
+}
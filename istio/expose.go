@@ -0,0 +1,162 @@
+package istio
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/layer5io/meshery-adapter-library/status"
+	"github.com/layer5io/meshery-istio/internal/config"
+	"github.com/layer5io/meshkit/utils"
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	kubeerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// exposeValues are the values of the Gateway and VirtualService template
+type exposeValues struct {
+	Name      string
+	Namespace string
+	Host      string
+	Port      int
+	Service   string
+
+	// ServicePort is the port of the service the requests are routed to,
+	// the only port of the service is used when 0
+	ServicePort int
+}
+
+// newExposeValues validates the properties of the expose service operation.
+// The Gateway and the VirtualService are both named after the service,
+// deleting them only needs the service.
+func newExposeValues(namespace string, props map[string]string, del bool) (*exposeValues, error) {
+	values := &exposeValues{
+		Namespace: namespace,
+		Host:      strings.TrimSpace(props[config.ExposeHost]),
+		Service:   strings.TrimSpace(props[config.ExposeService]),
+	}
+	if errs := validation.IsDNS1035Label(values.Service); len(errs) > 0 {
+		return nil, ErrExposeServiceInvalid(fmt.Errorf("invalid service %q: %s", values.Service, strings.Join(errs, ", ")))
+	}
+	values.Name = values.Service + "-expose"
+	if len(values.Name) > validation.DNS1123LabelMaxLength {
+		values.Name = strings.Trim(values.Service[:validation.DNS1123LabelMaxLength-len("-expose")], "-") + "-expose"
+	}
+	if del {
+		return values, nil
+	}
+
+	var errs []string
+	if strings.HasPrefix(values.Host, "*.") {
+		errs = validation.IsWildcardDNS1123Subdomain(values.Host)
+	} else {
+		errs = validation.IsDNS1123Subdomain(values.Host)
+	}
+	if len(errs) > 0 {
+		return nil, ErrExposeServiceInvalid(fmt.Errorf("invalid host %q: %s", values.Host, strings.Join(errs, ", ")))
+	}
+	port, err := parsePort(props[config.ExposePort], 80)
+	if err != nil {
+		return nil, ErrExposeServiceInvalid(fmt.Errorf("invalid port: %w", err))
+	}
+	values.Port = port
+	servicePort, err := parsePort(props[config.ExposeServicePort], 0)
+	if err != nil {
+		return nil, ErrExposeServiceInvalid(fmt.Errorf("invalid service port: %w", err))
+	}
+	values.ServicePort = servicePort
+	return values, nil
+}
+
+// parsePort parses a port number, def is returned for an empty port
+func parsePort(port string, def int) (int, error) {
+	port = strings.TrimSpace(port)
+	if port == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(port)
+	if err != nil {
+		return 0, err
+	}
+	if errs := validation.IsValidPortNum(n); len(errs) > 0 {
+		return 0, fmt.Errorf("%d: %s", n, strings.Join(errs, ", "))
+	}
+	return n, nil
+}
+
+// exposeService applies or removes the Gateway and the VirtualService routing
+// the ingress gateway requests for the host to the service. They are applied
+// as one manifest once the service is found on every cluster.
+func (istio *Istio) exposeService(ctx context.Context, del bool, values *exposeValues, templates []adapter.Template, kubeconfigs []string) (string, error) {
+	st := status.Deploying
+	if del {
+		st = status.Removing
+	}
+
+	var manifests []string
+	for _, template := range templates {
+		contents, err := utils.ReadFileSource(string(template))
+		if err != nil {
+			return st, ErrExposeService(err)
+		}
+		contents, err = renderTemplate(contents, values)
+		if err != nil {
+			return st, ErrExposeService(err)
+		}
+		manifests = append(manifests, contents)
+	}
+	manifest := []byte(strings.Join(manifests, "\n---\n"))
+
+	if !del {
+		if err := servicesExist(ctx, values.Namespace, []string{values.Service}, kubeconfigs); err != nil {
+			return st, ErrExposeServiceInvalid(err)
+		}
+	}
+
+	if err := istio.applyRouting(ctx, manifest, del, values.Namespace, kubeconfigs); err != nil {
+		return st, ErrExposeService(err)
+	}
+	if del {
+		return status.Removed, nil
+	}
+	return status.Deployed, nil
+}
+
+// servicesExist returns an error naming the first of the services missing
+// from the namespace of a cluster
+func servicesExist(ctx context.Context, namespace string, services []string, kubeconfigs []string) error {
+	return forEachCluster(kubeconfigs, func(k8sconfig string) error {
+		mclient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return err
+		}
+		for _, service := range services {
+			_, err = mclient.KubeClient.CoreV1().Services(namespace).Get(ctx, service, metav1.GetOptions{})
+			if kubeerror.IsNotFound(err) {
+				return fmt.Errorf("service %s not found in %s namespace of cluster %s", service, namespace, clusterName(k8sconfig))
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}, nil)
+}
+
+// applyRouting applies or removes the routing manifest on every cluster. A
+// manifest failing to apply on a cluster is removed again from it, so that
+// the routing is not left half applied.
+func (istio *Istio) applyRouting(ctx context.Context, manifest []byte, del bool, namespace string, kubeconfigs []string) error {
+	return forEachCluster(kubeconfigs, func(k8sconfig string) error {
+		err := istio.applyManifestOnCluster(ctx, manifest, del, namespace, k8sconfig)
+		if err != nil && !del {
+			if rerr := istio.applyManifestOnCluster(context.WithoutCancel(ctx), manifest, true, namespace, k8sconfig); rerr != nil {
+				return fmt.Errorf("%w, the removal of what was applied failed as well: %v", err, rerr)
+			}
+		}
+		return err
+	}, nil)
+}
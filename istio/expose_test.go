@@ -0,0 +1,128 @@
+package istio
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/layer5io/meshery-istio/internal/config"
+	"gopkg.in/yaml.v2"
+)
+
+func TestExposeService(t *testing.T) {
+	tmpl, err := os.ReadFile("../templates/routing/expose.yaml")
+	if err != nil {
+		t.Fatalf("unable to read the expose template: %v", err)
+	}
+
+	tests := []struct {
+		name            string
+		props           map[string]string
+		del             bool
+		wantName        string
+		wantPort        int
+		wantServicePort int
+		wantErr         bool
+	}{
+		{
+			name:     "default port",
+			props:    map[string]string{config.ExposeHost: "productpage.example.com", config.ExposeService: "productpage"},
+			wantName: "productpage-expose",
+			wantPort: 80,
+		},
+		{
+			name:            "wildcard host and ports",
+			props:           map[string]string{config.ExposeHost: "*.example.com", config.ExposeService: "productpage", config.ExposePort: "8080", config.ExposeServicePort: "9080"},
+			wantName:        "productpage-expose",
+			wantPort:        8080,
+			wantServicePort: 9080,
+		},
+		{
+			name:     "delete only needs the service",
+			props:    map[string]string{config.ExposeService: "productpage"},
+			del:      true,
+			wantName: "productpage-expose",
+		},
+		{
+			name:    "invalid host",
+			props:   map[string]string{config.ExposeHost: "Product_Page", config.ExposeService: "productpage"},
+			wantErr: true,
+		},
+		{
+			name:    "missing service",
+			props:   map[string]string{config.ExposeHost: "productpage.example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid port",
+			props:   map[string]string{config.ExposeHost: "productpage.example.com", config.ExposeService: "productpage", config.ExposePort: "70000"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := newExposeValues("bookinfo", tt.props, tt.del)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newExposeValues() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			rendered, err := renderTemplate(string(tmpl), values)
+			if err != nil {
+				t.Fatalf("renderTemplate() error = %v", err)
+			}
+			docs := strings.Split(rendered, "\n---\n")
+			if len(docs) != 2 {
+				t.Fatalf("renderTemplate() rendered %d resources, want a Gateway and a VirtualService", len(docs))
+			}
+			var gateway struct {
+				Metadata struct {
+					Name string `yaml:"name"`
+				} `yaml:"metadata"`
+				Spec struct {
+					Servers []struct {
+						Port struct {
+							Number int `yaml:"number"`
+						} `yaml:"port"`
+						Hosts []string `yaml:"hosts"`
+					} `yaml:"servers"`
+				} `yaml:"spec"`
+			}
+			var virtualService struct {
+				Spec struct {
+					Gateways []string `yaml:"gateways"`
+					HTTP     []struct {
+						Route []struct {
+							Destination struct {
+								Host string `yaml:"host"`
+								Port struct {
+									Number int `yaml:"number"`
+								} `yaml:"port"`
+							} `yaml:"destination"`
+						} `yaml:"route"`
+					} `yaml:"http"`
+				} `yaml:"spec"`
+			}
+			if err := yaml.Unmarshal([]byte(docs[0]), &gateway); err != nil {
+				t.Fatalf("renderTemplate() generated an invalid Gateway: %v\n%s", err, rendered)
+			}
+			if err := yaml.Unmarshal([]byte(docs[1]), &virtualService); err != nil {
+				t.Fatalf("renderTemplate() generated an invalid VirtualService: %v\n%s", err, rendered)
+			}
+			if gateway.Metadata.Name != tt.wantName || len(virtualService.Spec.Gateways) != 1 || virtualService.Spec.Gateways[0] != tt.wantName {
+				t.Errorf("renderTemplate() = %s, want the %s Gateway bound to the VirtualService", rendered, tt.wantName)
+			}
+			if tt.del {
+				return
+			}
+			if gateway.Spec.Servers[0].Port.Number != tt.wantPort || gateway.Spec.Servers[0].Hosts[0] != tt.props[config.ExposeHost] {
+				t.Errorf("renderTemplate() gateway servers = %+v, want %s on port %d", gateway.Spec.Servers, tt.props[config.ExposeHost], tt.wantPort)
+			}
+			destination := virtualService.Spec.HTTP[0].Route[0].Destination
+			if destination.Host != "productpage" || destination.Port.Number != tt.wantServicePort {
+				t.Errorf("renderTemplate() destination = %+v, want productpage port %d", destination, tt.wantServicePort)
+			}
+		})
+	}
+}
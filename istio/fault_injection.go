@@ -0,0 +1,92 @@
+package istio
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/layer5io/meshery-istio/internal/config"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// faultValues are the values of the fault injection template, the delay is
+// injected when FixedDelay is set and the abort when HTTPStatus is set
+type faultValues struct {
+	Name      string
+	Namespace string
+	Service   string
+
+	DelayPercent float64
+	// FixedDelay is in seconds, the format of the durations of the Istio APIs
+	FixedDelay string
+
+	AbortPercent float64
+	HTTPStatus   int
+}
+
+// newFaultValues validates the fault injection properties of the operation.
+// A percentage of 0 leaves the fault out, at least one of the delay and the
+// abort is required. The VirtualService is named after the service, deleting
+// it only needs the service.
+func newFaultValues(namespace string, props map[string]string, del bool) (*faultValues, error) {
+	values := &faultValues{
+		Namespace: namespace,
+		Service:   strings.TrimSpace(props[config.FaultService]),
+	}
+	if errs := validation.IsDNS1035Label(values.Service); len(errs) > 0 {
+		return nil, ErrInvalidFaultInjection(fmt.Errorf("invalid service %q: %s", values.Service, strings.Join(errs, ", ")))
+	}
+	values.Name = values.Service + "-fault"
+	if len(values.Name) > validation.DNS1123LabelMaxLength {
+		values.Name = strings.Trim(values.Service[:validation.DNS1123LabelMaxLength-len("-fault")], "-") + "-fault"
+	}
+	if del {
+		return values, nil
+	}
+
+	delayPercent, err := parsePercentage(props[config.FaultDelayPercent])
+	if err != nil {
+		return nil, ErrInvalidFaultInjection(fmt.Errorf("invalid delayPercent: %w", err))
+	}
+	if delayPercent > 0 {
+		delay, err := time.ParseDuration(strings.TrimSpace(props[config.FaultFixedDelay]))
+		if err != nil || delay < time.Millisecond {
+			return nil, ErrInvalidFaultInjection(fmt.Errorf("fixedDelay %q is not a duration of at least 1ms", props[config.FaultFixedDelay]))
+		}
+		values.DelayPercent = delayPercent
+		values.FixedDelay = strconv.FormatFloat(delay.Seconds(), 'f', -1, 64) + "s"
+	}
+
+	abortPercent, err := parsePercentage(props[config.FaultAbortPercent])
+	if err != nil {
+		return nil, ErrInvalidFaultInjection(fmt.Errorf("invalid abortPercent: %w", err))
+	}
+	if abortPercent > 0 {
+		status, err := strconv.Atoi(strings.TrimSpace(props[config.FaultHTTPStatus]))
+		if err != nil || status < 200 || status > 599 {
+			return nil, ErrInvalidFaultInjection(fmt.Errorf("httpStatus %q is not an HTTP status between 200 and 599", props[config.FaultHTTPStatus]))
+		}
+		values.AbortPercent = abortPercent
+		values.HTTPStatus = status
+	}
+
+	if values.FixedDelay == "" && values.HTTPStatus == 0 {
+		return nil, ErrInvalidFaultInjection(fmt.Errorf("either delayPercent or abortPercent must be above 0"))
+	}
+	return values, nil
+}
+
+// parsePercentage parses a percentage between 0 and 100, an empty percentage
+// being 0
+func parsePercentage(value string) (float64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	percentage, err := strconv.ParseFloat(value, 64)
+	if err != nil || percentage < 0 || percentage > 100 {
+		return 0, fmt.Errorf("%q is not a number between 0 and 100", value)
+	}
+	return percentage, nil
+}
@@ -0,0 +1,125 @@
+package istio
+
+import (
+	"os"
+	"testing"
+
+	"github.com/layer5io/meshery-istio/internal/config"
+	"gopkg.in/yaml.v2"
+)
+
+func TestFaultInjection(t *testing.T) {
+	tmpl, err := os.ReadFile("../templates/routing/fault.yaml")
+	if err != nil {
+		t.Fatalf("unable to read the fault injection template: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		props     map[string]string
+		del       bool
+		wantDelay string
+		wantAbort int
+		wantErr   bool
+	}{
+		{
+			name:      "delay",
+			props:     map[string]string{config.FaultService: "ratings", config.FaultDelayPercent: "100", config.FaultFixedDelay: "7s", config.FaultAbortPercent: "0", config.FaultHTTPStatus: "500"},
+			wantDelay: "7s",
+		},
+		{
+			name:      "abort",
+			props:     map[string]string{config.FaultService: "ratings", config.FaultAbortPercent: "10", config.FaultHTTPStatus: "503"},
+			wantAbort: 503,
+		},
+		{
+			name:      "delay and abort",
+			props:     map[string]string{config.FaultService: "ratings", config.FaultDelayPercent: "50", config.FaultFixedDelay: "1500ms", config.FaultAbortPercent: "12.5", config.FaultHTTPStatus: "500"},
+			wantDelay: "1.5s",
+			wantAbort: 500,
+		},
+		{
+			name:  "delete only needs the service",
+			props: map[string]string{config.FaultService: "ratings"},
+			del:   true,
+		},
+		{
+			name:    "no fault",
+			props:   map[string]string{config.FaultService: "ratings", config.FaultDelayPercent: "0", config.FaultAbortPercent: "0"},
+			wantErr: true,
+		},
+		{
+			name:    "delay percentage above 100",
+			props:   map[string]string{config.FaultService: "ratings", config.FaultDelayPercent: "101", config.FaultFixedDelay: "7s"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid delay",
+			props:   map[string]string{config.FaultService: "ratings", config.FaultDelayPercent: "100", config.FaultFixedDelay: "7"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid status",
+			props:   map[string]string{config.FaultService: "ratings", config.FaultAbortPercent: "100", config.FaultHTTPStatus: "700"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid service",
+			props:   map[string]string{config.FaultService: "Ratings", config.FaultAbortPercent: "100", config.FaultHTTPStatus: "500"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := newFaultValues("bookinfo", tt.props, tt.del)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newFaultValues() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			rendered, err := renderTemplate(string(tmpl), values)
+			if err != nil {
+				t.Fatalf("renderTemplate() error = %v", err)
+			}
+			var virtualService struct {
+				Metadata struct {
+					Name string `yaml:"name"`
+				} `yaml:"metadata"`
+				Spec struct {
+					HTTP []struct {
+						Fault struct {
+							Delay *struct {
+								FixedDelay string `yaml:"fixedDelay"`
+							} `yaml:"delay"`
+							Abort *struct {
+								HTTPStatus int `yaml:"httpStatus"`
+							} `yaml:"abort"`
+						} `yaml:"fault"`
+						Route []struct {
+							Destination struct {
+								Host string `yaml:"host"`
+							} `yaml:"destination"`
+						} `yaml:"route"`
+					} `yaml:"http"`
+				} `yaml:"spec"`
+			}
+			if err := yaml.Unmarshal([]byte(rendered), &virtualService); err != nil {
+				t.Fatalf("renderTemplate() generated invalid YAML: %v\n%s", err, rendered)
+			}
+			if virtualService.Metadata.Name != "ratings-fault" || virtualService.Spec.HTTP[0].Route[0].Destination.Host != "ratings" {
+				t.Errorf("renderTemplate() = %s, want ratings-fault routing to ratings", rendered)
+			}
+			if tt.del {
+				return
+			}
+			fault := virtualService.Spec.HTTP[0].Fault
+			if (fault.Delay == nil) != (tt.wantDelay == "") || (fault.Delay != nil && fault.Delay.FixedDelay != tt.wantDelay) {
+				t.Errorf("renderTemplate() delay = %+v, want %q", fault.Delay, tt.wantDelay)
+			}
+			if (fault.Abort == nil) != (tt.wantAbort == 0) || (fault.Abort != nil && fault.Abort.HTTPStatus != tt.wantAbort) {
+				t.Errorf("renderTemplate() abort = %+v, want %d", fault.Abort, tt.wantAbort)
+			}
+		})
+	}
+}
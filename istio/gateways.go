@@ -0,0 +1,163 @@
+package istio
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/layer5io/meshery-adapter-library/status"
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	"gopkg.in/yaml.v2"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// IngressGateway is the gateway type of the ingress gateways
+	IngressGateway = "ingress"
+	// EgressGateway is the gateway type of the egress gateways
+	EgressGateway = "egress"
+)
+
+var gatewayServiceTypes = map[string]bool{
+	"LoadBalancer": true,
+	"NodePort":     true,
+	"ClusterIP":    true,
+}
+
+// gatewayOptions describes the gateway deployment
+type gatewayOptions struct {
+	// Version is the Istio version whose istioctl renders the gateway
+	Version string
+
+	Replicas    int
+	ServiceType string
+}
+
+// installGateway installs an ingress or egress gateway in the namespace, apart
+// from the control plane. The gateway component of an IstioOperator is rendered
+// to the gateway resources with istioctl, so that the deletion removes only the
+// resources of that gateway after scaling it down.
+func (istio *Istio) installGateway(ctx context.Context, gatewayType, namespace string, isDelete bool, opts gatewayOptions, kubeConfigs []string) (string, error) {
+	st := status.Installing
+	if isDelete {
+		st = status.Removing
+	}
+
+	operator, err := gatewayOperator(gatewayType, namespace, opts)
+	if err != nil {
+		return st, err
+	}
+
+	executable, err := istio.getExecutable(opts.Version)
+	if err != nil {
+		return st, ErrInstallGateway(gatewayType, err)
+	}
+	operatorFile, err := os.CreateTemp("", "istio-gateway-*.yaml")
+	if err != nil {
+		return st, ErrInstallGateway(gatewayType, err)
+	}
+	defer os.Remove(operatorFile.Name())
+	if _, err := operatorFile.Write(operator); err != nil {
+		_ = operatorFile.Close()
+		return st, ErrInstallGateway(gatewayType, err)
+	}
+	if err := operatorFile.Close(); err != nil {
+		return st, ErrInstallGateway(gatewayType, err)
+	}
+
+	manifest, err := runIstioctl(executable, "manifest", "generate", "-f", operatorFile.Name())
+	if err != nil {
+		return st, ErrInstallGateway(gatewayType, err)
+	}
+
+	if isDelete {
+		if err := scaleDownGateway(gatewayName(gatewayType), namespace, kubeConfigs); err != nil {
+			return st, ErrInstallGateway(gatewayType, err)
+		}
+	}
+
+	if err := istio.applyManifest(ctx, []byte(manifest), isDelete, namespace, kubeConfigs); err != nil {
+		return st, ErrInstallGateway(gatewayType, err)
+	}
+
+	if isDelete {
+		return status.Removed, nil
+	}
+	return status.Installed, nil
+}
+
+func gatewayName(gatewayType string) string {
+	return fmt.Sprintf("istio-%sgateway", gatewayType)
+}
+
+// gatewayOperator renders the IstioOperator containing only the requested gateway
+func gatewayOperator(gatewayType, namespace string, opts gatewayOptions) ([]byte, error) {
+	if gatewayType != IngressGateway && gatewayType != EgressGateway {
+		return nil, ErrInstallGateway(gatewayType, fmt.Errorf("unknown gateway type %q", gatewayType))
+	}
+	if opts.Replicas <= 0 {
+		return nil, ErrInstallGateway(gatewayType, fmt.Errorf("replicas must be positive, got %d", opts.Replicas))
+	}
+	if !gatewayServiceTypes[opts.ServiceType] {
+		return nil, ErrInstallGateway(gatewayType, fmt.Errorf("invalid service type %q, use LoadBalancer, NodePort or ClusterIP", opts.ServiceType))
+	}
+
+	name := gatewayName(gatewayType)
+	gateway := map[string]interface{}{
+		"name":      name,
+		"namespace": namespace,
+		"enabled":   true,
+		"label": map[string]string{
+			"istio": fmt.Sprintf("%sgateway", gatewayType),
+		},
+		"k8s": map[string]interface{}{
+			"replicaCount": opts.Replicas,
+			"service": map[string]interface{}{
+				"type": opts.ServiceType,
+			},
+		},
+	}
+	return yaml.Marshal(map[string]interface{}{
+		"apiVersion": "install.istio.io/v1alpha1",
+		"kind":       "IstioOperator",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": namespace,
+		},
+		"spec": map[string]interface{}{
+			// The empty profile leaves out the control plane
+			"profile": "empty",
+			"components": map[string]interface{}{
+				fmt.Sprintf("%sGateways", gatewayType): []interface{}{gateway},
+			},
+			"values": map[string]interface{}{
+				"gateways": map[string]interface{}{
+					name: map[string]interface{}{
+						"injectionTemplate": "gateway",
+					},
+				},
+			},
+		},
+	})
+}
+
+// scaleDownGateway scales the gateway deployment down to zero replicas so that
+// the connections are drained before its resources are removed
+func scaleDownGateway(name, namespace string, kubeConfigs []string) error {
+	return forEachCluster(kubeConfigs, func(k8sconfig string) error {
+		mclient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return err
+		}
+		_, err = mclient.KubeClient.AppsV1().Deployments(namespace).UpdateScale(context.TODO(), name, &autoscalingv1.Scale{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       autoscalingv1.ScaleSpec{Replicas: 0},
+		}, metav1.UpdateOptions{})
+		if err != nil && !kerrors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}, nil)
+}
@@ -0,0 +1,82 @@
+package istio
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestGatewayOperator(t *testing.T) {
+	tests := []struct {
+		name        string
+		gatewayType string
+		opts        gatewayOptions
+		wantErr     bool
+	}{
+		{
+			name:        "ingress gateway",
+			gatewayType: IngressGateway,
+			opts:        gatewayOptions{Replicas: 2, ServiceType: "NodePort"},
+		},
+		{
+			name:        "egress gateway",
+			gatewayType: EgressGateway,
+			opts:        gatewayOptions{Replicas: 1, ServiceType: "ClusterIP"},
+		},
+		{
+			name:        "unknown gateway type",
+			gatewayType: "eastwest",
+			opts:        gatewayOptions{Replicas: 1, ServiceType: "ClusterIP"},
+			wantErr:     true,
+		},
+		{
+			name:        "invalid service type",
+			gatewayType: IngressGateway,
+			opts:        gatewayOptions{Replicas: 1, ServiceType: "ExternalName"},
+			wantErr:     true,
+		},
+		{
+			name:        "no replicas",
+			gatewayType: IngressGateway,
+			opts:        gatewayOptions{ServiceType: "LoadBalancer"},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := gatewayOperator(tt.gatewayType, "istio-gateways", tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("gatewayOperator() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			var operator struct {
+				Spec struct {
+					Profile    string `yaml:"profile"`
+					Components map[string][]struct {
+						Name      string `yaml:"name"`
+						Namespace string `yaml:"namespace"`
+						K8s       struct {
+							ReplicaCount int `yaml:"replicaCount"`
+							Service      struct {
+								Type string `yaml:"type"`
+							} `yaml:"service"`
+						} `yaml:"k8s"`
+					} `yaml:"components"`
+				} `yaml:"spec"`
+			}
+			if err := yaml.Unmarshal(got, &operator); err != nil {
+				t.Fatalf("gatewayOperator() generated invalid YAML: %v", err)
+			}
+			gateways := operator.Spec.Components[tt.gatewayType+"Gateways"]
+			if operator.Spec.Profile != "empty" || len(operator.Spec.Components) != 1 || len(gateways) != 1 {
+				t.Fatalf("gatewayOperator() = %s, want only the %s gateway", got, tt.gatewayType)
+			}
+			gw := gateways[0]
+			if gw.Name != gatewayName(tt.gatewayType) || gw.Namespace != "istio-gateways" || gw.K8s.ReplicaCount != tt.opts.Replicas || gw.K8s.Service.Type != tt.opts.ServiceType {
+				t.Errorf("gatewayOperator() gateway = %+v, want %s with %+v", gw, gatewayName(tt.gatewayType), tt.opts)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package istio
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MeshHealth is the health of the Istio control plane and the injected
+// sidecars on a single cluster
+type MeshHealth struct {
+	Cluster string `json:"cluster"`
+
+	IstiodReady   int32 `json:"istiodReady"`
+	IstiodDesired int32 `json:"istiodDesired"`
+
+	// IngressInstalled is false when the profile does not ship an ingress gateway
+	IngressInstalled bool  `json:"ingressInstalled"`
+	IngressReady     int32 `json:"ingressReady"`
+	IngressDesired   int32 `json:"ingressDesired"`
+
+	WebhookReachable bool   `json:"webhookReachable"`
+	WebhookError     string `json:"webhookError,omitempty"`
+
+	ProxiesReady int `json:"proxiesReady"`
+	ProxiesTotal int `json:"proxiesTotal"`
+}
+
+// Problems returns the control plane components which are not healthy
+func (h MeshHealth) Problems() []string {
+	var problems []string
+	if h.IstiodReady == 0 {
+		problems = append(problems, "istiod has no ready replicas")
+	}
+	if h.IngressInstalled && h.IngressReady == 0 {
+		problems = append(problems, "istio-ingressgateway has no ready replicas")
+	}
+	if !h.WebhookReachable {
+		problems = append(problems, fmt.Sprintf("istio validating webhook is not reachable: %s", h.WebhookError))
+	}
+	return problems
+}
+
+func (h MeshHealth) String() string {
+	ingress := "not installed"
+	if h.IngressInstalled {
+		ingress = fmt.Sprintf("%d/%d ready", h.IngressReady, h.IngressDesired)
+	}
+	webhook := "reachable"
+	if !h.WebhookReachable {
+		webhook = "unreachable"
+	}
+	return fmt.Sprintf("istiod: %d/%d ready, ingress gateway: %s, validating webhook: %s, sidecars: %d/%d ready",
+		h.IstiodReady, h.IstiodDesired, ingress, webhook, h.ProxiesReady, h.ProxiesTotal)
+}
+
+// checkMeshHealth inspects istiod, the ingress gateway, the istio validating
+// webhook and the istio-proxy containers of the pods in the namespace on every
+// cluster. All the namespaces are inspected if namespace is empty.
+//
+// The health of every cluster is returned along with ErrMeshUnhealthy if any
+// control plane component has no ready replicas.
+func (istio *Istio) checkMeshHealth(namespace string, kubeConfigs []string) ([]MeshHealth, error) {
+	var mx sync.Mutex
+	var health []MeshHealth
+	err := forEachCluster(kubeConfigs, func(k8sconfig string) error {
+		mclient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return err
+		}
+		h, err := clusterMeshHealth(mclient, namespace)
+		if err != nil {
+			return err
+		}
+		h.Cluster = clusterName(k8sconfig)
+		mx.Lock()
+		health = append(health, h)
+		mx.Unlock()
+		if problems := h.Problems(); len(problems) > 0 {
+			return fmt.Errorf("%s", strings.Join(problems, ", "))
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		return health, ErrMeshUnhealthy(err)
+	}
+	return health, nil
+}
+
+func clusterMeshHealth(mclient *mesherykube.Client, namespace string) (MeshHealth, error) {
+	h := MeshHealth{}
+	ctx := context.TODO()
+
+	// Every revision of the control plane runs its own istiod deployment
+	istiods, err := mclient.KubeClient.AppsV1().Deployments(istioRootNamespace).List(ctx, metav1.ListOptions{LabelSelector: "app=istiod"})
+	if err != nil {
+		return h, err
+	}
+	for _, d := range istiods.Items {
+		h.IstiodReady += d.Status.AvailableReplicas
+		h.IstiodDesired += d.Status.Replicas
+	}
+
+	gateways, err := mclient.KubeClient.AppsV1().Deployments(istioRootNamespace).List(ctx, metav1.ListOptions{LabelSelector: "app=istio-ingressgateway"})
+	if err != nil {
+		return h, err
+	}
+	for _, d := range gateways.Items {
+		h.IngressInstalled = true
+		h.IngressReady += d.Status.AvailableReplicas
+		h.IngressDesired += d.Status.Replicas
+	}
+
+	h.WebhookReachable, h.WebhookError = validatingWebhookReachable(ctx, mclient)
+
+	pods, err := mclient.KubeClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return h, err
+	}
+	for _, pod := range pods.Items {
+		// Sidecars run either as regular containers or as native sidecars
+		var statuses []corev1.ContainerStatus
+		statuses = append(statuses, pod.Status.ContainerStatuses...)
+		statuses = append(statuses, pod.Status.InitContainerStatuses...)
+		for _, cs := range statuses {
+			if cs.Name != "istio-proxy" {
+				continue
+			}
+			h.ProxiesTotal++
+			if cs.Ready {
+				h.ProxiesReady++
+			}
+		}
+	}
+	return h, nil
+}
+
+// validatingWebhookReachable checks that the service backing the istio
+// validating webhook has ready endpoints
+func validatingWebhookReachable(ctx context.Context, mclient *mesherykube.Client) (bool, string) {
+	webhooks, err := mclient.KubeClient.AdmissionregistrationV1().ValidatingWebhookConfigurations().List(ctx, metav1.ListOptions{LabelSelector: "app=istiod"})
+	if err != nil {
+		return false, err.Error()
+	}
+	for _, cfg := range webhooks.Items {
+		for _, wh := range cfg.Webhooks {
+			svc := wh.ClientConfig.Service
+			if svc == nil {
+				// The webhook is served by an external URL
+				return true, ""
+			}
+			endpoints, err := mclient.KubeClient.CoreV1().Endpoints(svc.Namespace).Get(ctx, svc.Name, metav1.GetOptions{})
+			if err != nil {
+				return false, err.Error()
+			}
+			if readyAddresses(endpoints) > 0 {
+				return true, ""
+			}
+			return false, fmt.Sprintf("service %s/%s has no ready endpoints", svc.Namespace, svc.Name)
+		}
+	}
+	return false, "istio validating webhook configuration not found"
+}
+
+func readyAddresses(endpoints *corev1.Endpoints) int {
+	n := 0
+	for _, subset := range endpoints.Subsets {
+		n += len(subset.Addresses)
+	}
+	return n
+}
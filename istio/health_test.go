@@ -0,0 +1,34 @@
+package istio
+
+import "testing"
+
+func TestMeshHealth_Problems(t *testing.T) {
+	tests := []struct {
+		name   string
+		health MeshHealth
+		want   int
+	}{
+		{
+			name:   "healthy minimal profile",
+			health: MeshHealth{IstiodReady: 1, IstiodDesired: 1, WebhookReachable: true},
+			want:   0,
+		},
+		{
+			name:   "ingress gateway without ready replicas",
+			health: MeshHealth{IstiodReady: 1, IstiodDesired: 1, IngressInstalled: true, IngressDesired: 1, WebhookReachable: true},
+			want:   1,
+		},
+		{
+			name:   "control plane down",
+			health: MeshHealth{IstiodDesired: 1, WebhookError: "service istio-system/istiod has no ready endpoints"},
+			want:   2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.health.Problems(); len(got) != tt.want {
+				t.Errorf("MeshHealth.Problems() = %v, want %d problems", got, tt.want)
+			}
+		})
+	}
+}
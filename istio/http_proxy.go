@@ -0,0 +1,116 @@
+package istio
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/layer5io/meshery-istio/internal/config"
+	"golang.org/x/net/http/httpproxy"
+)
+
+// currentProxy is the proxy the default transport uses once useProxy was
+// called, it is read by every request hence it is swapped under the lock
+var currentProxy struct {
+	sync.RWMutex
+	proxy func(*url.URL) (*url.URL, error)
+	once  sync.Once
+}
+
+// newProxyConfig returns the HTTP(S) proxy configuration of the
+// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables, overridden by
+// the httpProxy, httpsProxy and noProxy properties when set.
+// ErrProxyConfigInvalid is returned if a proxy is not a valid http, https or
+// socks5 URL.
+func newProxyConfig(props map[string]string) (*httpproxy.Config, error) {
+	cfg := httpproxy.FromEnvironment()
+	if v := strings.TrimSpace(props[config.HTTPProxy]); v != "" {
+		cfg.HTTPProxy = v
+	}
+	if v := strings.TrimSpace(props[config.HTTPSProxy]); v != "" {
+		cfg.HTTPSProxy = v
+	}
+	if v := strings.TrimSpace(props[config.NoProxy]); v != "" {
+		cfg.NoProxy = v
+	}
+	for _, proxy := range []string{cfg.HTTPProxy, cfg.HTTPSProxy} {
+		if err := validateProxyURL(proxy); err != nil {
+			return nil, ErrProxyConfigInvalid(err)
+		}
+	}
+	return cfg, nil
+}
+
+// validateProxyURL validates the proxy URL, the scheme of which defaults to
+// http as with the environment variables
+func validateProxyURL(proxy string) error {
+	if proxy == "" {
+		return nil
+	}
+	raw := proxy
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid proxy %q: %w", proxy, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return fmt.Errorf("invalid proxy %q: the scheme must be http, https or socks5", proxy)
+	}
+	if u.Hostname() == "" {
+		return fmt.Errorf("invalid proxy %q: no host", proxy)
+	}
+	return nil
+}
+
+// useProxy makes the default transport, which the release listing and the
+// downloads of istioctl and of the release bundles go through, use the
+// proxy. The proxy applies to the whole adapter from then on.
+func useProxy(cfg *httpproxy.Config) {
+	currentProxy.Lock()
+	currentProxy.proxy = cfg.ProxyFunc()
+	currentProxy.Unlock()
+	currentProxy.once.Do(func() {
+		if transport, ok := http.DefaultTransport.(*http.Transport); ok {
+			transport.Proxy = proxyOfRequest
+		}
+	})
+}
+
+// proxyOfRequest returns the proxy of the request, see useProxy
+func proxyOfRequest(req *http.Request) (*url.URL, error) {
+	currentProxy.RLock()
+	defer currentProxy.RUnlock()
+	return currentProxy.proxy(req.URL)
+}
+
+// configureProxy validates the proxy of the properties of the operation and
+// uses it if any of httpProxy, httpsProxy and noProxy is set
+func configureProxy(props map[string]string) error {
+	if props[config.HTTPProxy] == "" && props[config.HTTPSProxy] == "" && props[config.NoProxy] == "" {
+		return nil
+	}
+	cfg, err := newProxyConfig(props)
+	if err != nil {
+		return err
+	}
+	useProxy(cfg)
+	return nil
+}
+
+// UseEnvironmentProxy validates the proxy of the HTTP_PROXY, HTTPS_PROXY and
+// NO_PROXY environment variables and uses it, so that an invalid proxy is
+// reported when the adapter starts rather than by the first download
+func UseEnvironmentProxy() error {
+	cfg, err := newProxyConfig(nil)
+	if err != nil {
+		return err
+	}
+	useProxy(cfg)
+	return nil
+}
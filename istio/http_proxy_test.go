@@ -0,0 +1,102 @@
+package istio
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/layer5io/meshery-istio/internal/config"
+	"github.com/layer5io/meshkit/errors"
+)
+
+func TestNewProxyConfig(t *testing.T) {
+	tests := []struct {
+		name          string
+		env           map[string]string
+		props         map[string]string
+		wantHTTPProxy string
+		wantNoProxy   string
+		wantErr       bool
+	}{
+		{
+			name: "none",
+		},
+		{
+			name:          "environment",
+			env:           map[string]string{"HTTP_PROXY": "http://proxy.corp.example.com:3128", "NO_PROXY": "10.0.0.0/8"},
+			wantHTTPProxy: "http://proxy.corp.example.com:3128",
+			wantNoProxy:   "10.0.0.0/8",
+		},
+		{
+			name:          "properties override the environment",
+			env:           map[string]string{"HTTP_PROXY": "http://proxy.corp.example.com:3128", "NO_PROXY": "10.0.0.0/8"},
+			props:         map[string]string{config.HTTPProxy: "user:secret@proxy.dmz.example.com:8080"},
+			wantHTTPProxy: "user:secret@proxy.dmz.example.com:8080",
+			wantNoProxy:   "10.0.0.0/8",
+		},
+		{
+			name:    "unsupported scheme",
+			props:   map[string]string{config.HTTPSProxy: "ftp://proxy.corp.example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid environment proxy",
+			env:     map[string]string{"HTTPS_PROXY": "http://"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "http_proxy", "https_proxy", "no_proxy", "REQUEST_METHOD"} {
+				t.Setenv(name, tt.env[name])
+			}
+			got, err := newProxyConfig(tt.props)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newProxyConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if errors.GetCode(err) != ErrProxyConfigInvalidCode {
+					t.Errorf("newProxyConfig() error code = %s, want %s", errors.GetCode(err), ErrProxyConfigInvalidCode)
+				}
+				return
+			}
+			if got.HTTPProxy != tt.wantHTTPProxy || got.NoProxy != tt.wantNoProxy {
+				t.Errorf("newProxyConfig() = %+v, want HTTP proxy %q and no proxy %q", got, tt.wantHTTPProxy, tt.wantNoProxy)
+			}
+		})
+	}
+}
+
+func TestConfigureProxy(t *testing.T) {
+	transport := http.DefaultTransport.(*http.Transport)
+	defer func(proxy func(*http.Request) (*url.URL, error)) { transport.Proxy = proxy }(transport.Proxy)
+
+	err := configureProxy(map[string]string{
+		config.HTTPSProxy: "https://proxy.corp.example.com:3128",
+		config.NoProxy:    ".svc.cluster.local",
+	})
+	if err != nil {
+		t.Fatalf("configureProxy() error = %v", err)
+	}
+	for target, want := range map[string]string{
+		"https://github.com/istio/istio/releases":        "https://proxy.corp.example.com:3128",
+		"https://grafana.istio-system.svc.cluster.local": "",
+	} {
+		req, _ := http.NewRequest(http.MethodGet, target, nil)
+		proxy, err := transport.Proxy(req)
+		if err != nil {
+			t.Fatalf("Proxy(%s) error = %v", target, err)
+		}
+		got := ""
+		if proxy != nil {
+			got = proxy.String()
+		}
+		if got != want {
+			t.Errorf("Proxy(%s) = %q, want %q", target, got, want)
+		}
+	}
+
+	if err := configureProxy(map[string]string{config.HTTPProxy: "socks4://proxy.corp.example.com"}); errors.GetCode(err) != ErrProxyConfigInvalidCode {
+		t.Errorf("configureProxy() error = %v, want %s", err, ErrProxyConfigInvalidCode)
+	}
+}
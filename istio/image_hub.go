@@ -0,0 +1,83 @@
+package istio
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// imageHubRegex matches a registry host, with an optional port, followed by
+// an optional repository path, such as registry.internal:5000/istio
+var imageHubRegex = regexp.MustCompile(`^(localhost|[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)+)(:([0-9]+))?(/[a-z0-9]+([._-][a-z0-9]+)*)*$`)
+
+// parseImageHub validates the image hub of the operation, the registry the
+// images are pulled from in place of docker.io and gcr.io. An empty hub is
+// returned as is, the images being pulled from their own registries.
+func parseImageHub(hub string) (string, error) {
+	hub = strings.TrimSuffix(strings.TrimSpace(hub), "/")
+	if hub == "" {
+		return "", nil
+	}
+	m := imageHubRegex.FindStringSubmatch(hub)
+	if m == nil {
+		return "", ErrInvalidImageHub(hub, fmt.Errorf("not a host[:port]/path"))
+	}
+	if m[6] != "" {
+		port, err := strconv.Atoi(m[6])
+		if err != nil || port < 1 || port > 65535 {
+			return "", ErrInvalidImageHub(hub, fmt.Errorf("invalid port %s", m[6]))
+		}
+	}
+	return hub, nil
+}
+
+// mirrorImage returns the image pulled from the hub, its registry being
+// replaced with the hub: docker.io/grafana/grafana:10.4.0 and
+// grafana/grafana:10.4.0 are both pulled as <hub>/grafana/grafana:10.4.0
+func mirrorImage(image, hub string) string {
+	if hub == "" || image == "" {
+		return image
+	}
+	if registry, rest, found := strings.Cut(image, "/"); found && (strings.ContainsAny(registry, ".:") || registry == "localhost") {
+		image = rest
+	}
+	return hub + "/" + image
+}
+
+// mirrorImages replaces the registry of the images of the containers of every
+// workload of the multi-document manifest with the hub
+func mirrorImages(contents []byte, hub string) ([]byte, error) {
+	if hub == "" {
+		return contents, nil
+	}
+	return mapManifest(contents, func(object map[string]interface{}) {
+		mirrorContainerImages(object, hub)
+	})
+}
+
+// mirrorContainerImages walks the object for the containers and init
+// containers of the pod specs, wherever the kind of the workload nests them
+func mirrorContainerImages(v interface{}, hub string) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if key == "containers" || key == "initContainers" {
+				containers, _ := value.([]interface{})
+				for _, c := range containers {
+					if container, ok := c.(map[string]interface{}); ok {
+						if image, ok := container["image"].(string); ok {
+							container["image"] = mirrorImage(image, hub)
+						}
+					}
+				}
+				continue
+			}
+			mirrorContainerImages(value, hub)
+		}
+	case []interface{}:
+		for _, value := range v {
+			mirrorContainerImages(value, hub)
+		}
+	}
+}
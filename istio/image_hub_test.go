@@ -0,0 +1,81 @@
+package istio
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/layer5io/meshkit/errors"
+)
+
+func TestParseImageHub(t *testing.T) {
+	tests := []struct {
+		hub     string
+		want    string
+		wantErr bool
+	}{
+		{hub: "", want: ""},
+		{hub: "registry.internal", want: "registry.internal"},
+		{hub: " registry.internal:5000/istio/ ", want: "registry.internal:5000/istio"},
+		{hub: "localhost:5000/mirror/docker.io", want: "localhost:5000/mirror/docker.io"},
+		{hub: "registry", wantErr: true},
+		{hub: "registry.internal:99999", wantErr: true},
+		{hub: "https://registry.internal/istio", wantErr: true},
+		{hub: "Registry.internal/Istio", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.hub, func(t *testing.T) {
+			got, err := parseImageHub(tt.hub)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseImageHub() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && errors.GetCode(err) != ErrInvalidImageHubCode {
+				t.Errorf("parseImageHub() error code = %s, want %s", errors.GetCode(err), ErrInvalidImageHubCode)
+			}
+			if got != tt.want {
+				t.Errorf("parseImageHub() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMirrorImages(t *testing.T) {
+	manifest := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: grafana
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: init
+        image: busybox:1.36
+      containers:
+      - name: grafana
+        image: docker.io/grafana/grafana:10.4.0
+      - name: sidecar
+        image: quay.io/kiali/kiali:v1.76
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: grafana
+`
+	got, err := mirrorImages([]byte(manifest), "registry.internal:5000/istio")
+	if err != nil {
+		t.Fatalf("mirrorImages() error = %v", err)
+	}
+	for _, want := range []string{
+		"image: registry.internal:5000/istio/busybox:1.36",
+		"image: registry.internal:5000/istio/grafana/grafana:10.4.0",
+		"image: registry.internal:5000/istio/kiali/kiali:v1.76",
+		"kind: Service",
+	} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("mirrorImages() = %s, want %q", got, want)
+		}
+	}
+
+	if got, _ := mirrorImages([]byte(manifest), ""); string(got) != manifest {
+		t.Errorf("mirrorImages() = %s, want the manifest as is without hub", got)
+	}
+}
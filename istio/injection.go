@@ -0,0 +1,139 @@
+package istio
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NamespaceInjection is the sidecar injection of a namespace labeled for
+// injection on a single cluster
+type NamespaceInjection struct {
+	Cluster   string `json:"cluster"`
+	Namespace string `json:"namespace"`
+
+	// Enabled is false when the namespace opts out of the injection with
+	// istio-injection=disabled
+	Enabled bool `json:"enabled"`
+
+	// Revision is the revision the namespace is injected by, "default" when
+	// it is labeled with istio-injection=enabled
+	Revision string `json:"revision,omitempty"`
+
+	// InjectedPods is the number of pods running the proxy of the revision,
+	// NotInjected and OtherRevision the pods running no proxy or the proxy
+	// of another revision, which need to be restarted. The pods opting out
+	// of the injection are left out.
+	InjectedPods  int      `json:"injectedPods"`
+	NotInjected   []string `json:"notInjected,omitempty"`
+	OtherRevision []string `json:"otherRevision,omitempty"`
+}
+
+// NeedsRestart reports whether pods of the namespace don't run the proxy
+// of its injection
+func (n NamespaceInjection) NeedsRestart() bool {
+	return len(n.NotInjected) > 0 || len(n.OtherRevision) > 0
+}
+
+func (n NamespaceInjection) String() string {
+	if !n.Enabled {
+		return "injection disabled"
+	}
+	return fmt.Sprintf("injection enabled (revision %s), %d pods injected, %d not injected, %d of another revision",
+		n.Revision, n.InjectedPods, len(n.NotInjected), len(n.OtherRevision))
+}
+
+// getInjectionStatus lists the namespaces labeled with istio-injection or
+// istio.io/rev on every cluster along with the injection of their pods,
+// sorted by cluster and namespace
+func (istio *Istio) getInjectionStatus(kubeConfigs []string) ([]NamespaceInjection, error) {
+	var mx sync.Mutex
+	var status []NamespaceInjection
+	err := forEachCluster(kubeConfigs, func(k8sconfig string) error {
+		mclient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return err
+		}
+		namespaces, err := mclient.KubeClient.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		cluster := clusterName(k8sconfig)
+		for _, ns := range namespaces.Items {
+			_, injection := ns.Labels["istio-injection"]
+			_, rev := ns.Labels["istio.io/rev"]
+			if !injection && !rev {
+				continue
+			}
+			pods, err := mclient.KubeClient.CoreV1().Pods(ns.Name).List(context.TODO(), metav1.ListOptions{})
+			if err != nil {
+				return err
+			}
+			n := namespaceInjection(ns, pods.Items)
+			n.Cluster = cluster
+			mx.Lock()
+			status = append(status, n)
+			mx.Unlock()
+		}
+		return nil
+	}, nil)
+	sort.Slice(status, func(i, j int) bool {
+		if status[i].Cluster != status[j].Cluster {
+			return status[i].Cluster < status[j].Cluster
+		}
+		return status[i].Namespace < status[j].Namespace
+	})
+	if err != nil {
+		return status, ErrInjectionStatus(err)
+	}
+	return status, nil
+}
+
+// namespaceInjection returns the injection of the namespace and its pods,
+// istio-injection taking precedence over istio.io/rev like it does for the
+// injection webhooks
+func namespaceInjection(ns corev1.Namespace, pods []corev1.Pod) NamespaceInjection {
+	n := NamespaceInjection{Namespace: ns.Name}
+	if label, ok := ns.Labels["istio-injection"]; ok {
+		n.Enabled = label == "enabled"
+		if n.Enabled {
+			n.Revision = "default"
+		}
+	} else {
+		n.Enabled = true
+		n.Revision = revisionName(ns.Labels["istio.io/rev"])
+	}
+	if !n.Enabled {
+		return n
+	}
+
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if pod.Labels["sidecar.istio.io/inject"] == "false" || pod.Annotations["sidecar.istio.io/inject"] == "false" || pod.Spec.HostNetwork {
+			continue
+		}
+		annotation, ok := pod.Annotations["sidecar.istio.io/status"]
+		if !ok {
+			n.NotInjected = append(n.NotInjected, pod.Name)
+			continue
+		}
+		var status struct {
+			Revision string `json:"revision"`
+		}
+		_ = json.Unmarshal([]byte(annotation), &status)
+		if revisionName(status.Revision) != n.Revision {
+			n.OtherRevision = append(n.OtherRevision, pod.Name)
+			continue
+		}
+		n.InjectedPods++
+	}
+	return n
+}
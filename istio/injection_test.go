@@ -0,0 +1,66 @@
+package istio
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestNamespaceInjection(t *testing.T) {
+	namespace := func(labels map[string]string) corev1.Namespace {
+		ns := corev1.Namespace{}
+		ns.Name = "bookinfo"
+		ns.Labels = labels
+		return ns
+	}
+	pod := func(name string, annotations map[string]string) corev1.Pod {
+		p := corev1.Pod{}
+		p.Name = name
+		p.Annotations = annotations
+		p.Status.Phase = corev1.PodRunning
+		return p
+	}
+	completed := pod("migration", nil)
+	completed.Status.Phase = corev1.PodSucceeded
+	pods := []corev1.Pod{
+		pod("productpage", map[string]string{"sidecar.istio.io/status": `{"revision":"default"}`}),
+		pod("reviews", map[string]string{"sidecar.istio.io/status": `{"revision":"1-20"}`}),
+		pod("ratings", nil),
+		pod("debug", map[string]string{"sidecar.istio.io/inject": "false"}),
+		completed,
+	}
+
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   NamespaceInjection
+	}{
+		{
+			name:   "default revision",
+			labels: map[string]string{"istio-injection": "enabled"},
+			want:   NamespaceInjection{Namespace: "bookinfo", Enabled: true, Revision: "default", InjectedPods: 1, NotInjected: []string{"ratings"}, OtherRevision: []string{"reviews"}},
+		},
+		{
+			name:   "revision",
+			labels: map[string]string{"istio.io/rev": "1-20"},
+			want:   NamespaceInjection{Namespace: "bookinfo", Enabled: true, Revision: "1-20", InjectedPods: 1, NotInjected: []string{"ratings"}, OtherRevision: []string{"productpage"}},
+		},
+		{
+			name:   "istio-injection takes precedence",
+			labels: map[string]string{"istio-injection": "disabled", "istio.io/rev": "1-20"},
+			want:   NamespaceInjection{Namespace: "bookinfo"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := namespaceInjection(namespace(tt.labels), pods)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("namespaceInjection() = %+v, want %+v", got, tt.want)
+			}
+			if got.NeedsRestart() != tt.want.Enabled {
+				t.Errorf("NeedsRestart() = %v, want %v", got.NeedsRestart(), tt.want.Enabled)
+			}
+		})
+	}
+}
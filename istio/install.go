@@ -5,20 +5,25 @@ import (
 	"archive/zip"
 	"bytes"
 	"compress/gzip"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"os/exec"
 	"path"
+	"regexp"
 	"runtime"
 	"strings"
-	"sync"
 
 	"github.com/layer5io/meshery-adapter-library/adapter"
 	"github.com/layer5io/meshery-adapter-library/status"
 	"github.com/layer5io/meshery-istio/internal/config"
 	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 )
 
 const (
@@ -28,14 +33,305 @@ const (
 
 var (
 	downloadLocation = os.TempDir()
+
+	minorVersionRegex = regexp.MustCompile(`v?(\d+)\.(\d+)`)
 )
 
+// installProfiles are the IstioOperator profiles which can be installed
+var installProfiles = map[string]bool{
+	"default": true,
+	"demo":    true,
+	"minimal": true,
+	"ambient": true,
+}
+
+// installOptions describes how the Istio control plane is installed
+type installOptions struct {
+	// Profile is the IstioOperator profile, "default" is used when empty
+	Profile string
+
+	// Revision is the control plane revision to install, the default
+	// revision is used when empty
+	Revision string
+
+	// ProxyResources are the resources of the sidecar proxies, the defaults
+	// of the profile are used when nil
+	ProxyResources proxyResources
+
+	// TracingSampling, if set, is the percentage of the requests the
+	// proxies trace, see parseTracingSampling
+	TracingSampling *float64
+
+	// Pilot is how istiod is scaled, the scaling of the profile is used
+	// when empty
+	Pilot pilotScaling
+
+	// CA is the CA istiod issues the workload certificates with, its
+	// self-signed root is used when empty
+	CA customCA
+
+	// TrustDomain is the trust domain of the workload identities, the one
+	// of the profile is used when empty
+	TrustDomain trustDomain
+
+	// CNI is how the Istio CNI plugin is installed, the plugin of the
+	// profile is used when it isn't enabled, see newCNIConfig
+	CNI cniConfig
+
+	// OperatorManifest, if set, is the IstioOperator applied by istioctl
+	// instead of the one rendered from the options, see useOperatorManifest
+	OperatorManifest []byte
+
+	// Metadata are the extra labels and annotations merged onto the
+	// resources the install creates in istio-system
+	Metadata extraMetadata
+
+	// Hub, if set, is the registry the Istio images are pulled from, see
+	// parseImageHub
+	Hub string
+
+	// Topology, if set, places the control plane in a multi-cluster mesh,
+	// see newMeshTopology
+	Topology meshTopology
+
+	// NoWait, if set, returns the install once the control plane is applied
+	// without waiting for its deployments to roll out, with statusSubmitted
+	NoWait bool
+
+	// OnCluster, if set, is called once the install is done on a cluster
+	OnCluster clusterProgress
+
+	// OnRetry, if set, is called before the install is retried on a cluster
+	OnRetry retryProgress
+
+	// OnPhase, if set, is called once a phase of the install is done on all
+	// the clusters
+	OnPhase installPhaseProgress
+
+	// OnUpgrade, if set, is called before the control plane of the revision
+	// is upgraded from the version running on the clusters
+	OnUpgrade func(from string)
+
+	// OnDowngrade, if set, is called before the control plane of the
+	// revision is downgraded from the later versions running on the clusters
+	OnDowngrade func(from []string)
+
+	// OnUnsupportedVersion, if set, is called before the install of a
+	// version which reached or is near its end of life, the install going
+	// ahead regardless
+	OnUnsupportedVersion func(support versionSupport)
+
+	// OnTrustDomainChange, if set, is called before the trust domain of the
+	// control plane of the revision is changed from the ones running on the
+	// clusters, the certificates of the workloads keeping the former one
+	// until they are rotated
+	OnTrustDomainChange func(from []string)
+
+	// OnRender, if set, makes the install a dry run: the manifest of the
+	// control plane is rendered and passed to it, nothing is applied
+	OnRender func(manifest []byte)
+}
+
+// statusSubmitted is the status of an install which didn't wait for the
+// rollout, the control plane being applied but not necessarily ready
+const statusSubmitted = "submitted"
+
+// installPhase is a step of the install, the phases are done in order
+type installPhase int
+
+const (
+	phaseCRDs installPhase = iota
+	phaseIstiod
+	phaseGateways
+)
+
+// installPhases are the phases of an install in the order they are done
+var installPhases = []installPhase{phaseCRDs, phaseIstiod, phaseGateways}
+
+// installPhaseProgress is called once a phase of the install is done, percent
+// being the rough share of the install done by then
+type installPhaseProgress func(phase installPhase, percent int)
+
+func (p installPhase) String() string {
+	switch p {
+	case phaseCRDs:
+		return "CRDs applied"
+	case phaseIstiod:
+		return "istiod rolled out"
+	case phaseGateways:
+		return "gateways rolled out"
+	}
+	return fmt.Sprintf("phase %d", int(p))
+}
+
+// percent is the rough share of the install done once the phase is done, the
+// rollouts taking most of the time
+func (p installPhase) percent() int {
+	switch p {
+	case phaseCRDs:
+		return 25
+	case phaseIstiod:
+		return 60
+	}
+	return 90
+}
+
+// deployments returns the deployments the phase rolls out in istio-system
+// for the profile and revision of the install
+func (p installPhase) deployments(opts installOptions) []string {
+	switch p {
+	case phaseIstiod:
+		if opts.Revision != "" {
+			return []string{fmt.Sprintf("istiod-%s", opts.Revision)}
+		}
+		return []string{"istiod"}
+	case phaseGateways:
+		// The gateways are shared with the default revision
+		if opts.Revision != "" {
+			return nil
+		}
+		switch opts.Profile {
+		case "default":
+			return []string{"istio-ingressgateway"}
+		case "demo":
+			return []string{"istio-ingressgateway", "istio-egressgateway"}
+		}
+	}
+	return nil
+}
+
+// proxyResources are the requests and limits of the sidecar proxies, keyed
+// by requests or limits and then by cpu or memory
+type proxyResources map[string]map[string]string
+
+// newProxyResources reads the proxy resources from the properties of the
+// install operation, nil is returned if none is set. The quantities are
+// returned in their canonical form.
+func newProxyResources(properties map[string]string) (proxyResources, error) {
+	var resources proxyResources
+	for _, key := range []string{config.ProxyCPURequest, config.ProxyMemoryRequest, config.ProxyCPULimit, config.ProxyMemoryLimit} {
+		value := strings.TrimSpace(properties[key])
+		if value == "" {
+			continue
+		}
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, ErrInvalidResourceQuantity(key, value, err)
+		}
+		// The keys are proxyResources.<requests|limits>.<cpu|memory>
+		parts := strings.Split(key, ".")
+		if resources == nil {
+			resources = proxyResources{}
+		}
+		if resources[parts[1]] == nil {
+			resources[parts[1]] = map[string]string{}
+		}
+		resources[parts[1]][parts[2]] = quantity.String()
+	}
+	return resources, nil
+}
+
+// String describes the resources for the event details
+func (r proxyResources) String() string {
+	var parts []string
+	for _, kind := range []string{"requests", "limits"} {
+		for _, name := range []string{"cpu", "memory"} {
+			if q, ok := r[kind][name]; ok {
+				parts = append(parts, fmt.Sprintf("%s %s=%s", kind, name, q))
+			}
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// values returns the helm values, also used as the IstioOperator values,
+// setting the resources of the proxies
+func (r proxyResources) values() map[string]interface{} {
+	resources := map[string]interface{}{}
+	for kind, quantities := range r {
+		q := map[string]interface{}{}
+		for name, quantity := range quantities {
+			q[name] = quantity
+		}
+		resources[kind] = q
+	}
+	return map[string]interface{}{
+		"proxy": map[string]interface{}{
+			"resources": resources,
+		},
+	}
+}
+
+// globalValues returns the global helm values, also used as the
+// IstioOperator values, of the proxy resources, the image hub and the mesh
+// topology, nil if none is set
+func (opts installOptions) globalValues() map[string]interface{} {
+	var global map[string]interface{}
+	if opts.ProxyResources != nil {
+		global = opts.ProxyResources.values()
+	}
+	if opts.Hub != "" {
+		if global == nil {
+			global = map[string]interface{}{}
+		}
+		global["hub"] = opts.Hub
+	}
+	if !opts.Topology.empty() {
+		if global == nil {
+			global = map[string]interface{}{}
+		}
+		for key, value := range opts.Topology.values() {
+			global[key] = value
+		}
+	}
+	if opts.CA.Address != "" {
+		if global == nil {
+			global = map[string]interface{}{}
+		}
+		global["caAddress"] = opts.CA.Address
+	}
+	return global
+}
+
+// pilotValues returns the pilot values of the scaling and the CA of istiod,
+// used both as the helm values and in the IstioOperator, nil if neither is
+// set
+func (opts installOptions) pilotValues() map[string]interface{} {
+	pilot := opts.Pilot.values()
+	if env := opts.CA.env(); env != nil {
+		if pilot == nil {
+			pilot = map[string]interface{}{}
+		}
+		pilot["env"] = env
+	}
+	return pilot
+}
+
+// meshConfig returns the mesh config of the tracing sampling and the trust
+// domain, used both as the helm values and in the IstioOperator, nil if
+// neither is set
+func (opts installOptions) meshConfig() map[string]interface{} {
+	if opts.TracingSampling == nil && opts.TrustDomain.empty() {
+		return nil
+	}
+	mesh := opts.TrustDomain.meshConfig()
+	if opts.TracingSampling != nil {
+		mesh["defaultConfig"] = map[string]interface{}{
+			"tracing": map[string]interface{}{
+				"sampling": *opts.TracingSampling,
+			},
+		}
+	}
+	return mesh
+}
+
 // installs Istio using either helm charts or istioctl.
 // Priority given to helm charts unless useBin set to true
-func (istio *Istio) installIstio(del, useBin bool, version, namespace string, profile string, kubeconfigs []string) (string, error) {
-	istio.Log.Debug(fmt.Sprintf("Requested install of version: %s", version))
-	istio.Log.Debug(fmt.Sprintf("Requested action is delete: %v", del))
-	istio.Log.Debug(fmt.Sprintf("Requested action is in namespace: %s", namespace))
+func (istio *Istio) installIstio(ctx context.Context, del, useBin bool, version, namespace string, opts installOptions, kubeconfigs []string) (string, error) {
+	istio.log(ctx).Debug(fmt.Sprintf("Requested install of version: %s", version))
+	istio.log(ctx).Debug(fmt.Sprintf("Requested action is delete: %v", del))
+	istio.log(ctx).Debug(fmt.Sprintf("Requested action is in namespace: %s", namespace))
+	istio.log(ctx).Debug(fmt.Sprintf("Requested revision: %s", opts.Revision))
 
 	st := status.Installing
 
@@ -43,11 +339,158 @@ func (istio *Istio) installIstio(del, useBin bool, version, namespace string, pr
 		st = status.Removing
 	}
 
-	err := istio.Config.GetObject(adapter.MeshSpecKey, istio)
+	if opts.Profile == "" {
+		opts.Profile = "default"
+	}
+	if !installProfiles[opts.Profile] {
+		return st, ErrInvalidProfile(opts.Profile)
+	}
+	istio.log(ctx).Debug(fmt.Sprintf("Requested profile: %s", opts.Profile))
+
+	// The IstioOperator supplied by the user is applied by istioctl as is
+	if opts.OperatorManifest != nil {
+		useBin = true
+	}
+
+	// The ambient data plane (istio-cni and ztunnel) is not part of the
+	// charts applied below, hence ambient is always installed by istioctl,
+	// its profile installing istio-cni and ztunnel along with istiod
+	if opts.Profile == "ambient" {
+		useBin = true
+		if !del {
+			if err := ambientSupported(version); err != nil {
+				return st, err
+			}
+		}
+	}
+	// Likewise, the CNI node agent is installed along with istiod by
+	// istioctl
+	if opts.CNI.Enabled {
+		useBin = true
+	}
+
+	if opts.OnRender != nil {
+		manifest, err := istio.renderManifest(ctx, version, opts)
+		if err != nil {
+			return st, err
+		}
+		opts.OnRender(manifest)
+		return statusRendered, nil
+	}
+
+	// The control plane of the revision running the requested version on
+	// every cluster is left untouched, the one running another version is
+	// upgraded or downgraded, as long as the upgrade path is supported. Only
+	// the versions are compared, hence the IstioOperator supplied by the user
+	// is always applied.
+	installed := status.Installed
+	if !del && opts.OperatorManifest == nil {
+		versions, err := installedVersions(ctx, opts.Revision, kubeconfigs)
+		if err != nil {
+			istio.log(ctx).Info(fmt.Sprintf("Unable to read the installed version, installing anyway: %v", err))
+		}
+		done, from := installState(versions, version, len(kubeconfigs))
+		if done && err == nil {
+			return statusAlreadyInstalled, nil
+		}
+		downgrades, err := checkUpgradePath(versions, version)
+		if err != nil {
+			return st, err
+		}
+		switch {
+		case len(downgrades) > 0:
+			istio.log(ctx).Info(fmt.Sprintf("Downgrading the %s revision from %s to %s...", revisionName(opts.Revision), strings.Join(downgrades, ", "), version))
+			st, installed = statusDowngrading, statusDowngraded
+			if opts.OnDowngrade != nil {
+				opts.OnDowngrade(downgrades)
+			}
+		case from != "":
+			istio.log(ctx).Info(fmt.Sprintf("Upgrading the %s revision from %s to %s...", revisionName(opts.Revision), from, version))
+			st, installed = statusUpgrading, statusUpgraded
+			if opts.OnUpgrade != nil {
+				opts.OnUpgrade(from)
+			}
+		}
+		if !opts.TrustDomain.empty() && opts.OnTrustDomainChange != nil {
+			changed, err := changedTrustDomains(ctx, opts.Revision, opts.TrustDomain.domain(), kubeconfigs)
+			if err != nil {
+				istio.log(ctx).Info(fmt.Sprintf("Unable to read the installed trust domain, installing anyway: %v", err))
+			}
+			if len(changed) > 0 {
+				opts.OnTrustDomainChange(changed)
+			}
+		}
+	}
+
+	if !del && opts.OnUnsupportedVersion != nil {
+		// The support window is only known from the releases, hence
+		// nothing is reported when they can't be listed
+		available, err := listAvailableVersions()
+		if err != nil {
+			istio.log(ctx).Info(fmt.Sprintf("Unable to list the Istio releases, the support of %s is not checked: %v", version, err))
+		}
+		if support := versionSupportOf(version, available); !support.Supported() {
+			opts.OnUnsupportedVersion(support)
+		}
+	}
+
+	if !del {
+		if err := istio.preflightCheck(ctx, version, kubeconfigs); err != nil {
+			return st, err
+		}
+		// istiod loads the plugged-in CA when it starts, hence the cacerts
+		// secret is created before istiod is installed
+		if err := plugCACerts(ctx, opts.CA, kubeconfigs); err != nil {
+			return st, ErrInvalidCAConfig(err)
+		}
+	}
+
+	var spec adapter.Spec
+	err := istio.Config.GetObject(adapter.MeshSpecKey, &spec)
 	if err != nil {
 		return st, ErrMeshConfig(err)
 	}
 
+	// Install using istioctl if explicitly stated
+	if useBin {
+		// The waypoints are Gateway API resources, which the uninstall
+		// leaves behind without the control plane deploying their proxies
+		if del && opts.Profile == "ambient" && opts.Revision == "" {
+			if err := removeWaypoints(kubeconfigs); err != nil {
+				return st, ErrInstallUsingIstioctl(fmt.Errorf("unable to remove the waypoints: %w", err))
+			}
+		}
+		istio.log(ctx).Info("Installing istio using istioctl...")
+		if err := istio.installWithIstioctl(ctx, del, version, opts, kubeconfigs); err != nil {
+			return st, err
+		}
+
+		if del {
+			return status.Removed, nil
+		}
+		if !opts.NoWait {
+			for _, phase := range installPhases {
+				if err := istio.completePhase(ctx, phase, opts, kubeconfigs); err != nil {
+					return st, err
+				}
+			}
+		}
+		if err := labelInstalled(ctx, opts.Metadata, kubeconfigs); err != nil {
+			return st, ErrInstallUsingIstioctl(err)
+		}
+		if opts.NoWait {
+			return statusSubmitted, nil
+		}
+		return installed, nil
+	}
+
+	// Install using Helm Chart and fallback to istioctl. Only the clusters
+	// done with the helm charts are reported as the failing ones are retried
+	helmProgress := func(cluster string, err error) {
+		if err == nil && opts.OnCluster != nil {
+			opts.OnCluster(cluster, nil)
+		}
+	}
 	// Fetch and/or return the path to downloaded and extracted release bundle
 	dirName, err := istio.getIstioRelease(version)
 	if err != nil {
@@ -55,41 +498,113 @@ func (istio *Istio) installIstio(del, useBin bool, version, namespace string, pr
 		return st, ErrGettingIstioRelease(err)
 	}
 
-	// Install using istioctl if explicitly stated
-	if useBin {
-		istio.Log.Info("Installing istio using istioctl...")
-		err = istio.runIstioCtlCmd(version, del, dirName, kubeconfigs)
-		if err != nil {
-			return st, ErrInstallUsingIstioctl(err)
+	// The charts of a phase are applied on every cluster before the phase
+	// completes, the removal goes the other way round
+	phases := installPhases
+	if del {
+		phases = []installPhase{phaseGateways, phaseIstiod, phaseCRDs}
+	}
+	done := 0
+	for i, phase := range phases {
+		if err := ctx.Err(); err != nil {
+			return st, interrupted(ctx)
 		}
+		var progress clusterProgress
+		if i == len(phases)-1 {
+			progress = helmProgress
+		}
+		if err = istio.applyHelmChart(ctx, del, phase, version, dirName, opts, progress, kubeconfigs); err != nil {
+			break
+		}
+		if !del && !opts.NoWait {
+			if err := istio.completePhase(ctx, phase, opts, kubeconfigs); err != nil {
+				return st, err
+			}
+		}
+		done++
 	}
-
-	// Install using Helm Chart and fallback to istioctl
-	err = istio.applyHelmChart(del, version, namespace, dirName, profile, kubeconfigs)
 	if err != nil {
-		istio.Log.Error(err)
-		istio.Log.Info("Retrying to install using istioctl...")
-
-		err = istio.runIstioCtlCmd(version, del, dirName, kubeconfigs)
-		if err != nil {
-			return st, ErrInstallUsingIstioctl(err)
+		istio.log(ctx).Error(err)
+		if ctx.Err() != nil {
+			return st, interrupted(ctx)
 		}
+		istio.log(ctx).Info("Retrying to install using istioctl...")
 
-		return st, nil
+		if err := istio.installWithIstioctl(ctx, del, version, opts, kubeconfigs); err != nil {
+			return st, err
+		}
+		if !del && !opts.NoWait {
+			for _, phase := range phases[done:] {
+				if err := istio.completePhase(ctx, phase, opts, kubeconfigs); err != nil {
+					return st, err
+				}
+			}
+		}
 	}
 
 	if del {
 		return status.Removed, nil
 	}
-	return status.Installed, nil
+	if err := labelInstalled(ctx, opts.Metadata, kubeconfigs); err != nil {
+		return st, ErrApplyHelmChart(err)
+	}
+	if opts.NoWait {
+		return statusSubmitted, nil
+	}
+	return installed, nil
+}
+
+// completePhase waits for the deployments of the phase to roll out on every
+// cluster and reports the phase. The gateways phase of the installs without
+// gateways is not reported.
+func (istio *Istio) completePhase(ctx context.Context, phase installPhase, opts installOptions, kubeconfigs []string) error {
+	deployments := phase.deployments(opts)
+	if err := istio.waitForRollout(ctx, istioRootNamespace, deployments, rolloutTimeout(ctx, istioRolloutTimeout), kubeconfigs); err != nil {
+		if ctx.Err() != nil {
+			return interrupted(ctx)
+		}
+		return err
+	}
+	if phase == phaseGateways && len(deployments) == 0 {
+		return nil
+	}
+	if opts.OnPhase != nil {
+		opts.OnPhase(phase, phase.percent())
+	}
+	return nil
+}
+
+// otherRevisions returns the revisions of the control planes running in
+// istio-system other than the given one, the default revision being ""
+func otherRevisions(client kubernetes.Interface, revision string) ([]string, error) {
+	deployments, err := client.AppsV1().Deployments(istioRootNamespace).List(context.TODO(), metav1.ListOptions{LabelSelector: "app=istiod"})
+	if err != nil {
+		return nil, err
+	}
+	var others []string
+	for _, deployment := range deployments.Items {
+		rev := deployment.Labels["istio.io/rev"]
+		if rev == "default" {
+			rev = ""
+		}
+		if rev != revision {
+			others = append(others, rev)
+		}
+	}
+	return others, nil
 }
 
-func (istio *Istio) applyHelmChart(del bool, version, namespace, dirName string, profile string, kubeconfigs []string) error {
-	if profile != "demo" && profile != "default" && profile != "minimal" {
-		return ErrInvalidInstallationProfile(profile) // This code will never be executed as json schema would have been validated beforehand
+// applyHelmChart installs or removes the charts of the install phase on
+// every cluster: the base chart with the CRDs, the istiod chart and the
+// gateway charts. The base chart isn't reinstalled on the clusters its CRDs
+// are installed on at a version compatible with the requested one, such as
+// by the Istio base operation.
+func (istio *Istio) applyHelmChart(ctx context.Context, del bool, phase installPhase, version, dirName string, opts installOptions, progress clusterProgress, kubeconfigs []string) error {
+	profile := opts.Profile
+	if !installProfiles[profile] || profile == "ambient" {
+		return ErrInvalidProfile(profile)
 	}
-	var errs []error
-	istio.Log.Info("Installing using helm charts...")
+	istio.log(ctx).Info(fmt.Sprintf("Installing using helm charts until %s...", phase))
 	var act mesherykube.HelmChartAction
 	if del {
 		act = mesherykube.UNINSTALL
@@ -97,83 +612,111 @@ func (istio *Istio) applyHelmChart(del bool, version, namespace, dirName string,
 		act = mesherykube.INSTALL
 	}
 
-	var wg sync.WaitGroup
-	var errMx sync.Mutex
-	for _, config := range kubeconfigs {
-		wg.Add(1)
-		go func(config string, act mesherykube.HelmChartAction) {
-			defer wg.Done()
-			kClient, err := mesherykube.New([]byte(config))
-			if err != nil {
-				errMx.Lock()
-				errs = append(errs, err)
-				errMx.Unlock()
-				return
+	// A revisioned control plane runs next to the existing one, hence only
+	// its own istiod release is installed or removed, leaving the shared
+	// base chart and the gateways of the other revisions untouched
+	releaseName := "istiod"
+	values := map[string]interface{}{
+		"profile": profile,
+	}
+	if opts.Revision != "" {
+		releaseName = fmt.Sprintf("istiod-%s", opts.Revision)
+		values["revision"] = opts.Revision
+	}
+	global := opts.globalValues()
+	if global != nil {
+		values["global"] = global
+	}
+	if meshConfig := opts.meshConfig(); meshConfig != nil {
+		values["meshConfig"] = meshConfig
+	}
+	if pilot := opts.pilotValues(); pilot != nil {
+		values["pilot"] = pilot
+	}
+	// The gateway charts only take the hub, the proxy resources being the
+	// ones of the sidecars
+	var gatewayValues map[string]interface{}
+	if opts.Hub != "" {
+		gatewayValues = map[string]interface{}{"global": map[string]interface{}{"hub": opts.Hub}}
+	}
+
+	err := forEachCluster(kubeconfigs, func(config string) error {
+		kClient, err := mesherykube.New([]byte(config))
+		if err != nil {
+			return err
+		}
+		switch phase {
+		case phaseCRDs:
+			// The base chart holds the CRDs and the webhooks the other
+			// revisions depend on, hence it's only removed along with the
+			// last revision
+			removeBase := opts.Revision == ""
+			if del && removeBase {
+				others, err := otherRevisions(kClient.KubeClient, "")
+				if err != nil {
+					return err
+				}
+				removeBase = len(others) == 0
+			}
+			if del && !removeBase {
+				return nil
+			}
+			if !del {
+				installed, err := baseInstalled(ctx, kClient.DynamicKubeClient, version)
+				if err != nil {
+					return err
+				}
+				if installed {
+					istio.log(ctx).Info(fmt.Sprintf("Istio CRDs compatible with %s already installed, skipping the base chart", version))
+					return nil
+				}
 			}
-			err = kClient.ApplyHelmChart(mesherykube.ApplyHelmChartConfig{
+			return kClient.ApplyHelmChart(mesherykube.ApplyHelmChartConfig{
 				LocalPath:       path.Join(downloadLocation, dirName, "manifests/charts/base"),
 				Namespace:       "istio-system",
 				Action:          act,
 				CreateNamespace: true,
 			})
-			if err != nil {
-				errMx.Lock()
-				errs = append(errs, err)
-				errMx.Unlock()
-				return
-			}
-
-			err = kClient.ApplyHelmChart(mesherykube.ApplyHelmChartConfig{
+		case phaseIstiod:
+			return kClient.ApplyHelmChart(mesherykube.ApplyHelmChartConfig{
 				LocalPath:       path.Join(downloadLocation, dirName, "manifests/charts/istio-control/istio-discovery"),
+				ReleaseName:     releaseName,
 				Namespace:       "istio-system",
 				Action:          act,
 				CreateNamespace: true,
+				OverrideValues:  values,
 			})
-			if err != nil {
-				errMx.Lock()
-				errs = append(errs, err)
-				errMx.Unlock()
-				return
-			}
+		}
 
-			if profile == "minimal" {
-				return
-			}
-			err = kClient.ApplyHelmChart(mesherykube.ApplyHelmChartConfig{
-				LocalPath:       path.Join(downloadLocation, dirName, "manifests/charts/gateways/istio-ingress"),
-				Namespace:       "istio-system",
-				Action:          act,
-				CreateNamespace: true,
-			})
-			if err != nil {
-				errMx.Lock()
-				errs = append(errs, err)
-				errMx.Unlock()
-				return
-			}
+		if profile == "minimal" || opts.Revision != "" {
+			return nil
+		}
+		err = kClient.ApplyHelmChart(mesherykube.ApplyHelmChartConfig{
+			LocalPath:       path.Join(downloadLocation, dirName, "manifests/charts/gateways/istio-ingress"),
+			Namespace:       "istio-system",
+			Action:          act,
+			CreateNamespace: true,
+			OverrideValues:  gatewayValues,
+		})
+		if err != nil {
+			return err
+		}
 
-			if profile == "default" {
-				return
-			}
-			err = kClient.ApplyHelmChart(mesherykube.ApplyHelmChartConfig{
-				LocalPath:       path.Join(downloadLocation, dirName, "manifests/charts/gateways/istio-egress"),
-				Namespace:       "istio-system",
-				Action:          act,
-				CreateNamespace: true,
-			})
-			if err != nil {
-				errMx.Lock()
-				errs = append(errs, err)
-				errMx.Unlock()
-				return
-			}
-		}(config, act)
-	}
-	wg.Wait()
-	if len(errs) == 0 {
-		return nil
+		if profile == "default" {
+			return nil
+		}
+		return kClient.ApplyHelmChart(mesherykube.ApplyHelmChartConfig{
+			LocalPath:       path.Join(downloadLocation, dirName, "manifests/charts/gateways/istio-egress"),
+			Namespace:       "istio-system",
+			Action:          act,
+			CreateNamespace: true,
+			OverrideValues:  gatewayValues,
+		})
+	}, progress)
+	if err != nil {
+		return ErrApplyHelmChart(err)
 	}
-	return ErrApplyHelmChart(mergeErrors(errs))
+	return nil
 }
 
 // getIstioRelease gets the manifests for latest istio release.
@@ -255,125 +798,126 @@ func extractTar(res *http.Response) error {
 	return nil
 }
 
+// installWithIstioctl installs/uninstalls Istio with the istioctl executable
+// of the release. The executable must have the minor version being
+// installed, ErrIstioctlVersionMismatch is returned otherwise.
+func (istio *Istio) installWithIstioctl(ctx context.Context, del bool, version string, opts installOptions, kubeconfigs []string) error {
+	executable, err := istio.getExecutable(version)
+	if err != nil {
+		return ErrInstallUsingIstioctl(err)
+	}
+	if !del {
+		if err := verifyIstioctlVersion(executable, version); err != nil {
+			return err
+		}
+	}
+	if err := istio.runIstioCtlCmd(ctx, executable, del, opts, kubeconfigs); err != nil {
+		return ErrInstallUsingIstioctl(err)
+	}
+	return nil
+}
+
 // Installs Istio using Istioctl
 // TODO: Figure out why this is not working in containers
-func (istio *Istio) runIstioCtlCmd(version string, isDel bool, dirName string, kubeconfigs []string) error {
-	var (
-		out bytes.Buffer
-		er  bytes.Buffer
-	)
-	var wg sync.WaitGroup
-	var errMx sync.Mutex
-	var errs []error
-	for _, config := range kubeconfigs {
-		wg.Add(1)
-		go func(config string, isDel bool) {
-			defer wg.Done()
-			kClient, err := mesherykube.New([]byte(config))
-			if err != nil {
-				errMx.Lock()
-				errs = append(errs, err)
-				errMx.Unlock()
-				return
-			}
-			kContext, err := kClient.GetCurrentContext()
-			if err != nil {
-				errMx.Lock()
-				errs = append(errs, err)
-				errMx.Unlock()
-				return
-			}
-			istio.Log.Info("Installing using istioctl...")
+func (istio *Istio) runIstioCtlCmd(ctx context.Context, executable string, isDel bool, opts installOptions, kubeconfigs []string) error {
+	operator, err := renderIstioOperator(opts)
+	if err != nil {
+		return err
+	}
+	operatorFile, err := os.CreateTemp("", "istio-operator-*.yaml")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(operatorFile.Name())
+	if _, err := operatorFile.Write(operator); err != nil {
+		_ = operatorFile.Close()
+		return err
+	}
+	if err := operatorFile.Close(); err != nil {
+		return err
+	}
 
-			Executable, err := istio.getExecutable(version, dirName)
-			if err != nil {
-				errMx.Lock()
-				errs = append(errs, err)
-				errMx.Unlock()
-				return
+	// runIstioctl returns ErrRunIstioCtlCmd already, hence the merged errors of
+	// the clusters are returned as is
+	return forEachCluster(kubeconfigs, func(config string) error {
+		kClient, err := mesherykube.New([]byte(config))
+		if err != nil {
+			return err
+		}
+		kContext, err := kClient.GetCurrentContext()
+		if err != nil {
+			return err
+		}
+		istio.log(ctx).Info("Installing using istioctl...")
+
+		execCmd := []string{"install", "-f", operatorFile.Name(), "-y", "--context", kContext}
+		if isDel {
+			revision := opts.Revision
+			if revision == "" {
+				others, err := otherRevisions(kClient.KubeClient, "")
+				if err != nil {
+					return err
+				}
+				// Purging would remove the CRDs and webhooks the other
+				// revisions depend on
+				if len(others) != 0 {
+					revision = "default"
+				}
 			}
-			execCmd := []string{"install", "--set", "profile=demo", "-y", "--context", kContext}
-			if isDel {
-				execCmd = []string{"x", "uninstall", "--purge", "-y", "--context", kContext}
+			execCmd = []string{"x", "uninstall", "--purge", "-y", "--context", kContext}
+			if revision != "" {
+				// Remove only the requested revision
+				execCmd = []string{"x", "uninstall", "--revision", revision, "-y", "--context", kContext}
 			}
+		}
 
-			// We need a variable executable here hence using nosec
-			// #nosec
-			command := exec.Command(Executable, execCmd...)
-			command.Stdout = &out
-			command.Stderr = &er
-			err = command.Run()
-			if err != nil {
-				errMx.Lock()
-				errs = append(errs, err)
-				errMx.Unlock()
-				return
+		return withRetry(ctx, func() error {
+			_, err := runIstioctlOnCluster(executable, config, execCmd...)
+			return err
+		}, func(attempt int, err error) {
+			istio.log(ctx).Info(fmt.Sprintf("Retrying istioctl on %s after attempt %d failed: %v", kContext, attempt, err))
+			if opts.OnRetry != nil {
+				opts.OnRetry(kContext, attempt, err)
 			}
-		}(config, isDel)
-	}
-	wg.Wait()
-	if len(errs) == 0 {
-		return nil
-	}
+		})
+	}, opts.OnCluster)
+}
 
-	return ErrRunIstioCtlCmd(mergeErrors(errs), mergeErrors(errs).Error())
+func (istio *Istio) applyManifest(ctx context.Context, contents []byte, isDel bool, namespace string, kubeconfigs []string) error {
+	return forEachCluster(kubeconfigs, func(k8sconfig string) error {
+		return istio.applyManifestOnCluster(ctx, contents, isDel, namespace, k8sconfig)
+	}, nil)
 }
 
-func (istio *Istio) applyManifest(contents []byte, isDel bool, namespace string, kubeconfigs []string) error {
-	var wg sync.WaitGroup
-	var errs []error
-	var errMx sync.Mutex
-	for _, k8sconfig := range kubeconfigs {
-		wg.Add(1)
-		go func(k8sconfig string) {
-			defer wg.Done()
-			mclient, err := mesherykube.New([]byte(k8sconfig))
-			if err != nil {
-				errMx.Lock()
-				errs = append(errs, err)
-				errMx.Unlock()
-				return
-			}
-			err = mclient.ApplyManifest(contents, mesherykube.ApplyOptions{
-				Namespace: namespace,
-				Update:    true,
-				Delete:    isDel,
-			})
-			if err != nil {
-				errMx.Lock()
-				errs = append(errs, err)
-				errMx.Unlock()
-				return
-			}
-		}(k8sconfig)
-	}
-	wg.Wait()
-	if len(errs) == 0 {
-		return nil
+// applyManifestOnCluster applies the manifest to the cluster of the
+// kubeconfig with server-side apply, retrying its transient failures
+func (istio *Istio) applyManifestOnCluster(ctx context.Context, contents []byte, isDel bool, namespace, k8sconfig string) error {
+	mclient, err := mesherykube.New([]byte(k8sconfig))
+	if err != nil {
+		return err
 	}
-	return mergeErrors(errs)
+	cluster := clusterName(k8sconfig)
+	return withRetry(ctx, func() error {
+		return serverSideApply(ctx, mclient, contents, isDel, namespace)
+	}, func(attempt int, err error) {
+		istio.log(ctx).Info(fmt.Sprintf("Retrying to apply the manifest on %s after attempt %d failed: %v", cluster, attempt, err))
+	})
 }
 
 // For direct simpler use cases
 func (istio *Istio) applyManifestOnSingleCluster(contents []byte, isDel bool, namespace string, mclient *mesherykube.Client) error {
-	err := mclient.ApplyManifest(contents, mesherykube.ApplyOptions{
-		Namespace: namespace,
-		Update:    true,
-		Delete:    isDel,
-	})
-	if err != nil {
-		return err
-	}
-	return nil
+	return serverSideApply(context.TODO(), mclient, contents, isDel, namespace)
 }
 
 // getExecutable looks for the executable in
 // 1. $PATH
 // 2. Root config path
+// 3. istioctl cache, downloading the release of istioctl if not cached
 //
-// If it doesn't find the executable in the above two, it uses the one
-// in "istio-version/bin" directory in temp dir
-func (istio *Istio) getExecutable(release, dirName string) (string, error) {
+// If it doesn't find the executable in the above three, it uses the one
+// in "istio-version/bin" directory in temp dir, the release bundle being
+// downloaded only then
+func (istio *Istio) getExecutable(release string) (string, error) {
 	binaryName := generatePlatformSpecificBinaryName("istioctl", platform)
 	alternateBinaryName := generatePlatformSpecificBinaryName("istioctl-"+release, platform)
 
@@ -397,8 +941,19 @@ func (istio *Istio) getExecutable(release, dirName string) (string, error) {
 		return executable, nil
 	}
 
+	istio.Log.Info("Looking for istioctl in the cache...")
+	executable, err = istio.getIstioctl(release)
+	if err == nil {
+		return executable, nil
+	}
+	istio.Log.Warn(err)
+
 	istio.Log.Info("Using istioctl from the downloaded release bundle...")
-	executable = path.Join(dirName, "bin", binaryName)
+	dirName, err := istio.getIstioRelease(release)
+	if err != nil {
+		return "", err
+	}
+	executable = path.Join(downloadLocation, dirName, "bin", binaryName)
 	if _, err := os.Stat(executable); err == nil {
 		return executable, nil
 	}
@@ -530,3 +1085,110 @@ func generatePlatformSpecificBinaryName(binName, platform string) string {
 
 	return binName
 }
+
+// verifyIstioctlVersion makes sure that the istioctl executable which will be
+// used to install the requested version has the same major and minor version,
+// istioctl doesn't support installing a control plane of another minor version
+func verifyIstioctlVersion(executable, requestedVersion string) error {
+	out, err := runIstioctl(executable, "version", "--remote=false", "--short")
+	if err != nil {
+		return err
+	}
+	clientVersion := strings.TrimSpace(firstLine(out))
+
+	if match, err := sameMinorVersion(clientVersion, requestedVersion); err != nil || !match {
+		return ErrIstioctlVersionMismatch(clientVersion, requestedVersion)
+	}
+	return nil
+}
+
+// runIstioctl runs istioctl with the given arguments and returns its output
+func runIstioctl(executable string, args ...string) (string, error) {
+	var out, er bytes.Buffer
+	// We need a variable executable here hence using nosec
+	// #nosec
+	command := exec.Command(executable, args...)
+	command.Stdout = &out
+	command.Stderr = &er
+	if err := command.Run(); err != nil {
+		return out.String(), ErrRunIstioCtlCmd(fmt.Errorf("%w: %s", err, strings.TrimSpace(er.String())), er.String())
+	}
+	return out.String(), nil
+}
+
+// runIstioctlOnCluster runs istioctl against the cluster of the kubeconfig,
+// passed with --kubeconfig so the concurrent operations writing the shared
+// kubeconfig don't switch the cluster istioctl runs against
+func runIstioctlOnCluster(executable, k8sconfig string, args ...string) (string, error) {
+	kubeconfig, err := writeKubeconfigFile(k8sconfig)
+	if err != nil {
+		return "", ErrRunIstioCtlCmd(err, err.Error())
+	}
+	defer os.Remove(kubeconfig)
+	return runIstioctl(executable, append(args, "--kubeconfig", kubeconfig)...)
+}
+
+// sameMinorVersion reports whether both versions have the same major and minor version
+func sameMinorVersion(a, b string) (bool, error) {
+	ma := minorVersionRegex.FindStringSubmatch(a)
+	mb := minorVersionRegex.FindStringSubmatch(b)
+	if ma == nil || mb == nil {
+		return false, fmt.Errorf("unable to parse versions %q and %q", a, b)
+	}
+	return ma[1] == mb[1] && ma[2] == mb[2], nil
+}
+
+// renderIstioOperator renders the IstioOperator resource passed to istioctl
+// for the requested profile and revision, the operator manifest of the
+// options is returned as is when set
+func renderIstioOperator(opts installOptions) ([]byte, error) {
+	if opts.OperatorManifest != nil {
+		return opts.OperatorManifest, nil
+	}
+	if !installProfiles[opts.Profile] {
+		return nil, ErrInvalidProfile(opts.Profile)
+	}
+	spec := map[string]interface{}{
+		"profile": opts.Profile,
+	}
+	name := "installed-state"
+	if opts.Revision != "" {
+		spec["revision"] = opts.Revision
+		name = fmt.Sprintf("installed-state-%s", opts.Revision)
+	}
+	values := map[string]interface{}{}
+	if global := opts.globalValues(); global != nil {
+		values["global"] = global
+	}
+	if pilot := opts.pilotValues(); pilot != nil {
+		values["pilot"] = pilot
+	}
+	if cni := opts.CNI.values(); cni != nil {
+		values["cni"] = cni
+	}
+	components := map[string]interface{}{}
+	if pilot := opts.Pilot.component(); pilot != nil {
+		components["pilot"] = pilot
+	}
+	if cni := opts.CNI.component(); cni != nil {
+		components["cni"] = cni
+	}
+	if len(components) > 0 {
+		spec["components"] = components
+	}
+	if len(values) > 0 {
+		spec["values"] = values
+	}
+	if meshConfig := opts.meshConfig(); meshConfig != nil {
+		spec["meshConfig"] = meshConfig
+	}
+	return yaml.Marshal(map[string]interface{}{
+		"apiVersion": "install.istio.io/v1alpha1",
+		"kind":       "IstioOperator",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": "istio-system",
+		},
+		"spec": spec,
+	})
+}
@@ -0,0 +1,332 @@
+package istio
+
+import (
+	"os"
+	"path"
+	"reflect"
+	"testing"
+
+	"github.com/layer5io/meshery-istio/internal/config"
+	"github.com/layer5io/meshkit/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestRenderIstioOperator(t *testing.T) {
+	sampling := 12.5
+	tests := []struct {
+		name     string
+		opts     installOptions
+		wantName string
+		wantErr  bool
+	}{
+		{
+			name:     "default profile",
+			opts:     installOptions{Profile: "default"},
+			wantName: "installed-state",
+		},
+		{
+			name:     "ambient profile with revision",
+			opts:     installOptions{Profile: "ambient", Revision: "1-20"},
+			wantName: "installed-state-1-20",
+		},
+		{
+			name:     "proxy resources",
+			opts:     installOptions{Profile: "default", ProxyResources: proxyResources{"requests": {"cpu": "50m"}, "limits": {"memory": "256Mi"}}},
+			wantName: "installed-state",
+		},
+		{
+			name:     "image hub",
+			opts:     installOptions{Profile: "demo", ProxyResources: proxyResources{"limits": {"cpu": "1"}}, Hub: "registry.internal:5000/istio"},
+			wantName: "installed-state",
+		},
+		{
+			name:     "multi-cluster",
+			opts:     installOptions{Profile: "default", Hub: "registry.internal:5000/istio", Topology: meshTopology{MeshID: "mesh1", ClusterName: "east", Network: "network1"}},
+			wantName: "installed-state",
+		},
+		{
+			name:     "tracing sampling",
+			opts:     installOptions{Profile: "default", TracingSampling: &sampling},
+			wantName: "installed-state",
+		},
+		{
+			name:     "trust domain with tracing sampling",
+			opts:     installOptions{Profile: "default", TracingSampling: &sampling, TrustDomain: trustDomain{Domain: "example.com", Aliases: []string{"cluster.local", "old.example.com"}}},
+			wantName: "installed-state",
+		},
+		{
+			name:     "istiod replicas",
+			opts:     installOptions{Profile: "default", Pilot: pilotScaling{Replicas: 3}},
+			wantName: "installed-state",
+		},
+		{
+			name:     "istiod autoscaling",
+			opts:     installOptions{Profile: "default", Pilot: pilotScaling{Autoscale: true, MinReplicas: 2, MaxReplicas: 6, TargetCPU: 70}},
+			wantName: "installed-state",
+		},
+		{
+			name:     "kubernetes signer with istiod replicas",
+			opts:     installOptions{Profile: "default", Pilot: pilotScaling{Replicas: 2}, CA: customCA{Signer: "clusterissuers.cert-manager.io/istio-ca"}},
+			wantName: "installed-state",
+		},
+		{
+			name:     "external CA",
+			opts:     installOptions{Profile: "default", CA: customCA{Address: "cert-manager-istio-csr.cert-manager.svc:443"}},
+			wantName: "installed-state",
+		},
+		{
+			name:     "standalone CNI with istiod replicas",
+			opts:     installOptions{Profile: "default", Pilot: pilotScaling{Replicas: 2}, CNI: cniConfig{Enabled: true, BinDir: "/var/lib/cni/bin", ConfDir: "/etc/cni/multus/net.d"}},
+			wantName: "installed-state",
+		},
+		{
+			name:     "chained CNI",
+			opts:     installOptions{Profile: "minimal", CNI: cniConfig{Enabled: true, Chained: true}},
+			wantName: "installed-state",
+		},
+		{
+			name:    "unknown profile",
+			opts:    installOptions{Profile: "preview"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderIstioOperator(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("renderIstioOperator() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			var operator struct {
+				Kind     string `yaml:"kind"`
+				Metadata struct {
+					Name string `yaml:"name"`
+				} `yaml:"metadata"`
+				Spec struct {
+					Profile  string `yaml:"profile"`
+					Revision string `yaml:"revision"`
+					Values   struct {
+						Global struct {
+							Hub   string `yaml:"hub"`
+							Proxy struct {
+								Resources proxyResources `yaml:"resources"`
+							} `yaml:"proxy"`
+							CAAddress    string `yaml:"caAddress"`
+							MeshID       string `yaml:"meshID"`
+							Network      string `yaml:"network"`
+							MultiCluster struct {
+								ClusterName string `yaml:"clusterName"`
+							} `yaml:"multiCluster"`
+						} `yaml:"global"`
+						Pilot struct {
+							AutoscaleEnabled bool `yaml:"autoscaleEnabled"`
+							AutoscaleMin     int  `yaml:"autoscaleMin"`
+							AutoscaleMax     int  `yaml:"autoscaleMax"`
+							CPU              struct {
+								TargetAverageUtilization int `yaml:"targetAverageUtilization"`
+							} `yaml:"cpu"`
+							Env map[string]interface{} `yaml:"env"`
+						} `yaml:"pilot"`
+						Cni struct {
+							Chained    bool   `yaml:"chained"`
+							CNIBinDir  string `yaml:"cniBinDir"`
+							CNIConfDir string `yaml:"cniConfDir"`
+						} `yaml:"cni"`
+					} `yaml:"values"`
+					Components struct {
+						Pilot struct {
+							K8s struct {
+								ReplicaCount int `yaml:"replicaCount"`
+								HPASpec      struct {
+									MinReplicas int `yaml:"minReplicas"`
+									MaxReplicas int `yaml:"maxReplicas"`
+								} `yaml:"hpaSpec"`
+							} `yaml:"k8s"`
+						} `yaml:"pilot"`
+						Cni struct {
+							Enabled bool `yaml:"enabled"`
+						} `yaml:"cni"`
+					} `yaml:"components"`
+					MeshConfig struct {
+						DefaultConfig struct {
+							Tracing struct {
+								Sampling *float64 `yaml:"sampling"`
+							} `yaml:"tracing"`
+						} `yaml:"defaultConfig"`
+						TrustDomain        string   `yaml:"trustDomain"`
+						TrustDomainAliases []string `yaml:"trustDomainAliases"`
+					} `yaml:"meshConfig"`
+				} `yaml:"spec"`
+			}
+			if err := yaml.Unmarshal(got, &operator); err != nil {
+				t.Fatalf("renderIstioOperator() generated invalid YAML: %v", err)
+			}
+			if operator.Kind != "IstioOperator" || operator.Metadata.Name != tt.wantName {
+				t.Errorf("renderIstioOperator() kind/name = %s/%s, want IstioOperator/%s", operator.Kind, operator.Metadata.Name, tt.wantName)
+			}
+			if operator.Spec.Profile != tt.opts.Profile || operator.Spec.Revision != tt.opts.Revision {
+				t.Errorf("renderIstioOperator() profile/revision = %s/%s, want %s/%s", operator.Spec.Profile, operator.Spec.Revision, tt.opts.Profile, tt.opts.Revision)
+			}
+			if got := operator.Spec.Values.Global.Proxy.Resources; !reflect.DeepEqual(got, tt.opts.ProxyResources) {
+				t.Errorf("renderIstioOperator() proxy resources = %v, want %v", got, tt.opts.ProxyResources)
+			}
+			if got := operator.Spec.Values.Global.Hub; got != tt.opts.Hub {
+				t.Errorf("renderIstioOperator() hub = %s, want %s", got, tt.opts.Hub)
+			}
+			global := operator.Spec.Values.Global
+			if got := (meshTopology{MeshID: global.MeshID, ClusterName: global.MultiCluster.ClusterName, Network: global.Network}); got != tt.opts.Topology {
+				t.Errorf("renderIstioOperator() topology = %+v, want %+v", got, tt.opts.Topology)
+			}
+			if got := operator.Spec.MeshConfig.DefaultConfig.Tracing.Sampling; !reflect.DeepEqual(got, tt.opts.TracingSampling) {
+				t.Errorf("renderIstioOperator() tracing sampling = %v, want %v", got, tt.opts.TracingSampling)
+			}
+			if got := (trustDomain{Domain: operator.Spec.MeshConfig.TrustDomain, Aliases: operator.Spec.MeshConfig.TrustDomainAliases}); !reflect.DeepEqual(got, tt.opts.TrustDomain) {
+				t.Errorf("renderIstioOperator() trust domain = %+v, want %+v", got, tt.opts.TrustDomain)
+			}
+			pilot, k8s := operator.Spec.Values.Pilot, operator.Spec.Components.Pilot.K8s
+			scaling := pilotScaling{
+				Replicas:    k8s.ReplicaCount,
+				Autoscale:   pilot.AutoscaleEnabled,
+				MinReplicas: pilot.AutoscaleMin,
+				MaxReplicas: pilot.AutoscaleMax,
+				TargetCPU:   pilot.CPU.TargetAverageUtilization,
+			}
+			if scaling != tt.opts.Pilot {
+				t.Errorf("renderIstioOperator() istiod scaling = %+v, want %+v", scaling, tt.opts.Pilot)
+			}
+			if k8s.HPASpec.MinReplicas != tt.opts.Pilot.MinReplicas || k8s.HPASpec.MaxReplicas != tt.opts.Pilot.MaxReplicas {
+				t.Errorf("renderIstioOperator() istiod hpaSpec = %+v, want %+v", k8s.HPASpec, tt.opts.Pilot)
+			}
+			if got := global.CAAddress; got != tt.opts.CA.Address {
+				t.Errorf("renderIstioOperator() caAddress = %s, want %s", got, tt.opts.CA.Address)
+			}
+			if got, want := pilot.Env, tt.opts.CA.env(); !reflect.DeepEqual(got, want) {
+				t.Errorf("renderIstioOperator() istiod env = %v, want %v", got, want)
+			}
+			values := operator.Spec.Values.Cni
+			cni := cniConfig{Enabled: operator.Spec.Components.Cni.Enabled, Chained: values.Chained, BinDir: values.CNIBinDir, ConfDir: values.CNIConfDir}
+			if cni != tt.opts.CNI {
+				t.Errorf("renderIstioOperator() CNI = %+v, want %+v", cni, tt.opts.CNI)
+			}
+		})
+	}
+}
+
+func TestSameMinorVersion(t *testing.T) {
+	tests := []struct {
+		a, b    string
+		want    bool
+		wantErr bool
+	}{
+		{a: "1.20.1", b: "1.20.0", want: true},
+		{a: "1.19.3", b: "v1.20.0", want: false},
+		{a: "2.20.0", b: "1.20.0", want: false},
+		{a: "1.20-dev", b: "1.20.0", want: true},
+		{a: "unknown", b: "1.20.0", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			got, err := sameMinorVersion(tt.a, tt.b)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("sameMinorVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("sameMinorVersion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewProxyResources(t *testing.T) {
+	got, err := newProxyResources(map[string]string{
+		config.ProxyCPURequest:    "0.1",
+		config.ProxyMemoryRequest: "128Mi",
+		config.ProxyMemoryLimit:   "",
+	})
+	if err != nil {
+		t.Fatalf("newProxyResources() error = %v", err)
+	}
+	want := proxyResources{"requests": {"cpu": "100m", "memory": "128Mi"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("newProxyResources() = %v, want %v", got, want)
+	}
+	if s := got.String(); s != "requests cpu=100m, requests memory=128Mi" {
+		t.Errorf("proxyResources.String() = %s", s)
+	}
+
+	if got, err := newProxyResources(map[string]string{config.Profile: "default"}); err != nil || got != nil {
+		t.Errorf("newProxyResources() without resources = %v, %v, want nil", got, err)
+	}
+
+	_, err = newProxyResources(map[string]string{config.ProxyCPULimit: "two cores"})
+	if err == nil || errors.GetCode(err) != ErrInvalidResourceQuantityCode {
+		t.Errorf("newProxyResources() of an invalid quantity error = %v, want ErrInvalidResourceQuantity", err)
+	}
+}
+
+func TestOtherRevisions(t *testing.T) {
+	istiod := func(name string, labels map[string]string) runtime.Object {
+		return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "istio-system", Labels: labels}}
+	}
+	tests := []struct {
+		name     string
+		objects  []runtime.Object
+		revision string
+		want     []string
+	}{
+		{
+			name:    "default revision only",
+			objects: []runtime.Object{istiod("istiod", map[string]string{"app": "istiod", "istio.io/rev": "default"})},
+		},
+		{
+			name: "default and canary revisions",
+			objects: []runtime.Object{
+				istiod("istiod", map[string]string{"app": "istiod"}),
+				istiod("istiod-1-20", map[string]string{"app": "istiod", "istio.io/rev": "1-20"}),
+			},
+			want: []string{"1-20"},
+		},
+		{
+			name: "other revisions of a canary",
+			objects: []runtime.Object{
+				istiod("istiod", map[string]string{"app": "istiod", "istio.io/rev": "default"}),
+				istiod("istiod-1-20", map[string]string{"app": "istiod", "istio.io/rev": "1-20"}),
+				istiod("istio-ingressgateway", map[string]string{"app": "istio-ingressgateway"}),
+			},
+			revision: "1-20",
+			want:     []string{""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := otherRevisions(fake.NewSimpleClientset(tt.objects...), tt.revision)
+			if err != nil {
+				t.Fatalf("otherRevisions() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("otherRevisions() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyIstioctlVersion(t *testing.T) {
+	executable := path.Join(t.TempDir(), "istioctl")
+	if err := os.WriteFile(executable, []byte("#!/bin/sh\necho 1.19.3\n"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyIstioctlVersion(executable, "1.19.0"); err != nil {
+		t.Errorf("verifyIstioctlVersion() of the same minor version error = %v", err)
+	}
+	err := verifyIstioctlVersion(executable, "1.20.1")
+	if err == nil || errors.GetCode(err) != ErrIstioctlVersionMismatchCode {
+		t.Errorf("verifyIstioctlVersion() of another minor version error = %v, want ErrIstioctlVersionMismatch", err)
+	}
+}
@@ -0,0 +1,116 @@
+package istio
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Statuses of the install of a control plane already running, at the
+// requested version or at the one it is upgraded or downgraded from
+const (
+	statusAlreadyInstalled = "already installed"
+	statusUpgrading        = "upgrading"
+	statusUpgraded         = "upgraded"
+	statusDowngrading      = "downgrading"
+	statusDowngraded       = "downgraded"
+)
+
+// installedVersions returns the version of the control plane of the revision
+// running on every cluster, keyed by cluster. The clusters the revision isn't
+// running on, or isn't available on, are left out.
+func installedVersions(ctx context.Context, revision string, kubeconfigs []string) (map[string]string, error) {
+	var mx sync.Mutex
+	versions := map[string]string{}
+	err := forEachCluster(kubeconfigs, func(k8sconfig string) error {
+		mclient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return err
+		}
+		version, err := installedRevisionVersion(ctx, mclient.KubeClient, revision)
+		if err != nil || version == "" {
+			return err
+		}
+		mx.Lock()
+		versions[clusterName(k8sconfig)] = version
+		mx.Unlock()
+		return nil
+	}, nil)
+	return versions, err
+}
+
+// installedRevisionVersion returns the version of the available istiod of the
+// revision, the tag of its discovery image, or "" if it isn't installed
+func installedRevisionVersion(ctx context.Context, client kubernetes.Interface, revision string) (string, error) {
+	deployments, err := client.AppsV1().Deployments(istioRootNamespace).List(ctx, metav1.ListOptions{LabelSelector: "app=istiod"})
+	if err != nil {
+		return "", err
+	}
+	for i := range deployments.Items {
+		deployment := &deployments.Items[i]
+		rev := deployment.Labels["istio.io/rev"]
+		if rev == "default" {
+			rev = ""
+		}
+		if rev != revision || !deploymentAvailable(deployment) {
+			continue
+		}
+		return istiodVersion(deployment), nil
+	}
+	return "", nil
+}
+
+// istiodVersion returns the tag of the discovery image of istiod, stripped of
+// the variant of the image, such as 1.20.0 for pilot:1.20.0-distroless
+func istiodVersion(deployment *appsv1.Deployment) string {
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		if container.Name != "discovery" {
+			continue
+		}
+		return imageVersion(container.Image)
+	}
+	return ""
+}
+
+// imageVersion returns the tag of the Istio image stripped of the variant of
+// the image, "" if the image has no tag
+func imageVersion(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	i := strings.LastIndex(image, ":")
+	if i < 0 || strings.Contains(image[i:], "/") {
+		return ""
+	}
+	tag := image[i+1:]
+	for _, variant := range []string{"-distroless", "-debug"} {
+		tag = strings.TrimSuffix(tag, variant)
+	}
+	return tag
+}
+
+// installState compares the versions installed on the clusters with the
+// requested one. The install is done when the version is running on every
+// cluster, otherwise it upgrades from the other version running on any
+// cluster, the first of them in lexical order if more than one is.
+func installState(installed map[string]string, version string, clusters int) (done bool, from string) {
+	version = strings.TrimPrefix(version, "v")
+	var others []string
+	matching := 0
+	for _, v := range installed {
+		if strings.TrimPrefix(v, "v") == version {
+			matching++
+			continue
+		}
+		others = append(others, v)
+	}
+	if len(others) == 0 {
+		return matching == clusters && clusters > 0, ""
+	}
+	sort.Strings(others)
+	return false, others[0]
+}
@@ -0,0 +1,198 @@
+package istio
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	kubeerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// InstalledComponents is the inventory of what is deployed of Istio on a
+// single cluster
+type InstalledComponents struct {
+	Cluster string `json:"cluster"`
+
+	// Components are the components the IstioOperators of istio-system
+	// enable, such as pilot or ingressGateways/istio-ingressgateway, empty
+	// when Istio was installed without istioctl or the operator
+	Components []string `json:"components"`
+
+	Revisions []InstalledRevision `json:"revisions"`
+	Addons    []InstalledAddon    `json:"addons"`
+}
+
+// InstalledRevision is a control plane running in istio-system
+type InstalledRevision struct {
+	// Revision is "default" for the control plane of the default revision
+	Revision  string `json:"revision"`
+	Version   string `json:"version"`
+	Available bool   `json:"available"`
+}
+
+// InstalledAddon is the deployment of an addon, Version being the tag of
+// its first image
+type InstalledAddon struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Version   string `json:"version"`
+	Available bool   `json:"available"`
+}
+
+// String summarizes the inventory for the event summary
+func (c InstalledComponents) String() string {
+	revisions := make([]string, 0, len(c.Revisions))
+	for _, r := range c.Revisions {
+		revisions = append(revisions, fmt.Sprintf("%s (%s)", r.Revision, r.Version))
+	}
+	addons := make([]string, 0, len(c.Addons))
+	for _, a := range c.Addons {
+		addons = append(addons, a.Name)
+	}
+	if len(revisions) == 0 {
+		revisions = []string{"none"}
+	}
+	if len(addons) == 0 {
+		addons = []string{"none"}
+	}
+	return fmt.Sprintf("%d components, revisions %s, addons %s", len(c.Components), strings.Join(revisions, ", "), strings.Join(addons, ", "))
+}
+
+// listInstalledComponents collects the IstioOperator components, the control
+// plane revisions and the addons installed on every cluster, sorted by
+// cluster
+func (istio *Istio) listInstalledComponents(kubeConfigs []string) ([]InstalledComponents, error) {
+	var mx sync.Mutex
+	var inventories []InstalledComponents
+	err := forEachCluster(kubeConfigs, func(k8sconfig string) error {
+		mclient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return err
+		}
+		inventory, err := clusterInstalledComponents(context.TODO(), mclient.KubeClient, mclient.DynamicKubeClient)
+		if err != nil {
+			return err
+		}
+		inventory.Cluster = clusterName(k8sconfig)
+		mx.Lock()
+		inventories = append(inventories, inventory)
+		mx.Unlock()
+		return nil
+	}, nil)
+	sort.Slice(inventories, func(i, j int) bool { return inventories[i].Cluster < inventories[j].Cluster })
+	if err != nil {
+		return inventories, ErrListInstalledComponents(err)
+	}
+	return inventories, nil
+}
+
+func clusterInstalledComponents(ctx context.Context, client kubernetes.Interface, dyn dynamic.Interface) (InstalledComponents, error) {
+	inventory := InstalledComponents{Components: []string{}, Revisions: []InstalledRevision{}, Addons: []InstalledAddon{}}
+
+	// The IstioOperator CRD is only installed by istioctl and the operator
+	operators, err := dyn.Resource(istioOperatorResource).Namespace(istioRootNamespace).List(ctx, metav1.ListOptions{})
+	if err != nil && !kubeerror.IsNotFound(err) {
+		return inventory, err
+	}
+	if err == nil {
+		seen := map[string]bool{}
+		for _, operator := range operators.Items {
+			components, _, _ := unstructured.NestedMap(operator.Object, "spec", "components")
+			for _, name := range enabledComponents(components) {
+				if !seen[name] {
+					seen[name] = true
+					inventory.Components = append(inventory.Components, name)
+				}
+			}
+		}
+		sort.Strings(inventory.Components)
+	}
+
+	istiods, err := client.AppsV1().Deployments(istioRootNamespace).List(ctx, metav1.ListOptions{LabelSelector: "app=istiod"})
+	if err != nil {
+		return inventory, err
+	}
+	for i := range istiods.Items {
+		deployment := &istiods.Items[i]
+		rev := deployment.Labels["istio.io/rev"]
+		if rev == "" {
+			rev = "default"
+		}
+		inventory.Revisions = append(inventory.Revisions, InstalledRevision{
+			Revision:  rev,
+			Version:   istiodVersion(deployment),
+			Available: deploymentAvailable(deployment),
+		})
+	}
+	sort.Slice(inventory.Revisions, func(i, j int) bool { return inventory.Revisions[i].Revision < inventory.Revisions[j].Revision })
+
+	// The addon manifests label their deployments with either app or
+	// app.kubernetes.io/name
+	seen := map[string]bool{}
+	for _, label := range []string{"app", "app.kubernetes.io/name"} {
+		selector := fmt.Sprintf("%s in (%s)", label, strings.Join(addonApps, ","))
+		deployments, err := client.AppsV1().Deployments(metav1.NamespaceAll).List(ctx, metav1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			return inventory, err
+		}
+		for i := range deployments.Items {
+			deployment := &deployments.Items[i]
+			key := deployment.Namespace + "/" + deployment.Name
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			addon := InstalledAddon{
+				Name:      deployment.Labels[label],
+				Namespace: deployment.Namespace,
+				Available: deploymentAvailable(deployment),
+			}
+			if containers := deployment.Spec.Template.Spec.Containers; len(containers) > 0 {
+				addon.Version = imageVersion(containers[0].Image)
+			}
+			inventory.Addons = append(inventory.Addons, addon)
+		}
+	}
+	sort.Slice(inventory.Addons, func(i, j int) bool {
+		if inventory.Addons[i].Name != inventory.Addons[j].Name {
+			return inventory.Addons[i].Name < inventory.Addons[j].Name
+		}
+		return inventory.Addons[i].Namespace < inventory.Addons[j].Namespace
+	})
+	return inventory, nil
+}
+
+// enabledComponents returns the enabled components of the components of an
+// IstioOperator spec, the gateways being listed by name. A component without
+// the enabled field is left to the profile and listed.
+func enabledComponents(components map[string]interface{}) []string {
+	var names []string
+	enabled := func(component map[string]interface{}) bool {
+		e, ok := component["enabled"].(bool)
+		return !ok || e
+	}
+	for name, value := range components {
+		switch value := value.(type) {
+		case map[string]interface{}:
+			if enabled(value) {
+				names = append(names, name)
+			}
+		case []interface{}:
+			for _, gateway := range value {
+				gateway, _ := gateway.(map[string]interface{})
+				if gateway == nil || !enabled(gateway) {
+					continue
+				}
+				names = append(names, fmt.Sprintf("%s/%v", name, gateway["name"]))
+			}
+		}
+	}
+	return names
+}
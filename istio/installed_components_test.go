@@ -0,0 +1,88 @@
+package istio
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestClusterInstalledComponents(t *testing.T) {
+	deployment := func(namespace, name string, labels map[string]string, image string, available bool) *appsv1.Deployment {
+		d := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+		}
+		d.Spec.Template.Spec.Containers = []corev1.Container{{Name: "discovery", Image: image}}
+		status := corev1.ConditionFalse
+		if available {
+			status = corev1.ConditionTrue
+		}
+		d.Status.Conditions = []appsv1.DeploymentCondition{{Type: appsv1.DeploymentAvailable, Status: status}}
+		return d
+	}
+	client := fake.NewSimpleClientset(
+		deployment(istioRootNamespace, "istiod", map[string]string{"app": "istiod"}, "docker.io/istio/pilot:1.20.0-distroless", true),
+		deployment(istioRootNamespace, "istiod-canary", map[string]string{"app": "istiod", "istio.io/rev": "canary"}, "docker.io/istio/pilot:1.21.0", false),
+		deployment(istioRootNamespace, "kiali", map[string]string{"app": "kiali", "app.kubernetes.io/name": "kiali"}, "quay.io/kiali/kiali:v1.76", true),
+		deployment("monitoring", "grafana", map[string]string{"app.kubernetes.io/name": "grafana"}, "grafana/grafana:10.0.0", true),
+		deployment("default", "reviews", map[string]string{"app": "reviews"}, "reviews:1.0", true),
+	)
+	operator := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "install.istio.io/v1alpha1",
+		"kind":       "IstioOperator",
+		"metadata":   map[string]interface{}{"name": "installed-state", "namespace": istioRootNamespace},
+		"spec": map[string]interface{}{"components": map[string]interface{}{
+			"base":  map[string]interface{}{"enabled": true},
+			"pilot": map[string]interface{}{"enabled": true},
+			"cni":   map[string]interface{}{"enabled": false},
+			"ingressGateways": []interface{}{
+				map[string]interface{}{"name": "istio-ingressgateway", "enabled": true},
+				map[string]interface{}{"name": "internal-gateway", "enabled": false},
+			},
+		}},
+	}}
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		istioOperatorResource: "IstioOperatorList",
+	}, operator)
+
+	got, err := clusterInstalledComponents(context.Background(), client, dyn)
+	if err != nil {
+		t.Fatalf("clusterInstalledComponents() error = %v", err)
+	}
+	want := InstalledComponents{
+		Components: []string{"base", "ingressGateways/istio-ingressgateway", "pilot"},
+		Revisions: []InstalledRevision{
+			{Revision: "canary", Version: "1.21.0"},
+			{Revision: "default", Version: "1.20.0", Available: true},
+		},
+		Addons: []InstalledAddon{
+			{Name: "grafana", Namespace: "monitoring", Version: "10.0.0", Available: true},
+			{Name: "kiali", Namespace: istioRootNamespace, Version: "v1.76", Available: true},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("clusterInstalledComponents() = %+v, want %+v", got, want)
+	}
+	if _, err := json.Marshal(got); err != nil {
+		t.Errorf("clusterInstalledComponents() inventory can't be marshalled to JSON: %v", err)
+	}
+
+	got, err = clusterInstalledComponents(context.Background(), fake.NewSimpleClientset(), dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		istioOperatorResource: "IstioOperatorList",
+	}))
+	if err != nil {
+		t.Fatalf("clusterInstalledComponents() without Istio error = %v", err)
+	}
+	if len(got.Components) != 0 || len(got.Revisions) != 0 || len(got.Addons) != 0 {
+		t.Errorf("clusterInstalledComponents() without Istio = %+v, want an empty inventory", got)
+	}
+}
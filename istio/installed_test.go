@@ -0,0 +1,80 @@
+package istio
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestInstalledRevisionVersion(t *testing.T) {
+	deployment := func(name, rev, image string, available bool) *appsv1.Deployment {
+		d := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: istioRootNamespace, Labels: map[string]string{"app": "istiod", "istio.io/rev": rev}},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{
+				{Name: "discovery", Image: image},
+			}}}},
+		}
+		if available {
+			d.Status.Conditions = []appsv1.DeploymentCondition{{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue}}
+		}
+		return d
+	}
+	client := fake.NewSimpleClientset(
+		deployment("istiod", "default", "docker.io/istio/pilot:1.20.0-distroless", true),
+		deployment("istiod-canary", "canary", "registry.internal:5000/istio/pilot:1.21.2", true),
+		deployment("istiod-broken", "broken", "docker.io/istio/pilot:1.21.2", false),
+	)
+	for revision, want := range map[string]string{"": "1.20.0", "canary": "1.21.2", "broken": "", "stable": ""} {
+		got, err := installedRevisionVersion(context.Background(), client, revision)
+		if err != nil {
+			t.Fatalf("installedRevisionVersion(%q) error = %v", revision, err)
+		}
+		if got != want {
+			t.Errorf("installedRevisionVersion(%q) = %q, want %q", revision, got, want)
+		}
+	}
+}
+
+func TestInstallState(t *testing.T) {
+	tests := []struct {
+		name      string
+		installed map[string]string
+		clusters  int
+		wantDone  bool
+		wantFrom  string
+	}{
+		{
+			name:      "installed on every cluster",
+			installed: map[string]string{"east": "1.20.0", "west": "1.20.0"},
+			clusters:  2,
+			wantDone:  true,
+		},
+		{
+			name:      "missing on a cluster",
+			installed: map[string]string{"east": "1.20.0"},
+			clusters:  2,
+		},
+		{
+			name:     "not installed",
+			clusters: 1,
+		},
+		{
+			name:      "upgrade",
+			installed: map[string]string{"east": "1.20.0", "west": "1.19.3"},
+			clusters:  2,
+			wantFrom:  "1.19.3",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			done, from := installState(tt.installed, "v1.20.0", tt.clusters)
+			if done != tt.wantDone || from != tt.wantFrom {
+				t.Errorf("installState() = %v, %q, want %v, %q", done, from, tt.wantDone, tt.wantFrom)
+			}
+		})
+	}
+}
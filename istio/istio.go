@@ -2,8 +2,14 @@ package istio
 
 import (
 	"context"
-	"fmt"
+	"encoding/json"
 	stderrors "errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/layer5io/meshery-adapter-library/adapter"
 	"github.com/layer5io/meshery-adapter-library/common"
@@ -24,6 +30,13 @@ import (
 // Istio represents the istio adapter and embeds adapter.Adapter
 type Istio struct {
 	adapter.Adapter // Type Embedded
+
+	running  runningOperations
+	statuses operationStatuses
+
+	// kubeconfigMx serializes the writes of the kubeconfig shared by the
+	// operations, see CreateKubeconfigs
+	kubeconfigMx sync.Mutex
 }
 
 // New initializes istio handler.
@@ -40,14 +53,18 @@ func New(c meshkitCfg.Handler, l logger.Handler, kc meshkitCfg.Handler, ev *even
 
 // ApplyOperation applies the operation on istio
 func (istio *Istio) ApplyOperation(ctx context.Context, opReq adapter.OperationRequest) error {
-	err := istio.CreateKubeconfigs(opReq.K8sConfigs)
+	operations := make(adapter.Operations)
+	requestedVersion := adapter.Version(opReq.Version)
+	err := istio.Config.GetObject(adapter.OperationsKey, &operations)
 	if err != nil {
 		return err
 	}
-	kubeConfigs := opReq.K8sConfigs
-	operations := make(adapter.Operations)
-	requestedVersion := adapter.Version(opReq.Version)
-	err = istio.Config.GetObject(adapter.OperationsKey, &operations)
+	opts, customBody := parseRequestOptions(opReq.CustomBody)
+	opReq.CustomBody = customBody
+	if err := validateOperationRequest(opReq); err != nil {
+		return err
+	}
+	kubeConfigs, err := istio.CreateKubeconfigs(opReq.K8sConfigs, opts.KubeContexts)
 	if err != nil {
 		return err
 	}
@@ -59,37 +76,331 @@ func (istio *Istio) ApplyOperation(ctx context.Context, opReq adapter.OperationR
 		Component:     internalconfig.ServerConfig["type"],
 		ComponentName: internalconfig.ServerConfig["name"],
 	}
+	ctx, finish := istio.running.start(ctx, opReq.OperationID)
+	istio.statuses.start(e)
+	operationLabel := opReq.OperationName
+	if _, ok := operations[operationLabel]; !ok {
+		operationLabel = unknownOperation
+	}
+	observe := adapterMetrics.start(operationLabel)
+	// The operations stream their outcome with e, hence they failed if its
+	// last event is an error
+	done := func() {
+		finish()
+		istio.statuses.finish(opReq.OperationID)
+		observe(e.EventType == meshes.EventType_ERROR)
+	}
+	ctx = withOperationLogger(ctx, newOperationLogger(istio.Log, opReq))
+	istio.log(ctx).Debug("Operation requested")
 	switch opReq.OperationName {
 	case internalconfig.IstioOperation:
 		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
 			var err error
 			var stat, version string
-			if len(operations[opReq.OperationName].Versions) == 0 {
-				err = ErrFetchIstioVersions
-			} else {
-				version = string(operations[opReq.OperationName].Versions[len(operations[opReq.OperationName].Versions)-1])
-				if utils.Contains[[]adapter.Version, adapter.Version](operations[opReq.OperationName].Versions, requestedVersion) {
-					version = requestedVersion.String()
+			revision := operations[opReq.OperationName].AdditionalProperties[internalconfig.Revision]
+			profile := operations[opReq.OperationName].AdditionalProperties[internalconfig.Profile]
+			if profile == "" {
+				profile = "default"
+			}
+			action := "installing Istio"
+			if opReq.IsDeleteOperation {
+				action = "uninstalling Istio"
+			}
+			results := newClusterResults()
+			timeout, err := operationTimeout(operations[opReq.OperationName].AdditionalProperties)
+			ctx, cancel := withOperationTimeout(ctx, timeout)
+			defer cancel()
+			var upgradeFrom string
+			var proxyResources proxyResources
+			if err == nil {
+				proxyResources, err = newProxyResources(operations[opReq.OperationName].AdditionalProperties)
+			}
+			var metadata extraMetadata
+			if err == nil {
+				metadata, err = newExtraMetadata(operations[opReq.OperationName].AdditionalProperties)
+			}
+			var hub string
+			if err == nil {
+				hub, err = parseImageHub(operations[opReq.OperationName].AdditionalProperties[internalconfig.ImageHub])
+			}
+			var topology meshTopology
+			if err == nil {
+				topology, err = newMeshTopology(operations[opReq.OperationName].AdditionalProperties)
+			}
+			var sampling *float64
+			if err == nil {
+				sampling, err = parseTracingSampling(operations[opReq.OperationName].AdditionalProperties[internalconfig.TracingSampling])
+			}
+			var pilot pilotScaling
+			if err == nil {
+				pilot, err = newPilotScaling(operations[opReq.OperationName].AdditionalProperties)
+			}
+			var ca customCA
+			if err == nil {
+				ca, err = newCustomCA(operations[opReq.OperationName].AdditionalProperties)
+			}
+			var td trustDomain
+			if err == nil {
+				td, err = newTrustDomain(operations[opReq.OperationName].AdditionalProperties)
+			}
+			var cni cniConfig
+			if err == nil {
+				cni, err = newCNIConfig(operations[opReq.OperationName].AdditionalProperties)
+			}
+			if err == nil {
+				err = configureProxy(operations[opReq.OperationName].AdditionalProperties)
+			}
+			if err == nil {
+				version, err = resolveVersion(operations[opReq.OperationName].Versions, requestedVersion)
+			}
+			installOpts := installOptions{
+				Profile:         profile,
+				Revision:        revision,
+				ProxyResources:  proxyResources,
+				TracingSampling: sampling,
+				Pilot:           pilot,
+				CA:              ca,
+				TrustDomain:     td,
+				CNI:             cni,
+				Metadata:        metadata,
+				Hub:             hub,
+				Topology:        topology,
+				NoWait:          operations[opReq.OperationName].AdditionalProperties[internalconfig.Wait] == "false",
+				OnCluster:       results.track(hh.streamClusterProgress(ee, action)),
+				OnRetry:         hh.streamRetryProgress(ee, action),
+				OnPhase:         hh.streamPhaseProgress(ee, action),
+				OnUpgrade: func(from string) {
+					upgradeFrom = from
+				},
+				OnUnsupportedVersion: func(support versionSupport) {
+					hh.StreamWarn(&meshes.EventsResponse{
+						OperationId:   ee.OperationId,
+						Component:     ee.Component,
+						ComponentName: ee.ComponentName,
+						Summary:       fmt.Sprintf("Istio %s is %s", support.Version, support.label()),
+						Details:       support.String(),
+					}, stderrors.New(support.String()))
+				},
+				OnDowngrade: func(from []string) {
+					msg := fmt.Sprintf("Istio is downgraded to %s from %s, the configuration resources of the later version may not be supported by %s", version, strings.Join(from, ", "), version)
+					hh.StreamWarn(&meshes.EventsResponse{
+						OperationId:   ee.OperationId,
+						Component:     ee.Component,
+						ComponentName: ee.ComponentName,
+						Summary:       fmt.Sprintf("Downgrading the Istio service mesh to %s", version),
+						Details:       msg,
+					}, stderrors.New(msg))
+					upgradeFrom = strings.Join(from, ", ")
+				},
+				OnTrustDomainChange: func(from []string) {
+					msg := fmt.Sprintf("The trust domain is changed to %s from %s, the certificates of the running workloads keep their former identities until they are rotated: restart the workloads, or rotate the root CA with the %s operation", td.domain(), strings.Join(from, ", "), internalconfig.IstioRotateCAOperation)
+					hh.StreamWarn(&meshes.EventsResponse{
+						OperationId:   ee.OperationId,
+						Component:     ee.Component,
+						ComponentName: ee.ComponentName,
+						Summary:       fmt.Sprintf("Changing the trust domain of the Istio service mesh to %s", td.domain()),
+						Details:       msg,
+					}, stderrors.New(msg))
+				},
+			}
+			var manifest []byte
+			if !opReq.IsDeleteOperation && operations[opReq.OperationName].AdditionalProperties[internalconfig.DryRun] == "true" {
+				installOpts.OnRender = func(rendered []byte) {
+					manifest = rendered
 				}
-				stat, err = hh.installIstio(opReq.IsDeleteOperation, false, version, opReq.Namespace, "default", kubeConfigs)
+			}
+			if err == nil && opts.OperatorManifest != "" {
+				err = installOpts.useOperatorManifest(opts.OperatorManifest)
+				profile, revision, proxyResources, sampling, pilot, ca, td, cni = installOpts.Profile, installOpts.Revision, installOpts.ProxyResources, installOpts.TracingSampling, installOpts.Pilot, installOpts.CA, installOpts.TrustDomain, installOpts.CNI
+			}
+			if err == nil {
+				stat, err = hh.installIstio(ctx, opReq.IsDeleteOperation, false, version, opReq.Namespace, installOpts, kubeConfigs)
+			}
+			// Purging the CRDs breaks the other revisions, hence only the
+			// uninstall of the default revision purges
+			var purged []string
+			purge := opReq.IsDeleteOperation && revision == "" && operations[opReq.OperationName].AdditionalProperties[internalconfig.Purge] == "true"
+			if err == nil && purge {
+				purged, err = hh.purgeIstio(kubeConfigs)
+			}
+			if revision != "" {
+				version = fmt.Sprintf("%s (revision %s)", version, revision)
 			}
 			if err != nil { //Make sure that this is a meshkit error
 				ee.Summary = fmt.Sprintf("Error while %s Istio service mesh %s", stat, version)
 				ee.Details = err.Error()
+				if len(purged) != 0 {
+					ee.Details = fmt.Sprintf("%s\nPurged before the failure:\n%s", ee.Details, strings.Join(purged, "\n"))
+				}
+				ee.Details = results.details(ee.Details)
 				ee.ErrorCode = errors.GetCode(err)
 				ee.ProbableCause = errors.GetCause(err)
 				ee.SuggestedRemediation = errors.GetRemedy(err)
 				hh.StreamErr(ee, err)
 				return
 			}
+			if stat == statusRendered {
+				ee.Summary = fmt.Sprintf("Istio service mesh %s manifest rendered successfully", version)
+				ee.Details = fmt.Sprintf("Nothing was applied, the install of the %s profile applies:\n%s", profile, manifest)
+				hh.StreamInfo(ee)
+				return
+			}
+			if stat == statusSubmitted {
+				ee.Summary = fmt.Sprintf("Istio service mesh %s install submitted", version)
+				ee.Details = fmt.Sprintf("The Istio service mesh %s is applied using the %s profile without waiting for its rollout, hence it may not be ready yet and the failures of its rollout are not reported. Poll the %s operation for the progress of the clusters, or check the deployments of %s.", version, profile, internalconfig.OperationStatusOperation, istioRootNamespace)
+				ee.Details = results.details(ee.Details)
+				hh.StreamInfo(ee)
+				return
+			}
+			if stat == statusAlreadyInstalled {
+				ee.Summary = fmt.Sprintf("Istio service mesh %s already installed", version)
+				ee.Details = fmt.Sprintf("The Istio service mesh %s is already running on every cluster, nothing was changed.", version)
+				hh.StreamInfo(ee)
+				return
+			}
 			ee.Summary = fmt.Sprintf("Istio service mesh %s %s successfully", version, stat)
-			ee.Details = fmt.Sprintf("The Istio service mesh %s is now %s.", version, stat)
+			ee.Details = fmt.Sprintf("The Istio service mesh %s is now %s using the %s profile.", version, stat, profile)
+			if stat == statusUpgraded || stat == statusDowngraded {
+				ee.Details = fmt.Sprintf("The Istio service mesh is now %s from %s to %s using the %s profile.", stat, upgradeFrom, version, profile)
+			}
+			if !opReq.IsDeleteOperation && proxyResources != nil {
+				ee.Details = fmt.Sprintf("%s The proxies use %s.", ee.Details, proxyResources)
+			}
+			if !opReq.IsDeleteOperation && sampling != nil {
+				ee.Details = fmt.Sprintf("%s The proxies trace %s of the requests.", ee.Details, formatTracingSampling(*sampling))
+			}
+			if !opReq.IsDeleteOperation && !pilot.empty() {
+				ee.Details = fmt.Sprintf("%s istiod is %s.", ee.Details, pilot)
+			}
+			if !opReq.IsDeleteOperation && !ca.empty() {
+				ee.Details = fmt.Sprintf("%s The workload certificates are issued by %s.", ee.Details, ca)
+			}
+			if !opReq.IsDeleteOperation && !td.empty() {
+				ee.Details = fmt.Sprintf("%s The workload identities use %s.", ee.Details, td)
+			}
+			if !opReq.IsDeleteOperation && cni.Enabled {
+				ee.Details = fmt.Sprintf("%s The pods are set up by %s.", ee.Details, cni)
+				// The node agent is a DaemonSet, not waited for like the
+				// deployments, hence its readiness is only reported
+				statuses, err := cniStatus(ctx, kubeConfigs)
+				if err != nil {
+					hh.log(ctx).Info(fmt.Sprintf("Unable to read the status of the Istio CNI node agent: %v", err))
+				}
+				if len(statuses) > 0 {
+					ee.Details = fmt.Sprintf("%s The CNI node agent is %s.", ee.Details, strings.Join(statuses, ", "))
+				}
+			}
+			if !opReq.IsDeleteOperation && hub != "" && opts.OperatorManifest == "" {
+				ee.Details = fmt.Sprintf("%s The images are pulled from %s.", ee.Details, hub)
+			}
+			if !opReq.IsDeleteOperation && !topology.empty() && opts.OperatorManifest == "" {
+				ee.Details = fmt.Sprintf("%s The control plane is the %s.", ee.Details, topology)
+			}
+			if !opReq.IsDeleteOperation {
+				ee.Details = metadata.details(ee.Details)
+			}
+			if purge {
+				ee.Details = fmt.Sprintf("The Istio service mesh %s is now %s, nothing else was left to purge.", version, stat)
+				if len(purged) != 0 {
+					ee.Details = fmt.Sprintf("The Istio service mesh %s is now %s, purged:\n%s", version, stat, strings.Join(purged, "\n"))
+				}
+			}
+			ee.Details = results.details(ee.Details)
+			hh.StreamInfo(ee)
+		}(istio, e)
+	case internalconfig.IstioBaseOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			var stat string
+			version, err := resolveVersion(operations[opReq.OperationName].Versions, requestedVersion)
+			if err == nil {
+				stat, err = hh.installBase(ctx, opReq.IsDeleteOperation, version, kubeConfigs)
+			}
+			if err != nil {
+				ee.Summary = fmt.Sprintf("Error while %s the Istio base %s", stat, version)
+				ee.Details = err.Error()
+				ee.ErrorCode = errors.GetCode(err)
+				ee.ProbableCause = errors.GetCause(err)
+				ee.SuggestedRemediation = errors.GetRemedy(err)
+				hh.StreamErr(ee, err)
+				return
+			}
+			ee.Summary = fmt.Sprintf("Istio base %s %s successfully", version, stat)
+			ee.Details = fmt.Sprintf("The Istio %s CRDs and cluster roles are now %s, istiod can be installed by the Istio operation.", version, stat)
+			if opReq.IsDeleteOperation {
+				ee.Details = fmt.Sprintf("The Istio %s CRDs and cluster roles are now %s, along with all the Istio resources.", version, stat)
+			}
+			hh.StreamInfo(ee)
+		}(istio, e)
+	case internalconfig.IstioRollbackOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			profile := operations[opReq.OperationName].AdditionalProperties[internalconfig.Profile]
+			if profile == "" {
+				profile = "default"
+			}
+			results := newClusterResults()
+			timeout, err := operationTimeout(operations[opReq.OperationName].AdditionalProperties)
+			ctx, cancel := withOperationTimeout(ctx, timeout)
+			defer cancel()
+			var version string
+			if err == nil {
+				version, err = resolveVersion(operations[opReq.OperationName].Versions, requestedVersion)
+			}
+			var result RollbackResult
+			if err == nil {
+				result, err = hh.rollbackIstio(ctx, version, opReq.Namespace, installOptions{
+					Profile:   profile,
+					OnCluster: results.track(hh.streamClusterProgress(ee, "rolling back Istio")),
+					OnPhase:   hh.streamPhaseProgress(ee, "rolling back Istio"),
+				}, kubeConfigs)
+			}
+			if err != nil {
+				ee.Summary = fmt.Sprintf("Error while rolling back Istio service mesh to %s", version)
+				ee.Details = results.details(err.Error())
+				ee.ErrorCode = errors.GetCode(err)
+				ee.ProbableCause = errors.GetCause(err)
+				ee.SuggestedRemediation = errors.GetRemedy(err)
+				hh.StreamErr(ee, err)
+				return
+			}
+			from := make([]string, 0, len(result.From))
+			for cluster, v := range result.From {
+				from = append(from, fmt.Sprintf("%s on %s", v, cluster))
+			}
+			sort.Strings(from)
+			if len(result.Skewed) > 0 {
+				hh.StreamWarn(&meshes.EventsResponse{
+					OperationId:   ee.OperationId,
+					Component:     ee.Component,
+					ComponentName: ee.ComponentName,
+					Summary:       fmt.Sprintf("%d proxies run another minor version than the control plane %s", len(result.Skewed), version),
+					Details:       fmt.Sprintf("Restart the workloads for their proxies to run %s:\n%s", version, strings.Join(result.Skewed, "\n")),
+				}, stderrors.New("proxy and control plane version skew"))
+			}
+			ee.Summary = fmt.Sprintf("Istio service mesh rolled back to %s successfully", version)
+			ee.Details = fmt.Sprintf("The Istio control plane is now rolled back from %s to %s using the %s profile, the configuration resources were kept.", strings.Join(from, ", "), version, profile)
+			if len(result.Skewed) > 0 {
+				ee.Details = fmt.Sprintf("%s %d proxies still run another minor version until their workloads are restarted.", ee.Details, len(result.Skewed))
+			}
 			hh.StreamInfo(ee)
 		}(istio, e)
 	case common.BookInfoOperation, common.HTTPBinOperation, common.ImageHubOperation, common.EmojiVotoOperation:
 		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
 			appName := operations[opReq.OperationName].AdditionalProperties[common.ServiceName]
-			stat, err := hh.installSampleApp(opReq.Namespace, opReq.IsDeleteOperation, operations[opReq.OperationName].Templates, kubeConfigs)
+			readyTimeout, _ := time.ParseDuration(operations[opReq.OperationName].AdditionalProperties[internalconfig.ReadinessTimeout])
+			stat := status.Installing
+			timeout, err := operationTimeout(operations[opReq.OperationName].AdditionalProperties)
+			if timeout > 0 {
+				readyTimeout = timeout
+			}
+			ctx, cancel := withOperationTimeout(ctx, timeout)
+			defer cancel()
+			if err == nil {
+				stat, err = hh.installSampleApp(ctx, opReq.Namespace, opReq.IsDeleteOperation, operations[opReq.OperationName].Templates, readyTimeout, kubeConfigs)
+			}
 			if err != nil {
 				ee.Summary = fmt.Sprintf("Error while %s Istio service mesh", stat)
 				ee.Details = err.Error()
@@ -103,19 +414,74 @@ func (istio *Istio) ApplyOperation(ctx context.Context, opReq adapter.OperationR
 			ee.Details = fmt.Sprintf("The %s application is now %s.", appName, stat)
 			hh.StreamInfo(ee)
 		}(istio, e)
+	case internalconfig.GatewayAPIBookInfoOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			props := operations[opReq.OperationName].AdditionalProperties
+			appName := props[common.ServiceName]
+			readyTimeout, _ := time.ParseDuration(props[internalconfig.ReadinessTimeout])
+			stat := status.Installing
+			timeout, err := operationTimeout(props)
+			if timeout > 0 {
+				readyTimeout = timeout
+			}
+			ctx, cancel := withOperationTimeout(ctx, timeout)
+			defer cancel()
+			if err == nil {
+				stat, err = hh.installGatewayAPISampleApp(ctx, opReq.Namespace, opReq.IsDeleteOperation, operations[opReq.OperationName].Templates, adapter.Template(props[internalconfig.RoutingTemplate]), readyTimeout, kubeConfigs)
+			}
+			if err != nil {
+				ee.Summary = fmt.Sprintf("Error while %s %s application", stat, appName)
+				ee.Details = err.Error()
+				ee.ErrorCode = errors.GetCode(err)
+				ee.ProbableCause = errors.GetCause(err)
+				ee.SuggestedRemediation = errors.GetRemedy(err)
+				hh.StreamErr(ee, err)
+				return
+			}
+			ee.Summary = fmt.Sprintf("%s application %s successfully", appName, stat)
+			ee.Details = fmt.Sprintf("The %s application is now %s, routed through the Gateway API (%s CRDs found).", appName, stat, gatewayAPIGroupVersion)
+			if opReq.IsDeleteOperation {
+				ee.Summary = fmt.Sprintf("%s application %s successfully", appName, status.Removed)
+				ee.Details = fmt.Sprintf("The %s application and its Gateway API routes are removed.", appName)
+			}
+			hh.StreamInfo(ee)
+		}(istio, e)
 	case common.SmiConformanceOperation:
 		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
 			name := operations[opReq.OperationName].Description
-			_, err := hh.RunSMITest(adapter.SMITestOptions{
-				Ctx:         context.TODO(),
-				OperationID: ee.OperationId,
-				Labels: map[string]string{
-					"istio-injection": "enabled",
-				},
-				Namespace:   "meshery",
-				Manifest:    string(operations[opReq.OperationName].Templates[0]),
-				Annotations: make(map[string]string),
-			})
+			testVersion := operations[opReq.OperationName].AdditionalProperties[internalconfig.SMITestVersion]
+			var report *SMIReport
+			var resp adapter.Response
+			timeout, err := operationTimeout(operations[opReq.OperationName].AdditionalProperties)
+			if err == nil {
+				report, resp, err = hh.runSMITest(ctx, ee.OperationId, string(operations[opReq.OperationName].Templates[0]), testVersion, timeout, kubeConfigs)
+			}
+			// The report is written even if the tests failed, a failure to
+			// write it only failing the operation when they passed
+			var reportFile string
+			if dir := strings.TrimSpace(operations[opReq.OperationName].AdditionalProperties[internalconfig.SMIResultsDir]); report != nil && dir != "" {
+				var werr error
+				reportFile, werr = writeSMIResults(dir, report, kubeConfigs)
+				if werr != nil && err == nil {
+					err = werr
+				} else if werr != nil {
+					hh.StreamWarn(&meshes.EventsResponse{
+						OperationId:   ee.OperationId,
+						Component:     ee.Component,
+						ComponentName: ee.ComponentName,
+						Summary:       "Error while writing the SMI conformance report",
+						Details:       werr.Error(),
+					}, werr)
+				}
+			}
+			if report != nil {
+				hh.streamSMIReport(ee, report)
+			}
+			if operations[opReq.OperationName].AdditionalProperties[internalconfig.ResultFormat] == JUnitFormat {
+				hh.streamJUnit(ee, name, smiTestResults(resp))
+			}
 			if err != nil {
 				ee.Summary = fmt.Sprintf("Error while %s %s test", status.Running, name)
 				ee.Details = err.Error()
@@ -126,15 +492,73 @@ func (istio *Istio) ApplyOperation(ctx context.Context, opReq adapter.OperationR
 				return
 			}
 			ee.Summary = fmt.Sprintf("%s test %s successfully", name, status.Completed)
+			ee.Details = report.String()
+			if reportFile != "" {
+				ee.Details = fmt.Sprintf("%s, the report is written to %s", ee.Details, reportFile)
+			}
+			hh.StreamInfo(ee)
+		}(istio, e)
+	case internalconfig.DenyAllPolicyOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			stat := status.Deploying
+			results := newClusterResults()
+			exceptions, err := newDenyAllExceptions(opReq.Namespace, operations[opReq.OperationName].AdditionalProperties, opReq.IsDeleteOperation)
+			var metadata extraMetadata
+			if err == nil {
+				metadata, err = newExtraMetadata(operations[opReq.OperationName].AdditionalProperties)
+			}
+			// The exceptions are allowed before all the requests are
+			// denied, and denied again only once the deny-all is removed
+			applyExceptions := func() {
+				if err == nil && exceptions != nil {
+					stat, err = hh.applyPolicy(ctx, opReq.Namespace, opReq.IsDeleteOperation, operations[internalconfig.AuthorizationPolicyOperation].Templates, exceptions, metadata, nil, kubeConfigs)
+				}
+			}
+			if !opReq.IsDeleteOperation {
+				applyExceptions()
+			}
+			if err == nil {
+				stat, err = hh.applyPolicy(ctx, opReq.Namespace, opReq.IsDeleteOperation, operations[opReq.OperationName].Templates, nil, metadata, results.track(nil), kubeConfigs)
+			}
+			if opReq.IsDeleteOperation {
+				applyExceptions()
+			}
+			if err != nil {
+				ee.Summary = fmt.Sprintf("Error while %s policy", stat)
+				ee.Details = results.details(err.Error())
+				ee.ErrorCode = errors.GetCode(err)
+				ee.ProbableCause = errors.GetCause(err)
+				ee.SuggestedRemediation = errors.GetRemedy(err)
+				hh.StreamErr(ee, err)
+				return
+			}
+			ee.Summary = fmt.Sprintf("Policy %s successfully", status.Deployed)
 			ee.Details = ""
+			if opReq.IsDeleteOperation {
+				ee.Summary = fmt.Sprintf("Policy %s successfully", status.Removed)
+				ee.Details = fmt.Sprintf("The deny-all policy and its exceptions are removed from %s namespace", opReq.Namespace)
+			} else {
+				if exceptions != nil {
+					ee.Details = fmt.Sprintf("The requests are denied in %s namespace except the ones allowed by AuthorizationPolicy %s", opReq.Namespace, exceptions.Name)
+				}
+				ee.Details = metadata.details(ee.Details)
+			}
+			ee.Details = results.details(ee.Details)
 			hh.StreamInfo(ee)
 		}(istio, e)
-	case internalconfig.DenyAllPolicyOperation, internalconfig.StrictMTLSPolicyOperation, internalconfig.MutualMTLSPolicyOperation, internalconfig.DisableMTLSPolicyOperation:
+	case internalconfig.StrictMTLSPolicyOperation, internalconfig.MutualMTLSPolicyOperation, internalconfig.DisableMTLSPolicyOperation:
 		go func(hh *Istio, ee *meshes.EventsResponse) {
-			stat, err := hh.applyPolicy(opReq.Namespace, opReq.IsDeleteOperation, operations[opReq.OperationName].Templates, kubeConfigs)
+			defer done()
+			stat := status.Deploying
+			results := newClusterResults()
+			metadata, err := newExtraMetadata(operations[opReq.OperationName].AdditionalProperties)
+			if err == nil {
+				stat, err = hh.applyPolicy(ctx, opReq.Namespace, opReq.IsDeleteOperation, operations[opReq.OperationName].Templates, nil, metadata, results.track(nil), kubeConfigs)
+			}
 			if err != nil {
 				ee.Summary = fmt.Sprintf("Error while %s policy", stat)
-				ee.Details = err.Error()
+				ee.Details = results.details(err.Error())
 				ee.ErrorCode = errors.GetCode(err)
 				ee.ProbableCause = errors.GetCause(err)
 				ee.SuggestedRemediation = errors.GetRemedy(err)
@@ -143,13 +567,210 @@ func (istio *Istio) ApplyOperation(ctx context.Context, opReq adapter.OperationR
 			}
 			ee.Summary = fmt.Sprintf("Policy %s successfully", status.Deployed)
 			ee.Details = ""
+			if !opReq.IsDeleteOperation {
+				ee.Details = metadata.details(ee.Details)
+			}
+			ee.Details = results.details(ee.Details)
+			hh.StreamInfo(ee)
+		}(istio, e)
+	case internalconfig.NamespaceMTLSPolicyOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			stat := status.Deploying
+			results := newClusterResults()
+			values, err := newNamespaceMTLSValues(opReq.Namespace, operations[opReq.OperationName].AdditionalProperties, opReq.IsDeleteOperation)
+			var metadata extraMetadata
+			if err == nil {
+				metadata, err = newExtraMetadata(operations[opReq.OperationName].AdditionalProperties)
+			}
+			if err == nil {
+				stat, err = hh.applyPolicy(ctx, opReq.Namespace, opReq.IsDeleteOperation, operations[opReq.OperationName].Templates, values, metadata, results.track(nil), kubeConfigs)
+			}
+			if err != nil {
+				ee.Summary = fmt.Sprintf("Error while %s mTLS policy in %s namespace", stat, opReq.Namespace)
+				ee.Details = results.details(err.Error())
+				ee.ErrorCode = errors.GetCode(err)
+				ee.ProbableCause = errors.GetCause(err)
+				ee.SuggestedRemediation = errors.GetRemedy(err)
+				hh.StreamErr(ee, err)
+				return
+			}
+			ee.Summary = fmt.Sprintf("Policy %s successfully", status.Deployed)
+			ee.Details = fmt.Sprintf("PeerAuthentication with %s mTLS mode %s in %s namespace", values, stat, opReq.Namespace)
+			if opReq.IsDeleteOperation {
+				ee.Summary = fmt.Sprintf("Policy %s successfully", status.Removed)
+				ee.Details = fmt.Sprintf("PeerAuthentication removed from %s namespace", opReq.Namespace)
+			} else {
+				ee.Details = metadata.details(ee.Details)
+			}
+			ee.Details = results.details(ee.Details)
+			hh.StreamInfo(ee)
+		}(istio, e)
+	case internalconfig.AuthorizationPolicyOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			stat := status.Deploying
+			results := newClusterResults()
+			values, err := newAuthorizationPolicyValues(opReq.Namespace, operations[opReq.OperationName].AdditionalProperties, opReq.IsDeleteOperation)
+			var metadata extraMetadata
+			if err == nil {
+				metadata, err = newExtraMetadata(operations[opReq.OperationName].AdditionalProperties)
+			}
+			if err == nil {
+				stat, err = hh.applyPolicy(ctx, opReq.Namespace, opReq.IsDeleteOperation, operations[opReq.OperationName].Templates, values, metadata, results.track(nil), kubeConfigs)
+			}
+			if err != nil {
+				ee.Summary = fmt.Sprintf("Error while %s authorization policy in %s namespace", stat, opReq.Namespace)
+				ee.Details = results.details(err.Error())
+				ee.ErrorCode = errors.GetCode(err)
+				ee.ProbableCause = errors.GetCause(err)
+				ee.SuggestedRemediation = errors.GetRemedy(err)
+				hh.StreamErr(ee, err)
+				return
+			}
+			ee.Summary = fmt.Sprintf("Policy %s successfully", status.Deployed)
+			ee.Details = fmt.Sprintf("AuthorizationPolicy %s with %s action %s in %s namespace", values.Name, values.Action, stat, opReq.Namespace)
+			if opReq.IsDeleteOperation {
+				ee.Summary = fmt.Sprintf("Policy %s successfully", status.Removed)
+				ee.Details = fmt.Sprintf("AuthorizationPolicy %s removed from %s namespace", values.Name, opReq.Namespace)
+			} else {
+				ee.Details = metadata.details(ee.Details)
+			}
+			ee.Details = results.details(ee.Details)
+			hh.StreamInfo(ee)
+		}(istio, e)
+	case internalconfig.RequestAuthenticationOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			stat := status.Deploying
+			results := newClusterResults()
+			values, err := newRequestAuthenticationValues(opReq.Namespace, operations[opReq.OperationName].AdditionalProperties, opReq.IsDeleteOperation)
+			var metadata extraMetadata
+			if err == nil {
+				metadata, err = newExtraMetadata(operations[opReq.OperationName].AdditionalProperties)
+			}
+			if err == nil {
+				stat, err = hh.applyPolicy(ctx, opReq.Namespace, opReq.IsDeleteOperation, operations[opReq.OperationName].Templates, values, metadata, results.track(nil), kubeConfigs)
+			}
+			if err != nil {
+				ee.Summary = fmt.Sprintf("Error while %s request authentication policy in %s namespace", stat, opReq.Namespace)
+				ee.Details = results.details(err.Error())
+				ee.ErrorCode = errors.GetCode(err)
+				ee.ProbableCause = errors.GetCause(err)
+				ee.SuggestedRemediation = errors.GetRemedy(err)
+				hh.StreamErr(ee, err)
+				return
+			}
+			ee.Summary = fmt.Sprintf("Policy %s successfully", status.Deployed)
+			ee.Details = fmt.Sprintf("RequestAuthentication %s validating the JWTs of %s %s in %s namespace", values.Name, values.Issuer, stat, opReq.Namespace)
+			if opReq.IsDeleteOperation {
+				ee.Summary = fmt.Sprintf("Policy %s successfully", status.Removed)
+				ee.Details = fmt.Sprintf("RequestAuthentication %s removed from %s namespace", values.Name, opReq.Namespace)
+			} else {
+				ee.Details = metadata.details(ee.Details)
+			}
+			ee.Details = results.details(ee.Details)
+			hh.StreamInfo(ee)
+		}(istio, e)
+	case internalconfig.FaultInjectionOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			stat := status.Deploying
+			results := newClusterResults()
+			values, err := newFaultValues(opReq.Namespace, operations[opReq.OperationName].AdditionalProperties, opReq.IsDeleteOperation)
+			var metadata extraMetadata
+			if err == nil {
+				metadata, err = newExtraMetadata(operations[opReq.OperationName].AdditionalProperties)
+			}
+			if err == nil {
+				stat, err = hh.applyPolicy(ctx, opReq.Namespace, opReq.IsDeleteOperation, operations[opReq.OperationName].Templates, values, metadata, results.track(nil), kubeConfigs)
+			}
+			if err != nil {
+				ee.Summary = fmt.Sprintf("Error while %s fault injection in %s namespace", stat, opReq.Namespace)
+				ee.Details = results.details(err.Error())
+				ee.ErrorCode = errors.GetCode(err)
+				ee.ProbableCause = errors.GetCause(err)
+				ee.SuggestedRemediation = errors.GetRemedy(err)
+				hh.StreamErr(ee, err)
+				return
+			}
+			var faults []string
+			if values.FixedDelay != "" {
+				faults = append(faults, fmt.Sprintf("%v%% of the requests delayed by %s", values.DelayPercent, values.FixedDelay))
+			}
+			if values.HTTPStatus != 0 {
+				faults = append(faults, fmt.Sprintf("%v%% of the requests aborted with %d", values.AbortPercent, values.HTTPStatus))
+			}
+			ee.Summary = fmt.Sprintf("Faults injected into %s", values.Service)
+			ee.Details = fmt.Sprintf("VirtualService %s %s in %s namespace: %s.", values.Name, stat, opReq.Namespace, strings.Join(faults, ", "))
+			if opReq.IsDeleteOperation {
+				ee.Summary = fmt.Sprintf("Faults of %s removed", values.Service)
+				ee.Details = fmt.Sprintf("VirtualService %s removed from %s namespace.", values.Name, opReq.Namespace)
+			} else {
+				ee.Details = metadata.details(ee.Details)
+			}
+			ee.Details = results.details(ee.Details)
+			hh.StreamInfo(ee)
+		}(istio, e)
+	case internalconfig.TrafficMirrorOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			stat := status.Deploying
+			values, err := newMirrorValues(opReq.Namespace, operations[opReq.OperationName].AdditionalProperties, opReq.IsDeleteOperation)
+			if err == nil {
+				stat, err = hh.mirrorTraffic(ctx, opReq.IsDeleteOperation, values, operations[opReq.OperationName].Templates, kubeConfigs)
+			}
+			if err != nil {
+				ee.Summary = fmt.Sprintf("Error while %s the traffic mirroring in %s namespace", stat, opReq.Namespace)
+				ee.Details = err.Error()
+				ee.ErrorCode = errors.GetCode(err)
+				ee.ProbableCause = errors.GetCause(err)
+				ee.SuggestedRemediation = errors.GetRemedy(err)
+				hh.StreamErr(ee, err)
+				return
+			}
+			ee.Summary = fmt.Sprintf("Traffic of %s mirrored to %s", values.Service, values.Target)
+			ee.Details = fmt.Sprintf("%s keeps serving all the requests, %v%% of them are mirrored to %s whose responses are discarded.", values.Service, values.Percentage, values.Target)
+			if opReq.IsDeleteOperation {
+				ee.Summary = fmt.Sprintf("Traffic of %s no longer mirrored", values.Service)
+				ee.Details = fmt.Sprintf("The VirtualService %s is removed, %s is back to its original routing.", values.Name, values.Service)
+			}
+			hh.StreamInfo(ee)
+		}(istio, e)
+	case internalconfig.ExposeServiceOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			stat := status.Deploying
+			values, err := newExposeValues(opReq.Namespace, operations[opReq.OperationName].AdditionalProperties, opReq.IsDeleteOperation)
+			if err == nil {
+				stat, err = hh.exposeService(ctx, opReq.IsDeleteOperation, values, operations[opReq.OperationName].Templates, kubeConfigs)
+			}
+			if err != nil {
+				ee.Summary = fmt.Sprintf("Error while %s the exposed service in %s namespace", stat, opReq.Namespace)
+				ee.Details = err.Error()
+				ee.ErrorCode = errors.GetCode(err)
+				ee.ProbableCause = errors.GetCause(err)
+				ee.SuggestedRemediation = errors.GetRemedy(err)
+				hh.StreamErr(ee, err)
+				return
+			}
+			ee.Summary = fmt.Sprintf("Service %s exposed successfully", values.Service)
+			ee.Details = fmt.Sprintf("The ingress gateway routes the requests for %s on port %d to the %s service of %s namespace.", values.Host, values.Port, values.Service, opReq.Namespace)
+			if opReq.IsDeleteOperation {
+				ee.Summary = fmt.Sprintf("Service %s no longer exposed", values.Service)
+				ee.Details = fmt.Sprintf("The Gateway and VirtualService %s are removed from %s namespace.", values.Name, opReq.Namespace)
+			}
 			hh.StreamInfo(ee)
 		}(istio, e)
 	case common.CustomOperation:
 		go func(hh *Istio, ee *meshes.EventsResponse) {
-			stat, err := hh.applyCustomOperation(opReq.Namespace, opReq.CustomBody, opReq.IsDeleteOperation, kubeConfigs)
+			defer done()
+			stat, err := hh.applyCustomOperation(ctx, opReq.Namespace, opReq.CustomBody, opReq.IsDeleteOperation, opts.DryRun, kubeConfigs)
 			if err != nil {
 				ee.Summary = fmt.Sprintf("Error while %s custom operation", stat)
+				if opts.DryRun {
+					ee.Summary = "Custom operation manifest failed validation"
+				}
 				ee.Details = err.Error()
 				ee.ErrorCode = errors.GetCode(err)
 				ee.ProbableCause = errors.GetCause(err)
@@ -159,11 +780,21 @@ func (istio *Istio) ApplyOperation(ctx context.Context, opReq adapter.OperationR
 			}
 			ee.Summary = fmt.Sprintf("Manifest %s successfully", status.Deployed)
 			ee.Details = ""
+			if opts.DryRun {
+				ee.Summary = "Manifest validated successfully"
+				ee.Details = "The dry run found no issues, the cluster was not changed."
+			}
 			hh.StreamInfo(ee)
 		}(istio, e)
 	case internalconfig.LabelNamespace:
 		go func(hh *Istio, ee *meshes.EventsResponse) {
-			err := hh.LoadNamespaceToMesh(opReq.Namespace, opReq.IsDeleteOperation, kubeConfigs)
+			defer done()
+			revision := operations[opReq.OperationName].AdditionalProperties[internalconfig.Revision]
+			err := hh.LoadNamespaceToMesh(opReq.Namespace, opReq.IsDeleteOperation, revision, kubeConfigs)
+			label := "ISTIO-INJECTION"
+			if revision != "" {
+				label = fmt.Sprintf("istio.io/rev=%s", revision)
+			}
 			operation := "enabled"
 			if opReq.IsDeleteOperation {
 				operation = "removed"
@@ -178,41 +809,134 @@ func (istio *Istio) ApplyOperation(ctx context.Context, opReq adapter.OperationR
 				return
 			}
 			ee.Summary = fmt.Sprintf("Label updated on %s namespace", opReq.Namespace)
-			ee.Details = fmt.Sprintf("ISTIO-INJECTION label %s on %s namespace", operation, opReq.Namespace)
+			ee.Details = fmt.Sprintf("%s label %s on %s namespace", label, operation, opReq.Namespace)
 			hh.StreamInfo(ee)
 		}(istio, e)
-	case internalconfig.PrometheusAddon, internalconfig.GrafanaAddon, internalconfig.KialiAddon, internalconfig.JaegerAddon, internalconfig.ZipkinAddon:
+	case internalconfig.PrometheusAddon, internalconfig.GrafanaAddon, internalconfig.KialiAddon, internalconfig.JaegerAddon, internalconfig.ZipkinAddon, internalconfig.LokiAddon, internalconfig.TempoAddon:
 		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
 			svcname := operations[opReq.OperationName].AdditionalProperties[common.ServiceName]
 			patches := make([]string, 0)
 			patches = append(patches, operations[opReq.OperationName].AdditionalProperties[internalconfig.ServicePatchFile])
 
-			_, err := hh.installAddon(opReq.Namespace, opReq.IsDeleteOperation, svcname, patches, operations[opReq.OperationName].Templates, kubeConfigs)
 			operation := "install"
 			if opReq.IsDeleteOperation {
 				operation = "uninstall"
 			}
+			var endpoints, warnings []string
+			var stat string
+			results := newClusterResults()
+			timeout, err := operationTimeout(operations[opReq.OperationName].AdditionalProperties)
+			ctx, cancel := withOperationTimeout(ctx, timeout)
+			defer cancel()
+			var templates []adapter.Template
+			var addonVersion string
+			var metadata extraMetadata
+			if err == nil {
+				metadata, err = newExtraMetadata(operations[opReq.OperationName].AdditionalProperties)
+			}
+			var hub string
+			if err == nil {
+				hub, err = parseImageHub(operations[opReq.OperationName].AdditionalProperties[internalconfig.ImageHub])
+			}
+			if err == nil {
+				templates, addonVersion, err = pinAddonVersion(operations[opReq.OperationName].Templates, operations[opReq.OperationName].Versions, operations[opReq.OperationName].AdditionalProperties[internalconfig.AddonVersion])
+			}
+			if err == nil {
+				requested := addonVersion
+				templates, addonVersion, err = locateAddonManifests(templates, operations[opReq.OperationName].Versions, addonVersion)
+				if err == nil && addonVersion != requested {
+					msg := fmt.Sprintf("The %s manifests of Istio %s are missing, the ones of Istio %s are used instead", opReq.OperationName, requested, addonVersion)
+					hh.StreamWarn(&meshes.EventsResponse{
+						OperationId:   ee.OperationId,
+						Component:     ee.Component,
+						ComponentName: ee.ComponentName,
+						Summary:       fmt.Sprintf("%s manifests of Istio %s substituted", opReq.OperationName, addonVersion),
+						Details:       msg,
+					}, stderrors.New(msg))
+				}
+			}
+			// The tracing provider is reverted before the addon is removed, so
+			// that the proxies stop reporting to it
+			if err == nil && opReq.OperationName == internalconfig.TempoAddon && opReq.IsDeleteOperation {
+				err = hh.patchTracingProvider(true, tempoTracingProvider, kubeConfigs)
+			}
+			if err == nil {
+				progress := results.track(hh.streamClusterProgress(ee, fmt.Sprintf("%sing %s", operation, opReq.OperationName)))
+				wait := operations[opReq.OperationName].AdditionalProperties[internalconfig.Wait] != "false"
+				stat, endpoints, warnings, err = hh.installAddon(ctx, opReq.Namespace, opReq.IsDeleteOperation, svcname, patches, templates, metadata, hub, wait, progress, kubeConfigs)
+			}
+			if err == nil && opReq.OperationName == internalconfig.TempoAddon && !opReq.IsDeleteOperation {
+				err = hh.patchTracingProvider(false, tempoTracingProvider, kubeConfigs)
+			}
 
 			if err != nil {
 				ee.Summary = fmt.Sprintf("Error while %sing %s", operation, opReq.OperationName)
 				ee.Details = err.Error()
+				if len(warnings) != 0 {
+					ee.Details = fmt.Sprintf("%s\nThe other resources failed as well:\n%s", ee.Details, strings.Join(warnings, "\n"))
+				}
+				ee.Details = results.details(ee.Details)
 				ee.ErrorCode = errors.GetCode(err)
 				ee.ProbableCause = errors.GetCause(err)
 				ee.SuggestedRemediation = errors.GetRemedy(err)
 				hh.StreamErr(ee, err)
 				return
 			}
+			if len(warnings) != 0 {
+				warnErr := ErrAddonPartiallyApplied(opReq.OperationName, warnings)
+				hh.StreamWarn(&meshes.EventsResponse{
+					OperationId:          ee.OperationId,
+					Component:            ee.Component,
+					ComponentName:        ee.ComponentName,
+					Summary:              fmt.Sprintf("%s %sed with %d non-fatal failures", opReq.OperationName, operation, len(warnings)),
+					Details:              warnErr.Error(),
+					ErrorCode:            errors.GetCode(warnErr),
+					ProbableCause:        errors.GetCause(warnErr),
+					SuggestedRemediation: errors.GetRemedy(warnErr),
+				}, warnErr)
+			}
 			ee.Summary = fmt.Sprintf("Successfully %sed %s", operation, opReq.OperationName)
 			ee.Details = fmt.Sprintf("Successfully %sed %s from the %s namespace", operation, opReq.OperationName, opReq.Namespace)
+			if addonVersion != "" {
+				ee.Details = fmt.Sprintf("Successfully %sed %s version %s from the %s namespace", operation, opReq.OperationName, addonVersion, opReq.Namespace)
+			}
+			if stat == statusSubmitted {
+				ee.Summary = fmt.Sprintf("%s %s submitted", opReq.OperationName, operation)
+				ee.Details = fmt.Sprintf("%s is applied to the %s namespace without waiting for its rollout, hence it may not be ready yet, the failures of its rollout are not reported and its endpoints are not resolved. Poll the %s operation for the progress of the clusters, or check the deployments of the namespace", opReq.OperationName, opReq.Namespace, internalconfig.OperationStatusOperation)
+			}
+			if !opReq.IsDeleteOperation && hub != "" {
+				ee.Details = fmt.Sprintf("%s, its images pulled from %s", ee.Details, hub)
+			}
+			if len(endpoints) != 0 {
+				ee.Details = fmt.Sprintf("%s, accessible at:\n%s", ee.Details, strings.Join(endpoints, "\n"))
+			}
+			if !opReq.IsDeleteOperation {
+				ee.Details = metadata.details(ee.Details)
+			}
+			ee.Details = results.details(ee.Details)
 			hh.StreamInfo(ee)
 		}(istio, e)
 	case internalconfig.IstioVetOperation:
 		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
 			responseChan := make(chan *meshes.EventsResponse, 1)
+			format := operations[opReq.OperationName].AdditionalProperties[internalconfig.ResultFormat]
+			junit := format == JUnitFormat
+			var results []testResult
+			var findings []VetFinding
+			last := time.Now()
 
-			go hh.RunVet(responseChan, kubeConfigs)
+			go func() {
+				defer close(responseChan)
+				findings, _ = hh.vet(responseChan, kubeConfigs)
+			}()
 
 			for msg := range responseChan {
+				if junit {
+					results = append(results, vetTestResult(msg, time.Since(last)))
+					last = time.Now()
+				}
 				switch msg.EventType {
 				case meshes.EventType_ERROR:
 					istio.StreamErr(msg, ErrIstioVet(stderrors.New(msg.Details)))
@@ -222,14 +946,656 @@ func (istio *Istio) ApplyOperation(ctx context.Context, opReq adapter.OperationR
 					istio.StreamInfo(msg)
 				}
 			}
+			if junit {
+				hh.streamJUnit(ee, operations[opReq.OperationName].Description, results)
+			}
+			if format == JSONFormat {
+				details, _ := json.Marshal(findings)
+				ee.Summary = fmt.Sprintf("%d istio-vet findings", len(findings))
+				ee.Details = string(details)
+				hh.StreamInfo(ee)
+			}
 
-			istio.Log.Info("Done")
+			hh.log(ctx).Info("Done")
 		}(istio, e)
-	case internalconfig.EnvoyFilterOperation:
+	case internalconfig.IngressGatewayOperation, internalconfig.EgressGatewayOperation:
 		go func(hh *Istio, ee *meshes.EventsResponse) {
-			appName := operations[opReq.OperationName].AdditionalProperties[common.ServiceName]
-			patchFile := operations[opReq.OperationName].AdditionalProperties[internalconfig.FilterPatchFile]
-			stat, err := hh.patchWithEnvoyFilter(opReq.Namespace, opReq.IsDeleteOperation, appName, operations[opReq.OperationName].Templates, patchFile, kubeConfigs)
+			defer done()
+			gatewayType := IngressGateway
+			if opReq.OperationName == internalconfig.EgressGatewayOperation {
+				gatewayType = EgressGateway
+			}
+			props := operations[opReq.OperationName].AdditionalProperties
+			stat := status.Installing
+			if opReq.IsDeleteOperation {
+				stat = status.Removing
+			}
+			version, err := resolveVersion(operations[opReq.OperationName].Versions, requestedVersion)
+			if err == nil {
+				var replicas int
+				replicas, err = strconv.Atoi(props[internalconfig.GatewayReplicas])
+				if err != nil {
+					err = ErrInstallGateway(gatewayType, fmt.Errorf("invalid replicas %q", props[internalconfig.GatewayReplicas]))
+				} else {
+					stat, err = hh.installGateway(ctx, gatewayType, opReq.Namespace, opReq.IsDeleteOperation, gatewayOptions{
+						Version:     version,
+						Replicas:    replicas,
+						ServiceType: props[internalconfig.GatewayServiceType],
+					}, kubeConfigs)
+				}
+			}
+			if err != nil {
+				ee.Summary = fmt.Sprintf("Error while %s Istio %s gateway", stat, gatewayType)
+				ee.Details = err.Error()
+				ee.ErrorCode = errors.GetCode(err)
+				ee.ProbableCause = errors.GetCause(err)
+				ee.SuggestedRemediation = errors.GetRemedy(err)
+				hh.StreamErr(ee, err)
+				return
+			}
+			ee.Summary = fmt.Sprintf("Istio %s gateway %s successfully", gatewayType, stat)
+			ee.Details = fmt.Sprintf("The Istio %s gateway %s is now %s in %s namespace.", gatewayType, version, stat, opReq.Namespace)
+			hh.StreamInfo(ee)
+		}(istio, e)
+	case internalconfig.EastWestGatewayOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			network := strings.TrimSpace(operations[opReq.OperationName].AdditionalProperties[internalconfig.Network])
+			stat := status.Installing
+			if opReq.IsDeleteOperation {
+				stat = status.Removing
+			}
+			var addresses []string
+			version, err := resolveVersion(operations[opReq.OperationName].Versions, requestedVersion)
+			if err == nil {
+				stat, err = hh.installEastWestGateway(ctx, network, opReq.Namespace, opReq.IsDeleteOperation, version, func(cluster, address string) {
+					addresses = append(addresses, fmt.Sprintf("%s: %s", cluster, address))
+					hh.StreamInfo(&meshes.EventsResponse{
+						OperationId:   ee.OperationId,
+						Component:     ee.Component,
+						ComponentName: ee.ComponentName,
+						Summary:       fmt.Sprintf("East-west gateway of %s ready on cluster %s", network, cluster),
+						Details:       fmt.Sprintf("The clusters of the other networks reach %s through %s.", cluster, address),
+					})
+				}, kubeConfigs)
+			}
+			if err != nil {
+				ee.Summary = fmt.Sprintf("Error while %s the east-west gateway of %s", stat, network)
+				ee.Details = err.Error()
+				ee.ErrorCode = errors.GetCode(err)
+				ee.ProbableCause = errors.GetCause(err)
+				ee.SuggestedRemediation = errors.GetRemedy(err)
+				hh.StreamErr(ee, err)
+				return
+			}
+			ee.Summary = fmt.Sprintf("East-west gateway of %s %s successfully", network, stat)
+			ee.Details = fmt.Sprintf("The east-west gateway of %s is now %s in %s namespace.", network, stat, opReq.Namespace)
+			if len(addresses) > 0 {
+				sort.Strings(addresses)
+				ee.Details = fmt.Sprintf("%s External addresses:\n%s", ee.Details, strings.Join(addresses, "\n"))
+			}
+			hh.StreamInfo(ee)
+		}(istio, e)
+	case internalconfig.CreateRemoteSecretOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			props := operations[opReq.OperationName].AdditionalProperties
+			remoteCluster := props[internalconfig.ClusterName]
+			stat := status.Installing
+			if opReq.IsDeleteOperation {
+				stat = status.Removing
+			}
+			version, err := resolveVersion(operations[opReq.OperationName].Versions, requestedVersion)
+			if err == nil {
+				stat, remoteCluster, err = hh.createRemoteSecret(ctx, props[internalconfig.RemoteKubeconfig], remoteCluster, opReq.IsDeleteOperation, version, kubeConfigs)
+			}
+			if err != nil {
+				ee.Summary = fmt.Sprintf("Error while %s the remote secret of %s", stat, remoteCluster)
+				ee.Details = err.Error()
+				ee.ErrorCode = errors.GetCode(err)
+				ee.ProbableCause = errors.GetCause(err)
+				ee.SuggestedRemediation = errors.GetRemedy(err)
+				hh.StreamErr(ee, err)
+				return
+			}
+			ee.Summary = fmt.Sprintf("Remote secret of %s %s successfully", remoteCluster, stat)
+			ee.Details = fmt.Sprintf("The control planes now discover the services of the remote cluster %s through the %s secret.", remoteCluster, remoteSecretName(remoteCluster))
+			if opReq.IsDeleteOperation {
+				ee.Details = fmt.Sprintf("The %s secret is now removed, the control planes no longer discover the services of the remote cluster %s.", remoteSecretName(remoteCluster), remoteCluster)
+			}
+			hh.StreamInfo(ee)
+		}(istio, e)
+	case internalconfig.SetProxyLogLevelOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			props := operations[opReq.OperationName].AdditionalProperties
+			level := props[internalconfig.ProxyLogLevel]
+			if opReq.IsDeleteOperation {
+				level = "the initial level"
+			}
+			version, err := resolveVersion(operations[opReq.OperationName].Versions, requestedVersion)
+			var proxies []string
+			if err == nil {
+				proxies, err = hh.setProxyLogLevel(ctx, opReq.Namespace, props[internalconfig.ProxyPod], props[internalconfig.ProxyLogLevel], opReq.IsDeleteOperation, version, kubeConfigs)
+			}
+			if err != nil {
+				ee.Summary = fmt.Sprintf("Error while setting the log level of the proxies in %s namespace", opReq.Namespace)
+				ee.Details = err.Error()
+				if len(proxies) > 0 {
+					ee.Details = fmt.Sprintf("%s\nProxies set to %s:\n%s", ee.Details, level, strings.Join(proxies, "\n"))
+				}
+				ee.ErrorCode = errors.GetCode(err)
+				ee.ProbableCause = errors.GetCause(err)
+				ee.SuggestedRemediation = errors.GetRemedy(err)
+				hh.StreamErr(ee, err)
+				return
+			}
+			ee.Summary = fmt.Sprintf("Log level of %d proxies set to %s successfully", len(proxies), level)
+			ee.Details = fmt.Sprintf("No proxy found in %s namespace.", opReq.Namespace)
+			if len(proxies) > 0 {
+				ee.Details = fmt.Sprintf("The proxies log at %s until they restart:\n%s", level, strings.Join(proxies, "\n"))
+			}
+			hh.StreamInfo(ee)
+		}(istio, e)
+	case internalconfig.TelemetryOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			provider := operations[opReq.OperationName].AdditionalProperties[internalconfig.ProviderName]
+			stat, err := hh.applyTelemetry(ctx, opReq.Namespace, opReq.IsDeleteOperation, provider, operations[opReq.OperationName].Templates, kubeConfigs)
+			if err != nil {
+				ee.Summary = fmt.Sprintf("Error while %s telemetry in %s namespace", stat, opReq.Namespace)
+				ee.Details = err.Error()
+				ee.ErrorCode = errors.GetCode(err)
+				ee.ProbableCause = errors.GetCause(err)
+				ee.SuggestedRemediation = errors.GetRemedy(err)
+				hh.StreamErr(ee, err)
+				return
+			}
+			ee.Summary = fmt.Sprintf("Telemetry %s successfully", stat)
+			ee.Details = fmt.Sprintf("Telemetry %s in %s namespace", stat, opReq.Namespace)
+			if provider != "" && !opReq.IsDeleteOperation {
+				ee.Details = fmt.Sprintf("%s with tracing provider %s", ee.Details, provider)
+			}
+			hh.StreamInfo(ee)
+		}(istio, e)
+	case internalconfig.IstioAnalyzeOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			var messages []AnalyzerMessage
+			version, err := resolveVersion(operations[opReq.OperationName].Versions, requestedVersion)
+			if err == nil {
+				messages, err = hh.runAnalyze(version, opReq.Namespace, kubeConfigs)
+			}
+			counts := make(map[string]int)
+			for _, m := range messages {
+				counts[m.Level]++
+				e := &meshes.EventsResponse{
+					OperationId:   ee.OperationId,
+					Component:     ee.Component,
+					ComponentName: ee.ComponentName,
+					Summary:       fmt.Sprintf("%s %s on cluster %s", m.Code, m.Level, m.Cluster),
+					Details:       m.String(),
+				}
+				switch m.Level {
+				case AnalyzerLevelError:
+					hh.StreamErr(e, stderrors.New(m.String()))
+				case AnalyzerLevelWarning:
+					hh.StreamWarn(e, stderrors.New(m.String()))
+				default:
+					hh.StreamInfo(e)
+				}
+			}
+			if err != nil {
+				ee.Summary = "Error while analyzing Istio configuration"
+				ee.Details = err.Error()
+				ee.ErrorCode = errors.GetCode(err)
+				ee.ProbableCause = errors.GetCause(err)
+				ee.SuggestedRemediation = errors.GetRemedy(err)
+				hh.StreamErr(ee, err)
+				return
+			}
+			failOnWarning := operations[opReq.OperationName].AdditionalProperties[internalconfig.FailOnWarning] == "true"
+			if operations[opReq.OperationName].AdditionalProperties[internalconfig.ResultFormat] == JUnitFormat {
+				clusters := make([]string, 0, len(kubeConfigs))
+				for _, k8sconfig := range kubeConfigs {
+					clusters = append(clusters, clusterName(k8sconfig))
+				}
+				hh.streamJUnit(ee, operations[opReq.OperationName].Description, analyzeTestResults(messages, clusters, failOnWarning))
+			}
+			ee.Summary = "Istio configuration analyzed"
+			if counts[AnalyzerLevelError] > 0 || (failOnWarning && counts[AnalyzerLevelWarning] > 0) {
+				ee.Summary = "Istio configuration analysis failed"
+			}
+			ee.Details = fmt.Sprintf("%d errors, %d warnings and %d info messages on %d cluster(s)", counts[AnalyzerLevelError], counts[AnalyzerLevelWarning], counts[AnalyzerLevelInfo], len(kubeConfigs))
+			hh.StreamInfo(ee)
+		}(istio, e)
+	case internalconfig.ProxyStatusOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			var statuses []ProxySyncStatus
+			version, err := resolveVersion(operations[opReq.OperationName].Versions, requestedVersion)
+			if err == nil {
+				statuses, err = hh.getProxySyncStatus(version, kubeConfigs)
+			}
+			unsynced := 0
+			for _, s := range statuses {
+				if s.Synced() {
+					continue
+				}
+				unsynced++
+				hh.StreamWarn(&meshes.EventsResponse{
+					OperationId:   ee.OperationId,
+					Component:     ee.Component,
+					ComponentName: ee.ComponentName,
+					Summary:       fmt.Sprintf("Proxy %s on cluster %s not synced", s.Proxy, s.Cluster),
+					Details:       s.String(),
+				}, stderrors.New(s.String()))
+			}
+			if err != nil {
+				ee.Summary = "Error while reading the proxy sync status"
+				ee.Details = err.Error()
+				ee.ErrorCode = errors.GetCode(err)
+				ee.ProbableCause = errors.GetCause(err)
+				ee.SuggestedRemediation = errors.GetRemedy(err)
+				hh.StreamErr(ee, err)
+				return
+			}
+			ee.Summary = "All the proxies are synced"
+			if unsynced > 0 {
+				ee.Summary = fmt.Sprintf("%d proxies not synced", unsynced)
+			}
+			ee.Details = fmt.Sprintf("%d of %d proxies fully synced on %d cluster(s)", len(statuses)-unsynced, len(statuses), len(kubeConfigs))
+			hh.StreamInfo(ee)
+		}(istio, e)
+	case internalconfig.ProxyConfigOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			pod := strings.TrimSpace(operations[opReq.OperationName].AdditionalProperties[internalconfig.ProxyConfigPod])
+			configType := operations[opReq.OperationName].AdditionalProperties[internalconfig.ProxyConfigType]
+			var dumps []ProxyConfigDump
+			version, err := resolveVersion(operations[opReq.OperationName].Versions, requestedVersion)
+			if err == nil {
+				dumps, err = hh.getProxyConfig(version, pod, opReq.Namespace, configType, kubeConfigs)
+			}
+			var details string
+			if err == nil {
+				details, err = proxyConfigDetails(dumps)
+			}
+			if err != nil {
+				ee.Summary = fmt.Sprintf("Error while dumping the proxy config of %s", pod)
+				ee.Details = err.Error()
+				ee.ErrorCode = errors.GetCode(err)
+				ee.ProbableCause = errors.GetCause(err)
+				ee.SuggestedRemediation = errors.GetRemedy(err)
+				hh.StreamErr(ee, err)
+				return
+			}
+			ee.Summary = fmt.Sprintf("Proxy %s of %s dumped on %d cluster(s)", dumps[0].Type, pod, len(dumps))
+			ee.Details = details
+			hh.StreamInfo(ee)
+		}(istio, e)
+	case internalconfig.IstioBugReportOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			var reports []BugReport
+			version, err := resolveVersion(operations[opReq.OperationName].Versions, requestedVersion)
+			if err == nil {
+				reports, err = hh.collectBugReports(version, opReq.Namespace, kubeConfigs)
+			}
+			archives := make([]string, 0, len(reports))
+			for _, r := range reports {
+				archives = append(archives, fmt.Sprintf("%s: %s", r.Cluster, r.Archive))
+			}
+			if err != nil {
+				ee.Summary = "Error while collecting the bug report"
+				ee.Details = err.Error()
+				if len(archives) > 0 {
+					ee.Details = fmt.Sprintf("%s\nReports collected:\n%s", ee.Details, strings.Join(archives, "\n"))
+				}
+				ee.ErrorCode = errors.GetCode(err)
+				ee.ProbableCause = errors.GetCause(err)
+				ee.SuggestedRemediation = errors.GetRemedy(err)
+				hh.StreamErr(ee, err)
+				return
+			}
+			scope := "all the namespaces"
+			if opReq.Namespace != "" {
+				scope = fmt.Sprintf("%s namespace", opReq.Namespace)
+			}
+			ee.Summary = fmt.Sprintf("Bug report of %s collected", scope)
+			ee.Details = fmt.Sprintf("Reports archived on %d cluster(s):\n%s", len(reports), strings.Join(archives, "\n"))
+			hh.StreamInfo(ee)
+		}(istio, e)
+	case internalconfig.IstioListVersionsOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			var versions []adapter.Version
+			err := configureProxy(operations[opReq.OperationName].AdditionalProperties)
+			if err == nil {
+				versions, err = listAvailableVersions()
+			}
+			if err != nil {
+				ee.Summary = "Error while listing the available Istio versions"
+				ee.Details = err.Error()
+				ee.ErrorCode = errors.GetCode(err)
+				ee.ProbableCause = errors.GetCause(err)
+				ee.SuggestedRemediation = errors.GetRemedy(err)
+				hh.StreamErr(ee, err)
+				return
+			}
+			names := make([]string, 0, len(versions))
+			for _, v := range versions {
+				name := v.String()
+				if label := versionSupportOf(name, versions).label(); label != "" {
+					name = fmt.Sprintf("%s (%s)", name, label)
+				}
+				names = append(names, name)
+			}
+			ee.Summary = fmt.Sprintf("%d Istio versions are available", len(versions))
+			ee.Details = strings.Join(names, ", ")
+			if support := versionSupportOf(requestedVersion.String(), versions); !support.Supported() {
+				ee.Details = fmt.Sprintf("%s\n%s", ee.Details, support)
+			}
+			hh.StreamInfo(ee)
+		}(istio, e)
+	case internalconfig.IstioHealthCheckOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			health, err := hh.checkMeshHealth(opReq.Namespace, kubeConfigs)
+			for _, h := range health {
+				details, _ := json.Marshal(h)
+				e := &meshes.EventsResponse{
+					OperationId:   ee.OperationId,
+					Component:     ee.Component,
+					ComponentName: ee.ComponentName,
+					Summary:       fmt.Sprintf("Mesh health on cluster %s: %s", h.Cluster, h),
+					Details:       string(details),
+				}
+				if problems := h.Problems(); len(problems) > 0 {
+					hh.StreamWarn(e, stderrors.New(strings.Join(problems, ", ")))
+					continue
+				}
+				hh.StreamInfo(e)
+			}
+			if err != nil {
+				ee.Summary = "Istio service mesh is not healthy"
+				ee.Details = err.Error()
+				ee.ErrorCode = errors.GetCode(err)
+				ee.ProbableCause = errors.GetCause(err)
+				ee.SuggestedRemediation = errors.GetRemedy(err)
+				hh.StreamErr(ee, err)
+				return
+			}
+			ee.Summary = "Istio service mesh is healthy"
+			ee.Details = fmt.Sprintf("Control plane and sidecars are healthy on %d cluster(s)", len(health))
+			hh.StreamInfo(ee)
+		}(istio, e)
+	case internalconfig.IstioLogsOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			component := operations[opReq.OperationName].AdditionalProperties[internalconfig.LogsComponent]
+			var logs []ComponentLogs
+			tailLines, err := parseTailLines(operations[opReq.OperationName].AdditionalProperties[internalconfig.LogsTailLines])
+			if err != nil {
+				err = ErrFetchLogsFailed(component, err)
+			} else {
+				logs, err = hh.fetchComponentLogs(ctx, component, opReq.Namespace, tailLines, kubeConfigs)
+			}
+			lines := make([]string, 0, len(logs))
+			for _, l := range logs {
+				lines = append(lines, l.String())
+			}
+			if err != nil {
+				ee.Summary = fmt.Sprintf("Error while fetching the logs of %s", component)
+				ee.Details = err.Error()
+				if len(lines) > 0 {
+					ee.Details = fmt.Sprintf("%s\n%s", ee.Details, strings.Join(lines, "\n"))
+				}
+				ee.ErrorCode = errors.GetCode(err)
+				ee.ProbableCause = errors.GetCause(err)
+				ee.SuggestedRemediation = errors.GetRemedy(err)
+				hh.StreamErr(ee, err)
+				return
+			}
+			ee.Summary = fmt.Sprintf("Logs of %s fetched from %d pod(s)", component, len(logs))
+			ee.Details = strings.Join(lines, "\n")
+			hh.StreamInfo(ee)
+		}(istio, e)
+	case internalconfig.WaypointOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			stat := status.Deploying
+			values, err := newWaypointValues(opReq.Namespace, operations[opReq.OperationName].AdditionalProperties)
+			if err == nil {
+				stat, err = hh.deployWaypoint(ctx, opReq.IsDeleteOperation, values, operations[opReq.OperationName].Templates, kubeConfigs)
+			}
+			if err != nil {
+				ee.Summary = fmt.Sprintf("Error while %s the waypoint proxy in %s namespace", stat, opReq.Namespace)
+				ee.Details = err.Error()
+				ee.ErrorCode = errors.GetCode(err)
+				ee.ProbableCause = errors.GetCause(err)
+				ee.SuggestedRemediation = errors.GetRemedy(err)
+				hh.StreamErr(ee, err)
+				return
+			}
+			ee.Summary = fmt.Sprintf("Waypoint proxy %s %s successfully", values.Name, stat)
+			switch {
+			case opReq.IsDeleteOperation:
+				ee.Details = fmt.Sprintf("The waypoint proxy %s is now %s from %s namespace.", values.Name, stat, opReq.Namespace)
+			case values.ServiceAccount != "":
+				ee.Details = fmt.Sprintf("The waypoint proxy %s of the %s service account is now %s, label its workloads with %s=%s to use it.", values.Name, values.ServiceAccount, stat, useWaypointLabel, values.Name)
+			default:
+				ee.Details = fmt.Sprintf("The waypoint proxy %s is now %s and used by the services of %s namespace.", values.Name, stat, opReq.Namespace)
+			}
+			hh.StreamInfo(ee)
+		}(istio, e)
+	case internalconfig.InjectionStatusOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			status, err := hh.getInjectionStatus(kubeConfigs)
+			restarts := 0
+			for _, n := range status {
+				details, _ := json.Marshal(n)
+				e := &meshes.EventsResponse{
+					OperationId:   ee.OperationId,
+					Component:     ee.Component,
+					ComponentName: ee.ComponentName,
+					Summary:       fmt.Sprintf("Namespace %s on cluster %s: %s", n.Namespace, n.Cluster, n),
+					Details:       string(details),
+				}
+				if n.NeedsRestart() {
+					restarts++
+					hh.StreamWarn(e, fmt.Errorf("pods of %s need to be restarted to run the proxy of revision %s, see the %s operation", n.Namespace, n.Revision, internalconfig.RestartWorkloadsOperation))
+					continue
+				}
+				hh.StreamInfo(e)
+			}
+			if err != nil {
+				ee.Summary = "Error while getting the sidecar injection status"
+				ee.Details = err.Error()
+				ee.ErrorCode = errors.GetCode(err)
+				ee.ProbableCause = errors.GetCause(err)
+				ee.SuggestedRemediation = errors.GetRemedy(err)
+				hh.StreamErr(ee, err)
+				return
+			}
+			ee.Summary = fmt.Sprintf("%d namespaces are labeled for sidecar injection", len(status))
+			ee.Details = fmt.Sprintf("%d of them have pods to restart.", restarts)
+			hh.StreamInfo(ee)
+		}(istio, e)
+	case internalconfig.MeshConfigDumpOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			dumps, err := hh.dumpMeshConfig(kubeConfigs)
+			for _, dump := range dumps {
+				details, _ := json.Marshal(dump)
+				hh.StreamInfo(&meshes.EventsResponse{
+					OperationId:   ee.OperationId,
+					Component:     ee.Component,
+					ComponentName: ee.ComponentName,
+					Summary:       fmt.Sprintf("Mesh configuration of cluster %s", dump.Cluster),
+					Details:       string(details),
+				})
+			}
+			if err != nil {
+				ee.Summary = "Error while dumping the mesh configuration"
+				ee.Details = err.Error()
+				ee.ErrorCode = errors.GetCode(err)
+				ee.ProbableCause = errors.GetCause(err)
+				ee.SuggestedRemediation = errors.GetRemedy(err)
+				hh.StreamErr(ee, err)
+				return
+			}
+			ee.Summary = fmt.Sprintf("Mesh configuration of %d clusters dumped", len(dumps))
+			ee.Details = "The mesh config, proxy config defaults, IstioOperators and Istio CRD versions of each cluster are in the events of the operation."
+			hh.StreamInfo(ee)
+		}(istio, e)
+	case internalconfig.ListInstalledComponentsOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			inventories, err := hh.listInstalledComponents(kubeConfigs)
+			for _, inventory := range inventories {
+				details, _ := json.Marshal(inventory)
+				hh.StreamInfo(&meshes.EventsResponse{
+					OperationId:   ee.OperationId,
+					Component:     ee.Component,
+					ComponentName: ee.ComponentName,
+					Summary:       fmt.Sprintf("Cluster %s: %s", inventory.Cluster, inventory),
+					Details:       string(details),
+				})
+			}
+			if err != nil {
+				ee.Summary = "Error while listing the installed Istio components"
+				ee.Details = err.Error()
+				ee.ErrorCode = errors.GetCode(err)
+				ee.ProbableCause = errors.GetCause(err)
+				ee.SuggestedRemediation = errors.GetRemedy(err)
+				hh.StreamErr(ee, err)
+				return
+			}
+			ee.Summary = fmt.Sprintf("Installed Istio components of %d clusters listed", len(inventories))
+			ee.Details = "The IstioOperator components, control plane revisions and addons of each cluster are in the events of the operation."
+			hh.StreamInfo(ee)
+		}(istio, e)
+	case internalconfig.ExportManifestsOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			bundles, err := hh.exportInstalledManifests(opReq.Namespace, kubeConfigs)
+			for _, bundle := range bundles {
+				hh.StreamInfo(&meshes.EventsResponse{
+					OperationId:   ee.OperationId,
+					Component:     ee.Component,
+					ComponentName: ee.ComponentName,
+					Summary:       fmt.Sprintf("%d Istio resources exported from cluster %s", bundle.Resources, bundle.Cluster),
+					Details:       bundle.Manifest,
+				})
+			}
+			if err != nil {
+				ee.Summary = "Error while exporting the installed manifests"
+				ee.Details = err.Error()
+				ee.ErrorCode = errors.GetCode(err)
+				ee.ProbableCause = errors.GetCause(err)
+				ee.SuggestedRemediation = errors.GetRemedy(err)
+				hh.StreamErr(ee, err)
+				return
+			}
+			ee.Summary = fmt.Sprintf("Installed manifests of %d clusters exported", len(bundles))
+			ee.Details = "The manifest of each cluster is in the events of the operation, stripped of the fields specific to the cluster so that it can be applied to another one."
+			hh.StreamInfo(ee)
+		}(istio, e)
+	case internalconfig.MetricsSummaryOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			workload := operations[opReq.OperationName].AdditionalProperties[internalconfig.WorkloadName]
+			promURL := operations[opReq.OperationName].AdditionalProperties[internalconfig.PrometheusURL]
+			summary, err := hh.getServiceMetrics(opReq.Namespace, workload, promURL, kubeConfigs)
+			if err != nil {
+				ee.Summary = fmt.Sprintf("Error while fetching metrics summary for %s namespace", opReq.Namespace)
+				ee.Details = err.Error()
+				ee.ErrorCode = errors.GetCode(err)
+				ee.ProbableCause = errors.GetCause(err)
+				ee.SuggestedRemediation = errors.GetRemedy(err)
+				hh.StreamErr(ee, err)
+				return
+			}
+			details, _ := json.Marshal(summary)
+			ee.Summary = fmt.Sprintf("Metrics summary fetched for %s namespace", opReq.Namespace)
+			ee.Details = string(details)
+			hh.StreamInfo(ee)
+		}(istio, e)
+	case internalconfig.MigrateAuthPolicyOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			removeLegacy := operations[opReq.OperationName].AdditionalProperties[internalconfig.RemoveLegacyPolicies] == "true"
+			migrations, err := hh.migrateLegacyAuthPolicies(opReq.IsDeleteOperation, removeLegacy, kubeConfigs)
+			details, _ := json.Marshal(migrations)
+			if err != nil {
+				ee.Summary = "Error while migrating legacy authentication policies"
+				ee.Details = fmt.Sprintf("%s\n%s", err.Error(), details)
+				ee.ErrorCode = errors.GetCode(err)
+				ee.ProbableCause = errors.GetCause(err)
+				ee.SuggestedRemediation = errors.GetRemedy(err)
+				hh.StreamErr(ee, err)
+				return
+			}
+			ee.Summary = fmt.Sprintf("%d legacy authentication policies migrated to PeerAuthentication", len(migrations))
+			if opReq.IsDeleteOperation {
+				ee.Summary = fmt.Sprintf("%d migrated PeerAuthentication policies removed", len(migrations))
+			} else if !removeLegacy {
+				ee.Summary += ", legacy policies are left in place until confirmed"
+			}
+			ee.Details = string(details)
+			hh.StreamInfo(ee)
+		}(istio, e)
+	case internalconfig.ReadinessGatedRolloutOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			opts, err := newTrafficGatingOptions(operations[opReq.OperationName].AdditionalProperties, opReq.IsDeleteOperation)
+			var weight int
+			if err == nil {
+				weight, err = hh.gateTrafficOnReadiness(ctx, opReq.Namespace, opReq.IsDeleteOperation, opts, func(weight, ready, total int) {
+					hh.StreamInfo(&meshes.EventsResponse{
+						OperationId:   ee.OperationId,
+						Component:     ee.Component,
+						ComponentName: ee.ComponentName,
+						Summary:       fmt.Sprintf("%s traffic shifted: %d%% to %s", opts.Service, weight, opts.Canary),
+						Details:       fmt.Sprintf("%d of %d %s pods are ready, %s receives %d%% and %s receives %d%% of the traffic", ready, total, opts.Canary, opts.Stable, 100-weight, opts.Canary, weight),
+					})
+				}, kubeConfigs)
+			}
+			if err != nil {
+				ee.Summary = fmt.Sprintf("Error while shifting %s traffic to %s", opts.Service, opts.Canary)
+				ee.Details = err.Error()
+				ee.ErrorCode = errors.GetCode(err)
+				ee.ProbableCause = errors.GetCause(err)
+				ee.SuggestedRemediation = errors.GetRemedy(err)
+				hh.StreamErr(ee, err)
+				return
+			}
+			if opReq.IsDeleteOperation {
+				ee.Summary = fmt.Sprintf("Readiness gated routing for %s removed", opts.Service)
+				ee.Details = fmt.Sprintf("The DestinationRule and VirtualService for %s are now %s.", opts.Service, status.Removed)
+				hh.StreamInfo(ee)
+				return
+			}
+			ee.Summary = fmt.Sprintf("%s rollout finished with %d%% of the traffic on %s", opts.Service, weight, opts.Canary)
+			ee.Details = fmt.Sprintf("%s receives %d%% and %s receives %d%% of the traffic.", opts.Stable, 100-weight, opts.Canary, weight)
+			if weight < 100 {
+				ee.Details += fmt.Sprintf(" Not all %s pods became ready within %s.", opts.Canary, opts.Window)
+			}
+			hh.StreamInfo(ee)
+		}(istio, e)
+	case internalconfig.EnvoyFilterOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			appName := operations[opReq.OperationName].AdditionalProperties[common.ServiceName]
+			patchFile := operations[opReq.OperationName].AdditionalProperties[internalconfig.FilterPatchFile]
+			merge := operations[opReq.OperationName].AdditionalProperties[internalconfig.FilterMerge] == "true"
+			stat := status.Deploying
+			version, err := resolveVersion(operations[opReq.OperationName].Versions, requestedVersion)
+			if err == nil {
+				stat, err = hh.patchWithEnvoyFilter(opReq.Namespace, opReq.IsDeleteOperation, merge, appName, version, operations[opReq.OperationName].Templates, patchFile, kubeConfigs)
+			}
 			if err != nil {
 				ee.Summary = fmt.Sprintf("Error while %s %s application", stat, appName)
 				ee.Details = err.Error()
@@ -241,72 +1607,470 @@ func (istio *Istio) ApplyOperation(ctx context.Context, opReq adapter.OperationR
 			}
 			ee.Summary = fmt.Sprintf("%s application %s successfully", appName, stat)
 			ee.Details = fmt.Sprintf("The %s application is now %s.", appName, stat)
+			if merge && opReq.IsDeleteOperation {
+				ee.Details = fmt.Sprintf("%s Only the config patches added by the merge were removed from the EnvoyFilters.", ee.Details)
+			} else if merge {
+				ee.Details = fmt.Sprintf("%s The config patches were merged into the existing EnvoyFilters.", ee.Details)
+			}
+			hh.StreamInfo(ee)
+		}(istio, e)
+	case internalconfig.IstioCanaryUpgradeOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			props := operations[opReq.OperationName].AdditionalProperties
+			fromRev, toRev := props[internalconfig.FromRevision], props[internalconfig.ToRevision]
+			namespaces := []string{opReq.Namespace}
+			if props[internalconfig.UpgradeNamespaces] != "" {
+				namespaces = nil
+				for _, ns := range strings.Split(props[internalconfig.UpgradeNamespaces], ",") {
+					if ns = strings.TrimSpace(ns); ns != "" {
+						namespaces = append(namespaces, ns)
+					}
+				}
+			}
+			var err error
+			var version string
+			if opReq.IsDeleteOperation {
+				err = ErrCanaryUpgradeFailed(stderrors.New("a canary upgrade can't be removed, run it again towards the previous revision instead"))
+			} else {
+				version, err = resolveVersion(operations[opReq.OperationName].Versions, requestedVersion)
+			}
+			if err == nil {
+				err = hh.upgradeCanary(ctx, fromRev, toRev, namespaces, canaryUpgradeOptions{
+					Version: version,
+					Profile: props[internalconfig.Profile],
+					OnStep: func(step string) {
+						hh.StreamInfo(&meshes.EventsResponse{
+							OperationId:   ee.OperationId,
+							Component:     ee.Component,
+							ComponentName: ee.ComponentName,
+							Summary:       fmt.Sprintf("Canary upgrade to revision %s: %s", toRev, step),
+						})
+					},
+				}, kubeConfigs)
+			}
+			if err != nil {
+				ee.Summary = fmt.Sprintf("Error while upgrading to revision %s", toRev)
+				ee.Details = err.Error()
+				ee.ErrorCode = errors.GetCode(err)
+				ee.ProbableCause = errors.GetCause(err)
+				ee.SuggestedRemediation = errors.GetRemedy(err)
+				hh.StreamErr(ee, err)
+				return
+			}
+			ee.Summary = fmt.Sprintf("Canary upgrade to revision %s completed successfully", toRev)
+			ee.Details = fmt.Sprintf("The workloads of %s now run the proxies of revision %s, revision %s can be removed once it is no longer needed.", strings.Join(namespaces, ", "), toRev, revisionName(fromRev))
+			hh.StreamInfo(ee)
+		}(istio, e)
+	case internalconfig.DestinationRuleOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			stat := status.Deploying
+			results := newClusterResults()
+			values, err := newDestinationRuleValues(opReq.Namespace, operations[opReq.OperationName].AdditionalProperties, opReq.IsDeleteOperation)
+			var metadata extraMetadata
+			if err == nil {
+				metadata, err = newExtraMetadata(operations[opReq.OperationName].AdditionalProperties)
+			}
+			if err == nil {
+				stat, err = hh.applyPolicy(ctx, opReq.Namespace, opReq.IsDeleteOperation, operations[opReq.OperationName].Templates, values, metadata, results.track(nil), kubeConfigs)
+			}
+			if err != nil {
+				ee.Summary = fmt.Sprintf("Error while %s DestinationRule in %s namespace", stat, opReq.Namespace)
+				ee.Details = results.details(err.Error())
+				ee.ErrorCode = errors.GetCode(err)
+				ee.ProbableCause = errors.GetCause(err)
+				ee.SuggestedRemediation = errors.GetRemedy(err)
+				hh.StreamErr(ee, err)
+				return
+			}
+			ee.Summary = fmt.Sprintf("Traffic policy of %s applied", values.Host)
+			ee.Details = fmt.Sprintf("DestinationRule %s %s in %s namespace: %s.", values.Name, stat, opReq.Namespace, values)
+			if opReq.IsDeleteOperation {
+				ee.Summary = fmt.Sprintf("DestinationRule %s removed", values.Name)
+				ee.Details = fmt.Sprintf("DestinationRule %s removed from %s namespace.", values.Name, opReq.Namespace)
+			} else {
+				ee.Details = metadata.details(ee.Details)
+			}
+			ee.Details = results.details(ee.Details)
+			hh.StreamInfo(ee)
+		}(istio, e)
+	case internalconfig.ServiceEntryOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			stat := status.Deploying
+			results := newClusterResults()
+			values, err := newServiceEntryValues(opReq.Namespace, operations[opReq.OperationName].AdditionalProperties, opReq.IsDeleteOperation)
+			var metadata extraMetadata
+			if err == nil {
+				metadata, err = newExtraMetadata(operations[opReq.OperationName].AdditionalProperties)
+			}
+			if err == nil {
+				stat, err = hh.applyPolicy(ctx, opReq.Namespace, opReq.IsDeleteOperation, operations[opReq.OperationName].Templates, values, metadata, results.track(nil), kubeConfigs)
+			}
+			if err != nil {
+				ee.Summary = fmt.Sprintf("Error while %s ServiceEntry in %s namespace", stat, opReq.Namespace)
+				ee.Details = results.details(err.Error())
+				ee.ErrorCode = errors.GetCode(err)
+				ee.ProbableCause = errors.GetCause(err)
+				ee.SuggestedRemediation = errors.GetRemedy(err)
+				hh.StreamErr(ee, err)
+				return
+			}
+			ports := make([]string, 0, len(values.Ports))
+			for _, port := range values.Ports {
+				ports = append(ports, fmt.Sprintf("%d/%s", port.Number, port.Protocol))
+			}
+			ee.Summary = fmt.Sprintf("%s registered in the mesh", strings.Join(values.Hosts, ", "))
+			ee.Details = fmt.Sprintf("ServiceEntry %s %s in %s namespace: ports %s with %s resolution.", values.Name, stat, opReq.Namespace, strings.Join(ports, ", "), values.Resolution)
+			if opReq.IsDeleteOperation {
+				ee.Summary = fmt.Sprintf("ServiceEntry %s removed", values.Name)
+				ee.Details = fmt.Sprintf("ServiceEntry %s removed from %s namespace.", values.Name, opReq.Namespace)
+			} else {
+				ee.Details = metadata.details(ee.Details)
+			}
+			ee.Details = results.details(ee.Details)
+			hh.StreamInfo(ee)
+		}(istio, e)
+	case internalconfig.WorkloadEntryOperation, internalconfig.WorkloadGroupOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			stat := status.Deploying
+			kind := "WorkloadEntry"
+			if opReq.OperationName == internalconfig.WorkloadGroupOperation {
+				kind = "WorkloadGroup"
+			}
+			results := newClusterResults()
+			values, err := newWorkloadValues(opReq.Namespace, operations[opReq.OperationName].AdditionalProperties, kind == "WorkloadGroup", opReq.IsDeleteOperation)
+			var metadata extraMetadata
+			if err == nil {
+				metadata, err = newExtraMetadata(operations[opReq.OperationName].AdditionalProperties)
+			}
+			if err == nil {
+				stat, err = hh.applyPolicy(ctx, opReq.Namespace, opReq.IsDeleteOperation, operations[opReq.OperationName].Templates, values, metadata, results.track(nil), kubeConfigs)
+			}
+			if err != nil {
+				ee.Summary = fmt.Sprintf("Error while %s %s in %s namespace", stat, kind, opReq.Namespace)
+				ee.Details = results.details(err.Error())
+				ee.ErrorCode = errors.GetCode(err)
+				ee.ProbableCause = errors.GetCause(err)
+				ee.SuggestedRemediation = errors.GetRemedy(err)
+				hh.StreamErr(ee, err)
+				return
+			}
+			ee.Summary = fmt.Sprintf("%s %s registered in the mesh", kind, values.Name)
+			ee.Details = fmt.Sprintf("%s %s %s in %s namespace for the VMs labeled %s.", kind, values.Name, stat, opReq.Namespace, workloadLabels(values.Labels))
+			if kind == "WorkloadEntry" {
+				ee.Details = fmt.Sprintf("%s %s %s in %s namespace for the VM at %s.", kind, values.Name, stat, opReq.Namespace, values.Address)
+			}
+			if opReq.IsDeleteOperation {
+				ee.Summary = fmt.Sprintf("%s %s removed", kind, values.Name)
+				ee.Details = fmt.Sprintf("%s %s removed from %s namespace.", kind, values.Name, opReq.Namespace)
+			} else {
+				ee.Details = metadata.details(ee.Details)
+			}
+			ee.Details = results.details(ee.Details)
+			hh.StreamInfo(ee)
+		}(istio, e)
+	case internalconfig.ConnectivityTestOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			props := operations[opReq.OperationName].AdditionalProperties
+			results, err := hh.testConnectivity(ctx, props[internalconfig.SourcePod], props[internalconfig.TargetService], opReq.Namespace, kubeConfigs)
+			lines := make([]string, 0, len(results))
+			for _, r := range results {
+				lines = append(lines, r.String())
+			}
+			if err != nil {
+				ee.Summary = fmt.Sprintf("Error while testing the connectivity from %s to %s", props[internalconfig.SourcePod], props[internalconfig.TargetService])
+				ee.Details = err.Error()
+				if len(lines) > 0 {
+					ee.Details = fmt.Sprintf("%s\nResponses received:\n%s", ee.Details, strings.Join(lines, "\n"))
+				}
+				ee.ErrorCode = errors.GetCode(err)
+				ee.ProbableCause = errors.GetCause(err)
+				ee.SuggestedRemediation = errors.GetRemedy(err)
+				hh.StreamErr(ee, err)
+				return
+			}
+			ee.Summary = fmt.Sprintf("%s reached %s", props[internalconfig.SourcePod], props[internalconfig.TargetService])
+			ee.Details = strings.Join(lines, "\n")
+			hh.StreamInfo(ee)
+		}(istio, e)
+	case internalconfig.VerifyMTLSOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			sourcePod := strings.TrimSpace(operations[opReq.OperationName].AdditionalProperties[internalconfig.SourcePod])
+			results, err := hh.verifyMTLSEnforced(ctx, sourcePod, opReq.Namespace, kubeConfigs)
+			lines := make([]string, 0, len(results))
+			for _, r := range results {
+				lines = append(lines, r.String())
+			}
+			if err != nil {
+				ee.Summary = fmt.Sprintf("mTLS is not enforced in %s", opReq.Namespace)
+				if errors.GetCode(err) != ErrMTLSNotEnforcedCode {
+					ee.Summary = fmt.Sprintf("Error while verifying mTLS in %s", opReq.Namespace)
+				}
+				ee.Details = err.Error()
+				if len(lines) > 0 {
+					ee.Details = fmt.Sprintf("%s\n%s", ee.Details, strings.Join(lines, "\n"))
+				}
+				ee.ErrorCode = errors.GetCode(err)
+				ee.ProbableCause = errors.GetCause(err)
+				ee.SuggestedRemediation = errors.GetRemedy(err)
+				hh.StreamErr(ee, err)
+				return
+			}
+			ee.Summary = fmt.Sprintf("mTLS is enforced in %s", opReq.Namespace)
+			ee.Details = strings.Join(lines, "\n")
+			if len(lines) == 0 {
+				ee.Summary = fmt.Sprintf("No service of %s to verify", opReq.Namespace)
+				ee.Details = "None of the services of the namespace has an HTTP port."
+			}
+			hh.StreamInfo(ee)
+		}(istio, e)
+	case internalconfig.CleanupNamespaceOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			removed, err := hh.cleanupIstioResources(opReq.Namespace, kubeConfigs)
+			if err != nil {
+				ee.Summary = fmt.Sprintf("Error while cleaning up the Istio resources of %s", opReq.Namespace)
+				ee.Details = err.Error()
+				if len(removed) > 0 {
+					ee.Details = fmt.Sprintf("%s\nRemoved before the failure:\n%s", ee.Details, strings.Join(removed, "\n"))
+				}
+				ee.ErrorCode = errors.GetCode(err)
+				ee.ProbableCause = errors.GetCause(err)
+				ee.SuggestedRemediation = errors.GetRemedy(err)
+				hh.StreamErr(ee, err)
+				return
+			}
+			ee.Summary = fmt.Sprintf("Istio resources of %s cleaned up", opReq.Namespace)
+			ee.Details = "No Istio resource created by the adapter was left in the namespace."
+			if len(removed) > 0 {
+				ee.Details = fmt.Sprintf("Removed:\n%s", strings.Join(removed, "\n"))
+			}
+			hh.StreamInfo(ee)
+		}(istio, e)
+	case internalconfig.SwitchNamespaceRevisionOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			revision := strings.TrimSpace(operations[opReq.OperationName].AdditionalProperties[internalconfig.ToRevision])
+			restart := operations[opReq.OperationName].AdditionalProperties[internalconfig.RestartWorkloads] != "false"
+			restarted, err := hh.SwitchNamespaceRevision(ctx, opReq.Namespace, revision, restart, kubeConfigs)
+			if err != nil {
+				ee.Summary = fmt.Sprintf("Error while switching %s to revision %s", opReq.Namespace, revisionName(revision))
+				ee.Details = err.Error()
+				ee.ErrorCode = errors.GetCode(err)
+				ee.ProbableCause = errors.GetCause(err)
+				ee.SuggestedRemediation = errors.GetRemedy(err)
+				hh.StreamErr(ee, err)
+				return
+			}
+			ee.Summary = fmt.Sprintf("%s switched to revision %s", opReq.Namespace, revisionName(revision))
+			ee.Details = fmt.Sprintf("The workloads of %s namespace are injected by revision %s from now on, the running ones were left as is.", opReq.Namespace, revisionName(revision))
+			if restart {
+				ee.Details = fmt.Sprintf("%d workloads of %s namespace restarted to be injected by revision %s.", restarted, opReq.Namespace, revisionName(revision))
+			}
+			hh.StreamInfo(ee)
+		}(istio, e)
+	case internalconfig.IstioRotateCAOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			var err error
+			if opReq.IsDeleteOperation {
+				err = ErrCARotationFailed(stderrors.New("the root CA can't be removed, it can only be rotated"))
+			} else {
+				hh.StreamWarn(&meshes.EventsResponse{
+					OperationId:   ee.OperationId,
+					Component:     ee.Component,
+					ComponentName: ee.ComponentName,
+					Summary:       "Rotating the root CA restarts istiod",
+					Details:       "The proxies reconnect to istiod and get their certificates signed by the new CA as they renew them, the workloads with certificates of the old root can't reach the ones with certificates of the new root until then.",
+				}, stderrors.New("the proxies reconnect to istiod"))
+				err = hh.rotateRootCA(ctx, istioRootNamespace, opReq.CustomBody, kubeConfigs)
+			}
+			if err != nil {
+				ee.Summary = "Error while rotating the root CA"
+				ee.Details = err.Error()
+				ee.ErrorCode = errors.GetCode(err)
+				ee.ProbableCause = errors.GetCause(err)
+				ee.SuggestedRemediation = errors.GetRemedy(err)
+				hh.StreamErr(ee, err)
+				return
+			}
+			ee.Summary = "Root CA rotated successfully"
+			ee.Details = "The cacerts secret is replaced and istiod is restarted with the new CA."
+			if strings.TrimSpace(opReq.CustomBody) == "" {
+				ee.Details = "The cacerts secret is replaced with a newly generated root and istiod is restarted with it."
+			}
+			hh.StreamInfo(ee)
+		}(istio, e)
+	case internalconfig.RestartWorkloadsOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			var restarted int
+			var err error
+			if opReq.IsDeleteOperation {
+				err = ErrWorkloadRestartFailed(stderrors.New("a restart can't be undone"))
+			} else {
+				restarted, err = hh.restartInjectedWorkloads(opReq.Namespace, kubeConfigs)
+			}
+			if err != nil {
+				ee.Summary = fmt.Sprintf("Error while restarting the workloads of %s namespace", opReq.Namespace)
+				ee.Details = fmt.Sprintf("%d workloads restarted before the failure: %s", restarted, err.Error())
+				ee.ErrorCode = errors.GetCode(err)
+				ee.ProbableCause = errors.GetCause(err)
+				ee.SuggestedRemediation = errors.GetRemedy(err)
+				hh.StreamErr(ee, err)
+				return
+			}
+			ee.Summary = fmt.Sprintf("%d workloads of %s namespace restarted", restarted, opReq.Namespace)
+			ee.Details = "The deployments, statefulsets and daemonsets are rolling out with the sidecar of the current injection of the namespace."
+			hh.StreamInfo(ee)
+		}(istio, e)
+	case internalconfig.CancelOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			operationID := strings.TrimSpace(operations[opReq.OperationName].AdditionalProperties[internalconfig.CancelOperationID])
+			if !hh.running.cancel(operationID) {
+				ee.Summary = fmt.Sprintf("No running operation %s to cancel", operationID)
+				ee.Details = "The operation is already done or its ID is unknown."
+				hh.StreamWarn(ee, fmt.Errorf("operation %q is not running", operationID))
+				return
+			}
+			ee.Summary = fmt.Sprintf("Operation %s cancelled", operationID)
+			ee.Details = "The operation stops at its next step and reports the steps it didn't complete."
+			hh.StreamInfo(ee)
+		}(istio, e)
+	case internalconfig.OperationStatusOperation:
+		go func(hh *Istio, ee *meshes.EventsResponse) {
+			defer done()
+			operationID := strings.TrimSpace(operations[opReq.OperationName].AdditionalProperties[internalconfig.CancelOperationID])
+			st, ok := hh.GetOperationStatus(operationID)
+			if !ok {
+				ee.Summary = fmt.Sprintf("No status of operation %s", operationID)
+				ee.Details = "The operation ID is unknown or the operation was last updated too long ago."
+				hh.StreamWarn(ee, fmt.Errorf("no status of operation %q", operationID))
+				return
+			}
+			ee.Summary = fmt.Sprintf("Operation %s running: %s", operationID, st.Summary)
+			if st.Done {
+				ee.Summary = fmt.Sprintf("Operation %s done: %s", operationID, st.Summary)
+			}
+			ee.Details = operationStatusDetails(st)
 			hh.StreamInfo(ee)
 		}(istio, e)
 	default:
 		istio.StreamErr(e, ErrOpInvalid)
+		done()
 	}
 
 	return nil
 }
 
-// CreateKubeconfigs creates and writes passed kubeconfig onto the filesystem
-func (istio *Istio) CreateKubeconfigs(kubeconfigs []string) error {
+// CreateKubeconfigs creates and writes passed kubeconfig onto the filesystem.
+// The clusters, users and contexts of all the kubeconfigs are merged into
+// the written kubeconfig, the current-context is the one of the first
+// kubeconfig.
+// contexts holds the context to use for each kubeconfig, in the same order,
+// instead of its current-context, an empty context keeps the current-context.
+// The kubeconfigs are returned with the chosen contexts set as their
+// current-context. The kubeconfigs which can't be parsed are reported with
+// ErrInvalidKubeconfig, identifying them by index, and the failures to
+// write the merged kubeconfig with ErrWriteKubeconfig.
+//
+// The merged kubeconfig is written as a whole, the concurrent operations
+// each writing their own in turn. Since it only holds the clusters of the
+// last operation, the operations only rely on the returned kubeconfigs,
+// istioctl being run with the kubeconfig of its cluster, see
+// runIstioctlOnCluster.
+func (istio *Istio) CreateKubeconfigs(kubeconfigs []string, contexts []string) ([]string, error) {
 	var errs = make([]error, 0)
-	for _, kubeconfig := range kubeconfigs {
-		kconfig := models.Kubeconfig{}
-		err := yaml.Unmarshal([]byte(kubeconfig), &kconfig)
-		if err != nil {
-			errs = append(errs, err)
-			continue
+	var merged models.Kubeconfig
+	selected := make([]string, 0, len(kubeconfigs))
+	for i, kubeconfig := range kubeconfigs {
+		var kubeContext string
+		if i < len(contexts) {
+			kubeContext = strings.TrimSpace(contexts[i])
 		}
-
-		// To have control over what exactly to take in on kubeconfig
-		istio.KubeconfigHandler.SetKey("kind", kconfig.Kind)
-		istio.KubeconfigHandler.SetKey("apiVersion", kconfig.APIVersion)
-		istio.KubeconfigHandler.SetKey("current-context", kconfig.CurrentContext)
-		err = istio.KubeconfigHandler.SetObject("preferences", kconfig.Preferences)
-		if err != nil {
-			errs = append(errs, err)
-			continue
+		if kubeContext != "" {
+			var err error
+			kubeconfig, err = useKubeContext(kubeconfig, kubeContext)
+			if err != nil {
+				if _, ok := errors.Is(err); !ok {
+					err = ErrInvalidKubeconfig(i, kubeContext, err)
+				}
+				errs = append(errs, err)
+				continue
+			}
 		}
+		selected = append(selected, kubeconfig)
 
-		err = istio.KubeconfigHandler.SetObject("clusters", kconfig.Clusters)
+		kconfig := models.Kubeconfig{}
+		err := yaml.Unmarshal([]byte(kubeconfig), &kconfig)
 		if err != nil {
-			errs = append(errs, err)
+			errs = append(errs, ErrInvalidKubeconfig(i, kubeContext, err))
 			continue
 		}
+		mergeKubeconfig(&merged, kconfig)
+	}
 
-		err = istio.KubeconfigHandler.SetObject("users", kconfig.Users)
-		if err != nil {
-			errs = append(errs, err)
-			continue
+	// To have control over what exactly to take in on kubeconfig
+	istio.kubeconfigMx.Lock()
+	defer istio.kubeconfigMx.Unlock()
+	istio.KubeconfigHandler.SetKey("kind", merged.Kind)
+	istio.KubeconfigHandler.SetKey("apiVersion", merged.APIVersion)
+	istio.KubeconfigHandler.SetKey("current-context", merged.CurrentContext)
+	for _, object := range []struct {
+		key   string
+		value interface{}
+	}{
+		{"preferences", merged.Preferences},
+		{"clusters", merged.Clusters},
+		{"users", merged.Users},
+		{"contexts", merged.Contexts},
+	} {
+		if err := istio.KubeconfigHandler.SetObject(object.key, object.value); err != nil {
+			errs = append(errs, ErrWriteKubeconfig(object.key, err))
 		}
+	}
+	return selected, kubeconfigErrors(errs)
+}
 
-		err = istio.KubeconfigHandler.SetObject("contexts", kconfig.Contexts)
-		if err != nil {
-			errs = append(errs, err)
-			continue
-		}
+// resolveVersion returns the requested version if it is one of the available
+// versions of the operation, the latest available version otherwise
+func resolveVersion(versions []adapter.Version, requested adapter.Version) (string, error) {
+	if len(versions) == 0 {
+		return "", ErrFetchIstioVersions
 	}
-	if len(errs) == 0 {
-		return nil
+	if utils.Contains[[]adapter.Version, adapter.Version](versions, requested) {
+		return requested.String(), nil
 	}
-	return mergeErrors(errs)
+	return string(versions[len(versions)-1]), nil
+}
+
+// clusterName returns the current context of the given kubeconfig which is
+// used to identify the cluster in the event details
+func clusterName(kubeconfig string) string {
+	kconfig := models.Kubeconfig{}
+	if err := yaml.Unmarshal([]byte(kubeconfig), &kconfig); err != nil || kconfig.CurrentContext == "" {
+		return "unknown"
+	}
+	return kconfig.CurrentContext
 }
 
 // ProcessOAM will handles the grpc invocation for handling OAM objects
 func (istio *Istio) ProcessOAM(ctx context.Context, oamReq adapter.OAMRequest) (string, error) {
-	err := istio.CreateKubeconfigs(oamReq.K8sConfigs)
+	kubeconfigs, err := istio.CreateKubeconfigs(oamReq.K8sConfigs, nil)
 	if err != nil {
 		return "", err
 	}
-	kubeconfigs := oamReq.K8sConfigs
 	var comps []v1alpha1.Component
 	for _, acomp := range oamReq.OamComps {
 		comp, configErr := oam.ParseApplicationComponent(acomp)
 		if configErr != nil {
-			istio.Log.Error(ErrParseOAMComponent)
+			istio.Log.Error(ErrParseOAMComponent(comp.Name, configErr))
 			continue
 		}
 		comps = append(comps, comp)
@@ -334,6 +2098,11 @@ func (istio *Istio) ProcessOAM(ctx context.Context, oamReq adapter.OAMRequest) (
 		return msg1 + "\n" + msg2, nil
 	}
 
+	// Validate the components before anything is applied
+	if err := istio.validateComponents(comps); err != nil {
+		return "", ErrProcessOAM(err)
+	}
+
 	// Process components
 	msg1, err := istio.HandleComponents(comps, oamReq.DeleteOp, kubeconfigs)
 	if err != nil {
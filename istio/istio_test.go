@@ -355,6 +355,32 @@ func TestIstio_ApplyOperation(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "Loki Addon operation",
+			args: args{
+				ctx: context.TODO(),
+				opReq: adapter.OperationRequest{
+					OperationName:     internalconfig.LokiAddon,
+					Namespace:         "default",
+					IsDeleteOperation: false,
+					OperationID:       "test_id",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Tempo Addon operation",
+			args: args{
+				ctx: context.TODO(),
+				opReq: adapter.OperationRequest{
+					OperationName:     internalconfig.TempoAddon,
+					Namespace:         "default",
+					IsDeleteOperation: false,
+					OperationID:       "test_id",
+				},
+			},
+			wantErr: false,
+		},
 		// TODO: Add test cases.
 	}
 	for _, tt := range tests {
@@ -0,0 +1,284 @@
+package istio
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/layer5io/meshery-istio/internal/config"
+)
+
+var (
+	// istioctlCacheRoot holds a directory per istioctl version, each with the
+	// release archive, its published checksum and the extracted binary
+	istioctlCacheRoot = path.Join(config.RootPath(), "bin", "istioctl-cache")
+
+	istioReleaseURL = "https://github.com/istio/istio/releases/download"
+
+	// istioctlCacheLocks holds a *sync.Mutex per version, serializing the
+	// cache updates of a version so that concurrent operations don't download
+	// it twice, while the other versions remain available
+	istioctlCacheLocks sync.Map
+)
+
+// getIstioctl returns the path to the cached istioctl binary of the version,
+// downloading it first if it isn't cached yet.
+//
+// The release archive is verified against the checksum published with it
+// before the binary is extracted, and the checksum of the extracted binary is
+// recorded so that a corrupted binary is extracted again, or downloaded again
+// if the archive is corrupted as well, before it is reused.
+func (istio *Istio) getIstioctl(version string) (string, error) {
+	mx, _ := istioctlCacheLocks.LoadOrStore(version, &sync.Mutex{})
+	mx.(*sync.Mutex).Lock()
+	defer mx.(*sync.Mutex).Unlock()
+
+	archiveName, err := istioctlArchiveName(version, platform, arch)
+	if err != nil {
+		return "", ErrIstioctlCache(err)
+	}
+	dir := path.Join(istioctlCacheRoot, version)
+	binary := path.Join(dir, generatePlatformSpecificBinaryName("istioctl", platform))
+	archive := path.Join(dir, archiveName)
+
+	if matchesChecksumFile(binary) {
+		return binary, nil
+	}
+
+	if !matchesChecksumFile(archive) {
+		istio.Log.Info(fmt.Sprintf("Downloading istioctl %s...", version))
+		if err := os.MkdirAll(dir, 0750); err != nil {
+			return "", ErrIstioctlCache(err)
+		}
+		if err := downloadIstioctl(fmt.Sprintf("%s/%s/%s", istioReleaseURL, version, archiveName), archive); err != nil {
+			return "", ErrIstioctlCache(err)
+		}
+	}
+
+	istio.Log.Info(fmt.Sprintf("Extracting istioctl %s to %s...", version, dir))
+	if err := extractIstioctl(archive, binary); err != nil {
+		return "", ErrIstioctlCache(err)
+	}
+	return binary, nil
+}
+
+// istioctlArchiveName returns the name of the standalone istioctl archive
+// published for the platform
+func istioctlArchiveName(version, platform, arch string) (string, error) {
+	switch platform {
+	case "linux":
+		return fmt.Sprintf("istioctl-%s-linux-%s.tar.gz", version, arch), nil
+	case "darwin":
+		if arch == "arm64" {
+			return fmt.Sprintf("istioctl-%s-osx-arm64.tar.gz", version), nil
+		}
+		return fmt.Sprintf("istioctl-%s-osx.tar.gz", version), nil
+	case "windows":
+		return fmt.Sprintf("istioctl-%s-win.zip", version), nil
+	}
+	return "", ErrUnsupportedPlatform
+}
+
+// downloadIstioctl downloads the archive and its published checksum, the
+// archive is kept only if it matches the checksum
+func downloadIstioctl(url, archive string) error {
+	sum, err := httpGet(url + ".sha256")
+	if err != nil {
+		return err
+	}
+	want, err := parseChecksum(string(sum))
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.Get(url)
+	if err != nil {
+		return ErrDownloadingTar(err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return ErrDownloadingTar(fmt.Errorf("%s returned status %s", url, resp.Status))
+	}
+
+	tmp, err := os.CreateTemp(path.Dir(archive), ".download-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, h), resp.Body); err != nil {
+		_ = tmp.Close()
+		return ErrDownloadingTar(err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); got != want {
+		return fmt.Errorf("checksum mismatch for %s: got %s, published %s", path.Base(archive), got, want)
+	}
+
+	if err := os.Rename(tmp.Name(), archive); err != nil {
+		return err
+	}
+	return os.WriteFile(archive+".sha256", sum, 0600)
+}
+
+// extractIstioctl extracts the istioctl binary from the archive and records
+// its checksum next to it
+func extractIstioctl(archive, binary string) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	tmp, err := os.CreateTemp(path.Dir(binary), ".istioctl-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	h := sha256.New()
+	w := io.MultiWriter(tmp, h)
+
+	name := path.Base(binary)
+	var found bool
+	if strings.HasSuffix(archive, ".zip") {
+		found, err = copyFromZip(f, name, w)
+	} else {
+		found, err = copyFromTarGz(f, name, w)
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("%s not found in %s", name, path.Base(archive))
+	}
+
+	// istioctl binary needs to be executable
+	if err := os.Chmod(tmp.Name(), 0750); err != nil {
+		return ErrMakingBinExecutable(err)
+	}
+	// Renaming keeps the running istioctl processes of the previous binary intact
+	if err := os.Rename(tmp.Name(), binary); err != nil {
+		return err
+	}
+	return os.WriteFile(binary+".sha256", []byte(fmt.Sprintf("%s  %s\n", hex.EncodeToString(h.Sum(nil)), name)), 0600)
+}
+
+func copyFromTarGz(r io.Reader, name string, w io.Writer) (bool, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return false, ErrTarXZF(err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return false, ErrTarXZF(err)
+		}
+		if header.Typeflag != tar.TypeReg || path.Base(header.Name) != name {
+			continue
+		}
+		// Trust istioctl tar hence
+		// #nosec
+		if _, err := io.Copy(w, tr); err != nil {
+			return false, ErrTarXZF(err)
+		}
+		return true, nil
+	}
+}
+
+func copyFromZip(f *os.File, name string, w io.Writer) (bool, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return false, err
+	}
+	zr, err := zip.NewReader(f, info.Size())
+	if err != nil {
+		return false, ErrUnzipFile(err)
+	}
+	for _, file := range zr.File {
+		if file.FileInfo().IsDir() || path.Base(file.Name) != name {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return false, ErrUnzipFile(err)
+		}
+		defer rc.Close()
+		// Trust istio zip hence,
+		// #nosec
+		if _, err := io.Copy(w, rc); err != nil {
+			return false, ErrUnzipFile(err)
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// matchesChecksumFile reports whether the file exists and matches the
+// checksum recorded in the file of the same name with the .sha256 suffix
+func matchesChecksumFile(file string) bool {
+	sum, err := os.ReadFile(file + ".sha256")
+	if err != nil {
+		return false
+	}
+	want, err := parseChecksum(string(sum))
+	if err != nil {
+		return false
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return false
+	}
+	return hex.EncodeToString(h.Sum(nil)) == want
+}
+
+// parseChecksum parses a checksum file in the sha256sum format
+func parseChecksum(sum string) (string, error) {
+	fields := strings.Fields(sum)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("empty checksum")
+	}
+	checksum := strings.ToLower(fields[0])
+	if _, err := hex.DecodeString(checksum); err != nil || len(checksum) != sha256.Size*2 {
+		return "", fmt.Errorf("invalid sha256 checksum %q", fields[0])
+	}
+	return checksum, nil
+}
+
+func httpGet(url string) ([]byte, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned status %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,145 @@
+package istio
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+)
+
+func TestIstio_getIstioctl(t *testing.T) {
+	if platform == "windows" {
+		t.Skip("the test release server publishes tar.gz archives only")
+	}
+	const version = "1.20.0"
+	archiveName, err := istioctlArchiveName(version, platform, arch)
+	if err != nil {
+		t.Skip(err)
+	}
+
+	archive := tarGz(t, "istioctl", []byte("#!/bin/sh\necho "+version+"\n"))
+	sum := sha256.Sum256(archive)
+	published := fmt.Sprintf("%s  %s\n", hex.EncodeToString(sum[:]), archiveName)
+	downloads := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case fmt.Sprintf("/%s/%s", version, archiveName):
+			downloads++
+			_, _ = w.Write(archive)
+		case fmt.Sprintf("/%s/%s.sha256", version, archiveName):
+			_, _ = w.Write([]byte(published))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	defer func(root, url string) {
+		istioctlCacheRoot, istioReleaseURL = root, url
+	}(istioctlCacheRoot, istioReleaseURL)
+	istioctlCacheRoot = t.TempDir()
+	istioReleaseURL = srv.URL
+
+	istio := &Istio{Adapter: adapter.Adapter{Log: getLoggerHandler(t)}}
+
+	binary, err := istio.getIstioctl(version)
+	if err != nil {
+		t.Fatalf("getIstioctl() error = %v", err)
+	}
+	if downloads != 1 {
+		t.Fatalf("getIstioctl() downloaded %d times, want 1", downloads)
+	}
+
+	if _, err := istio.getIstioctl(version); err != nil || downloads != 1 {
+		t.Errorf("getIstioctl() of cached version error = %v, downloads = %d, want no download", err, downloads)
+	}
+
+	// A corrupted binary is extracted again from the cached archive
+	if err := os.WriteFile(binary, []byte("corrupted"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := istio.getIstioctl(version); err != nil || downloads != 1 {
+		t.Errorf("getIstioctl() of corrupted binary error = %v, downloads = %d, want no download", err, downloads)
+	}
+	if !matchesChecksumFile(binary) {
+		t.Errorf("getIstioctl() didn't restore the corrupted binary")
+	}
+
+	// A corrupted archive is downloaded again
+	if err := os.WriteFile(binary, []byte("corrupted"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path.Join(istioctlCacheRoot, version, archiveName), []byte("corrupted"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := istio.getIstioctl(version); err != nil || downloads != 2 {
+		t.Errorf("getIstioctl() of corrupted archive error = %v, downloads = %d, want 2", err, downloads)
+	}
+
+	// An archive which doesn't match the published checksum is rejected
+	if err := os.RemoveAll(path.Join(istioctlCacheRoot, version)); err != nil {
+		t.Fatal(err)
+	}
+	published = fmt.Sprintf("%064x  %s\n", 0, archiveName)
+	if _, err := istio.getIstioctl(version); err == nil {
+		t.Errorf("getIstioctl() with checksum mismatch succeeded, want error")
+	}
+	if _, err := os.Stat(path.Join(istioctlCacheRoot, version, archiveName)); !os.IsNotExist(err) {
+		t.Errorf("getIstioctl() kept the archive which doesn't match the published checksum")
+	}
+}
+
+func TestParseChecksum(t *testing.T) {
+	valid := "7e1b5a6a4f3c0b1d9e4a8c2f6d0b3a5e7c9f1d2b4a6c8e0f2a4b6c8d0e2f4a6b"
+	tests := []struct {
+		name    string
+		sum     string
+		want    string
+		wantErr bool
+	}{
+		{name: "sha256sum format", sum: valid + "  istioctl-1.20.0-linux-amd64.tar.gz\n", want: valid},
+		{name: "checksum only", sum: valid, want: valid},
+		{name: "empty", sum: "\n", wantErr: true},
+		{name: "not a sha256", sum: "abc  istioctl.tar.gz", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseChecksum(tt.sum)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseChecksum() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseChecksum() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func tarGz(t *testing.T, name string, contents []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0750, Size: int64(len(contents)), Typeflag: tar.TypeReg}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write(contents); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
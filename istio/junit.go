@@ -0,0 +1,209 @@
+package istio
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/layer5io/meshery-adapter-library/meshes"
+	"github.com/layer5io/meshkit/errors"
+)
+
+// JUnitFormat is the value of the result format property which makes the
+// operation stream its results as JUnit XML
+const JUnitFormat = "junit"
+
+// testResult is the outcome of a single test case of a pass/fail operation
+type testResult struct {
+	Suite    string
+	Name     string
+	Passed   bool
+	Errored  bool
+	Message  string
+	Duration time.Duration
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// toJUnit renders the test results as a JUnit XML document, grouping the
+// test cases into one test suite per result suite
+func toJUnit(name string, results []testResult) (string, error) {
+	timestamp := time.Now().UTC().Format(time.RFC3339)
+	doc := junitTestSuites{Name: name}
+	suites := make(map[string]int)
+	var total time.Duration
+	for _, r := range results {
+		idx, ok := suites[r.Suite]
+		if !ok {
+			doc.Suites = append(doc.Suites, junitTestSuite{Name: r.Suite, Timestamp: timestamp})
+			idx = len(doc.Suites) - 1
+			suites[r.Suite] = idx
+		}
+		suite := &doc.Suites[idx]
+
+		tc := junitTestCase{
+			Name:      r.Name,
+			Classname: fmt.Sprintf("%s.%s", name, r.Suite),
+			Time:      junitSeconds(r.Duration),
+		}
+		switch {
+		case r.Errored:
+			tc.Error = &junitMessage{Message: firstLine(r.Message), Text: r.Message}
+			suite.Errors++
+			doc.Errors++
+		case !r.Passed:
+			tc.Failure = &junitMessage{Message: firstLine(r.Message), Text: r.Message}
+			suite.Failures++
+			doc.Failures++
+		}
+		suite.Cases = append(suite.Cases, tc)
+		suite.Tests++
+		doc.Tests++
+		total += r.Duration
+	}
+	for i := range doc.Suites {
+		var d time.Duration
+		for _, r := range results {
+			if r.Suite == doc.Suites[i].Name {
+				d += r.Duration
+			}
+		}
+		doc.Suites[i].Time = junitSeconds(d)
+	}
+	doc.Time = junitSeconds(total)
+
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return xml.Header + string(out), nil
+}
+
+// smiTestResults converts the SMI conformance response into test results
+func smiTestResults(resp adapter.Response) []testResult {
+	var results []testResult
+	for _, d := range resp.MoreDetails {
+		if d == nil {
+			continue
+		}
+		duration, _ := time.ParseDuration(d.Time)
+		message := d.Result
+		if d.Reason != "" {
+			message = fmt.Sprintf("%s\n%s", d.Result, d.Reason)
+		}
+		results = append(results, testResult{
+			Suite:    fmt.Sprintf("%s-%s", d.SmiSpecification, d.SmiVersion),
+			Name:     d.Assertions,
+			Passed:   d.Status == "PASSED",
+			Message:  message,
+			Duration: duration,
+		})
+	}
+	return results
+}
+
+// vetTestResult converts an istio-vet event into a test result, vetters
+// which generated warning or error notes are reported as failures
+func vetTestResult(e *meshes.EventsResponse, duration time.Duration) testResult {
+	return testResult{
+		Suite:    "istio-vet",
+		Name:     e.Summary,
+		Passed:   e.EventType == meshes.EventType_INFO,
+		Errored:  strings.HasPrefix(e.Summary, "Vetter:") && strings.HasSuffix(e.Summary, "reported error"),
+		Message:  e.Details,
+		Duration: duration,
+	}
+}
+
+// analyzeTestResults converts the istioctl analyze messages into test
+// results, the error messages are failures and so are the warnings if
+// failOnWarning is set. A passing test case is added for the clusters which
+// have no message.
+func analyzeTestResults(messages []AnalyzerMessage, clusters []string, failOnWarning bool) []testResult {
+	var results []testResult
+	reported := make(map[string]bool)
+	for _, m := range messages {
+		reported[m.Cluster] = true
+		results = append(results, testResult{
+			Suite:   fmt.Sprintf("istioctl-analyze-%s", m.Cluster),
+			Name:    fmt.Sprintf("%s %s", m.Code, m.Origin),
+			Passed:  m.Level == AnalyzerLevelInfo || (m.Level == AnalyzerLevelWarning && !failOnWarning),
+			Message: m.String(),
+		})
+	}
+	for _, cluster := range clusters {
+		if !reported[cluster] {
+			results = append(results, testResult{
+				Suite:  fmt.Sprintf("istioctl-analyze-%s", cluster),
+				Name:   "No validation issues found",
+				Passed: true,
+			})
+		}
+	}
+	return results
+}
+
+// streamJUnit streams the test results of an operation as a JUnit XML
+// document in the event details
+func (istio *Istio) streamJUnit(ee *meshes.EventsResponse, name string, results []testResult) {
+	e := &meshes.EventsResponse{
+		OperationId:   ee.OperationId,
+		Component:     ee.Component,
+		ComponentName: ee.ComponentName,
+	}
+	report, err := toJUnit(name, results)
+	if err != nil {
+		err = ErrJUnitReport(err)
+		e.Summary = fmt.Sprintf("Error while generating JUnit report for %s", name)
+		e.Details = err.Error()
+		e.ErrorCode = errors.GetCode(err)
+		e.ProbableCause = errors.GetCause(err)
+		e.SuggestedRemediation = errors.GetRemedy(err)
+		istio.StreamErr(e, err)
+		return
+	}
+	e.Summary = fmt.Sprintf("JUnit report for %s", name)
+	e.Details = report
+	istio.StreamInfo(e)
+}
+
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+func firstLine(s string) string {
+	return strings.SplitN(s, "\n", 2)[0]
+}
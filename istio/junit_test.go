@@ -0,0 +1,98 @@
+package istio
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+)
+
+func TestToJUnit(t *testing.T) {
+	tests := []struct {
+		name         string
+		results      []testResult
+		wantTests    int
+		wantFailures int
+		wantErrors   int
+		wantSuites   int
+	}{
+		{
+			name:       "no results",
+			results:    nil,
+			wantSuites: 0,
+		},
+		{
+			name: "mixed results",
+			results: []testResult{
+				{Suite: "traffic-access", Name: "allow", Passed: true, Duration: 1500 * time.Millisecond},
+				{Suite: "traffic-access", Name: "deny", Passed: false, Message: "request was allowed\nexpected 403"},
+				{Suite: "traffic-split", Name: "weights", Errored: true, Message: "timed out <waiting> & gave up"},
+			},
+			wantTests:    3,
+			wantFailures: 1,
+			wantErrors:   1,
+			wantSuites:   2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := toJUnit("SMI Conformance", tt.results)
+			if err != nil {
+				t.Fatalf("toJUnit() error = %v", err)
+			}
+			var got junitTestSuites
+			if err := xml.Unmarshal([]byte(report), &got); err != nil {
+				t.Fatalf("toJUnit() generated invalid XML: %v", err)
+			}
+			if got.Tests != tt.wantTests || got.Failures != tt.wantFailures || got.Errors != tt.wantErrors {
+				t.Errorf("toJUnit() tests/failures/errors = %d/%d/%d, want %d/%d/%d", got.Tests, got.Failures, got.Errors, tt.wantTests, tt.wantFailures, tt.wantErrors)
+			}
+			if len(got.Suites) != tt.wantSuites {
+				t.Errorf("toJUnit() suites = %d, want %d", len(got.Suites), tt.wantSuites)
+			}
+			for _, suite := range got.Suites {
+				for _, tc := range suite.Cases {
+					if tc.Time == "" {
+						t.Errorf("toJUnit() test case %s has no time", tc.Name)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestAnalyzeTestResults(t *testing.T) {
+	messages := []AnalyzerMessage{
+		{Cluster: "east", Code: "IST0101", Level: AnalyzerLevelError, Origin: "VirtualService default/reviews", Message: "Referenced host not found"},
+		{Cluster: "east", Code: "IST0102", Level: AnalyzerLevelInfo, Origin: "Namespace default", Message: "The namespace is not enabled for Istio injection"},
+		{Cluster: "east", Code: "IST0118", Level: AnalyzerLevelWarning, Origin: "Service default/ratings", Message: "Port name is not following the naming convention"},
+	}
+	tests := []struct {
+		name          string
+		failOnWarning bool
+		wantFailures  int
+	}{
+		{name: "warnings pass", failOnWarning: false, wantFailures: 1},
+		{name: "warnings fail", failOnWarning: true, wantFailures: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := analyzeTestResults(messages, []string{"east", "west"}, tt.failOnWarning)
+			// One case per message and a passing case for west
+			if len(results) != 4 {
+				t.Fatalf("analyzeTestResults() = %d results, want 4", len(results))
+			}
+			failures := 0
+			for _, r := range results {
+				if !r.Passed {
+					failures++
+				}
+			}
+			if failures != tt.wantFailures {
+				t.Errorf("analyzeTestResults() failures = %d, want %d", failures, tt.wantFailures)
+			}
+			if last := results[3]; last.Suite != "istioctl-analyze-west" || !last.Passed {
+				t.Errorf("analyzeTestResults() west result = %+v, want a passing case", last)
+			}
+		})
+	}
+}
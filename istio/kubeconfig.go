@@ -0,0 +1,129 @@
+package istio
+
+import (
+	"os"
+
+	"github.com/layer5io/meshkit/models"
+	"gopkg.in/yaml.v2"
+)
+
+// useKubeContext returns the kubeconfig with the context set as its
+// current-context, the rest of the kubeconfig is kept as is
+func useKubeContext(kubeconfig, context string) (string, error) {
+	kconfig := models.Kubeconfig{}
+	if err := yaml.Unmarshal([]byte(kubeconfig), &kconfig); err != nil {
+		return "", err
+	}
+	var available []string
+	found := false
+	for _, c := range kconfig.Contexts {
+		available = append(available, c.Name)
+		if c.Name == context {
+			found = true
+		}
+	}
+	if !found {
+		return "", ErrInvalidKubeContext(context, available)
+	}
+
+	var config yaml.MapSlice
+	if err := yaml.Unmarshal([]byte(kubeconfig), &config); err != nil {
+		return "", err
+	}
+	set := false
+	for i := range config {
+		if config[i].Key == "current-context" {
+			config[i].Value = context
+			set = true
+		}
+	}
+	if !set {
+		config = append(config, yaml.MapItem{Key: "current-context", Value: context})
+	}
+	out, err := yaml.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// mergeKubeconfig adds the clusters, users and contexts of the kubeconfig to
+// the merged kubeconfig. The entries whose name is already in the merged
+// kubeconfig are skipped, so the first kubeconfig defining a name wins.
+func mergeKubeconfig(merged *models.Kubeconfig, kconfig models.Kubeconfig) {
+	if merged.Kind == "" {
+		merged.Kind = kconfig.Kind
+	}
+	if merged.APIVersion == "" {
+		merged.APIVersion = kconfig.APIVersion
+	}
+	if merged.CurrentContext == "" {
+		merged.CurrentContext = kconfig.CurrentContext
+	}
+
+	clusters := map[string]bool{}
+	for _, c := range merged.Clusters {
+		clusters[c.Name] = true
+	}
+	for _, c := range kconfig.Clusters {
+		if !clusters[c.Name] {
+			clusters[c.Name] = true
+			merged.Clusters = append(merged.Clusters, c)
+		}
+	}
+
+	users := map[string]bool{}
+	for _, u := range merged.Users {
+		users[u.Name] = true
+	}
+	for _, u := range kconfig.Users {
+		if !users[u.Name] {
+			users[u.Name] = true
+			merged.Users = append(merged.Users, u)
+		}
+	}
+
+	contexts := map[string]bool{}
+	for _, c := range merged.Contexts {
+		contexts[c.Name] = true
+	}
+	for _, c := range kconfig.Contexts {
+		if !contexts[c.Name] {
+			contexts[c.Name] = true
+			merged.Contexts = append(merged.Contexts, c)
+		}
+	}
+}
+
+// kubeconfigErrors returns the error of a single kubeconfig as is, so that
+// its code is reported, and ErrKubeconfigs listing them all otherwise
+func kubeconfigErrors(errs []error) error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+	return ErrKubeconfigs(errs)
+}
+
+// writeKubeconfigFile writes the kubeconfig of a single cluster to a
+// temporary file and returns its path, for the commands to be run against
+// the cluster rather than the kubeconfig shared by the operations. The caller
+// removes the file.
+func writeKubeconfigFile(kubeconfig string) (string, error) {
+	file, err := os.CreateTemp("", "istio-kubeconfig-*.yaml")
+	if err != nil {
+		return "", err
+	}
+	if _, err := file.WriteString(kubeconfig); err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		_ = os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
@@ -0,0 +1,235 @@
+package istio
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	configprovider "github.com/layer5io/meshery-adapter-library/config/provider"
+	internalconfig "github.com/layer5io/meshery-istio/internal/config"
+	"github.com/layer5io/meshkit/errors"
+	"gopkg.in/yaml.v2"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: dev
+  cluster:
+    server: https://dev.example.com
+- name: prod
+  cluster:
+    server: https://prod.example.com
+contexts:
+- name: dev
+  context:
+    cluster: dev
+    user: admin
+- name: prod
+  context:
+    cluster: prod
+    user: admin
+current-context: dev
+users:
+- name: admin
+  user:
+    token: secret
+`
+
+func TestUseKubeContext(t *testing.T) {
+	got, err := useKubeContext(testKubeconfig, "prod")
+	if err != nil {
+		t.Fatalf("useKubeContext() error = %v", err)
+	}
+	if cluster := clusterName(got); cluster != "prod" {
+		t.Errorf("useKubeContext() current-context = %s, want prod", cluster)
+	}
+	var kconfig map[string]interface{}
+	if err := yaml.Unmarshal([]byte(got), &kconfig); err != nil {
+		t.Fatal(err)
+	}
+	if users, _ := kconfig["users"].([]interface{}); len(users) != 1 {
+		t.Errorf("useKubeContext() dropped the users of the kubeconfig: %s", got)
+	}
+
+	_, err = useKubeContext(testKubeconfig, "staging")
+	if err == nil {
+		t.Fatalf("useKubeContext() of an unknown context succeeded, want error")
+	}
+	if code := errors.GetCode(err); code != ErrInvalidKubeContextCode {
+		t.Errorf("useKubeContext() error code = %s, want %s", code, ErrInvalidKubeContextCode)
+	}
+}
+
+// clusterKubeconfig returns the kubeconfig of a single cluster named after
+// its context
+func clusterKubeconfig(name string) string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: %[1]s
+  cluster:
+    server: https://%[1]s.example.com
+contexts:
+- name: %[1]s
+  context:
+    cluster: %[1]s
+    user: admin
+current-context: %[1]s
+users:
+- name: admin
+`, name)
+}
+
+func TestCreateKubeconfigs(t *testing.T) {
+	kc, err := internalconfig.NewKubeconfigBuilder(configprovider.InMemKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	istio := &Istio{Adapter: adapter.Adapter{KubeconfigHandler: kc}}
+
+	var kubeconfigs []string
+	for _, name := range []string{"east", "west", "central"} {
+		kubeconfigs = append(kubeconfigs, clusterKubeconfig(name))
+	}
+	if _, err := istio.CreateKubeconfigs(kubeconfigs, nil); err != nil {
+		t.Fatalf("CreateKubeconfigs() error = %v", err)
+	}
+
+	var clusters, contexts, users []struct {
+		Name string `json:"name"`
+	}
+	for key, v := range map[string]interface{}{"clusters": &clusters, "contexts": &contexts, "users": &users} {
+		if err := kc.GetObject(key, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(clusters) != 3 || clusters[0].Name != "east" || clusters[1].Name != "west" || clusters[2].Name != "central" {
+		t.Errorf("CreateKubeconfigs() clusters = %v, want east, west and central", clusters)
+	}
+	if len(contexts) != 3 {
+		t.Errorf("CreateKubeconfigs() contexts = %v, want east, west and central", contexts)
+	}
+	if len(users) != 1 {
+		t.Errorf("CreateKubeconfigs() users = %v, want the admin user once", users)
+	}
+	if got := kc.GetKey("current-context"); got != "east" {
+		t.Errorf("CreateKubeconfigs() current-context = %s, want east", got)
+	}
+}
+
+func TestCreateKubeconfigsConcurrently(t *testing.T) {
+	kc, err := internalconfig.NewKubeconfigBuilder(configprovider.InMemKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	istio := &Istio{Adapter: adapter.Adapter{KubeconfigHandler: kc}}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 2)
+	for _, name := range []string{"east", "west"} {
+		wg.Add(1)
+		go func(name, other string) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				selected, err := istio.CreateKubeconfigs([]string{clusterKubeconfig(name)}, nil)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if len(selected) != 1 || clusterName(selected[0]) != name {
+					errs <- fmt.Errorf("operation on %s got the kubeconfigs %v", name, selected)
+					return
+				}
+				// The kubeconfig istioctl is run with
+				file, err := writeKubeconfigFile(selected[0])
+				if err != nil {
+					errs <- err
+					return
+				}
+				contents, err := os.ReadFile(file)
+				_ = os.Remove(file)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if strings.Contains(string(contents), other) {
+					errs <- fmt.Errorf("operation on %s sees the cluster %s:\n%s", name, other, contents)
+					return
+				}
+			}
+		}(name, map[string]string{"east": "west", "west": "east"}[name])
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	// The shared kubeconfig is the one of either operation as a whole
+	var clusters []struct {
+		Name string `json:"name"`
+	}
+	if err := kc.GetObject("clusters", &clusters); err != nil {
+		t.Fatal(err)
+	}
+	if current := kc.GetKey("current-context"); len(clusters) != 1 || clusters[0].Name != current {
+		t.Errorf("CreateKubeconfigs() clusters = %v with current-context %s, want the cluster of a single operation", clusters, current)
+	}
+}
+
+func TestCreateKubeconfigsErrors(t *testing.T) {
+	kc, err := internalconfig.NewKubeconfigBuilder(configprovider.InMemKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	istio := &Istio{Adapter: adapter.Adapter{KubeconfigHandler: kc}}
+
+	tests := []struct {
+		name        string
+		kubeconfigs []string
+		contexts    []string
+		wantCode    string
+		wantDetails []string
+	}{
+		{
+			name:        "malformed kubeconfig",
+			kubeconfigs: []string{testKubeconfig, "clusters: [unterminated"},
+			wantCode:    ErrInvalidKubeconfigCode,
+			wantDetails: []string{"kubeconfig at index 1"},
+		},
+		{
+			name:        "unknown context",
+			kubeconfigs: []string{testKubeconfig},
+			contexts:    []string{"staging"},
+			wantCode:    ErrInvalidKubeContextCode,
+			wantDetails: []string{`"staging"`},
+		},
+		{
+			name:        "several failures",
+			kubeconfigs: []string{"clusters: [unterminated", testKubeconfig, "users: {"},
+			contexts:    []string{"", "staging", "prod"},
+			wantCode:    ErrKubeconfigsCode,
+			wantDetails: []string{"kubeconfig at index 0 can't be parsed", `"staging"`, `kubeconfig at index 2 (context "prod")`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := istio.CreateKubeconfigs(tt.kubeconfigs, tt.contexts)
+			if err == nil {
+				t.Fatalf("CreateKubeconfigs() succeeded, want error %s", tt.wantCode)
+			}
+			if code := errors.GetCode(err); code != tt.wantCode {
+				t.Errorf("CreateKubeconfigs() error code = %s, want %s", code, tt.wantCode)
+			}
+			for _, details := range tt.wantDetails {
+				if !strings.Contains(err.Error(), details) {
+					t.Errorf("CreateKubeconfigs() error = %v, want it to mention %s", err, details)
+				}
+			}
+		})
+	}
+}
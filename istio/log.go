@@ -0,0 +1,75 @@
+package istio
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/layer5io/meshkit/errors"
+	"github.com/layer5io/meshkit/logger"
+)
+
+// operationLoggerKey is the context key of the logger of the operation
+type operationLoggerKey struct{}
+
+// operationLogger tags every line logged within an operation with the ID,
+// the name and the namespace of the operation, the lines of concurrent
+// operations being told apart and correlated with their events
+type operationLogger struct {
+	logger.Handler
+	tag string
+}
+
+func newOperationLogger(l logger.Handler, opReq adapter.OperationRequest) logger.Handler {
+	return &operationLogger{
+		Handler: l,
+		tag:     fmt.Sprintf("[operation=%s name=%s namespace=%s]", opReq.OperationID, opReq.OperationName, opReq.Namespace),
+	}
+}
+
+func (l *operationLogger) Info(description ...interface{}) {
+	l.Handler.Info(append([]interface{}{l.tag + " "}, description...)...)
+}
+
+func (l *operationLogger) Debug(description ...interface{}) {
+	l.Handler.Debug(append([]interface{}{l.tag + " "}, description...)...)
+}
+
+func (l *operationLogger) Warn(err error) {
+	l.Handler.Warn(l.tagError(err))
+}
+
+func (l *operationLogger) Error(err error) {
+	l.Handler.Error(l.tagError(err))
+}
+
+// tagError prefixes the description of the error with the tag, the meshkit
+// errors keeping their code, cause and remedy
+func (l *operationLogger) tagError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if e, ok := err.(*errors.Error); ok {
+		tagged := *e
+		tagged.LongDescription = []string{l.tag}
+		if len(e.LongDescription) > 0 {
+			tagged.LongDescription = append([]string{l.tag + " " + e.LongDescription[0]}, e.LongDescription[1:]...)
+		}
+		return &tagged
+	}
+	return fmt.Errorf("%s %w", l.tag, err)
+}
+
+// withOperationLogger returns the context of the operation holding its logger
+func withOperationLogger(ctx context.Context, l logger.Handler) context.Context {
+	return context.WithValue(ctx, operationLoggerKey{}, l)
+}
+
+// log returns the logger of the operation of the context, the logger of the
+// adapter outside of an operation
+func (istio *Istio) log(ctx context.Context) logger.Handler {
+	if l, ok := ctx.Value(operationLoggerKey{}).(logger.Handler); ok {
+		return l
+	}
+	return istio.Log
+}
@@ -0,0 +1,69 @@
+package istio
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/layer5io/meshkit/errors"
+	"github.com/layer5io/meshkit/logger"
+)
+
+// recordingLogger records the lines logged, the controller and database
+// loggers of the embedded handler are not used
+type recordingLogger struct {
+	logger.Handler
+	lines []string
+	errs  []error
+}
+
+func (l *recordingLogger) Info(description ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprint(description...))
+}
+
+func (l *recordingLogger) Debug(description ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprint(description...))
+}
+
+func (l *recordingLogger) Warn(err error) {
+	l.errs = append(l.errs, err)
+}
+
+func (l *recordingLogger) Error(err error) {
+	l.errs = append(l.errs, err)
+}
+
+func TestOperationLogger(t *testing.T) {
+	rec := &recordingLogger{}
+	istio := &Istio{Adapter: adapter.Adapter{Log: rec}}
+	if got := istio.log(context.Background()); got != rec {
+		t.Errorf("log() = %v, want the logger of the adapter outside of an operation", got)
+	}
+
+	ctx := withOperationLogger(context.Background(), newOperationLogger(rec, adapter.OperationRequest{
+		OperationID:   "op-1",
+		OperationName: "istio-operation",
+		Namespace:     "istio-system",
+	}))
+	tag := "[operation=op-1 name=istio-operation namespace=istio-system]"
+	istio.log(ctx).Info("Installing using istioctl...")
+	if want := tag + " Installing using istioctl..."; len(rec.lines) != 1 || rec.lines[0] != want {
+		t.Errorf("Info() logged %q, want %q", rec.lines, want)
+	}
+
+	istio.log(ctx).Error(ErrInvalidProfile("minimal-ish"))
+	istio.log(ctx).Warn(fmt.Errorf("not found"))
+	if len(rec.errs) != 2 {
+		t.Fatalf("Error() and Warn() logged %v, want 2 errors", rec.errs)
+	}
+	if errors.GetCode(rec.errs[0]) != ErrInvalidProfileCode {
+		t.Errorf("Error() code = %s, want %s", errors.GetCode(rec.errs[0]), ErrInvalidProfileCode)
+	}
+	if want := tag + " " + ErrInvalidProfile("minimal-ish").Error(); rec.errs[0].Error() != want {
+		t.Errorf("Error() logged %q, want %q", rec.errs[0].Error(), want)
+	}
+	if want := tag + " not found"; rec.errs[1].Error() != want {
+		t.Errorf("Warn() logged %q, want %q", rec.errs[1].Error(), want)
+	}
+}
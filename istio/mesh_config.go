@@ -0,0 +1,138 @@
+package istio
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	"gopkg.in/yaml.v2"
+	kubeerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+var istioOperatorResource = schema.GroupVersionResource{Group: "install.istio.io", Version: "v1alpha1", Resource: "istiooperators"}
+
+// MeshConfigDump is the effective configuration of the mesh on a single
+// cluster, as istiod and the proxies see it
+type MeshConfigDump struct {
+	Cluster string `json:"cluster"`
+
+	// MeshConfig is the mesh config of the istio ConfigMap, nil when the
+	// default revision is not installed
+	MeshConfig map[string]interface{} `json:"meshConfig,omitempty"`
+
+	// ProxyConfig is the defaultConfig of the mesh config, the defaults of
+	// the proxies the workloads can override with their proxy.istio.io/config
+	// annotation
+	ProxyConfig map[string]interface{} `json:"proxyConfig,omitempty"`
+
+	// IstioOperators are the IstioOperator resources of istio-system, their
+	// status and managed fields are left out
+	IstioOperators []map[string]interface{} `json:"istioOperators,omitempty"`
+
+	// CRDs are the served versions of every Istio CRD
+	CRDs map[string][]string `json:"crds"`
+}
+
+// dumpMeshConfig collects the mesh config, the proxy config defaults, the
+// IstioOperator resources and the versions of the Istio CRDs of every
+// cluster, sorted by cluster
+func (istio *Istio) dumpMeshConfig(kubeConfigs []string) ([]MeshConfigDump, error) {
+	var mx sync.Mutex
+	var dumps []MeshConfigDump
+	err := forEachCluster(kubeConfigs, func(k8sconfig string) error {
+		mclient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return err
+		}
+		dump, err := clusterMeshConfig(context.TODO(), mclient.KubeClient, mclient.DynamicKubeClient)
+		if err != nil {
+			return err
+		}
+		dump.Cluster = clusterName(k8sconfig)
+		mx.Lock()
+		dumps = append(dumps, dump)
+		mx.Unlock()
+		return nil
+	}, nil)
+	sort.Slice(dumps, func(i, j int) bool { return dumps[i].Cluster < dumps[j].Cluster })
+	if err != nil {
+		return dumps, ErrDumpMeshConfig(err)
+	}
+	return dumps, nil
+}
+
+func clusterMeshConfig(ctx context.Context, client kubernetes.Interface, dyn dynamic.Interface) (MeshConfigDump, error) {
+	dump := MeshConfigDump{CRDs: map[string][]string{}}
+
+	cm, err := client.CoreV1().ConfigMaps(istioRootNamespace).Get(ctx, meshConfigMap, metav1.GetOptions{})
+	if err != nil && !kubeerror.IsNotFound(err) {
+		return dump, err
+	}
+	if err == nil {
+		var mesh interface{}
+		if err := yaml.Unmarshal([]byte(cm.Data["mesh"]), &mesh); err != nil {
+			return dump, fmt.Errorf("invalid mesh config: %w", err)
+		}
+		dump.MeshConfig, _ = jsonValue(mesh).(map[string]interface{})
+		dump.ProxyConfig, _ = dump.MeshConfig["defaultConfig"].(map[string]interface{})
+	}
+
+	// The IstioOperator CRD is only installed by istioctl and the operator
+	operators, err := dyn.Resource(istioOperatorResource).Namespace(istioRootNamespace).List(ctx, metav1.ListOptions{})
+	if err != nil && !kubeerror.IsNotFound(err) {
+		return dump, err
+	}
+	if err == nil {
+		for _, operator := range operators.Items {
+			unstructured.RemoveNestedField(operator.Object, "status")
+			unstructured.RemoveNestedField(operator.Object, "metadata", "managedFields")
+			dump.IstioOperators = append(dump.IstioOperators, operator.Object)
+		}
+	}
+
+	crds, err := dyn.Resource(crdResource).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return dump, err
+	}
+	for _, crd := range crds.Items {
+		if !isIstioGroup(crd.GetName()) {
+			continue
+		}
+		versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+		served := []string{}
+		for _, v := range versions {
+			version, _ := v.(map[string]interface{})
+			if version["served"] == true {
+				served = append(served, fmt.Sprint(version["name"]))
+			}
+		}
+		dump.CRDs[crd.GetName()] = served
+	}
+	return dump, nil
+}
+
+// jsonValue converts the maps decoded by yaml.v2, keyed by interface{}, to
+// maps keyed by string so that the value can be marshalled to JSON
+func jsonValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[fmt.Sprint(key)] = jsonValue(value)
+		}
+		return m
+	case []interface{}:
+		for i := range v {
+			v[i] = jsonValue(v[i])
+		}
+		return v
+	}
+	return v
+}
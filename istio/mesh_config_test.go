@@ -0,0 +1,80 @@
+package istio
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestClusterMeshConfig(t *testing.T) {
+	cm := &corev1.ConfigMap{}
+	cm.Name = meshConfigMap
+	cm.Namespace = istioRootNamespace
+	cm.Data = map[string]string{"mesh": `accessLogFile: /dev/stdout
+defaultConfig:
+  holdApplicationUntilProxyStarts: true
+  tracing:
+    sampling: 10
+`}
+	crd := func(name string, served ...string) *unstructured.Unstructured {
+		var versions []interface{}
+		for _, v := range served {
+			versions = append(versions, map[string]interface{}{"name": v, "served": true})
+		}
+		versions = append(versions, map[string]interface{}{"name": "v1alpha0", "served": false})
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apiextensions.k8s.io/v1",
+			"kind":       "CustomResourceDefinition",
+			"metadata":   map[string]interface{}{"name": name},
+			"spec":       map[string]interface{}{"versions": versions},
+		}}
+	}
+	operator := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "install.istio.io/v1alpha1",
+		"kind":       "IstioOperator",
+		"metadata":   map[string]interface{}{"name": "installed-state", "namespace": istioRootNamespace},
+		"spec":       map[string]interface{}{"profile": "default"},
+		"status":     map[string]interface{}{"status": "HEALTHY"},
+	}}
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		crdResource:           "CustomResourceDefinitionList",
+		istioOperatorResource: "IstioOperatorList",
+	}, crd("virtualservices.networking.istio.io", "v1alpha3", "v1beta1", "v1"), crd("widgets.example.com", "v1"), operator)
+
+	dump, err := clusterMeshConfig(context.Background(), fake.NewSimpleClientset(cm), dyn)
+	if err != nil {
+		t.Fatalf("clusterMeshConfig() error = %v", err)
+	}
+	if dump.MeshConfig["accessLogFile"] != "/dev/stdout" {
+		t.Errorf("clusterMeshConfig() mesh config = %v, want the mesh of the istio ConfigMap", dump.MeshConfig)
+	}
+	if dump.ProxyConfig["holdApplicationUntilProxyStarts"] != true {
+		t.Errorf("clusterMeshConfig() proxy config = %v, want the defaultConfig of the mesh config", dump.ProxyConfig)
+	}
+	wantCRDs := map[string][]string{"virtualservices.networking.istio.io": {"v1alpha3", "v1beta1", "v1"}}
+	if !reflect.DeepEqual(dump.CRDs, wantCRDs) {
+		t.Errorf("clusterMeshConfig() CRDs = %v, want %v", dump.CRDs, wantCRDs)
+	}
+	if len(dump.IstioOperators) != 1 || dump.IstioOperators[0]["status"] != nil {
+		t.Errorf("clusterMeshConfig() IstioOperators = %v, want installed-state without its status", dump.IstioOperators)
+	}
+	if _, err := json.Marshal(dump); err != nil {
+		t.Errorf("clusterMeshConfig() dump can't be marshalled to JSON: %v", err)
+	}
+
+	dump, err = clusterMeshConfig(context.Background(), fake.NewSimpleClientset(), dyn)
+	if err != nil {
+		t.Fatalf("clusterMeshConfig() without the istio ConfigMap error = %v", err)
+	}
+	if dump.MeshConfig != nil || dump.ProxyConfig != nil {
+		t.Errorf("clusterMeshConfig() without the istio ConfigMap = %+v, want no mesh config", dump)
+	}
+}
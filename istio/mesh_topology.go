@@ -0,0 +1,80 @@
+package istio
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/layer5io/meshery-istio/internal/config"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// meshTopology places the control plane of a cluster in a multi-cluster
+// mesh: the mesh it is part of, its own cluster name and the network its
+// pods are reachable on
+type meshTopology struct {
+	MeshID      string
+	ClusterName string
+
+	// Network is the network of the pods of the cluster, the clusters on
+	// a single network leave it empty
+	Network string
+}
+
+// newMeshTopology reads the meshID, clusterName and network properties of
+// the operation. A multi-cluster mesh identifies its clusters by their mesh
+// ID and cluster name, hence either both are set or none of the three is,
+// ErrIncompleteMultiClusterConfig being returned otherwise.
+func newMeshTopology(props map[string]string) (meshTopology, error) {
+	t := meshTopology{
+		MeshID:      strings.TrimSpace(props[config.MeshID]),
+		ClusterName: strings.TrimSpace(props[config.ClusterName]),
+		Network:     strings.TrimSpace(props[config.Network]),
+	}
+	if t.empty() {
+		return t, nil
+	}
+	var missing []string
+	if t.MeshID == "" {
+		missing = append(missing, config.MeshID)
+	}
+	if t.ClusterName == "" {
+		missing = append(missing, config.ClusterName)
+	}
+	if len(missing) > 0 {
+		return t, ErrIncompleteMultiClusterConfig(fmt.Errorf("%s must be set along with the other multi-cluster properties", strings.Join(missing, " and ")))
+	}
+	// The values end up in the topology.istio.io labels of the workloads
+	for _, p := range []struct{ name, value string }{{config.MeshID, t.MeshID}, {config.ClusterName, t.ClusterName}, {config.Network, t.Network}} {
+		if errs := validation.IsValidLabelValue(p.value); len(errs) > 0 {
+			return t, ErrIncompleteMultiClusterConfig(fmt.Errorf("invalid %s %q: %s", p.name, p.value, strings.Join(errs, ", ")))
+		}
+	}
+	return t, nil
+}
+
+func (t meshTopology) empty() bool {
+	return t.MeshID == "" && t.ClusterName == "" && t.Network == ""
+}
+
+// values returns the global values of the topology
+func (t meshTopology) values() map[string]interface{} {
+	values := map[string]interface{}{
+		"meshID": t.MeshID,
+		"multiCluster": map[string]interface{}{
+			"clusterName": t.ClusterName,
+		},
+	}
+	if t.Network != "" {
+		values["network"] = t.Network
+	}
+	return values
+}
+
+// String describes the topology for the event details
+func (t meshTopology) String() string {
+	network := "a single network"
+	if t.Network != "" {
+		network = fmt.Sprintf("network %s", t.Network)
+	}
+	return fmt.Sprintf("cluster %s of mesh %s on %s", t.ClusterName, t.MeshID, network)
+}
@@ -0,0 +1,64 @@
+package istio
+
+import (
+	"testing"
+
+	"github.com/layer5io/meshery-istio/internal/config"
+	"github.com/layer5io/meshkit/errors"
+)
+
+func TestNewMeshTopology(t *testing.T) {
+	tests := []struct {
+		name    string
+		props   map[string]string
+		want    meshTopology
+		wantErr bool
+	}{
+		{
+			name:  "single cluster",
+			props: map[string]string{config.MeshID: "", config.ClusterName: "", config.Network: ""},
+		},
+		{
+			name:  "multi-network",
+			props: map[string]string{config.MeshID: "mesh1", config.ClusterName: " east ", config.Network: "network1"},
+			want:  meshTopology{MeshID: "mesh1", ClusterName: "east", Network: "network1"},
+		},
+		{
+			name:  "single network",
+			props: map[string]string{config.MeshID: "mesh1", config.ClusterName: "east"},
+			want:  meshTopology{MeshID: "mesh1", ClusterName: "east"},
+		},
+		{
+			name:    "network alone",
+			props:   map[string]string{config.Network: "network1"},
+			wantErr: true,
+		},
+		{
+			name:    "missing cluster name",
+			props:   map[string]string{config.MeshID: "mesh1", config.Network: "network1"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid cluster name",
+			props:   map[string]string{config.MeshID: "mesh1", config.ClusterName: "east cluster"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newMeshTopology(tt.props)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newMeshTopology() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if errors.GetCode(err) != ErrIncompleteMultiClusterConfigCode {
+					t.Errorf("newMeshTopology() error code = %s, want %s", errors.GetCode(err), ErrIncompleteMultiClusterConfigCode)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf("newMeshTopology() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package istio
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/layer5io/meshery-istio/internal/config"
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	"gopkg.in/yaml.v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/dynamic"
+)
+
+// reservedLabels are the labels Istio and its charts select the workloads
+// and the resources of a revision with, the extra labels can't set them
+var reservedLabels = map[string]bool{
+	"app":     true,
+	"version": true,
+	"istio":   true,
+	"release": true,
+}
+
+// installedResources are the kinds of the resources the install creates in
+// istio-system
+var installedResources = []schema.GroupVersionResource{
+	{Group: "apps", Version: "v1", Resource: "deployments"},
+	{Group: "autoscaling", Version: "v2", Resource: "horizontalpodautoscalers"},
+	{Group: "policy", Version: "v1", Resource: "poddisruptionbudgets"},
+	{Version: "v1", Resource: "services"},
+	{Version: "v1", Resource: "configmaps"},
+	{Version: "v1", Resource: "serviceaccounts"},
+}
+
+// extraMetadata are the labels and annotations merged onto the resources
+// the operation creates, such as the cost attribution labels of a platform
+type extraMetadata struct {
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+// newExtraMetadata reads the extraLabels and extraAnnotations properties of
+// the operation, comma separated key=value lists. The keys of the istio.io
+// domains and the labels Istio selects resources with are refused.
+func newExtraMetadata(props map[string]string) (extraMetadata, error) {
+	var m extraMetadata
+	var err error
+	if m.Labels, err = parseMetadata(props[config.ExtraLabels], true); err != nil {
+		return m, ErrInvalidExtraMetadata(config.ExtraLabels, err)
+	}
+	if m.Annotations, err = parseMetadata(props[config.ExtraAnnotations], false); err != nil {
+		return m, ErrInvalidExtraMetadata(config.ExtraAnnotations, err)
+	}
+	return m, nil
+}
+
+func parseMetadata(value string, labels bool) (map[string]string, error) {
+	var parsed map[string]string
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, val, found := strings.Cut(pair, "=")
+		key, val = strings.TrimSpace(key), strings.TrimSpace(val)
+		if !found {
+			return nil, fmt.Errorf("%q is not a key=value pair", pair)
+		}
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid key %q: %s", key, strings.Join(errs, ", "))
+		}
+		if isIstioKey(key) || (labels && reservedLabels[key]) {
+			return nil, fmt.Errorf("%q is reserved by Istio", key)
+		}
+		if labels {
+			if errs := validation.IsValidLabelValue(val); len(errs) > 0 {
+				return nil, fmt.Errorf("invalid value of %q: %s", key, strings.Join(errs, ", "))
+			}
+		}
+		if parsed == nil {
+			parsed = map[string]string{}
+		}
+		parsed[key] = val
+	}
+	return parsed, nil
+}
+
+// isIstioKey reports whether the label or annotation key is in the istio.io
+// domain or one of its subdomains
+func isIstioKey(key string) bool {
+	prefix, _, found := strings.Cut(key, "/")
+	return found && (prefix == "istio.io" || strings.HasSuffix(prefix, ".istio.io"))
+}
+
+func (m extraMetadata) empty() bool {
+	return len(m.Labels) == 0 && len(m.Annotations) == 0
+}
+
+// String describes the labels and annotations for the event details
+func (m extraMetadata) String() string {
+	var parts []string
+	for _, kind := range []struct {
+		name   string
+		values map[string]string
+	}{{"labels", m.Labels}, {"annotations", m.Annotations}} {
+		if len(kind.values) == 0 {
+			continue
+		}
+		pairs := make([]string, 0, len(kind.values))
+		for key, value := range kind.values {
+			pairs = append(pairs, key+"="+value)
+		}
+		sort.Strings(pairs)
+		parts = append(parts, fmt.Sprintf("%s %s", kind.name, strings.Join(pairs, ", ")))
+	}
+	return strings.Join(parts, " and ")
+}
+
+// details appends the applied labels and annotations to the event details
+func (m extraMetadata) details(details string) string {
+	if m.empty() {
+		return details
+	}
+	applied := fmt.Sprintf("Applied %s.", m)
+	if details == "" {
+		return applied
+	}
+	return fmt.Sprintf("%s\n%s", details, applied)
+}
+
+// merge sets the labels and annotations on the object, leaving the ones it
+// already has untouched, and reports whether the object changed
+func (m extraMetadata) merge(obj *unstructured.Unstructured) bool {
+	changed := false
+	labels := obj.GetLabels()
+	for key, value := range m.Labels {
+		if _, ok := labels[key]; ok {
+			continue
+		}
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[key] = value
+		changed = true
+	}
+	annotations := obj.GetAnnotations()
+	for key, value := range m.Annotations {
+		if _, ok := annotations[key]; ok {
+			continue
+		}
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[key] = value
+		changed = true
+	}
+	if changed {
+		obj.SetLabels(labels)
+		obj.SetAnnotations(annotations)
+	}
+	return changed
+}
+
+// manifest merges the labels and annotations onto every resource of the
+// multi-document manifest. The resources are identified by their names,
+// hence the manifest removes them regardless of the extra metadata.
+func (m extraMetadata) manifest(contents []byte) ([]byte, error) {
+	if m.empty() {
+		return contents, nil
+	}
+	return mapManifest(contents, func(object map[string]interface{}) {
+		m.merge(&unstructured.Unstructured{Object: object})
+	})
+}
+
+// mapManifest calls fn on every resource of the multi-document manifest and
+// returns the manifest of the updated resources, the empty documents being
+// left out
+func mapManifest(contents []byte, fn func(object map[string]interface{})) ([]byte, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(contents))
+	var docs []string
+	for {
+		var doc interface{}
+		err := decoder.Decode(&doc)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		object, ok := jsonValue(doc).(map[string]interface{})
+		if !ok {
+			continue
+		}
+		fn(object)
+		out, err := yaml.Marshal(object)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, string(out))
+	}
+	return []byte(strings.Join(docs, "---\n")), nil
+}
+
+// labelInstalled merges the labels and annotations onto the resources the
+// install created in istio-system on every cluster, the ones istioctl owns
+// and the ones of the helm releases
+func labelInstalled(ctx context.Context, m extraMetadata, kubeconfigs []string) error {
+	if m.empty() {
+		return nil
+	}
+	return forEachCluster(kubeconfigs, func(k8sconfig string) error {
+		mclient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return err
+		}
+		return labelInstalledOnCluster(ctx, mclient.DynamicKubeClient, m)
+	}, nil)
+}
+
+func labelInstalledOnCluster(ctx context.Context, dyn dynamic.Interface, m extraMetadata) error {
+	for _, gvr := range installedResources {
+		list, err := dyn.Resource(gvr).Namespace(istioRootNamespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("unable to list the %s of %s: %w", gvr.Resource, istioRootNamespace, err)
+		}
+		for i := range list.Items {
+			obj := &list.Items[i]
+			_, istioctl := obj.GetLabels()["install.operator.istio.io/owning-resource"]
+			_, helm := obj.GetAnnotations()["meta.helm.sh/release-name"]
+			if !istioctl && !helm {
+				continue
+			}
+			if !m.merge(obj) {
+				continue
+			}
+			if _, err := dyn.Resource(gvr).Namespace(istioRootNamespace).Update(ctx, obj, metav1.UpdateOptions{}); err != nil {
+				return fmt.Errorf("unable to label %s %s: %w", gvr.Resource, obj.GetName(), err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package istio
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/layer5io/meshery-istio/internal/config"
+	"github.com/layer5io/meshkit/errors"
+	"gopkg.in/yaml.v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestNewExtraMetadata(t *testing.T) {
+	tests := []struct {
+		name    string
+		props   map[string]string
+		want    extraMetadata
+		wantErr bool
+	}{
+		{
+			name:  "none",
+			props: map[string]string{config.ExtraLabels: "", config.ExtraAnnotations: ""},
+		},
+		{
+			name:  "labels and annotations",
+			props: map[string]string{config.ExtraLabels: "team=payments, cost-center=42", config.ExtraAnnotations: "example.com/owner=Payments Team"},
+			want: extraMetadata{
+				Labels:      map[string]string{"team": "payments", "cost-center": "42"},
+				Annotations: map[string]string{"example.com/owner": "Payments Team"},
+			},
+		},
+		{
+			name:    "not a pair",
+			props:   map[string]string{config.ExtraLabels: "team"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid label value",
+			props:   map[string]string{config.ExtraLabels: "owner=Payments Team"},
+			wantErr: true,
+		},
+		{
+			name:    "reserved label",
+			props:   map[string]string{config.ExtraLabels: "app=payments"},
+			wantErr: true,
+		},
+		{
+			name:    "istio annotation",
+			props:   map[string]string{config.ExtraAnnotations: "sidecar.istio.io/inject=false"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newExtraMetadata(tt.props)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newExtraMetadata() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if errors.GetCode(err) != ErrInvalidExtraMetadataCode {
+					t.Errorf("newExtraMetadata() error code = %s, want %s", errors.GetCode(err), ErrInvalidExtraMetadataCode)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newExtraMetadata() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtraMetadataManifest(t *testing.T) {
+	m := extraMetadata{
+		Labels:      map[string]string{"team": "payments", "istio": "overridden"},
+		Annotations: map[string]string{"example.com/owner": "payments"},
+	}
+	manifest := `apiVersion: v1
+kind: Service
+metadata:
+  name: grafana
+  labels:
+    app: grafana
+    istio: kept
+spec:
+  selector:
+    app: grafana
+---
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: grafana
+`
+	got, err := m.manifest([]byte(manifest))
+	if err != nil {
+		t.Fatalf("manifest() error = %v", err)
+	}
+	docs := strings.Split(string(got), "---\n")
+	if len(docs) != 2 {
+		t.Fatalf("manifest() = %s, want the 2 resources", got)
+	}
+	for _, doc := range docs {
+		var obj interface{}
+		if err := yaml.Unmarshal([]byte(doc), &obj); err != nil {
+			t.Fatalf("manifest() generated invalid YAML: %v\n%s", err, doc)
+		}
+		u := unstructured.Unstructured{Object: jsonValue(obj).(map[string]interface{})}
+		if u.GetLabels()["team"] != "payments" || u.GetAnnotations()["example.com/owner"] != "payments" {
+			t.Errorf("manifest() %s metadata = %v %v, want the extra metadata", u.GetKind(), u.GetLabels(), u.GetAnnotations())
+		}
+		if u.GetKind() == "Service" && (u.GetLabels()["istio"] != "kept" || u.GetLabels()["app"] != "grafana") {
+			t.Errorf("manifest() Service labels = %v, want the existing labels untouched", u.GetLabels())
+		}
+	}
+
+	if got, _ := (extraMetadata{}).manifest([]byte(manifest)); string(got) != manifest {
+		t.Errorf("manifest() = %s, want the manifest as is without extra metadata", got)
+	}
+}
+
+func TestLabelInstalledOnCluster(t *testing.T) {
+	deployment := func(name string, labels map[string]interface{}, annotations map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   map[string]interface{}{"name": name, "namespace": istioRootNamespace, "labels": labels, "annotations": annotations},
+		}}
+	}
+	listKinds := map[schema.GroupVersionResource]string{}
+	for _, gvr := range installedResources {
+		listKinds[gvr] = "List"
+	}
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds,
+		deployment("istiod", map[string]interface{}{"app": "istiod"}, map[string]interface{}{"meta.helm.sh/release-name": "istiod"}),
+		deployment("istio-ingressgateway", map[string]interface{}{"install.operator.istio.io/owning-resource": "installed-state"}, nil),
+		deployment("grafana", map[string]interface{}{"app": "grafana"}, nil),
+	)
+	m := extraMetadata{Labels: map[string]string{"team": "payments"}}
+	if err := labelInstalledOnCluster(context.Background(), dyn, m); err != nil {
+		t.Fatalf("labelInstalledOnCluster() error = %v", err)
+	}
+	for name, want := range map[string]string{"istiod": "payments", "istio-ingressgateway": "payments", "grafana": ""} {
+		got, err := dyn.Resource(installedResources[0]).Namespace(istioRootNamespace).Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("unable to get %s: %v", name, err)
+		}
+		if got.GetLabels()["team"] != want {
+			t.Errorf("labelInstalledOnCluster() %s labels = %v, want team=%q", name, got.GetLabels(), want)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package istio
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	prometheusNamespace = "istio-system"
+	prometheusService   = "prometheus"
+	metricsWindow       = "5m"
+)
+
+// ServiceMetrics is the metrics summary of a single destination service of a
+// workload. The standard Istio metrics carry no route labels, hence the
+// summary is per service.
+type ServiceMetrics struct {
+	Service     string  `json:"service"`
+	RequestRate float64 `json:"request_rate"`
+	ErrorRate   float64 `json:"error_rate"`
+	P50         float64 `json:"p50_ms"`
+	P90         float64 `json:"p90_ms"`
+	P99         float64 `json:"p99_ms"`
+}
+
+// queryFunc runs an instant PromQL query and returns the raw API response
+type queryFunc func(query string) ([]byte, error)
+
+// getServiceMetrics fetches the request rate, error rate and latency
+// percentiles of the destination services of a workload from Prometheus for every cluster.
+//
+// If promURL is empty the Prometheus addon installed in the istio-system
+// namespace is queried through the kubernetes API server proxy.
+func (istio *Istio) getServiceMetrics(namespace, workload, promURL string, kubeconfigs []string) (map[string][]ServiceMetrics, error) {
+	var mx sync.Mutex
+	var promNotFound bool
+	summary := make(map[string][]ServiceMetrics)
+	err := forEachCluster(kubeconfigs, func(k8sconfig string) error {
+		query, err := prometheusQuerier(promURL, k8sconfig)
+		if err == nil {
+			var metrics []ServiceMetrics
+			metrics, err = collectServiceMetrics(query, namespace, workload)
+			if err == nil {
+				mx.Lock()
+				summary[clusterName(k8sconfig)] = metrics
+				mx.Unlock()
+				return nil
+			}
+		}
+		if err == ErrPrometheusNotFound {
+			mx.Lock()
+			promNotFound = true
+			mx.Unlock()
+		}
+		return err
+	}, nil)
+	if err == nil {
+		return summary, nil
+	}
+	if promNotFound {
+		return summary, ErrPrometheusNotFound
+	}
+	return summary, ErrQueryPrometheus(err)
+}
+
+// prometheusQuerier returns a queryFunc for the external Prometheus if
+// configured, else for the Prometheus addon running in the cluster
+func prometheusQuerier(promURL, k8sconfig string) (queryFunc, error) {
+	if promURL != "" {
+		if _, err := url.ParseRequestURI(promURL); err != nil {
+			return nil, err
+		}
+		return func(query string) ([]byte, error) {
+			resp, err := http.Get(fmt.Sprintf("%s/api/v1/query?query=%s", strings.TrimSuffix(promURL, "/"), url.QueryEscape(query)))
+			if err != nil {
+				return nil, err
+			}
+			defer func() {
+				_ = resp.Body.Close()
+			}()
+			if resp.StatusCode != http.StatusOK {
+				return nil, fmt.Errorf("prometheus returned status %s", resp.Status)
+			}
+			return io.ReadAll(resp.Body)
+		}, nil
+	}
+
+	mclient, err := mesherykube.New([]byte(k8sconfig))
+	if err != nil {
+		return nil, err
+	}
+	svc, err := mclient.KubeClient.CoreV1().Services(prometheusNamespace).Get(context.TODO(), prometheusService, metav1.GetOptions{})
+	if err != nil || len(svc.Spec.Ports) == 0 {
+		return nil, ErrPrometheusNotFound
+	}
+	port := strconv.Itoa(int(svc.Spec.Ports[0].Port))
+	return func(query string) ([]byte, error) {
+		return mclient.KubeClient.CoreV1().Services(prometheusNamespace).
+			ProxyGet("http", prometheusService, port, "api/v1/query", map[string]string{"query": query}).
+			DoRaw(context.TODO())
+	}, nil
+}
+
+// collectServiceMetrics runs the summary queries and groups the results by
+// destination service
+func collectServiceMetrics(query queryFunc, namespace, workload string) ([]ServiceMetrics, error) {
+	selector := fmt.Sprintf(`reporter="destination",destination_workload_namespace=%q`, namespace)
+	if workload != "" {
+		selector = fmt.Sprintf(`%s,destination_workload=%q`, selector, workload)
+	}
+	requests := fmt.Sprintf(`sum(rate(istio_requests_total{%s}[%s])) by (destination_service)`, selector, metricsWindow)
+	failures := fmt.Sprintf(`sum(rate(istio_requests_total{%s,response_code=~"5.."}[%s])) by (destination_service)`, selector, metricsWindow)
+	latency := func(q float64) string {
+		return fmt.Sprintf(`histogram_quantile(%g, sum(rate(istio_request_duration_milliseconds_bucket{%s}[%s])) by (le, destination_service))`, q, selector, metricsWindow)
+	}
+
+	services := make(map[string]*ServiceMetrics)
+	set := func(promQL string, fn func(*ServiceMetrics, float64)) error {
+		body, err := query(promQL)
+		if err != nil {
+			return err
+		}
+		values, err := parsePrometheusVector(body, "destination_service")
+		if err != nil {
+			return err
+		}
+		for service, value := range values {
+			if _, ok := services[service]; !ok {
+				services[service] = &ServiceMetrics{Service: service}
+			}
+			fn(services[service], value)
+		}
+		return nil
+	}
+
+	if err := set(requests, func(r *ServiceMetrics, v float64) { r.RequestRate = v }); err != nil {
+		return nil, err
+	}
+	if err := set(failures, func(r *ServiceMetrics, v float64) { r.ErrorRate = v }); err != nil {
+		return nil, err
+	}
+	if err := set(latency(0.5), func(r *ServiceMetrics, v float64) { r.P50 = v }); err != nil {
+		return nil, err
+	}
+	if err := set(latency(0.9), func(r *ServiceMetrics, v float64) { r.P90 = v }); err != nil {
+		return nil, err
+	}
+	if err := set(latency(0.99), func(r *ServiceMetrics, v float64) { r.P99 = v }); err != nil {
+		return nil, err
+	}
+
+	metrics := make([]ServiceMetrics, 0, len(services))
+	for _, r := range services {
+		// Error rate is reported as a fraction of the request rate
+		if r.RequestRate > 0 {
+			r.ErrorRate = r.ErrorRate / r.RequestRate
+		}
+		metrics = append(metrics, *r)
+	}
+	sort.Slice(metrics, func(i, j int) bool {
+		return metrics[i].Service < metrics[j].Service
+	})
+	return metrics, nil
+}
+
+// parsePrometheusVector parses an instant vector query response into a map
+// of the given label's value to the sample value
+func parsePrometheusVector(body []byte, label string) (map[string]float64, error) {
+	var resp struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+		Data   struct {
+			ResultType string `json:"resultType"`
+			Result     []struct {
+				Metric map[string]string `json:"metric"`
+				Value  []interface{}     `json:"value"`
+			} `json:"result"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Status != "success" {
+		return nil, fmt.Errorf("prometheus query failed: %s", resp.Error)
+	}
+	if resp.Data.ResultType != "vector" {
+		return nil, fmt.Errorf("unexpected prometheus result type: %s", resp.Data.ResultType)
+	}
+
+	values := make(map[string]float64)
+	for _, sample := range resp.Data.Result {
+		if len(sample.Value) != 2 {
+			continue
+		}
+		raw, ok := sample.Value[1].(string)
+		if !ok {
+			continue
+		}
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, err
+		}
+		// Quantiles are NaN when there was no traffic in the window
+		if math.IsNaN(value) {
+			value = 0
+		}
+		values[sample.Metric[label]] = value
+	}
+	return values, nil
+}
@@ -0,0 +1,58 @@
+package istio
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePrometheusVector(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    map[string]float64
+		wantErr bool
+	}{
+		{
+			name: "vector result",
+			body: `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"destination_service":"reviews.default.svc.cluster.local"},"value":[1700000000,"2.5"]},{"metric":{"destination_service":"ratings.default.svc.cluster.local"},"value":[1700000000,"NaN"]}]}}`,
+			want: map[string]float64{
+				"reviews.default.svc.cluster.local": 2.5,
+				"ratings.default.svc.cluster.local": 0,
+			},
+			wantErr: false,
+		},
+		{
+			name:    "empty result",
+			body:    `{"status":"success","data":{"resultType":"vector","result":[]}}`,
+			want:    map[string]float64{},
+			wantErr: false,
+		},
+		{
+			name:    "failed query",
+			body:    `{"status":"error","error":"parse error"}`,
+			wantErr: true,
+		},
+		{
+			name:    "matrix result",
+			body:    `{"status":"success","data":{"resultType":"matrix","result":[]}}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid body",
+			body:    `not json`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePrometheusVector([]byte(tt.body), "destination_service")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parsePrometheusVector() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parsePrometheusVector() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package istio
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/layer5io/meshery-adapter-library/status"
+	"github.com/layer5io/meshery-istio/internal/config"
+	"github.com/layer5io/meshkit/utils"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// mirrorValues are the values of the traffic mirroring template
+type mirrorValues struct {
+	Name      string
+	Namespace string
+
+	// Service is the service whose traffic is mirrored, it keeps receiving
+	// all the traffic while Target receives a copy of Percentage of it
+	Service    string
+	Target     string
+	Percentage float64
+}
+
+// newMirrorValues validates the properties of the traffic mirror operation.
+// The VirtualService is named after the mirrored service, deleting it only
+// needs the service.
+func newMirrorValues(namespace string, props map[string]string, del bool) (*mirrorValues, error) {
+	values := &mirrorValues{
+		Namespace: namespace,
+		Service:   strings.TrimSpace(props[config.MirrorService]),
+		Target:    strings.TrimSpace(props[config.MirrorTarget]),
+	}
+	if errs := validation.IsDNS1035Label(values.Service); len(errs) > 0 {
+		return nil, ErrTrafficMirrorInvalid(fmt.Errorf("invalid service %q: %s", values.Service, strings.Join(errs, ", ")))
+	}
+	values.Name = values.Service + "-mirror"
+	if len(values.Name) > validation.DNS1123LabelMaxLength {
+		values.Name = strings.Trim(values.Service[:validation.DNS1123LabelMaxLength-len("-mirror")], "-") + "-mirror"
+	}
+	if del {
+		return values, nil
+	}
+
+	if errs := validation.IsDNS1035Label(values.Target); len(errs) > 0 {
+		return nil, ErrTrafficMirrorInvalid(fmt.Errorf("invalid mirror target %q: %s", values.Target, strings.Join(errs, ", ")))
+	}
+	if values.Target == values.Service {
+		return nil, ErrTrafficMirrorInvalid(fmt.Errorf("service %s can't be mirrored to itself", values.Service))
+	}
+	percentage, err := strconv.ParseFloat(strings.TrimSpace(props[config.MirrorPercentage]), 64)
+	if err != nil || percentage < 0 || percentage > 100 {
+		return nil, ErrTrafficMirrorInvalid(fmt.Errorf("mirror percentage %q is not a number between 0 and 100", props[config.MirrorPercentage]))
+	}
+	values.Percentage = percentage
+	return values, nil
+}
+
+// mirrorTraffic applies the VirtualService routing the traffic of the service
+// to the service itself while mirroring a percentage of it to the target, the
+// responses of the target being discarded. Removing the VirtualService
+// reverts the service to its original routing.
+func (istio *Istio) mirrorTraffic(ctx context.Context, del bool, values *mirrorValues, templates []adapter.Template, kubeconfigs []string) (string, error) {
+	st := status.Deploying
+	if del {
+		st = status.Removing
+	}
+
+	var manifests []string
+	for _, template := range templates {
+		contents, err := utils.ReadFileSource(string(template))
+		if err != nil {
+			return st, ErrTrafficMirror(err)
+		}
+		contents, err = renderTemplate(contents, values)
+		if err != nil {
+			return st, ErrTrafficMirror(err)
+		}
+		manifests = append(manifests, contents)
+	}
+	manifest := []byte(strings.Join(manifests, "\n---\n"))
+
+	if !del {
+		if err := servicesExist(ctx, values.Namespace, []string{values.Service, values.Target}, kubeconfigs); err != nil {
+			return st, ErrTrafficMirrorInvalid(err)
+		}
+	}
+
+	if err := istio.applyRouting(ctx, manifest, del, values.Namespace, kubeconfigs); err != nil {
+		return st, ErrTrafficMirror(err)
+	}
+	if del {
+		return status.Removed, nil
+	}
+	return status.Deployed, nil
+}
@@ -0,0 +1,114 @@
+package istio
+
+import (
+	"os"
+	"testing"
+
+	"github.com/layer5io/meshery-istio/internal/config"
+	"gopkg.in/yaml.v2"
+)
+
+func TestTrafficMirror(t *testing.T) {
+	tmpl, err := os.ReadFile("../templates/routing/mirror.yaml")
+	if err != nil {
+		t.Fatalf("unable to read the mirror template: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		props          map[string]string
+		del            bool
+		wantPercentage float64
+		wantErr        bool
+	}{
+		{
+			name:           "whole traffic",
+			props:          map[string]string{config.MirrorService: "reviews", config.MirrorTarget: "reviews-v2", config.MirrorPercentage: "100"},
+			wantPercentage: 100,
+		},
+		{
+			name:           "fraction of the traffic",
+			props:          map[string]string{config.MirrorService: "reviews", config.MirrorTarget: "reviews-v2", config.MirrorPercentage: " 12.5 "},
+			wantPercentage: 12.5,
+		},
+		{
+			name:  "delete only needs the service",
+			props: map[string]string{config.MirrorService: "reviews"},
+			del:   true,
+		},
+		{
+			name:    "percentage above 100",
+			props:   map[string]string{config.MirrorService: "reviews", config.MirrorTarget: "reviews-v2", config.MirrorPercentage: "150"},
+			wantErr: true,
+		},
+		{
+			name:    "negative percentage",
+			props:   map[string]string{config.MirrorService: "reviews", config.MirrorTarget: "reviews-v2", config.MirrorPercentage: "-1"},
+			wantErr: true,
+		},
+		{
+			name:    "missing percentage",
+			props:   map[string]string{config.MirrorService: "reviews", config.MirrorTarget: "reviews-v2"},
+			wantErr: true,
+		},
+		{
+			name:    "mirrored to itself",
+			props:   map[string]string{config.MirrorService: "reviews", config.MirrorTarget: "reviews", config.MirrorPercentage: "50"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid target",
+			props:   map[string]string{config.MirrorService: "reviews", config.MirrorTarget: "Reviews_V2", config.MirrorPercentage: "50"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := newMirrorValues("bookinfo", tt.props, tt.del)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newMirrorValues() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if values.Name != "reviews-mirror" {
+				t.Errorf("newMirrorValues() name = %s, want reviews-mirror", values.Name)
+			}
+			if tt.del {
+				return
+			}
+			rendered, err := renderTemplate(string(tmpl), values)
+			if err != nil {
+				t.Fatalf("renderTemplate() error = %v", err)
+			}
+			var virtualService struct {
+				Spec struct {
+					Hosts []string `yaml:"hosts"`
+					HTTP  []struct {
+						Route []struct {
+							Destination struct {
+								Host string `yaml:"host"`
+							} `yaml:"destination"`
+						} `yaml:"route"`
+						Mirror struct {
+							Host string `yaml:"host"`
+						} `yaml:"mirror"`
+						MirrorPercentage struct {
+							Value float64 `yaml:"value"`
+						} `yaml:"mirrorPercentage"`
+					} `yaml:"http"`
+				} `yaml:"spec"`
+			}
+			if err := yaml.Unmarshal([]byte(rendered), &virtualService); err != nil {
+				t.Fatalf("renderTemplate() generated invalid YAML: %v\n%s", err, rendered)
+			}
+			http := virtualService.Spec.HTTP[0]
+			if virtualService.Spec.Hosts[0] != "reviews" || http.Route[0].Destination.Host != "reviews" {
+				t.Errorf("renderTemplate() = %s, want the reviews traffic routed to reviews", rendered)
+			}
+			if http.Mirror.Host != "reviews-v2" || http.MirrorPercentage.Value != tt.wantPercentage {
+				t.Errorf("renderTemplate() mirror = %s at %v%%, want reviews-v2 at %v%%", http.Mirror.Host, http.MirrorPercentage.Value, tt.wantPercentage)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package istio
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -27,6 +28,8 @@ func (istio *Istio) HandleComponents(comps []v1alpha1.Component, isDel bool, kub
 		"PrometheusIstioAddon": handleComponentIstioAddon,
 		"ZipkinIstioAddon":     handleComponentIstioAddon,
 		"JaegerIstioAddon":     handleComponentIstioAddon,
+		"WasmPlugin":           handleComponentWasmPlugin,
+		"Sidecar":              handleComponentSidecar,
 	}
 	stat1 := "deploying"
 	stat2 := "deployed"
@@ -122,7 +125,7 @@ func handleMTLS(istio *Istio, namespaces []string, policy string, isDel bool, ku
 	for _, ns := range namespaces {
 		policyName := fmt.Sprintf("%s-mtls-policy-operation", policy)
 
-		if _, err := istio.applyPolicy(ns, isDel, config.GetOperations(common.Operations, "master")[policyName].Templates, kubeconfigs); err != nil {
+		if _, err := istio.applyPolicy(context.TODO(), ns, isDel, config.GetOperations(common.Operations, "master")[policyName].Templates, nil, extraMetadata{}, nil, kubeconfigs); err != nil {
 			errs = append(errs, err)
 		}
 	}
@@ -133,7 +136,7 @@ func handleMTLS(istio *Istio, namespaces []string, policy string, isDel bool, ku
 func handleNamespaceLabel(istio *Istio, namespaces []string, isDel bool, kubeconfigs []string) error {
 	var errs []error
 	for _, ns := range namespaces {
-		if err := istio.LoadNamespaceToMesh(ns, isDel, kubeconfigs); err != nil {
+		if err := istio.LoadNamespaceToMesh(ns, isDel, "", kubeconfigs); err != nil {
 			errs = append(errs, err)
 		}
 	}
@@ -151,7 +154,8 @@ func handleComponentIstioMesh(istio *Istio, comp v1alpha1.Component, isDel bool,
 	}
 	//TODO: When no version is passed in service, use the latest istio version
 	profile := comp.Spec.Settings["profile"].(string)
-	return istio.installIstio(isDel, false, version, comp.Namespace, profile, kubeconfigs)
+	revision, _ := comp.Spec.Settings["revision"].(string)
+	return istio.installIstio(context.TODO(), isDel, false, version, comp.Namespace, installOptions{Profile: profile, Revision: revision}, kubeconfigs)
 }
 
 func handleIstioCoreComponent(
@@ -161,17 +165,34 @@ func handleIstioCoreComponent(
 	apiVersion,
 	kind string,
 	kubeconfigs []string) (string, error) {
+	yamlByt, kind, err := renderIstioCoreComponent(comp, apiVersion, kind)
+	if err != nil {
+		istio.Log.Error(err)
+		return "", err
+	}
+	msg := fmt.Sprintf("created %s \"%s\" in namespace \"%s\"", kind, comp.Name, comp.Namespace)
+	if isDel {
+		msg = fmt.Sprintf("deleted %s config \"%s\" in namespace \"%s\"", kind, comp.Name, comp.Namespace)
+	}
+
+	return msg, istio.applyManifest(context.TODO(), yamlByt, isDel, comp.Namespace, kubeconfigs)
+}
+
+// renderIstioCoreComponent renders the manifest of the Istio resource of the
+// component, the apiVersion and kind are taken from the component if empty.
+// The kind of the resource is returned along with its manifest.
+func renderIstioCoreComponent(comp v1alpha1.Component, apiVersion, kind string) ([]byte, string, error) {
 	if apiVersion == "" {
 		apiVersion = v1alpha1.GetAPIVersionFromComponent(comp)
 		if apiVersion == "" {
-			return "", ErrIstioCoreComponentFail(fmt.Errorf("failed to get API Version for: %s", comp.Name))
+			return nil, "", ErrIstioCoreComponentFail(fmt.Errorf("failed to get API Version for: %s", comp.Name))
 		}
 	}
 
 	if kind == "" {
 		kind = v1alpha1.GetKindFromComponent(comp)
 		if kind == "" {
-			return "", ErrIstioCoreComponentFail(fmt.Errorf("failed to get kind for: %s", comp.Name))
+			return nil, "", ErrIstioCoreComponentFail(fmt.Errorf("failed to get kind for: %s", comp.Name))
 		}
 	}
 	component := map[string]interface{}{
@@ -188,16 +209,80 @@ func handleIstioCoreComponent(
 	// Convert to yaml
 	yamlByt, err := yaml.Marshal(component)
 	if err != nil {
-		err = ErrParseIstioCoreComponent(err)
-		istio.Log.Error(err)
+		return nil, "", ErrParseIstioCoreComponent(err)
+	}
+	return yamlByt, kind, nil
+}
+
+func handleComponentWasmPlugin(istio *Istio, comp v1alpha1.Component, isDel bool, kubeconfigs []string) (string, error) {
+	plugin, err := wasmPluginManifest(comp, isDel)
+	if err != nil {
 		return "", err
 	}
-	msg := fmt.Sprintf("created %s \"%s\" in namespace \"%s\"", kind, comp.Name, comp.Namespace)
+
+	yamlByt, err := yaml.Marshal(plugin)
+	if err != nil {
+		return "", ErrParseOAMComponent(comp.Name, err)
+	}
+	msg := fmt.Sprintf("created WasmPlugin \"%s\" in namespace \"%s\"", comp.Name, comp.Namespace)
 	if isDel {
-		msg = fmt.Sprintf("deleted %s config \"%s\" in namespace \"%s\"", kind, comp.Name, comp.Namespace)
+		msg = fmt.Sprintf("deleted WasmPlugin \"%s\" in namespace \"%s\"", comp.Name, comp.Namespace)
 	}
 
-	return msg, istio.applyManifest(yamlByt, isDel, comp.Namespace, kubeconfigs)
+	return msg, istio.applyManifest(context.TODO(), yamlByt, isDel, comp.Namespace, kubeconfigs)
+}
+
+// wasmPluginManifest renders the WasmPlugin resource from the url, pluginConfig,
+// phase and priority settings of the component. The settings aren't needed to
+// delete the plugin as it is removed by name and namespace
+func wasmPluginManifest(comp v1alpha1.Component, isDel bool) (map[string]interface{}, error) {
+	plugin := map[string]interface{}{
+		"apiVersion": "extensions.istio.io/v1alpha1",
+		"kind":       "WasmPlugin",
+		"metadata": map[string]interface{}{
+			"name":      comp.Name,
+			"namespace": comp.Namespace,
+		},
+	}
+	if isDel {
+		return plugin, nil
+	}
+
+	url, _ := comp.Spec.Settings["url"].(string)
+	if url == "" {
+		return nil, ErrParseOAMComponent(comp.Name, fmt.Errorf("url is required for WasmPlugin"))
+	}
+	spec := map[string]interface{}{
+		"url": url,
+	}
+	if pluginConfig, ok := comp.Spec.Settings["pluginConfig"]; ok {
+		if _, ok := pluginConfig.(map[string]interface{}); !ok {
+			return nil, ErrParseOAMComponent(comp.Name, fmt.Errorf("pluginConfig must be an object"))
+		}
+		spec["pluginConfig"] = pluginConfig
+	}
+	if phase, ok := comp.Spec.Settings["phase"]; ok {
+		p, _ := phase.(string)
+		switch p {
+		case "UNSPECIFIED_PHASE", "AUTHN", "AUTHZ", "STATS":
+			spec["phase"] = p
+		default:
+			return nil, ErrParseOAMComponent(comp.Name, fmt.Errorf("invalid phase %v", phase))
+		}
+	}
+	if priority, ok := comp.Spec.Settings["priority"]; ok {
+		// Numbers are decoded as float64 from the JSON component
+		p, ok := priority.(float64)
+		if !ok || p != float64(int64(p)) {
+			return nil, ErrParseOAMComponent(comp.Name, fmt.Errorf("priority must be an integer"))
+		}
+		spec["priority"] = int64(p)
+	}
+	if selector, ok := comp.Spec.Settings["selector"]; ok {
+		spec["selector"] = selector
+	}
+	plugin["spec"] = spec
+	return plugin, nil
 }
 
 func handleComponentIstioAddon(istio *Istio, comp v1alpha1.Component, isDel bool, kubeconfigs []string) (string, error) {
@@ -228,7 +313,7 @@ func handleComponentIstioAddon(istio *Istio, comp v1alpha1.Component, isDel bool
 	// Get the templates
 	templates := config.GetOperations(common.Operations, version)[addonName].Templates
 
-	_, err := istio.installAddon(comp.Namespace, isDel, svc, patches, templates, kubeconfigs)
+	_, _, _, err := istio.installAddon(context.TODO(), comp.Namespace, isDel, svc, patches, templates, extraMetadata{}, "", true, nil, kubeconfigs)
 
 	msg := fmt.Sprintf("created service of type \"%s\"", comp.Spec.Type)
 	if isDel {
@@ -238,6 +323,83 @@ func handleComponentIstioAddon(istio *Istio, comp v1alpha1.Component, isDel bool
 	return msg, err
 }
 
+func handleComponentSidecar(istio *Istio, comp v1alpha1.Component, isDel bool, kubeconfigs []string) (string, error) {
+	sidecar, err := sidecarManifest(comp, isDel)
+	if err != nil {
+		return "", err
+	}
+
+	yamlByt, err := yaml.Marshal(sidecar)
+	if err != nil {
+		return "", ErrParseOAMComponent(comp.Name, err)
+	}
+	msg := fmt.Sprintf("created Sidecar \"%s\" in namespace \"%s\"", comp.Name, comp.Namespace)
+	if isDel {
+		msg = fmt.Sprintf("deleted Sidecar \"%s\" in namespace \"%s\"", comp.Name, comp.Namespace)
+	}
+
+	return msg, istio.applyManifest(context.TODO(), yamlByt, isDel, comp.Namespace, kubeconfigs)
+}
+
+// sidecarManifest renders the Sidecar resource scoping the config pushed to
+// the proxies from the egress, workloadSelector, ingress and
+// outboundTrafficPolicy settings of the component. The settings aren't needed
+// to delete the Sidecar as it is removed by name and namespace
+func sidecarManifest(comp v1alpha1.Component, isDel bool) (map[string]interface{}, error) {
+	sidecar := map[string]interface{}{
+		"apiVersion": "networking.istio.io/v1beta1",
+		"kind":       "Sidecar",
+		"metadata": map[string]interface{}{
+			"name":      comp.Name,
+			"namespace": comp.Namespace,
+		},
+	}
+	if isDel {
+		return sidecar, nil
+	}
+
+	egress, ok := comp.Spec.Settings["egress"].([]interface{})
+	if !ok || len(egress) == 0 {
+		return nil, ErrParseOAMComponent(comp.Name, fmt.Errorf("egress is required for Sidecar"))
+	}
+	for i, listener := range egress {
+		l, ok := listener.(map[string]interface{})
+		if !ok {
+			return nil, ErrParseOAMComponent(comp.Name, fmt.Errorf("egress[%d] must be an object", i))
+		}
+		hosts, ok := l["hosts"].([]interface{})
+		if !ok || len(hosts) == 0 {
+			return nil, ErrParseOAMComponent(comp.Name, fmt.Errorf("egress[%d].hosts is required", i))
+		}
+		for _, host := range hosts {
+			// Hosts are in the namespace/dnsName format
+			if h, ok := host.(string); !ok || !strings.Contains(h, "/") {
+				return nil, ErrParseOAMComponent(comp.Name, fmt.Errorf("invalid egress[%d] host %v, hosts must be in the namespace/dnsName format", i, host))
+			}
+		}
+	}
+	spec := map[string]interface{}{
+		"egress": egress,
+	}
+	if selector, ok := comp.Spec.Settings["workloadSelector"]; ok {
+		s, ok := selector.(map[string]interface{})
+		if !ok {
+			return nil, ErrParseOAMComponent(comp.Name, fmt.Errorf("workloadSelector must be an object"))
+		}
+		if _, ok := s["labels"].(map[string]interface{}); !ok {
+			return nil, ErrParseOAMComponent(comp.Name, fmt.Errorf("workloadSelector.labels is required"))
+		}
+		spec["workloadSelector"] = selector
+	}
+	for _, setting := range []string{"ingress", "outboundTrafficPolicy"} {
+		if value, ok := comp.Spec.Settings[setting]; ok {
+			spec[setting] = value
+		}
+	}
+	sidecar["spec"] = spec
+	return sidecar, nil
+}
+
 func castSliceInterfaceToSliceString(in []interface{}) []string {
 	var out []string
 
@@ -0,0 +1,186 @@
+package istio
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/layer5io/meshkit/errors"
+	"github.com/layer5io/meshkit/models/oam/core/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSidecarManifest(t *testing.T) {
+	component := func(settings map[string]interface{}) v1alpha1.Component {
+		return v1alpha1.Component{
+			ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "bookinfo"},
+			Spec:       v1alpha1.ComponentSpec{Type: "Sidecar", Settings: settings},
+		}
+	}
+	egress := []interface{}{
+		map[string]interface{}{"hosts": []interface{}{"./*", "istio-system/*"}},
+	}
+
+	tests := []struct {
+		name     string
+		settings map[string]interface{}
+		isDel    bool
+		wantSpec bool
+		wantErr  bool
+	}{
+		{
+			name: "egress and workload selector",
+			settings: map[string]interface{}{
+				"egress":           egress,
+				"workloadSelector": map[string]interface{}{"labels": map[string]interface{}{"app": "reviews"}},
+			},
+			wantSpec: true,
+		},
+		{
+			name:    "missing egress",
+			wantErr: true,
+		},
+		{
+			name: "host without namespace",
+			settings: map[string]interface{}{
+				"egress": []interface{}{map[string]interface{}{"hosts": []interface{}{"reviews"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "workload selector without labels",
+			settings: map[string]interface{}{
+				"egress":           egress,
+				"workloadSelector": map[string]interface{}{},
+			},
+			wantErr: true,
+		},
+		{
+			name:  "delete by name",
+			isDel: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sidecarManifest(component(tt.settings), tt.isDel)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("sidecarManifest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got["kind"] != "Sidecar" || got["apiVersion"] != "networking.istio.io/v1beta1" {
+				t.Errorf("sidecarManifest() = %s %s, want networking.istio.io/v1beta1 Sidecar", got["apiVersion"], got["kind"])
+			}
+			if _, ok := got["spec"]; ok != tt.wantSpec {
+				t.Errorf("sidecarManifest() has spec = %v, want %v", ok, tt.wantSpec)
+			}
+		})
+	}
+}
+
+func TestWasmPluginManifest(t *testing.T) {
+	component := func(settings map[string]interface{}) v1alpha1.Component {
+		return v1alpha1.Component{
+			ObjectMeta: metav1.ObjectMeta{Name: "basic-auth", Namespace: "bookinfo"},
+			Spec:       v1alpha1.ComponentSpec{Type: "WasmPlugin", Settings: settings},
+		}
+	}
+	url := "oci://ghcr.io/istio-ecosystem/wasm-extensions/basic_auth:1.12.0"
+
+	tests := []struct {
+		name     string
+		settings map[string]interface{}
+		isDel    bool
+		wantSpec map[string]interface{}
+		wantErr  string
+	}{
+		{
+			name: "all settings",
+			settings: map[string]interface{}{
+				"url":          url,
+				"pluginConfig": map[string]interface{}{"basic_auth_rules": []interface{}{}},
+				"phase":        "AUTHN",
+				"priority":     float64(10),
+				"selector":     map[string]interface{}{"matchLabels": map[string]interface{}{"app": "productpage"}},
+			},
+			wantSpec: map[string]interface{}{
+				"url":          url,
+				"pluginConfig": map[string]interface{}{"basic_auth_rules": []interface{}{}},
+				"phase":        "AUTHN",
+				"priority":     int64(10),
+				"selector":     map[string]interface{}{"matchLabels": map[string]interface{}{"app": "productpage"}},
+			},
+		},
+		{
+			name:     "url only",
+			settings: map[string]interface{}{"url": url},
+			wantSpec: map[string]interface{}{"url": url},
+		},
+		{
+			name:     "missing url",
+			settings: map[string]interface{}{"phase": "AUTHN"},
+			wantErr:  "url is required",
+		},
+		{
+			name:     "invalid phase",
+			settings: map[string]interface{}{"url": url, "phase": "AUTHENTICATION"},
+			wantErr:  "invalid phase AUTHENTICATION",
+		},
+		{
+			name:     "non string phase",
+			settings: map[string]interface{}{"url": url, "phase": float64(1)},
+			wantErr:  "invalid phase 1",
+		},
+		{
+			name:     "fractional priority",
+			settings: map[string]interface{}{"url": url, "priority": 1.5},
+			wantErr:  "priority must be an integer",
+		},
+		{
+			name:     "non number priority",
+			settings: map[string]interface{}{"url": url, "priority": "10"},
+			wantErr:  "priority must be an integer",
+		},
+		{
+			name:     "non object pluginConfig",
+			settings: map[string]interface{}{"url": url, "pluginConfig": "basic_auth_rules: []"},
+			wantErr:  "pluginConfig must be an object",
+		},
+		{
+			name:  "delete by name",
+			isDel: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := wasmPluginManifest(component(tt.settings), tt.isDel)
+			if tt.wantErr != "" {
+				if errors.GetCode(err) != ErrParseOAMComponentCode || !strings.Contains(err.Error(), "basic-auth") || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("wasmPluginManifest() error = %v, want %s of basic-auth containing %q", err, ErrParseOAMComponentCode, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("wasmPluginManifest() error = %v", err)
+			}
+			if got["kind"] != "WasmPlugin" || got["apiVersion"] != "extensions.istio.io/v1alpha1" {
+				t.Errorf("wasmPluginManifest() = %s %s, want extensions.istio.io/v1alpha1 WasmPlugin", got["apiVersion"], got["kind"])
+			}
+			metadata := map[string]interface{}{"name": "basic-auth", "namespace": "bookinfo"}
+			if !reflect.DeepEqual(got["metadata"], metadata) {
+				t.Errorf("wasmPluginManifest() metadata = %v, want %v", got["metadata"], metadata)
+			}
+			spec, ok := got["spec"]
+			if tt.wantSpec == nil {
+				if ok {
+					t.Errorf("wasmPluginManifest() spec = %v, want a manifest with the name only", spec)
+				}
+				return
+			}
+			if !reflect.DeepEqual(spec, tt.wantSpec) {
+				t.Errorf("wasmPluginManifest() spec = %v, want %v", spec, tt.wantSpec)
+			}
+		})
+	}
+}
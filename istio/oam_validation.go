@@ -0,0 +1,112 @@
+package istio
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/layer5io/meshkit/models/oam/core/v1alpha1"
+	"gopkg.in/yaml.v2"
+)
+
+// validateComponents validates the Istio resources rendered from the
+// components with istioctl validate, so that invalid components are reported
+// before anything is applied. The errors of all the invalid components are
+// returned, each prefixed with its component.
+//
+// The components which don't render to a single Istio resource, IstioMesh and
+// the addons, are not validated. istioctl is of the version of the IstioMesh
+// component of the request, else of the first component having a version, an
+// error being returned if no component has one.
+func (istio *Istio) validateComponents(comps []v1alpha1.Component) error {
+	var errs []error
+	version := meshVersion(comps)
+	manifests := map[string][]byte{}
+	var names []string
+	for _, comp := range comps {
+		name := fmt.Sprintf("%s %s", comp.Spec.Type, comp.Name)
+		manifest, err := renderComponent(comp)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		if manifest == nil {
+			continue
+		}
+		manifests[name] = manifest
+		names = append(names, name)
+	}
+	if len(manifests) == 0 {
+		return mergeErrors(errs)
+	}
+
+	if version == "" {
+		return fmt.Errorf("unable to validate the components: no Istio version is set, set the version of the IstioMesh component")
+	}
+	executable, err := istio.getExecutable(version)
+	if err != nil {
+		return fmt.Errorf("unable to validate the components with istioctl %s: %w", version, err)
+	}
+	for _, name := range names {
+		if err := validateManifest(executable, manifests[name]); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return mergeErrors(errs)
+}
+
+// meshVersion returns the version of the IstioMesh component, or else the
+// version of the first component having one
+func meshVersion(comps []v1alpha1.Component) string {
+	var version string
+	for _, comp := range comps {
+		if comp.Spec.Type == "IstioMesh" && comp.Spec.Version != "" {
+			return comp.Spec.Version
+		}
+		if version == "" {
+			version = comp.Spec.Version
+		}
+	}
+	return version
+}
+
+// renderComponent renders the manifest the component is applied as, nil is
+// returned for the components which don't render to a single Istio resource
+func renderComponent(comp v1alpha1.Component) ([]byte, error) {
+	var resource map[string]interface{}
+	var err error
+	switch comp.Spec.Type {
+	case "IstioMesh", "GrafanaIstioAddon", "PrometheusIstioAddon", "ZipkinIstioAddon", "JaegerIstioAddon":
+		return nil, nil
+	case "WasmPlugin":
+		resource, err = wasmPluginManifest(comp, false)
+	case "Sidecar":
+		resource, err = sidecarManifest(comp, false)
+	default:
+		manifest, _, err := renderIstioCoreComponent(comp, "", "")
+		return manifest, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(resource)
+}
+
+// validateManifest runs istioctl validate on the manifest, the error holds
+// the reasons istioctl reports the manifest invalid for
+func validateManifest(executable string, manifest []byte) error {
+	f, err := os.CreateTemp("", "oam-component-*.yaml")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(manifest); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	_, err = runIstioctl(executable, "validate", "-f", f.Name())
+	return err
+}
@@ -0,0 +1,103 @@
+package istio
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/layer5io/meshkit/models/oam/core/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIstio_validateComponents(t *testing.T) {
+	if platform == "windows" {
+		t.Skip("the fake istioctl is a shell script")
+	}
+	// The fake istioctl rejects the manifests with an invalid host
+	dir := t.TempDir()
+	script := "#!/bin/sh\nif grep -q 'invalid host' \"$3\"; then echo 'Error: 1 error occurred: invalid host' >&2; exit 1; fi\n"
+	if err := os.WriteFile(path.Join(dir, "istioctl"), []byte(script), 0750); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	virtualService := func(name, host string) v1alpha1.Component {
+		return v1alpha1.Component{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+				Annotations: map[string]string{
+					v1alpha1.MesheryAnnotationPrefix + ".k8s.APIVersion": "networking.istio.io/v1beta1",
+					v1alpha1.MesheryAnnotationPrefix + ".k8s.Kind":       "VirtualService",
+				},
+			},
+			Spec: v1alpha1.ComponentSpec{
+				Type:     "VirtualService",
+				Settings: map[string]interface{}{"hosts": []interface{}{host}},
+			},
+		}
+	}
+	wasm := v1alpha1.Component{
+		ObjectMeta: metav1.ObjectMeta{Name: "no-url"},
+		Spec:       v1alpha1.ComponentSpec{Type: "WasmPlugin"},
+	}
+	mesh := v1alpha1.Component{
+		ObjectMeta: metav1.ObjectMeta{Name: "istio"},
+		Spec:       v1alpha1.ComponentSpec{Type: "IstioMesh", Version: "1.19.3"},
+	}
+
+	istio := &Istio{Adapter: adapter.Adapter{Log: getLoggerHandler(t)}}
+
+	if err := istio.validateComponents([]v1alpha1.Component{virtualService("reviews", "reviews"), mesh}); err != nil {
+		t.Errorf("validateComponents() of valid components error = %v", err)
+	}
+
+	if err := istio.validateComponents([]v1alpha1.Component{virtualService("reviews", "reviews")}); err == nil || !strings.Contains(err.Error(), "no Istio version") {
+		t.Errorf("validateComponents() without version error = %v, want no Istio version error", err)
+	}
+
+	err := istio.validateComponents([]v1alpha1.Component{virtualService("reviews", "reviews"), virtualService("ratings", "invalid host"), wasm, mesh})
+	if err == nil {
+		t.Fatalf("validateComponents() of invalid components succeeded, want error")
+	}
+	for _, want := range []string{"VirtualService ratings: ", "invalid host", "WasmPlugin no-url: "} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("validateComponents() error = %v, want it to contain %q", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "VirtualService reviews") {
+		t.Errorf("validateComponents() error = %v, reports the valid component", err)
+	}
+}
+
+func TestMeshVersion(t *testing.T) {
+	component := func(kind, version string) v1alpha1.Component {
+		return v1alpha1.Component{Spec: v1alpha1.ComponentSpec{Type: kind, Version: version}}
+	}
+	tests := []struct {
+		name  string
+		comps []v1alpha1.Component
+		want  string
+	}{
+		{name: "no components"},
+		{name: "no version", comps: []v1alpha1.Component{component("VirtualService", "")}},
+		{
+			name:  "mesh version first",
+			comps: []v1alpha1.Component{component("VirtualService", "1.18.0"), component("IstioMesh", "1.19.3")},
+			want:  "1.19.3",
+		},
+		{
+			name:  "first component version without mesh",
+			comps: []v1alpha1.Component{component("VirtualService", ""), component("Gateway", "1.18.0"), component("Sidecar", "1.17.0")},
+			want:  "1.18.0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := meshVersion(tt.comps); got != tt.want {
+				t.Errorf("meshVersion() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
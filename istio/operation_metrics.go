@@ -0,0 +1,78 @@
+package istio
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Outcomes of an operation the metrics are labeled with
+const (
+	outcomeSuccess = "success"
+	outcomeFailure = "failure"
+)
+
+// unknownOperation labels the operations the adapter doesn't offer, hence
+// the operation label only takes the names of the operations of the adapter
+const unknownOperation = "unknown"
+
+// adapterMetrics are the metrics of the operations applied by the adapter,
+// registered once with the default registry
+var adapterMetrics = newOperationMetrics(prometheus.DefaultRegisterer)
+
+// operationMetrics counts the operations by name and outcome, times them and
+// tracks the ones in flight
+type operationMetrics struct {
+	total    *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	inFlight *prometheus.GaugeVec
+}
+
+func newOperationMetrics(reg prometheus.Registerer) *operationMetrics {
+	m := &operationMetrics{
+		total: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "meshery_istio",
+			Name:      "operations_total",
+			Help:      "Number of the operations the adapter applied, by operation and outcome.",
+		}, []string{"operation", "outcome"}),
+		// The installs take minutes, hence the buckets go from a second up
+		// to about half an hour
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "meshery_istio",
+			Name:      "operation_duration_seconds",
+			Help:      "Duration of the operations the adapter applied, by operation and outcome.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 12),
+		}, []string{"operation", "outcome"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "meshery_istio",
+			Name:      "operations_in_flight",
+			Help:      "Number of the operations the adapter is applying, by operation.",
+		}, []string{"operation"}),
+	}
+	reg.MustRegister(m.total, m.duration, m.inFlight)
+	return m
+}
+
+// start counts the operation as in flight and returns the function to call
+// once it returns, reporting whether it failed
+func (m *operationMetrics) start(operation string) func(failed bool) {
+	started := time.Now()
+	m.inFlight.WithLabelValues(operation).Inc()
+	return func(failed bool) {
+		outcome := outcomeSuccess
+		if failed {
+			outcome = outcomeFailure
+		}
+		m.inFlight.WithLabelValues(operation).Dec()
+		m.total.WithLabelValues(operation, outcome).Inc()
+		m.duration.WithLabelValues(operation, outcome).Observe(time.Since(started).Seconds())
+	}
+}
+
+// MetricsHandler serves the metrics of the operations of the adapter, along
+// with the Go runtime and process metrics, in the Prometheus format
+func MetricsHandler() http.Handler {
+	return promhttp.Handler()
+}
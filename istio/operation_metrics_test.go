@@ -0,0 +1,60 @@
+package istio
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestOperationMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := newOperationMetrics(reg)
+
+	installed := m.start("istio")
+	failed := m.start("istio")
+	running := m.start("bookinfo")
+	installed(false)
+	failed(true)
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	metrics := map[string][]*dto.Metric{}
+	for _, family := range families {
+		metrics[family.GetName()] = family.GetMetric()
+	}
+	labels := func(metric *dto.Metric) map[string]string {
+		l := map[string]string{}
+		for _, pair := range metric.GetLabel() {
+			l[pair.GetName()] = pair.GetValue()
+		}
+		return l
+	}
+
+	totals := map[string]float64{}
+	for _, metric := range metrics["meshery_istio_operations_total"] {
+		l := labels(metric)
+		totals[l["operation"]+"/"+l["outcome"]] = metric.GetCounter().GetValue()
+	}
+	if totals["istio/"+outcomeSuccess] != 1 || totals["istio/"+outcomeFailure] != 1 || len(totals) != 2 {
+		t.Errorf("operations_total = %v, want one success and one failure of istio", totals)
+	}
+
+	for _, metric := range metrics["meshery_istio_operation_duration_seconds"] {
+		if count := metric.GetHistogram().GetSampleCount(); count != 1 {
+			t.Errorf("operation_duration_seconds %v sample count = %d, want 1", labels(metric), count)
+		}
+	}
+
+	inFlight := map[string]float64{}
+	for _, metric := range metrics["meshery_istio_operations_in_flight"] {
+		inFlight[labels(metric)["operation"]] = metric.GetGauge().GetValue()
+	}
+	if inFlight["istio"] != 0 || inFlight["bookinfo"] != 1 {
+		t.Errorf("operations_in_flight = %v, want bookinfo in flight only", inFlight)
+	}
+
+	running(false)
+}
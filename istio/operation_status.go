@@ -0,0 +1,279 @@
+package istio
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/layer5io/meshery-adapter-library/meshes"
+)
+
+// operationStatusTTL is how long the status of an operation is kept after it
+// was last updated
+var operationStatusTTL = time.Hour
+
+// OperationState is the machine readable state of an operation or of an
+// operation on a cluster, the summary and details of the events being meant
+// for display. Every event streamed by the adapter carries the state of its
+// operation as the prefix of its summary, in brackets followed by a space:
+//
+//	[STARTED] the first event streamed by the operation
+//	[IN_PROGRESS] the events of its progress that follow
+//	[SUCCESS] or [FAILED] the event of its outcome, failed if it's an error
+//
+// The clients match the prefix to follow the operation without parsing the
+// rest of the summary, which is not stable.
+type OperationState string
+
+// States of an operation, an operation is started once requested and in
+// progress from its first event until it returns
+const (
+	OperationStarted    OperationState = "STARTED"
+	OperationInProgress OperationState = "IN_PROGRESS"
+	OperationSucceeded  OperationState = "SUCCESS"
+	OperationFailed     OperationState = "FAILED"
+)
+
+// ClusterStatus is the latest event of an operation on a cluster
+type ClusterStatus struct {
+	State     OperationState
+	EventType meshes.EventType
+	Summary   string
+	Details   string
+	Updated   time.Time
+}
+
+// OperationStatus is the latest event streamed by an operation, kept for the
+// clients whose event stream dropped to poll for the progress they missed
+type OperationStatus struct {
+	OperationID string
+	State       OperationState
+	EventType   meshes.EventType
+	Summary     string
+	Details     string
+	Updated     time.Time
+
+	// Done reports whether the operation returned, its last event being its
+	// outcome
+	Done bool
+
+	// Clusters are the latest events of the operation on every cluster it
+	// reported the progress on, keyed by cluster
+	Clusters map[string]ClusterStatus
+
+	// root is the event the operation streams its outcome with, the events
+	// of its progress being new ones, and outcome the type of the last
+	// event streamed with it
+	root    *meshes.EventsResponse
+	outcome *meshes.EventType
+
+	// streamed reports whether the operation streamed an event already
+	streamed bool
+}
+
+// operationStatuses are the statuses of the operations by their operation ID,
+// the ones not updated for operationStatusTTL being evicted
+type operationStatuses struct {
+	mx       sync.Mutex
+	statuses map[string]*OperationStatus
+	now      func() time.Time
+}
+
+func (s *operationStatuses) time() time.Time {
+	if s.now != nil {
+		return s.now()
+	}
+	return time.Now()
+}
+
+// start records the operation as started, e being the event its outcome is
+// streamed with
+func (s *operationStatuses) start(e *meshes.EventsResponse) {
+	if e.OperationId == "" {
+		return
+	}
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	now := s.time()
+	s.evict(now)
+	if s.statuses == nil {
+		s.statuses = map[string]*OperationStatus{}
+	}
+	s.statuses[e.OperationId] = &OperationStatus{OperationID: e.OperationId, State: OperationStarted, Updated: now, root: e}
+}
+
+// record records the event as the latest status of its operation and, if
+// cluster is set, as the latest one on the cluster in the given state
+func (s *operationStatuses) record(cluster string, state OperationState, e *meshes.EventsResponse) {
+	if e.OperationId == "" {
+		return
+	}
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	now := s.time()
+	s.evict(now)
+	if s.statuses == nil {
+		s.statuses = map[string]*OperationStatus{}
+	}
+	st, ok := s.statuses[e.OperationId]
+	if !ok {
+		st = &OperationStatus{OperationID: e.OperationId}
+		s.statuses[e.OperationId] = st
+	}
+	st.EventType, st.Summary, st.Details, st.Updated = e.EventType, e.Summary, e.Details, now
+	if !st.Done {
+		st.State = OperationInProgress
+	}
+	if e == st.root {
+		eventType := e.EventType
+		st.outcome = &eventType
+	}
+	if cluster != "" {
+		if st.Clusters == nil {
+			st.Clusters = map[string]ClusterStatus{}
+		}
+		st.Clusters[cluster] = ClusterStatus{State: state, EventType: e.EventType, Summary: e.Summary, Details: e.Details, Updated: now}
+	}
+}
+
+// finish marks the operation as done, it failed if its outcome is an error
+// or, if it streamed no outcome, if its latest event is an error
+func (s *operationStatuses) finish(operationID string) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	if st, ok := s.statuses[operationID]; ok {
+		outcome := st.EventType
+		if st.outcome != nil {
+			outcome = *st.outcome
+		}
+		st.State = outcomeState(outcome)
+		st.Done = true
+		st.Updated = s.time()
+		st.root = nil
+	}
+}
+
+// streamedState returns the state of the operation the event is streamed
+// in, its outcome if it's the event of the operation and STARTED if it's
+// the first one streamed
+func (s *operationStatuses) streamedState(e *meshes.EventsResponse) OperationState {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	st, ok := s.statuses[e.OperationId]
+	if !ok {
+		return OperationInProgress
+	}
+	first := !st.streamed
+	st.streamed = true
+	switch {
+	case e == st.root:
+		return outcomeState(e.EventType)
+	case first:
+		return OperationStarted
+	}
+	return OperationInProgress
+}
+
+// outcomeState returns the state of an operation whose outcome is of the
+// event type
+func outcomeState(eventType meshes.EventType) OperationState {
+	if eventType == meshes.EventType_ERROR {
+		return OperationFailed
+	}
+	return OperationSucceeded
+}
+
+// stateSummary returns the summary prefixed with the state
+func stateSummary(state OperationState, summary string) string {
+	return fmt.Sprintf("[%s] %s", state, trimStateSummary(summary))
+}
+
+// trimStateSummary returns the summary without its state prefix, the events
+// of the operations being streamed again at times
+func trimStateSummary(summary string) string {
+	for _, state := range []OperationState{OperationStarted, OperationInProgress, OperationSucceeded, OperationFailed} {
+		if trimmed := strings.TrimPrefix(summary, fmt.Sprintf("[%s] ", state)); trimmed != summary {
+			return trimmed
+		}
+	}
+	return summary
+}
+
+// get returns a copy of the status of the operation
+func (s *operationStatuses) get(operationID string) (OperationStatus, bool) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	s.evict(s.time())
+	st, ok := s.statuses[operationID]
+	if !ok {
+		return OperationStatus{}, false
+	}
+	status := *st
+	status.root, status.outcome = nil, nil
+	if st.Clusters != nil {
+		status.Clusters = make(map[string]ClusterStatus, len(st.Clusters))
+		for cluster, cs := range st.Clusters {
+			status.Clusters[cluster] = cs
+		}
+	}
+	return status, true
+}
+
+func (s *operationStatuses) evict(now time.Time) {
+	for id, st := range s.statuses {
+		if now.Sub(st.Updated) > operationStatusTTL {
+			delete(s.statuses, id)
+		}
+	}
+}
+
+// operationStatusDetails describes the status of the operation for the
+// event details, its state and latest event followed by the ones of every
+// cluster
+func operationStatusDetails(st OperationStatus) string {
+	lines := []string{fmt.Sprintf("%s, %s at %s: %s", st.State, st.EventType, st.Updated.Format(time.RFC3339), st.Summary)}
+	if st.Details != "" {
+		lines = append(lines, st.Details)
+	}
+	clusters := make([]string, 0, len(st.Clusters))
+	for cluster := range st.Clusters {
+		clusters = append(clusters, cluster)
+	}
+	sort.Strings(clusters)
+	for _, cluster := range clusters {
+		cs := st.Clusters[cluster]
+		lines = append(lines, fmt.Sprintf("%s: %s, %s at %s: %s", cluster, cs.State, cs.EventType, cs.Updated.Format(time.RFC3339), cs.Summary))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// GetOperationStatus returns the latest status of the operation, overall and
+// on every cluster, as long as it was updated within the last
+// operationStatusTTL
+func (istio *Istio) GetOperationStatus(operationID string) (OperationStatus, bool) {
+	return istio.statuses.get(operationID)
+}
+
+// StreamInfo records the event as the status of its operation and streams it
+func (istio *Istio) StreamInfo(e *meshes.EventsResponse) {
+	e.EventType = meshes.EventType_INFO
+	istio.recordStreamed(e)
+	istio.Adapter.StreamInfo(e)
+}
+
+// StreamErr records the event as the status of its operation and streams it
+func (istio *Istio) StreamErr(e *meshes.EventsResponse, err error) {
+	e.EventType = meshes.EventType_ERROR
+	istio.recordStreamed(e)
+	istio.Adapter.StreamErr(e, err)
+}
+
+// recordStreamed records the event as the status of its operation and
+// prefixes its summary with the state of the operation
+func (istio *Istio) recordStreamed(e *meshes.EventsResponse) {
+	e.Summary = trimStateSummary(e.Summary)
+	istio.statuses.record("", "", e)
+	e.Summary = stateSummary(istio.statuses.streamedState(e), e.Summary)
+}
@@ -0,0 +1,136 @@
+package istio
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/layer5io/meshery-adapter-library/meshes"
+)
+
+func TestOperationStatuses(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	s := &operationStatuses{now: func() time.Time { return now }}
+
+	root := &meshes.EventsResponse{OperationId: "op-1"}
+	s.start(root)
+	if st, _ := s.get("op-1"); st.State != OperationStarted {
+		t.Errorf("get() of a started operation State = %s, want %s", st.State, OperationStarted)
+	}
+	s.record("", "", &meshes.EventsResponse{OperationId: "op-1", Summary: "Installing Istio 40% done: istiod"})
+	s.record("kind-east", OperationSucceeded, &meshes.EventsResponse{OperationId: "op-1", Summary: "Finished installing Istio on cluster kind-east"})
+	s.record("kind-west", OperationFailed, &meshes.EventsResponse{OperationId: "op-1", EventType: meshes.EventType_ERROR, Summary: "Error while installing Istio on cluster kind-west"})
+	s.record("", "", &meshes.EventsResponse{Summary: "no operation ID"})
+
+	st, ok := s.get("op-1")
+	if !ok {
+		t.Fatal("get() found no status of op-1")
+	}
+	if st.Done || st.State != OperationInProgress || st.EventType != meshes.EventType_ERROR || !strings.HasPrefix(st.Summary, "Error while installing") {
+		t.Errorf("get() = %+v, want the latest event of op-1 in progress", st)
+	}
+	if len(st.Clusters) != 2 || st.Clusters["kind-east"].State != OperationSucceeded || st.Clusters["kind-west"].State != OperationFailed || st.Clusters["kind-west"].EventType != meshes.EventType_ERROR {
+		t.Errorf("get() clusters = %+v, want the latest event of both clusters", st.Clusters)
+	}
+	st.Clusters["kind-east"] = ClusterStatus{Summary: "changed"}
+	if st, _ := s.get("op-1"); st.Clusters["kind-east"].Summary == "changed" {
+		t.Error("get() returned the recorded status instead of a copy")
+	}
+
+	// The outcome is streamed with the event of the operation, the error
+	// of a cluster not failing it
+	root.EventType = meshes.EventType_INFO
+	root.Summary = "Istio service mesh 1.22.0 installed successfully"
+	s.record("", "", root)
+	s.finish("op-1")
+	if st, _ := s.get("op-1"); !st.Done || st.State != OperationSucceeded {
+		t.Errorf("get() of a finished operation Done = %v State = %s, want true %s", st.Done, st.State, OperationSucceeded)
+	}
+	if _, ok := s.get("op-2"); ok {
+		t.Error("get() of an unknown operation found a status")
+	}
+
+	s.record("", "", &meshes.EventsResponse{OperationId: "op-3", EventType: meshes.EventType_ERROR, Summary: "Error while installing Istio"})
+	s.finish("op-3")
+	if st, _ := s.get("op-3"); st.State != OperationFailed {
+		t.Errorf("get() of an operation finished with an error State = %s, want %s", st.State, OperationFailed)
+	}
+
+	now = now.Add(operationStatusTTL + time.Second)
+	if _, ok := s.get("op-1"); ok {
+		t.Error("get() of an operation not updated within the TTL found a status, want it evicted")
+	}
+}
+
+func TestOperationStatusDetails(t *testing.T) {
+	updated := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	got := operationStatusDetails(OperationStatus{
+		OperationID: "op-1",
+		State:       OperationInProgress,
+		Summary:     "Installing Istio 40% done: istiod",
+		Updated:     updated,
+		Clusters: map[string]ClusterStatus{
+			"kind-west": {State: OperationFailed, EventType: meshes.EventType_ERROR, Summary: "Error", Updated: updated},
+			"kind-east": {State: OperationSucceeded, Summary: "Finished", Updated: updated},
+		},
+	})
+	want := "IN_PROGRESS, INFO at 2024-05-01T10:00:00Z: Installing Istio 40% done: istiod\nkind-east: SUCCESS, INFO at 2024-05-01T10:00:00Z: Finished\nkind-west: FAILED, ERROR at 2024-05-01T10:00:00Z: Error"
+	if got != want {
+		t.Errorf("operationStatusDetails() = %q, want %q", got, want)
+	}
+}
+
+func TestStreamedState(t *testing.T) {
+	s := &operationStatuses{}
+	root := &meshes.EventsResponse{OperationId: "op-1"}
+	s.start(root)
+
+	first := &meshes.EventsResponse{OperationId: "op-1", Summary: "Installing Istio 10% done: base"}
+	progress := &meshes.EventsResponse{OperationId: "op-1", Summary: "Installing Istio 40% done: istiod"}
+	if got := s.streamedState(first); got != OperationStarted {
+		t.Errorf("streamedState() of the first event = %s, want %s", got, OperationStarted)
+	}
+	if got := s.streamedState(progress); got != OperationInProgress {
+		t.Errorf("streamedState() of a progress event = %s, want %s", got, OperationInProgress)
+	}
+	root.EventType = meshes.EventType_ERROR
+	if got := s.streamedState(root); got != OperationFailed {
+		t.Errorf("streamedState() of an error outcome = %s, want %s", got, OperationFailed)
+	}
+	root.EventType = meshes.EventType_INFO
+	if got := s.streamedState(root); got != OperationSucceeded {
+		t.Errorf("streamedState() of an info outcome = %s, want %s", got, OperationSucceeded)
+	}
+	if got := s.streamedState(&meshes.EventsResponse{Summary: "no operation ID"}); got != OperationInProgress {
+		t.Errorf("streamedState() of an event of no operation = %s, want %s", got, OperationInProgress)
+	}
+
+	// The outcome streamed first is the only event of the operation
+	root = &meshes.EventsResponse{OperationId: "op-2", EventType: meshes.EventType_INFO}
+	s.start(root)
+	if got := s.streamedState(root); got != OperationSucceeded {
+		t.Errorf("streamedState() of an outcome streamed first = %s, want %s", got, OperationSucceeded)
+	}
+}
+
+func TestStateSummary(t *testing.T) {
+	tests := []struct {
+		state   OperationState
+		summary string
+		want    string
+	}{
+		{OperationStarted, "Installing Istio", "[STARTED] Installing Istio"},
+		{OperationInProgress, "[STARTED] Installing Istio", "[IN_PROGRESS] Installing Istio"},
+		{OperationSucceeded, "[IN_PROGRESS] Istio installed", "[SUCCESS] Istio installed"},
+		{OperationFailed, "[INFO] Error while installing Istio", "[FAILED] [INFO] Error while installing Istio"},
+		{OperationSucceeded, "", "[SUCCESS] "},
+	}
+	for _, tt := range tests {
+		if got := stateSummary(tt.state, tt.summary); got != tt.want {
+			t.Errorf("stateSummary(%s, %q) = %q, want %q", tt.state, tt.summary, got, tt.want)
+		}
+		if got := trimStateSummary(stateSummary(tt.state, tt.summary)); got != strings.TrimPrefix(tt.want, "["+string(tt.state)+"] ") {
+			t.Errorf("trimStateSummary() = %q, want the summary without its state", got)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package istio
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// istioOperator is the part of an IstioOperator the adapter needs to know to
+// install it, the rest of the manifest is passed to istioctl as is
+type istioOperator struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Spec       *struct {
+		Profile  string `yaml:"profile"`
+		Revision string `yaml:"revision"`
+	} `yaml:"spec"`
+}
+
+// useOperatorManifest makes the install apply the IstioOperator manifest
+// verbatim with istioctl instead of the one rendered from the options. The
+// profile and revision of the manifest replace the ones of the options, for
+// the install to wait for the deployments of the manifest, and the proxy
+// resources, the tracing sampling, the scaling of istiod, the CA, the trust
+// domain and the CNI plugin are left to the manifest.
+func (o *installOptions) useOperatorManifest(manifest string) error {
+	docs := 0
+	for _, doc := range strings.Split(strings.TrimPrefix(strings.TrimSpace(manifest), "---\n"), "\n---") {
+		if strings.TrimSpace(doc) != "" {
+			docs++
+		}
+	}
+	if docs != 1 {
+		return ErrInvalidOperatorManifest(fmt.Errorf("the manifest has %d documents, want a single IstioOperator", docs))
+	}
+
+	var operator istioOperator
+	if err := yaml.Unmarshal([]byte(manifest), &operator); err != nil {
+		return ErrInvalidOperatorManifest(err)
+	}
+	if operator.Kind != "IstioOperator" || !strings.HasPrefix(operator.APIVersion, "install.istio.io/") {
+		return ErrInvalidOperatorManifest(fmt.Errorf("got %s %s, want an install.istio.io IstioOperator", operator.APIVersion, operator.Kind))
+	}
+	if operator.Spec == nil {
+		return ErrInvalidOperatorManifest(fmt.Errorf("the IstioOperator has no spec"))
+	}
+	profile := operator.Spec.Profile
+	if profile == "" {
+		profile = "default"
+	}
+	if !installProfiles[profile] {
+		return ErrInvalidOperatorManifest(fmt.Errorf("unsupported profile %q", profile))
+	}
+
+	o.OperatorManifest = []byte(manifest)
+	o.Profile = profile
+	o.Revision = operator.Spec.Revision
+	o.ProxyResources = nil
+	o.TracingSampling = nil
+	o.Pilot = pilotScaling{}
+	o.CA = customCA{}
+	o.TrustDomain = trustDomain{}
+	o.CNI = cniConfig{}
+	return nil
+}
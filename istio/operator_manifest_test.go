@@ -0,0 +1,93 @@
+package istio
+
+import (
+	"testing"
+
+	"github.com/layer5io/meshkit/errors"
+)
+
+func TestUseOperatorManifest(t *testing.T) {
+	tests := []struct {
+		name         string
+		manifest     string
+		wantProfile  string
+		wantRevision string
+		wantErr      bool
+	}{
+		{
+			name: "profile and revision",
+			manifest: `apiVersion: install.istio.io/v1alpha1
+kind: IstioOperator
+metadata:
+  namespace: istio-system
+spec:
+  profile: demo
+  revision: 1-22
+  meshConfig:
+    accessLogFile: /dev/stdout
+`,
+			wantProfile:  "demo",
+			wantRevision: "1-22",
+		},
+		{
+			name: "default profile",
+			manifest: `---
+apiVersion: install.istio.io/v1alpha1
+kind: IstioOperator
+spec:
+  components:
+    egressGateways:
+    - name: istio-egressgateway
+      enabled: true
+`,
+			wantProfile: "default",
+		},
+		{
+			name:     "not an IstioOperator",
+			manifest: "apiVersion: v1\nkind: ConfigMap\nspec: {}\n",
+			wantErr:  true,
+		},
+		{
+			name:     "no spec",
+			manifest: "apiVersion: install.istio.io/v1alpha1\nkind: IstioOperator\n",
+			wantErr:  true,
+		},
+		{
+			name:     "unsupported profile",
+			manifest: "apiVersion: install.istio.io/v1alpha1\nkind: IstioOperator\nspec:\n  profile: openshift\n",
+			wantErr:  true,
+		},
+		{
+			name:     "several documents",
+			manifest: "apiVersion: install.istio.io/v1alpha1\nkind: IstioOperator\nspec: {}\n---\napiVersion: v1\nkind: Namespace\n",
+			wantErr:  true,
+		},
+		{
+			name:     "malformed",
+			manifest: "kind: [IstioOperator",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := installOptions{Profile: "minimal", Revision: "canary", ProxyResources: proxyResources{"limits": {"cpu": "1"}}}
+			err := opts.useOperatorManifest(tt.manifest)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("useOperatorManifest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if code := errors.GetCode(err); code != ErrInvalidOperatorManifestCode {
+					t.Errorf("useOperatorManifest() error code = %s, want %s", code, ErrInvalidOperatorManifestCode)
+				}
+				return
+			}
+			if opts.Profile != tt.wantProfile || opts.Revision != tt.wantRevision || opts.ProxyResources != nil {
+				t.Errorf("useOperatorManifest() options = %+v, want profile %s and revision %q of the manifest", opts, tt.wantProfile, tt.wantRevision)
+			}
+			rendered, err := renderIstioOperator(opts)
+			if err != nil || string(rendered) != tt.manifest {
+				t.Errorf("renderIstioOperator() = %s, %v, want the manifest verbatim", rendered, err)
+			}
+		})
+	}
+}
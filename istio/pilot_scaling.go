@@ -0,0 +1,151 @@
+package istio
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/layer5io/meshery-istio/internal/config"
+)
+
+// The defaults of the istiod chart for the bounds of its
+// HorizontalPodAutoscaler, used for the bounds the install doesn't set
+const (
+	defaultPilotMinReplicas = 1
+	defaultPilotMaxReplicas = 5
+	defaultPilotTargetCPU   = 80
+)
+
+// pilotScaling is how istiod is scaled, either a fixed replica count or the
+// bounds of its HorizontalPodAutoscaler. The zero value leaves the scaling
+// of the profile untouched.
+type pilotScaling struct {
+	// Replicas is the fixed replica count of istiod, the HPA is disabled
+	// when set
+	Replicas int
+
+	// Autoscale enables the HPA of istiod, scaling it between MinReplicas
+	// and MaxReplicas to keep its CPU utilization at TargetCPU percent
+	Autoscale   bool
+	MinReplicas int
+	MaxReplicas int
+	TargetCPU   int
+}
+
+// newPilotScaling reads the istiod scaling from the properties of the
+// install operation. Setting any of the HPA properties enables the HPA, the
+// bounds it doesn't set defaulting to the ones of the istiod chart, hence a
+// fixed replica count can't be set along with them.
+func newPilotScaling(props map[string]string) (pilotScaling, error) {
+	var s pilotScaling
+	values := map[string]int{}
+	for _, key := range []string{config.PilotReplicaCount, config.PilotMinReplicas, config.PilotMaxReplicas, config.PilotTargetCPU} {
+		value := strings.TrimSpace(props[key])
+		if key == config.PilotTargetCPU {
+			value = strings.TrimSuffix(value, "%")
+		}
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return s, ErrInvalidPilotScaling(fmt.Errorf("%s %q is not an integer", key, value))
+		}
+		if n <= 0 {
+			return s, ErrInvalidPilotScaling(fmt.Errorf("%s %d is not positive", key, n))
+		}
+		values[key] = n
+	}
+	s.Replicas = values[config.PilotReplicaCount]
+	_, min := values[config.PilotMinReplicas]
+	_, max := values[config.PilotMaxReplicas]
+	_, cpu := values[config.PilotTargetCPU]
+	if !min && !max && !cpu {
+		return s, nil
+	}
+	if s.Replicas != 0 {
+		return pilotScaling{}, ErrInvalidPilotScaling(fmt.Errorf("%s can't be set along with the HPA bounds, the HPA scales istiod", config.PilotReplicaCount))
+	}
+	s.Autoscale = true
+	s.MinReplicas, s.MaxReplicas, s.TargetCPU = defaultPilotMinReplicas, defaultPilotMaxReplicas, defaultPilotTargetCPU
+	if min {
+		s.MinReplicas = values[config.PilotMinReplicas]
+	}
+	if max {
+		s.MaxReplicas = values[config.PilotMaxReplicas]
+	}
+	if cpu {
+		s.TargetCPU = values[config.PilotTargetCPU]
+	}
+	if s.MinReplicas > s.MaxReplicas {
+		return pilotScaling{}, ErrInvalidPilotScaling(fmt.Errorf("%s %d is greater than %s %d", config.PilotMinReplicas, s.MinReplicas, config.PilotMaxReplicas, s.MaxReplicas))
+	}
+	return s, nil
+}
+
+func (s pilotScaling) empty() bool {
+	return s == pilotScaling{}
+}
+
+// String describes the effective scaling for the event details
+func (s pilotScaling) String() string {
+	if s.Autoscale {
+		return fmt.Sprintf("autoscaled between %d and %d replicas at %d%% CPU utilization", s.MinReplicas, s.MaxReplicas, s.TargetCPU)
+	}
+	return fmt.Sprintf("scaled to %d replicas", s.Replicas)
+}
+
+// values returns the pilot helm values of the istiod chart, also used as the
+// IstioOperator values, nil if the scaling isn't set
+func (s pilotScaling) values() map[string]interface{} {
+	if s.empty() {
+		return nil
+	}
+	if !s.Autoscale {
+		return map[string]interface{}{
+			"autoscaleEnabled": false,
+			"replicaCount":     s.Replicas,
+		}
+	}
+	return map[string]interface{}{
+		"autoscaleEnabled": true,
+		"autoscaleMin":     s.MinReplicas,
+		"autoscaleMax":     s.MaxReplicas,
+		"cpu": map[string]interface{}{
+			"targetAverageUtilization": s.TargetCPU,
+		},
+	}
+}
+
+// component returns the Kubernetes settings of the pilot component of the
+// IstioOperator, nil if the scaling isn't set
+func (s pilotScaling) component() map[string]interface{} {
+	if s.empty() {
+		return nil
+	}
+	k8s := map[string]interface{}{}
+	if !s.Autoscale {
+		k8s["replicaCount"] = s.Replicas
+	} else {
+		k8s["hpaSpec"] = map[string]interface{}{
+			"minReplicas": s.MinReplicas,
+			"maxReplicas": s.MaxReplicas,
+			"metrics": []interface{}{
+				map[string]interface{}{
+					"type": "Resource",
+					"resource": map[string]interface{}{
+						"name": "cpu",
+						"target": map[string]interface{}{
+							"type":               "Utilization",
+							"averageUtilization": s.TargetCPU,
+						},
+					},
+				},
+			},
+		}
+	}
+	return map[string]interface{}{
+		"enabled": true,
+		"k8s":     k8s,
+	}
+}
@@ -0,0 +1,79 @@
+package istio
+
+import (
+	"testing"
+
+	"github.com/layer5io/meshery-istio/internal/config"
+	"github.com/layer5io/meshkit/errors"
+)
+
+func TestNewPilotScaling(t *testing.T) {
+	tests := []struct {
+		name    string
+		props   map[string]string
+		want    pilotScaling
+		wantErr bool
+	}{
+		{
+			name:  "none",
+			props: map[string]string{config.PilotReplicaCount: "", config.PilotMinReplicas: ""},
+		},
+		{
+			name:  "replicas",
+			props: map[string]string{config.PilotReplicaCount: " 3 "},
+			want:  pilotScaling{Replicas: 3},
+		},
+		{
+			name:  "autoscaling",
+			props: map[string]string{config.PilotMinReplicas: "2", config.PilotMaxReplicas: "10", config.PilotTargetCPU: "60%"},
+			want:  pilotScaling{Autoscale: true, MinReplicas: 2, MaxReplicas: 10, TargetCPU: 60},
+		},
+		{
+			name:  "chart defaults",
+			props: map[string]string{config.PilotMinReplicas: "3"},
+			want:  pilotScaling{Autoscale: true, MinReplicas: 3, MaxReplicas: defaultPilotMaxReplicas, TargetCPU: defaultPilotTargetCPU},
+		},
+		{
+			name:    "min greater than max",
+			props:   map[string]string{config.PilotMinReplicas: "4", config.PilotMaxReplicas: "2"},
+			wantErr: true,
+		},
+		{
+			name:    "min greater than the default max",
+			props:   map[string]string{config.PilotMinReplicas: "8"},
+			wantErr: true,
+		},
+		{
+			name:    "zero replicas",
+			props:   map[string]string{config.PilotReplicaCount: "0"},
+			wantErr: true,
+		},
+		{
+			name:    "not an integer",
+			props:   map[string]string{config.PilotMaxReplicas: "two"},
+			wantErr: true,
+		},
+		{
+			name:    "replicas along with the HPA",
+			props:   map[string]string{config.PilotReplicaCount: "3", config.PilotMaxReplicas: "5"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newPilotScaling(tt.props)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newPilotScaling() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if errors.GetCode(err) != ErrInvalidPilotScalingCode {
+					t.Errorf("newPilotScaling() error code = %s, want %s", errors.GetCode(err), ErrInvalidPilotScalingCode)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf("newPilotScaling() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,358 @@
+package istio
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	"gopkg.in/yaml.v2"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/dynamic"
+)
+
+const istioRootNamespace = "istio-system"
+
+const (
+	// migratedPolicyLabel labels the PeerAuthentication resources created by
+	// the migration, for its removal to find them once the legacy policies
+	// are gone
+	migratedPolicyLabel = "meshery.io/migrated-policy"
+
+	// migratedFromAnnotation is the legacy policy a PeerAuthentication was
+	// migrated from
+	migratedFromAnnotation = "meshery.io/migrated-from"
+)
+
+var (
+	legacyPolicyGVR       = schema.GroupVersionResource{Group: "authentication.istio.io", Version: "v1alpha1", Resource: "policies"}
+	legacyMeshPolicyGVR   = schema.GroupVersionResource{Group: "authentication.istio.io", Version: "v1alpha1", Resource: "meshpolicies"}
+	peerAuthenticationGVR = schema.GroupVersionResource{Group: "security.istio.io", Version: "v1beta1", Resource: "peerauthentications"}
+
+	validMTLSModes = map[string]bool{
+		"STRICT":     true,
+		"PERMISSIVE": true,
+		"DISABLE":    true,
+	}
+)
+
+// PolicyMigration describes the PeerAuthentication a legacy
+// authentication policy was migrated to
+type PolicyMigration struct {
+	Cluster string   `json:"cluster"`
+	Source  string   `json:"source"`
+	Target  string   `json:"target,omitempty"`
+	Notes   []string `json:"notes,omitempty"`
+
+	manifest map[string]interface{}
+}
+
+// serviceLookup returns the selector and the target port numbers keyed by
+// port name and number of a service
+type serviceLookup func(namespace, name string) (map[string]string, map[string]int32, error)
+
+// migrateLegacyAuthPolicies detects the deprecated v1alpha1 Policy and MeshPolicy
+// resources and converts them to v1beta1 PeerAuthentication resources.
+//
+// The legacy resources are left untouched unless removeLegacy is set, a
+// legacy resource being only removed once all its PeerAuthentication
+// resources are applied. If del is set the PeerAuthentication resources
+// created by the migration are removed, found by their migratedPolicyLabel.
+func (istio *Istio) migrateLegacyAuthPolicies(del, removeLegacy bool, kubeconfigs []string) ([]PolicyMigration, error) {
+	var mx sync.Mutex
+	var migrations []PolicyMigration
+	err := forEachCluster(kubeconfigs, func(k8sconfig string) error {
+		mclient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return err
+		}
+		res, err := istio.migrateOnSingleCluster(mclient, clusterName(k8sconfig), del, removeLegacy)
+		mx.Lock()
+		migrations = append(migrations, res...)
+		mx.Unlock()
+		return err
+	}, nil)
+	if err != nil {
+		return migrations, ErrMigrateAuthPolicy(err)
+	}
+	return migrations, nil
+}
+
+func (istio *Istio) migrateOnSingleCluster(mclient *mesherykube.Client, cluster string, del, removeLegacy bool) ([]PolicyMigration, error) {
+	if del {
+		return removeMigratedPolicies(context.TODO(), mclient.DynamicKubeClient, cluster)
+	}
+
+	var legacy []unstructured.Unstructured
+	for _, gvr := range []schema.GroupVersionResource{legacyMeshPolicyGVR, legacyPolicyGVR} {
+		list, err := mclient.DynamicKubeClient.Resource(gvr).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			// The legacy APIs are not served anymore, hence nothing to migrate
+			if kerrors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		legacy = append(legacy, list.Items...)
+	}
+
+	lookup := func(namespace, name string) (map[string]string, map[string]int32, error) {
+		svc, err := mclient.KubeClient.CoreV1().Services(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, err
+		}
+		ports := make(map[string]int32)
+		for _, p := range svc.Spec.Ports {
+			target := p.Port
+			if p.TargetPort.Type == intstr.Int && p.TargetPort.IntVal != 0 {
+				target = p.TargetPort.IntVal
+			}
+			ports[p.Name] = target
+			ports[fmt.Sprint(p.Port)] = target
+		}
+		return svc.Spec.Selector, ports, nil
+	}
+	apply := func(manifest []byte) error {
+		return istio.applyManifestOnSingleCluster(manifest, false, "", mclient)
+	}
+	return migrateLegacyPolicies(context.TODO(), mclient.DynamicKubeClient, cluster, legacy, lookup, apply, removeLegacy)
+}
+
+// migrateLegacyPolicies applies the PeerAuthentication resources of the
+// legacy policies of a cluster with apply. A legacy policy is removed, when
+// removeLegacy is set, only if all its PeerAuthentication resources were
+// applied, for the policy never to be lost.
+func migrateLegacyPolicies(ctx context.Context, dyn dynamic.Interface, cluster string, legacy []unstructured.Unstructured, lookup serviceLookup, apply func(manifest []byte) error, removeLegacy bool) ([]PolicyMigration, error) {
+	var errs []error
+	var migrations []PolicyMigration
+	for _, obj := range legacy {
+		converted, err := convertLegacyPolicy(obj, lookup)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		applied := true
+		for _, m := range converted {
+			m.Cluster = cluster
+			if m.manifest != nil {
+				manifest, err := yaml.Marshal(m.manifest)
+				if err == nil {
+					err = apply(manifest)
+				}
+				if err != nil {
+					errs = append(errs, err)
+					applied = false
+					continue
+				}
+			}
+			migrations = append(migrations, m)
+		}
+
+		if removeLegacy && applied {
+			gvr := legacyPolicyGVR
+			if obj.GetKind() == "MeshPolicy" {
+				gvr = legacyMeshPolicyGVR
+			}
+			if err := dyn.Resource(gvr).Namespace(obj.GetNamespace()).Delete(ctx, obj.GetName(), metav1.DeleteOptions{}); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return migrations, mergeErrors(errs)
+}
+
+// removeMigratedPolicies removes the PeerAuthentication resources the
+// migration created on a cluster, whether their legacy policies remain or
+// not
+func removeMigratedPolicies(ctx context.Context, dyn dynamic.Interface, cluster string) ([]PolicyMigration, error) {
+	list, err := dyn.Resource(peerAuthenticationGVR).List(ctx, metav1.ListOptions{LabelSelector: migratedPolicyLabel + "=true"})
+	if err != nil {
+		return nil, err
+	}
+	var errs []error
+	var migrations []PolicyMigration
+	for _, pa := range list.Items {
+		err := dyn.Resource(peerAuthenticationGVR).Namespace(pa.GetNamespace()).Delete(ctx, pa.GetName(), metav1.DeleteOptions{})
+		if err != nil && !kerrors.IsNotFound(err) {
+			errs = append(errs, err)
+			continue
+		}
+		migrations = append(migrations, PolicyMigration{
+			Cluster: cluster,
+			Source:  pa.GetAnnotations()[migratedFromAnnotation],
+			Target:  fmt.Sprintf("PeerAuthentication %s/%s", pa.GetNamespace(), pa.GetName()),
+		})
+	}
+	return migrations, mergeErrors(errs)
+}
+
+// convertLegacyPolicy converts a v1alpha1 Policy or MeshPolicy into the
+// equivalent PeerAuthentication resources, one per policy target
+func convertLegacyPolicy(obj unstructured.Unstructured, lookup serviceLookup) ([]PolicyMigration, error) {
+	source := fmt.Sprintf("%s %s/%s", obj.GetKind(), obj.GetNamespace(), obj.GetName())
+	if obj.GetKind() == "MeshPolicy" {
+		source = fmt.Sprintf("%s %s", obj.GetKind(), obj.GetName())
+	}
+
+	mode := "DISABLE"
+	peers, _, _ := unstructured.NestedSlice(obj.Object, "spec", "peers")
+	for _, peer := range peers {
+		p, ok := peer.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if _, ok := p["mtls"]; !ok {
+			continue
+		}
+		mode = "STRICT"
+		if m, found, _ := unstructured.NestedString(p, "mtls", "mode"); found && m != "" {
+			mode = m
+		}
+	}
+
+	var notes []string
+	if origins, found, _ := unstructured.NestedSlice(obj.Object, "spec", "origins"); found && len(origins) > 0 {
+		notes = append(notes, "origin (JWT) authentication is not migrated, use a RequestAuthentication instead")
+	}
+
+	namespace := obj.GetNamespace()
+	if obj.GetKind() == "MeshPolicy" {
+		namespace = istioRootNamespace
+	}
+
+	targets, _, _ := unstructured.NestedSlice(obj.Object, "spec", "targets")
+	if len(targets) == 0 {
+		pa := peerAuthentication(source, obj.GetName(), namespace, mode, nil, nil)
+		if err := validatePeerAuthentication(pa); err != nil {
+			return nil, ErrInvalidPolicyConversion(source, err)
+		}
+		return []PolicyMigration{{
+			Source:   source,
+			Target:   fmt.Sprintf("PeerAuthentication %s/%s", namespace, obj.GetName()),
+			Notes:    notes,
+			manifest: pa,
+		}}, nil
+	}
+
+	var migrations []PolicyMigration
+	for _, target := range targets {
+		t, ok := target.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		svcName, _, _ := unstructured.NestedString(t, "name")
+		selector, svcPorts, err := lookup(namespace, svcName)
+		if err != nil {
+			return nil, ErrInvalidPolicyConversion(source, fmt.Errorf("unable to resolve target service %q: %w", svcName, err))
+		}
+
+		var portLevel map[int32]string
+		ports, _, _ := unstructured.NestedSlice(t, "ports")
+		for _, port := range ports {
+			p, ok := port.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			key := fmt.Sprint(p["number"])
+			if name, ok := p["name"].(string); ok && name != "" {
+				key = name
+			}
+			number, ok := svcPorts[key]
+			if !ok {
+				return nil, ErrInvalidPolicyConversion(source, fmt.Errorf("port %q not found on service %q", key, svcName))
+			}
+			if portLevel == nil {
+				portLevel = make(map[int32]string)
+			}
+			portLevel[number] = mode
+		}
+
+		name := fmt.Sprintf("%s-%s", obj.GetName(), svcName)
+		workloadMode := mode
+		if portLevel != nil {
+			// Only the listed ports were covered by the legacy policy
+			workloadMode = "UNSET"
+		}
+		pa := peerAuthentication(source, name, namespace, workloadMode, selector, portLevel)
+		if err := validatePeerAuthentication(pa); err != nil {
+			return nil, ErrInvalidPolicyConversion(source, err)
+		}
+		migrations = append(migrations, PolicyMigration{
+			Source:   source,
+			Target:   fmt.Sprintf("PeerAuthentication %s/%s", namespace, name),
+			Notes:    notes,
+			manifest: pa,
+		})
+	}
+	return migrations, nil
+}
+
+// peerAuthentication renders the PeerAuthentication migrated from the legacy
+// policy source, labeled with migratedPolicyLabel
+func peerAuthentication(source, name, namespace, mode string, selector map[string]string, portLevel map[int32]string) map[string]interface{} {
+	spec := map[string]interface{}{
+		"mtls": map[string]interface{}{
+			"mode": mode,
+		},
+	}
+	if len(selector) > 0 {
+		spec["selector"] = map[string]interface{}{
+			"matchLabels": selector,
+		}
+	}
+	if len(portLevel) > 0 {
+		levels := make(map[int32]interface{})
+		for port, m := range portLevel {
+			levels[port] = map[string]interface{}{
+				"mode": m,
+			}
+		}
+		spec["portLevelMtls"] = levels
+	}
+	return map[string]interface{}{
+		"apiVersion": "security.istio.io/v1beta1",
+		"kind":       "PeerAuthentication",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": namespace,
+			"labels": map[string]interface{}{
+				migratedPolicyLabel: "true",
+			},
+			"annotations": map[string]interface{}{
+				migratedFromAnnotation: source,
+			},
+		},
+		"spec": spec,
+	}
+}
+
+// validatePeerAuthentication validates the rendered PeerAuthentication
+func validatePeerAuthentication(pa map[string]interface{}) error {
+	spec, _ := pa["spec"].(map[string]interface{})
+	mtls, _ := spec["mtls"].(map[string]interface{})
+	mode, _ := mtls["mode"].(string)
+	if mode != "UNSET" && !validMTLSModes[mode] {
+		return fmt.Errorf("invalid mTLS mode %q", mode)
+	}
+	levels, ok := spec["portLevelMtls"].(map[int32]interface{})
+	if !ok {
+		return nil
+	}
+	if _, ok := spec["selector"]; !ok {
+		return fmt.Errorf("port level mTLS requires a workload selector")
+	}
+	for port, level := range levels {
+		if port <= 0 {
+			return fmt.Errorf("invalid port %d", port)
+		}
+		m, _ := level.(map[string]interface{})["mode"].(string)
+		if !validMTLSModes[m] {
+			return fmt.Errorf("invalid mTLS mode %q for port %d", m, port)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package istio
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestConvertLegacyPolicy(t *testing.T) {
+	lookup := func(namespace, name string) (map[string]string, map[string]int32, error) {
+		if name != "reviews" {
+			return nil, nil, fmt.Errorf("service %s not found", name)
+		}
+		return map[string]string{"app": "reviews"}, map[string]int32{"http": 9080, "80": 9080}, nil
+	}
+
+	tests := []struct {
+		name       string
+		obj        map[string]interface{}
+		wantTarget []string
+		wantMode   string
+		wantErr    bool
+	}{
+		{
+			name: "mesh policy",
+			obj: map[string]interface{}{
+				"kind":     "MeshPolicy",
+				"metadata": map[string]interface{}{"name": "default"},
+				"spec": map[string]interface{}{
+					"peers": []interface{}{map[string]interface{}{"mtls": map[string]interface{}{}}},
+				},
+			},
+			wantTarget: []string{"PeerAuthentication istio-system/default"},
+			wantMode:   "STRICT",
+		},
+		{
+			name: "namespace policy without peers",
+			obj: map[string]interface{}{
+				"kind":     "Policy",
+				"metadata": map[string]interface{}{"name": "default", "namespace": "bookinfo"},
+				"spec":     map[string]interface{}{},
+			},
+			wantTarget: []string{"PeerAuthentication bookinfo/default"},
+			wantMode:   "DISABLE",
+		},
+		{
+			name: "targeted policy with named port",
+			obj: map[string]interface{}{
+				"kind":     "Policy",
+				"metadata": map[string]interface{}{"name": "reviews-mtls", "namespace": "bookinfo"},
+				"spec": map[string]interface{}{
+					"targets": []interface{}{map[string]interface{}{
+						"name":  "reviews",
+						"ports": []interface{}{map[string]interface{}{"name": "http"}},
+					}},
+					"peers": []interface{}{map[string]interface{}{"mtls": map[string]interface{}{"mode": "PERMISSIVE"}}},
+				},
+			},
+			wantTarget: []string{"PeerAuthentication bookinfo/reviews-mtls-reviews"},
+			wantMode:   "UNSET",
+		},
+		{
+			name: "unknown target service",
+			obj: map[string]interface{}{
+				"kind":     "Policy",
+				"metadata": map[string]interface{}{"name": "ratings", "namespace": "bookinfo"},
+				"spec": map[string]interface{}{
+					"targets": []interface{}{map[string]interface{}{"name": "ratings"}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid mode",
+			obj: map[string]interface{}{
+				"kind":     "Policy",
+				"metadata": map[string]interface{}{"name": "default", "namespace": "bookinfo"},
+				"spec": map[string]interface{}{
+					"peers": []interface{}{map[string]interface{}{"mtls": map[string]interface{}{"mode": "OPTIONAL"}}},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := convertLegacyPolicy(unstructured.Unstructured{Object: tt.obj}, lookup)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("convertLegacyPolicy() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			var targets []string
+			for _, m := range got {
+				targets = append(targets, m.Target)
+				mode, _, _ := unstructured.NestedString(m.manifest, "spec", "mtls", "mode")
+				if mode != tt.wantMode {
+					t.Errorf("convertLegacyPolicy() mode = %v, want %v", mode, tt.wantMode)
+				}
+			}
+			if !reflect.DeepEqual(targets, tt.wantTarget) {
+				t.Errorf("convertLegacyPolicy() targets = %v, want %v", targets, tt.wantTarget)
+			}
+		})
+	}
+}
+
+func TestMigrateLegacyPolicies(t *testing.T) {
+	policy := func(name string, targets ...string) *unstructured.Unstructured {
+		var t []interface{}
+		for _, target := range targets {
+			t = append(t, map[string]interface{}{"name": target})
+		}
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "authentication.istio.io/v1alpha1",
+			"kind":       "Policy",
+			"metadata":   map[string]interface{}{"name": name, "namespace": "bookinfo"},
+			"spec": map[string]interface{}{
+				"targets": t,
+				"peers":   []interface{}{map[string]interface{}{"mtls": map[string]interface{}{}}},
+			},
+		}}
+	}
+	lookup := func(namespace, name string) (map[string]string, map[string]int32, error) {
+		return map[string]string{"app": name}, nil, nil
+	}
+	// The PeerAuthentication of ratings can't be applied, hence the policy
+	// of reviews and ratings is kept while the one of details is removed
+	apply := func(manifest []byte) error {
+		if strings.Contains(string(manifest), "name: both-ratings") {
+			return stderrors.New("admission webhook denied the request")
+		}
+		return nil
+	}
+	both, details := policy("both", "reviews", "ratings"), policy("details", "details")
+	dyn := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), both, details)
+
+	migrations, err := migrateLegacyPolicies(context.Background(), dyn, "east", []unstructured.Unstructured{*both, *details}, lookup, apply, true)
+	if err == nil {
+		t.Errorf("migrateLegacyPolicies() error = nil, want the failure of both-ratings")
+	}
+	var targets []string
+	for _, m := range migrations {
+		targets = append(targets, m.Target)
+	}
+	if want := []string{"PeerAuthentication bookinfo/both-reviews", "PeerAuthentication bookinfo/details-details"}; !reflect.DeepEqual(targets, want) {
+		t.Errorf("migrateLegacyPolicies() = %v, want %v", targets, want)
+	}
+	if _, err := dyn.Resource(legacyPolicyGVR).Namespace("bookinfo").Get(context.Background(), "both", metav1.GetOptions{}); err != nil {
+		t.Errorf("migrateLegacyPolicies() removed the policy of a failed PeerAuthentication: %v", err)
+	}
+	if _, err := dyn.Resource(legacyPolicyGVR).Namespace("bookinfo").Get(context.Background(), "details", metav1.GetOptions{}); err == nil {
+		t.Errorf("migrateLegacyPolicies() kept the migrated policy details")
+	}
+}
+
+func TestRemoveMigratedPolicies(t *testing.T) {
+	pa := func(name string, labels map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "security.istio.io/v1beta1",
+			"kind":       "PeerAuthentication",
+			"metadata": map[string]interface{}{
+				"name":        name,
+				"namespace":   "bookinfo",
+				"labels":      labels,
+				"annotations": map[string]interface{}{migratedFromAnnotation: "Policy bookinfo/" + name},
+			},
+		}}
+	}
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		peerAuthenticationGVR: "PeerAuthenticationList",
+	}, pa("default", map[string]interface{}{migratedPolicyLabel: "true"}), pa("strict", map[string]interface{}{"app": "reviews"}))
+
+	got, err := removeMigratedPolicies(context.Background(), dyn, "east")
+	if err != nil {
+		t.Fatalf("removeMigratedPolicies() error = %v", err)
+	}
+	want := []PolicyMigration{{Cluster: "east", Source: "Policy bookinfo/default", Target: "PeerAuthentication bookinfo/default"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("removeMigratedPolicies() = %+v, want %+v", got, want)
+	}
+	left, err := dyn.Resource(peerAuthenticationGVR).List(context.Background(), metav1.ListOptions{})
+	if err != nil || len(left.Items) != 1 || left.Items[0].GetName() != "strict" {
+		t.Errorf("removeMigratedPolicies() left %v, want the PeerAuthentication which wasn't migrated", left)
+	}
+}
@@ -0,0 +1,150 @@
+package istio
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// kubernetesRange is the range of the Kubernetes minor versions an Istio
+// minor version supports
+type kubernetesRange struct {
+	min, max int
+}
+
+// supportedKubernetesVersions are the Kubernetes 1.x minor versions supported
+// by the Istio 1.x minor versions, see
+// https://istio.io/latest/docs/releases/supported-releases/. The Istio
+// versions missing here are not checked.
+var supportedKubernetesVersions = map[int]kubernetesRange{
+	18: {24, 27},
+	19: {25, 28},
+	20: {25, 29},
+	21: {26, 29},
+	22: {27, 30},
+	23: {27, 30},
+	24: {28, 31},
+	25: {29, 32},
+	26: {29, 32},
+	27: {29, 33},
+}
+
+// clusterScopedResources are the cluster scoped resources the install
+// creates, which the user must be allowed to create
+var clusterScopedResources = []authorizationv1.ResourceAttributes{
+	{Verb: "create", Group: "apiextensions.k8s.io", Resource: "customresourcedefinitions"},
+	{Verb: "create", Group: "rbac.authorization.k8s.io", Resource: "clusterroles"},
+	{Verb: "create", Group: "rbac.authorization.k8s.io", Resource: "clusterrolebindings"},
+	{Verb: "create", Group: "admissionregistration.k8s.io", Resource: "mutatingwebhookconfigurations"},
+	{Verb: "create", Group: "admissionregistration.k8s.io", Resource: "validatingwebhookconfigurations"},
+}
+
+// preflightCheck checks that every cluster meets the requirements of the
+// install of the version before anything is applied. ErrPreflightFailed is
+// returned with the unmet requirements, prefixed with their cluster.
+func (istio *Istio) preflightCheck(ctx context.Context, version string, kubeConfigs []string) error {
+	var mx sync.Mutex
+	var problems []string
+	err := forEachCluster(kubeConfigs, func(k8sconfig string) error {
+		mclient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return err
+		}
+		unmet, err := preflightProblems(ctx, mclient.KubeClient, version)
+		if err != nil {
+			return err
+		}
+		cluster := clusterName(k8sconfig)
+		mx.Lock()
+		for _, problem := range unmet {
+			problems = append(problems, fmt.Sprintf("%s: %s", cluster, problem))
+		}
+		mx.Unlock()
+		return nil
+	}, nil)
+	if err != nil {
+		return ErrPreflightFailed([]string{err.Error()})
+	}
+	if len(problems) != 0 {
+		sort.Strings(problems)
+		return ErrPreflightFailed(problems)
+	}
+	return nil
+}
+
+// preflightProblems returns the requirements of the install of the version
+// the cluster doesn't meet
+func preflightProblems(ctx context.Context, client kubernetes.Interface, version string) ([]string, error) {
+	var problems []string
+
+	serverVersion, err := client.Discovery().ServerVersion()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the Kubernetes version: %w", err)
+	}
+	if problem := kubernetesVersionProblem(version, serverVersion.Major, serverVersion.Minor); problem != "" {
+		problems = append(problems, problem)
+	}
+
+	// Another control plane outside of istio-system, or the deprecated
+	// in-cluster operator, would fight over the webhooks and the CRDs
+	istiods, err := client.AppsV1().Deployments(metav1.NamespaceAll).List(ctx, metav1.ListOptions{LabelSelector: "app=istiod"})
+	if err != nil {
+		return nil, err
+	}
+	for _, deployment := range istiods.Items {
+		if deployment.Namespace != istioRootNamespace {
+			problems = append(problems, fmt.Sprintf("an Istio control plane is already installed in the %s namespace", deployment.Namespace))
+		}
+	}
+	operators, err := client.AppsV1().Deployments(metav1.NamespaceAll).List(ctx, metav1.ListOptions{LabelSelector: "name=istio-operator"})
+	if err != nil {
+		return nil, err
+	}
+	for _, deployment := range operators.Items {
+		problems = append(problems, fmt.Sprintf("the Istio operator is running in the %s namespace", deployment.Namespace))
+	}
+
+	for _, resource := range clusterScopedResources {
+		review, err := client.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: &resource},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return nil, err
+		}
+		if !review.Status.Allowed {
+			problems = append(problems, fmt.Sprintf("not allowed to %s %s.%s", resource.Verb, resource.Resource, resource.Group))
+		}
+	}
+	return problems, nil
+}
+
+// kubernetesVersionProblem returns why the Kubernetes version isn't supported
+// by the Istio version, or "" if it is or the support is unknown
+func kubernetesVersionProblem(version, major, minor string) string {
+	m := minorVersionRegex.FindStringSubmatch(version)
+	if m == nil || m[1] != "1" {
+		return ""
+	}
+	istioMinor, _ := strconv.Atoi(m[2])
+	supported, ok := supportedKubernetesVersions[istioMinor]
+	if !ok {
+		return ""
+	}
+	// The minor versions of some providers have a suffix, such as 29+
+	k8sMinor := minorVersionRegex.FindStringSubmatch(fmt.Sprintf("%s.%s", major, minor))
+	if k8sMinor == nil {
+		return ""
+	}
+	kubeMinor, _ := strconv.Atoi(k8sMinor[2])
+	if k8sMinor[1] != "1" || kubeMinor < supported.min || kubeMinor > supported.max {
+		return fmt.Sprintf("Kubernetes %s.%s is not supported by Istio %s, which supports Kubernetes 1.%d to 1.%d", major, minor, version, supported.min, supported.max)
+	}
+	return ""
+}
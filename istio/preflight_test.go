@@ -0,0 +1,87 @@
+package istio
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestKubernetesVersionProblem(t *testing.T) {
+	tests := []struct {
+		version, major, minor string
+		wantProblem           bool
+	}{
+		{version: "1.22.1", major: "1", minor: "28"},
+		{version: "v1.24.0", major: "1", minor: "29+"},
+		{version: "1.22.1", major: "1", minor: "26", wantProblem: true},
+		{version: "1.20.0", major: "1", minor: "30", wantProblem: true},
+		{version: "1.9.0", major: "1", minor: "30"},
+		{version: "latest", major: "1", minor: "20"},
+	}
+	for _, tt := range tests {
+		if got := kubernetesVersionProblem(tt.version, tt.major, tt.minor); (got != "") != tt.wantProblem {
+			t.Errorf("kubernetesVersionProblem(%s, %s.%s) = %q, want a problem %v", tt.version, tt.major, tt.minor, got, tt.wantProblem)
+		}
+	}
+}
+
+func TestPreflightProblems(t *testing.T) {
+	istiod := func(namespace string) *appsv1.Deployment {
+		return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "istiod", Namespace: namespace, Labels: map[string]string{"app": "istiod"}}}
+	}
+	tests := []struct {
+		name    string
+		objects []runtime.Object
+		minor   string
+		denied  string
+		want    []string
+	}{
+		{
+			name:    "upgrade of the control plane in istio-system",
+			objects: []runtime.Object{istiod(istioRootNamespace)},
+			minor:   "28",
+		},
+		{
+			name: "unmet requirements",
+			objects: []runtime.Object{
+				istiod("openshift-istio"),
+				&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "istio-operator", Namespace: "istio-operator", Labels: map[string]string{"name": "istio-operator"}}},
+			},
+			minor:  "25",
+			denied: "customresourcedefinitions",
+			want: []string{
+				"Kubernetes 1.25 is not supported by Istio 1.22.1, which supports Kubernetes 1.27 to 1.30",
+				"an Istio control plane is already installed in the openshift-istio namespace",
+				"the Istio operator is running in the istio-operator namespace",
+				"not allowed to create customresourcedefinitions.apiextensions.k8s.io",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(tt.objects...)
+			client.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{Major: "1", Minor: tt.minor}
+			client.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+				review.Status.Allowed = review.Spec.ResourceAttributes.Resource != tt.denied
+				return true, review, nil
+			})
+			got, err := preflightProblems(context.Background(), client, "1.22.1")
+			if err != nil {
+				t.Fatalf("preflightProblems() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("preflightProblems() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
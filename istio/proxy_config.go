@@ -0,0 +1,121 @@
+package istio
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// proxyConfigTypes are the kinds of the Envoy configuration istioctl
+// proxy-config can dump
+var proxyConfigTypes = []string{"clusters", "listeners", "routes", "endpoints"}
+
+// ProxyConfigDump is the Envoy configuration of a kind dumped from the
+// sidecar of a pod on a cluster
+type ProxyConfigDump struct {
+	Cluster string
+	Pod     string
+	Type    string
+
+	// Config is the JSON printed by istioctl proxy-config
+	Config json.RawMessage
+}
+
+// getProxyConfig runs istioctl proxy-config of the given version to dump the
+// configuration of the type, such as clusters, from the sidecar of the pod on
+// every cluster the pod runs on, the clusters without the pod being skipped.
+// ErrProxyConfigFailed is returned if the type is unknown, if the pod runs on
+// no cluster or if istioctl fails.
+func (istio *Istio) getProxyConfig(version, pod, namespace, configType string, kubeConfigs []string) ([]ProxyConfigDump, error) {
+	configType, err := parseProxyConfigType(configType)
+	if err != nil {
+		return nil, ErrProxyConfigFailed(err)
+	}
+	if pod == "" {
+		return nil, ErrProxyConfigFailed(fmt.Errorf("no pod set"))
+	}
+	executable, err := istio.getExecutable(version)
+	if err != nil {
+		return nil, ErrProxyConfigFailed(err)
+	}
+
+	var mx sync.Mutex
+	var dumps []ProxyConfigDump
+	err = forEachCluster(kubeConfigs, func(k8sconfig string) error {
+		kClient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return err
+		}
+		_, err = kClient.KubeClient.CoreV1().Pods(namespace).Get(context.TODO(), pod, metav1.GetOptions{})
+		if kerrors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		kContext, err := kClient.GetCurrentContext()
+		if err != nil {
+			return err
+		}
+		out, err := runIstioctlOnCluster(executable, k8sconfig, "proxy-config", configType, fmt.Sprintf("%s.%s", pod, namespace), "--context", kContext, "-o", "json")
+		if err != nil {
+			return err
+		}
+		if !json.Valid([]byte(out)) {
+			return fmt.Errorf("istioctl proxy-config %s generated invalid JSON", configType)
+		}
+		mx.Lock()
+		dumps = append(dumps, ProxyConfigDump{
+			Cluster: clusterName(k8sconfig),
+			Pod:     pod,
+			Type:    configType,
+			Config:  json.RawMessage(strings.TrimSpace(out)),
+		})
+		mx.Unlock()
+		return nil
+	}, nil)
+	sort.Slice(dumps, func(i, j int) bool { return dumps[i].Cluster < dumps[j].Cluster })
+	if err != nil {
+		return dumps, ErrProxyConfigFailed(err)
+	}
+	if len(dumps) == 0 {
+		return nil, ErrProxyConfigFailed(fmt.Errorf("pod %s not found in %s", pod, namespace))
+	}
+	return dumps, nil
+}
+
+// parseProxyConfigType validates the type of the configuration to dump,
+// clusters being dumped when empty
+func parseProxyConfigType(configType string) (string, error) {
+	configType = strings.ToLower(strings.TrimSpace(configType))
+	if configType == "" {
+		return proxyConfigTypes[0], nil
+	}
+	for _, t := range proxyConfigTypes {
+		if t == configType {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("unknown config type %q, want one of %s", configType, strings.Join(proxyConfigTypes, ", "))
+}
+
+// proxyConfigDetails returns the dumps as a JSON object for the event
+// details, keyed by cluster
+func proxyConfigDetails(dumps []ProxyConfigDump) (string, error) {
+	configs := make(map[string]json.RawMessage, len(dumps))
+	for _, d := range dumps {
+		configs[d.Cluster] = d.Config
+	}
+	out, err := json.MarshalIndent(configs, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
@@ -0,0 +1,52 @@
+package istio
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseProxyConfigType(t *testing.T) {
+	tests := []struct {
+		configType string
+		want       string
+		wantErr    bool
+	}{
+		{configType: "", want: "clusters"},
+		{configType: " Listeners ", want: "listeners"},
+		{configType: "routes", want: "routes"},
+		{configType: "endpoints", want: "endpoints"},
+		{configType: "bootstrap", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseProxyConfigType(tt.configType)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("parseProxyConfigType(%q) error = %v, wantErr %v", tt.configType, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("parseProxyConfigType(%q) = %s, want %s", tt.configType, got, tt.want)
+		}
+	}
+}
+
+func TestProxyConfigDetails(t *testing.T) {
+	dumps := []ProxyConfigDump{
+		{Cluster: "east", Pod: "productpage", Type: "routes", Config: json.RawMessage(`[{"name":"9080"}]`)},
+		{Cluster: "west", Pod: "productpage", Type: "routes", Config: json.RawMessage(`[]`)},
+	}
+	details, err := proxyConfigDetails(dumps)
+	if err != nil {
+		t.Fatalf("proxyConfigDetails() error = %v", err)
+	}
+	var got map[string][]map[string]interface{}
+	if err := json.Unmarshal([]byte(details), &got); err != nil {
+		t.Fatalf("proxyConfigDetails() generated invalid JSON: %v\n%s", err, details)
+	}
+	want := map[string][]map[string]interface{}{
+		"east": {{"name": "9080"}},
+		"west": {},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("proxyConfigDetails() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,109 @@
+package istio
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// proxyLogLevels are the Envoy log levels istioctl proxy-config log sets
+var proxyLogLevels = map[string]bool{
+	"trace": true, "debug": true, "info": true, "warning": true,
+	"error": true, "critical": true, "off": true,
+}
+
+// parseProxyLogLevel validates the Envoy log level, the upper case levels
+// being accepted as well
+func parseProxyLogLevel(level string) (string, error) {
+	level = strings.ToLower(strings.TrimSpace(level))
+	if !proxyLogLevels[level] {
+		return "", ErrInvalidLogLevel(level)
+	}
+	return level, nil
+}
+
+// setProxyLogLevel sets the log level of the Envoy proxies of the namespace,
+// of the pod of the namespace only if pod is set, with istioctl
+// proxy-config log of the given version on every cluster. The level applies
+// until the proxy restarts, the delete operation resets the proxies to the
+// level they started with. The proxies set are returned as
+// cluster/namespace/pod, along with the merged errors of the proxies
+// istioctl failed on.
+func (istio *Istio) setProxyLogLevel(ctx context.Context, namespace, pod, level string, del bool, version string, kubeConfigs []string) ([]string, error) {
+	levelArgs := []string{"--reset"}
+	if !del {
+		parsed, err := parseProxyLogLevel(level)
+		if err != nil {
+			return nil, err
+		}
+		levelArgs = []string{"--level", parsed}
+	}
+	executable, err := istio.getExecutable(version)
+	if err != nil {
+		return nil, ErrProxyLogLevelFailed(err)
+	}
+
+	var mx sync.Mutex
+	var set []string
+	err = forEachCluster(kubeConfigs, func(k8sconfig string) error {
+		kClient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return err
+		}
+		kContext, err := kClient.GetCurrentContext()
+		if err != nil {
+			return err
+		}
+		pods := []string{pod}
+		if pod == "" {
+			list, err := kClient.KubeClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return err
+			}
+			pods = proxyPods(list.Items)
+		}
+		var errs []error
+		for _, name := range pods {
+			args := append([]string{"proxy-config", "log", fmt.Sprintf("%s.%s", name, namespace), "--context", kContext}, levelArgs...)
+			if _, err := runIstioctlOnCluster(executable, k8sconfig, args...); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+				continue
+			}
+			mx.Lock()
+			set = append(set, fmt.Sprintf("%s/%s/%s", clusterName(k8sconfig), namespace, name))
+			mx.Unlock()
+		}
+		if len(errs) > 0 {
+			return mergeErrors(errs)
+		}
+		return nil
+	}, nil)
+	sort.Strings(set)
+	if err != nil {
+		return set, ErrProxyLogLevelFailed(err)
+	}
+	return set, nil
+}
+
+// proxyPods returns the names of the running pods the sidecar was injected
+// into, the gateways included
+func proxyPods(pods []corev1.Pod) []string {
+	var names []string
+	for _, pod := range pods {
+		if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil {
+			continue
+		}
+		if _, ok := pod.Annotations["sidecar.istio.io/status"]; !ok {
+			continue
+		}
+		names = append(names, pod.Name)
+	}
+	sort.Strings(names)
+	return names
+}
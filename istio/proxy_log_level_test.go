@@ -0,0 +1,61 @@
+package istio
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/layer5io/meshkit/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseProxyLogLevel(t *testing.T) {
+	tests := []struct {
+		level   string
+		want    string
+		wantErr bool
+	}{
+		{level: "debug", want: "debug"},
+		{level: " WARNING ", want: "warning"},
+		{level: "off", want: "off"},
+		{level: "warn", wantErr: true},
+		{level: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			got, err := parseProxyLogLevel(tt.level)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseProxyLogLevel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && errors.GetCode(err) != ErrInvalidLogLevelCode {
+				t.Errorf("parseProxyLogLevel() error code = %s, want %s", errors.GetCode(err), ErrInvalidLogLevelCode)
+			}
+			if got != tt.want {
+				t.Errorf("parseProxyLogLevel() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProxyPods(t *testing.T) {
+	pod := func(name string, phase corev1.PodPhase, injected, terminating bool) corev1.Pod {
+		p := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}, Status: corev1.PodStatus{Phase: phase}}
+		if injected {
+			p.Annotations = map[string]string{"sidecar.istio.io/status": `{"containers":["istio-proxy"]}`}
+		}
+		if terminating {
+			p.DeletionTimestamp = &metav1.Time{}
+		}
+		return p
+	}
+	pods := []corev1.Pod{
+		pod("reviews", corev1.PodRunning, true, false),
+		pod("details", corev1.PodRunning, true, false),
+		pod("ratings", corev1.PodRunning, false, false),
+		pod("productpage", corev1.PodPending, true, false),
+		pod("reviews-old", corev1.PodRunning, true, true),
+	}
+	if got, want := proxyPods(pods), []string{"details", "reviews"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("proxyPods() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,163 @@
+package istio
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+)
+
+// Sync states of the xDS configuration of a proxy reported by istioctl
+// proxy-status
+const (
+	ProxySynced  = "SYNCED"
+	ProxyStale   = "STALE"
+	ProxyNotSent = "NOT SENT"
+)
+
+// proxyXDSTypes are the xDS types the sync status is reported for
+var proxyXDSTypes = []string{"CDS", "LDS", "EDS", "RDS"}
+
+// proxyStatusColumns matches the column names of the istioctl proxy-status
+// header, none of which has a space
+var proxyStatusColumns = regexp.MustCompile(`\S+`)
+
+// ProxySyncStatus is the sync status of the xDS configuration istiod pushed
+// to a proxy
+type ProxySyncStatus struct {
+	Cluster string
+	Proxy   string
+	Istiod  string
+	Version string
+
+	// XDS is the sync state of every xDS type, keyed by CDS, LDS, EDS and RDS
+	XDS map[string]string
+}
+
+// Synced reports whether the proxy is fully synced: no configuration is
+// STALE and the clusters and listeners were sent. The proxies without
+// endpoints or routes to watch are never sent any, hence their EDS and RDS
+// may be NOT SENT.
+func (s ProxySyncStatus) Synced() bool {
+	for _, xds := range proxyXDSTypes {
+		if s.XDS[xds] == ProxyStale {
+			return false
+		}
+	}
+	return s.XDS["CDS"] == ProxySynced && s.XDS["LDS"] == ProxySynced
+}
+
+func (s ProxySyncStatus) String() string {
+	states := make([]string, 0, len(proxyXDSTypes))
+	for _, xds := range proxyXDSTypes {
+		states = append(states, fmt.Sprintf("%s %s", xds, s.XDS[xds]))
+	}
+	return fmt.Sprintf("%s: %s (istiod %s, version %s)", s.Proxy, strings.Join(states, ", "), s.Istiod, s.Version)
+}
+
+// getProxySyncStatus runs istioctl proxy-status of the given version on
+// every cluster and returns the sync status of all the proxies, sorted by
+// cluster and proxy. ErrProxyStatusFailed is returned along with the status
+// of the clusters istiod could be reached on if it couldn't on any cluster.
+func (istio *Istio) getProxySyncStatus(version string, kubeConfigs []string) ([]ProxySyncStatus, error) {
+	executable, err := istio.getExecutable(version)
+	if err != nil {
+		return nil, ErrProxyStatusFailed(err)
+	}
+
+	var mx sync.Mutex
+	var statuses []ProxySyncStatus
+	err = forEachCluster(kubeConfigs, func(k8sconfig string) error {
+		kClient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return err
+		}
+		kContext, err := kClient.GetCurrentContext()
+		if err != nil {
+			return err
+		}
+		out, err := runIstioctlOnCluster(executable, k8sconfig, "proxy-status", "--context", kContext)
+		if err != nil {
+			return err
+		}
+		proxies, err := parseProxyStatus(out)
+		if err != nil {
+			return err
+		}
+		cluster := clusterName(k8sconfig)
+		for i := range proxies {
+			proxies[i].Cluster = cluster
+		}
+		mx.Lock()
+		statuses = append(statuses, proxies...)
+		mx.Unlock()
+		return nil
+	}, nil)
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Cluster != statuses[j].Cluster {
+			return statuses[i].Cluster < statuses[j].Cluster
+		}
+		return statuses[i].Proxy < statuses[j].Proxy
+	})
+	if err != nil {
+		return statuses, ErrProxyStatusFailed(err)
+	}
+	return statuses, nil
+}
+
+// parseProxyStatus parses the table printed by istioctl proxy-status. The
+// columns are found by their offset in the header, as the releases add and
+// remove some and leave the cells without a value blank. The age of the
+// configuration the recent releases print along with its state, such as
+// SYNCED (2m1s), is left out.
+func parseProxyStatus(out string) ([]ProxySyncStatus, error) {
+	var header []string
+	var offsets [][]int
+	var statuses []ProxySyncStatus
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			continue
+		}
+		if header == nil {
+			if !strings.HasPrefix(line, "NAME") {
+				return nil, fmt.Errorf("invalid istioctl proxy-status output: %q", line)
+			}
+			offsets = proxyStatusColumns.FindAllStringIndex(line, -1)
+			for _, offset := range offsets {
+				header = append(header, line[offset[0]:offset[1]])
+			}
+			continue
+		}
+		status := ProxySyncStatus{XDS: map[string]string{}}
+		for i, name := range header {
+			start, end := offsets[i][0], len(line)
+			if i+1 < len(offsets) && offsets[i+1][0] < end {
+				end = offsets[i+1][0]
+			}
+			if start >= end {
+				continue
+			}
+			value := strings.TrimSpace(line[start:end])
+			switch name {
+			case "NAME":
+				status.Proxy = value
+			case "ISTIOD":
+				status.Istiod = value
+			case "VERSION":
+				status.Version = value
+			case "CDS", "LDS", "EDS", "RDS":
+				state, _, _ := strings.Cut(value, " (")
+				status.XDS[name] = state
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	if header == nil {
+		return nil, fmt.Errorf("istioctl proxy-status generated no output")
+	}
+	return statuses, nil
+}
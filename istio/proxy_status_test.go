@@ -0,0 +1,66 @@
+package istio
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseProxyStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		out        string
+		want       []ProxySyncStatus
+		wantSynced []bool
+		wantErr    bool
+	}{
+		{
+			name: "with ECDS",
+			out: `NAME                                                  CLUSTER        CDS        LDS        EDS        RDS          ECDS         ISTIOD                      VERSION
+details-v1-698b5d8c98-qglgn.default                   Kubernetes     SYNCED     SYNCED     SYNCED     SYNCED       NOT SENT     istiod-6cf8d4f9cb-wm7x6     1.20.0
+istio-ingressgateway-6785fcd48-n7zlg.istio-system     Kubernetes     SYNCED     SYNCED     SYNCED     NOT SENT     NOT SENT     istiod-6cf8d4f9cb-wm7x6     1.20.0
+reviews-v1-5b5d6494f4-4b4mv.default                   Kubernetes     SYNCED     STALE      SYNCED     SYNCED       NOT SENT     istiod-6cf8d4f9cb-wm7x6     1.20.0
+`,
+			want: []ProxySyncStatus{
+				{Proxy: "details-v1-698b5d8c98-qglgn.default", Istiod: "istiod-6cf8d4f9cb-wm7x6", Version: "1.20.0", XDS: map[string]string{"CDS": ProxySynced, "LDS": ProxySynced, "EDS": ProxySynced, "RDS": ProxySynced}},
+				{Proxy: "istio-ingressgateway-6785fcd48-n7zlg.istio-system", Istiod: "istiod-6cf8d4f9cb-wm7x6", Version: "1.20.0", XDS: map[string]string{"CDS": ProxySynced, "LDS": ProxySynced, "EDS": ProxySynced, "RDS": ProxyNotSent}},
+				{Proxy: "reviews-v1-5b5d6494f4-4b4mv.default", Istiod: "istiod-6cf8d4f9cb-wm7x6", Version: "1.20.0", XDS: map[string]string{"CDS": ProxySynced, "LDS": ProxyStale, "EDS": ProxySynced, "RDS": ProxySynced}},
+			},
+			wantSynced: []bool{true, true, false},
+		},
+		{
+			name: "with the age of the configuration",
+			out: `NAME                                 CLUSTER        CDS                LDS                EDS                RDS                ECDS        ISTIOD                     VERSION
+ratings-v1-7c9bd4b87f-mkz9x.default  Kubernetes     SYNCED (2m1s)      NOT SENT           SYNCED (2m1s)      SYNCED (2m1s)                  istiod-5c4f6dc7c-hdxfj     1.23.0
+`,
+			want: []ProxySyncStatus{
+				{Proxy: "ratings-v1-7c9bd4b87f-mkz9x.default", Istiod: "istiod-5c4f6dc7c-hdxfj", Version: "1.23.0", XDS: map[string]string{"CDS": ProxySynced, "LDS": ProxyNotSent, "EDS": ProxySynced, "RDS": ProxySynced}},
+			},
+			wantSynced: []bool{false},
+		},
+		{
+			name: "no proxy",
+			out:  "NAME     CLUSTER     CDS     LDS     EDS     RDS     ECDS     ISTIOD     VERSION\n",
+		},
+		{
+			name:    "no output",
+			out:     "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseProxyStatus(tt.out)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseProxyStatus() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseProxyStatus() = %+v, want %+v", got, tt.want)
+			}
+			for i, synced := range tt.wantSynced {
+				if got[i].Synced() != synced {
+					t.Errorf("%s Synced() = %v, want %v", got[i].Proxy, got[i].Synced(), synced)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package istio
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var crdResource = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+
+// purgeIstio removes what the uninstall of Istio leaves behind in every
+// cluster: the Istio CRDs, along with all the resources of their kinds, the
+// webhook configurations of istiod and the istio-system namespace if nothing
+// runs in it anymore. The removed objects are returned prefixed with their
+// cluster.
+func (istio *Istio) purgeIstio(kubeconfigs []string) ([]string, error) {
+	var mx sync.Mutex
+	var removed []string
+	err := forEachCluster(kubeconfigs, func(k8sconfig string) error {
+		mclient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return err
+		}
+		cluster := clusterName(k8sconfig)
+		objects, err := purgeCluster(mclient)
+		mx.Lock()
+		for _, object := range objects {
+			removed = append(removed, fmt.Sprintf("%s: %s", cluster, object))
+		}
+		mx.Unlock()
+		return err
+	}, nil)
+	sort.Strings(removed)
+	if err != nil {
+		return removed, ErrPurgeIstio(err)
+	}
+	return removed, nil
+}
+
+func purgeCluster(mclient *mesherykube.Client) ([]string, error) {
+	var removed []string
+	var errs []error
+
+	crds, err := mclient.DynamicKubeClient.Resource(crdResource).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, crd := range crds.Items {
+		if !isIstioGroup(crd.GetName()) {
+			continue
+		}
+		if err := mclient.DynamicKubeClient.Resource(crdResource).Delete(context.TODO(), crd.GetName(), metav1.DeleteOptions{}); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		removed = append(removed, "CustomResourceDefinition "+crd.GetName())
+	}
+
+	admission := mclient.KubeClient.AdmissionregistrationV1()
+	mutating, err := admission.MutatingWebhookConfigurations().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		errs = append(errs, err)
+	} else {
+		for _, webhook := range mutating.Items {
+			if !strings.Contains(webhook.Name, "istio") {
+				continue
+			}
+			if err := admission.MutatingWebhookConfigurations().Delete(context.TODO(), webhook.Name, metav1.DeleteOptions{}); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			removed = append(removed, "MutatingWebhookConfiguration "+webhook.Name)
+		}
+	}
+	validating, err := admission.ValidatingWebhookConfigurations().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		errs = append(errs, err)
+	} else {
+		for _, webhook := range validating.Items {
+			if !strings.Contains(webhook.Name, "istio") {
+				continue
+			}
+			if err := admission.ValidatingWebhookConfigurations().Delete(context.TODO(), webhook.Name, metav1.DeleteOptions{}); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			removed = append(removed, "ValidatingWebhookConfiguration "+webhook.Name)
+		}
+	}
+
+	// The namespace is kept if anything still runs in it, e.g. the addons
+	pods, err := mclient.KubeClient.CoreV1().Pods(istioRootNamespace).List(context.TODO(), metav1.ListOptions{Limit: 1})
+	if err != nil {
+		errs = append(errs, err)
+	} else if len(pods.Items) == 0 {
+		if err := mclient.KubeClient.CoreV1().Namespaces().Delete(context.TODO(), istioRootNamespace, metav1.DeleteOptions{}); err != nil {
+			errs = append(errs, err)
+		} else {
+			removed = append(removed, "Namespace "+istioRootNamespace)
+		}
+	}
+	return removed, mergeErrors(errs)
+}
+
+// isIstioGroup reports whether the CRD belongs to one of the istio.io API
+// groups
+func isIstioGroup(crd string) bool {
+	_, group, found := strings.Cut(crd, ".")
+	return found && (group == "istio.io" || strings.HasSuffix(group, ".istio.io"))
+}
@@ -0,0 +1,24 @@
+package istio
+
+import "testing"
+
+func TestIsIstioGroup(t *testing.T) {
+	tests := []struct {
+		crd  string
+		want bool
+	}{
+		{crd: "virtualservices.networking.istio.io", want: true},
+		{crd: "istiooperators.install.istio.io", want: true},
+		{crd: "wasmplugins.extensions.istio.io", want: true},
+		{crd: "gateways.gateway.networking.k8s.io", want: false},
+		{crd: "widgets.notistio.io", want: false},
+		{crd: "istio.io", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.crd, func(t *testing.T) {
+			if got := isIstioGroup(tt.crd); got != tt.want {
+				t.Errorf("isIstioGroup() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package istio
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/layer5io/meshery-adapter-library/status"
+	"github.com/layer5io/meshkit/utils"
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// remoteSecretName is the name istioctl create-remote-secret gives to the
+// secret of the remote cluster
+func remoteSecretName(cluster string) string {
+	return fmt.Sprintf("istio-remote-secret-%s", cluster)
+}
+
+// readRemoteKubeconfig returns the kubeconfig of the remote cluster, given
+// either inline or as a file:// or http(s):// location
+func readRemoteKubeconfig(kubeconfig string) (string, error) {
+	kubeconfig = strings.TrimSpace(kubeconfig)
+	if kubeconfig == "" {
+		return "", fmt.Errorf("the kubeconfig of the remote cluster is required")
+	}
+	if strings.HasPrefix(kubeconfig, "file://") || strings.HasPrefix(kubeconfig, "http://") || strings.HasPrefix(kubeconfig, "https://") {
+		return utils.ReadFileSource(kubeconfig)
+	}
+	return kubeconfig, nil
+}
+
+// resolveRemoteCluster returns the name of the remote cluster, the current
+// context of its kubeconfig unless the name is set. The name is the one of
+// the topology.istio.io/cluster label of its workloads, hence it must be a
+// label value.
+func resolveRemoteCluster(remoteKubeconfig, name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = clusterName(remoteKubeconfig)
+		if name == "unknown" {
+			return "", fmt.Errorf("the kubeconfig of the remote cluster has no current context, set the name of the remote cluster")
+		}
+	}
+	if errs := validation.IsValidLabelValue(name); len(errs) > 0 {
+		return "", fmt.Errorf("invalid remote cluster name %q: %s", name, strings.Join(errs, ", "))
+	}
+	return name, nil
+}
+
+// createRemoteSecret creates the secret granting the istiod of the clusters
+// access to the API server of the remote cluster with istioctl
+// create-remote-secret, the remote cluster being named after the current
+// context of its kubeconfig unless remoteCluster is set. The name of the
+// remote cluster is returned, the remote cluster itself is skipped when it is
+// among the clusters. The deletion removes the
+// secret of the remote cluster from the clusters, the remote cluster not
+// being reached.
+func (istio *Istio) createRemoteSecret(ctx context.Context, remoteKubeconfig, remoteCluster string, del bool, version string, kubeConfigs []string) (string, string, error) {
+	st := status.Installing
+	if del {
+		st = status.Removing
+	}
+
+	remoteKubeconfig, err := readRemoteKubeconfig(remoteKubeconfig)
+	if err != nil {
+		return st, remoteCluster, ErrRemoteSecretFailed(err)
+	}
+	remoteCluster, err = resolveRemoteCluster(remoteKubeconfig, remoteCluster)
+	if err != nil {
+		return st, remoteCluster, ErrRemoteSecretFailed(err)
+	}
+	local := make([]string, 0, len(kubeConfigs))
+	for _, k8sconfig := range kubeConfigs {
+		if clusterName(k8sconfig) != remoteCluster {
+			local = append(local, k8sconfig)
+		}
+	}
+
+	if del {
+		err := forEachCluster(local, func(k8sconfig string) error {
+			mclient, err := mesherykube.New([]byte(k8sconfig))
+			if err != nil {
+				return err
+			}
+			err = mclient.KubeClient.CoreV1().Secrets(istioRootNamespace).Delete(ctx, remoteSecretName(remoteCluster), metav1.DeleteOptions{})
+			if err != nil && !kerrors.IsNotFound(err) {
+				return err
+			}
+			return nil
+		}, nil)
+		if err != nil {
+			return st, remoteCluster, ErrRemoteSecretFailed(err)
+		}
+		return status.Removed, remoteCluster, nil
+	}
+
+	remote, err := mesherykube.New([]byte(remoteKubeconfig))
+	if err != nil {
+		return st, remoteCluster, ErrRemoteSecretFailed(fmt.Errorf("invalid kubeconfig of the remote cluster %s: %w", remoteCluster, err))
+	}
+	if _, err := remote.KubeClient.Discovery().ServerVersion(); err != nil {
+		return st, remoteCluster, ErrRemoteSecretFailed(fmt.Errorf("the remote cluster %s is unreachable: %w", remoteCluster, err))
+	}
+
+	executable, err := istio.getExecutable(version)
+	if err != nil {
+		return st, remoteCluster, ErrRemoteSecretFailed(err)
+	}
+	secret, err := runIstioctlOnCluster(executable, remoteKubeconfig, "create-remote-secret", "--name", remoteCluster)
+	if err != nil {
+		return st, remoteCluster, ErrRemoteSecretFailed(err)
+	}
+
+	if err := istio.applyManifest(ctx, []byte(secret), false, istioRootNamespace, local); err != nil {
+		return st, remoteCluster, ErrRemoteSecretFailed(err)
+	}
+	return status.Installed, remoteCluster, nil
+}
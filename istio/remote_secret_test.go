@@ -0,0 +1,64 @@
+package istio
+
+import "testing"
+
+func TestResolveRemoteCluster(t *testing.T) {
+	kubeconfig := `apiVersion: v1
+kind: Config
+current-context: west
+contexts:
+- name: west
+  context:
+    cluster: west
+`
+	tests := []struct {
+		name       string
+		kubeconfig string
+		cluster    string
+		want       string
+		wantErr    bool
+	}{
+		{
+			name:       "current context",
+			kubeconfig: kubeconfig,
+			want:       "west",
+		},
+		{
+			name:       "cluster name",
+			kubeconfig: kubeconfig,
+			cluster:    " cluster2 ",
+			want:       "cluster2",
+		},
+		{
+			name:       "no current context",
+			kubeconfig: "apiVersion: v1\nkind: Config\n",
+			wantErr:    true,
+		},
+		{
+			name:       "invalid cluster name",
+			kubeconfig: kubeconfig,
+			cluster:    "arn:aws:eks:us-east-1:123456789012:cluster/west",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveRemoteCluster(tt.kubeconfig, tt.cluster)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveRemoteCluster() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolveRemoteCluster() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadRemoteKubeconfig(t *testing.T) {
+	if _, err := readRemoteKubeconfig("  "); err == nil {
+		t.Errorf("readRemoteKubeconfig() error = nil, want the kubeconfig required")
+	}
+	if got, err := readRemoteKubeconfig("apiVersion: v1\nkind: Config\n"); err != nil || got != "apiVersion: v1\nkind: Config" {
+		t.Errorf("readRemoteKubeconfig() = %q, %v, want the inline kubeconfig", got, err)
+	}
+}
@@ -0,0 +1,53 @@
+package istio
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// statusRendered is the status of the dry run of an install, the manifest
+// being generated without being applied
+const statusRendered = "rendered"
+
+// renderManifest generates the manifest of the control plane with istioctl
+// manifest generate of the given version, from the IstioOperator the install
+// applies along with the extra labels and annotations it merges afterwards.
+// Nothing is applied and no cluster is reached. The helm charts install the
+// same resources, though their rendering may differ in the details.
+func (istio *Istio) renderManifest(ctx context.Context, version string, opts installOptions) ([]byte, error) {
+	executable, err := istio.getExecutable(version)
+	if err != nil {
+		return nil, ErrRenderManifestFailed(err)
+	}
+	if err := verifyIstioctlVersion(executable, version); err != nil {
+		return nil, err
+	}
+	operator, err := renderIstioOperator(opts)
+	if err != nil {
+		return nil, err
+	}
+	operatorFile, err := os.CreateTemp("", "istio-operator-*.yaml")
+	if err != nil {
+		return nil, ErrRenderManifestFailed(err)
+	}
+	defer os.Remove(operatorFile.Name())
+	if _, err := operatorFile.Write(operator); err != nil {
+		_ = operatorFile.Close()
+		return nil, ErrRenderManifestFailed(err)
+	}
+	if err := operatorFile.Close(); err != nil {
+		return nil, ErrRenderManifestFailed(err)
+	}
+
+	istio.log(ctx).Info(fmt.Sprintf("Rendering the manifest of Istio %s using istioctl...", version))
+	out, err := runIstioctl(executable, "manifest", "generate", "-f", operatorFile.Name())
+	if err != nil {
+		return nil, ErrRenderManifestFailed(err)
+	}
+	manifest, err := opts.Metadata.manifest([]byte(out))
+	if err != nil {
+		return nil, ErrRenderManifestFailed(err)
+	}
+	return manifest, nil
+}
@@ -0,0 +1,49 @@
+package istio
+
+import (
+	"context"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/layer5io/meshkit/errors"
+)
+
+func TestIstio_renderManifest(t *testing.T) {
+	if platform == "windows" {
+		t.Skip("the fake istioctl is a shell script")
+	}
+	// The fake istioctl generates istiod only for the demo profile, hence
+	// the manifest tells the IstioOperator of the options was passed
+	bin := t.TempDir()
+	script := `#!/bin/sh
+case "$1" in
+version) echo 1.20.0 ;;
+manifest) grep -q "profile: demo" "$4" && printf 'apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: istiod\n  namespace: istio-system\n' ;;
+esac
+`
+	if err := os.WriteFile(path.Join(bin, "istioctl"), []byte(script), 0750); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	istio := &Istio{Adapter: adapter.Adapter{Log: getLoggerHandler(t)}}
+	opts := installOptions{
+		Profile:  "demo",
+		Metadata: extraMetadata{Labels: map[string]string{"team": "payments"}},
+	}
+	got, err := istio.renderManifest(context.Background(), "1.20.0", opts)
+	if err != nil {
+		t.Fatalf("renderManifest() error = %v", err)
+	}
+	if !strings.Contains(string(got), "name: istiod") || !strings.Contains(string(got), "team: payments") {
+		t.Errorf("renderManifest() = %s, want istiod with the extra labels", got)
+	}
+
+	_, err = istio.renderManifest(context.Background(), "1.21.0", opts)
+	if errors.GetCode(err) != ErrIstioctlVersionMismatchCode {
+		t.Errorf("renderManifest() of another minor version error = %v, want ErrIstioctlVersionMismatch", err)
+	}
+}
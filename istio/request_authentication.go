@@ -0,0 +1,69 @@
+package istio
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/layer5io/meshery-istio/internal/config"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// requestAuthenticationValues are the values of the RequestAuthentication
+// template
+type requestAuthenticationValues struct {
+	Name      string
+	Namespace string
+	Selector  map[string]string
+	Issuer    string
+	JWKSURI   string
+	Audiences []string
+}
+
+// newRequestAuthenticationValues validates the RequestAuthentication
+// properties of the operation.
+//
+// The audiences are a comma separated list, the tokens of any audience are
+// accepted when it is empty. The policy is named after the policy-name
+// property, or else after the workloads of its selector. Deleting a policy only
+// needs its name, so the JWT rule is not validated then.
+func newRequestAuthenticationValues(namespace string, props map[string]string, del bool) (*requestAuthenticationValues, error) {
+	values := &requestAuthenticationValues{
+		Name:      strings.TrimSpace(props[config.PolicyName]),
+		Namespace: namespace,
+		Issuer:    strings.TrimSpace(props[config.JWTIssuer]),
+		JWKSURI:   strings.TrimSpace(props[config.JWKSURI]),
+	}
+
+	selector, err := parseWorkloadSelector(props[config.WorkloadSelector])
+	if err != nil {
+		return nil, ErrInvalidRequestAuthentication(err)
+	}
+	values.Selector = selector
+	if values.Name == "" {
+		values.Name = "namespace-jwt"
+		if len(selector) > 0 {
+			values.Name = workloadName(selector) + "-jwt"
+		}
+	}
+	if errs := validation.IsDNS1123Subdomain(values.Name); len(errs) > 0 {
+		return nil, ErrInvalidRequestAuthentication(fmt.Errorf("invalid policy name %q: %s", values.Name, strings.Join(errs, ", ")))
+	}
+	if del {
+		return values, nil
+	}
+
+	if values.Issuer == "" {
+		return nil, ErrInvalidRequestAuthentication(fmt.Errorf("issuer is required"))
+	}
+	uri, err := url.Parse(values.JWKSURI)
+	if err != nil || (uri.Scheme != "http" && uri.Scheme != "https") || uri.Host == "" {
+		return nil, ErrInvalidRequestAuthentication(fmt.Errorf("jwksUri %q is not an http or https URL", values.JWKSURI))
+	}
+	for _, audience := range strings.Split(props[config.JWTAudiences], ",") {
+		if audience = strings.TrimSpace(audience); audience != "" {
+			values.Audiences = append(values.Audiences, audience)
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,114 @@
+package istio
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/layer5io/meshery-istio/internal/config"
+	"gopkg.in/yaml.v2"
+)
+
+func TestRequestAuthentication(t *testing.T) {
+	tmpl, err := os.ReadFile("../templates/policies/request_authentication.yaml")
+	if err != nil {
+		t.Fatalf("unable to read the policy template: %v", err)
+	}
+
+	type jwtRule struct {
+		Issuer    string   `yaml:"issuer"`
+		JWKSURI   string   `yaml:"jwksUri"`
+		Audiences []string `yaml:"audiences"`
+	}
+	tests := []struct {
+		name         string
+		props        map[string]string
+		del          bool
+		wantName     string
+		wantSelector map[string]string
+		wantRules    []jwtRule
+		wantErr      bool
+	}{
+		{
+			name: "namespace",
+			props: map[string]string{
+				config.JWTIssuer: "https://accounts.example.com",
+				config.JWKSURI:   "https://accounts.example.com/.well-known/jwks.json",
+			},
+			wantName:  "namespace-jwt",
+			wantRules: []jwtRule{{Issuer: "https://accounts.example.com", JWKSURI: "https://accounts.example.com/.well-known/jwks.json"}},
+		},
+		{
+			name: "workload with audiences",
+			props: map[string]string{
+				config.JWTIssuer:        "testing@secure.istio.io",
+				config.JWKSURI:          "http://jwks.auth.svc:8080/keys",
+				config.JWTAudiences:     "productpage, reviews,",
+				config.WorkloadSelector: "app=productpage",
+			},
+			wantName:     "productpage-jwt",
+			wantSelector: map[string]string{"app": "productpage"},
+			wantRules:    []jwtRule{{Issuer: "testing@secure.istio.io", JWKSURI: "http://jwks.auth.svc:8080/keys", Audiences: []string{"productpage", "reviews"}}},
+		},
+		{
+			name:     "delete only needs the name",
+			props:    map[string]string{config.PolicyName: "edge-jwt"},
+			del:      true,
+			wantName: "edge-jwt",
+		},
+		{
+			name:    "missing issuer",
+			props:   map[string]string{config.JWKSURI: "https://accounts.example.com/jwks.json"},
+			wantErr: true,
+		},
+		{
+			name:    "relative jwksUri",
+			props:   map[string]string{config.JWTIssuer: "issuer", config.JWKSURI: "/jwks.json"},
+			wantErr: true,
+		},
+		{
+			name:    "jwksUri of another scheme",
+			props:   map[string]string{config.JWTIssuer: "issuer", config.JWKSURI: "file:///etc/jwks.json"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := newRequestAuthenticationValues("bookinfo", tt.props, tt.del)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newRequestAuthenticationValues() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			rendered, err := renderTemplate(string(tmpl), values)
+			if err != nil {
+				t.Fatalf("renderTemplate() error = %v", err)
+			}
+			var policy struct {
+				Metadata struct {
+					Name      string `yaml:"name"`
+					Namespace string `yaml:"namespace"`
+				} `yaml:"metadata"`
+				Spec struct {
+					Selector struct {
+						MatchLabels map[string]string `yaml:"matchLabels"`
+					} `yaml:"selector"`
+					JWTRules []jwtRule `yaml:"jwtRules"`
+				} `yaml:"spec"`
+			}
+			if err := yaml.Unmarshal([]byte(rendered), &policy); err != nil {
+				t.Fatalf("renderTemplate() generated invalid YAML: %v\n%s", err, rendered)
+			}
+			if policy.Metadata.Name != tt.wantName || policy.Metadata.Namespace != "bookinfo" {
+				t.Errorf("renderTemplate() name/namespace = %s/%s, want %s/bookinfo", policy.Metadata.Name, policy.Metadata.Namespace, tt.wantName)
+			}
+			if !reflect.DeepEqual(policy.Spec.Selector.MatchLabels, tt.wantSelector) {
+				t.Errorf("renderTemplate() selector = %v, want %v", policy.Spec.Selector.MatchLabels, tt.wantSelector)
+			}
+			if !reflect.DeepEqual(policy.Spec.JWTRules, tt.wantRules) {
+				t.Errorf("renderTemplate() jwtRules = %+v, want %+v", policy.Spec.JWTRules, tt.wantRules)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package istio
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// requestOptions are the options chosen for a single operation request. The
+// requests of the adapter library have no room for them, hence they are passed
+// as a leading YAML document of the custom body, followed by the manifest in
+// the case of a custom operation:
+//
+//	dryRun: true
+//	---
+//	apiVersion: networking.istio.io/v1beta1
+//	kind: VirtualService
+//	...
+type requestOptions struct {
+	// DryRun validates the manifest of a custom operation with a server
+	// side dry run instead of applying it
+	DryRun bool `yaml:"dryRun"`
+
+	// KubeContexts are the contexts to use for the kubeconfigs of the
+	// request, in their order, instead of their current-context. An empty
+	// entry keeps the current-context.
+	KubeContexts []string `yaml:"kubeContexts"`
+
+	// OperatorManifest is an IstioOperator the install operation applies
+	// verbatim with istioctl instead of the one the adapter renders
+	OperatorManifest string `yaml:"operatorManifest"`
+}
+
+// parseRequestOptions splits the request options from the custom body. The
+// first document is taken as the options only if it has no other fields than
+// the options, otherwise the body is left untouched and the default options
+// are returned.
+func parseRequestOptions(body string) (requestOptions, string) {
+	opts := requestOptions{}
+	trimmed := strings.TrimPrefix(strings.TrimLeft(body, "\n"), "---\n")
+	parts := strings.SplitN(trimmed, "\n---\n", 2)
+	if strings.TrimSpace(parts[0]) == "" {
+		return opts, body
+	}
+	if err := yaml.UnmarshalStrict([]byte(parts[0]), &opts); err != nil {
+		return requestOptions{}, body
+	}
+	if len(parts) == 1 {
+		return opts, ""
+	}
+	return opts, parts[1]
+}
@@ -0,0 +1,72 @@
+package istio
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRequestOptions(t *testing.T) {
+	const manifest = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+`
+	tests := []struct {
+		name         string
+		body         string
+		want         requestOptions
+		wantManifest string
+	}{
+		{
+			name: "empty body",
+		},
+		{
+			name:         "manifest without options",
+			body:         manifest,
+			wantManifest: manifest,
+		},
+		{
+			name:         "options followed by the manifest",
+			body:         "dryRun: true\n---\n" + manifest,
+			want:         requestOptions{DryRun: true},
+			wantManifest: manifest,
+		},
+		{
+			name:         "options after a leading separator",
+			body:         "---\ndryRun: true\n---\n" + manifest,
+			want:         requestOptions{DryRun: true},
+			wantManifest: manifest,
+		},
+		{
+			name: "options only",
+			body: "dryRun: true\n",
+			want: requestOptions{DryRun: true},
+		},
+		{
+			name: "kube contexts",
+			body: "kubeContexts: [east, \"\", west]\n",
+			want: requestOptions{KubeContexts: []string{"east", "", "west"}},
+		},
+		{
+			name: "operator manifest",
+			body: "operatorManifest: |\n  apiVersion: install.istio.io/v1alpha1\n  kind: IstioOperator\n",
+			want: requestOptions{OperatorManifest: "apiVersion: install.istio.io/v1alpha1\nkind: IstioOperator\n"},
+		},
+		{
+			name:         "unknown fields are part of the manifest",
+			body:         "dryRun: true\nkind: ConfigMap\n---\n" + manifest,
+			wantManifest: "dryRun: true\nkind: ConfigMap\n---\n" + manifest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotManifest := parseRequestOptions(tt.body)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseRequestOptions() options = %+v, want %+v", got, tt.want)
+			}
+			if gotManifest != tt.wantManifest {
+				t.Errorf("parseRequestOptions() manifest = %q, want %q", gotManifest, tt.wantManifest)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package istio
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/layer5io/meshery-adapter-library/common"
+	"github.com/layer5io/meshery-istio/internal/config"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// namespacedOperations are the operations applied to the namespace of the
+// request, which the clients would otherwise end up applying to the default
+// namespace by leaving it empty
+var namespacedOperations = map[string]bool{
+	common.BookInfoOperation:                true,
+	common.HTTPBinOperation:                 true,
+	common.ImageHubOperation:                true,
+	common.EmojiVotoOperation:               true,
+	config.GatewayAPIBookInfoOperation:      true,
+	config.LabelNamespace:                   true,
+	config.DenyAllPolicyOperation:           true,
+	config.StrictMTLSPolicyOperation:        true,
+	config.MutualMTLSPolicyOperation:        true,
+	config.DisableMTLSPolicyOperation:       true,
+	config.NamespaceMTLSPolicyOperation:     true,
+	config.AuthorizationPolicyOperation:     true,
+	config.RequestAuthenticationOperation:   true,
+	config.FaultInjectionOperation:          true,
+	config.TrafficMirrorOperation:           true,
+	config.ExposeServiceOperation:           true,
+	config.DestinationRuleOperation:         true,
+	config.ServiceEntryOperation:            true,
+	config.WorkloadEntryOperation:           true,
+	config.WorkloadGroupOperation:           true,
+	config.SetProxyLogLevelOperation:        true,
+	config.ProxyConfigOperation:             true,
+	config.WaypointOperation:                true,
+	config.ConnectivityTestOperation:        true,
+	config.VerifyMTLSOperation:              true,
+	config.CleanupNamespaceOperation:        true,
+	config.SwitchNamespaceRevisionOperation: true,
+	config.RestartWorkloadsOperation:        true,
+}
+
+// validateOperationRequest checks the fields of the request the operation
+// relies on before it is started: the operation is named, the namespace of
+// the namespaced operations is set, any namespace is a valid name and the
+// custom operation has a manifest. The custom body is the one left once the
+// request options are split from it.
+func validateOperationRequest(opReq adapter.OperationRequest) error {
+	var problems []string
+	if strings.TrimSpace(opReq.OperationName) == "" {
+		problems = append(problems, "no operation name")
+	}
+	namespace := strings.TrimSpace(opReq.Namespace)
+	if namespace == "" && namespacedOperations[opReq.OperationName] {
+		problems = append(problems, "no namespace, the operation applies to a namespace")
+	}
+	if namespace != "" {
+		if errs := validation.IsDNS1123Label(opReq.Namespace); len(errs) > 0 {
+			problems = append(problems, fmt.Sprintf("invalid namespace %q: %s", opReq.Namespace, strings.Join(errs, ", ")))
+		}
+	}
+	if opReq.OperationName == common.CustomOperation && strings.TrimSpace(opReq.CustomBody) == "" {
+		problems = append(problems, "no manifest in the custom body")
+	}
+	if len(problems) > 0 {
+		return ErrInvalidOperationRequest(opReq.OperationName, problems)
+	}
+	return nil
+}
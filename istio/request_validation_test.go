@@ -0,0 +1,67 @@
+package istio
+
+import (
+	"testing"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/layer5io/meshery-adapter-library/common"
+	"github.com/layer5io/meshery-istio/internal/config"
+	"github.com/layer5io/meshkit/errors"
+)
+
+func TestValidateOperationRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		opReq   adapter.OperationRequest
+		wantErr bool
+	}{
+		{
+			name:  "install without namespace",
+			opReq: adapter.OperationRequest{OperationName: config.IstioOperation},
+		},
+		{
+			name:  "policy with namespace",
+			opReq: adapter.OperationRequest{OperationName: config.StrictMTLSPolicyOperation, Namespace: "payments"},
+		},
+		{
+			name:    "policy without namespace",
+			opReq:   adapter.OperationRequest{OperationName: config.StrictMTLSPolicyOperation, Namespace: " "},
+			wantErr: true,
+		},
+		{
+			name:    "label without namespace",
+			opReq:   adapter.OperationRequest{OperationName: config.LabelNamespace, IsDeleteOperation: true},
+			wantErr: true,
+		},
+		{
+			name:    "invalid namespace",
+			opReq:   adapter.OperationRequest{OperationName: config.IstioOperation, Namespace: "Payments_Team"},
+			wantErr: true,
+		},
+		{
+			name:  "custom with manifest",
+			opReq: adapter.OperationRequest{OperationName: common.CustomOperation, CustomBody: "apiVersion: v1\nkind: ConfigMap\n"},
+		},
+		{
+			name:    "custom without manifest",
+			opReq:   adapter.OperationRequest{OperationName: common.CustomOperation, CustomBody: "\n"},
+			wantErr: true,
+		},
+		{
+			name:    "no operation",
+			opReq:   adapter.OperationRequest{Namespace: "default"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateOperationRequest(tt.opReq)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateOperationRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && errors.GetCode(err) != ErrInvalidOperationRequestCode {
+				t.Errorf("validateOperationRequest() error code = %s, want %s", errors.GetCode(err), ErrInvalidOperationRequestCode)
+			}
+		})
+	}
+}
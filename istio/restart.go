@@ -0,0 +1,105 @@
+package istio
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// restartPatch is the patch of the pod template restarting a workload, the
+// way kubectl rollout restart does
+func restartPatch(now time.Time) []byte {
+	return []byte(fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{"kubectl.kubernetes.io/restartedAt":%q}}}}}`, now.Format(time.RFC3339)))
+}
+
+// restartInjectedWorkloads triggers a rolling restart of the deployments,
+// statefulsets and daemonsets of the namespace on every cluster, for their
+// pods to be injected with the proxy of the current injection of the
+// namespace. The workloads opting out of the injection are left alone. The
+// number of workloads restarted is returned, the rollouts are not waited for.
+func (istio *Istio) restartInjectedWorkloads(namespace string, kubeConfigs []string) (int, error) {
+	var mx sync.Mutex
+	var restarted int
+	err := forEachCluster(kubeConfigs, func(k8sconfig string) error {
+		mclient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return err
+		}
+		n, err := restartWorkloads(mclient.KubeClient, namespace, time.Now())
+		mx.Lock()
+		restarted += n
+		mx.Unlock()
+		return err
+	}, nil)
+	if err != nil {
+		return restarted, ErrWorkloadRestartFailed(err)
+	}
+	return restarted, nil
+}
+
+// restartWorkloads restarts the workloads of the namespace which don't opt
+// out of the injection and returns how many were restarted
+func restartWorkloads(client kubernetes.Interface, namespace string, now time.Time) (int, error) {
+	apps := client.AppsV1()
+	patch := restartPatch(now)
+	var restarted int
+	restart := func(kind, name string, template metav1.ObjectMeta, apply func() error) error {
+		if template.Labels["sidecar.istio.io/inject"] == "false" || template.Annotations["sidecar.istio.io/inject"] == "false" {
+			return nil
+		}
+		if err := apply(); err != nil {
+			return fmt.Errorf("unable to restart %s %s: %w", kind, name, err)
+		}
+		restarted++
+		return nil
+	}
+
+	deployments, err := apps.Deployments(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return restarted, err
+	}
+	for _, d := range deployments.Items {
+		err := restart("deployment", d.Name, d.Spec.Template.ObjectMeta, func() error {
+			_, err := apps.Deployments(namespace).Patch(context.TODO(), d.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+			return err
+		})
+		if err != nil {
+			return restarted, err
+		}
+	}
+
+	statefulsets, err := apps.StatefulSets(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return restarted, err
+	}
+	for _, s := range statefulsets.Items {
+		err := restart("statefulset", s.Name, s.Spec.Template.ObjectMeta, func() error {
+			_, err := apps.StatefulSets(namespace).Patch(context.TODO(), s.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+			return err
+		})
+		if err != nil {
+			return restarted, err
+		}
+	}
+
+	daemonsets, err := apps.DaemonSets(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return restarted, err
+	}
+	for _, d := range daemonsets.Items {
+		err := restart("daemonset", d.Name, d.Spec.Template.ObjectMeta, func() error {
+			_, err := apps.DaemonSets(namespace).Patch(context.TODO(), d.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+			return err
+		})
+		if err != nil {
+			return restarted, err
+		}
+	}
+	return restarted, nil
+}
@@ -0,0 +1,58 @@
+package istio
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRestartWorkloads(t *testing.T) {
+	meta := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: "bookinfo"}
+	}
+	optOut := appsv1.Deployment{ObjectMeta: meta("debug")}
+	optOut.Spec.Template.Labels = map[string]string{"sidecar.istio.io/inject": "false"}
+	client := fake.NewSimpleClientset([]runtime.Object{
+		&appsv1.Deployment{ObjectMeta: meta("productpage")},
+		&optOut,
+		&appsv1.StatefulSet{ObjectMeta: meta("mongodb")},
+		&appsv1.DaemonSet{ObjectMeta: meta("node-agent")},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "reviews", Namespace: "default"}},
+	}...)
+
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	restarted, err := restartWorkloads(client, "bookinfo", now)
+	if err != nil {
+		t.Fatalf("restartWorkloads() error = %v", err)
+	}
+	if restarted != 3 {
+		t.Errorf("restartWorkloads() restarted %d workloads, want 3", restarted)
+	}
+
+	restartedAt := func(template map[string]string) string {
+		return template["kubectl.kubernetes.io/restartedAt"]
+	}
+	want := now.Format(time.RFC3339)
+	deployment, _ := client.AppsV1().Deployments("bookinfo").Get(context.TODO(), "productpage", metav1.GetOptions{})
+	statefulset, _ := client.AppsV1().StatefulSets("bookinfo").Get(context.TODO(), "mongodb", metav1.GetOptions{})
+	daemonset, _ := client.AppsV1().DaemonSets("bookinfo").Get(context.TODO(), "node-agent", metav1.GetOptions{})
+	for name, got := range map[string]string{
+		"productpage": restartedAt(deployment.Spec.Template.Annotations),
+		"mongodb":     restartedAt(statefulset.Spec.Template.Annotations),
+		"node-agent":  restartedAt(daemonset.Spec.Template.Annotations),
+	} {
+		if got != want {
+			t.Errorf("%s restartedAt = %q, want %q", name, got, want)
+		}
+	}
+	debug, _ := client.AppsV1().Deployments("bookinfo").Get(context.TODO(), "debug", metav1.GetOptions{})
+	other, _ := client.AppsV1().Deployments("default").Get(context.TODO(), "reviews", metav1.GetOptions{})
+	if restartedAt(debug.Spec.Template.Annotations) != "" || restartedAt(other.Spec.Template.Annotations) != "" {
+		t.Errorf("restartWorkloads() restarted a workload opting out of the injection or of another namespace")
+	}
+}
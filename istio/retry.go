@@ -0,0 +1,102 @@
+package istio
+
+import (
+	"context"
+	stderrors "errors"
+	"strings"
+	"time"
+
+	"github.com/layer5io/meshery-istio/internal/config"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	// retryInitialBackoff is the wait before the first retry, it doubles
+	// with every retry up to retryMaxBackoff
+	retryInitialBackoff = time.Second
+	retryMaxBackoff     = 16 * time.Second
+)
+
+// retryProgress is called before an attempt on a cluster is retried, err is
+// the error of the failed attempt
+type retryProgress func(cluster string, attempt int, err error)
+
+// sleep waits for the backoff unless the context is done first, it is
+// replaced in the tests to not wait
+var sleep = func(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// transientErrors are the messages of the errors, returned as plain text by
+// istioctl or wrapped by meshkit, which are worth retrying
+var transientErrors = []string{
+	"the object has been modified",
+	"connection refused",
+	"connection reset by peer",
+	"i/o timeout",
+	"TLS handshake timeout",
+	"context deadline exceeded",
+	"etcdserver: request timed out",
+	"the server is currently unable to handle the request",
+	"Too many requests",
+	"failed calling webhook",
+}
+
+// withRetry runs fn until it succeeds, fails with an error which is not
+// retriable, config.ApplyMaxAttempts attempts are made or the context is done.
+// onRetry, if set, is called before every retry.
+func withRetry(ctx context.Context, fn func() error, onRetry func(attempt int, err error)) error {
+	attempts := config.ApplyMaxAttempts
+	if attempts <= 0 {
+		attempts = 1
+	}
+	backoff := retryInitialBackoff
+	for attempt := 1; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		err := fn()
+		if err == nil || attempt >= attempts || !isRetriable(err) {
+			return err
+		}
+		if onRetry != nil {
+			onRetry(attempt, err)
+		}
+		if err := sleep(ctx, backoff); err != nil {
+			return err
+		}
+		backoff *= 2
+		if backoff > retryMaxBackoff {
+			backoff = retryMaxBackoff
+		}
+	}
+}
+
+// isRetriable reports whether the error is a transient failure of the API
+// server, such as a conflict or a timeout. Validation and authorization
+// failures are not retriable.
+func isRetriable(err error) bool {
+	var status apierrors.APIStatus
+	if stderrors.As(err, &status) {
+		return apierrors.IsConflict(err) ||
+			apierrors.IsServerTimeout(err) ||
+			apierrors.IsTimeout(err) ||
+			apierrors.IsTooManyRequests(err) ||
+			apierrors.IsServiceUnavailable(err) ||
+			apierrors.IsInternalError(err)
+	}
+	msg := err.Error()
+	for _, transient := range transientErrors {
+		if strings.Contains(msg, transient) {
+			return true
+		}
+	}
+	return false
+}
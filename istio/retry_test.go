@@ -0,0 +1,79 @@
+package istio
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/layer5io/meshery-istio/internal/config"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestWithRetry(t *testing.T) {
+	defer func(n int) { config.ApplyMaxAttempts = n }(config.ApplyMaxAttempts)
+	config.ApplyMaxAttempts = 4
+	defer func(f func(context.Context, time.Duration) error) { sleep = f }(sleep)
+	var waits []time.Duration
+	sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+
+	conflict := apierrors.NewConflict(schema.GroupResource{Group: "apps", Resource: "deployments"}, "istiod", fmt.Errorf("the object has been modified"))
+	forbidden := apierrors.NewForbidden(schema.GroupResource{Resource: "namespaces"}, "istio-system", fmt.Errorf("denied"))
+
+	tests := []struct {
+		name         string
+		errs         []error
+		wantAttempts int
+		wantErr      bool
+	}{
+		{name: "succeeds at once", errs: []error{nil}, wantAttempts: 1},
+		{name: "conflict is retried", errs: []error{conflict, conflict, nil}, wantAttempts: 3},
+		{name: "istioctl timeout is retried", errs: []error{ErrRunIstioCtlCmd(fmt.Errorf("exit status 1: dial tcp: i/o timeout"), ""), nil}, wantAttempts: 2},
+		{name: "forbidden fails fast", errs: []error{forbidden, nil}, wantAttempts: 1, wantErr: true},
+		{name: "validation fails fast", errs: []error{fmt.Errorf("unknown field \"replica\""), nil}, wantAttempts: 1, wantErr: true},
+		{name: "gives up after max attempts", errs: []error{conflict, conflict, conflict, conflict, nil}, wantAttempts: 4, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			waits = nil
+			attempts, retries := 0, 0
+			err := withRetry(context.Background(), func() error {
+				err := tt.errs[attempts]
+				attempts++
+				return err
+			}, func(attempt int, err error) {
+				retries++
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("withRetry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if attempts != tt.wantAttempts || retries != tt.wantAttempts-1 {
+				t.Errorf("withRetry() made %d attempts and %d retries, want %d attempts", attempts, retries, tt.wantAttempts)
+			}
+			for i, wait := range waits {
+				if want := retryInitialBackoff << i; wait != want {
+					t.Errorf("withRetry() waited %v before retry %d, want %v", wait, i+1, want)
+				}
+			}
+		})
+	}
+}
+
+func TestWithRetryCancelled(t *testing.T) {
+	defer func(n int) { config.ApplyMaxAttempts = n }(config.ApplyMaxAttempts)
+	config.ApplyMaxAttempts = 3
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts := 0
+	err := withRetry(ctx, func() error {
+		attempts++
+		cancel()
+		return fmt.Errorf("connection refused")
+	}, nil)
+	if err != context.Canceled || attempts != 1 {
+		t.Errorf("withRetry() = %v after %d attempts, want to stop once cancelled", err, attempts)
+	}
+}
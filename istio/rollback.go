@@ -0,0 +1,165 @@
+package istio
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RollbackResult describes the rollback of the control plane
+type RollbackResult struct {
+	// From is the version the control plane ran before the rollback, keyed
+	// by cluster
+	From map[string]string
+
+	// Skewed are the proxies still running a minor version other than the
+	// one of the control plane, as namespace/pod (version), until their
+	// workloads are restarted
+	Skewed []string
+}
+
+// rollbackIstio reinstalls the control plane of the default revision at the
+// target version, older than the one running on every cluster. The release
+// of the target version is fetched before anything is changed. The charts
+// are used, whose base chart isn't reinstalled over the CRDs of the later
+// version, hence the configuration resources are kept as is. The proxies
+// keep running the later version until their workloads are restarted, they
+// are returned as skewed. ErrRollbackFailed is returned if the release
+// can't be fetched or the control plane can't be reinstalled.
+func (istio *Istio) rollbackIstio(ctx context.Context, targetVersion, namespace string, opts installOptions, kubeConfigs []string) (RollbackResult, error) {
+	var result RollbackResult
+	opts.Revision = ""
+	installed, err := installedVersions(ctx, "", kubeConfigs)
+	if err != nil {
+		return result, ErrRollbackFailed(targetVersion, err)
+	}
+	if err := rollbackAllowed(installed, targetVersion, len(kubeConfigs)); err != nil {
+		return result, ErrRollbackFailed(targetVersion, err)
+	}
+	result.From = installed
+	if _, err := istio.getIstioRelease(targetVersion); err != nil {
+		return result, ErrRollbackFailed(targetVersion, fmt.Errorf("unable to fetch the release: %w", err))
+	}
+	if _, err := istio.installIstio(ctx, false, false, targetVersion, namespace, opts, kubeConfigs); err != nil {
+		return result, ErrRollbackFailed(targetVersion, err)
+	}
+
+	var mx sync.Mutex
+	err = forEachCluster(kubeConfigs, func(k8sconfig string) error {
+		mclient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return err
+		}
+		pods, err := mclient.KubeClient.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		skewed := proxyVersionSkew(pods.Items, targetVersion)
+		for i := range skewed {
+			skewed[i] = fmt.Sprintf("%s: %s", clusterName(k8sconfig), skewed[i])
+		}
+		mx.Lock()
+		result.Skewed = append(result.Skewed, skewed...)
+		mx.Unlock()
+		return nil
+	}, nil)
+	sort.Strings(result.Skewed)
+	if err != nil {
+		istio.log(ctx).Info(fmt.Sprintf("Unable to read the version of the proxies: %v", err))
+	}
+	return result, nil
+}
+
+// rollbackAllowed checks that the control plane runs on every cluster at a
+// version later than the target one
+func rollbackAllowed(installed map[string]string, target string, clusters int) error {
+	if len(installed) < clusters {
+		return fmt.Errorf("the control plane of the default revision isn't running on every cluster")
+	}
+	var problems []string
+	for cluster, version := range installed {
+		newer, err := newerVersion(version, target)
+		if err != nil {
+			return err
+		}
+		if !newer {
+			problems = append(problems, fmt.Sprintf("%s runs %s", cluster, version))
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("%s is not older than the running version: %s", target, strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+// newerVersion reports whether the version a is later than b, both being
+// major.minor.patch versions
+func newerVersion(a, b string) (bool, error) {
+	va, err := parseVersionNumbers(a)
+	if err != nil {
+		return false, err
+	}
+	vb, err := parseVersionNumbers(b)
+	if err != nil {
+		return false, err
+	}
+	for i := range va {
+		if va[i] != vb[i] {
+			return va[i] > vb[i], nil
+		}
+	}
+	return false, nil
+}
+
+// parseVersionNumbers returns the major, minor and patch numbers of the
+// version, the pre-release suffix being left out
+func parseVersionNumbers(version string) ([3]int, error) {
+	var numbers [3]int
+	core, _, _ := strings.Cut(strings.TrimPrefix(version, "v"), "-")
+	parts := strings.Split(core, ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return numbers, fmt.Errorf("invalid version %q", version)
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return numbers, fmt.Errorf("invalid version %q", version)
+		}
+		numbers[i] = n
+	}
+	return numbers, nil
+}
+
+// proxyVersionSkew returns the injected pods whose proxy runs a minor version
+// other than the one of the control plane, the proxies running either as a
+// regular container or as a native sidecar
+func proxyVersionSkew(pods []corev1.Pod, version string) []string {
+	var skewed []string
+	for _, pod := range pods {
+		if _, ok := pod.Annotations["sidecar.istio.io/status"]; !ok || pod.DeletionTimestamp != nil {
+			continue
+		}
+		var containers []corev1.Container
+		containers = append(containers, pod.Spec.Containers...)
+		containers = append(containers, pod.Spec.InitContainers...)
+		for _, container := range containers {
+			if container.Name != "istio-proxy" {
+				continue
+			}
+			proxy := imageVersion(container.Image)
+			if match, err := sameMinorVersion(proxy, version); err == nil && !match {
+				skewed = append(skewed, fmt.Sprintf("%s/%s (%s)", pod.Namespace, pod.Name, proxy))
+			}
+			break
+		}
+	}
+	return skewed
+}
@@ -0,0 +1,94 @@
+package istio
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRollbackAllowed(t *testing.T) {
+	tests := []struct {
+		name      string
+		installed map[string]string
+		target    string
+		clusters  int
+		wantErr   bool
+	}{
+		{
+			name:      "older patch",
+			installed: map[string]string{"east": "1.22.1", "west": "1.22.2"},
+			target:    "1.22.0",
+			clusters:  2,
+		},
+		{
+			name:      "older minor",
+			installed: map[string]string{"east": "1.23.0"},
+			target:    "v1.22.3",
+			clusters:  1,
+		},
+		{
+			name:      "same version",
+			installed: map[string]string{"east": "1.22.0"},
+			target:    "1.22.0",
+			clusters:  1,
+			wantErr:   true,
+		},
+		{
+			name:      "newer on a cluster",
+			installed: map[string]string{"east": "1.23.0", "west": "1.21.0"},
+			target:    "1.22.0",
+			clusters:  2,
+			wantErr:   true,
+		},
+		{
+			name:      "not installed on a cluster",
+			installed: map[string]string{"east": "1.23.0"},
+			target:    "1.22.0",
+			clusters:  2,
+			wantErr:   true,
+		},
+		{
+			name:      "invalid version",
+			installed: map[string]string{"east": "latest"},
+			target:    "1.22.0",
+			clusters:  1,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := rollbackAllowed(tt.installed, tt.target, tt.clusters)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("rollbackAllowed() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProxyVersionSkew(t *testing.T) {
+	pod := func(name string, injected bool, native bool, image string) corev1.Pod {
+		p := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+		if injected {
+			p.Annotations = map[string]string{"sidecar.istio.io/status": "{}"}
+		}
+		containers := []corev1.Container{{Name: "istio-proxy", Image: image}}
+		if native {
+			p.Spec.InitContainers = containers
+		} else {
+			p.Spec.Containers = append([]corev1.Container{{Name: "app", Image: "app:1.23.0"}}, containers...)
+		}
+		return p
+	}
+	pods := []corev1.Pod{
+		pod("productpage", true, false, "docker.io/istio/proxyv2:1.23.0"),
+		pod("reviews", true, true, "docker.io/istio/proxyv2:1.23.0-distroless"),
+		pod("ratings", true, false, "docker.io/istio/proxyv2:1.22.3"),
+		pod("details", false, false, "docker.io/istio/proxyv2:1.23.0"),
+	}
+	want := []string{"default/productpage (1.23.0)", "default/reviews (1.23.0)"}
+	if got := proxyVersionSkew(pods, "1.22.0"); !reflect.DeepEqual(got, want) {
+		t.Errorf("proxyVersionSkew() = %v, want %v", got, want)
+	}
+}
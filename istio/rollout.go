@@ -0,0 +1,139 @@
+package istio
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// istioRolloutTimeout is how long the control plane deployments are
+	// waited for to roll out after the install
+	istioRolloutTimeout = 5 * time.Minute
+
+	// addonRolloutTimeout is how long the addon deployments are waited for to
+	// roll out after the install
+	addonRolloutTimeout = 3 * time.Minute
+
+	// workloadRolloutTimeout is how long the restarted workloads are waited
+	// for to roll out
+	workloadRolloutTimeout = 5 * time.Minute
+)
+
+// rolloutPollInterval is how often the deployments are checked while waiting
+// for them to roll out
+var rolloutPollInterval = 2 * time.Second
+
+// waitForRollout polls the deployments of the namespace of every cluster until
+// they are all available. ErrRolloutNotReady is returned with the deployments,
+// prefixed with their cluster, which didn't become available within the
+// timeout.
+func (istio *Istio) waitForRollout(ctx context.Context, namespace string, deployments []string, timeout time.Duration, kubeConfigs []string) error {
+	notReady, err := istio.rolloutStatus(ctx, namespace, deployments, timeout, kubeConfigs)
+	if err == nil || len(notReady) == 0 {
+		return err
+	}
+	return ErrRolloutNotReady(notReady, err)
+}
+
+// rolloutStatus waits for the deployments like waitForRollout, the
+// deployments which didn't become available are returned along with the
+// plain error so that the callers can report them with their own error
+func (istio *Istio) rolloutStatus(ctx context.Context, namespace string, deployments []string, timeout time.Duration, kubeConfigs []string) ([]string, error) {
+	if len(deployments) == 0 {
+		return nil, nil
+	}
+	var mx sync.Mutex
+	var notReady []string
+	err := forEachCluster(kubeConfigs, func(k8sconfig string) error {
+		mclient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return err
+		}
+		pending, err := pollRollout(ctx, func(name string) (*appsv1.Deployment, error) {
+			return mclient.KubeClient.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		}, deployments, timeout)
+		if err != nil {
+			cluster := clusterName(k8sconfig)
+			mx.Lock()
+			for _, name := range pending {
+				notReady = append(notReady, fmt.Sprintf("%s: %s", cluster, name))
+			}
+			mx.Unlock()
+		}
+		return err
+	}, nil)
+	sort.Strings(notReady)
+	return notReady, err
+}
+
+// pollRollout gets the deployments until they are all available, the timeout
+// is over or the context is done, the deployments which are still not
+// available are returned along with the error
+func pollRollout(ctx context.Context, get func(name string) (*appsv1.Deployment, error), deployments []string, timeout time.Duration) ([]string, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		var pending []string
+		for _, name := range deployments {
+			deployment, err := get(name)
+			if err != nil || !deploymentAvailable(deployment) {
+				pending = append(pending, name)
+			}
+		}
+		if len(pending) == 0 {
+			return nil, nil
+		}
+		if time.Now().After(deadline) {
+			return pending, fmt.Errorf("deployments %s not available after %s", strings.Join(pending, ", "), timeout)
+		}
+		select {
+		case <-ctx.Done():
+			return pending, ctx.Err()
+		case <-time.After(rolloutPollInterval):
+		}
+	}
+}
+
+func deploymentAvailable(deployment *appsv1.Deployment) bool {
+	if deployment.Status.ObservedGeneration < deployment.Generation {
+		return false
+	}
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentAvailable {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// restartDeployments restarts the deployments of the namespace matching the
+// label selector, the way kubectl rollout restart does, and waits for them to
+// roll out. The restarted deployments are returned.
+func restartDeployments(ctx context.Context, mclient *mesherykube.Client, namespace, selector string, timeout time.Duration) ([]string, error) {
+	deployments, err := mclient.KubeClient.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	patch := restartPatch(time.Now())
+	var names []string
+	for _, deployment := range deployments.Items {
+		_, err := mclient.KubeClient.AppsV1().Deployments(namespace).Patch(ctx, deployment.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+		if err != nil {
+			return names, err
+		}
+		names = append(names, deployment.Name)
+	}
+	_, err = pollRollout(ctx, func(name string) (*appsv1.Deployment, error) {
+		return mclient.KubeClient.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	}, names, timeout)
+	return names, err
+}
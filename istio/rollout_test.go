@@ -0,0 +1,89 @@
+package istio
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestPollRollout(t *testing.T) {
+	defer func(d time.Duration) { rolloutPollInterval = d }(rolloutPollInterval)
+	rolloutPollInterval = time.Millisecond
+
+	available := func(generation, observed int64) *appsv1.Deployment {
+		d := &appsv1.Deployment{}
+		d.Generation = generation
+		d.Status.ObservedGeneration = observed
+		d.Status.Conditions = []appsv1.DeploymentCondition{{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue}}
+		return d
+	}
+
+	polls := 0
+	pending, err := pollRollout(context.Background(), func(name string) (*appsv1.Deployment, error) {
+		polls++
+		switch name {
+		case "istiod":
+			return available(1, 1), nil
+		case "istio-ingressgateway":
+			// Rolls out on the second poll
+			if polls > 2 {
+				return available(2, 2), nil
+			}
+			return available(2, 1), nil
+		}
+		return nil, fmt.Errorf("deployment %s not found", name)
+	}, []string{"istiod", "istio-ingressgateway"}, time.Second)
+	if err != nil || pending != nil {
+		t.Errorf("pollRollout() = %v, %v, want the deployments rolled out", pending, err)
+	}
+
+	pending, err = pollRollout(context.Background(), func(name string) (*appsv1.Deployment, error) {
+		if name == "grafana" {
+			return nil, fmt.Errorf("deployment %s not found", name)
+		}
+		return available(1, 1), nil
+	}, []string{"prometheus", "grafana"}, 10*time.Millisecond)
+	if err == nil || !reflect.DeepEqual(pending, []string{"grafana"}) {
+		t.Errorf("pollRollout() = %v, %v, want grafana not rolled out", pending, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	pending, err = pollRollout(ctx, func(name string) (*appsv1.Deployment, error) {
+		return available(2, 1), nil
+	}, []string{"istiod"}, time.Minute)
+	if err != context.Canceled || !reflect.DeepEqual(pending, []string{"istiod"}) {
+		t.Errorf("pollRollout() = %v, %v, want istiod pending once cancelled", pending, err)
+	}
+}
+
+func TestInstallPhaseDeployments(t *testing.T) {
+	tests := []struct {
+		opts installOptions
+		want map[installPhase][]string
+	}{
+		{opts: installOptions{Profile: "minimal"}, want: map[installPhase][]string{phaseIstiod: {"istiod"}}},
+		{opts: installOptions{Profile: "default"}, want: map[installPhase][]string{phaseIstiod: {"istiod"}, phaseGateways: {"istio-ingressgateway"}}},
+		{opts: installOptions{Profile: "demo"}, want: map[installPhase][]string{phaseIstiod: {"istiod"}, phaseGateways: {"istio-ingressgateway", "istio-egressgateway"}}},
+		{opts: installOptions{Profile: "default", Revision: "1-20"}, want: map[installPhase][]string{phaseIstiod: {"istiod-1-20"}}},
+	}
+	for _, tt := range tests {
+		for _, phase := range installPhases {
+			if got := phase.deployments(tt.opts); !reflect.DeepEqual(got, tt.want[phase]) {
+				t.Errorf("%s deployments(%+v) = %v, want %v", phase, tt.opts, got, tt.want[phase])
+			}
+		}
+	}
+	percent := 0
+	for _, phase := range installPhases {
+		if phase.percent() <= percent || phase.percent() >= 100 {
+			t.Errorf("%s percent = %d, want between %d and 100", phase, phase.percent(), percent)
+		}
+		percent = phase.percent()
+	}
+}
@@ -0,0 +1,200 @@
+package istio
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	kubeerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// caCertsSecret is the secret istiod signs the workload certificates
+	// with instead of its self-signed root
+	caCertsSecret = "cacerts"
+
+	// rootCAValidity is how long the generated root certificates are valid
+	rootCAValidity = 10 * 365 * 24 * time.Hour
+)
+
+// rootCA is the signing CA plugged into istiod with the cacerts secret
+type rootCA struct {
+	// Cert and Key are the PEM encoded certificate and key istiod signs with
+	Cert []byte
+	Key  []byte
+
+	// Chain is the PEM encoded chain from the signing certificate up to the
+	// root, and Root the root certificate
+	Chain []byte
+	Root  []byte
+}
+
+// secretData returns the data of the cacerts secret
+func (ca *rootCA) secretData() map[string][]byte {
+	return map[string][]byte{
+		"ca-cert.pem":    ca.Cert,
+		"ca-key.pem":     ca.Key,
+		"cert-chain.pem": ca.Chain,
+		"root-cert.pem":  ca.Root,
+	}
+}
+
+// generateRootCA generates a self-signed root, used as is as the signing CA
+func generateRootCA(now time.Time) (*rootCA, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"Istio"}, CommonName: "Root CA"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(rootCAValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	return &rootCA{
+		Cert:  cert,
+		Key:   pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}),
+		Chain: cert,
+		Root:  cert,
+	}, nil
+}
+
+// parseRootCA parses a user supplied CA from PEM blocks: the signing
+// certificate first, followed by the rest of its chain up to the root, and
+// the private key of the signing certificate.
+func parseRootCA(bundle string) (*rootCA, error) {
+	var certs []*pem.Block
+	var key *pem.Block
+	rest := []byte(bundle)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		switch {
+		case block.Type == "CERTIFICATE":
+			certs = append(certs, block)
+		case strings.HasSuffix(block.Type, "PRIVATE KEY"):
+			if key != nil {
+				return nil, fmt.Errorf("more than one private key is supplied")
+			}
+			key = block
+		default:
+			return nil, fmt.Errorf("unexpected %s PEM block", block.Type)
+		}
+	}
+	if len(certs) == 0 || key == nil {
+		return nil, fmt.Errorf("a certificate and its private key are required")
+	}
+
+	ca := &rootCA{
+		Cert: pem.EncodeToMemory(certs[0]),
+		Key:  pem.EncodeToMemory(key),
+		Root: pem.EncodeToMemory(certs[len(certs)-1]),
+	}
+	if _, err := tls.X509KeyPair(ca.Cert, ca.Key); err != nil {
+		return nil, fmt.Errorf("the private key doesn't match the certificate: %w", err)
+	}
+	for i, block := range certs {
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid certificate %d: %w", i, err)
+		}
+		if !cert.IsCA {
+			return nil, fmt.Errorf("certificate %s is not a CA", cert.Subject)
+		}
+		ca.Chain = append(ca.Chain, pem.EncodeToMemory(block)...)
+	}
+	return ca, nil
+}
+
+// rotateRootCA replaces the cacerts secret of the namespace of istiod in
+// every cluster and restarts istiod so that it signs with the new CA. The
+// CA is taken from the custom body if any, or else a new root is generated,
+// the clusters sharing the same one so that they keep trusting each other.
+// The workloads keep their certificates until they are rotated, hence the
+// proxies reconnect as their certificates are renewed.
+func (istio *Istio) rotateRootCA(ctx context.Context, namespace, customBody string, kubeConfigs []string) error {
+	var ca *rootCA
+	var err error
+	if strings.TrimSpace(customBody) != "" {
+		ca, err = parseRootCA(customBody)
+	} else {
+		ca, err = generateRootCA(time.Now())
+	}
+	if err != nil {
+		return ErrCARotationFailed(err)
+	}
+
+	err = forEachCluster(kubeConfigs, func(k8sconfig string) error {
+		mclient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return err
+		}
+		if err := updateCACerts(mclient, namespace, ca); err != nil {
+			return fmt.Errorf("unable to update the %s secret: %w", caCertsSecret, err)
+		}
+		if err := restartIstiod(ctx, mclient, namespace); err != nil {
+			return fmt.Errorf("unable to restart istiod: %w", err)
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		return ErrCARotationFailed(err)
+	}
+	return nil
+}
+
+// updateCACerts creates or replaces the cacerts secret
+func updateCACerts(mclient *mesherykube.Client, namespace string, ca *rootCA) error {
+	secrets := mclient.KubeClient.CoreV1().Secrets(namespace)
+	secret, err := secrets.Get(context.TODO(), caCertsSecret, metav1.GetOptions{})
+	if kubeerror.IsNotFound(err) {
+		_, err = secrets.Create(context.TODO(), &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: caCertsSecret, Namespace: namespace},
+			Type:       corev1.SecretTypeOpaque,
+			Data:       ca.secretData(),
+		}, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	secret.Data = ca.secretData()
+	_, err = secrets.Update(context.TODO(), secret, metav1.UpdateOptions{})
+	return err
+}
+
+// restartIstiod restarts the istiod deployments of every revision and waits
+// for them to roll out
+func restartIstiod(ctx context.Context, mclient *mesherykube.Client, namespace string) error {
+	restarted, err := restartDeployments(ctx, mclient, namespace, "app=istiod", istioRolloutTimeout)
+	if err == nil && len(restarted) == 0 {
+		return fmt.Errorf("no istiod deployment found in %s", namespace)
+	}
+	return err
+}
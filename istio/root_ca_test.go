@@ -0,0 +1,129 @@
+package istio
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestRootCA(t *testing.T) {
+	root, err := generateRootCA(time.Now())
+	if err != nil {
+		t.Fatalf("generateRootCA() error = %v", err)
+	}
+	block, _ := pem.Decode(root.Cert)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("generateRootCA() generated an invalid certificate: %v", err)
+	}
+	if !cert.IsCA || cert.CheckSignatureFrom(cert) != nil {
+		t.Errorf("generateRootCA() generated a certificate which is not a self-signed CA")
+	}
+	for _, key := range []string{"ca-cert.pem", "ca-key.pem", "cert-chain.pem", "root-cert.pem"} {
+		if len(root.secretData()[key]) == 0 {
+			t.Errorf("secretData() has no %s", key)
+		}
+	}
+
+	other, err := generateRootCA(time.Now())
+	if err != nil {
+		t.Fatalf("generateRootCA() error = %v", err)
+	}
+	intermediate, intermediateKey := signCertificate(t, root, true)
+	leaf, leafKey := signCertificate(t, root, false)
+
+	tests := []struct {
+		name      string
+		bundle    string
+		wantCert  []byte
+		wantChain []byte
+		wantErr   bool
+	}{
+		{
+			name:      "root",
+			bundle:    string(root.Cert) + string(root.Key),
+			wantCert:  root.Cert,
+			wantChain: root.Cert,
+		},
+		{
+			name:      "intermediate with its chain",
+			bundle:    string(intermediate) + string(root.Cert) + string(intermediateKey),
+			wantCert:  intermediate,
+			wantChain: append(append([]byte{}, intermediate...), root.Cert...),
+		},
+		{
+			name:    "no key",
+			bundle:  string(root.Cert),
+			wantErr: true,
+		},
+		{
+			name:    "key of another certificate",
+			bundle:  string(root.Cert) + string(other.Key),
+			wantErr: true,
+		},
+		{
+			name:    "not a CA",
+			bundle:  string(leaf) + string(root.Cert) + string(leafKey),
+			wantErr: true,
+		},
+		{
+			name:    "not PEM",
+			bundle:  "apiVersion: v1\nkind: Secret\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ca, err := parseRootCA(tt.bundle)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRootCA() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !bytes.Equal(ca.Cert, tt.wantCert) || !bytes.Equal(ca.Chain, tt.wantChain) || !bytes.Equal(ca.Root, root.Cert) {
+				t.Errorf("parseRootCA() = %s, want the certificate, its chain and the root", ca.Chain)
+			}
+		})
+	}
+}
+
+// signCertificate returns a certificate signed by the CA and its key, PEM encoded
+func signCertificate(t *testing.T, ca *rootCA, isCA bool) ([]byte, []byte) {
+	t.Helper()
+	caBlock, _ := pem.Decode(ca.Cert)
+	caCert, err := x509.ParseCertificate(caBlock.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyBlock, _ := pem.Decode(ca.Key)
+	caKey, err := x509.ParsePKCS1PrivateKey(keyBlock.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(2),
+		Subject:               pkix.Name{CommonName: "signed"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+}
@@ -1,36 +1,159 @@
 package istio
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"text/template"
+	"time"
 
 	"github.com/layer5io/meshery-adapter-library/adapter"
 	"github.com/layer5io/meshery-adapter-library/status"
+	"github.com/layer5io/meshery-istio/internal/config"
 	"github.com/layer5io/meshkit/utils"
 	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	"gopkg.in/yaml.v2"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	types "k8s.io/apimachinery/pkg/types"
 )
 
-func (istio *Istio) installSampleApp(namespace string, del bool, templates []adapter.Template, kubeconfigs []string) (string, error) {
+// installSampleApp installs/uninstalls the sample app in the namespace. Once
+// installed, the deployments of the sample app are waited for to be available
+// for at most readyTimeout, no wait is done if readyTimeout is 0.
+func (istio *Istio) installSampleApp(ctx context.Context, namespace string, del bool, templates []adapter.Template, readyTimeout time.Duration, kubeconfigs []string) (string, error) {
 	st := status.Installing
 
 	if del {
 		st = status.Removing
 	}
 
+	var deployments []string
 	for _, template := range templates {
-		err := istio.applyManifest([]byte(template.String()), del, namespace, kubeconfigs)
+		contents := template.String()
+		err := istio.applyManifest(ctx, []byte(contents), del, namespace, kubeconfigs)
 		if err != nil {
 			return st, ErrSampleApp(err)
 		}
+		deployments = append(deployments, manifestDeployments(contents)...)
 	}
 
+	if del || readyTimeout <= 0 || len(deployments) == 0 {
+		return status.Installed, nil
+	}
+	notReady, err := istio.rolloutStatus(ctx, namespace, deployments, readyTimeout, kubeconfigs)
+	if err != nil {
+		if len(notReady) == 0 {
+			return st, ErrSampleApp(err)
+		}
+		return st, ErrSampleAppNotReady(notReady, err)
+	}
 	return status.Installed, nil
 }
 
-func (istio *Istio) patchWithEnvoyFilter(namespace string, del bool, app string, templates []adapter.Template, patchObject string, kubeconfigs []string) (string, error) {
+// gatewayAPIGroupVersion is the Gateway API version of the routing templates
+const gatewayAPIGroupVersion = "gateway.networking.k8s.io/v1beta1"
+
+// installGatewayAPISampleApp installs/uninstalls the sample app workloads
+// along with their routing through the Kubernetes Gateway API. The Gateway
+// API CRDs must be installed in every cluster.
+func (istio *Istio) installGatewayAPISampleApp(ctx context.Context, namespace string, del bool, templates []adapter.Template, routing adapter.Template, readyTimeout time.Duration, kubeconfigs []string) (string, error) {
+	st := status.Installing
+
+	if del {
+		st = status.Removing
+	}
+
+	if err := gatewayAPIInstalled(kubeconfigs); err != nil {
+		return st, err
+	}
+
+	// The routes are removed before the workloads they route to
+	if del {
+		if err := istio.applyManifest(ctx, []byte(routing.String()), true, namespace, kubeconfigs); err != nil {
+			return st, ErrSampleApp(err)
+		}
+		return istio.installSampleApp(ctx, namespace, true, templates, 0, kubeconfigs)
+	}
+
+	stat, err := istio.installSampleApp(ctx, namespace, false, templates, readyTimeout, kubeconfigs)
+	if err != nil {
+		return stat, err
+	}
+	if err := istio.applyManifest(ctx, []byte(routing.String()), false, namespace, kubeconfigs); err != nil {
+		return st, ErrSampleApp(err)
+	}
+	return status.Installed, nil
+}
+
+// gatewayAPIInstalled returns ErrGatewayAPINotInstalled with the clusters in
+// which the Gateway and HTTPRoute CRDs are not installed
+func gatewayAPIInstalled(kubeconfigs []string) error {
+	var mx sync.Mutex
+	var missing []string
+	err := forEachCluster(kubeconfigs, func(k8sconfig string) error {
+		mclient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return err
+		}
+		found := map[string]bool{}
+		resources, err := mclient.KubeClient.Discovery().ServerResourcesForGroupVersion(gatewayAPIGroupVersion)
+		if err == nil {
+			for _, resource := range resources.APIResources {
+				found[resource.Name] = true
+			}
+		}
+		if !found["gateways"] || !found["httproutes"] {
+			mx.Lock()
+			missing = append(missing, clusterName(k8sconfig))
+			mx.Unlock()
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		return ErrSampleApp(err)
+	}
+	if len(missing) != 0 {
+		sort.Strings(missing)
+		return ErrGatewayAPINotInstalled(missing)
+	}
+	return nil
+}
+
+// manifestDeployments returns the names of the Deployments in the manifest
+func manifestDeployments(manifest string) []string {
+	var names []string
+	decoder := yaml.NewDecoder(strings.NewReader(manifest))
+	for {
+		var object struct {
+			Kind     string `yaml:"kind"`
+			Metadata struct {
+				Name string `yaml:"name"`
+			} `yaml:"metadata"`
+		}
+		if err := decoder.Decode(&object); err != nil {
+			// The manifest is validated when it is applied, hence the
+			// documents after an invalid one are not looked for
+			return names
+		}
+		if object.Kind == "Deployment" && object.Metadata.Name != "" {
+			names = append(names, object.Metadata.Name)
+		}
+	}
+}
+
+// patchWithEnvoyFilter patches the deployment of the app and applies the
+// EnvoyFilter templates. A malformed EnvoyFilter can break the proxies of the
+// whole mesh, hence the patch and the templates are validated with istioctl of
+// the given version before they are applied, the removal is not validated.
+// With merge, the config patches of the templates are merged into the
+// existing EnvoyFilters instead of replacing them, see mergeEnvoyFilter.
+func (istio *Istio) patchWithEnvoyFilter(namespace string, del, merge bool, app, version string, templates []adapter.Template, patchObject string, kubeconfigs []string) (string, error) {
 	st := status.Deploying
 
 	if del {
@@ -41,6 +164,19 @@ func (istio *Istio) patchWithEnvoyFilter(namespace string, del bool, app string,
 	if err != nil {
 		return st, ErrEnvoyFilter(err)
 	}
+	manifests := make([]string, 0, len(templates))
+	for _, template := range templates {
+		contents, err := utils.ReadFileSource(string(template))
+		if err != nil {
+			return st, ErrEnvoyFilter(err)
+		}
+		manifests = append(manifests, contents)
+	}
+	if !del {
+		if err := istio.validateEnvoyFilter(version, jsonContents, manifests); err != nil {
+			return st, err
+		}
+	}
 	var wg sync.WaitGroup
 	var errMx sync.Mutex
 	var errs []error
@@ -63,16 +199,12 @@ func (istio *Istio) patchWithEnvoyFilter(namespace string, del bool, app string,
 				return
 			}
 
-			for _, template := range templates {
-				contents, err := utils.ReadFileSource(string(template))
-				if err != nil {
-					errMx.Lock()
-					errs = append(errs, err)
-					errMx.Unlock()
-					continue
+			for _, contents := range manifests {
+				if merge {
+					err = applyMergedEnvoyFilters([]byte(contents), del, namespace, mclient)
+				} else {
+					err = istio.applyManifestOnSingleCluster([]byte(contents), del, namespace, mclient)
 				}
-
-				err = istio.applyManifestOnSingleCluster([]byte(contents), del, namespace, mclient)
 				if err != nil {
 					errMx.Lock()
 					errs = append(errs, err)
@@ -88,27 +220,220 @@ func (istio *Istio) patchWithEnvoyFilter(namespace string, del bool, app string,
 	}
 	return st, ErrEnvoyFilter(mergeErrors(errs))
 }
-func (istio *Istio) applyPolicy(namespace string, del bool, templates []adapter.Template, kubeconfigs []string) (string, error) {
+
+// applyMergedEnvoyFilters merges the EnvoyFilters of the manifest into the
+// ones of the cluster, the other resources being applied as is
+func applyMergedEnvoyFilters(contents []byte, del bool, namespace string, mclient *mesherykube.Client) error {
+	filters, rest, err := splitEnvoyFilters(contents)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		if err := serverSideApply(context.TODO(), mclient, rest, del, namespace); err != nil {
+			return err
+		}
+	}
+	for _, filter := range filters {
+		if err := mergeEnvoyFilter(context.TODO(), mclient.DynamicKubeClient, filter, del, namespace); err != nil {
+			return fmt.Errorf("unable to merge EnvoyFilter %s: %w", filter.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// applyPolicy applies the policy templates, the templates are rendered with
+// the given values unless values is nil and the extra metadata, along with the
+// management label, is merged onto the resources they create. All the
+// templates are applied to a cluster before progress, which may be nil, is
+// called for it.
+func (istio *Istio) applyPolicy(ctx context.Context, namespace string, del bool, templates []adapter.Template, values interface{}, metadata extraMetadata, progress clusterProgress, kubeconfigs []string) (string, error) {
 	st := status.Deploying
 
 	if del {
 		st = status.Removing
 	}
+	istio.log(ctx).Debug(fmt.Sprintf("Requested policy templates: %v, delete: %v", templates, del))
 
+	manifests := make([][]byte, 0, len(templates))
 	for _, template := range templates {
 		contents, err := utils.ReadFileSource(string(template))
 		if err != nil {
 			return st, ErrApplyPolicy(err)
 		}
 
-		err = istio.applyManifest([]byte(contents), del, namespace, kubeconfigs)
-		if err != nil {
-			return st, ErrApplyPolicy(err)
+		if values != nil {
+			contents, err = renderTemplate(contents, values)
+			if err != nil {
+				return st, ErrApplyPolicy(err)
+			}
+		}
+		manifest := []byte(contents)
+		if !del {
+			manifest, err = metadata.manifest(manifest)
+			if err != nil {
+				return st, ErrApplyPolicy(err)
+			}
+			manifest, err = managedManifest(manifest)
+			if err != nil {
+				return st, ErrApplyPolicy(err)
+			}
 		}
+		manifests = append(manifests, manifest)
+	}
+
+	err := forEachCluster(kubeconfigs, func(k8sconfig string) error {
+		for _, manifest := range manifests {
+			if err := istio.applyManifestOnCluster(ctx, manifest, del, namespace, k8sconfig); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, progress)
+	if err != nil {
+		return st, ErrApplyPolicy(err)
 	}
 	return status.Deployed, nil
 }
 
+// namespaceMTLSValues are the values of the namespace scoped mTLS policy template
+type namespaceMTLSValues struct {
+	Name          string
+	Namespace     string
+	Mode          string
+	Selector      map[string]string
+	PortLevelMtls map[int32]string
+}
+
+// newNamespaceMTLSValues validates the mTLS properties of the operation.
+//
+// The port level modes are passed as a JSON object of port number to mode and
+// override the default mode of the policy on those ports only, hence the ports
+// having the default mode are left out. Istio accepts port level modes only on
+// policies with a workload selector, passed as comma separated key=value pairs.
+// Deleting a policy only needs its name, so the modes are not validated then.
+func newNamespaceMTLSValues(namespace string, props map[string]string, del bool) (*namespaceMTLSValues, error) {
+	values := &namespaceMTLSValues{
+		Name:      "namespace-mtls",
+		Namespace: namespace,
+		Mode:      strings.ToUpper(props[config.MTLSMode]),
+	}
+
+	selector, err := parseWorkloadSelector(props[config.WorkloadSelector])
+	if err != nil {
+		return nil, ErrApplyPolicy(err)
+	}
+	if len(selector) > 0 {
+		values.Selector = selector
+		values.Name = workloadName(selector) + "-mtls"
+	}
+	if del {
+		return values, nil
+	}
+	if !validMTLSModes[values.Mode] {
+		return nil, ErrInvalidMTLSMode(values.Mode)
+	}
+
+	portLevel := strings.TrimSpace(props[config.PortLevelMTLS])
+	if portLevel == "" {
+		return values, nil
+	}
+	var ports map[string]string
+	if err := json.Unmarshal([]byte(portLevel), &ports); err != nil {
+		return nil, ErrApplyPolicy(fmt.Errorf("invalid port level mTLS %q: %w", portLevel, err))
+	}
+	for p, m := range ports {
+		port, err := strconv.ParseInt(p, 10, 32)
+		if err != nil || port <= 0 {
+			return nil, ErrApplyPolicy(fmt.Errorf("port %q is not a positive integer", p))
+		}
+		mode := strings.ToUpper(m)
+		if !validMTLSModes[mode] {
+			return nil, ErrInvalidMTLSMode(mode)
+		}
+		if mode == values.Mode {
+			continue
+		}
+		if values.PortLevelMtls == nil {
+			values.PortLevelMtls = make(map[int32]string)
+		}
+		values.PortLevelMtls[int32(port)] = mode
+	}
+	if len(values.PortLevelMtls) > 0 && len(values.Selector) == 0 {
+		return nil, ErrApplyPolicy(fmt.Errorf("port level mTLS requires a workload selector"))
+	}
+	return values, nil
+}
+
+// parseWorkloadSelector parses the comma separated key=value pairs of a
+// workload selector, nil is returned for an empty selector
+func parseWorkloadSelector(selector string) (map[string]string, error) {
+	if strings.TrimSpace(selector) == "" {
+		return nil, nil
+	}
+	labels := make(map[string]string)
+	for _, pair := range strings.Split(selector, ",") {
+		kv := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("invalid workload selector %q", pair)
+		}
+		labels[kv[0]] = kv[1]
+	}
+	return labels, nil
+}
+
+// workloadName names the workloads matched by the selector after their app
+// label, or after all the label values when there is none, so that policies
+// of different workloads in the same namespace do not replace each other.
+func workloadName(selector map[string]string) string {
+	parts := []string{selector["app"]}
+	if parts[0] == "" {
+		parts = parts[:0]
+		for _, value := range selector {
+			parts = append(parts, value)
+		}
+		sort.Strings(parts)
+	}
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '-'
+	}, strings.ToLower(strings.Join(parts, "-")))
+	// leave room for the -mtls suffix within the 63 characters of a name
+	if len(name) > 58 {
+		name = name[:58]
+	}
+	if name = strings.Trim(name, "-"); name == "" {
+		return "workload"
+	}
+	return name
+}
+
+func (v *namespaceMTLSValues) String() string {
+	if len(v.PortLevelMtls) == 0 {
+		return v.Mode
+	}
+	ports := make([]string, 0, len(v.PortLevelMtls))
+	for port, mode := range v.PortLevelMtls {
+		ports = append(ports, fmt.Sprintf("%d=%s", port, mode))
+	}
+	sort.Strings(ports)
+	return fmt.Sprintf("%s (%s)", v.Mode, strings.Join(ports, ", "))
+}
+
+// renderTemplate executes the manifest template with the given values
+func renderTemplate(contents string, values interface{}) (string, error) {
+	tmpl, err := template.New("manifest").Option("missingkey=error").Parse(contents)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, values); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
 // LoadToMesh is used to mark deployment for automatic sidecar injection (or not)
 func (istio *Istio) LoadToMesh(namespace string, service string, remove bool, kubeconfigs []string) error {
 	var wg sync.WaitGroup
@@ -159,8 +484,10 @@ func (istio *Istio) LoadToMesh(namespace string, service string, remove bool, ku
 	return mergeErrors(errs)
 }
 
-// LoadNamespaceToMesh is used to mark namespaces for automatic sidecar injection (or not)
-func (istio *Istio) LoadNamespaceToMesh(namespace string, remove bool, kubeconfigs []string) error {
+// LoadNamespaceToMesh is used to mark namespaces for automatic sidecar injection (or not).
+// If a revision is passed the namespace is bound to that control plane revision
+// using the istio.io/rev label instead of the istio-injection label
+func (istio *Istio) LoadNamespaceToMesh(namespace string, remove bool, revision string, kubeconfigs []string) error {
 	var wg sync.WaitGroup
 	var errMx sync.Mutex
 	var errs []error
@@ -188,10 +515,17 @@ func (istio *Istio) LoadNamespaceToMesh(namespace string, remove bool, kubeconfi
 			if ns.ObjectMeta.Labels == nil {
 				ns.ObjectMeta.Labels = map[string]string{}
 			}
-			ns.ObjectMeta.Labels["istio-injection"] = "enabled"
+			if revision != "" {
+				// istio-injection takes precedence over istio.io/rev
+				delete(ns.ObjectMeta.Labels, "istio-injection")
+				ns.ObjectMeta.Labels["istio.io/rev"] = revision
+			} else {
+				ns.ObjectMeta.Labels["istio-injection"] = "enabled"
+			}
 
 			if remove {
 				delete(ns.ObjectMeta.Labels, "istio-injection")
+				delete(ns.ObjectMeta.Labels, "istio.io/rev")
 			}
 
 			_, err = kclient.KubeClient.CoreV1().Namespaces().Update(context.TODO(), ns, metav1.UpdateOptions{})
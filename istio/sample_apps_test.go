@@ -0,0 +1,252 @@
+package istio
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/layer5io/meshery-istio/internal/config"
+	"github.com/layer5io/meshkit/errors"
+	"gopkg.in/yaml.v2"
+)
+
+func TestNamespaceMTLSPolicy(t *testing.T) {
+	tmpl, err := os.ReadFile("../templates/policies/namespace_mtls.yaml")
+	if err != nil {
+		t.Fatalf("unable to read the policy template: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		props         map[string]string
+		del           bool
+		wantName      string
+		wantMode      string
+		wantPortLevel map[int]string
+		wantErr       bool
+	}{
+		{
+			name:     "namespace wide mode",
+			props:    map[string]string{config.MTLSMode: "permissive"},
+			wantName: "namespace-mtls",
+			wantMode: "PERMISSIVE",
+		},
+		{
+			name: "port level modes merged with the default mode",
+			props: map[string]string{
+				config.MTLSMode:         "STRICT",
+				config.PortLevelMTLS:    `{"8080": "DISABLE", "9080": "STRICT"}`,
+				config.WorkloadSelector: "app=reviews",
+			},
+			wantName:      "reviews-mtls",
+			wantMode:      "STRICT",
+			wantPortLevel: map[int]string{8080: "DISABLE"},
+		},
+		{
+			name: "workload named after the selector values",
+			props: map[string]string{
+				config.MTLSMode:         "DISABLE",
+				config.WorkloadSelector: "version=v1,service=Ratings",
+			},
+			wantName: "ratings-v1-mtls",
+			wantMode: "DISABLE",
+		},
+		{
+			name:     "delete does not need a mode",
+			props:    map[string]string{config.WorkloadSelector: "app=reviews"},
+			del:      true,
+			wantName: "reviews-mtls",
+		},
+		{
+			name:    "invalid mode",
+			props:   map[string]string{config.MTLSMode: "OPTIONAL"},
+			wantErr: true,
+		},
+		{
+			name: "non positive port",
+			props: map[string]string{
+				config.MTLSMode:         "STRICT",
+				config.PortLevelMTLS:    `{"-1": "DISABLE"}`,
+				config.WorkloadSelector: "app=reviews",
+			},
+			wantErr: true,
+		},
+		{
+			name: "port level modes without selector",
+			props: map[string]string{
+				config.MTLSMode:      "STRICT",
+				config.PortLevelMTLS: `{"8080": "DISABLE"}`,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := newNamespaceMTLSValues("bookinfo", tt.props, tt.del)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newNamespaceMTLSValues() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			rendered, err := renderTemplate(string(tmpl), values)
+			if err != nil {
+				t.Fatalf("renderTemplate() error = %v", err)
+			}
+			var pa struct {
+				Metadata struct {
+					Name      string `yaml:"name"`
+					Namespace string `yaml:"namespace"`
+				} `yaml:"metadata"`
+				Spec struct {
+					Mtls struct {
+						Mode string `yaml:"mode"`
+					} `yaml:"mtls"`
+					PortLevelMtls map[int]struct {
+						Mode string `yaml:"mode"`
+					} `yaml:"portLevelMtls"`
+				} `yaml:"spec"`
+			}
+			if err := yaml.Unmarshal([]byte(rendered), &pa); err != nil {
+				t.Fatalf("renderTemplate() generated invalid YAML: %v\n%s", err, rendered)
+			}
+			if pa.Metadata.Namespace != "bookinfo" || pa.Spec.Mtls.Mode != tt.wantMode {
+				t.Errorf("renderTemplate() namespace/mode = %s/%s, want bookinfo/%s", pa.Metadata.Namespace, pa.Spec.Mtls.Mode, tt.wantMode)
+			}
+			if pa.Metadata.Name != tt.wantName {
+				t.Errorf("renderTemplate() name = %s, want %s", pa.Metadata.Name, tt.wantName)
+			}
+			var gotPortLevel map[int]string
+			for port, level := range pa.Spec.PortLevelMtls {
+				if gotPortLevel == nil {
+					gotPortLevel = make(map[int]string)
+				}
+				gotPortLevel[port] = level.Mode
+			}
+			if !reflect.DeepEqual(gotPortLevel, tt.wantPortLevel) {
+				t.Errorf("renderTemplate() portLevelMtls = %v, want %v", gotPortLevel, tt.wantPortLevel)
+			}
+		})
+	}
+}
+
+func TestNamespaceMTLSMode(t *testing.T) {
+	tmpl, err := os.ReadFile("../templates/policies/namespace_mtls.yaml")
+	if err != nil {
+		t.Fatalf("unable to read the policy template: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		props    map[string]string
+		wantMode string
+		wantErr  bool
+	}{
+		{name: "strict", props: map[string]string{config.MTLSMode: "STRICT"}, wantMode: "STRICT"},
+		{name: "permissive", props: map[string]string{config.MTLSMode: "PERMISSIVE"}, wantMode: "PERMISSIVE"},
+		{name: "disable", props: map[string]string{config.MTLSMode: "DISABLE"}, wantMode: "DISABLE"},
+		{name: "lowercase", props: map[string]string{config.MTLSMode: "strict"}, wantMode: "STRICT"},
+		{name: "mixed case", props: map[string]string{config.MTLSMode: "Disable"}, wantMode: "DISABLE"},
+		{name: "garbage", props: map[string]string{config.MTLSMode: "mutual"}, wantErr: true},
+		{name: "unset", props: map[string]string{config.MTLSMode: "UNSET"}, wantErr: true},
+		{name: "no mode", props: map[string]string{}, wantErr: true},
+		{
+			name: "garbage port level mode",
+			props: map[string]string{
+				config.MTLSMode:         "STRICT",
+				config.PortLevelMTLS:    `{"8080": "sometimes"}`,
+				config.WorkloadSelector: "app=reviews",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := newNamespaceMTLSValues("bookinfo", tt.props, false)
+			if tt.wantErr {
+				if errors.GetCode(err) != ErrInvalidMTLSModeCode {
+					t.Fatalf("newNamespaceMTLSValues() error = %v, want %s", err, ErrInvalidMTLSModeCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("newNamespaceMTLSValues() error = %v", err)
+			}
+			rendered, err := renderTemplate(string(tmpl), values)
+			if err != nil {
+				t.Fatalf("renderTemplate() error = %v", err)
+			}
+			var pa struct {
+				Kind     string `yaml:"kind"`
+				Metadata struct {
+					Namespace string `yaml:"namespace"`
+				} `yaml:"metadata"`
+				Spec struct {
+					Mtls struct {
+						Mode string `yaml:"mode"`
+					} `yaml:"mtls"`
+				} `yaml:"spec"`
+			}
+			if err := yaml.Unmarshal([]byte(rendered), &pa); err != nil {
+				t.Fatalf("renderTemplate() generated invalid YAML: %v\n%s", err, rendered)
+			}
+			if pa.Kind != "PeerAuthentication" || pa.Metadata.Namespace != "bookinfo" || pa.Spec.Mtls.Mode != tt.wantMode {
+				t.Errorf("renderTemplate() kind/namespace/mode = %s/%s/%s, want PeerAuthentication/bookinfo/%s", pa.Kind, pa.Metadata.Namespace, pa.Spec.Mtls.Mode, tt.wantMode)
+			}
+		})
+	}
+}
+
+func TestManifestDeployments(t *testing.T) {
+	manifest := `apiVersion: v1
+kind: Service
+metadata:
+  name: productpage
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: productpage-v1
+---
+# reviews
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: reviews-v1
+`
+	got := manifestDeployments(manifest)
+	want := []string{"productpage-v1", "reviews-v1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("manifestDeployments() = %v, want %v", got, want)
+	}
+}
+
+func TestGatewayAPIRoutingTemplate(t *testing.T) {
+	contents, err := os.ReadFile("../templates/bookinfo/gateway-api.yaml")
+	if err != nil {
+		t.Fatalf("unable to read the routing template: %v", err)
+	}
+
+	kinds := map[string]bool{}
+	decoder := yaml.NewDecoder(bytes.NewReader(contents))
+	for {
+		var object struct {
+			APIVersion string `yaml:"apiVersion"`
+			Kind       string `yaml:"kind"`
+		}
+		if err := decoder.Decode(&object); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("invalid routing template: %v", err)
+		}
+		if object.APIVersion != gatewayAPIGroupVersion {
+			t.Errorf("%s uses %s, want %s", object.Kind, object.APIVersion, gatewayAPIGroupVersion)
+		}
+		kinds[object.Kind] = true
+	}
+	if !kinds["Gateway"] || !kinds["HTTPRoute"] {
+		t.Errorf("routing template kinds = %v, want Gateway and HTTPRoute", kinds)
+	}
+}
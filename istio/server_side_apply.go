@@ -0,0 +1,117 @@
+package istio
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
+)
+
+// fieldManager is the field manager the adapter applies the resources with,
+// the fields it sets being owned by it
+const fieldManager = "meshery-istio"
+
+// namespacesResource is the resource of the namespaces the resources are
+// applied to, created when missing
+var namespacesResource = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+
+// serverSideApply applies every resource of the manifest to the cluster with
+// server-side apply, or deletes them. Unlike the client-side apply, the
+// resources don't carry their last applied configuration and the fields the
+// manifest leaves out are kept. The fields another field manager owns are not
+// taken over, the conflicts being returned as ErrFieldConflict.
+func serverSideApply(ctx context.Context, mclient *mesherykube.Client, contents []byte, del bool, namespace string) error {
+	return serverSideApplyOnSingleCluster(ctx, mclient.KubeClient.Discovery(), mclient.DynamicKubeClient, contents, del, namespace)
+}
+
+// serverSideApplyOnSingleCluster applies the resources of the manifest in
+// order, the namespace, when set, overriding the one of the namespaced
+// resources. The namespaces of the applied resources are created if missing.
+func serverSideApplyOnSingleCluster(ctx context.Context, disc discovery.DiscoveryInterface, dyn dynamic.Interface, contents []byte, del bool, namespace string) error {
+	groupResources, err := restmapper.GetAPIGroupResources(disc)
+	if err != nil {
+		return err
+	}
+	mapper := restmapper.NewDiscoveryRESTMapper(groupResources)
+
+	for _, manifest := range strings.Split(string(contents), "\n---\n") {
+		if strings.TrimSpace(manifest) == "" {
+			continue
+		}
+		_, obj, err := mesherykube.GetObjectFromManifest(manifest)
+		if err != nil {
+			// The documents holding only comments have no kind
+			if obj.GetKind() == "" {
+				continue
+			}
+			return err
+		}
+		name := fmt.Sprintf("%s/%s", obj.GetKind(), obj.GetName())
+		gvk := obj.GroupVersionKind()
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+
+		var resource dynamic.ResourceInterface = dyn.Resource(mapping.Resource)
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			ns := obj.GetNamespace()
+			if namespace != "" {
+				ns = namespace
+			}
+			if ns == "" {
+				ns = metav1.NamespaceDefault
+			}
+			obj.SetNamespace(ns)
+			resource = dyn.Resource(mapping.Resource).Namespace(ns)
+			if !del {
+				if err := ensureNamespace(ctx, dyn, ns); err != nil {
+					return err
+				}
+			}
+		}
+
+		if del {
+			err := resource.Delete(ctx, obj.GetName(), metav1.DeleteOptions{})
+			if err != nil && !kerrors.IsNotFound(err) {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			continue
+		}
+		_, err = resource.Apply(ctx, obj.GetName(), obj, metav1.ApplyOptions{FieldManager: fieldManager})
+		if kerrors.IsConflict(err) {
+			return ErrFieldConflict(name, err)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// ensureNamespace creates the namespace if it doesn't exist, an existing
+// namespace being left untouched
+func ensureNamespace(ctx context.Context, dyn dynamic.Interface, namespace string) error {
+	_, err := dyn.Resource(namespacesResource).Get(ctx, namespace, metav1.GetOptions{})
+	if !kerrors.IsNotFound(err) {
+		return err
+	}
+	ns := &unstructured.Unstructured{}
+	ns.SetAPIVersion("v1")
+	ns.SetKind("Namespace")
+	ns.SetName(namespace)
+	_, err = dyn.Resource(namespacesResource).Create(ctx, ns, metav1.CreateOptions{FieldManager: fieldManager})
+	if kerrors.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
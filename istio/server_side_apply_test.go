@@ -0,0 +1,99 @@
+package istio
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/layer5io/meshkit/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestServerSideApplyOnSingleCluster(t *testing.T) {
+	disc := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{}}
+	disc.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "configmaps", Kind: "ConfigMap", Namespaced: true},
+				{Name: "namespaces", Kind: "Namespace"},
+			},
+		},
+		{
+			GroupVersion: "networking.istio.io/v1alpha3",
+			APIResources: []metav1.APIResource{{Name: "envoyfilters", Kind: "EnvoyFilter", Namespaced: true}},
+		},
+	}
+	manifest := []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: ignored
+---
+# only a comment
+---
+apiVersion: networking.istio.io/v1alpha3
+kind: EnvoyFilter
+metadata:
+  name: owned
+`)
+
+	t.Run("apply", func(t *testing.T) {
+		dyn := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(),
+			&unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "v1", "kind": "Namespace", "metadata": map[string]interface{}{"name": "default"},
+			}},
+		)
+		var applied []string
+		dyn.PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			patch := action.(k8stesting.PatchAction)
+			if patch.GetPatchType() != types.ApplyPatchType {
+				t.Errorf("patch type of %s = %s, want %s", patch.GetName(), patch.GetPatchType(), types.ApplyPatchType)
+			}
+			applied = append(applied, patch.GetNamespace()+"/"+patch.GetResource().Resource+"/"+patch.GetName())
+			return true, &unstructured.Unstructured{}, nil
+		})
+
+		if err := serverSideApplyOnSingleCluster(context.TODO(), disc, dyn, manifest, false, "mesh"); err != nil {
+			t.Fatalf("serverSideApplyOnSingleCluster() error = %v", err)
+		}
+		want := []string{"mesh/configmaps/settings", "mesh/envoyfilters/owned"}
+		if len(applied) != len(want) || applied[0] != want[0] || applied[1] != want[1] {
+			t.Errorf("serverSideApplyOnSingleCluster() applied %v, want %v", applied, want)
+		}
+		if _, err := dyn.Resource(namespacesResource).Get(context.TODO(), "mesh", metav1.GetOptions{}); err != nil {
+			t.Errorf("namespace mesh not created: %v", err)
+		}
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		dyn := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+		dyn.PrependReactor("patch", "envoyfilters", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, kerrors.NewConflict(schema.GroupResource{Group: "networking.istio.io", Resource: "envoyfilters"}, "owned",
+				fmt.Errorf(`Apply failed with 1 conflict: conflict with "kubectl" using networking.istio.io/v1alpha3: .spec.priority`))
+		})
+		dyn.PrependReactor("patch", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, &unstructured.Unstructured{}, nil
+		})
+
+		err := serverSideApplyOnSingleCluster(context.TODO(), disc, dyn, manifest, false, "")
+		if errors.GetCode(err) != ErrFieldConflictCode {
+			t.Errorf("serverSideApplyOnSingleCluster() error = %v, want code %s", err, ErrFieldConflictCode)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		dyn := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+		if err := serverSideApplyOnSingleCluster(context.TODO(), disc, dyn, manifest, true, "mesh"); err != nil {
+			t.Errorf("serverSideApplyOnSingleCluster() error = %v, want the missing resources ignored", err)
+		}
+	})
+}
@@ -0,0 +1,133 @@
+package istio
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/layer5io/meshery-istio/internal/config"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// serviceEntryResolutions are the ways the proxies resolve the addresses of
+// the hosts of a ServiceEntry
+var serviceEntryResolutions = map[string]bool{"NONE": true, "STATIC": true, "DNS": true}
+
+// serviceEntryLocations tell whether the hosts of a ServiceEntry are part of
+// the mesh
+var serviceEntryLocations = map[string]bool{"MESH_EXTERNAL": true, "MESH_INTERNAL": true}
+
+// serviceEntryProtocols are the protocols of the ports of a ServiceEntry
+var serviceEntryProtocols = map[string]bool{"HTTP": true, "HTTPS": true, "HTTP2": true, "GRPC": true, "TLS": true, "TCP": true, "MONGO": true}
+
+// serviceEntryPort is a port of the hosts of a ServiceEntry
+type serviceEntryPort struct {
+	Name     string
+	Number   int
+	Protocol string
+}
+
+// serviceEntryValues are the values of the ServiceEntry template
+type serviceEntryValues struct {
+	Name       string
+	Namespace  string
+	Hosts      []string
+	Ports      []serviceEntryPort
+	Resolution string
+	Location   string
+
+	// Endpoints are the addresses of the hosts, only used by the STATIC
+	// resolution
+	Endpoints []string
+}
+
+// newServiceEntryValues validates the ServiceEntry properties of the
+// operation. The ServiceEntry is named after its first host unless its name
+// is set, deleting it only needs the name or the hosts.
+func newServiceEntryValues(namespace string, props map[string]string, del bool) (*serviceEntryValues, error) {
+	values := &serviceEntryValues{
+		Namespace:  namespace,
+		Name:       strings.TrimSpace(props[config.ServiceEntryName]),
+		Hosts:      splitList(props[config.ServiceEntryHosts]),
+		Resolution: strings.ToUpper(strings.TrimSpace(props[config.ServiceEntryResolution])),
+		Location:   strings.ToUpper(strings.TrimSpace(props[config.ServiceEntryLocation])),
+		Endpoints:  splitList(props[config.ServiceEntryEndpoints]),
+	}
+	if len(values.Hosts) == 0 && !(del && values.Name != "") {
+		return nil, ErrInvalidServiceEntry(fmt.Errorf("at least one host is required"))
+	}
+	for _, host := range values.Hosts {
+		if errs := validation.IsDNS1123Subdomain(strings.TrimPrefix(host, "*.")); len(errs) > 0 {
+			return nil, ErrInvalidServiceEntry(fmt.Errorf("invalid host %q: %s", host, strings.Join(errs, ", ")))
+		}
+	}
+	if values.Name == "" {
+		values.Name = strings.ReplaceAll(strings.TrimPrefix(values.Hosts[0], "*."), ".", "-")
+		if len(values.Name) > validation.DNS1123LabelMaxLength {
+			values.Name = strings.Trim(values.Name[:validation.DNS1123LabelMaxLength], "-")
+		}
+	}
+	if errs := validation.IsDNS1123Subdomain(values.Name); len(errs) > 0 {
+		return nil, ErrInvalidServiceEntry(fmt.Errorf("invalid name %q: %s", values.Name, strings.Join(errs, ", ")))
+	}
+	if del {
+		return values, nil
+	}
+
+	if values.Resolution == "" {
+		values.Resolution = "DNS"
+	}
+	if !serviceEntryResolutions[values.Resolution] {
+		return nil, ErrInvalidServiceEntry(fmt.Errorf("resolution %q is not one of NONE, STATIC and DNS", values.Resolution))
+	}
+	if values.Location == "" {
+		values.Location = "MESH_EXTERNAL"
+	}
+	if !serviceEntryLocations[values.Location] {
+		return nil, ErrInvalidServiceEntry(fmt.Errorf("location %q is not one of MESH_EXTERNAL and MESH_INTERNAL", values.Location))
+	}
+	if values.Resolution == "STATIC" && len(values.Endpoints) == 0 {
+		return nil, ErrInvalidServiceEntry(fmt.Errorf("the STATIC resolution requires the addresses of the endpoints"))
+	}
+	for _, endpoint := range values.Endpoints {
+		if net.ParseIP(endpoint) == nil {
+			return nil, ErrInvalidServiceEntry(fmt.Errorf("endpoint %q is not an IP address", endpoint))
+		}
+	}
+
+	ports := splitList(props[config.ServiceEntryPorts])
+	if len(ports) == 0 {
+		return nil, ErrInvalidServiceEntry(fmt.Errorf("at least one port is required"))
+	}
+	for _, p := range ports {
+		number, protocol, _ := strings.Cut(p, "/")
+		n, err := parsePort(number, 0)
+		if err != nil || n == 0 {
+			return nil, ErrInvalidServiceEntry(fmt.Errorf("invalid port %q", p))
+		}
+		protocol = strings.ToUpper(strings.TrimSpace(protocol))
+		if protocol == "" {
+			protocol = "TCP"
+		}
+		if !serviceEntryProtocols[protocol] {
+			return nil, ErrInvalidServiceEntry(fmt.Errorf("invalid protocol of port %q", p))
+		}
+		values.Ports = append(values.Ports, serviceEntryPort{
+			Name:     fmt.Sprintf("%s-%d", strings.ToLower(protocol), n),
+			Number:   n,
+			Protocol: protocol,
+		})
+	}
+	return values, nil
+}
+
+// splitList splits a comma separated list, leaving the blank items out
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,139 @@
+package istio
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/layer5io/meshery-istio/internal/config"
+	"github.com/layer5io/meshkit/errors"
+	"gopkg.in/yaml.v2"
+)
+
+func TestServiceEntry(t *testing.T) {
+	tmpl, err := os.ReadFile("../templates/routing/service-entry.yaml")
+	if err != nil {
+		t.Fatalf("unable to read the ServiceEntry template: %v", err)
+	}
+
+	type port struct {
+		Number   int    `yaml:"number"`
+		Name     string `yaml:"name"`
+		Protocol string `yaml:"protocol"`
+	}
+	tests := []struct {
+		name           string
+		props          map[string]string
+		del            bool
+		wantName       string
+		wantPorts      []port
+		wantResolution string
+		wantEndpoints  int
+		wantErr        bool
+	}{
+		{
+			name:           "defaults",
+			props:          map[string]string{config.ServiceEntryHosts: "api.stripe.com, *.stripe.com", config.ServiceEntryPorts: "443/tls,80"},
+			wantName:       "api-stripe-com",
+			wantPorts:      []port{{443, "tls-443", "TLS"}, {80, "tcp-80", "TCP"}},
+			wantResolution: "DNS",
+		},
+		{
+			name: "static",
+			props: map[string]string{
+				config.ServiceEntryName:       "payments-db",
+				config.ServiceEntryHosts:      "db.payments.internal",
+				config.ServiceEntryPorts:      "5432/TCP",
+				config.ServiceEntryResolution: "STATIC",
+				config.ServiceEntryEndpoints:  "10.0.0.12, 10.0.0.13",
+			},
+			wantName:       "payments-db",
+			wantPorts:      []port{{5432, "tcp-5432", "TCP"}},
+			wantResolution: "STATIC",
+			wantEndpoints:  2,
+		},
+		{
+			name:     "delete by host",
+			props:    map[string]string{config.ServiceEntryHosts: "httpbin.org", config.ServiceEntryResolution: "ROUND_ROBIN"},
+			del:      true,
+			wantName: "httpbin-org",
+		},
+		{
+			name:     "delete by name",
+			props:    map[string]string{config.ServiceEntryName: "payments-db"},
+			del:      true,
+			wantName: "payments-db",
+		},
+		{
+			name:    "no host",
+			props:   map[string]string{config.ServiceEntryHosts: " , ", config.ServiceEntryPorts: "443/TLS"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid resolution",
+			props:   map[string]string{config.ServiceEntryHosts: "httpbin.org", config.ServiceEntryPorts: "443/TLS", config.ServiceEntryResolution: "ROUND_ROBIN"},
+			wantErr: true,
+		},
+		{
+			name:    "static without endpoints",
+			props:   map[string]string{config.ServiceEntryHosts: "httpbin.org", config.ServiceEntryPorts: "443/TLS", config.ServiceEntryResolution: "STATIC"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid port",
+			props:   map[string]string{config.ServiceEntryHosts: "httpbin.org", config.ServiceEntryPorts: "443/QUIC"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := newServiceEntryValues("payments", tt.props, tt.del)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newServiceEntryValues() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if errors.GetCode(err) != ErrInvalidServiceEntryCode {
+					t.Errorf("newServiceEntryValues() error code = %s, want %s", errors.GetCode(err), ErrInvalidServiceEntryCode)
+				}
+				return
+			}
+			if values.Name != tt.wantName {
+				t.Errorf("newServiceEntryValues() name = %s, want %s", values.Name, tt.wantName)
+			}
+			if tt.del {
+				return
+			}
+			rendered, err := renderTemplate(string(tmpl), values)
+			if err != nil {
+				t.Fatalf("renderTemplate() error = %v", err)
+			}
+			var entry struct {
+				Metadata struct {
+					Name      string `yaml:"name"`
+					Namespace string `yaml:"namespace"`
+				} `yaml:"metadata"`
+				Spec struct {
+					Hosts      []string `yaml:"hosts"`
+					Ports      []port   `yaml:"ports"`
+					Resolution string   `yaml:"resolution"`
+					Location   string   `yaml:"location"`
+					Endpoints  []struct {
+						Address string `yaml:"address"`
+					} `yaml:"endpoints"`
+				} `yaml:"spec"`
+			}
+			if err := yaml.Unmarshal([]byte(rendered), &entry); err != nil {
+				t.Fatalf("renderTemplate() generated invalid YAML: %v\n%s", err, rendered)
+			}
+			if entry.Metadata.Name != tt.wantName || entry.Metadata.Namespace != "payments" {
+				t.Errorf("renderTemplate() name/namespace = %s/%s, want %s/payments", entry.Metadata.Name, entry.Metadata.Namespace, tt.wantName)
+			}
+			if !reflect.DeepEqual(entry.Spec.Hosts, values.Hosts) || !reflect.DeepEqual(entry.Spec.Ports, tt.wantPorts) {
+				t.Errorf("renderTemplate() hosts/ports = %v/%v, want %v/%v", entry.Spec.Hosts, entry.Spec.Ports, values.Hosts, tt.wantPorts)
+			}
+			if entry.Spec.Resolution != tt.wantResolution || entry.Spec.Location != "MESH_EXTERNAL" || len(entry.Spec.Endpoints) != tt.wantEndpoints {
+				t.Errorf("renderTemplate() = %s, want %s resolution with %d endpoints", rendered, tt.wantResolution, tt.wantEndpoints)
+			}
+		})
+	}
+}
@@ -0,0 +1,424 @@
+package istio
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/layer5io/learn-layer5/smi-conformance/conformance"
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/layer5io/meshery-adapter-library/meshes"
+	"github.com/layer5io/meshkit/utils"
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	smp "github.com/layer5io/service-mesh-performance/spec"
+)
+
+const (
+	// smiManifestRef is the git ref of the SMI conformance manifest of the
+	// operation, which the pinned version replaces
+	smiManifestRef = "/master/"
+
+	// smiDefaultVersion runs the latest SMI conformance tests
+	smiDefaultVersion = "master"
+
+	// smiNamespace is the namespace the conformance tool is installed in
+	smiNamespace = "meshery"
+
+	// smiTestTimeout bounds the SMI conformance run when its operation
+	// timeout is unset
+	smiTestTimeout = 15 * time.Minute
+
+	// smiCleanupTimeout bounds the removal of the conformance tool of a run
+	// which timed out
+	smiCleanupTimeout = 2 * time.Minute
+
+	// smiToolStartDelay leaves the conformance tool the time to create its
+	// resources before it's connected to
+	smiToolStartDelay = 20 * time.Second
+
+	// smiRunAttempts bounds the attempts to reach the conformance tool, one
+	// per second
+	smiRunAttempts = 100
+)
+
+// smiRunOptions are the options of the SMI conformance run of a cluster
+type smiRunOptions struct {
+	Manifest    string
+	MeshVersion string
+	Labels      map[string]string
+}
+
+// runSMIConformance runs the SMI conformance tests on a single cluster and
+// returns their results, see runSMIConformanceOnCluster
+var runSMIConformance = func(ctx context.Context, opts smiRunOptions, k8sconfig string) ([]*adapter.Detail, error) {
+	return runSMIConformanceOnCluster(ctx, opts, k8sconfig)
+}
+
+// removeSMIConformanceTool removes the conformance tool of the manifest from
+// every cluster
+var removeSMIConformanceTool = func(ctx context.Context, manifest string, kubeconfigs []string) error {
+	contents, err := utils.ReadFileSource(manifest)
+	if err != nil {
+		return err
+	}
+	return forEachCluster(kubeconfigs, func(k8sconfig string) error {
+		mclient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return err
+		}
+		return serverSideApply(ctx, mclient, []byte(contents), true, smiNamespace)
+	}, nil)
+}
+
+// smiVersionRegex matches the git refs the SMI conformance tests can be
+// pinned to, a tag such as v0.1.0 or a branch
+var smiVersionRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/-]*$`)
+
+// smiFileNameUnsafe matches the characters of a version left out of the file
+// name of a report
+var smiFileNameUnsafe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// SMITestCase is the result of a single SMI conformance test
+type SMITestCase struct {
+	Name          string `json:"name"`
+	Specification string `json:"specification"`
+	Capability    string `json:"capability"`
+	Result        string `json:"result"`
+	Details       string `json:"details,omitempty"`
+}
+
+// SMIReport is the report of an SMI conformance run, meant to be published
+// along the compatibility matrix of the adapter
+type SMIReport struct {
+	MeshVersion string `json:"meshVersion"`
+	TestVersion string `json:"testVersion"`
+
+	// KubernetesVersions are the distinct versions of the clusters the
+	// tests ran on, set when the report is written to a file
+	KubernetesVersions []string `json:"kubernetesVersions,omitempty"`
+
+	Date   string        `json:"date"`
+	Passed int           `json:"passed"`
+	Total  int           `json:"total"`
+	Tests  []SMITestCase `json:"tests"`
+}
+
+func (r *SMIReport) String() string {
+	return fmt.Sprintf("%d of %d SMI conformance tests %s passed", r.Passed, r.Total, r.TestVersion)
+}
+
+// smiManifest returns the manifest of the SMI conformance tests of the
+// version, the manifests of the operation are fetched from the master branch
+func smiManifest(manifest, version string) (string, error) {
+	version = strings.TrimSpace(version)
+	if version == "" || version == smiDefaultVersion {
+		return manifest, nil
+	}
+	if !smiVersionRegex.MatchString(version) || strings.Contains(version, "..") {
+		return "", ErrInvalidSMITestVersion(fmt.Errorf("%q is not a tag or a branch", version))
+	}
+	if !strings.Contains(manifest, smiManifestRef) {
+		return "", ErrInvalidSMITestVersion(fmt.Errorf("the manifest %s is not versioned", manifest))
+	}
+	return strings.Replace(manifest, smiManifestRef, "/"+version+"/", 1), nil
+}
+
+// runSMITest runs the SMI conformance tests of the version on the clusters
+// and returns their report. The report holds the tests which ran even if the
+// run failed.
+//
+// The run is stopped once the timeout is over, smiTestTimeout when 0, or
+// when the context is done. The conformance tool is then removed from the
+// clusters for the run to be retried, and the report holds the tests of the
+// clusters done by then, the conformance tool reporting the tests of a
+// cluster once they all ran.
+func (istio *Istio) runSMITest(ctx context.Context, operationID, manifest, version string, timeout time.Duration, kubeConfigs []string) (*SMIReport, adapter.Response, error) {
+	manifest, err := smiManifest(manifest, version)
+	if err != nil {
+		return nil, adapter.Response{}, err
+	}
+	if timeout <= 0 {
+		timeout = smiTestTimeout
+	}
+	ctx, cancel := withOperationTimeout(ctx, timeout)
+	defer cancel()
+
+	resp := adapter.Response{ID: operationID, Date: time.Now().Format(time.RFC3339), MeshVersion: istio.GetVersion()}
+	opts := smiRunOptions{
+		Manifest:    manifest,
+		MeshVersion: resp.MeshVersion,
+		Labels: map[string]string{
+			"istio-injection": "enabled",
+		},
+	}
+	var mx sync.Mutex
+	run := runSMIConformance
+	err = forEachCluster(kubeConfigs, func(k8sconfig string) error {
+		details, err := run(ctx, opts, k8sconfig)
+		mx.Lock()
+		resp.MoreDetails = append(resp.MoreDetails, details...)
+		mx.Unlock()
+		return err
+	}, nil)
+	report := smiReport(resp, version)
+	resp.CasesPassed = strconv.Itoa(report.Passed)
+	if ctx.Err() == nil {
+		if err != nil {
+			return report, resp, adapter.ErrRunSmi(err)
+		}
+		return report, resp, nil
+	}
+
+	err = nil
+	cleanupCtx, cleanupCancel := context.WithTimeout(context.Background(), smiCleanupTimeout)
+	defer cleanupCancel()
+	if cerr := removeSMIConformanceTool(cleanupCtx, manifest, kubeConfigs); cerr != nil {
+		istio.log(ctx).Info(fmt.Sprintf("Unable to remove the SMI conformance tool: %v", cerr))
+		err = fmt.Errorf("the SMI conformance tool is left in %s: %w", smiNamespace, cerr)
+	}
+	if ctx.Err() != context.DeadlineExceeded {
+		return report, resp, interrupted(ctx)
+	}
+	timedOut := fmt.Errorf("the SMI conformance tests didn't complete within %s, %d tests ran", timeout, report.Total)
+	if err != nil {
+		timedOut = fmt.Errorf("%s, %w", timedOut, err)
+	}
+	return report, resp, ErrSMITestTimeout(timedOut)
+}
+
+// runSMIConformanceOnCluster installs the conformance tool on the cluster,
+// runs the tests through it and removes it once they ran. It returns as soon
+// as the context is done, leaving the tool to removeSMIConformanceTool.
+func runSMIConformanceOnCluster(ctx context.Context, opts smiRunOptions, k8sconfig string) ([]*adapter.Detail, error) {
+	mclient, err := mesherykube.New([]byte(k8sconfig))
+	if err != nil {
+		return nil, err
+	}
+	contents, err := utils.ReadFileSource(opts.Manifest)
+	if err != nil {
+		return nil, err
+	}
+	if err := serverSideApply(ctx, mclient, []byte(contents), false, smiNamespace); err != nil {
+		return nil, err
+	}
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(smiToolStartDelay):
+	}
+
+	endpoint, err := mesherykube.GetServiceEndpoint(ctx, mclient.KubeClient, &mesherykube.ServiceOptions{
+		Name:         "smi-conformance",
+		Namespace:    smiNamespace,
+		PortSelector: "smi-conformance",
+		APIServerURL: mclient.RestConfig.Host,
+	})
+	if err != nil {
+		return nil, err
+	}
+	client, err := conformance.CreateClient(ctx, fmt.Sprintf("%s:%d", endpoint.External.Address, endpoint.External.Port))
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	request := &conformance.Request{
+		Mesh: &smp.ServiceMesh{
+			Annotations: map[string]string{},
+			Labels:      opts.Labels,
+			Type:        smp.ServiceMesh_ISTIO,
+			Version:     opts.MeshVersion,
+		},
+	}
+	var result *conformance.Response
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Second):
+		}
+		result, err = client.CClient.RunTest(ctx, request)
+		if err == nil {
+			break
+		}
+		// The tool isn't reachable until its service is routed
+		if ctx.Err() != nil || !strings.Contains(err.Error(), "i/o timeout") || attempt == smiRunAttempts {
+			return nil, err
+		}
+	}
+
+	details := make([]*adapter.Detail, 0, len(result.Details))
+	for _, d := range result.Details {
+		detail := &adapter.Detail{
+			SmiSpecification: d.Smispec,
+			SmiVersion:       d.Specversion,
+			Time:             d.Duration,
+			Assertions:       d.Assertion,
+			Capability:       d.Capability.String(),
+			Status:           d.Status.String(),
+		}
+		if d.Result.GetMessage() != "" {
+			detail.Result = d.Result.GetMessage()
+		} else {
+			detail.Result = d.Result.GetError().GetShortDescription()
+			detail.Reason = d.Result.GetError().GetLongDescription()
+		}
+		details = append(details, detail)
+	}
+	return details, serverSideApply(ctx, mclient, []byte(contents), true, smiNamespace)
+}
+
+// smiReport converts the SMI conformance response into a report
+func smiReport(resp adapter.Response, version string) *SMIReport {
+	if strings.TrimSpace(version) == "" {
+		version = smiDefaultVersion
+	}
+	report := &SMIReport{
+		MeshVersion: resp.MeshVersion,
+		TestVersion: version,
+		Date:        resp.Date,
+		Tests:       []SMITestCase{},
+	}
+	for _, d := range resp.MoreDetails {
+		if d == nil {
+			continue
+		}
+		details := d.Result
+		if d.Reason != "" {
+			details = strings.TrimSpace(fmt.Sprintf("%s\n%s", d.Result, d.Reason))
+		}
+		report.Tests = append(report.Tests, SMITestCase{
+			Name:          d.Assertions,
+			Specification: fmt.Sprintf("%s-%s", d.SmiSpecification, d.SmiVersion),
+			Capability:    d.Capability,
+			Result:        d.Status,
+			Details:       details,
+		})
+		if d.Status == "PASSED" {
+			report.Passed++
+		}
+	}
+	report.Total = len(report.Tests)
+	if report.Total == 0 {
+		// The response only has the number of passed cases when the tests
+		// didn't report their details
+		report.Passed, _ = strconv.Atoi(resp.CasesPassed)
+	}
+	return report
+}
+
+// streamSMIReport streams the report of an SMI conformance run as JSON in
+// the event details
+func (istio *Istio) streamSMIReport(ee *meshes.EventsResponse, report *SMIReport) {
+	e := &meshes.EventsResponse{
+		OperationId:   ee.OperationId,
+		Component:     ee.Component,
+		ComponentName: ee.ComponentName,
+		Summary:       fmt.Sprintf("SMI conformance report: %s", report),
+	}
+	details, err := json.Marshal(report)
+	if err != nil {
+		e.Details = err.Error()
+		istio.StreamWarn(e, err)
+		return
+	}
+	e.Details = string(details)
+	istio.StreamInfo(e)
+}
+
+// FileName is the name of the file of the report, unique per Istio and
+// Kubernetes version for the reports of the compatibility matrix not to
+// overwrite each other, such as
+// smi-conformance-istio-1.20.3-kubernetes-v1.28.2.json
+func (r *SMIReport) FileName() string {
+	name := func(versions ...string) string {
+		for i, v := range versions {
+			versions[i] = strings.Trim(smiFileNameUnsafe.ReplaceAllString(strings.TrimSpace(v), "_"), "_.")
+		}
+		v := strings.Join(versions, "_")
+		if v == "" {
+			return "unknown"
+		}
+		return v
+	}
+	return fmt.Sprintf("smi-conformance-istio-%s-kubernetes-%s.json", name(r.MeshVersion), name(r.KubernetesVersions...))
+}
+
+// writeSMIResults writes the report of the tests run on the clusters to its
+// file in the directory, see writeSMIReport, and returns the path of the file
+func writeSMIResults(dir string, report *SMIReport, kubeconfigs []string) (string, error) {
+	versions, err := kubernetesVersions(kubeconfigs)
+	if err != nil {
+		return "", ErrWriteSMIResults(fmt.Errorf("unable to get the Kubernetes version: %w", err))
+	}
+	report.KubernetesVersions = versions
+	return writeSMIReport(dir, report)
+}
+
+// writeSMIReport writes the report as JSON to its file in the directory, the
+// directory being created if missing, and returns the path of the file. The
+// file is replaced as a whole for the uploads never to pick a partial one.
+func writeSMIReport(dir string, report *SMIReport) (string, error) {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", ErrWriteSMIResults(err)
+	}
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return "", ErrWriteSMIResults(err)
+	}
+	file := filepath.Join(dir, report.FileName())
+	tmp, err := os.CreateTemp(dir, ".smi-conformance-*")
+	if err != nil {
+		return "", ErrWriteSMIResults(err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return "", ErrWriteSMIResults(err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", ErrWriteSMIResults(err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return "", ErrWriteSMIResults(err)
+	}
+	if err := os.Rename(tmp.Name(), file); err != nil {
+		return "", ErrWriteSMIResults(err)
+	}
+	return file, nil
+}
+
+// kubernetesVersions returns the distinct Kubernetes versions of the
+// clusters, sorted
+func kubernetesVersions(kubeconfigs []string) ([]string, error) {
+	var mx sync.Mutex
+	seen := map[string]bool{}
+	err := forEachCluster(kubeconfigs, func(k8sconfig string) error {
+		mclient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return err
+		}
+		serverVersion, err := mclient.KubeClient.Discovery().ServerVersion()
+		if err != nil {
+			return err
+		}
+		mx.Lock()
+		seen[serverVersion.GitVersion] = true
+		mx.Unlock()
+		return nil
+	}, nil)
+	versions := make([]string, 0, len(seen))
+	for v := range seen {
+		versions = append(versions, v)
+	}
+	sort.Strings(versions)
+	return versions, err
+}
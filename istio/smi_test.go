@@ -0,0 +1,175 @@
+package istio
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	configprovider "github.com/layer5io/meshery-adapter-library/config/provider"
+	internalconfig "github.com/layer5io/meshery-istio/internal/config"
+	"github.com/layer5io/meshkit/errors"
+)
+
+func TestSMIManifest(t *testing.T) {
+	const manifest = "https://raw.githubusercontent.com/layer5io/learn-layer5/master/smi-conformance/manifest.yml"
+	tests := []struct {
+		version string
+		want    string
+		wantErr bool
+	}{
+		{version: "", want: manifest},
+		{version: "master", want: manifest},
+		{version: "v0.1.0", want: "https://raw.githubusercontent.com/layer5io/learn-layer5/v0.1.0/smi-conformance/manifest.yml"},
+		{version: "../../etc", wantErr: true},
+		{version: "v0.1.0?token=x", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := smiManifest(manifest, tt.version)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("smiManifest(%q) error = %v, wantErr %v", tt.version, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("smiManifest(%q) = %s, want %s", tt.version, got, tt.want)
+		}
+	}
+	if _, err := smiManifest("file://templates/smi.yaml", "v0.1.0"); err == nil {
+		t.Errorf("smiManifest() pinned an unversioned manifest")
+	}
+}
+
+func TestSMIReport(t *testing.T) {
+	resp := adapter.Response{
+		MeshVersion: "1.20.3",
+		Date:        "2024-05-01T10:00:00Z",
+		CasesPassed: "1",
+		MoreDetails: []*adapter.Detail{
+			{SmiSpecification: "traffic-access", SmiVersion: "v0.6.0", Assertions: "step 1", Capability: "FULL", Status: "PASSED", Result: "passed"},
+			nil,
+			{SmiSpecification: "traffic-split", SmiVersion: "v0.5.0", Assertions: "step 2", Capability: "NONE", Status: "FAILED", Result: "failed", Reason: "no split"},
+		},
+	}
+	want := &SMIReport{
+		MeshVersion: "1.20.3",
+		TestVersion: "master",
+		Date:        "2024-05-01T10:00:00Z",
+		Passed:      1,
+		Total:       2,
+		Tests: []SMITestCase{
+			{Name: "step 1", Specification: "traffic-access-v0.6.0", Capability: "FULL", Result: "PASSED", Details: "passed"},
+			{Name: "step 2", Specification: "traffic-split-v0.5.0", Capability: "NONE", Result: "FAILED", Details: "failed\nno split"},
+		},
+	}
+	if got := smiReport(resp, ""); !reflect.DeepEqual(got, want) {
+		t.Errorf("smiReport() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSMIReportFileName(t *testing.T) {
+	tests := []struct {
+		report SMIReport
+		want   string
+	}{
+		{
+			report: SMIReport{MeshVersion: "1.20.3", KubernetesVersions: []string{"v1.28.2"}},
+			want:   "smi-conformance-istio-1.20.3-kubernetes-v1.28.2.json",
+		},
+		{
+			report: SMIReport{MeshVersion: "1.21.0", KubernetesVersions: []string{"v1.28.2+k3s1", "v1.29.0"}},
+			want:   "smi-conformance-istio-1.21.0-kubernetes-v1.28.2_k3s1_v1.29.0.json",
+		},
+		{
+			report: SMIReport{MeshVersion: "../1.20"},
+			want:   "smi-conformance-istio-1.20-kubernetes-unknown.json",
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.report.FileName(); got != tt.want {
+			t.Errorf("SMIReport.FileName() = %s, want %s", got, tt.want)
+		}
+	}
+}
+
+func TestWriteSMIReport(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "results")
+	reports := []*SMIReport{
+		{MeshVersion: "1.20.3", KubernetesVersions: []string{"v1.28.2"}, Passed: 1, Total: 2, Tests: []SMITestCase{}},
+		{MeshVersion: "1.20.3", KubernetesVersions: []string{"v1.29.0"}, Passed: 2, Total: 2, Tests: []SMITestCase{}},
+	}
+	for _, report := range reports {
+		file, err := writeSMIReport(dir, report)
+		if err != nil {
+			t.Fatalf("writeSMIReport() error = %v", err)
+		}
+		if want := filepath.Join(dir, report.FileName()); file != want {
+			t.Errorf("writeSMIReport() = %s, want %s", file, want)
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("writeSMIReport() file not readable: %v", err)
+		}
+		var got SMIReport
+		if err := json.Unmarshal(data, &got); err != nil || !reflect.DeepEqual(&got, report) {
+			t.Errorf("writeSMIReport() wrote %s, want %+v", data, report)
+		}
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != len(reports) {
+		t.Errorf("writeSMIReport() left %d files, want one per Kubernetes version", len(entries))
+	}
+}
+
+func TestRunSMITestTimeout(t *testing.T) {
+	const manifest = "https://raw.githubusercontent.com/layer5io/learn-layer5/master/smi-conformance/manifest.yml"
+	runBackup, removeBackup := runSMIConformance, removeSMIConformanceTool
+	defer func() { runSMIConformance, removeSMIConformanceTool = runBackup, removeBackup }()
+	// The tests of east complete while the ones of west stall until the
+	// run is stopped
+	runSMIConformance = func(ctx context.Context, opts smiRunOptions, k8sconfig string) ([]*adapter.Detail, error) {
+		if clusterName(k8sconfig) == "east" {
+			return []*adapter.Detail{{SmiSpecification: "traffic-access", SmiVersion: "v1alpha2", Assertions: "allow", Status: "PASSED"}}, nil
+		}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	var removed string
+	removeSMIConformanceTool = func(_ context.Context, manifest string, _ []string) error {
+		removed = manifest
+		return nil
+	}
+
+	h, err := internalconfig.New(configprovider.InMemKey)
+	if err != nil {
+		t.Fatalf("internalconfig.New() error = %v", err)
+	}
+	istio := &Istio{Adapter: adapter.Adapter{Config: h, Log: getLoggerHandler(t)}}
+	kubeconfigs := []string{"current-context: east\n", "current-context: west\n"}
+	report, resp, err := istio.runSMITest(context.Background(), "operation", manifest, "v0.1.0", 10*time.Millisecond, kubeconfigs)
+	if errors.GetCode(err) != ErrSMITestTimeoutCode {
+		t.Fatalf("runSMITest() error = %v, want code %s", err, ErrSMITestTimeoutCode)
+	}
+	if report == nil || report.TestVersion != "v0.1.0" || report.MeshVersion != istio.GetVersion() || report.Total != 1 || report.Passed != 1 {
+		t.Errorf("runSMITest() report = %+v, want the test of east in the partial report of v0.1.0", report)
+	}
+	if len(resp.MoreDetails) != 1 || resp.CasesPassed != "1" {
+		t.Errorf("runSMITest() response = %+v, want the test of east", resp)
+	}
+	if want := "https://raw.githubusercontent.com/layer5io/learn-layer5/v0.1.0/smi-conformance/manifest.yml"; removed != want {
+		t.Errorf("runSMITest() removed the conformance tool of %q, want %q", removed, want)
+	}
+
+	removeSMIConformanceTool = func(context.Context, string, []string) error {
+		return stderrors.New("forbidden")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = istio.runSMITest(ctx, "operation", manifest, "", time.Minute, kubeconfigs)
+	if errors.GetCode(err) != ErrOperationCancelledCode {
+		t.Errorf("runSMITest() cancelled error = %v, want code %s", err, ErrOperationCancelledCode)
+	}
+}
@@ -0,0 +1,119 @@
+package istio
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// SwitchNamespaceRevision moves the namespace from its current control plane
+// to the one of the revision, the default one being "", on every cluster.
+// The injection labels are replaced in a single patch so that the namespace
+// is never left without one, and the workloads are restarted to be injected
+// with the proxies of the revision if restart is true. The number of
+// workloads restarted is returned.
+//
+// ErrRevisionNotInstalled is returned, before any namespace is relabeled, if
+// istiod of the revision doesn't run on every cluster.
+func (istio *Istio) SwitchNamespaceRevision(ctx context.Context, namespace, revision string, restart bool, kubeConfigs []string) (int, error) {
+	var mx sync.Mutex
+	var missing []string
+	err := forEachCluster(kubeConfigs, func(k8sconfig string) error {
+		mclient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return err
+		}
+		installed, err := revisionInstalled(ctx, mclient.KubeClient, revision)
+		if err != nil {
+			return err
+		}
+		if !installed {
+			mx.Lock()
+			missing = append(missing, clusterName(k8sconfig))
+			mx.Unlock()
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		return 0, ErrLoadNamespace(err, namespace)
+	}
+	if len(missing) > 0 {
+		return 0, ErrRevisionNotInstalled(revisionName(revision), missing)
+	}
+
+	var restarted int
+	err = forEachCluster(kubeConfigs, func(k8sconfig string) error {
+		mclient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return err
+		}
+		if err := switchRevisionLabel(ctx, mclient.KubeClient, namespace, revision); err != nil {
+			return ErrLoadNamespace(err, namespace)
+		}
+		if !restart {
+			return nil
+		}
+		n, err := restartWorkloads(mclient.KubeClient, namespace, time.Now())
+		mx.Lock()
+		restarted += n
+		mx.Unlock()
+		if err != nil {
+			return ErrWorkloadRestartFailed(err)
+		}
+		return nil
+	}, nil)
+	return restarted, err
+}
+
+// revisionInstalled reports whether istiod of the revision, the default one
+// being "", runs in istio-system
+func revisionInstalled(ctx context.Context, client kubernetes.Interface, revision string) (bool, error) {
+	deployments, err := client.AppsV1().Deployments(istioRootNamespace).List(ctx, metav1.ListOptions{LabelSelector: "app=istiod"})
+	if err != nil {
+		return false, err
+	}
+	for _, deployment := range deployments.Items {
+		rev := deployment.Labels["istio.io/rev"]
+		if rev == "default" {
+			rev = ""
+		}
+		if rev == revision {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// switchRevisionLabel replaces the injection label of the namespace with the
+// one of the revision in a single merge patch. The default revision is
+// selected by istio-injection, which takes precedence over istio.io/rev,
+// hence the other label is removed by the same patch.
+func switchRevisionLabel(ctx context.Context, client kubernetes.Interface, namespace, revision string) error {
+	labels := map[string]interface{}{
+		"istio-injection": nil,
+		"istio.io/rev":    revision,
+	}
+	if revision == "" {
+		labels = map[string]interface{}{
+			"istio-injection": "enabled",
+			"istio.io/rev":    nil,
+		}
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"labels": labels},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := client.CoreV1().Namespaces().Patch(ctx, namespace, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("unable to label %s for revision %s: %w", namespace, revisionName(revision), err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package istio
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRevisionInstalled(t *testing.T) {
+	istiod := func(name string, labels map[string]string) *appsv1.Deployment {
+		return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: istioRootNamespace, Labels: labels}}
+	}
+	client := fake.NewSimpleClientset(
+		istiod("istiod", map[string]string{"app": "istiod", "istio.io/rev": "default"}),
+		istiod("istiod-1-22", map[string]string{"app": "istiod", "istio.io/rev": "1-22"}),
+		istiod("istio-ingressgateway", map[string]string{"app": "istio-ingressgateway", "istio.io/rev": "1-23"}),
+	)
+	for revision, want := range map[string]bool{"": true, "1-22": true, "1-23": false} {
+		got, err := revisionInstalled(context.Background(), client, revision)
+		if err != nil {
+			t.Fatalf("revisionInstalled(%q) error = %v", revision, err)
+		}
+		if got != want {
+			t.Errorf("revisionInstalled(%q) = %v, want %v", revision, got, want)
+		}
+	}
+}
+
+func TestSwitchRevisionLabel(t *testing.T) {
+	tests := []struct {
+		name     string
+		labels   map[string]string
+		revision string
+		want     map[string]string
+	}{
+		{
+			name:     "from the default revision",
+			labels:   map[string]string{"istio-injection": "enabled", "team": "payments"},
+			revision: "1-22",
+			want:     map[string]string{"istio.io/rev": "1-22", "team": "payments"},
+		},
+		{
+			name:     "between revisions",
+			labels:   map[string]string{"istio.io/rev": "1-21"},
+			revision: "1-22",
+			want:     map[string]string{"istio.io/rev": "1-22"},
+		},
+		{
+			name:     "back to the default revision",
+			labels:   map[string]string{"istio.io/rev": "1-22"},
+			revision: "",
+			want:     map[string]string{"istio-injection": "enabled"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "bookinfo", Labels: tt.labels}})
+			if err := switchRevisionLabel(context.Background(), client, "bookinfo", tt.revision); err != nil {
+				t.Fatalf("switchRevisionLabel() error = %v", err)
+			}
+			ns, err := client.CoreV1().Namespaces().Get(context.Background(), "bookinfo", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("unable to get the namespace: %v", err)
+			}
+			if !reflect.DeepEqual(ns.Labels, tt.want) {
+				t.Errorf("switchRevisionLabel() labels = %v, want %v", ns.Labels, tt.want)
+			}
+			if n := len(client.Actions()); n != 2 || client.Actions()[0].GetVerb() != "patch" {
+				t.Errorf("switchRevisionLabel() actions = %v, want a single patch", client.Actions())
+			}
+		})
+	}
+}
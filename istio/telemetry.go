@@ -0,0 +1,53 @@
+package istio
+
+import (
+	"context"
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/layer5io/meshery-adapter-library/status"
+	"github.com/layer5io/meshkit/utils"
+)
+
+// telemetryValues are the values of the Telemetry templates
+type telemetryValues struct {
+	Namespace string
+
+	// ProviderName is the tracing provider, as configured in the
+	// extensionProviders of the mesh config. Tracing is left untouched when empty
+	ProviderName string
+}
+
+// applyTelemetry applies the Telemetry resources configuring the metrics,
+// access logging and tracing of the workloads in the namespace
+func (istio *Istio) applyTelemetry(ctx context.Context, namespace string, isDelete bool, providerName string, templates []adapter.Template, kubeConfigs []string) (string, error) {
+	st := status.Deploying
+
+	if isDelete {
+		st = status.Removing
+	}
+
+	values := telemetryValues{
+		Namespace:    namespace,
+		ProviderName: providerName,
+	}
+	for _, template := range templates {
+		contents, err := utils.ReadFileSource(string(template))
+		if err != nil {
+			return st, ErrApplyTelemetry(err)
+		}
+
+		contents, err = renderTemplate(contents, values)
+		if err != nil {
+			return st, ErrApplyTelemetry(err)
+		}
+
+		err = istio.applyManifest(ctx, []byte(contents), isDelete, namespace, kubeConfigs)
+		if err != nil {
+			return st, ErrApplyTelemetry(err)
+		}
+	}
+
+	if isDelete {
+		return status.Removed, nil
+	}
+	return status.Deployed, nil
+}
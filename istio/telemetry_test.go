@@ -0,0 +1,105 @@
+package istio
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/layer5io/meshkit/errors"
+	"gopkg.in/yaml.v2"
+)
+
+type telemetryProviders []struct {
+	Providers []struct {
+		Name string `yaml:"name"`
+	} `yaml:"providers"`
+}
+
+func (p telemetryProviders) names() []string {
+	var names []string
+	for _, entry := range p {
+		for _, provider := range entry.Providers {
+			names = append(names, provider.Name)
+		}
+	}
+	return names
+}
+
+func TestTelemetryManifest(t *testing.T) {
+	tmpl, err := os.ReadFile("../templates/telemetry/telemetry.yaml")
+	if err != nil {
+		t.Fatalf("unable to read the telemetry template: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		provider    string
+		wantTracing []string
+	}{
+		{name: "without tracing provider"},
+		{name: "with tracing provider", provider: "tempo", wantTracing: []string{"tempo"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rendered, err := renderTemplate(string(tmpl), telemetryValues{Namespace: "bookinfo", ProviderName: tt.provider})
+			if err != nil {
+				t.Fatalf("renderTemplate() error = %v", err)
+			}
+			var telemetry struct {
+				Kind     string `yaml:"kind"`
+				Metadata struct {
+					Namespace string `yaml:"namespace"`
+				} `yaml:"metadata"`
+				Spec struct {
+					Metrics       telemetryProviders `yaml:"metrics"`
+					AccessLogging telemetryProviders `yaml:"accessLogging"`
+					Tracing       telemetryProviders `yaml:"tracing"`
+				} `yaml:"spec"`
+			}
+			if err := yaml.Unmarshal([]byte(rendered), &telemetry); err != nil {
+				t.Fatalf("renderTemplate() generated invalid YAML: %v\n%s", err, rendered)
+			}
+			if telemetry.Kind != "Telemetry" || telemetry.Metadata.Namespace != "bookinfo" {
+				t.Errorf("renderTemplate() kind/namespace = %s/%s, want Telemetry/bookinfo", telemetry.Kind, telemetry.Metadata.Namespace)
+			}
+			if got := telemetry.Spec.Metrics.names(); len(got) != 1 || got[0] != "prometheus" {
+				t.Errorf("renderTemplate() metrics providers = %v, want [prometheus]", got)
+			}
+			if got := telemetry.Spec.AccessLogging.names(); len(got) != 1 || got[0] != "envoy" {
+				t.Errorf("renderTemplate() access logging providers = %v, want [envoy]", got)
+			}
+			got := telemetry.Spec.Tracing.names()
+			if len(got) != len(tt.wantTracing) || (len(got) > 0 && got[0] != tt.wantTracing[0]) {
+				t.Errorf("renderTemplate() tracing providers = %v, want %v", got, tt.wantTracing)
+			}
+		})
+	}
+}
+
+func TestApplyTelemetryErrors(t *testing.T) {
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "invalid.yaml")
+	if err := os.WriteFile(invalid, []byte("metadata:\n  name: {{ .Name }}\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		template adapter.Template
+	}{
+		{name: "missing template", template: adapter.Template("file://" + filepath.Join(dir, "missing.yaml"))},
+		{name: "unknown template value", template: adapter.Template("file://" + invalid)},
+		{name: "unsupported template source", template: adapter.Template("templates/telemetry/telemetry.yaml")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			istio := &Istio{}
+			_, err := istio.applyTelemetry(context.Background(), "bookinfo", false, "tempo", []adapter.Template{tt.template}, nil)
+			if err == nil || errors.GetCode(err) != ErrApplyTelemetryCode {
+				t.Errorf("applyTelemetry() error = %v, want code %s", err, ErrApplyTelemetryCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package istio
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/layer5io/meshery-istio/internal/config"
+)
+
+// operationTimeout parses the operationTimeout property of the operation, 0
+// is returned when it is unset for the defaults of the operation to be used
+func operationTimeout(props map[string]string) (time.Duration, error) {
+	value := strings.TrimSpace(props[config.OperationTimeout])
+	if value == "" {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, ErrInvalidTimeout(err)
+	}
+	if timeout <= 0 {
+		return 0, ErrInvalidTimeout(fmt.Errorf("timeout %s is not positive", value))
+	}
+	return timeout, nil
+}
+
+// withOperationTimeout bounds the context by the timeout, the context is
+// returned as is when the timeout is 0
+func withOperationTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// rolloutTimeout returns how long is left until the deadline of the context,
+// which replaces the default rollout timeout of the operations run with an
+// operation timeout
+func rolloutTimeout(ctx context.Context, def time.Duration) time.Duration {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return def
+	}
+	return time.Until(deadline)
+}
+
+// interrupted returns the error of an operation stopped by its context,
+// ErrOperationTimedOut once the operation timeout is over and
+// ErrOperationCancelled when it was cancelled
+func interrupted(ctx context.Context) error {
+	if ctx.Err() == context.DeadlineExceeded {
+		return ErrOperationTimedOut(ctx.Err())
+	}
+	return ErrOperationCancelled(ctx.Err())
+}
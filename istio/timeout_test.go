@@ -0,0 +1,68 @@
+package istio
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/layer5io/meshery-istio/internal/config"
+	"github.com/layer5io/meshkit/errors"
+)
+
+func TestOperationTimeout(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "", want: 0},
+		{value: " 10m ", want: 10 * time.Minute},
+		{value: "1h30m", want: 90 * time.Minute},
+		{value: "10", wantErr: true},
+		{value: "soon", wantErr: true},
+		{value: "-5m", wantErr: true},
+		{value: "0s", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := operationTimeout(map[string]string{config.OperationTimeout: tt.value})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("operationTimeout(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if err != nil && errors.GetCode(err) != ErrInvalidTimeoutCode {
+			t.Errorf("operationTimeout(%q) error code = %s, want %s", tt.value, errors.GetCode(err), ErrInvalidTimeoutCode)
+		}
+		if got != tt.want {
+			t.Errorf("operationTimeout(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestRolloutTimeout(t *testing.T) {
+	ctx, cancel := withOperationTimeout(context.Background(), 0)
+	defer cancel()
+	if got := rolloutTimeout(ctx, istioRolloutTimeout); got != istioRolloutTimeout {
+		t.Errorf("rolloutTimeout() without operation timeout = %s, want %s", got, istioRolloutTimeout)
+	}
+
+	ctx, cancel = withOperationTimeout(context.Background(), 20*time.Minute)
+	defer cancel()
+	if got := rolloutTimeout(ctx, istioRolloutTimeout); got <= istioRolloutTimeout || got > 20*time.Minute {
+		t.Errorf("rolloutTimeout() with a 20m operation timeout = %s, want the time left until the deadline", got)
+	}
+}
+
+func TestInterrupted(t *testing.T) {
+	ctx, cancel := withOperationTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	if code := errors.GetCode(interrupted(ctx)); code != ErrOperationTimedOutCode {
+		t.Errorf("interrupted() after the timeout error code = %s, want %s", code, ErrOperationTimedOutCode)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if code := errors.GetCode(interrupted(ctx)); code != ErrOperationCancelledCode {
+		t.Errorf("interrupted() after the cancel error code = %s, want %s", code, ErrOperationCancelledCode)
+	}
+}
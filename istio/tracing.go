@@ -0,0 +1,149 @@
+package istio
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	"gopkg.in/yaml.v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// meshConfigMap is the ConfigMap of the default revision holding the mesh
+// config istiod watches
+const meshConfigMap = "istio"
+
+// tracingProvider is a zipkin extension provider of the mesh config
+type tracingProvider struct {
+	Name    string
+	Service string
+	Port    int
+}
+
+// tempoTracingProvider reports the spans to the Tempo addon through its
+// zipkin receiver
+var tempoTracingProvider = tracingProvider{
+	Name:    "tempo",
+	Service: "tempo.istio-system.svc.cluster.local",
+	Port:    9411,
+}
+
+// patchTracingProvider adds the provider to the extensionProviders of the mesh
+// config and makes it the default tracing provider of the mesh, so that
+// istiod configures the proxies to report their spans to it. The provider is
+// removed again if del is true.
+func (istio *Istio) patchTracingProvider(del bool, provider tracingProvider, kubeconfigs []string) error {
+	err := forEachCluster(kubeconfigs, func(k8sconfig string) error {
+		mclient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return err
+		}
+		cm, err := mclient.KubeClient.CoreV1().ConfigMaps(istioRootNamespace).Get(context.TODO(), meshConfigMap, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		mesh, err := setTracingProvider(cm.Data["mesh"], provider, del)
+		if err != nil {
+			return err
+		}
+		if mesh == cm.Data["mesh"] {
+			return nil
+		}
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data["mesh"] = mesh
+		_, err = mclient.KubeClient.CoreV1().ConfigMaps(istioRootNamespace).Update(context.TODO(), cm, metav1.UpdateOptions{})
+		return err
+	}, nil)
+	if err != nil {
+		return ErrPatchTracingProvider(err)
+	}
+	return nil
+}
+
+// setTracingProvider returns the mesh config with the provider set as the
+// default tracing provider, or removed from the mesh config if del is true.
+// The other providers are kept as is.
+func setTracingProvider(mesh string, provider tracingProvider, del bool) (string, error) {
+	config := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(mesh), &config); err != nil {
+		return "", fmt.Errorf("invalid mesh config: %w", err)
+	}
+	if config == nil {
+		config = map[string]interface{}{}
+	}
+
+	var providers []interface{}
+	if existing, ok := config["extensionProviders"].([]interface{}); ok {
+		for _, p := range existing {
+			if m, ok := p.(map[interface{}]interface{}); ok && m["name"] == provider.Name {
+				continue
+			}
+			providers = append(providers, p)
+		}
+	}
+
+	defaults, _ := config["defaultProviders"].(map[interface{}]interface{})
+	if defaults == nil {
+		defaults = map[interface{}]interface{}{}
+	}
+
+	if del {
+		if tracing, ok := defaults["tracing"].([]interface{}); ok && len(tracing) == 1 && tracing[0] == provider.Name {
+			delete(defaults, "tracing")
+		}
+	} else {
+		providers = append(providers, map[string]interface{}{
+			"name": provider.Name,
+			"zipkin": map[string]interface{}{
+				"service": provider.Service,
+				"port":    provider.Port,
+			},
+		})
+		defaults["tracing"] = []interface{}{provider.Name}
+	}
+
+	if len(providers) == 0 {
+		delete(config, "extensionProviders")
+	} else {
+		config["extensionProviders"] = providers
+	}
+	if len(defaults) == 0 {
+		delete(config, "defaultProviders")
+	} else {
+		config["defaultProviders"] = defaults
+	}
+
+	out, err := yaml.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// parseTracingSampling parses the tracing sampling of the install operation,
+// a percentage between 0 and 100 such as 10 or 0.5. Nil is returned if the
+// sampling is empty, the default of the profile being used.
+func parseTracingSampling(value string) (*float64, error) {
+	value = strings.TrimSuffix(strings.TrimSpace(value), "%")
+	if value == "" {
+		return nil, nil
+	}
+	sampling, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, ErrInvalidTracingSampling(value, err)
+	}
+	if sampling < 0 || sampling > 100 {
+		return nil, ErrInvalidTracingSampling(value, fmt.Errorf("%s is not between 0 and 100", value))
+	}
+	return &sampling, nil
+}
+
+// formatTracingSampling formats the tracing sampling as a percentage for the
+// event details
+func formatTracingSampling(sampling float64) string {
+	return strconv.FormatFloat(sampling, 'f', -1, 64) + "%"
+}
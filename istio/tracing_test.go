@@ -0,0 +1,100 @@
+package istio
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/layer5io/meshkit/errors"
+	"gopkg.in/yaml.v2"
+)
+
+func TestSetTracingProvider(t *testing.T) {
+	const mesh = `accessLogFile: /dev/stdout
+extensionProviders:
+- name: otel
+  opentelemetry:
+    service: otel-collector.observability.svc.cluster.local
+    port: 4317
+`
+	installed, err := setTracingProvider(mesh, tempoTracingProvider, false)
+	if err != nil {
+		t.Fatalf("setTracingProvider() error = %v", err)
+	}
+	var got map[string]interface{}
+	if err := yaml.Unmarshal([]byte(installed), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["accessLogFile"] != "/dev/stdout" {
+		t.Errorf("setTracingProvider() dropped the other mesh config fields: %s", installed)
+	}
+	if providers, _ := got["extensionProviders"].([]interface{}); len(providers) != 2 {
+		t.Errorf("setTracingProvider() extensionProviders = %v, want otel and tempo", got["extensionProviders"])
+	}
+	defaults, _ := got["defaultProviders"].(map[interface{}]interface{})
+	if !reflect.DeepEqual(defaults["tracing"], []interface{}{"tempo"}) {
+		t.Errorf("setTracingProvider() default tracing providers = %v, want [tempo]", defaults["tracing"])
+	}
+
+	// Setting the provider again doesn't duplicate it
+	again, err := setTracingProvider(installed, tempoTracingProvider, false)
+	if err != nil || again != installed {
+		t.Errorf("setTracingProvider() isn't idempotent, got %s, want %s", again, installed)
+	}
+
+	reverted, err := setTracingProvider(installed, tempoTracingProvider, true)
+	if err != nil {
+		t.Fatalf("setTracingProvider() of delete error = %v", err)
+	}
+	var want map[string]interface{}
+	if err := yaml.Unmarshal([]byte(mesh), &want); err != nil {
+		t.Fatal(err)
+	}
+	got = nil
+	if err := yaml.Unmarshal([]byte(reverted), &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("setTracingProvider() of delete = %s, want %s", reverted, mesh)
+	}
+
+	if _, err := setTracingProvider("extensionProviders: [", tempoTracingProvider, false); err == nil {
+		t.Errorf("setTracingProvider() of invalid mesh config succeeded, want error")
+	}
+}
+
+func TestParseTracingSampling(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{value: ""},
+		{value: "10", want: "10%"},
+		{value: " 0.5% ", want: "0.5%"},
+		{value: "0", want: "0%"},
+		{value: "100", want: "100%"},
+		{value: "100.1", wantErr: true},
+		{value: "-1", wantErr: true},
+		{value: "ten", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseTracingSampling(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTracingSampling() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if errors.GetCode(err) != ErrInvalidTracingSamplingCode {
+					t.Errorf("parseTracingSampling() error code = %s, want %s", errors.GetCode(err), ErrInvalidTracingSamplingCode)
+				}
+				return
+			}
+			if (got == nil) != (tt.want == "") {
+				t.Fatalf("parseTracingSampling() = %v, want %q", got, tt.want)
+			}
+			if got != nil && formatTracingSampling(*got) != tt.want {
+				t.Errorf("parseTracingSampling() = %s, want %s", formatTracingSampling(*got), tt.want)
+			}
+		})
+	}
+}
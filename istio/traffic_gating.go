@@ -0,0 +1,220 @@
+package istio
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/layer5io/meshery-adapter-library/common"
+	"github.com/layer5io/meshery-istio/internal/config"
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// trafficGatingOptions describes the traffic shift between the stable and
+// the canary version of a service
+type trafficGatingOptions struct {
+	Service string
+	Stable  string
+	Canary  string
+	Window  time.Duration
+	Steps   int
+}
+
+// newTrafficGatingOptions reads the traffic gating options of the operation.
+// The rollout window and steps are only read when the routing is applied,
+// its removal not shifting any traffic.
+func newTrafficGatingOptions(props map[string]string, del bool) (trafficGatingOptions, error) {
+	opts := trafficGatingOptions{
+		Service: props[common.ServiceName],
+		Stable:  props[config.StableVersion],
+		Canary:  props[config.CanaryVersion],
+	}
+	if del {
+		return opts, nil
+	}
+	window, err := time.ParseDuration(strings.TrimSpace(props[config.RolloutWindow]))
+	if err != nil {
+		return opts, ErrTrafficGating(fmt.Errorf("%s: %w", config.RolloutWindow, err))
+	}
+	steps, err := strconv.Atoi(strings.TrimSpace(props[config.RolloutSteps]))
+	if err != nil {
+		return opts, ErrTrafficGating(fmt.Errorf("%s: %w", config.RolloutSteps, err))
+	}
+	opts.Window, opts.Steps = window, steps
+	return opts, nil
+}
+
+// gateTrafficOnReadiness shifts the traffic of a service from the stable to the
+// canary version over the configured window. At every step the canary weight is
+// capped by the fraction of canary pods which are ready, so that the new version
+// only receives traffic once its pods pass their readiness probes.
+//
+// progress is called every time the weight changes, the final canary weight
+// is returned. The shift stops at the weight reached once the context is
+// done.
+func (istio *Istio) gateTrafficOnReadiness(ctx context.Context, namespace string, del bool, opts trafficGatingOptions, progress func(weight, ready, total int), kubeconfigs []string) (int, error) {
+	if opts.Service == "" || opts.Stable == "" || opts.Canary == "" {
+		return 0, ErrTrafficGating(fmt.Errorf("service, stable and canary versions are required"))
+	}
+
+	if del {
+		manifest, err := trafficGatingManifest(namespace, opts, 0)
+		if err != nil {
+			return 0, ErrTrafficGating(err)
+		}
+		if err := istio.applyManifest(ctx, manifest, true, namespace, kubeconfigs); err != nil {
+			return 0, ErrTrafficGating(err)
+		}
+		return 0, nil
+	}
+
+	if opts.Steps <= 0 || opts.Window <= 0 {
+		return 0, ErrTrafficGating(fmt.Errorf("rollout window and steps must be positive"))
+	}
+	weight := 0
+	manifest, err := trafficGatingManifest(namespace, opts, weight)
+	if err != nil {
+		return weight, ErrTrafficGating(err)
+	}
+	if err := istio.applyManifest(ctx, manifest, false, namespace, kubeconfigs); err != nil {
+		return weight, ErrTrafficGating(err)
+	}
+	progress(weight, 0, 0)
+
+	interval := opts.Window / time.Duration(opts.Steps)
+	for step := 1; step <= opts.Steps; step++ {
+		select {
+		case <-ctx.Done():
+			return weight, interrupted(ctx)
+		case <-time.After(interval):
+		}
+
+		ready, total, err := canaryReadiness(ctx, namespace, opts, kubeconfigs)
+		if err != nil {
+			return weight, ErrTrafficGating(err)
+		}
+
+		target := gatedWeight(step, opts.Steps, ready, total)
+		if target == weight {
+			continue
+		}
+
+		weight = target
+		manifest, err := trafficGatingManifest(namespace, opts, weight)
+		if err != nil {
+			return weight, ErrTrafficGating(err)
+		}
+		if err := istio.applyManifest(ctx, manifest, false, namespace, kubeconfigs); err != nil {
+			return weight, ErrTrafficGating(err)
+		}
+		progress(weight, ready, total)
+	}
+	return weight, nil
+}
+
+// gatedWeight returns the canary weight of the step, capped by the
+// percentage of ready canary pods
+func gatedWeight(step, steps, ready, total int) int {
+	if total == 0 {
+		return 0
+	}
+	target := step * 100 / steps
+	if readyWeight := ready * 100 / total; readyWeight < target {
+		return readyWeight
+	}
+	return target
+}
+
+// canaryReadiness returns the number of ready and total canary pods across all
+// the clusters
+func canaryReadiness(ctx context.Context, namespace string, opts trafficGatingOptions, kubeconfigs []string) (int, int, error) {
+	var ready, total int
+	for _, k8sconfig := range kubeconfigs {
+		mclient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return 0, 0, err
+		}
+		pods, err := mclient.KubeClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+			LabelSelector: fmt.Sprintf("app=%s,version=%s", opts.Service, opts.Canary),
+		})
+		if err != nil {
+			return 0, 0, err
+		}
+		for _, pod := range pods.Items {
+			total++
+			for _, cond := range pod.Status.Conditions {
+				if cond.Type == corev1.PodReady && cond.Status == corev1.ConditionTrue {
+					ready++
+				}
+			}
+		}
+	}
+	return ready, total, nil
+}
+
+// trafficGatingManifest renders the DestinationRule with the stable and canary
+// subsets and the VirtualService splitting the traffic between them
+func trafficGatingManifest(namespace string, opts trafficGatingOptions, weight int) ([]byte, error) {
+	name := fmt.Sprintf("%s-readiness-gate", opts.Service)
+	dr := map[string]interface{}{
+		"apiVersion": "networking.istio.io/v1beta1",
+		"kind":       "DestinationRule",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": namespace,
+		},
+		"spec": map[string]interface{}{
+			"host": opts.Service,
+			"subsets": []interface{}{
+				map[string]interface{}{
+					"name":   opts.Stable,
+					"labels": map[string]string{"version": opts.Stable},
+				},
+				map[string]interface{}{
+					"name":   opts.Canary,
+					"labels": map[string]string{"version": opts.Canary},
+				},
+			},
+		},
+	}
+	vs := map[string]interface{}{
+		"apiVersion": "networking.istio.io/v1beta1",
+		"kind":       "VirtualService",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": namespace,
+		},
+		"spec": map[string]interface{}{
+			"hosts": []string{opts.Service},
+			"http": []interface{}{
+				map[string]interface{}{
+					"route": []interface{}{
+						map[string]interface{}{
+							"destination": map[string]interface{}{"host": opts.Service, "subset": opts.Stable},
+							"weight":      100 - weight,
+						},
+						map[string]interface{}{
+							"destination": map[string]interface{}{"host": opts.Service, "subset": opts.Canary},
+							"weight":      weight,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	drYaml, err := yaml.Marshal(dr)
+	if err != nil {
+		return nil, err
+	}
+	vsYaml, err := yaml.Marshal(vs)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(fmt.Sprintf("%s\n---\n%s", drYaml, vsYaml)), nil
+}
@@ -0,0 +1,199 @@
+package istio
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/layer5io/meshery-adapter-library/common"
+	"github.com/layer5io/meshery-istio/internal/config"
+	"github.com/layer5io/meshkit/errors"
+	"gopkg.in/yaml.v2"
+)
+
+func TestGatedWeight(t *testing.T) {
+	tests := []struct {
+		name               string
+		step, steps        int
+		ready, total, want int
+	}{
+		{name: "all canary pods ready", step: 1, steps: 4, ready: 2, total: 2, want: 25},
+		{name: "capped by ready pods", step: 3, steps: 4, ready: 1, total: 2, want: 50},
+		{name: "no canary pod ready", step: 2, steps: 4, ready: 0, total: 3, want: 0},
+		{name: "no canary pods", step: 4, steps: 4, ready: 0, total: 0, want: 0},
+		{name: "last step", step: 4, steps: 4, ready: 3, total: 3, want: 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := gatedWeight(tt.step, tt.steps, tt.ready, tt.total); got != tt.want {
+				t.Errorf("gatedWeight() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrafficGatingManifest(t *testing.T) {
+	opts := trafficGatingOptions{Service: "reviews", Stable: "v1", Canary: "v2"}
+	tests := []struct {
+		name       string
+		weight     int
+		wantStable int
+	}{
+		{name: "stable only", weight: 0, wantStable: 100},
+		{name: "split", weight: 30, wantStable: 70},
+		{name: "canary only", weight: 100, wantStable: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifest, err := trafficGatingManifest("default", opts, tt.weight)
+			if err != nil {
+				t.Fatalf("trafficGatingManifest() error = %v", err)
+			}
+			docs := bytes.Split(manifest, []byte("\n---\n"))
+			if len(docs) != 2 {
+				t.Fatalf("trafficGatingManifest() rendered %d resources, want 2", len(docs))
+			}
+			var dr, vs struct {
+				Kind     string `yaml:"kind"`
+				Metadata struct {
+					Name      string `yaml:"name"`
+					Namespace string `yaml:"namespace"`
+				} `yaml:"metadata"`
+				Spec struct {
+					Subsets []struct {
+						Name string `yaml:"name"`
+					} `yaml:"subsets"`
+					HTTP []struct {
+						Route []struct {
+							Destination struct {
+								Subset string `yaml:"subset"`
+							} `yaml:"destination"`
+							Weight int `yaml:"weight"`
+						} `yaml:"route"`
+					} `yaml:"http"`
+				} `yaml:"spec"`
+			}
+			if err := yaml.Unmarshal(docs[0], &dr); err != nil {
+				t.Fatal(err)
+			}
+			if err := yaml.Unmarshal(docs[1], &vs); err != nil {
+				t.Fatal(err)
+			}
+			if dr.Kind != "DestinationRule" || len(dr.Spec.Subsets) != 2 {
+				t.Errorf("trafficGatingManifest() DestinationRule = %+v, want the v1 and v2 subsets", dr)
+			}
+			if vs.Kind != "VirtualService" || vs.Metadata.Name != "reviews-readiness-gate" || vs.Metadata.Namespace != "default" {
+				t.Errorf("trafficGatingManifest() VirtualService = %s %s/%s", vs.Kind, vs.Metadata.Namespace, vs.Metadata.Name)
+			}
+			if len(vs.Spec.HTTP) != 1 || len(vs.Spec.HTTP[0].Route) != 2 {
+				t.Fatalf("trafficGatingManifest() routes = %+v, want a stable and a canary route", vs.Spec.HTTP)
+			}
+			routes := vs.Spec.HTTP[0].Route
+			if routes[0].Destination.Subset != "v1" || routes[0].Weight != tt.wantStable || routes[1].Destination.Subset != "v2" || routes[1].Weight != tt.weight {
+				t.Errorf("trafficGatingManifest() routes = %+v, want v1 %d and v2 %d", routes, tt.wantStable, tt.weight)
+			}
+		})
+	}
+}
+
+func TestGateTrafficOnReadinessValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		opts trafficGatingOptions
+	}{
+		{name: "missing service", opts: trafficGatingOptions{Stable: "v1", Canary: "v2", Window: time.Minute, Steps: 2}},
+		{name: "missing canary", opts: trafficGatingOptions{Service: "reviews", Stable: "v1", Window: time.Minute, Steps: 2}},
+		{name: "no steps", opts: trafficGatingOptions{Service: "reviews", Stable: "v1", Canary: "v2", Window: time.Minute}},
+		{name: "negative window", opts: trafficGatingOptions{Service: "reviews", Stable: "v1", Canary: "v2", Window: -time.Minute, Steps: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			istio := &Istio{}
+			_, err := istio.gateTrafficOnReadiness(context.Background(), "default", false, tt.opts, func(weight, ready, total int) {}, nil)
+			if err == nil || errors.GetCode(err) != ErrTrafficGatingCode {
+				t.Errorf("gateTrafficOnReadiness() error = %v, want ErrTrafficGating", err)
+			}
+		})
+	}
+}
+
+func TestGateTrafficOnReadinessDelete(t *testing.T) {
+	// The removal of the routing has no rollout window nor steps
+	istio := &Istio{}
+	opts := trafficGatingOptions{Service: "reviews", Stable: "v1", Canary: "v2"}
+	if _, err := istio.gateTrafficOnReadiness(context.Background(), "default", true, opts, func(weight, ready, total int) {}, nil); err != nil {
+		t.Errorf("gateTrafficOnReadiness() delete error = %v", err)
+	}
+}
+
+func TestNewTrafficGatingOptions(t *testing.T) {
+	props := func(window, steps string) map[string]string {
+		return map[string]string{
+			common.ServiceName:   "reviews",
+			config.StableVersion: "v1",
+			config.CanaryVersion: "v2",
+			config.RolloutWindow: window,
+			config.RolloutSteps:  steps,
+		}
+	}
+	tests := []struct {
+		name    string
+		props   map[string]string
+		del     bool
+		want    trafficGatingOptions
+		wantErr string
+	}{
+		{
+			name:  "rollout",
+			props: props("5m", " 5 "),
+			want:  trafficGatingOptions{Service: "reviews", Stable: "v1", Canary: "v2", Window: 5 * time.Minute, Steps: 5},
+		},
+		{
+			name:  "removal without rollout",
+			props: props("", ""),
+			del:   true,
+			want:  trafficGatingOptions{Service: "reviews", Stable: "v1", Canary: "v2"},
+		},
+		{
+			name:    "invalid window",
+			props:   props("5", "5"),
+			wantErr: `rollout-window: time: missing unit in duration "5"`,
+		},
+		{
+			name:    "invalid steps",
+			props:   props("5m", "five"),
+			wantErr: `rollout-steps: strconv.Atoi: parsing "five": invalid syntax`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newTrafficGatingOptions(tt.props, tt.del)
+			if tt.wantErr != "" {
+				if errors.GetCode(err) != ErrTrafficGatingCode || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("newTrafficGatingOptions() error = %v, want ErrTrafficGating with %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("newTrafficGatingOptions() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("newTrafficGatingOptions() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGateTrafficOnReadinessCancelled(t *testing.T) {
+	// The ramp stops at its first step instead of waiting for the window
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	istio := &Istio{}
+	opts := trafficGatingOptions{Service: "reviews", Stable: "v1", Canary: "v2", Window: time.Hour, Steps: 4}
+	weight, err := istio.gateTrafficOnReadiness(ctx, "default", false, opts, func(weight, ready, total int) {}, nil)
+	if errors.GetCode(err) != ErrOperationCancelledCode || weight != 0 {
+		t.Errorf("gateTrafficOnReadiness() = %d, %v, want 0 and code %s", weight, err, ErrOperationCancelledCode)
+	}
+}
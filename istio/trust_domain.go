@@ -0,0 +1,142 @@
+package istio
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/layer5io/meshery-istio/internal/config"
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	"gopkg.in/yaml.v2"
+	kubeerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+)
+
+// defaultTrustDomain is the trust domain of the mesh when the mesh config
+// doesn't set one
+const defaultTrustDomain = "cluster.local"
+
+// trustDomain is the SPIFFE trust domain of the workload identities, along
+// with the other trust domains the mesh accepts the identities of. The zero
+// value keeps the trust domain of the profile.
+type trustDomain struct {
+	Domain  string
+	Aliases []string
+}
+
+// newTrustDomain reads the trust domain of the install operation from its
+// trustDomain property and its aliases from the comma separated
+// trustDomainAliases property. Every trust domain must be a DNS subdomain.
+func newTrustDomain(props map[string]string) (trustDomain, error) {
+	var td trustDomain
+	domain := strings.TrimSpace(props[config.TrustDomain])
+	if domain != "" {
+		if errs := validation.IsDNS1123Subdomain(domain); len(errs) > 0 {
+			return td, ErrInvalidTrustDomain(fmt.Errorf("%s %q: %s", config.TrustDomain, domain, strings.Join(errs, ", ")))
+		}
+		td.Domain = domain
+	}
+	for _, alias := range strings.Split(props[config.TrustDomainAliases], ",") {
+		alias = strings.TrimSpace(alias)
+		if alias == "" {
+			continue
+		}
+		if errs := validation.IsDNS1123Subdomain(alias); len(errs) > 0 {
+			return trustDomain{}, ErrInvalidTrustDomain(fmt.Errorf("%s %q: %s", config.TrustDomainAliases, alias, strings.Join(errs, ", ")))
+		}
+		td.Aliases = append(td.Aliases, alias)
+	}
+	return td, nil
+}
+
+func (td trustDomain) empty() bool {
+	return td.Domain == "" && len(td.Aliases) == 0
+}
+
+// domain returns the trust domain the install sets, the default one when
+// only the aliases are set
+func (td trustDomain) domain() string {
+	if td.Domain == "" {
+		return defaultTrustDomain
+	}
+	return td.Domain
+}
+
+// String describes the trust domain for the event details
+func (td trustDomain) String() string {
+	domain := td.domain()
+	if len(td.Aliases) == 0 {
+		return fmt.Sprintf("the trust domain %s", domain)
+	}
+	return fmt.Sprintf("the trust domain %s, also accepting %s", domain, strings.Join(td.Aliases, ", "))
+}
+
+// meshConfig returns the fields of the mesh config setting the trust domain
+func (td trustDomain) meshConfig() map[string]interface{} {
+	mesh := map[string]interface{}{}
+	if td.Domain != "" {
+		mesh["trustDomain"] = td.Domain
+	}
+	if len(td.Aliases) > 0 {
+		aliases := make([]interface{}, 0, len(td.Aliases))
+		for _, alias := range td.Aliases {
+			aliases = append(aliases, alias)
+		}
+		mesh["trustDomainAliases"] = aliases
+	}
+	return mesh
+}
+
+// changedTrustDomains returns the trust domains of the control plane of the
+// revision other than the requested one, as "domain on cluster". The
+// clusters the revision isn't installed on are left out.
+func changedTrustDomains(ctx context.Context, revision, domain string, kubeconfigs []string) ([]string, error) {
+	var mx sync.Mutex
+	var changed []string
+	err := forEachCluster(kubeconfigs, func(k8sconfig string) error {
+		mclient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return err
+		}
+		installed, err := installedTrustDomain(ctx, mclient.KubeClient, revision)
+		if err != nil || installed == "" || installed == domain {
+			return err
+		}
+		mx.Lock()
+		changed = append(changed, fmt.Sprintf("%s on %s", installed, clusterName(k8sconfig)))
+		mx.Unlock()
+		return nil
+	}, nil)
+	sort.Strings(changed)
+	return changed, err
+}
+
+// installedTrustDomain returns the trust domain of the mesh config of the
+// revision, "" if the revision isn't installed
+func installedTrustDomain(ctx context.Context, client kubernetes.Interface, revision string) (string, error) {
+	name := meshConfigMap
+	if revision != "" {
+		name = fmt.Sprintf("%s-%s", meshConfigMap, revision)
+	}
+	cm, err := client.CoreV1().ConfigMaps(istioRootNamespace).Get(ctx, name, metav1.GetOptions{})
+	if kubeerror.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	var mesh struct {
+		TrustDomain string `yaml:"trustDomain"`
+	}
+	if err := yaml.Unmarshal([]byte(cm.Data["mesh"]), &mesh); err != nil {
+		return "", fmt.Errorf("invalid mesh config: %w", err)
+	}
+	if mesh.TrustDomain == "" {
+		return defaultTrustDomain, nil
+	}
+	return mesh.TrustDomain, nil
+}
@@ -0,0 +1,101 @@
+package istio
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/layer5io/meshery-istio/internal/config"
+	"github.com/layer5io/meshkit/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestNewTrustDomain(t *testing.T) {
+	tests := []struct {
+		name    string
+		props   map[string]string
+		want    trustDomain
+		wantErr bool
+	}{
+		{
+			name:  "trust domain of the profile",
+			props: map[string]string{config.TrustDomain: "", config.TrustDomainAliases: ""},
+		},
+		{
+			name:  "trust domain",
+			props: map[string]string{config.TrustDomain: " example.com "},
+			want:  trustDomain{Domain: "example.com"},
+		},
+		{
+			name:  "trust domain with aliases",
+			props: map[string]string{config.TrustDomain: "example.com", config.TrustDomainAliases: "cluster.local, old.example.com,"},
+			want:  trustDomain{Domain: "example.com", Aliases: []string{"cluster.local", "old.example.com"}},
+		},
+		{
+			name:  "aliases only",
+			props: map[string]string{config.TrustDomainAliases: "example.com"},
+			want:  trustDomain{Aliases: []string{"example.com"}},
+		},
+		{
+			name:    "uppercase trust domain",
+			props:   map[string]string{config.TrustDomain: "Example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "spiffe URI",
+			props:   map[string]string{config.TrustDomain: "spiffe://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid alias",
+			props:   map[string]string{config.TrustDomain: "example.com", config.TrustDomainAliases: "cluster.local;old"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newTrustDomain(tt.props)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newTrustDomain() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && errors.GetCode(err) != ErrInvalidTrustDomainCode {
+				t.Errorf("newTrustDomain() error code = %s, want %s", errors.GetCode(err), ErrInvalidTrustDomainCode)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newTrustDomain() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInstalledTrustDomain(t *testing.T) {
+	meshConfig := func(name, mesh string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: istioRootNamespace},
+			Data:       map[string]string{"mesh": mesh},
+		}
+	}
+	client := fake.NewSimpleClientset(
+		meshConfig(meshConfigMap, "accessLogFile: /dev/stdout\n"),
+		meshConfig(meshConfigMap+"-canary", "trustDomain: example.com\n"),
+	)
+	tests := []struct {
+		revision string
+		want     string
+	}{
+		{revision: "", want: defaultTrustDomain},
+		{revision: "canary", want: "example.com"},
+		{revision: "missing", want: ""},
+	}
+	for _, tt := range tests {
+		got, err := installedTrustDomain(context.Background(), client, tt.revision)
+		if err != nil {
+			t.Fatalf("installedTrustDomain(%q) error = %v", tt.revision, err)
+		}
+		if got != tt.want {
+			t.Errorf("installedTrustDomain(%q) = %q, want %q", tt.revision, got, tt.want)
+		}
+	}
+}
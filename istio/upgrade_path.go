@@ -0,0 +1,50 @@
+package istio
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// checkUpgradePath checks that the control plane can be moved to the version
+// from the versions running on the clusters, keyed by cluster. Istio only
+// supports upgrading one minor version at a time, hence a jump of several
+// minor versions is refused with ErrUnsupportedUpgradePath naming the minor
+// versions to upgrade through first. Reinstalling the running version and
+// downgrading are allowed, the versions downgraded from being returned as
+// "<version> on <cluster>" for the downgrade to be warned about. The versions
+// which aren't major.minor.patch versions, such as a custom tag, can't be
+// compared and are left out.
+func checkUpgradePath(installed map[string]string, version string) ([]string, error) {
+	target, err := parseVersionNumbers(version)
+	if err != nil {
+		return nil, nil
+	}
+	var downgrades, problems []string
+	for cluster, running := range installed {
+		from, err := parseVersionNumbers(running)
+		if err != nil {
+			continue
+		}
+		switch {
+		case from[0] != target[0]:
+			problems = append(problems, fmt.Sprintf("%s runs %s, of another major version", cluster, running))
+		case target[1] > from[1]+1:
+			hops := make([]string, 0, target[1]-from[1]-1)
+			for minor := from[1] + 1; minor < target[1]; minor++ {
+				hops = append(hops, fmt.Sprintf("%d.%d", target[0], minor))
+			}
+			problems = append(problems, fmt.Sprintf("%s runs %s, upgrade through %s first", cluster, running, strings.Join(hops, ", ")))
+		default:
+			if newer, _ := newerVersion(running, version); newer {
+				downgrades = append(downgrades, fmt.Sprintf("%s on %s", running, cluster))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return nil, ErrUnsupportedUpgradePath(version, problems)
+	}
+	sort.Strings(downgrades)
+	return downgrades, nil
+}
@@ -0,0 +1,79 @@
+package istio
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/layer5io/meshkit/errors"
+)
+
+func TestCheckUpgradePath(t *testing.T) {
+	tests := []struct {
+		name           string
+		installed      map[string]string
+		version        string
+		wantDowngrades []string
+		wantErr        []string
+	}{
+		{
+			name:    "not installed",
+			version: "1.22.0",
+		},
+		{
+			name:      "reinstall",
+			installed: map[string]string{"east": "1.22.0"},
+			version:   "1.22.0",
+		},
+		{
+			name:      "next minor version",
+			installed: map[string]string{"east": "1.21.3", "west": "1.22.0"},
+			version:   "1.22.1",
+		},
+		{
+			name:           "downgrade",
+			installed:      map[string]string{"east": "1.22.1", "west": "1.21.0"},
+			version:        "1.20.4",
+			wantDowngrades: []string{"1.21.0 on west", "1.22.1 on east"},
+		},
+		{
+			name:      "several minor versions",
+			installed: map[string]string{"east": "1.19.5", "west": "1.21.0"},
+			version:   "1.22.0",
+			wantErr:   []string{"east runs 1.19.5, upgrade through 1.20, 1.21 first"},
+		},
+		{
+			name:      "another major version",
+			installed: map[string]string{"east": "0.9.0"},
+			version:   "1.0.0",
+			wantErr:   []string{"east runs 0.9.0, of another major version"},
+		},
+		{
+			name:      "custom tag",
+			installed: map[string]string{"east": "latest"},
+			version:   "1.22.0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := checkUpgradePath(tt.installed, tt.version)
+			if (err != nil) != (len(tt.wantErr) > 0) {
+				t.Fatalf("checkUpgradePath() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if code := errors.GetCode(err); code != ErrUnsupportedUpgradePathCode {
+					t.Errorf("checkUpgradePath() error code = %s, want %s", code, ErrUnsupportedUpgradePathCode)
+				}
+				for _, want := range tt.wantErr {
+					if !strings.Contains(err.Error(), want) {
+						t.Errorf("checkUpgradePath() error = %v, want it to mention %q", err, want)
+					}
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.wantDowngrades) {
+				t.Errorf("checkUpgradePath() = %v, want %v", got, tt.wantDowngrades)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package istio
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MTLSResult is the outcome of the mTLS and the plaintext requests sent to
+// an HTTP port of a service of the namespace on a cluster
+type MTLSResult struct {
+	Cluster string
+	Service string
+
+	// MTLS reports whether the request sent through the sidecar of the
+	// source pod, hence over mTLS, got a response
+	MTLS bool
+
+	// Plaintext reports whether the request sent from the proxy container
+	// of the source pod, which bypasses the sidecar, got a response
+	Plaintext bool
+}
+
+// Enforced reports whether the service only accepts mTLS
+func (r MTLSResult) Enforced() bool {
+	return r.MTLS && !r.Plaintext
+}
+
+func (r MTLSResult) String() string {
+	accepted := func(ok bool) string {
+		if ok {
+			return "accepted"
+		}
+		return "refused"
+	}
+	verdict := "mTLS enforced"
+	switch {
+	case r.Plaintext:
+		verdict = "plaintext allowed"
+	case !r.MTLS:
+		verdict = "unverified, the service doesn't respond"
+	}
+	return fmt.Sprintf("%s: %s: mTLS %s, plaintext %s: %s", r.Cluster, r.Service, accepted(r.MTLS), accepted(r.Plaintext), verdict)
+}
+
+// verifyMTLSEnforced checks on every cluster that the services of the
+// namespace refuse plaintext while they accept mTLS. For the first HTTP port
+// of every service, a request is sent from the application container of the
+// source pod, which its sidecar upgrades to mTLS, and another one from the
+// proxy container of the pod, whose traffic isn't captured by the sidecar
+// and hence is plaintext. The source pod defaults to the first pod of the
+// namespace with a sidecar and an application container.
+//
+// ErrMTLSNotEnforced is returned along with the results if a service
+// accepted plaintext, the services which respond to neither request being
+// reported as unverified.
+func (istio *Istio) verifyMTLSEnforced(ctx context.Context, sourcePod, namespace string, kubeConfigs []string) ([]MTLSResult, error) {
+	var mx sync.Mutex
+	var results []MTLSResult
+	err := forEachCluster(kubeConfigs, func(k8sconfig string) error {
+		mclient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return err
+		}
+		pods, err := mclient.KubeClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		pod, container, err := mtlsSourcePod(pods.Items, sourcePod)
+		if err != nil {
+			return err
+		}
+		services, err := mclient.KubeClient.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		cluster := clusterName(k8sconfig)
+		for _, target := range mtlsTargets(services.Items) {
+			request := func(container string) bool {
+				command := []string{"curl", "-sS", "-o", "/dev/null", "-m", strconv.Itoa(int(connectivityTimeout.Seconds())), "http://" + target}
+				_, _, err := execInPod(ctx, mclient, namespace, pod, container, command)
+				return err == nil
+			}
+			result := MTLSResult{
+				Cluster:   cluster,
+				Service:   target,
+				MTLS:      request(container),
+				Plaintext: request("istio-proxy"),
+			}
+			mx.Lock()
+			results = append(results, result)
+			mx.Unlock()
+		}
+		return nil
+	}, nil)
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Cluster != results[j].Cluster {
+			return results[i].Cluster < results[j].Cluster
+		}
+		return results[i].Service < results[j].Service
+	})
+	if err != nil {
+		return results, ErrConnectivityTestFailed(err)
+	}
+	var plaintext []string
+	for _, r := range results {
+		if r.Plaintext {
+			plaintext = append(plaintext, fmt.Sprintf("%s: %s", r.Cluster, r.Service))
+		}
+	}
+	if len(plaintext) > 0 {
+		return results, ErrMTLSNotEnforced(namespace, plaintext)
+	}
+	return results, nil
+}
+
+// mtlsSourcePod returns the pod the requests are sent from along with its
+// application container, the named pod or the first running pod with a
+// sidecar and an application container
+func mtlsSourcePod(pods []corev1.Pod, name string) (string, string, error) {
+	injected := map[string]bool{}
+	for _, pod := range proxyPods(pods) {
+		injected[pod] = true
+	}
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+	for i := range pods {
+		pod := &pods[i]
+		if name != "" && pod.Name != name {
+			continue
+		}
+		if !injected[pod.Name] {
+			if name != "" {
+				return "", "", fmt.Errorf("pod %s is not running with a sidecar", name)
+			}
+			continue
+		}
+		container, err := sourceContainer(pod)
+		if err != nil {
+			if name != "" {
+				return "", "", err
+			}
+			continue
+		}
+		return pod.Name, container, nil
+	}
+	if name != "" {
+		return "", "", fmt.Errorf("pod %s not found", name)
+	}
+	return "", "", fmt.Errorf("no running pod with a sidecar and an application container")
+}
+
+// mtlsTargets returns the host:port of the first HTTP port of every service,
+// the protocol being the one Istio selects from the app protocol or the name
+// of the port. The headless services and the ones without HTTP port are left
+// out.
+func mtlsTargets(services []corev1.Service) []string {
+	var targets []string
+	for _, svc := range services {
+		if svc.Spec.ClusterIP == corev1.ClusterIPNone {
+			continue
+		}
+		for _, port := range svc.Spec.Ports {
+			if port.Protocol != "" && port.Protocol != corev1.ProtocolTCP {
+				continue
+			}
+			protocol := port.Name
+			if port.AppProtocol != nil {
+				protocol = *port.AppProtocol
+			}
+			protocol, _, _ = strings.Cut(strings.ToLower(protocol), "-")
+			if protocol != "http" && protocol != "http2" {
+				continue
+			}
+			targets = append(targets, fmt.Sprintf("%s:%d", svc.Name, port.Port))
+			break
+		}
+	}
+	sort.Strings(targets)
+	return targets
+}
@@ -0,0 +1,90 @@
+package istio
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMTLSTargets(t *testing.T) {
+	grpc := "grpc"
+	http := "http"
+	service := func(name, clusterIP string, ports ...corev1.ServicePort) corev1.Service {
+		return corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       corev1.ServiceSpec{ClusterIP: clusterIP, Ports: ports},
+		}
+	}
+	services := []corev1.Service{
+		service("reviews", "10.0.0.2", corev1.ServicePort{Name: "tcp-metrics", Port: 9090}, corev1.ServicePort{Name: "http", Port: 9080}),
+		service("httpbin", "10.0.0.1", corev1.ServicePort{Name: "http-web", Port: 8000}),
+		service("web", "10.0.0.3", corev1.ServicePort{Name: "web", Port: 80, AppProtocol: &http}),
+		service("orders", "10.0.0.4", corev1.ServicePort{Name: "api", Port: 9000, AppProtocol: &grpc}),
+		service("dns", "10.0.0.5", corev1.ServicePort{Name: "http-dns", Port: 53, Protocol: corev1.ProtocolUDP}),
+		service("mysql", corev1.ClusterIPNone, corev1.ServicePort{Name: "http", Port: 3306}),
+	}
+	want := []string{"httpbin:8000", "reviews:9080", "web:80"}
+	if got := mtlsTargets(services); !reflect.DeepEqual(got, want) {
+		t.Errorf("mtlsTargets() = %v, want %v", got, want)
+	}
+}
+
+func TestMTLSSourcePod(t *testing.T) {
+	pod := func(name string, injected bool, containers ...string) corev1.Pod {
+		p := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}, Status: corev1.PodStatus{Phase: corev1.PodRunning}}
+		if injected {
+			p.Annotations = map[string]string{"sidecar.istio.io/status": "{}"}
+		}
+		for _, c := range containers {
+			p.Spec.Containers = append(p.Spec.Containers, corev1.Container{Name: c})
+		}
+		return p
+	}
+	pods := []corev1.Pod{
+		pod("sleep", true, "sleep", "istio-proxy"),
+		pod("gateway", true, "istio-proxy"),
+		pod("legacy", false, "legacy"),
+	}
+	tests := []struct {
+		name          string
+		source        string
+		wantPod       string
+		wantContainer string
+		wantErr       bool
+	}{
+		{name: "first injected pod", wantPod: "sleep", wantContainer: "sleep"},
+		{name: "named pod", source: "sleep", wantPod: "sleep", wantContainer: "sleep"},
+		{name: "without application container", source: "gateway", wantErr: true},
+		{name: "without sidecar", source: "legacy", wantErr: true},
+		{name: "missing", source: "curl", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotPod, gotContainer, err := mtlsSourcePod(pods, tt.source)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("mtlsSourcePod() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotPod != tt.wantPod || gotContainer != tt.wantContainer {
+				t.Errorf("mtlsSourcePod() = %s, %s, want %s, %s", gotPod, gotContainer, tt.wantPod, tt.wantContainer)
+			}
+		})
+	}
+}
+
+func TestMTLSResultEnforced(t *testing.T) {
+	tests := []struct {
+		result MTLSResult
+		want   bool
+	}{
+		{MTLSResult{MTLS: true}, true},
+		{MTLSResult{MTLS: true, Plaintext: true}, false},
+		{MTLSResult{}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.result.Enforced(); got != tt.want {
+			t.Errorf("%s Enforced() = %v, want %v", tt.result, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package istio
+
+import (
+	"fmt"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+)
+
+// supportedMinorVersions is the number of the latest minor versions of Istio
+// supported upstream, a minor version reaching its end of life once the
+// third minor version after it is released
+const supportedMinorVersions = 3
+
+// versionSupport is where a version of Istio stands in the support window of
+// the available releases
+type versionSupport struct {
+	Version string
+
+	// EOL reports whether the minor version is no longer supported, NearEOL
+	// whether it is the oldest supported one, its support ending with the
+	// next minor release
+	EOL     bool
+	NearEOL bool
+
+	// Recommended is the latest available release
+	Recommended string
+}
+
+// versionSupportOf places the version in the support window of the available
+// versions. The version is reported as supported when either it or the
+// available versions can't be compared, such as a custom tag.
+func versionSupportOf(version string, available []adapter.Version) versionSupport {
+	s := versionSupport{Version: version}
+	target, err := parseVersionNumbers(version)
+	if err != nil {
+		return s
+	}
+	for _, v := range available {
+		if _, err := parseVersionNumbers(string(v)); err != nil {
+			continue
+		}
+		if s.Recommended == "" {
+			s.Recommended = string(v)
+			continue
+		}
+		if newer, _ := newerVersion(string(v), s.Recommended); newer {
+			s.Recommended = string(v)
+		}
+	}
+	if s.Recommended == "" {
+		return s
+	}
+	latest, _ := parseVersionNumbers(s.Recommended)
+	switch {
+	case target[0] < latest[0] || (target[0] == latest[0] && latest[1]-target[1] >= supportedMinorVersions):
+		s.EOL = true
+	case target[0] == latest[0] && latest[1]-target[1] == supportedMinorVersions-1:
+		s.NearEOL = true
+	}
+	return s
+}
+
+// Supported reports whether the version is supported and not about to reach
+// its end of life
+func (s versionSupport) Supported() bool {
+	return !s.EOL && !s.NearEOL
+}
+
+// String describes the support of the version for the event details
+func (s versionSupport) String() string {
+	switch {
+	case s.EOL:
+		return fmt.Sprintf("Istio %s reached its end of life, its minor version no longer gets security fixes. Istio %s is the recommended supported version.", s.Version, s.Recommended)
+	case s.NearEOL:
+		return fmt.Sprintf("Istio %s is near its end of life, the support of its minor version ends with the next minor release. Istio %s is the recommended supported version.", s.Version, s.Recommended)
+	}
+	return fmt.Sprintf("Istio %s is supported.", s.Version)
+}
+
+// label is the support of the version as listed along with the version, ""
+// if it is supported
+func (s versionSupport) label() string {
+	switch {
+	case s.EOL:
+		return "end of life"
+	case s.NearEOL:
+		return "near end of life"
+	}
+	return ""
+}
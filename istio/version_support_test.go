@@ -0,0 +1,70 @@
+package istio
+
+import (
+	"testing"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+)
+
+func TestVersionSupportOf(t *testing.T) {
+	available := []adapter.Version{"1.19.10", "1.20.8", "1.21.5", "1.22.0", "1.22.3", "1.22.3-rc.0"}
+	tests := []struct {
+		name      string
+		version   string
+		available []adapter.Version
+		want      versionSupport
+	}{
+		{
+			name:      "latest minor version",
+			version:   "1.22.0",
+			available: available,
+			want:      versionSupport{Version: "1.22.0", Recommended: "1.22.3"},
+		},
+		{
+			name:      "previous minor version",
+			version:   "1.21.5",
+			available: available,
+			want:      versionSupport{Version: "1.21.5", Recommended: "1.22.3"},
+		},
+		{
+			name:      "oldest supported minor version",
+			version:   "1.20.8",
+			available: available,
+			want:      versionSupport{Version: "1.20.8", NearEOL: true, Recommended: "1.22.3"},
+		},
+		{
+			name:      "end of life",
+			version:   "1.19.10",
+			available: available,
+			want:      versionSupport{Version: "1.19.10", EOL: true, Recommended: "1.22.3"},
+		},
+		{
+			name:      "previous major version",
+			version:   "0.8.0",
+			available: available,
+			want:      versionSupport{Version: "0.8.0", EOL: true, Recommended: "1.22.3"},
+		},
+		{
+			name:    "releases not listed",
+			version: "1.10.0",
+			want:    versionSupport{Version: "1.10.0"},
+		},
+		{
+			name:      "custom tag",
+			version:   "master",
+			available: available,
+			want:      versionSupport{Version: "master"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := versionSupportOf(tt.version, tt.available)
+			if got != tt.want {
+				t.Errorf("versionSupportOf() = %+v, want %+v", got, tt.want)
+			}
+			if got.Supported() != (!tt.want.EOL && !tt.want.NearEOL) {
+				t.Errorf("Supported() = %v, want %v", got.Supported(), !tt.want.EOL && !tt.want.NearEOL)
+			}
+		})
+	}
+}
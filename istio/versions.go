@@ -0,0 +1,62 @@
+package istio
+
+import (
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/layer5io/meshkit/utils"
+)
+
+// releaseCacheTTL is how long the fetched Istio releases are reused before
+// the release list is fetched again
+const releaseCacheTTL = 10 * time.Minute
+
+// stableRelease matches the tags of the Istio releases the adapter installs,
+// the alpha, beta and rc releases are left out
+var stableRelease = regexp.MustCompile(`^\d+\.\d+\.\d+$`)
+
+// fetchReleaseTags returns the release tags of Istio sorted from the oldest
+// to the latest release
+var fetchReleaseTags = func() ([]string, error) {
+	return utils.GetLatestReleaseTagsSorted("istio", "istio")
+}
+
+var releaseCache struct {
+	sync.Mutex
+	versions []adapter.Version
+	fetched  time.Time
+}
+
+// listAvailableVersions returns the stable Istio releases, sorted from the
+// oldest to the latest, which the adapter can install. The releases are
+// fetched from GitHub and cached for releaseCacheTTL.
+func listAvailableVersions() ([]adapter.Version, error) {
+	releaseCache.Lock()
+	defer releaseCache.Unlock()
+	if releaseCache.versions != nil && time.Since(releaseCache.fetched) < releaseCacheTTL {
+		return releaseCache.versions, nil
+	}
+
+	tags, err := fetchReleaseTags()
+	if err != nil {
+		return nil, ErrFetchIstioVersions
+	}
+	seen := map[string]bool{}
+	versions := []adapter.Version{}
+	for _, tag := range tags {
+		if !stableRelease.MatchString(tag) || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		versions = append(versions, adapter.Version(tag))
+	}
+	if len(versions) == 0 {
+		return nil, ErrFetchIstioVersions
+	}
+
+	releaseCache.versions = versions
+	releaseCache.fetched = time.Now()
+	return versions, nil
+}
@@ -0,0 +1,45 @@
+package istio
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+)
+
+func TestListAvailableVersions(t *testing.T) {
+	defer func(f func() ([]string, error)) { fetchReleaseTags = f }(fetchReleaseTags)
+	resetCache := func() {
+		releaseCache.versions = nil
+		releaseCache.fetched = time.Time{}
+	}
+	defer resetCache()
+	resetCache()
+
+	fetches := 0
+	fetchReleaseTags = func() ([]string, error) {
+		fetches++
+		return []string{"1.19.0", "1.19.0", "1.20.0-beta.1", "1.20.0-rc.0", "1.20.0", "1.20.1"}, nil
+	}
+	got, err := listAvailableVersions()
+	if err != nil {
+		t.Fatalf("listAvailableVersions() error = %v", err)
+	}
+	want := []adapter.Version{"1.19.0", "1.20.0", "1.20.1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("listAvailableVersions() = %v, want %v", got, want)
+	}
+	if _, err := listAvailableVersions(); err != nil || fetches != 1 {
+		t.Errorf("listAvailableVersions() fetched the releases %d times, want the cached releases reused", fetches)
+	}
+
+	resetCache()
+	fetchReleaseTags = func() ([]string, error) {
+		return nil, fmt.Errorf("github.com unreachable")
+	}
+	if _, err := listAvailableVersions(); err != ErrFetchIstioVersions {
+		t.Errorf("listAvailableVersions() error = %v, want ErrFetchIstioVersions", err)
+	}
+}
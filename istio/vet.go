@@ -2,11 +2,13 @@ package istio
 
 import (
 	"fmt"
+	"math/rand"
 	"reflect"
 	"strings"
 	"sync"
 	"time"
 
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
 	"github.com/aspenmesh/istio-vet/pkg/istioclient"
 	"github.com/aspenmesh/istio-vet/pkg/vetter"
 	"github.com/aspenmesh/istio-vet/pkg/vetter/applabel"
@@ -26,6 +28,45 @@ import (
 
 const istioVetSyncTimeout = 10 // istio vet sync timeout in seconds
 
+// vetEventInterval is the least time between two events of the findings of a
+// cluster, a random jitter of up to half of it is added so that the clusters
+// vetted concurrently don't emit in bursts
+var vetEventInterval = 20 * time.Millisecond
+
+// JSONFormat is the value of the result format property which makes istio-vet
+// stream its findings as a JSON array once all the vetters ran
+const JSONFormat = "json"
+
+// Severities of the istio-vet findings
+const (
+	VetSeverityInfo    = "INFO"
+	VetSeverityWarning = "WARNING"
+	VetSeverityError   = "ERROR"
+)
+
+// VetFinding is a note generated by an istio-vet vetter
+type VetFinding struct {
+	Cluster  string `json:"cluster"`
+	Vetter   string `json:"vetter"`
+	Type     string `json:"type"`
+	Severity string `json:"severity"`
+
+	// Resource is the kind and name of the resource the note is about,
+	// e.g. pod/reviews-v1-5d8f, if any
+	Resource  string `json:"resource,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+
+	Summary string `json:"summary"`
+	Message string `json:"message"`
+}
+
+// vetResourceKinds maps the note attributes naming a resource to its kind
+var vetResourceKinds = map[string]string{
+	"pod_name":     "pod",
+	"service_name": "service",
+	"vs_name":      "virtualservice",
+}
+
 type metaInformerFactory struct {
 	k8s   informers.SharedInformerFactory
 	istio istioinformer.SharedInformerFactory
@@ -41,180 +82,275 @@ func (m *metaInformerFactory) Istio() istioinformer.SharedInformerFactory {
 // RunVet runs istio-vet
 func (istio *Istio) RunVet(ch chan<- *meshes.EventsResponse, kubeconfigs []string) {
 	defer close(ch)
+	_, _ = istio.vet(ch, kubeconfigs)
+}
+
+// RunVetStructured runs istio-vet and returns the notes of the vetters as
+// findings instead of streaming them. The findings of the clusters which
+// could be vetted are returned along with ErrIstioVet if any cluster could
+// not be vetted.
+func (istio *Istio) RunVetStructured(kubeConfigs []string) ([]VetFinding, error) {
+	ch := make(chan *meshes.EventsResponse)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range ch {
+		}
+	}()
+	findings, err := istio.vet(ch, kubeConfigs)
+	close(ch)
+	<-done
+	return findings, err
+}
+
+// vet runs the vetters on every cluster, streaming the notes as events to the
+// channel, the similar notes of a vetter collapsed into a single event, and
+// returns all the notes as findings
+func (istio *Istio) vet(ch chan<- *meshes.EventsResponse, kubeconfigs []string) ([]VetFinding, error) {
 	var wg sync.WaitGroup
+	var mx sync.Mutex
+	var errs []error
+	var findings []VetFinding
 	for _, k8sconfig := range kubeconfigs {
 		wg.Add(1)
 		go func(k8sconfig string) {
 			defer wg.Done()
-			mclient, err := mesherykube.New([]byte(k8sconfig))
+			f, err := istio.vetCluster(ch, k8sconfig)
+			mx.Lock()
+			defer mx.Unlock()
+			findings = append(findings, f...)
 			if err != nil {
-				e := &meshes.EventsResponse{
-					Component:     internalconfig.ServerConfig["type"],
-					ComponentName: internalconfig.ServerConfig["name"],
-				}
-				e.EventType = meshes.EventType_ERROR
-				e.Details = ErrCreatingIstioClient(err).Error()
-				e.Summary = "Unable to create k8s client"
-				e.ErrorCode = errors.GetCode(err)
-				e.ProbableCause = errors.GetCause(err)
-				e.SuggestedRemediation = errors.GetRemedy(err)
-				ch <- e
-			}
-			istioClient, err := istioclient.New(&mclient.RestConfig)
-			if err != nil {
-				e := &meshes.EventsResponse{
-					Component:     internalconfig.ServerConfig["type"],
-					ComponentName: internalconfig.ServerConfig["name"],
-				}
-				e.EventType = meshes.EventType_ERROR
-				e.Details = ErrCreatingIstioClient(err).Error()
-				e.Summary = "Unable to create istio client"
-				e.ErrorCode = errors.GetCode(err)
-				e.ProbableCause = errors.GetCause(err)
-				e.SuggestedRemediation = errors.GetRemedy(err)
-				ch <- e
+				errs = append(errs, err)
 			}
+		}(k8sconfig)
+	}
+	wg.Wait()
+	if len(errs) == 0 {
+		return findings, nil
+	}
+	return findings, ErrIstioVet(mergeErrors(errs))
+}
 
-			kubeInformerFactory := informers.NewSharedInformerFactory(mclient.KubeClient, 0)
-			istioInformerFactory := istioinformer.NewSharedInformerFactory(istioClient, 0)
-			informerFactory := &metaInformerFactory{
-				k8s:   kubeInformerFactory,
-				istio: istioInformerFactory,
-			}
+// vetCluster runs the vetters on a single cluster
+func (istio *Istio) vetCluster(ch chan<- *meshes.EventsResponse, k8sconfig string) ([]VetFinding, error) {
+	cluster := clusterName(k8sconfig)
+	mclient, err := mesherykube.New([]byte(k8sconfig))
+	if err != nil {
+		err = ErrCreatingIstioClient(err)
+		ch <- vetErrorEvent("Unable to create k8s client", err)
+		return nil, err
+	}
+	istioClient, err := istioclient.New(&mclient.RestConfig)
+	if err != nil {
+		err = ErrCreatingIstioClient(err)
+		ch <- vetErrorEvent("Unable to create istio client", err)
+		return nil, err
+	}
 
-			vList := []vetter.Vetter{
-				vetter.Vetter(podsinmesh.NewVetter(informerFactory)),
-				vetter.Vetter(meshversion.NewVetter(informerFactory)),
-				vetter.Vetter(applabel.NewVetter(informerFactory)),
-				vetter.Vetter(serviceportprefix.NewVetter(informerFactory)),
-				vetter.Vetter(serviceassociation.NewVetter(informerFactory)),
-				vetter.Vetter(danglingroutedestinationhost.NewVetter(informerFactory)),
-				vetter.Vetter(conflictingvirtualservicehost.NewVetter(informerFactory)),
-			}
+	kubeInformerFactory := informers.NewSharedInformerFactory(mclient.KubeClient, 0)
+	istioInformerFactory := istioinformer.NewSharedInformerFactory(istioClient, 0)
+	informerFactory := &metaInformerFactory{
+		k8s:   kubeInformerFactory,
+		istio: istioInformerFactory,
+	}
 
-			stopCh := make(chan struct{})
+	vList := []vetter.Vetter{
+		vetter.Vetter(podsinmesh.NewVetter(informerFactory)),
+		vetter.Vetter(meshversion.NewVetter(informerFactory)),
+		vetter.Vetter(applabel.NewVetter(informerFactory)),
+		vetter.Vetter(serviceportprefix.NewVetter(informerFactory)),
+		vetter.Vetter(serviceassociation.NewVetter(informerFactory)),
+		vetter.Vetter(danglingroutedestinationhost.NewVetter(informerFactory)),
+		vetter.Vetter(conflictingvirtualservicehost.NewVetter(informerFactory)),
+	}
 
-			kubeInformerFactory.Start(stopCh)
-			oks, timedout := completeBefore(istioVetSyncTimeout, func() map[reflect.Type]bool {
-				return kubeInformerFactory.WaitForCacheSync(stopCh)
-			})
-			if timedout {
-				e := &meshes.EventsResponse{
-					Component:     internalconfig.ServerConfig["type"],
-					ComponentName: internalconfig.ServerConfig["name"],
-				}
-				e.EventType = meshes.EventType_ERROR
-				e.Details = ErrIstioVetSync(fmt.Errorf("istio service mesh was either not found or is not deployed")).Error()
-				e.Summary = "Failed to sync: Request timed out"
-				e.ErrorCode = errors.GetCode(err)
-				e.ProbableCause = errors.GetCause(err)
-				e.SuggestedRemediation = errors.GetRemedy(err)
-				ch <- e
-				close(stopCh)
-				return
-			}
-			for inf, ok := range oks {
-				if !ok {
-					e := &meshes.EventsResponse{
-						Component:     internalconfig.ServerConfig["type"],
-						ComponentName: internalconfig.ServerConfig["name"],
-					}
-					e.EventType = meshes.EventType_ERROR
-					e.Details = ErrIstioVetSync(fmt.Errorf("%s", inf)).Error()
-					e.Summary = "Failed to sync"
-					e.ErrorCode = errors.GetCode(err)
-					e.ProbableCause = errors.GetCause(err)
-					e.SuggestedRemediation = errors.GetRemedy(err)
-					ch <- e
-					return
-				}
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	for _, factory := range []interface {
+		Start(<-chan struct{})
+		WaitForCacheSync(<-chan struct{}) map[reflect.Type]bool
+	}{kubeInformerFactory, istioInformerFactory} {
+		factory.Start(stopCh)
+		oks, timedout := completeBefore(istioVetSyncTimeout, func() map[reflect.Type]bool {
+			return factory.WaitForCacheSync(stopCh)
+		})
+		if timedout {
+			err := ErrIstioVetSync(fmt.Errorf("istio service mesh was either not found or is not deployed"))
+			ch <- vetErrorEvent("Failed to sync: Request timed out", err)
+			return nil, err
+		}
+		for inf, ok := range oks {
+			if !ok {
+				err := ErrIstioVetSync(fmt.Errorf("%s", inf))
+				ch <- vetErrorEvent("Failed to sync", err)
+				return nil, err
 			}
+		}
+	}
 
-			istioInformerFactory.Start(stopCh)
-			oks, timedout = completeBefore(istioVetSyncTimeout, func() map[reflect.Type]bool {
-				return istioInformerFactory.WaitForCacheSync(stopCh)
-			})
-			if timedout {
-				e := &meshes.EventsResponse{
-					Component:     internalconfig.ServerConfig["type"],
-					ComponentName: internalconfig.ServerConfig["name"],
-				}
-				e.EventType = meshes.EventType_ERROR
-				e.Details = ErrIstioVetSync(fmt.Errorf("istio service mesh was either not found or is not deployed")).Error()
-				e.Summary = "Failed to sync: Request timed out"
-				e.ErrorCode = errors.GetCode(err)
-				e.ProbableCause = errors.GetCause(err)
-				e.SuggestedRemediation = errors.GetRemedy(err)
-				ch <- e
-				close(stopCh)
-				return
+	var findings []VetFinding
+	var errs []error
+	for _, v := range vList {
+		nList, err := v.Vet()
+		if err != nil {
+			e := &meshes.EventsResponse{}
+			e.Summary = fmt.Sprintf("Vetter: %s reported error", v.Info().GetId())
+			e.Details = err.Error()
+			e.EventType = meshes.EventType_ERROR
+			ch <- e
+			errs = append(errs, fmt.Errorf("%s: vetter %s: %w", cluster, v.Info().GetId(), err))
+			continue
+		}
+		if len(nList) > 0 {
+			var vetterFindings []VetFinding
+			for i := range nList {
+				vetterFindings = append(vetterFindings, vetFinding(cluster, v.Info().GetId(), nList[i]))
 			}
-			for inf, ok := range oks {
-				if !ok {
-					e := &meshes.EventsResponse{
-						Component:     internalconfig.ServerConfig["type"],
-						ComponentName: internalconfig.ServerConfig["name"],
-					}
-					e.EventType = meshes.EventType_ERROR
-					e.Details = ErrIstioVetSync(fmt.Errorf("%s", inf)).Error()
-					e.Summary = "Failed to sync"
-					e.ErrorCode = errors.GetCode(err)
-					e.ProbableCause = errors.GetCause(err)
-					e.SuggestedRemediation = errors.GetRemedy(err)
-					ch <- e
-					return
+			findings = append(findings, vetterFindings...)
+
+			for _, c := range collapseFindings(vetterFindings) {
+				paceVetEvent()
+				e := &meshes.EventsResponse{}
+				e.Summary = c.Summary
+				e.Details = c.Message
+				if c.Count > 1 {
+					e.Summary = fmt.Sprintf("%s and %d similar findings", c.Summary, c.Count-1)
+					e.Details = strings.Join(c.Messages, "\n")
 				}
-			}
-			close(stopCh)
-
-			for _, v := range vList {
-				nList, err := v.Vet()
-				if err != nil {
-					e := &meshes.EventsResponse{}
-					e.Summary = fmt.Sprintf("Vetter: %s reported error", v.Info().GetId())
-					e.Details = err.Error()
+				switch c.Severity {
+				case VetSeverityWarning:
+					e.EventType = meshes.EventType_WARN
+				case VetSeverityError:
 					e.EventType = meshes.EventType_ERROR
-					ch <- e
-					continue
-				}
-				if len(nList) > 0 {
-					for i := range nList {
-						e := &meshes.EventsResponse{}
-
-						var ts []string
-						for k, v := range nList[i].Attr {
-							ts = append(ts, "${"+k+"}", v)
-						}
-						r := strings.NewReplacer(ts...)
-						e.Summary = r.Replace(nList[i].GetSummary())
-						e.Details = r.Replace(nList[i].GetMsg())
-						switch nList[i].GetLevel().String() {
-						case "WARNING":
-							e.EventType = meshes.EventType_WARN
-						case "ERROR":
-							e.EventType = meshes.EventType_ERROR
-						default:
-							e.EventType = meshes.EventType_INFO
-						}
-						ch <- e
-					}
-				} else {
-					e := &meshes.EventsResponse{}
-					istio.Log.Debug(fmt.Sprintf("Vetter %s ran successfully and generated no notes", v.Info().GetId()))
-					e.Summary = fmt.Sprintf("Vetter: %s ran successfully", v.Info().GetId())
-					e.Details = "No notes generated"
+				default:
 					e.EventType = meshes.EventType_INFO
-					ch <- e
 				}
+				ch <- e
 			}
-		}(k8sconfig)
+		} else {
+			e := &meshes.EventsResponse{}
+			istio.Log.Debug(fmt.Sprintf("Vetter %s ran successfully and generated no notes", v.Info().GetId()))
+			e.Summary = fmt.Sprintf("Vetter: %s ran successfully", v.Info().GetId())
+			e.Details = "No notes generated"
+			e.EventType = meshes.EventType_INFO
+			ch <- e
+		}
+	}
+	return findings, mergeErrors(errs)
+}
+
+// vetFinding converts a note of a vetter into a finding, substituting the
+// attributes of the note in its summary and message
+func vetFinding(cluster, vetterID string, note *apiv1.Note) VetFinding {
+	var ts []string
+	for k, v := range note.Attr {
+		ts = append(ts, "${"+k+"}", v)
+	}
+	r := strings.NewReplacer(ts...)
+
+	severity := note.GetLevel().String()
+	if severity != VetSeverityWarning && severity != VetSeverityError {
+		severity = VetSeverityInfo
+	}
+	f := VetFinding{
+		Cluster:   cluster,
+		Vetter:    vetterID,
+		Type:      note.GetType(),
+		Severity:  severity,
+		Namespace: note.Attr["namespace"],
+		Summary:   r.Replace(note.GetSummary()),
+		Message:   r.Replace(note.GetMsg()),
+	}
+	for _, attr := range []string{"pod_name", "service_name", "vs_name"} {
+		if name, ok := note.Attr[attr]; ok {
+			f.Resource = fmt.Sprintf("%s/%s", vetResourceKinds[attr], name)
+			break
+		}
+	}
+	return f
+}
+
+// collapsedFinding is the findings of a vetter sharing their type and the kind
+// of their resource, streamed as a single event. The embedded finding is the
+// first of them, with the highest severity among them.
+type collapsedFinding struct {
+	VetFinding
+	Count int
+
+	// Messages are the distinct messages of the findings, in their order
+	Messages []string
+}
+
+// vetSeverityRanks orders the severities of the findings
+var vetSeverityRanks = map[string]int{
+	VetSeverityInfo:    0,
+	VetSeverityWarning: 1,
+	VetSeverityError:   2,
+}
+
+// collapseFindings collapses the findings sharing the cluster, the vetter, the
+// type and the resource kind, such as a missing sidecar in many pods, in the
+// order of their first occurrence
+func collapseFindings(findings []VetFinding) []collapsedFinding {
+	var collapsed []collapsedFinding
+	index := map[string]int{}
+	for _, f := range findings {
+		kind, _, _ := strings.Cut(f.Resource, "/")
+		key := strings.Join([]string{f.Cluster, f.Vetter, f.Type, kind}, "\x00")
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(collapsed)
+			collapsed = append(collapsed, collapsedFinding{VetFinding: f, Count: 1, Messages: []string{f.Message}})
+			continue
+		}
+		c := &collapsed[i]
+		c.Count++
+		if vetSeverityRanks[f.Severity] > vetSeverityRanks[c.Severity] {
+			c.Severity = f.Severity
+		}
+		distinct := true
+		for _, m := range c.Messages {
+			if m == f.Message {
+				distinct = false
+				break
+			}
+		}
+		if distinct {
+			c.Messages = append(c.Messages, f.Message)
+		}
+	}
+	return collapsed
+}
+
+// paceVetEvent waits for the vetEventInterval, plus its jitter, before the
+// next event of the findings is emitted
+func paceVetEvent() {
+	if vetEventInterval <= 0 {
+		return
+	}
+	time.Sleep(vetEventInterval + time.Duration(rand.Int63n(int64(vetEventInterval)/2+1)))
+}
+
+func vetErrorEvent(summary string, err error) *meshes.EventsResponse {
+	return &meshes.EventsResponse{
+		Component:            internalconfig.ServerConfig["type"],
+		ComponentName:        internalconfig.ServerConfig["name"],
+		EventType:            meshes.EventType_ERROR,
+		Summary:              summary,
+		Details:              err.Error(),
+		ErrorCode:            errors.GetCode(err),
+		ProbableCause:        errors.GetCause(err),
+		SuggestedRemediation: errors.GetRemedy(err),
 	}
 }
 
-// StreamWarn streams a warning message to the channel
+// StreamWarn records the warning as the status of its operation and streams
+// it to the channel
 func (istio *Istio) StreamWarn(e *meshes.EventsResponse, err error) {
 	istio.Log.Warn(err)
 	e.EventType = meshes.EventType_WARN
+	istio.recordStreamed(e)
 	istio.EventStreamer.Publish(e)
 }
 
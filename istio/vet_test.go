@@ -0,0 +1,100 @@
+package istio
+
+import (
+	"testing"
+
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+)
+
+func TestVetFinding(t *testing.T) {
+	tests := []struct {
+		name string
+		note *apiv1.Note
+		want VetFinding
+	}{
+		{
+			name: "warning about a pod",
+			note: &apiv1.Note{
+				Type:    "missing-sidecar",
+				Summary: "Missing sidecar in ${pod_name}",
+				Msg:     "Pod ${pod_name} in ${namespace} has no sidecar",
+				Level:   apiv1.NoteLevel_WARNING,
+				Attr:    map[string]string{"pod_name": "reviews-v1", "namespace": "bookinfo"},
+			},
+			want: VetFinding{
+				Cluster:   "kind",
+				Vetter:    "PodsInMesh",
+				Type:      "missing-sidecar",
+				Severity:  VetSeverityWarning,
+				Resource:  "pod/reviews-v1",
+				Namespace: "bookinfo",
+				Summary:   "Missing sidecar in reviews-v1",
+				Message:   "Pod reviews-v1 in bookinfo has no sidecar",
+			},
+		},
+		{
+			name: "error about a virtual service",
+			note: &apiv1.Note{
+				Summary: "Conflicting hosts",
+				Level:   apiv1.NoteLevel_ERROR,
+				Attr:    map[string]string{"vs_name": "reviews", "namespace": "bookinfo"},
+			},
+			want: VetFinding{
+				Cluster:   "kind",
+				Vetter:    "PodsInMesh",
+				Severity:  VetSeverityError,
+				Resource:  "virtualservice/reviews",
+				Namespace: "bookinfo",
+				Summary:   "Conflicting hosts",
+			},
+		},
+		{
+			name: "unset level without resource",
+			note: &apiv1.Note{Summary: "Mesh version"},
+			want: VetFinding{
+				Cluster:  "kind",
+				Vetter:   "PodsInMesh",
+				Severity: VetSeverityInfo,
+				Summary:  "Mesh version",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := vetFinding("kind", "PodsInMesh", tt.note); got != tt.want {
+				t.Errorf("vetFinding() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollapseFindings(t *testing.T) {
+	finding := func(vetterID, resource, severity, message string) VetFinding {
+		return VetFinding{Cluster: "kind", Vetter: vetterID, Type: "note", Severity: severity, Resource: resource, Summary: "Summary of " + resource, Message: message}
+	}
+	findings := []VetFinding{
+		finding("PodsInMesh", "pod/reviews-v1", VetSeverityInfo, "missing sidecar"),
+		finding("PodsInMesh", "service/reviews", VetSeverityWarning, "unnamed port"),
+		finding("PodsInMesh", "pod/reviews-v2", VetSeverityError, "missing sidecar"),
+		finding("PodsInMesh", "pod/ratings-v1", VetSeverityWarning, "init container failed"),
+		finding("AppLabel", "pod/ratings-v1", VetSeverityWarning, "missing app label"),
+	}
+
+	got := collapseFindings(findings)
+	if len(got) != 3 {
+		t.Fatalf("collapseFindings() = %+v, want 3 collapsed findings", got)
+	}
+	pods := got[0]
+	if pods.Count != 3 || pods.Summary != "Summary of pod/reviews-v1" || pods.Severity != VetSeverityError {
+		t.Errorf("collapseFindings() pods = %+v, want the 3 pod findings with the first summary and ERROR severity", pods)
+	}
+	if len(pods.Messages) != 2 || pods.Messages[0] != "missing sidecar" || pods.Messages[1] != "init container failed" {
+		t.Errorf("collapseFindings() pod messages = %v, want the distinct messages in order", pods.Messages)
+	}
+	if got[1].Count != 1 || got[1].Resource != "service/reviews" {
+		t.Errorf("collapseFindings() = %+v, want the service finding on its own", got[1])
+	}
+	if got[2].Count != 1 || got[2].Vetter != "AppLabel" {
+		t.Errorf("collapseFindings() = %+v, want the finding of another vetter on its own", got[2])
+	}
+}
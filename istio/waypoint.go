@@ -0,0 +1,185 @@
+package istio
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/layer5io/meshery-adapter-library/status"
+	"github.com/layer5io/meshery-istio/internal/config"
+	"github.com/layer5io/meshkit/utils"
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	kubeerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// ambientMinVersion is the first Istio release with ambient mode GA
+	ambientMinVersion = "1.24"
+
+	// waypointGatewayClass is the class of the Gateways istiod deploys as
+	// waypoint proxies
+	waypointGatewayClass = "istio-waypoint"
+
+	// useWaypointLabel binds the workloads of a namespace to a waypoint
+	useWaypointLabel = "istio.io/use-waypoint"
+)
+
+var gatewayResource = schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "gateways"}
+
+// ambientSupported returns ErrAmbientUnsupportedVersion if the version
+// predates ambient mode GA
+func ambientSupported(version string) error {
+	v := minorVersionRegex.FindStringSubmatch(version)
+	m := minorVersionRegex.FindStringSubmatch(ambientMinVersion)
+	if v == nil {
+		return ErrAmbientUnsupportedVersion(version)
+	}
+	major, _ := strconv.Atoi(v[1])
+	minor, _ := strconv.Atoi(v[2])
+	minMajor, _ := strconv.Atoi(m[1])
+	minMinor, _ := strconv.Atoi(m[2])
+	if major < minMajor || (major == minMajor && minor < minMinor) {
+		return ErrAmbientUnsupportedVersion(version)
+	}
+	return nil
+}
+
+// waypointValues are the values of the waypoint template
+type waypointValues struct {
+	Name      string
+	Namespace string
+
+	// For is the traffic the waypoint handles, "service" for the waypoint
+	// of a namespace and "workload" for the one of a service account
+	For string
+
+	// ServiceAccount is the service account the waypoint is deployed for,
+	// the waypoint is the one of the namespace when empty
+	ServiceAccount string
+}
+
+// newWaypointValues returns the waypoint of the namespace, or of the service
+// account of the operation if set
+func newWaypointValues(namespace string, props map[string]string) (*waypointValues, error) {
+	values := &waypointValues{
+		Name:           "waypoint",
+		Namespace:      namespace,
+		For:            "service",
+		ServiceAccount: strings.TrimSpace(props[config.WaypointServiceAccount]),
+	}
+	if values.ServiceAccount != "" {
+		if errs := validation.IsDNS1123Subdomain(values.ServiceAccount); len(errs) > 0 {
+			return nil, ErrDeployWaypoint(fmt.Errorf("invalid service account %q: %s", values.ServiceAccount, strings.Join(errs, ", ")))
+		}
+		// The waypoint deployment and service are named after the Gateway,
+		// leave room for the -waypoint suffix within 63 characters
+		name := workloadName(map[string]string{"app": values.ServiceAccount})
+		if len(name) > 54 {
+			name = strings.Trim(name[:54], "-")
+		}
+		values.Name = name + "-waypoint"
+		values.For = "workload"
+	}
+	return values, nil
+}
+
+// deployWaypoint deploys or removes the waypoint proxy. The waypoint of a
+// namespace is used by all its services, the namespace being labeled with
+// istio.io/use-waypoint, while the workloads of a service account opt in to
+// the waypoint of the service account with the same label.
+func (istio *Istio) deployWaypoint(ctx context.Context, del bool, values *waypointValues, templates []adapter.Template, kubeconfigs []string) (string, error) {
+	st := status.Deploying
+	if del {
+		st = status.Removing
+	}
+
+	if err := gatewayAPIInstalled(kubeconfigs); err != nil {
+		return st, err
+	}
+	for _, template := range templates {
+		contents, err := utils.ReadFileSource(string(template))
+		if err != nil {
+			return st, ErrDeployWaypoint(err)
+		}
+		contents, err = renderTemplate(contents, values)
+		if err != nil {
+			return st, ErrDeployWaypoint(err)
+		}
+		if err := istio.applyManifest(ctx, []byte(contents), del, values.Namespace, kubeconfigs); err != nil {
+			return st, ErrDeployWaypoint(err)
+		}
+	}
+	if values.ServiceAccount == "" {
+		if err := labelWaypointNamespace(values.Namespace, values.Name, del, kubeconfigs); err != nil {
+			return st, ErrDeployWaypoint(err)
+		}
+	}
+
+	if del {
+		return status.Removed, nil
+	}
+	return status.Deployed, nil
+}
+
+// labelWaypointNamespace binds the namespace to the waypoint, or unbinds it
+func labelWaypointNamespace(namespace, waypoint string, del bool, kubeconfigs []string) error {
+	return forEachCluster(kubeconfigs, func(k8sconfig string) error {
+		mclient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return err
+		}
+		ns, err := mclient.KubeClient.CoreV1().Namespaces().Get(context.TODO(), namespace, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if ns.Labels == nil {
+			ns.Labels = map[string]string{}
+		}
+		if del {
+			if ns.Labels[useWaypointLabel] != waypoint {
+				return nil
+			}
+			delete(ns.Labels, useWaypointLabel)
+		} else {
+			ns.Labels[useWaypointLabel] = waypoint
+		}
+		_, err = mclient.KubeClient.CoreV1().Namespaces().Update(context.TODO(), ns, metav1.UpdateOptions{})
+		return err
+	}, nil)
+}
+
+// removeWaypoints removes the waypoints of all the namespaces, which are left
+// behind by the uninstall of Istio as they are Gateway API resources. The
+// clusters without the Gateway API have no waypoint.
+func removeWaypoints(kubeconfigs []string) error {
+	return forEachCluster(kubeconfigs, func(k8sconfig string) error {
+		mclient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return err
+		}
+		gateways, err := mclient.DynamicKubeClient.Resource(gatewayResource).Namespace(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+		if kubeerror.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for _, gateway := range gateways.Items {
+			class, _, _ := unstructured.NestedString(gateway.Object, "spec", "gatewayClassName")
+			if class != waypointGatewayClass {
+				continue
+			}
+			err := mclient.DynamicKubeClient.Resource(gatewayResource).Namespace(gateway.GetNamespace()).Delete(context.TODO(), gateway.GetName(), metav1.DeleteOptions{})
+			if err != nil && !kubeerror.IsNotFound(err) {
+				return err
+			}
+		}
+		return nil
+	}, nil)
+}
@@ -0,0 +1,103 @@
+package istio
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/layer5io/meshery-istio/internal/config"
+	"github.com/layer5io/meshkit/errors"
+	"gopkg.in/yaml.v2"
+)
+
+func TestAmbientSupported(t *testing.T) {
+	for version, supported := range map[string]bool{
+		"1.24.0":  true,
+		"v1.25.2": true,
+		"2.0.0":   true,
+		"1.23.3":  false,
+		"1.9.0":   false,
+		"latest":  false,
+	} {
+		err := ambientSupported(version)
+		if (err == nil) != supported {
+			t.Errorf("ambientSupported(%s) error = %v, want supported %v", version, err, supported)
+		}
+		if err != nil && errors.GetCode(err) != ErrAmbientUnsupportedVersionCode {
+			t.Errorf("ambientSupported(%s) error code = %s, want %s", version, errors.GetCode(err), ErrAmbientUnsupportedVersionCode)
+		}
+	}
+}
+
+func TestWaypoint(t *testing.T) {
+	tmpl, err := os.ReadFile("../templates/ambient/waypoint.yaml")
+	if err != nil {
+		t.Fatalf("unable to read the waypoint template: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		props    map[string]string
+		wantName string
+		wantFor  string
+		wantErr  bool
+	}{
+		{
+			name:     "namespace",
+			props:    map[string]string{config.WaypointServiceAccount: ""},
+			wantName: "waypoint",
+			wantFor:  "service",
+		},
+		{
+			name:     "service account",
+			props:    map[string]string{config.WaypointServiceAccount: "bookinfo-reviews"},
+			wantName: "bookinfo-reviews-waypoint",
+			wantFor:  "workload",
+		},
+		{
+			name:     "long service account",
+			props:    map[string]string{config.WaypointServiceAccount: strings.Repeat("a", 70)},
+			wantName: strings.Repeat("a", 54) + "-waypoint",
+			wantFor:  "workload",
+		},
+		{
+			name:    "invalid service account",
+			props:   map[string]string{config.WaypointServiceAccount: "Reviews_SA"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := newWaypointValues("bookinfo", tt.props)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newWaypointValues() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			rendered, err := renderTemplate(string(tmpl), values)
+			if err != nil {
+				t.Fatalf("renderTemplate() error = %v", err)
+			}
+			var gateway struct {
+				Metadata struct {
+					Name      string            `yaml:"name"`
+					Namespace string            `yaml:"namespace"`
+					Labels    map[string]string `yaml:"labels"`
+				} `yaml:"metadata"`
+				Spec struct {
+					GatewayClassName string `yaml:"gatewayClassName"`
+				} `yaml:"spec"`
+			}
+			if err := yaml.Unmarshal([]byte(rendered), &gateway); err != nil {
+				t.Fatalf("renderTemplate() generated invalid YAML: %v\n%s", err, rendered)
+			}
+			if gateway.Metadata.Name != tt.wantName || gateway.Metadata.Namespace != "bookinfo" {
+				t.Errorf("renderTemplate() name/namespace = %s/%s, want %s/bookinfo", gateway.Metadata.Name, gateway.Metadata.Namespace, tt.wantName)
+			}
+			if gateway.Metadata.Labels["istio.io/waypoint-for"] != tt.wantFor || gateway.Spec.GatewayClassName != waypointGatewayClass {
+				t.Errorf("renderTemplate() = %s, want a %s waypoint for %s", rendered, waypointGatewayClass, tt.wantFor)
+			}
+		})
+	}
+}
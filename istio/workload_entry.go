@@ -0,0 +1,115 @@
+package istio
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/layer5io/meshery-istio/internal/config"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// workloadValues are the values of the WorkloadEntry and WorkloadGroup
+// templates, the VMs joining the mesh being registered by either
+type workloadValues struct {
+	Name      string
+	Namespace string
+
+	// Address is the address of the VM, only used by the WorkloadEntry
+	Address string
+
+	// Labels are the labels of the workload, the services select the VMs
+	// with, such as app=legacy-billing
+	Labels map[string]string
+
+	ServiceAccount string
+	Network        string
+}
+
+// newWorkloadValues validates the WorkloadEntry or, if group is set, the
+// WorkloadGroup properties of the operation. The WorkloadEntry requires the
+// address of the VM and the WorkloadGroup the labels of its VMs, deleting
+// either only needs its name.
+func newWorkloadValues(namespace string, props map[string]string, group, del bool) (*workloadValues, error) {
+	values := &workloadValues{
+		Namespace:      namespace,
+		Name:           strings.TrimSpace(props[config.WorkloadName]),
+		Address:        strings.TrimSpace(props[config.WorkloadAddress]),
+		ServiceAccount: strings.TrimSpace(props[config.WorkloadServiceAccount]),
+		Network:        strings.TrimSpace(props[config.Network]),
+	}
+	if values.Name == "" {
+		return nil, ErrInvalidWorkload(fmt.Errorf("the name is required"))
+	}
+	if errs := validation.IsDNS1123Subdomain(values.Name); len(errs) > 0 {
+		return nil, ErrInvalidWorkload(fmt.Errorf("invalid name %q: %s", values.Name, strings.Join(errs, ", ")))
+	}
+	if del {
+		return values, nil
+	}
+
+	var err error
+	if values.Labels, err = parseWorkloadLabels(props[config.WorkloadLabels]); err != nil {
+		return nil, ErrInvalidWorkload(err)
+	}
+	if group {
+		values.Address = ""
+		if len(values.Labels) == 0 {
+			return nil, ErrInvalidWorkload(fmt.Errorf("the labels of the VMs of the group are required"))
+		}
+	} else {
+		if values.Address == "" {
+			return nil, ErrInvalidWorkload(fmt.Errorf("the address of the VM is required"))
+		}
+		if net.ParseIP(values.Address) == nil && len(validation.IsDNS1123Subdomain(values.Address)) > 0 {
+			return nil, ErrInvalidWorkload(fmt.Errorf("address %q is neither an IP address nor a DNS name", values.Address))
+		}
+	}
+	if values.ServiceAccount != "" {
+		if errs := validation.IsDNS1123Subdomain(values.ServiceAccount); len(errs) > 0 {
+			return nil, ErrInvalidWorkload(fmt.Errorf("invalid service account %q: %s", values.ServiceAccount, strings.Join(errs, ", ")))
+		}
+	}
+	if values.Network != "" {
+		if errs := validation.IsValidLabelValue(values.Network); len(errs) > 0 {
+			return nil, ErrInvalidWorkload(fmt.Errorf("invalid network %q: %s", values.Network, strings.Join(errs, ", ")))
+		}
+	}
+	return values, nil
+}
+
+// parseWorkloadLabels parses the comma separated key=value labels of the
+// workload. Unlike the extra labels, the labels Istio selects workloads with,
+// such as app and version, are the point of them.
+func parseWorkloadLabels(value string) (map[string]string, error) {
+	var labels map[string]string
+	for _, pair := range splitList(value) {
+		key, val, found := strings.Cut(pair, "=")
+		key, val = strings.TrimSpace(key), strings.TrimSpace(val)
+		if !found {
+			return nil, fmt.Errorf("label %q is not a key=value pair", pair)
+		}
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid label key %q: %s", key, strings.Join(errs, ", "))
+		}
+		if errs := validation.IsValidLabelValue(val); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid value of label %q: %s", key, strings.Join(errs, ", "))
+		}
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[key] = val
+	}
+	return labels, nil
+}
+
+// workloadLabels describes the labels of the workload for the event details
+func workloadLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for key, value := range labels {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ", ")
+}
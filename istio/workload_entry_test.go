@@ -0,0 +1,148 @@
+package istio
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/layer5io/meshery-istio/internal/config"
+	"github.com/layer5io/meshkit/errors"
+	"gopkg.in/yaml.v2"
+)
+
+func TestWorkloadEntry(t *testing.T) {
+	tmpl, err := os.ReadFile("../templates/vm/workload-entry.yaml")
+	if err != nil {
+		t.Fatalf("unable to read the WorkloadEntry template: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		props   map[string]string
+		del     bool
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name: "vm",
+			props: map[string]string{
+				config.WorkloadName:           "billing-vm-1",
+				config.WorkloadAddress:        "10.128.0.7",
+				config.WorkloadLabels:         "app=billing, version=v1",
+				config.WorkloadServiceAccount: "billing",
+				config.Network:                "vm-network",
+			},
+			want: map[string]interface{}{
+				"address":        "10.128.0.7",
+				"labels":         map[string]interface{}{"app": "billing", "version": "v1"},
+				"serviceAccount": "billing",
+				"network":        "vm-network",
+			},
+		},
+		{
+			name:  "dns address without labels",
+			props: map[string]string{config.WorkloadName: "billing-vm-1", config.WorkloadAddress: "vm1.billing.internal"},
+			want:  map[string]interface{}{"address": "vm1.billing.internal"},
+		},
+		{
+			name:  "delete by name",
+			props: map[string]string{config.WorkloadName: "billing-vm-1", config.WorkloadLabels: "app"},
+			del:   true,
+		},
+		{
+			name:    "no name",
+			props:   map[string]string{config.WorkloadAddress: "10.128.0.7"},
+			wantErr: true,
+		},
+		{
+			name:    "no address",
+			props:   map[string]string{config.WorkloadName: "billing-vm-1"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid address",
+			props:   map[string]string{config.WorkloadName: "billing-vm-1", config.WorkloadAddress: "10.128.0.7:8080"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid label",
+			props:   map[string]string{config.WorkloadName: "billing-vm-1", config.WorkloadAddress: "10.128.0.7", config.WorkloadLabels: "app"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := newWorkloadValues("billing", tt.props, false, tt.del)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newWorkloadValues() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if errors.GetCode(err) != ErrInvalidWorkloadCode {
+					t.Errorf("newWorkloadValues() error code = %s, want %s", errors.GetCode(err), ErrInvalidWorkloadCode)
+				}
+				return
+			}
+			if tt.del {
+				return
+			}
+			got := renderWorkload(t, string(tmpl), values)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("renderTemplate() spec = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorkloadGroup(t *testing.T) {
+	tmpl, err := os.ReadFile("../templates/vm/workload-group.yaml")
+	if err != nil {
+		t.Fatalf("unable to read the WorkloadGroup template: %v", err)
+	}
+
+	props := map[string]string{
+		config.WorkloadName:           "billing",
+		config.WorkloadAddress:        "10.128.0.7",
+		config.WorkloadLabels:         "app=billing",
+		config.WorkloadServiceAccount: "billing",
+	}
+	values, err := newWorkloadValues("billing", props, true, false)
+	if err != nil {
+		t.Fatalf("newWorkloadValues() error = %v", err)
+	}
+	want := map[string]interface{}{
+		"metadata": map[string]interface{}{"labels": map[string]interface{}{"app": "billing"}},
+		"template": map[string]interface{}{"serviceAccount": "billing"},
+	}
+	if got := renderWorkload(t, string(tmpl), values); !reflect.DeepEqual(got, want) {
+		t.Errorf("renderTemplate() spec = %v, want %v", got, want)
+	}
+
+	delete(props, config.WorkloadLabels)
+	if _, err := newWorkloadValues("billing", props, true, false); errors.GetCode(err) != ErrInvalidWorkloadCode {
+		t.Errorf("newWorkloadValues() of a group without labels error = %v, want ErrInvalidWorkload", err)
+	}
+}
+
+// renderWorkload renders the template of the workload and returns its spec
+func renderWorkload(t *testing.T, tmpl string, values *workloadValues) map[string]interface{} {
+	t.Helper()
+	rendered, err := renderTemplate(tmpl, values)
+	if err != nil {
+		t.Fatalf("renderTemplate() error = %v", err)
+	}
+	var workload struct {
+		Metadata struct {
+			Name      string `yaml:"name"`
+			Namespace string `yaml:"namespace"`
+		} `yaml:"metadata"`
+		Spec interface{} `yaml:"spec"`
+	}
+	if err := yaml.Unmarshal([]byte(rendered), &workload); err != nil {
+		t.Fatalf("renderTemplate() generated invalid YAML: %v\n%s", err, rendered)
+	}
+	if workload.Metadata.Name != values.Name || workload.Metadata.Namespace != "billing" {
+		t.Errorf("renderTemplate() name/namespace = %s/%s, want %s/billing", workload.Metadata.Name, workload.Metadata.Namespace, values.Name)
+	}
+	spec, _ := jsonValue(workload.Spec).(map[string]interface{})
+	return spec
+}
@@ -15,8 +15,10 @@ package main
 
 import (
 	"fmt"
+	"net/http"
 	"os"
 	"path"
+	"strconv"
 	"strings"
 	"time"
 
@@ -77,6 +79,40 @@ func main() {
 		log.Warn(err)
 	}
 
+	if err := istio.UseEnvironmentProxy(); err != nil {
+		// The downloads fail until an operation sets a valid proxy
+		log.Error(err)
+	}
+
+	if c := os.Getenv("CLUSTER_CONCURRENCY"); c != "" {
+		n, err := strconv.Atoi(c)
+		if err != nil || n <= 0 {
+			log.Warn(fmt.Errorf("invalid CLUSTER_CONCURRENCY %q, using %d", c, config.ClusterConcurrency))
+		} else {
+			config.ClusterConcurrency = n
+		}
+	}
+
+	if c := os.Getenv("APPLY_MAX_ATTEMPTS"); c != "" {
+		n, err := strconv.Atoi(c)
+		if err != nil || n <= 0 {
+			log.Warn(fmt.Errorf("invalid APPLY_MAX_ATTEMPTS %q, using %d", c, config.ApplyMaxAttempts))
+		} else {
+			config.ApplyMaxAttempts = n
+		}
+	}
+
+	if addr, ok := os.LookupEnv("METRICS_ADDRESS"); ok {
+		config.MetricsAddress = addr
+	}
+	if config.MetricsAddress != "" {
+		go serveMetrics(config.MetricsAddress, log)
+	}
+
+	if dir := os.Getenv("TEMPLATES_OVERRIDE_DIR"); dir != "" {
+		config.TemplatesOverrideDir = dir
+	}
+
 	// Initialize application specific configs and dependencies
 	// App and request config
 	cfg, err := config.New(configprovider.ViperKey)
@@ -155,6 +191,21 @@ func serviceAddress() string {
 	return "localhost"
 }
 
+// serveMetrics serves the Prometheus metrics of the adapter at /metrics
+func serveMetrics(addr string, log logger.Handler) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", istio.MetricsHandler())
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	log.Info("Serving metrics at ", addr)
+	if err := server.ListenAndServe(); err != nil {
+		log.Error(err)
+	}
+}
+
 func registerCapabilities(port string, log logger.Handler) {
 	err := oam.RegisterMeshModelComponents(instanceID, mesheryServerAddress(), serviceAddress(), port)
 	if err != nil {